@@ -0,0 +1,210 @@
+package main
+
+import (
+	"PaymentAPI/config"
+	"PaymentAPI/constants"
+	req "PaymentAPI/dto/request"
+	"PaymentAPI/enums"
+	"PaymentAPI/service"
+	"PaymentAPI/storage"
+	"PaymentAPI/utils"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+)
+
+// storagePaths lists every JSON storage file managed by the application
+var storagePaths = []string{
+	constants.CustomerJsonPath,
+	constants.RefreshTokenJsonPath,
+	constants.SessionJsonPath,
+	constants.BlacklistJsonPath,
+	constants.TokenWatermarkJsonPath,
+	constants.WalletJsonPath,
+	constants.TransactionJsonPath,
+	constants.HoldJsonPath,
+	constants.PaymentRequestJsonPath,
+	constants.SplitBillJsonPath,
+	constants.PayoutBatchJsonPath,
+	constants.MerchantJsonPath,
+	constants.CheckoutSessionJsonPath,
+	constants.VirtualAccountJsonPath,
+	constants.BillerProductJsonPath,
+	constants.BillPaymentJsonPath,
+	constants.WebhookEndpointJsonPath,
+	constants.WebhookDeliveryJsonPath,
+	constants.OutboxEventJsonPath,
+	constants.PasswordResetTokenJsonPath,
+	constants.LoginAttemptJsonPath,
+	constants.TwoFactorJsonPath,
+	constants.LoginChallengeJsonPath,
+	constants.TransactionPinJsonPath,
+	constants.LedgerCheckpointJsonPath,
+	constants.ReconciliationReportJsonPath,
+}
+
+// commandContext holds the dependencies available to command line operations
+type commandContext struct {
+	keyring               *storage.Keyring
+	customerService       service.CustomerService
+	ledgerAuditService    service.LedgerAuditService
+	reconciliationService service.ReconciliationService
+}
+
+// initStorageEncryption enables encryption at rest when a storage key is configured
+func initStorageEncryption() *storage.Keyring {
+	if config.StorageEncryptionKey == "" {
+		return nil
+	}
+
+	currentKey, err := storage.DecodeKey(config.StorageEncryptionKey)
+	if err != nil {
+		log.Fatalf("Failed to load storage encryption key: %v", err)
+	}
+
+	var previousKeys [][]byte
+	for _, encodedKey := range config.StorageEncryptionPreviousKeys {
+		key, err := storage.DecodeKey(encodedKey)
+		if err != nil {
+			log.Fatalf("Failed to load previous storage encryption key: %v", err)
+		}
+		previousKeys = append(previousKeys, key)
+	}
+
+	keyring, err := storage.NewKeyring(currentKey, previousKeys, config.StorageAllowPlaintext)
+	if err != nil {
+		log.Fatalf("Failed to initialize storage encryption: %v", err)
+	}
+
+	storage.UseKeyring(keyring)
+	return keyring
+}
+
+// initJwtKeys signs access tokens with the configured private key, and verifies them with it or any other
+// configured public key
+func initJwtKeys() {
+	if config.JwtPrivateKey == nil {
+		if len(config.JwtVerificationKeys) > 0 {
+			log.Fatal("JWT_VERIFICATION_KEY_FILES is set without JWT_PRIVATE_KEY_FILE")
+		}
+		log.Println("No JWT private key configured, access tokens are signed with HS256 and JWT_SIGNATURE_KEY")
+		return
+	}
+
+	keys, err := utils.NewJwtKeys(config.JwtPrivateKey, config.JwtVerificationKeys)
+	if err != nil {
+		log.Fatalf("Failed to load JWT keys: %v", err)
+	}
+
+	utils.UseJwtKeys(keys)
+	log.Printf("Access tokens are signed with key %s", keys.SigningKeyId())
+}
+
+// runCommand executes a command line operation and exits with a non-zero code on failure
+func runCommand(args []string, ctx commandContext) {
+	switch args[0] {
+	case "rotate-storage-keys":
+		if ctx.keyring == nil {
+			log.Fatal("Storage encryption is not configured, set STORAGE_ENCRYPTION_KEY first")
+		}
+		if err := storage.ReEncryptFiles(ctx.keyring, storagePaths); err != nil {
+			log.Fatalf("Failed to rotate storage keys: %v", err)
+		}
+		fmt.Printf("All storage files are encrypted with key %s\n", ctx.keyring.CurrentKeyId())
+	case "verify-ledger":
+		report, err := ctx.ledgerAuditService.VerifyChain()
+		if err != nil {
+			log.Fatalf("Failed to verify ledger: %v", err)
+		}
+		printJson(report)
+		if !report.Valid {
+			os.Exit(1)
+		}
+	case "checkpoint-ledger":
+		checkpoint, err := ctx.ledgerAuditService.CreateCheckpoint()
+		if err != nil {
+			log.Fatalf("Failed to create ledger checkpoint: %v", err)
+		}
+		printJson(checkpoint)
+	case "simulate-bank-deposit":
+		if len(args) < 3 {
+			log.Fatal("Usage: simulate-bank-deposit <virtual_account_number> <amount> [external_id]")
+		}
+		simulateBankDeposit(args[1:])
+	case "grant-admin", "revoke-admin":
+		if len(args) < 2 {
+			log.Fatalf("Usage: %s <username>", args[0])
+		}
+		role := enums.ROLE_ADMIN
+		if args[0] == "revoke-admin" {
+			role = enums.ROLE_USER
+		}
+		if err := ctx.customerService.SetRole(args[1], role); err != nil {
+			log.Fatalf("Failed to set role: %v", err)
+		}
+		fmt.Printf("%s now has role %s, effective from their next login\n", args[1], role)
+	case "reconcile":
+		report, err := ctx.reconciliationService.Reconcile()
+		if err != nil {
+			log.Fatalf("Failed to reconcile ledger: %v", err)
+		}
+		printJson(report)
+		if !report.Balanced {
+			os.Exit(1)
+		}
+	default:
+		fmt.Printf("Unknown command: %s\n", args[0])
+		os.Exit(1)
+	}
+}
+
+// simulateBankDeposit plays the bank: it signs a deposit notification and sends it to the running server
+func simulateBankDeposit(args []string) {
+	amount, err := strconv.ParseFloat(args[1], 64)
+	if err != nil {
+		log.Fatalf("Invalid amount: %v", err)
+	}
+
+	externalId := "SIM-" + uuid.New().String()
+	if len(args) > 2 {
+		externalId = args[2]
+	}
+
+	body, _ := json.Marshal(req.BankNotificationRequest{
+		ExternalId:           externalId,
+		VirtualAccountNumber: args[0],
+		Amount:               amount,
+		PayerName:            "Bank Simulator",
+		PaidAt:               time.Now().Format(time.RFC3339),
+	})
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	request, _ := http.NewRequest(http.MethodPost, "http://localhost:"+config.ServerPort+"/api/public/bank/notifications", bytes.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("X-Bank-Timestamp", timestamp)
+	request.Header.Set("X-Bank-Signature", utils.SignBankNotification(timestamp, body))
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		log.Fatalf("Failed to send bank notification: %v", err)
+	}
+	defer response.Body.Close()
+
+	responseBody, _ := io.ReadAll(response.Body)
+	fmt.Printf("%d %s\n", response.StatusCode, responseBody)
+	if response.StatusCode != http.StatusOK {
+		os.Exit(1)
+	}
+}
+
+func printJson(value interface{}) {
+	output, _ := json.MarshalIndent(value, "", "  ")
+	fmt.Println(string(output))
+}
@@ -4,6 +4,7 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v4"
@@ -16,6 +17,64 @@ var (
 	LoginExpirationDuration time.Duration
 	JwtSigningMethod        jwt.SigningMethod
 	JwtSignatureKey         []byte
+	JwtPrivateKey           []byte
+	JwtVerificationKeys     [][]byte
+
+	PasswordMinLength        int
+	PasswordMaxLength        int
+	PasswordRequireUppercase bool
+	PasswordRequireLowercase bool
+	PasswordRequireDigit     bool
+	PasswordRequireSymbol    bool
+	PasswordBlocklist        []string
+
+	PasswordHashAlgorithm string
+	BCryptCost            int
+	Argon2Memory          int
+	Argon2Iterations      int
+	Argon2Parallelism     int
+
+	LoginMaxFailures      int
+	LoginIpMaxFailures    int
+	LoginLockoutDuration  time.Duration
+	LoginFailureBaseDelay time.Duration
+	LoginFailureMaxDelay  time.Duration
+	TrustedProxies        []string
+
+	TotpIssuer                       string
+	LoginChallengeExpirationDuration time.Duration
+
+	TransactionStepUpThreshold    float64
+	TransactionPinMaxFailures     int
+	TransactionPinLockoutDuration time.Duration
+
+	PasswordResetTokenExpirationDuration time.Duration
+	NotifierType                         string
+	NotifierFilePath                     string
+
+	StorageEncryptionKey          string
+	StorageEncryptionPreviousKeys []string
+	StorageAllowPlaintext         bool
+
+	LedgerCheckpointSigningKey []byte
+	LedgerCheckpointInterval   time.Duration
+	ReconciliationInterval     time.Duration
+
+	HoldExpirationDuration           time.Duration
+	PaymentRequestExpirationDuration time.Duration
+
+	PayoutMaxRows int
+
+	CheckoutSessionExpirationDuration time.Duration
+
+	VirtualAccountPrefix         string
+	BankNotificationSigningKey   []byte
+	BankNotificationMaxClockSkew time.Duration
+
+	WebhookMaxAttempts      int
+	WebhookRetryBaseDelay   time.Duration
+	WebhookDeliveryTimeout  time.Duration
+	WebhookAllowPrivateUrls bool
 )
 
 func InitConfig() {
@@ -41,8 +100,228 @@ func InitConfig() {
 	// Set JwtSigningMethod (default: HS256)
 	JwtSigningMethod = jwt.SigningMethodHS256
 
-	// Read Jwt Signature Key (default: "secret")
-	JwtSignatureKey = []byte(getEnv("JWT_SIGNATURE_KEY", "secret"))
+	// Read JWT Private Key File, a PEM encoded RSA or Ed25519 key to sign access tokens with RS256 or EdDSA instead
+	// (default: none, HS256 with the signature key)
+	JwtPrivateKey = nil
+	if keyFile := getEnv("JWT_PRIVATE_KEY_FILE", ""); keyFile != "" {
+		key, err := os.ReadFile(keyFile)
+		if err != nil {
+			log.Fatalf("Failed to read JWT_PRIVATE_KEY_FILE: %v", err)
+		}
+		JwtPrivateKey = key
+	}
+
+	// Read Jwt Signature Key, signing access tokens with HS256 when no private key is configured (required then, a
+	// default key would let anyone forge an access token)
+	JwtSignatureKey = nil
+	if JwtPrivateKey == nil {
+		JwtSignatureKey = getRequiredSecret("JWT_SIGNATURE_KEY")
+	}
+
+	// Read JWT Verification Key Files, PEM encoded public keys also accepted during a key rotation (default: none)
+	JwtVerificationKeys = nil
+	for _, keyFile := range strings.Split(getEnv("JWT_VERIFICATION_KEY_FILES", ""), ",") {
+		if keyFile = strings.TrimSpace(keyFile); keyFile == "" {
+			continue
+		}
+		key, err := os.ReadFile(keyFile)
+		if err != nil {
+			log.Fatalf("Failed to read JWT_VERIFICATION_KEY_FILES: %v", err)
+		}
+		JwtVerificationKeys = append(JwtVerificationKeys, key)
+	}
+
+	// Read Password Min Length and Max Length, bcrypt only uses the first 72 bytes of a password (default: 8 and 72)
+	PasswordMinLength = getEnvInt("PASSWORD_MIN_LENGTH", "8")
+	PasswordMaxLength = getEnvInt("PASSWORD_MAX_LENGTH", "72")
+
+	// Read the character classes a password must contain (default: uppercase, lowercase and digit)
+	PasswordRequireUppercase = getEnv("PASSWORD_REQUIRE_UPPERCASE", "true") == "true"
+	PasswordRequireLowercase = getEnv("PASSWORD_REQUIRE_LOWERCASE", "true") == "true"
+	PasswordRequireDigit = getEnv("PASSWORD_REQUIRE_DIGIT", "true") == "true"
+	PasswordRequireSymbol = getEnv("PASSWORD_REQUIRE_SYMBOL", "false") == "true"
+
+	// Read Password Blocklist File with one common password per line, on top of the built-in list (default: none)
+	PasswordBlocklist = nil
+	if blocklistFile := getEnv("PASSWORD_BLOCKLIST_FILE", ""); blocklistFile != "" {
+		blocklist, err := os.ReadFile(blocklistFile)
+		if err != nil {
+			log.Fatalf("Failed to read PASSWORD_BLOCKLIST_FILE: %v", err)
+		}
+		for _, password := range strings.Split(string(blocklist), "\n") {
+			if password = strings.TrimSpace(password); password != "" {
+				PasswordBlocklist = append(PasswordBlocklist, password)
+			}
+		}
+	}
+
+	// Read Password Hash Algorithm, "bcrypt" or "argon2id", for new and upgraded password hashes (default: bcrypt)
+	PasswordHashAlgorithm = getEnv("PASSWORD_HASH_ALGORITHM", "bcrypt")
+	if PasswordHashAlgorithm != "bcrypt" && PasswordHashAlgorithm != "argon2id" {
+		log.Fatalf("Unknown PASSWORD_HASH_ALGORITHM: %s", PasswordHashAlgorithm)
+	}
+
+	// Read BCrypt Cost (default: 12)
+	BCryptCost = getEnvInt("BCRYPT_COST", "12")
+
+	// Read Argon2id memory in KiB, iterations and parallelism (default: 64 MiB, 3 iterations, 2 threads)
+	Argon2Memory = getEnvInt("ARGON2_MEMORY", "65536")
+	Argon2Iterations = getEnvInt("ARGON2_ITERATIONS", "3")
+	Argon2Parallelism = getEnvInt("ARGON2_PARALLELISM", "2")
+
+	// Read Login Max Failures per username and per client IP before logins are locked (default: 5 and 20)
+	LoginMaxFailures = getEnvInt("LOGIN_MAX_FAILURES", "5")
+	LoginIpMaxFailures = getEnvInt("LOGIN_IP_MAX_FAILURES", "20")
+
+	// Read Login Lockout Duration, also how long failed attempts are remembered (default: 15 minutes)
+	LoginLockoutDuration = getEnvMinutes("LOGIN_LOCKOUT_DURATION", "15")
+
+	// Read the delay before another login after a failure, doubled after every failure (default: 1 up to 30 seconds)
+	LoginFailureBaseDelay = getEnvSeconds("LOGIN_FAILURE_BASE_DELAY", "1")
+	LoginFailureMaxDelay = getEnvSeconds("LOGIN_FAILURE_MAX_DELAY", "30")
+
+	// Read Trusted Proxies, the addresses or CIDR ranges of the reverse proxies whose X-Forwarded-For header names the
+	// client IP that failed logins are counted by (default: none, the IP of the connection)
+	TrustedProxies = nil
+	for _, proxy := range strings.Split(getEnv("TRUSTED_PROXIES", ""), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			TrustedProxies = append(TrustedProxies, proxy)
+		}
+	}
+
+	// Read TOTP Issuer shown next to the account in authenticator apps (default: the application name)
+	TotpIssuer = getEnv("TOTP_ISSUER", ApplicationName)
+
+	// Read Login Challenge Expiration Duration, how long a two-factor code can be given after the password (default: 5 minutes)
+	LoginChallengeExpirationDuration = getEnvMinutes("LOGIN_CHALLENGE_EXPIRATION_DURATION", "5")
+
+	// Read Transaction Step Up Threshold, transfers above it need the transaction PIN or a two-factor code (default: 1000000)
+	TransactionStepUpThreshold = getEnvFloat("TRANSACTION_STEP_UP_THRESHOLD", "1000000")
+
+	// Read Transaction PIN Max Failures before transfers are locked, and for how long (default: 5 and 30 minutes)
+	TransactionPinMaxFailures = getEnvInt("TRANSACTION_PIN_MAX_FAILURES", "5")
+	TransactionPinLockoutDuration = getEnvMinutes("TRANSACTION_PIN_LOCKOUT_DURATION", "30")
+
+	// Read Password Reset Token Expiration Duration (default: 30 minutes)
+	PasswordResetTokenExpirationDuration = getEnvMinutes("PASSWORD_RESET_TOKEN_EXPIRATION_DURATION", "30")
+
+	// Read Notifier Type, "log" or "file", used to send password reset tokens (default: log)
+	NotifierType = getEnv("NOTIFIER_TYPE", "log")
+
+	// Read Notifier File Path the file notifier appends notifications to (default: ./logger/notifications.jsonl)
+	NotifierFilePath = getEnv("NOTIFIER_FILE_PATH", "./logger/notifications.jsonl")
+
+	// Read Storage Encryption Key, either inline or from a key file (default: encryption disabled)
+	StorageEncryptionKey = getEnv("STORAGE_ENCRYPTION_KEY", "")
+	if keyFile := getEnv("STORAGE_ENCRYPTION_KEY_FILE", ""); keyFile != "" {
+		key, err := os.ReadFile(keyFile)
+		if err != nil {
+			log.Fatalf("Failed to read STORAGE_ENCRYPTION_KEY_FILE: %v", err)
+		}
+		StorageEncryptionKey = strings.TrimSpace(string(key))
+	}
+
+	// Read keys that are still accepted for decryption during a key rotation (default: none)
+	StorageEncryptionPreviousKeys = nil
+	for _, key := range strings.Split(getEnv("STORAGE_ENCRYPTION_PREVIOUS_KEYS", ""), ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			StorageEncryptionPreviousKeys = append(StorageEncryptionPreviousKeys, key)
+		}
+	}
+
+	// Read whether plaintext storage files may still be loaded while encryption is enabled (default: false)
+	StorageAllowPlaintext = getEnv("STORAGE_ENCRYPTION_ALLOW_PLAINTEXT", "false") == "true"
+
+	// Read Ledger Checkpoint Signing Key (required, a default key would let anyone forge checkpoints)
+	LedgerCheckpointSigningKey = getRequiredSecret("LEDGER_CHECKPOINT_SIGNING_KEY")
+
+	// Read Ledger Checkpoint Interval (default: 60 minutes, 0 disables scheduled checkpoints)
+	LedgerCheckpointInterval = getEnvMinutes("LEDGER_CHECKPOINT_INTERVAL", "60")
+
+	// Read Reconciliation Interval (default: 60 minutes, 0 disables scheduled reconciliation)
+	ReconciliationInterval = getEnvMinutes("RECONCILIATION_INTERVAL", "60")
+
+	// Read Hold Expiration Duration used when a hold does not set its own expiry (default: 7 days)
+	HoldExpirationDuration = getEnvMinutes("HOLD_EXPIRATION_DURATION", "10080")
+
+	// Read Payment Request Expiration Duration (default: 3 days)
+	PaymentRequestExpirationDuration = getEnvMinutes("PAYMENT_REQUEST_EXPIRATION_DURATION", "4320")
+
+	// Read Payout Max Rows accepted in a single payout batch (default: 1000)
+	PayoutMaxRows, err = strconv.Atoi(getEnv("PAYOUT_MAX_ROWS", "1000"))
+	if err != nil {
+		log.Fatalf("Failed to parse PAYOUT_MAX_ROWS: %v", err)
+	}
+
+	// Read Checkout Session Expiration Duration used when a session does not set its own expiry (default: 30 minutes)
+	CheckoutSessionExpirationDuration = getEnvMinutes("CHECKOUT_SESSION_EXPIRATION_DURATION", "30")
+
+	// Read Virtual Account Prefix, the bank code every virtual account number starts with (default: 8808)
+	VirtualAccountPrefix = getEnv("VIRTUAL_ACCOUNT_PREFIX", "8808")
+
+	// Read Bank Notification Signing Key shared with the bank to sign deposit notifications (required, a default key
+	// would let anyone credit a wallet through the public notification endpoint)
+	BankNotificationSigningKey = getRequiredSecret("BANK_NOTIFICATION_SIGNING_KEY")
+
+	// Read Bank Notification Max Clock Skew, how old a signed notification may be (default: 5 minutes)
+	BankNotificationMaxClockSkew = getEnvMinutes("BANK_NOTIFICATION_MAX_CLOCK_SKEW", "5")
+
+	// Read Webhook Max Attempts before a delivery is given up (default: 6)
+	WebhookMaxAttempts, err = strconv.Atoi(getEnv("WEBHOOK_MAX_ATTEMPTS", "6"))
+	if err != nil {
+		log.Fatalf("Failed to parse WEBHOOK_MAX_ATTEMPTS: %v", err)
+	}
+
+	// Read Webhook Retry Base Delay, doubled after every failed attempt (default: 30 seconds)
+	WebhookRetryBaseDelay = getEnvSeconds("WEBHOOK_RETRY_BASE_DELAY", "30")
+
+	// Read Webhook Delivery Timeout for a single attempt (default: 10 seconds)
+	WebhookDeliveryTimeout = getEnvSeconds("WEBHOOK_DELIVERY_TIMEOUT", "10")
+
+	// Read Webhook Allow Private Urls, letting webhooks and callbacks reach loopback and private addresses for local
+	// development (default: false, a URL could reach internal services or the cloud metadata address otherwise)
+	WebhookAllowPrivateUrls = getEnv("WEBHOOK_ALLOW_PRIVATE_URLS", "false") == "true"
+}
+
+// getRequiredSecret reads a signing key that has no default, refusing to start while it is unset or still "secret"
+func getRequiredSecret(key string) []byte {
+	value := strings.TrimSpace(getEnv(key, ""))
+	if value == "" || value == "secret" {
+		log.Fatalf("%s must be set to a secret key", key)
+	}
+	return []byte(value)
+}
+
+func getEnvInt(key, defaultValue string) int {
+	value, err := strconv.Atoi(getEnv(key, defaultValue))
+	if err != nil {
+		log.Fatalf("Failed to parse %s: %v", key, err)
+	}
+	return value
+}
+
+func getEnvFloat(key, defaultValue string) float64 {
+	value, err := strconv.ParseFloat(getEnv(key, defaultValue), 64)
+	if err != nil {
+		log.Fatalf("Failed to parse %s: %v", key, err)
+	}
+	return value
+}
+
+func getEnvMinutes(key, defaultValue string) time.Duration {
+	minutes, err := strconv.Atoi(getEnv(key, defaultValue))
+	if err != nil {
+		log.Fatalf("Failed to parse %s: %v", key, err)
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+func getEnvSeconds(key, defaultValue string) time.Duration {
+	seconds, err := strconv.Atoi(getEnv(key, defaultValue))
+	if err != nil {
+		log.Fatalf("Failed to parse %s: %v", key, err)
+	}
+	return time.Duration(seconds) * time.Second
 }
 
 func getEnv(key, defaultValue string) string {
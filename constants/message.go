@@ -10,14 +10,59 @@ const CustomerForbiddenAccess = "User does not have permission to access this cu
 
 const RefreshTokenNotFoundError = "Refresh token not found, could be expired"
 const RefreshTokenExpiredError = "Refresh token is expired"
+const RefreshTokenReusedError = "Refresh token was already used, the session has been revoked, log in again"
+
+const SessionFindSuccess = "Successfully get sessions"
+const SessionRevokeSuccess = "Successfully revoked the session"
+const SessionRevokeOthersSuccess = "Successfully revoked all other sessions"
+const SessionNotFoundError = "Session not found"
+const SessionUnknownError = "The current session is unknown, log in again"
 
 const LoginSuccess = "Successfully logged in"
 const LoginUnauthorizedError = "Invalid credentials"
 const AuthorizationHeaderMissingError = "Authorization header is missing"
 const AuthorizationHeaderInvalidError = "Invalid Authorization header token"
 const LogoutSuccess = "Successfully logged out"
+const LogoutAllSuccess = "Successfully logged out from all devices"
 const AccessTokenNotFoundError = "Access token not found"
 const AuthenticatedUserNotFoundError = "Authenticated user not found"
+const RoleForbiddenAccess = "User does not have permission to access this resource"
+
+const LoginLockedError = "Too many failed login attempts, try again later"
+const LoginThrottledError = "Too many failed login attempts, wait before trying again"
+const LoginAttemptNotFoundError = "Login attempt not found"
+const LoginLockoutFindSuccess = "Successfully get login lockouts"
+const LoginUnlockSuccess = "Successfully unlocked login"
+const LoginUnlockRequestError = "Username or IP is required"
+
+const TwoFactorEnrollSuccess = "Successfully started two-factor enrollment, verify a code to enable it"
+const TwoFactorEnableSuccess = "Successfully enabled two-factor authentication"
+const TwoFactorDisableSuccess = "Successfully disabled two-factor authentication"
+const TwoFactorRecoveryCodesSuccess = "Successfully generated new recovery codes"
+const TwoFactorChallengeSuccess = "Password accepted, a two-factor code is required"
+const TwoFactorNotFoundError = "Two-factor authentication is not set up"
+const TwoFactorAlreadyEnabledError = "Two-factor authentication is already enabled"
+const TwoFactorNotEnabledError = "Two-factor authentication is not enabled"
+const TwoFactorCodeInvalidError = "Two-factor code is invalid"
+const LoginChallengeInvalidError = "Login challenge is invalid or expired, log in again"
+const LoginChallengeNotFoundError = "Login challenge not found"
+
+const PasswordChangeSuccess = "Successfully changed the password"
+const PasswordIncorrectError = "Current password is incorrect"
+const PasswordPolicyError = "Password does not meet the password policy"
+const PasswordTooShortError = "Password must be at least %d characters long"
+const PasswordTooLongError = "Password must be at most %d bytes long"
+const PasswordUppercaseError = "Password must contain an uppercase letter"
+const PasswordLowercaseError = "Password must contain a lowercase letter"
+const PasswordDigitError = "Password must contain a digit"
+const PasswordSymbolError = "Password must contain a symbol"
+const PasswordCommonError = "Password is too common"
+const PasswordUsernameError = "Password must not contain the username"
+const PasswordUnchangedError = "New password must be different from the current password"
+const PasswordResetRequestSuccess = "If the username exists, a password reset token has been sent"
+const PasswordResetSuccess = "Successfully reset the password, please log in again"
+const PasswordResetTokenInvalidError = "Password reset token is invalid, expired or already used"
+const PasswordResetTokenNotFoundError = "Password reset token not found"
 
 const JsonWriteSuccess = "Successfully wrote JSON file"
 const JsonFileNotFound = "Json file path not found"
@@ -25,14 +70,156 @@ const JsonMarshalError = "An error occurred while marshalling json"
 const JsonMappingError = "An error occurred while mapping json, make sure the data type is compatible with the json"
 const JsonCreateError = "An error occurred while creating JSON file"
 const JsonWriteError = "An error occurred while writing JSON file"
+const JsonEncryptError = "An error occurred while encrypting JSON file"
+
+const StorageKeyInvalidError = "Storage encryption key must be a base64 encoded 32 byte key"
+const StorageKeyNotFoundError = "Storage file is encrypted with an unknown key"
+const StorageIntegrityError = "Storage file failed integrity check, it may have been tampered with"
+const StoragePlaintextRejectedError = "Storage file is not encrypted while encryption is enabled"
 
 const JwtTokenInvalidError = "Invalid JWT token"
+const JwtKeyInvalidError = "JWT key must be a PEM encoded Ed25519 key or RSA key of at least 2048 bits"
 
 const WalletNotFoundError = "Wallet not found"
 const WalletDuplicateError = "Wallet already exists"
 const WalletForbiddenAccess = "User does not have permission to access this wallet"
+const WalletFreezeSuccess = "Successfully froze the wallet"
+const WalletUnfreezeSuccess = "Successfully unfroze the wallet"
+const WalletCloseSuccess = "Successfully closed the wallet"
+const WalletAlreadyFrozenError = "Wallet is already frozen"
+const WalletNotFrozenError = "Wallet is not frozen"
+const WalletAlreadyClosedError = "Wallet is already closed"
+const WalletCloseBalanceError = "Wallet balance must be zero or paid out before closing"
+const WalletCloseActiveHoldsError = "Wallet has active holds, capture or void them before closing"
+const WalletPayoutSameWalletError = "Payout wallet must be different from the wallet being closed"
+const WalletSenderFrozenError = "Sender wallet is frozen"
+const WalletSenderClosedError = "Sender wallet is closed"
+const WalletReceiverFrozenError = "Receiver wallet is frozen"
+const WalletReceiverClosedError = "Receiver wallet is closed"
 
 const InvalidRequestBodyError = "Invalid request body"
 
 const TransactionInsufficientError = "Insufficient amount of funds"
 const TransactionSuccess = "Successfully created a transaction"
+const TransactionInvalidAmountError = "Transaction amount must be greater than zero"
+const TransactionStepUpRequiredError = "Transfers above the step-up threshold require the transaction PIN or a two-factor code"
+
+const TransactionPinSetSuccess = "Successfully set the transaction PIN"
+const TransactionPinFormatError = "Transaction PIN must be exactly 6 digits"
+const TransactionPinWeakError = "Transaction PIN must not be a repeated or sequential number"
+const TransactionPinNotFoundError = "Transaction PIN not found"
+const TransactionPinNotSetError = "Set a transaction PIN to make transfers above the step-up threshold"
+const TransactionPinIncorrectError = "Transaction PIN is incorrect"
+const TransactionPinLockedError = "Transfers are locked after too many failed PIN or two-factor attempts, try again later"
+
+const HoldCreateSuccess = "Successfully created a hold"
+const HoldFindSuccess = "Successfully get a hold"
+const HoldCaptureSuccess = "Successfully captured the hold"
+const HoldVoidSuccess = "Successfully voided the hold"
+const HoldNotFoundError = "Hold not found"
+const HoldNotActiveError = "Hold is no longer active"
+const HoldCaptureAmountError = "Capture amount must be greater than zero and not exceed the held amount"
+const HoldForbiddenAccess = "User does not have permission to access this hold"
+const HoldSameWalletError = "Hold wallet and receiving wallet must be different"
+
+const PaymentRequestCreateSuccess = "Successfully created a payment request"
+const PaymentRequestFindSuccess = "Successfully get payment requests"
+const PaymentRequestAcceptSuccess = "Successfully accepted the payment request"
+const PaymentRequestDeclineSuccess = "Successfully declined the payment request"
+const PaymentRequestNotFoundError = "Payment request not found"
+const PaymentRequestNotPendingError = "Payment request is no longer pending"
+const PaymentRequestSameWalletError = "Cannot request a payment from your own wallet"
+const PaymentRequestForbiddenAccess = "User does not have permission to access this payment request"
+const PaymentRequestDirectionError = "Direction must be either incoming or outgoing"
+
+const SplitBillCreateSuccess = "Successfully created a split bill"
+const SplitBillFindSuccess = "Successfully get split bill"
+const SplitBillNotFoundError = "Split bill not found"
+const SplitBillForbiddenAccess = "User does not have permission to access this split bill"
+const SplitBillNoParticipantsError = "Split bill must have at least one participant"
+const SplitBillParticipantError = "Participants must be unique and different from the creator"
+const SplitBillTypeError = "Split type must be either EQUAL or CUSTOM"
+const SplitBillShareError = "Every share must be greater than zero"
+const SplitBillShareTotalError = "Shares must not exceed the total amount"
+
+const PayoutBatchCompleteSuccess = "Successfully processed the payout batch"
+const PayoutBatchFindSuccess = "Successfully get payout batch"
+const PayoutBatchNotFoundError = "Payout batch not found"
+const PayoutBatchForbiddenAccess = "User does not have permission to access this payout batch"
+const PayoutBatchEmptyError = "Payout batch must have at least one row"
+const PayoutBatchTooLargeError = "Payout batch has too many rows"
+const PayoutBatchInvalidRowsError = "Payout batch has invalid rows, no transfer was made"
+const PayoutCsvInvalidError = "CSV file must have a header with recipient_wallet_id and amount columns"
+const PayoutAmountInvalidError = "Amount must be a number greater than zero"
+const PayoutRecipientRequiredError = "Recipient wallet ID is required"
+const PayoutSameWalletError = "Recipient wallet must be different from the paying wallet"
+
+const MerchantCreateSuccess = "Successfully created a merchant"
+const MerchantFindSuccess = "Successfully get merchant"
+const MerchantNotFoundError = "Merchant not found"
+const MerchantForbiddenAccess = "User does not have permission to access this merchant"
+const MerchantNameRequiredError = "Merchant name is required"
+const MerchantCallbackUrlError = "Callback URL must be an absolute http or https URL of a public host"
+
+const CheckoutSessionCreateSuccess = "Successfully created a checkout session"
+const CheckoutSessionFindSuccess = "Successfully get checkout session"
+const CheckoutSessionPaySuccess = "Successfully paid the checkout session"
+const CheckoutSessionNotFoundError = "Checkout session not found"
+const CheckoutSessionNotOpenError = "Checkout session is no longer open"
+const CheckoutSessionReferenceError = "Reference is required and must be unique for the merchant"
+const CheckoutSessionOwnWalletError = "Cannot pay a checkout session with the merchant's own wallet"
+
+const QrGenerateSuccess = "Successfully generated the QR payload"
+const QrPaySuccess = "Successfully paid the QR payload"
+const QrPayloadInvalidError = "QR payload is not a valid EMV payload"
+const QrChecksumError = "QR payload checksum does not match"
+const QrUnsupportedError = "QR payload is not supported by this application"
+const QrAmountRequiredError = "Amount is required for a QR payload without a fixed amount"
+const QrAmountMismatchError = "Amount does not match the fixed amount of the QR payload"
+const QrOwnWalletError = "Cannot pay a QR payload of your own wallet"
+
+const VirtualAccountFindSuccess = "Successfully get virtual account"
+const VirtualAccountNotFoundError = "Virtual account not found"
+const VirtualAccountInvalidError = "Virtual account number is not valid"
+
+const BankNotificationSuccess = "Bank notification processed"
+const BankNotificationDuplicateSuccess = "Bank notification was already processed"
+const BankNotificationSignatureError = "Bank notification signature is invalid"
+const BankNotificationExpiredError = "Bank notification timestamp is outside the accepted window"
+const BankNotificationInvalidError = "Bank notification must have an external ID, a virtual account number and a positive amount"
+
+const BillerProductFindSuccess = "Successfully get biller products"
+const BillerProductNotFoundError = "Biller product not found"
+const BillerProductInactiveError = "Biller product is not available"
+const BillerCustomerNumberError = "Customer number must be 8 to 16 digits"
+const BillerUnavailableError = "Biller is temporarily unavailable"
+
+const BillPaymentCreateSuccess = "Successfully paid the bill"
+const BillPaymentFindSuccess = "Successfully get bill payment"
+const BillPaymentNotFoundError = "Bill payment not found"
+const BillPaymentForbiddenAccess = "User does not have permission to access this bill payment"
+
+const WebhookEndpointCreateSuccess = "Successfully created a webhook endpoint"
+const WebhookEndpointFindSuccess = "Successfully get webhook endpoints"
+const WebhookEndpointDeleteSuccess = "Successfully deleted the webhook endpoint"
+const WebhookEndpointNotFoundError = "Webhook endpoint not found"
+const WebhookEndpointForbiddenAccess = "User does not have permission to access this webhook endpoint"
+const WebhookEndpointUrlError = "Webhook URL must be an absolute http or https URL of a public host"
+const WebhookEventTypeError = "Webhook events must be known event types"
+const WebhookDeliveryFindSuccess = "Successfully get webhook deliveries"
+const WebhookRedeliverSuccess = "Webhook delivery was sent again"
+const WebhookDeliveryNotFoundError = "Webhook delivery not found"
+const WebhookDeliveryInProgressError = "Webhook delivery is being attempted already"
+const WebhookAddressNotPublicError = "Webhook URL resolves to an address that is not public"
+const OutboxEventNotFoundError = "Outbox event not found"
+
+const StatementFindSuccess = "Successfully get statement"
+const StatementPeriodError = "Statement period must be a month as YYYY-MM or a from and to date as YYYY-MM-DD"
+const StatementPeriodTooLongError = "Statement period must not be longer than a year"
+const StatementFormatError = "Statement format must be json, csv, ofx, pdf or camt053"
+
+const LedgerPrevHashMismatch = "Previous hash does not match the hash of the preceding record"
+const LedgerHashMismatch = "Record hash does not match its content"
+const LedgerCheckpointSignatureInvalid = "Checkpoint signature is invalid"
+const LedgerTruncated = "Transaction log is shorter than a signed checkpoint"
+const LedgerCheckpointHeadMismatch = "Transaction log does not match the head hash of a signed checkpoint"
@@ -3,7 +3,28 @@ package constants
 // This file used to store path of file as a constant
 const CustomerJsonPath = "./storage/customers.json"
 const RefreshTokenJsonPath = "./storage/refresh_token.json"
+const SessionJsonPath = "./storage/sessions.json"
 const BlacklistJsonPath = "./storage/blacklist.json"
+const TokenWatermarkJsonPath = "./storage/token_watermarks.json"
 const WalletJsonPath = "./storage/wallets.json"
 const TransactionJsonPath = "./storage/transactions.json"
+const HoldJsonPath = "./storage/holds.json"
+const PaymentRequestJsonPath = "./storage/payment_requests.json"
+const SplitBillJsonPath = "./storage/split_bills.json"
+const PayoutBatchJsonPath = "./storage/payout_batches.json"
+const MerchantJsonPath = "./storage/merchants.json"
+const CheckoutSessionJsonPath = "./storage/checkout_sessions.json"
+const VirtualAccountJsonPath = "./storage/virtual_accounts.json"
+const BillerProductJsonPath = "./storage/biller_products.json"
+const BillPaymentJsonPath = "./storage/bill_payments.json"
+const WebhookEndpointJsonPath = "./storage/webhook_endpoints.json"
+const WebhookDeliveryJsonPath = "./storage/webhook_deliveries.json"
+const OutboxEventJsonPath = "./storage/outbox_events.json"
+const PasswordResetTokenJsonPath = "./storage/password_reset_tokens.json"
+const LoginAttemptJsonPath = "./storage/login_attempts.json"
+const TwoFactorJsonPath = "./storage/two_factors.json"
+const LoginChallengeJsonPath = "./storage/login_challenges.json"
+const TransactionPinJsonPath = "./storage/transaction_pins.json"
+const LedgerCheckpointJsonPath = "./storage/ledger_checkpoints.json"
+const ReconciliationReportJsonPath = "./storage/reconciliation_reports.json"
 const LogJsonPath = "./logger/log.txt"
@@ -0,0 +1,28 @@
+package dto
+
+type BankNotificationRequest struct {
+	ExternalId           string  `json:"external_id"`
+	VirtualAccountNumber string  `json:"virtual_account_number"`
+	Amount               float64 `json:"amount"`
+	PayerName            string  `json:"payer_name"`
+	PaidAt               string  `json:"paid_at"`
+}
+
+type CreateDepositRequest struct {
+	FromAccount string
+	ToWalletId  string
+	Amount      float64
+	ExternalId  string
+	Message     string
+	// Refund returns funds that were debited from the wallet, it is credited even while the wallet is frozen
+	Refund bool
+}
+
+type CreateWithdrawalRequest struct {
+	FromWalletId string
+	ToAccount    string
+	Amount       float64
+	ExternalId   string
+	Message      string
+	StepUp
+}
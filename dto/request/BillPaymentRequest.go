@@ -0,0 +1,8 @@
+package dto
+
+type CreateBillPaymentRequest struct {
+	WalletId       string `json:"wallet_id"`
+	ProductCode    string `json:"product_code"`
+	CustomerNumber string `json:"customer_number"`
+	StepUp
+}
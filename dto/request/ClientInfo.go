@@ -0,0 +1,9 @@
+package dto
+
+// ClientInfo describes the client a login or refresh comes from. It is not read from a request body, handlers fill
+// it in from the connection and headers.
+type ClientInfo struct {
+	Ip         string
+	UserAgent  string
+	DeviceName string
+}
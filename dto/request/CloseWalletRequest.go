@@ -0,0 +1,6 @@
+package dto
+
+type CloseWalletRequest struct {
+	PayoutWalletId string `json:"payout_wallet_id"`
+	StepUp
+}
@@ -0,0 +1,9 @@
+package dto
+
+type CreatePaymentRequestRequest struct {
+	RequesterWalletId string  `json:"requester_wallet_id"`
+	PayerWalletId     string  `json:"payer_wallet_id"`
+	Amount            float64 `json:"amount"`
+	Message           string  `json:"message"`
+	SplitBillId       string  `json:"-"`
+}
@@ -0,0 +1,16 @@
+package dto
+
+import "PaymentAPI/enums"
+
+type CreateSplitBillRequest struct {
+	CreatorWalletId string                        `json:"creator_wallet_id"`
+	TotalAmount     float64                       `json:"total_amount"`
+	Message         string                        `json:"message"`
+	SplitType       enums.SplitType               `json:"split_type"`
+	Participants    []SplitBillParticipantRequest `json:"participants"`
+}
+
+type SplitBillParticipantRequest struct {
+	WalletId string  `json:"wallet_id"`
+	Amount   float64 `json:"amount"`
+}
@@ -5,4 +5,5 @@ type CreateTransactionRequest struct {
 	ToWalletId   string  `json:"to_wallet_id"`
 	Amount       float64 `json:"amount"`
 	Message      string  `json:"message"`
+	StepUp
 }
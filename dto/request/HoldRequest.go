@@ -0,0 +1,14 @@
+package dto
+
+type CreateHoldRequest struct {
+	WalletId         string  `json:"wallet_id"`
+	ToWalletId       string  `json:"to_wallet_id"`
+	Amount           float64 `json:"amount"`
+	Message          string  `json:"message"`
+	ExpiresInMinutes int     `json:"expires_in_minutes"`
+	StepUp
+}
+
+type CaptureHoldRequest struct {
+	Amount float64 `json:"amount"`
+}
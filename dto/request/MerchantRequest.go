@@ -0,0 +1,13 @@
+package dto
+
+type CreateMerchantRequest struct {
+	Name        string `json:"name"`
+	CallbackUrl string `json:"callback_url"`
+}
+
+type CreateCheckoutSessionRequest struct {
+	Amount           float64 `json:"amount"`
+	Reference        string  `json:"reference"`
+	Description      string  `json:"description"`
+	ExpiresInMinutes int     `json:"expires_in_minutes"`
+}
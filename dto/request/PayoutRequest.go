@@ -0,0 +1,13 @@
+package dto
+
+type CreatePayoutBatchRequest struct {
+	WalletId string              `json:"wallet_id"`
+	Items    []PayoutItemRequest `json:"items"`
+	StepUp
+}
+
+type PayoutItemRequest struct {
+	RecipientWalletId string  `json:"recipient_wallet_id"`
+	Amount            float64 `json:"amount"`
+	Message           string  `json:"message"`
+}
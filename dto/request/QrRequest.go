@@ -0,0 +1,13 @@
+package dto
+
+type GenerateWalletQrRequest struct {
+	WalletId string  `json:"wallet_id"`
+	Amount   float64 `json:"amount"`
+}
+
+type PayQrRequest struct {
+	Payload string  `json:"payload"`
+	Amount  float64 `json:"amount"`
+	Message string  `json:"message"`
+	StepUp
+}
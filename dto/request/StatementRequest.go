@@ -0,0 +1,8 @@
+package dto
+
+// StatementRequest selects the period of a statement, either a calendar month or a range of days
+type StatementRequest struct {
+	Month string `json:"month"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
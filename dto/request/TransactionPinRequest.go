@@ -0,0 +1,17 @@
+package dto
+
+type SetTransactionPinRequest struct {
+	Password string `json:"password"`
+	Pin      string `json:"pin"`
+	Code     string `json:"code,omitempty"`
+}
+
+// StepUp is sent with every request that debits a wallet, the transaction PIN or a two-factor code is required above
+// the step-up threshold
+type StepUp struct {
+	Pin      string `json:"pin,omitempty"`
+	TotpCode string `json:"totp_code,omitempty"`
+	// Authorized marks a debit whose operation was already stepped up as a whole, such as a row of a payout batch or
+	// the capture of a hold, it is never read from a request body
+	Authorized bool `json:"-"`
+}
@@ -0,0 +1,6 @@
+package dto
+
+type UnlockLoginRequest struct {
+	Username string `json:"username"`
+	Ip       string `json:"ip"`
+}
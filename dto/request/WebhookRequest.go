@@ -0,0 +1,8 @@
+package dto
+
+import "PaymentAPI/enums"
+
+type CreateWebhookEndpointRequest struct {
+	Url    string            `json:"url"`
+	Events []enums.EventType `json:"events"`
+}
@@ -1,7 +1,12 @@
 package dto
 
+// AuthResponse carries the tokens of a login. When two-factor authentication is enabled the password step only
+// returns a challenge token, exchanged for the access and refresh tokens with a valid code.
 type AuthResponse struct {
-	AccessToken  string `json:"access_token"`
-	RefreshToken string `json:"refresh_token"`
-	CustomerId   string `json:"customer_id"`
+	AccessToken        string `json:"access_token,omitempty"`
+	RefreshToken       string `json:"refresh_token,omitempty"`
+	CustomerId         string `json:"customer_id"`
+	TwoFactorRequired  bool   `json:"two_factor_required,omitempty"`
+	ChallengeToken     string `json:"challenge_token,omitempty"`
+	ChallengeExpiresAt string `json:"challenge_expires_at,omitempty"`
 }
@@ -1,8 +1,12 @@
 package dto
 
+import "PaymentAPI/enums"
+
 type CustomerResponse struct {
-	Id       string  `json:"id"`
-	Username string  `json:"username"`
-	WalletId string  `json:"wallet_id"`
-	Balance  float64 `json:"balance"`
+	Id               string             `json:"id"`
+	Username         string             `json:"username"`
+	WalletId         string             `json:"wallet_id"`
+	Balance          float64            `json:"balance"`
+	AvailableBalance float64            `json:"available_balance"`
+	WalletStatus     enums.WalletStatus `json:"wallet_status"`
 }
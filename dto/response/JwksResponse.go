@@ -0,0 +1,18 @@
+package dto
+
+// JwksResponse is the JSON Web Key Set (RFC 7517) of the public keys access tokens are verified with
+type JwksResponse struct {
+	Keys []JsonWebKey `json:"keys"`
+}
+
+// JsonWebKey is a public RSA or Ed25519 key, N and E are set for RSA and Crv and X for Ed25519
+type JsonWebKey struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
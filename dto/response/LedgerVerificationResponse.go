@@ -0,0 +1,11 @@
+package dto
+
+type LedgerVerificationResponse struct {
+	Valid               bool   `json:"valid"`
+	TransactionCount    int    `json:"transaction_count"`
+	HeadHash            string `json:"head_hash"`
+	CheckpointsVerified int    `json:"checkpoints_verified"`
+	BrokenAtIndex       *int   `json:"broken_at_index,omitempty"`
+	BrokenTransactionId string `json:"broken_transaction_id,omitempty"`
+	Reason              string `json:"reason,omitempty"`
+}
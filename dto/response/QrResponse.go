@@ -0,0 +1,12 @@
+package dto
+
+type QrPayloadResponse struct {
+	Payload string `json:"payload"`
+}
+
+type QrPaymentResponse struct {
+	TransactionId     string  `json:"transaction_id"`
+	ToWalletId        string  `json:"to_wallet_id"`
+	Amount            float64 `json:"amount"`
+	CheckoutSessionId string  `json:"checkout_session_id,omitempty"`
+}
@@ -0,0 +1,16 @@
+package dto
+
+type SessionResponse struct {
+	Id         string `json:"id"`
+	DeviceName string `json:"device_name,omitempty"`
+	Ip         string `json:"ip"`
+	UserAgent  string `json:"user_agent"`
+	CreatedAt  string `json:"created_at"`
+	LastUsedAt string `json:"last_used_at"`
+	ExpiresAt  string `json:"expires_at"`
+	Current    bool   `json:"current"`
+}
+
+type RevokedSessionsResponse struct {
+	Revoked int `json:"revoked"`
+}
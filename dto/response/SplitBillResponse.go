@@ -0,0 +1,23 @@
+package dto
+
+import "PaymentAPI/enums"
+
+type SplitBillResponse struct {
+	Id                string                         `json:"id"`
+	CreatorWalletId   string                         `json:"creator_wallet_id"`
+	TotalAmount       float64                        `json:"total_amount"`
+	Message           string                         `json:"message"`
+	SplitType         enums.SplitType                `json:"split_type"`
+	Status            enums.SplitBillStatus          `json:"status"`
+	PaidAmount        float64                        `json:"paid_amount"`
+	OutstandingAmount float64                        `json:"outstanding_amount"`
+	Participants      []SplitBillParticipantResponse `json:"participants"`
+	CreatedAt         string                         `json:"created_at"`
+}
+
+type SplitBillParticipantResponse struct {
+	WalletId         string                     `json:"wallet_id"`
+	Amount           float64                    `json:"amount"`
+	PaymentRequestId string                     `json:"payment_request_id"`
+	Status           enums.PaymentRequestStatus `json:"status"`
+}
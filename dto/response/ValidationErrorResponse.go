@@ -0,0 +1,12 @@
+package dto
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+type ValidationErrorResponse struct {
+	StatusCode   int          `json:"status_code"`
+	ErrorMessage string       `json:"error_message"`
+	Errors       []FieldError `json:"errors"`
+}
@@ -0,0 +1,12 @@
+package dto
+
+type VirtualAccountResponse struct {
+	Number   string `json:"number"`
+	WalletId string `json:"wallet_id"`
+}
+
+type BankNotificationResponse struct {
+	ExternalId    string `json:"external_id"`
+	TransactionId string `json:"transaction_id"`
+	Duplicate     bool   `json:"duplicate"`
+}
@@ -0,0 +1,12 @@
+package dto
+
+import "PaymentAPI/enums"
+
+// WebhookEndpointResponse hides the signing secret, which is only returned when the endpoint is created
+type WebhookEndpointResponse struct {
+	Id        string            `json:"id"`
+	Url       string            `json:"url"`
+	Events    []enums.EventType `json:"events"`
+	Secret    string            `json:"secret,omitempty"`
+	CreatedAt string            `json:"created_at"`
+}
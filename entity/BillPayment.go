@@ -0,0 +1,22 @@
+package entity
+
+import "PaymentAPI/enums"
+
+type BillPayment struct {
+	Id                  string                  `json:"id"`
+	WalletId            string                  `json:"wallet_id"`
+	ProductCode         string                  `json:"product_code"`
+	BillerCode          string                  `json:"biller_code"`
+	CustomerNumber      string                  `json:"customer_number"`
+	Price               float64                 `json:"price"`
+	Fee                 float64                 `json:"fee"`
+	TotalAmount         float64                 `json:"total_amount"`
+	Status              enums.BillPaymentStatus `json:"status"`
+	SerialNumber        string                  `json:"serial_number,omitempty"`
+	Voucher             string                  `json:"voucher,omitempty"`
+	FailureReason       string                  `json:"failure_reason,omitempty"`
+	TransactionId       string                  `json:"transaction_id"`
+	RefundTransactionId string                  `json:"refund_transaction_id,omitempty"`
+	CreatedAt           string                  `json:"created_at"`
+	CompletedAt         string                  `json:"completed_at"`
+}
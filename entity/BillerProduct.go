@@ -0,0 +1,14 @@
+package entity
+
+import "PaymentAPI/enums"
+
+type BillerProduct struct {
+	Code       string               `json:"code"`
+	BillerCode string               `json:"biller_code"`
+	BillerName string               `json:"biller_name"`
+	Category   enums.BillerCategory `json:"category"`
+	Name       string               `json:"name"`
+	Price      float64              `json:"price"`
+	Fee        float64              `json:"fee"`
+	Active     bool                 `json:"active"`
+}
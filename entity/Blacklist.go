@@ -1,6 +1,8 @@
 package entity
 
+// Blacklist revokes a single access token by its JWT ID. The entry is only needed until the token expires.
 type Blacklist struct {
-	AccessToken string `json:"access_token"`
-	ExpiresAt   string `json:"expires_at"`
+	TokenId    string `json:"jti"`
+	CustomerId string `json:"customer_id"`
+	ExpiresAt  string `json:"expires_at"`
 }
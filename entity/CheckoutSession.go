@@ -0,0 +1,17 @@
+package entity
+
+import "PaymentAPI/enums"
+
+type CheckoutSession struct {
+	Id            string                      `json:"id"`
+	MerchantId    string                      `json:"merchant_id"`
+	Amount        float64                     `json:"amount"`
+	Reference     string                      `json:"reference"`
+	Description   string                      `json:"description"`
+	Status        enums.CheckoutSessionStatus `json:"status"`
+	PayerWalletId string                      `json:"payer_wallet_id"`
+	TransactionId string                      `json:"transaction_id"`
+	CreatedAt     string                      `json:"created_at"`
+	ExpiresAt     string                      `json:"expires_at"`
+	PaidAt        string                      `json:"paid_at"`
+}
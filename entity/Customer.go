@@ -1,7 +1,10 @@
 package entity
 
+import "PaymentAPI/enums"
+
 type Customer struct {
-	Id       string `json:"id"`
-	Username string `json:"username"`
-	Password string `json:"password"`
+	Id       string     `json:"id"`
+	Username string     `json:"username"`
+	Password string     `json:"password"`
+	Role     enums.Role `json:"role,omitempty"`
 }
@@ -0,0 +1,17 @@
+package entity
+
+import "PaymentAPI/enums"
+
+type Hold struct {
+	Id             string           `json:"id"`
+	WalletId       string           `json:"wallet_id"`
+	ToWalletId     string           `json:"to_wallet_id"`
+	Amount         float64          `json:"amount"`
+	CapturedAmount float64          `json:"captured_amount"`
+	Message        string           `json:"message"`
+	Status         enums.HoldStatus `json:"status"`
+	TransactionId  string           `json:"transaction_id"`
+	CreatedAt      string           `json:"created_at"`
+	ExpiresAt      string           `json:"expires_at"`
+	UpdatedAt      string           `json:"updated_at"`
+}
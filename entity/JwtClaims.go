@@ -7,6 +7,7 @@ import (
 
 type JwtClaims struct {
 	jwt.RegisteredClaims
-	Username string     `json:"username"`
-	Role     enums.Role `json:"role"`
+	Username  string     `json:"username"`
+	Role      enums.Role `json:"role"`
+	SessionId string     `json:"sid,omitempty"`
 }
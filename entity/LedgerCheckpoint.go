@@ -0,0 +1,8 @@
+package entity
+
+type LedgerCheckpoint struct {
+	Sequence  int    `json:"sequence"`
+	HeadHash  string `json:"head_hash"`
+	CreatedAt string `json:"created_at"`
+	Signature string `json:"signature"`
+}
@@ -0,0 +1,14 @@
+package entity
+
+import "PaymentAPI/enums"
+
+// LoginAttempt counts the recent failed logins for a username or a client IP. Usernames are tracked whether or not
+// they exist, so a lockout does not reveal which ones do.
+type LoginAttempt struct {
+	Id            string                 `json:"id"`
+	Type          enums.LoginAttemptType `json:"type"`
+	Subject       string                 `json:"subject"`
+	Failures      int                    `json:"failures"`
+	LastFailureAt string                 `json:"last_failure_at"`
+	LockedUntil   string                 `json:"locked_until,omitempty"`
+}
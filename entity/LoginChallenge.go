@@ -0,0 +1,13 @@
+package entity
+
+// LoginChallenge is handed out after the password step of a login with two-factor authentication enabled. It is
+// stored by the SHA-256 hash of its token and exchanged for access and refresh tokens with a valid code.
+type LoginChallenge struct {
+	Id         string `json:"id"`
+	TokenHash  string `json:"token_hash"`
+	CustomerId string `json:"customer_id"`
+	DeviceName string `json:"device_name,omitempty"`
+	Attempts   int    `json:"attempts"`
+	ExpiresAt  string `json:"expires_at"`
+	CreatedAt  string `json:"created_at"`
+}
@@ -0,0 +1,13 @@
+package entity
+
+type Merchant struct {
+	Id                 string `json:"id"`
+	CustomerId         string `json:"customer_id"`
+	WalletId           string `json:"wallet_id"`
+	Name               string `json:"name"`
+	CallbackUrl        string `json:"callback_url"`
+	CallbackEndpointId string `json:"callback_endpoint_id,omitempty"`
+	// CallbackSecret signs the callbacks, it is only returned when the merchant is created and never stored with it
+	CallbackSecret string `json:"callback_secret,omitempty"`
+	CreatedAt      string `json:"created_at"`
+}
@@ -0,0 +1,22 @@
+package entity
+
+import (
+	"PaymentAPI/enums"
+	"encoding/json"
+)
+
+// OutboxEvent is a domain event recorded with the state change it describes. It is prepared before the change is
+// stored and pending once the change is, until every subscriber handled it. HandledBy remembers which ones did, so a
+// redelivery after a failure or restart skips them. Dispatched events are removed from the outbox.
+type OutboxEvent struct {
+	Id           string                  `json:"id"`
+	Type         enums.EventType         `json:"type"`
+	WalletIds    []string                `json:"wallet_ids"`
+	Payload      json.RawMessage         `json:"payload"`
+	Status       enums.OutboxEventStatus `json:"status"`
+	HandledBy    []string                `json:"handled_by"`
+	Attempts     int                     `json:"attempts"`
+	LastError    string                  `json:"last_error,omitempty"`
+	CreatedAt    string                  `json:"created_at"`
+	DispatchedAt string                  `json:"dispatched_at"`
+}
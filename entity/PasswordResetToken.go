@@ -0,0 +1,12 @@
+package entity
+
+// PasswordResetToken is stored by the SHA-256 hash of the token sent to the customer, so the storage file alone
+// cannot be used to reset a password
+type PasswordResetToken struct {
+	Id         string `json:"id"`
+	TokenHash  string `json:"token_hash"`
+	CustomerId string `json:"customer_id"`
+	ExpiresAt  string `json:"expires_at"`
+	UsedAt     string `json:"used_at,omitempty"`
+	CreatedAt  string `json:"created_at"`
+}
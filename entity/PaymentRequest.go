@@ -0,0 +1,17 @@
+package entity
+
+import "PaymentAPI/enums"
+
+type PaymentRequest struct {
+	Id                string                     `json:"id"`
+	RequesterWalletId string                     `json:"requester_wallet_id"`
+	PayerWalletId     string                     `json:"payer_wallet_id"`
+	Amount            float64                    `json:"amount"`
+	Message           string                     `json:"message"`
+	Status            enums.PaymentRequestStatus `json:"status"`
+	TransactionId     string                     `json:"transaction_id"`
+	CreatedAt         string                     `json:"created_at"`
+	ExpiresAt         string                     `json:"expires_at"`
+	RespondedAt       string                     `json:"responded_at"`
+	SplitBillId       string                     `json:"split_bill_id,omitempty"`
+}
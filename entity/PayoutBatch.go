@@ -0,0 +1,26 @@
+package entity
+
+import "PaymentAPI/enums"
+
+type PayoutBatch struct {
+	Id             string                  `json:"id"`
+	WalletId       string                  `json:"wallet_id"`
+	Status         enums.PayoutBatchStatus `json:"status"`
+	TotalAmount    float64                 `json:"total_amount"`
+	SucceededCount int                     `json:"succeeded_count"`
+	FailedCount    int                     `json:"failed_count"`
+	ErrorMessage   string                  `json:"error_message,omitempty"`
+	Items          []PayoutItem            `json:"items"`
+	CreatedAt      string                  `json:"created_at"`
+	CompletedAt    string                  `json:"completed_at"`
+}
+
+type PayoutItem struct {
+	Row               int                    `json:"row"`
+	RecipientWalletId string                 `json:"recipient_wallet_id"`
+	Amount            float64                `json:"amount"`
+	Message           string                 `json:"message"`
+	Status            enums.PayoutItemStatus `json:"status"`
+	TransactionId     string                 `json:"transaction_id,omitempty"`
+	ErrorMessage      string                 `json:"error_message,omitempty"`
+}
@@ -0,0 +1,21 @@
+package entity
+
+type ReconciliationReport struct {
+	Id                     string              `json:"id"`
+	GeneratedAt            string              `json:"generated_at"`
+	WalletCount            int                 `json:"wallet_count"`
+	TransactionCount       int                 `json:"transaction_count"`
+	Balanced               bool                `json:"balanced"`
+	TotalStoredBalance     float64             `json:"total_stored_balance"`
+	TotalExternalInflow    float64             `json:"total_external_inflow"`
+	TotalExternalOutflow   float64             `json:"total_external_outflow"`
+	ConservationDifference float64             `json:"conservation_difference"`
+	Discrepancies          []WalletDiscrepancy `json:"discrepancies"`
+}
+
+type WalletDiscrepancy struct {
+	WalletId        string  `json:"wallet_id"`
+	StoredBalance   float64 `json:"stored_balance"`
+	ReplayedBalance float64 `json:"replayed_balance"`
+	Difference      float64 `json:"difference"`
+}
@@ -1,7 +1,13 @@
 package entity
 
+// RefreshToken is stored by the SHA-256 hash of its token, the token itself is only known when it is issued. Every
+// token of a session is one rotation family: a rotated token is kept, marked as rotated, until it expires, so a replay
+// of it can be told apart from an unknown token.
 type RefreshToken struct {
-	RefreshToken string `json:"refresh_token"`
+	RefreshToken string `json:"-"`
+	TokenHash    string `json:"token_hash"`
 	CustomerId   string `json:"customer_id"`
+	SessionId    string `json:"session_id,omitempty"`
 	ExpiresAt    string `json:"expires_at"`
+	RotatedAt    string `json:"rotated_at,omitempty"`
 }
@@ -0,0 +1,14 @@
+package entity
+
+// Session is one login of a customer on a device. Every refresh token belongs to a session, so a customer can stay
+// logged in on several devices and revoke them one by one.
+type Session struct {
+	Id         string `json:"id"`
+	CustomerId string `json:"customer_id"`
+	DeviceName string `json:"device_name,omitempty"`
+	Ip         string `json:"ip"`
+	UserAgent  string `json:"user_agent"`
+	CreatedAt  string `json:"created_at"`
+	LastUsedAt string `json:"last_used_at"`
+	ExpiresAt  string `json:"expires_at"`
+}
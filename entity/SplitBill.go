@@ -0,0 +1,19 @@
+package entity
+
+import "PaymentAPI/enums"
+
+type SplitBill struct {
+	Id              string                 `json:"id"`
+	CreatorWalletId string                 `json:"creator_wallet_id"`
+	TotalAmount     float64                `json:"total_amount"`
+	Message         string                 `json:"message"`
+	SplitType       enums.SplitType        `json:"split_type"`
+	Participants    []SplitBillParticipant `json:"participants"`
+	CreatedAt       string                 `json:"created_at"`
+}
+
+type SplitBillParticipant struct {
+	WalletId         string  `json:"wallet_id"`
+	Amount           float64 `json:"amount"`
+	PaymentRequestId string  `json:"payment_request_id"`
+}
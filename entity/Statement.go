@@ -0,0 +1,30 @@
+package entity
+
+import "PaymentAPI/enums"
+
+// Statement lists the transactions of a wallet over a period, with the balance before the period, after every
+// transaction and at the end of it. Balances are worked back from the stored wallet balance through the transactions.
+type Statement struct {
+	WalletId       string           `json:"wallet_id"`
+	CustomerId     string           `json:"customer_id"`
+	Currency       string           `json:"currency"`
+	PeriodStart    string           `json:"period_start"`
+	PeriodEnd      string           `json:"period_end"`
+	OpeningBalance float64          `json:"opening_balance"`
+	TotalCredits   float64          `json:"total_credits"`
+	TotalDebits    float64          `json:"total_debits"`
+	ClosingBalance float64          `json:"closing_balance"`
+	Entries        []StatementEntry `json:"entries"`
+	GeneratedAt    string           `json:"generated_at"`
+}
+
+// StatementEntry is one transaction of a statement, Amount is negative for a debit
+type StatementEntry struct {
+	TransactionId string                   `json:"transaction_id"`
+	BookedAt      string                   `json:"booked_at"`
+	Type          enums.StatementEntryType `json:"type"`
+	Counterparty  string                   `json:"counterparty"`
+	Description   string                   `json:"description"`
+	Amount        float64                  `json:"amount"`
+	Balance       float64                  `json:"balance"`
+}
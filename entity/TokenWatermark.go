@@ -0,0 +1,9 @@
+package entity
+
+// TokenWatermark revokes every access token of a customer issued before IssuedBefore, as on logout from all devices.
+// Access tokens carry their issue time in whole seconds, so IssuedBefore is kept in whole seconds too and a token issued
+// in the same second is revoked as well.
+type TokenWatermark struct {
+	CustomerId   string `json:"customer_id"`
+	IssuedBefore string `json:"issued_before"`
+}
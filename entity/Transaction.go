@@ -7,4 +7,7 @@ type Transaction struct {
 	CreatedAt    string  `json:"created_at"`
 	Amount       float64 `json:"amount"`
 	Message      string  `json:"message"`
+	ExternalId   string  `json:"external_id,omitempty"`
+	PrevHash     string  `json:"prev_hash"`
+	Hash         string  `json:"hash"`
 }
@@ -0,0 +1,11 @@
+package entity
+
+// TransactionPin holds the hashed transaction PIN of a customer and the failed step-up attempts that lock their
+// transfers. A record without a PIN hash only counts failed two-factor step-ups.
+type TransactionPin struct {
+	CustomerId  string `json:"customer_id"`
+	PinHash     string `json:"pin_hash,omitempty"`
+	Failures    int    `json:"failures"`
+	LockedUntil string `json:"locked_until,omitempty"`
+	UpdatedAt   string `json:"updated_at"`
+}
@@ -0,0 +1,13 @@
+package entity
+
+// TwoFactor is the TOTP setup of a customer. It is pending until a first code is verified. Recovery codes are stored
+// by their SHA-256 hash and removed once used.
+type TwoFactor struct {
+	CustomerId         string   `json:"customer_id"`
+	Secret             string   `json:"secret"`
+	Enabled            bool     `json:"enabled"`
+	RecoveryCodeHashes []string `json:"recovery_code_hashes"`
+	LastUsedStep       int64    `json:"last_used_step"`
+	CreatedAt          string   `json:"created_at"`
+	EnabledAt          string   `json:"enabled_at,omitempty"`
+}
@@ -0,0 +1,7 @@
+package entity
+
+type VirtualAccount struct {
+	Number    string `json:"number"`
+	WalletId  string `json:"wallet_id"`
+	CreatedAt string `json:"created_at"`
+}
@@ -1,7 +1,10 @@
 package entity
 
+import "PaymentAPI/enums"
+
 type Wallet struct {
-	Id         string  `json:"id"`
-	CustomerId string  `json:"customer_id"`
-	Balance    float64 `json:"balance"`
+	Id         string             `json:"id"`
+	CustomerId string             `json:"customer_id"`
+	Balance    float64            `json:"balance"`
+	Status     enums.WalletStatus `json:"status"`
 }
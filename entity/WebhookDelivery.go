@@ -0,0 +1,22 @@
+package entity
+
+import (
+	"PaymentAPI/enums"
+	"encoding/json"
+)
+
+type WebhookDelivery struct {
+	Id             string                      `json:"id"`
+	EndpointId     string                      `json:"endpoint_id"`
+	EventId        string                      `json:"event_id"`
+	EventType      enums.EventType             `json:"event_type"`
+	Payload        json.RawMessage             `json:"payload"`
+	Status         enums.WebhookDeliveryStatus `json:"status"`
+	Attempts       int                         `json:"attempts"`
+	NextAttemptAt  string                      `json:"next_attempt_at"`
+	LastAttemptAt  string                      `json:"last_attempt_at"`
+	ResponseStatus int                         `json:"response_status"`
+	ErrorMessage   string                      `json:"error_message,omitempty"`
+	CreatedAt      string                      `json:"created_at"`
+	DeliveredAt    string                      `json:"delivered_at"`
+}
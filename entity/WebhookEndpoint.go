@@ -0,0 +1,12 @@
+package entity
+
+import "PaymentAPI/enums"
+
+type WebhookEndpoint struct {
+	Id         string            `json:"id"`
+	CustomerId string            `json:"customer_id"`
+	Url        string            `json:"url"`
+	Secret     string            `json:"secret"`
+	Events     []enums.EventType `json:"events"`
+	CreatedAt  string            `json:"created_at"`
+}
@@ -0,0 +1,23 @@
+package entity
+
+import (
+	"PaymentAPI/enums"
+	"encoding/json"
+)
+
+// WebhookEvent is the body posted to a webhook endpoint
+type WebhookEvent struct {
+	Id        string          `json:"id"`
+	Type      enums.EventType `json:"type"`
+	CreatedAt string          `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// RejectedTransaction is the data of a transaction.rejected event, the transfer that was attempted and why it was refused
+type RejectedTransaction struct {
+	FromWalletId string  `json:"from_wallet_id"`
+	ToWalletId   string  `json:"to_wallet_id"`
+	Amount       float64 `json:"amount"`
+	Message      string  `json:"message"`
+	Reason       string  `json:"reason"`
+}
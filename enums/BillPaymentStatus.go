@@ -0,0 +1,12 @@
+package enums
+
+type BillPaymentStatus string
+
+const (
+	BILL_PAYMENT_PENDING  BillPaymentStatus = "PENDING"
+	BILL_PAYMENT_SUCCESS  BillPaymentStatus = "SUCCESS"
+	BILL_PAYMENT_FAILED   BillPaymentStatus = "FAILED"
+	BILL_PAYMENT_REFUNDED BillPaymentStatus = "REFUNDED"
+	// BILL_PAYMENT_REFUND_FAILED is a failed payment whose wallet was closed before the refund, it is not retried
+	BILL_PAYMENT_REFUND_FAILED BillPaymentStatus = "REFUND_FAILED"
+)
@@ -0,0 +1,9 @@
+package enums
+
+type BillerCategory string
+
+const (
+	BILLER_PULSA       BillerCategory = "PULSA"
+	BILLER_DATA        BillerCategory = "DATA"
+	BILLER_ELECTRICITY BillerCategory = "ELECTRICITY"
+)
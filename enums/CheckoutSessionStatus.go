@@ -0,0 +1,9 @@
+package enums
+
+type CheckoutSessionStatus string
+
+const (
+	CHECKOUT_OPEN    CheckoutSessionStatus = "OPEN"
+	CHECKOUT_PAID    CheckoutSessionStatus = "PAID"
+	CHECKOUT_EXPIRED CheckoutSessionStatus = "EXPIRED"
+)
@@ -0,0 +1,34 @@
+package enums
+
+// EventType names a domain event, recorded in the outbox when the state change it describes happens
+type EventType string
+
+const (
+	EVENT_TRANSACTION_CREATED  EventType = "transaction.created"
+	EVENT_TRANSACTION_SETTLED  EventType = "transaction.settled"
+	EVENT_TRANSACTION_REJECTED EventType = "transaction.rejected"
+	EVENT_TRANSACTION_REFUNDED EventType = "transaction.refunded"
+	EVENT_WALLET_FROZEN        EventType = "wallet.frozen"
+	EVENT_WALLET_UNFROZEN      EventType = "wallet.unfrozen"
+	EVENT_WALLET_CLOSED        EventType = "wallet.closed"
+	EVENT_CHECKOUT_PAID        EventType = "checkout.paid"
+	EVENT_CHECKOUT_EXPIRED     EventType = "checkout.expired"
+)
+
+// EventTypes lists every domain event, which is also every event a webhook endpoint can subscribe to
+var EventTypes = []EventType{
+	EVENT_TRANSACTION_CREATED,
+	EVENT_TRANSACTION_SETTLED,
+	EVENT_TRANSACTION_REJECTED,
+	EVENT_TRANSACTION_REFUNDED,
+	EVENT_WALLET_FROZEN,
+	EVENT_WALLET_UNFROZEN,
+	EVENT_WALLET_CLOSED,
+}
+
+// CheckoutEventTypes lists the events sent to the callback of the merchant a checkout session belongs to. They are
+// not recorded in the outbox, so no other webhook endpoint can subscribe to them.
+var CheckoutEventTypes = []EventType{
+	EVENT_CHECKOUT_PAID,
+	EVENT_CHECKOUT_EXPIRED,
+}
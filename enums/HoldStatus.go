@@ -0,0 +1,10 @@
+package enums
+
+type HoldStatus string
+
+const (
+	HOLD_ACTIVE   HoldStatus = "ACTIVE"
+	HOLD_CAPTURED HoldStatus = "CAPTURED"
+	HOLD_VOIDED   HoldStatus = "VOIDED"
+	HOLD_EXPIRED  HoldStatus = "EXPIRED"
+)
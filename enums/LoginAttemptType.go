@@ -0,0 +1,8 @@
+package enums
+
+type LoginAttemptType string
+
+const (
+	LOGIN_ATTEMPT_USERNAME LoginAttemptType = "USERNAME"
+	LOGIN_ATTEMPT_IP       LoginAttemptType = "IP"
+)
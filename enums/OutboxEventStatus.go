@@ -0,0 +1,9 @@
+package enums
+
+type OutboxEventStatus string
+
+const (
+	OUTBOX_PREPARED   OutboxEventStatus = "PREPARED"
+	OUTBOX_PENDING    OutboxEventStatus = "PENDING"
+	OUTBOX_DISPATCHED OutboxEventStatus = "DISPATCHED"
+)
@@ -0,0 +1,10 @@
+package enums
+
+type PaymentRequestStatus string
+
+const (
+	PAYMENT_REQUEST_PENDING  PaymentRequestStatus = "PENDING"
+	PAYMENT_REQUEST_ACCEPTED PaymentRequestStatus = "ACCEPTED"
+	PAYMENT_REQUEST_DECLINED PaymentRequestStatus = "DECLINED"
+	PAYMENT_REQUEST_EXPIRED  PaymentRequestStatus = "EXPIRED"
+)
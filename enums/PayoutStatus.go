@@ -0,0 +1,20 @@
+package enums
+
+type PayoutBatchStatus string
+
+const (
+	PAYOUT_BATCH_REJECTED            PayoutBatchStatus = "REJECTED"
+	PAYOUT_BATCH_PROCESSING          PayoutBatchStatus = "PROCESSING"
+	PAYOUT_BATCH_COMPLETED           PayoutBatchStatus = "COMPLETED"
+	PAYOUT_BATCH_PARTIALLY_COMPLETED PayoutBatchStatus = "PARTIALLY_COMPLETED"
+	PAYOUT_BATCH_FAILED              PayoutBatchStatus = "FAILED"
+)
+
+type PayoutItemStatus string
+
+const (
+	PAYOUT_ITEM_PENDING   PayoutItemStatus = "PENDING"
+	PAYOUT_ITEM_INVALID   PayoutItemStatus = "INVALID"
+	PAYOUT_ITEM_SUCCEEDED PayoutItemStatus = "SUCCEEDED"
+	PAYOUT_ITEM_FAILED    PayoutItemStatus = "FAILED"
+)
@@ -0,0 +1,28 @@
+package enums
+
+type SecurityEventType string
+
+const (
+	SECURITY_LOGIN_FAILED     SecurityEventType = "LOGIN_FAILED"
+	SECURITY_LOGIN_THROTTLED  SecurityEventType = "LOGIN_THROTTLED"
+	SECURITY_ACCOUNT_LOCKED   SecurityEventType = "ACCOUNT_LOCKED"
+	SECURITY_IP_LOCKED        SecurityEventType = "IP_LOCKED"
+	SECURITY_ACCOUNT_UNLOCKED SecurityEventType = "ACCOUNT_UNLOCKED"
+	SECURITY_IP_UNLOCKED      SecurityEventType = "IP_UNLOCKED"
+
+	SECURITY_TWO_FACTOR_ENABLED         SecurityEventType = "TWO_FACTOR_ENABLED"
+	SECURITY_TWO_FACTOR_DISABLED        SecurityEventType = "TWO_FACTOR_DISABLED"
+	SECURITY_TWO_FACTOR_FAILED          SecurityEventType = "TWO_FACTOR_FAILED"
+	SECURITY_RECOVERY_CODE_USED         SecurityEventType = "RECOVERY_CODE_USED"
+	SECURITY_RECOVERY_CODES_REGENERATED SecurityEventType = "RECOVERY_CODES_REGENERATED"
+
+	SECURITY_TRANSACTION_PIN_SET    SecurityEventType = "TRANSACTION_PIN_SET"
+	SECURITY_TRANSACTION_PIN_FAILED SecurityEventType = "TRANSACTION_PIN_FAILED"
+	SECURITY_STEP_UP_FAILED         SecurityEventType = "STEP_UP_FAILED"
+	SECURITY_TRANSFERS_LOCKED       SecurityEventType = "TRANSFERS_LOCKED"
+
+	SECURITY_SESSION_REVOKED        SecurityEventType = "SESSION_REVOKED"
+	SECURITY_OTHER_SESSIONS_REVOKED SecurityEventType = "OTHER_SESSIONS_REVOKED"
+	SECURITY_ALL_SESSIONS_REVOKED   SecurityEventType = "ALL_SESSIONS_REVOKED"
+	SECURITY_REFRESH_TOKEN_REUSED   SecurityEventType = "REFRESH_TOKEN_REUSED"
+)
@@ -0,0 +1,9 @@
+package enums
+
+type SplitBillStatus string
+
+const (
+	SPLIT_BILL_OPEN       SplitBillStatus = "OPEN"
+	SPLIT_BILL_SETTLED    SplitBillStatus = "SETTLED"
+	SPLIT_BILL_INCOMPLETE SplitBillStatus = "INCOMPLETE"
+)
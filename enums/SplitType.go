@@ -0,0 +1,8 @@
+package enums
+
+type SplitType string
+
+const (
+	SPLIT_EQUAL  SplitType = "EQUAL"
+	SPLIT_CUSTOM SplitType = "CUSTOM"
+)
@@ -0,0 +1,8 @@
+package enums
+
+type StatementEntryType string
+
+const (
+	STATEMENT_CREDIT StatementEntryType = "CREDIT"
+	STATEMENT_DEBIT  StatementEntryType = "DEBIT"
+)
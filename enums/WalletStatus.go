@@ -0,0 +1,9 @@
+package enums
+
+type WalletStatus string
+
+const (
+	WALLET_ACTIVE WalletStatus = "ACTIVE"
+	WALLET_FROZEN WalletStatus = "FROZEN"
+	WALLET_CLOSED WalletStatus = "CLOSED"
+)
@@ -0,0 +1,9 @@
+package enums
+
+type WebhookDeliveryStatus string
+
+const (
+	WEBHOOK_DELIVERY_PENDING   WebhookDeliveryStatus = "PENDING"
+	WEBHOOK_DELIVERY_DELIVERED WebhookDeliveryStatus = "DELIVERED"
+	WEBHOOK_DELIVERY_FAILED    WebhookDeliveryStatus = "FAILED"
+)
@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"PaymentAPI/constants"
+	req "PaymentAPI/dto/request"
+	res "PaymentAPI/dto/response"
+	"PaymentAPI/service"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"net/http"
+)
+
+type AdminHandler interface {
+	HandleGetLoginLockouts(c *gin.Context)
+	HandleUnlockLogin(c *gin.Context)
+}
+
+type adminHandler struct {
+	loginAttemptService service.LoginAttemptService
+}
+
+// NewAdminHandler creates a new instance of AdminHandler.
+func NewAdminHandler(loginAttemptService service.LoginAttemptService) AdminHandler {
+	return &adminHandler{loginAttemptService}
+}
+
+// HandleGetLoginLockouts lists the usernames and client IPs currently locked out of logging in.
+func (a *adminHandler) HandleGetLoginLockouts(c *gin.Context) {
+	lockouts, err := a.loginAttemptService.GetLockouts()
+	if err != nil {
+		logrus.Errorf("Failed to retrieve login lockouts, error: %v", err)
+		c.JSON(http.StatusInternalServerError, res.ErrorResponse{
+			StatusCode:   http.StatusInternalServerError,
+			ErrorMessage: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, res.CommonResponse{
+		StatusCode: http.StatusOK,
+		Message:    constants.LoginLockoutFindSuccess,
+		Data:       lockouts,
+	})
+}
+
+// HandleUnlockLogin lifts the lockout of the username, the client IP, or both in the request body.
+func (a *adminHandler) HandleUnlockLogin(c *gin.Context) {
+	user, ok := getAuthenticatedUser(c)
+	if !ok {
+		return
+	}
+
+	var request req.UnlockLoginRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		logrus.Warnf("Invalid unlock login request: %v", err)
+		c.JSON(http.StatusBadRequest, res.ErrorResponse{
+			StatusCode:   http.StatusBadRequest,
+			ErrorMessage: constants.InvalidRequestBodyError,
+		})
+		return
+	}
+
+	if err := a.loginAttemptService.Unlock(user, request); err != nil {
+		logrus.Errorf("Failed to unlock login, error: %v", err)
+		status := http.StatusInternalServerError
+		if err.Error() == constants.LoginUnlockRequestError {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, res.ErrorResponse{
+			StatusCode:   status,
+			ErrorMessage: err.Error(),
+		})
+		return
+	}
+
+	logrus.Infof("Admin %s unlocked login for username %q and IP %q", user, request.Username, request.Ip)
+	c.JSON(http.StatusOK, res.CommonResponse{
+		StatusCode: http.StatusOK,
+		Message:    constants.LoginUnlockSuccess,
+		Data:       []interface{}{},
+	})
+}
@@ -15,8 +15,14 @@ import (
 type AuthHandler interface {
 	HandleRegister(c *gin.Context)
 	HandleLogin(c *gin.Context)
+	HandleLoginTwoFactor(c *gin.Context)
 	HandleLogout(c *gin.Context)
+	HandleLogoutAll(c *gin.Context)
 	HandleRefreshToken(c *gin.Context)
+	HandleChangePassword(c *gin.Context)
+	HandleRequestPasswordReset(c *gin.Context)
+	HandleResetPassword(c *gin.Context)
+	HandleJwks(c *gin.Context)
 }
 
 type authHandler struct {
@@ -46,6 +52,9 @@ func (a *authHandler) HandleRegister(c *gin.Context) {
 
 	if _, err := a.customerService.CreateNewCustomer(request); err != nil {
 		logger.Warn("Failed to create customer", "error", err)
+		if writePasswordPolicyError(c, err) {
+			return
+		}
 		switch err.Error() {
 		case constants.UsernameDuplicateError:
 			c.JSON(http.StatusBadRequest, res.ErrorResponse{StatusCode: http.StatusBadRequest, ErrorMessage: err.Error()})
@@ -79,9 +88,12 @@ func (a *authHandler) HandleLogin(c *gin.Context) {
 		return
 	}
 
-	login, err := a.authService.Login(request)
+	login, err := a.authService.Login(request, getClientInfo(c, request.DeviceName))
 	if err != nil {
 		logger.Warn("Login failed", "error", err)
+		if writeLoginThrottledError(c, err) {
+			return
+		}
 		switch err.Error() {
 		case constants.LoginUnauthorizedError:
 			c.JSON(http.StatusUnauthorized, res.ErrorResponse{StatusCode: http.StatusUnauthorized, ErrorMessage: err.Error()})
@@ -91,6 +103,17 @@ func (a *authHandler) HandleLogin(c *gin.Context) {
 		return
 	}
 
+	// No refresh token cookie yet, the challenge token is exchanged for the tokens with a two-factor code
+	if login.TwoFactorRequired {
+		logger.Info("Password accepted, two-factor code required")
+		c.JSON(http.StatusOK, res.CommonResponse{
+			StatusCode: http.StatusOK,
+			Message:    constants.TwoFactorChallengeSuccess,
+			Data:       login,
+		})
+		return
+	}
+
 	SetCookie(c, "refresh_token", login.RefreshToken, 24)
 	logger.Info("Login successful")
 	c.JSON(http.StatusOK, res.CommonResponse{
@@ -101,6 +124,46 @@ func (a *authHandler) HandleLogin(c *gin.Context) {
 	return
 }
 
+func (a *authHandler) HandleLoginTwoFactor(c *gin.Context) {
+	logger := logrus.WithFields(logrus.Fields{
+		"clientIP": c.ClientIP(),
+		"endpoint": "/login/2fa",
+	})
+
+	var request req.LoginTwoFactorRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		logger.Warn("Invalid request body", "error", err)
+		c.JSON(http.StatusBadRequest, res.ErrorResponse{
+			StatusCode:   http.StatusBadRequest,
+			ErrorMessage: constants.InvalidRequestBodyError,
+		})
+		return
+	}
+
+	login, err := a.authService.LoginWithTwoFactor(request, getClientInfo(c, ""))
+	if err != nil {
+		logger.Warn("Two-factor login failed", "error", err)
+		if writeLoginThrottledError(c, err) {
+			return
+		}
+		switch err.Error() {
+		case constants.LoginChallengeInvalidError, constants.TwoFactorCodeInvalidError:
+			c.JSON(http.StatusUnauthorized, res.ErrorResponse{StatusCode: http.StatusUnauthorized, ErrorMessage: err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, res.ErrorResponse{StatusCode: http.StatusInternalServerError, ErrorMessage: err.Error()})
+		return
+	}
+
+	SetCookie(c, "refresh_token", login.RefreshToken, 24)
+	logger.Info("Login successful")
+	c.JSON(http.StatusOK, res.CommonResponse{
+		StatusCode: http.StatusOK,
+		Message:    constants.LoginSuccess,
+		Data:       login,
+	})
+}
+
 func (a *authHandler) HandleLogout(c *gin.Context) {
 	logger := logrus.WithFields(logrus.Fields{
 		"clientIP": c.ClientIP(),
@@ -146,6 +209,35 @@ func (a *authHandler) HandleLogout(c *gin.Context) {
 	return
 }
 
+// HandleLogoutAll logs the authenticated user out of every device, the access token of the request included.
+func (a *authHandler) HandleLogoutAll(c *gin.Context) {
+	logger := logrus.WithFields(logrus.Fields{
+		"clientIP": c.ClientIP(),
+		"endpoint": "/logout-all",
+	})
+
+	user, ok := getAuthenticatedUser(c)
+	if !ok {
+		return
+	}
+
+	if err := a.authService.LogoutAll(user); err != nil {
+		logger.Error("Logout from all devices failed", "error", err)
+		c.JSON(http.StatusInternalServerError, res.ErrorResponse{
+			StatusCode:   http.StatusInternalServerError,
+			ErrorMessage: err.Error(),
+		})
+		return
+	}
+
+	logger.Info("Logout from all devices successful")
+	c.JSON(http.StatusOK, res.CommonResponse{
+		StatusCode: http.StatusOK,
+		Message:    constants.LogoutAllSuccess,
+		Data:       []interface{}{},
+	})
+}
+
 func (a *authHandler) HandleRefreshToken(c *gin.Context) {
 	logger := logrus.WithFields(logrus.Fields{
 		"clientIP": c.ClientIP(),
@@ -162,7 +254,7 @@ func (a *authHandler) HandleRefreshToken(c *gin.Context) {
 		return
 	}
 
-	login, err := a.authService.GetNewAccessToken(refreshToken)
+	login, err := a.authService.GetNewAccessToken(refreshToken, getClientInfo(c, ""))
 	if err != nil {
 		logger.Warn("Failed to refresh access token", "error", err)
 		c.JSON(http.StatusUnauthorized, res.ErrorResponse{
@@ -182,6 +274,127 @@ func (a *authHandler) HandleRefreshToken(c *gin.Context) {
 	return
 }
 
+func (a *authHandler) HandleChangePassword(c *gin.Context) {
+	logger := logrus.WithFields(logrus.Fields{
+		"clientIP": c.ClientIP(),
+		"endpoint": "/change-password",
+	})
+
+	user, ok := getAuthenticatedUser(c)
+	if !ok {
+		return
+	}
+
+	var request req.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		logger.Warn("Invalid request body", "error", err)
+		c.JSON(http.StatusBadRequest, res.ErrorResponse{
+			StatusCode:   http.StatusBadRequest,
+			ErrorMessage: constants.InvalidRequestBodyError,
+		})
+		return
+	}
+
+	if err := a.authService.ChangePassword(user, request, getClientInfo(c, "")); err != nil {
+		logger.Warn("Password change failed", "error", err)
+		if writeLoginThrottledError(c, err) || writePasswordPolicyError(c, err) {
+			return
+		}
+		switch err.Error() {
+		case constants.PasswordIncorrectError:
+			c.JSON(http.StatusUnauthorized, res.ErrorResponse{StatusCode: http.StatusUnauthorized, ErrorMessage: err.Error()})
+			return
+		case constants.PasswordUnchangedError:
+			c.JSON(http.StatusBadRequest, res.ErrorResponse{StatusCode: http.StatusBadRequest, ErrorMessage: err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, res.ErrorResponse{StatusCode: http.StatusInternalServerError, ErrorMessage: err.Error()})
+		return
+	}
+
+	logger.Info("Password changed successfully")
+	c.JSON(http.StatusOK, res.CommonResponse{
+		StatusCode: http.StatusOK,
+		Message:    constants.PasswordChangeSuccess,
+		Data:       []interface{}{},
+	})
+}
+
+func (a *authHandler) HandleRequestPasswordReset(c *gin.Context) {
+	logger := logrus.WithFields(logrus.Fields{
+		"clientIP": c.ClientIP(),
+		"endpoint": "/password-reset",
+	})
+
+	var request req.PasswordResetRequest
+	if err := c.ShouldBindJSON(&request); err != nil || request.Username == "" {
+		logger.Warn("Invalid request body", "error", err)
+		c.JSON(http.StatusBadRequest, res.ErrorResponse{
+			StatusCode:   http.StatusBadRequest,
+			ErrorMessage: constants.InvalidRequestBodyError,
+		})
+		return
+	}
+
+	if err := a.authService.RequestPasswordReset(request); err != nil {
+		logger.Error("Password reset request failed", "error", err)
+		c.JSON(http.StatusInternalServerError, res.ErrorResponse{StatusCode: http.StatusInternalServerError, ErrorMessage: err.Error()})
+		return
+	}
+
+	// The same response whether or not the username exists
+	c.JSON(http.StatusAccepted, res.CommonResponse{
+		StatusCode: http.StatusAccepted,
+		Message:    constants.PasswordResetRequestSuccess,
+		Data:       []interface{}{},
+	})
+}
+
+func (a *authHandler) HandleResetPassword(c *gin.Context) {
+	logger := logrus.WithFields(logrus.Fields{
+		"clientIP": c.ClientIP(),
+		"endpoint": "/password-reset/confirm",
+	})
+
+	var request req.ConfirmPasswordResetRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		logger.Warn("Invalid request body", "error", err)
+		c.JSON(http.StatusBadRequest, res.ErrorResponse{
+			StatusCode:   http.StatusBadRequest,
+			ErrorMessage: constants.InvalidRequestBodyError,
+		})
+		return
+	}
+
+	if err := a.authService.ResetPassword(request); err != nil {
+		logger.Warn("Password reset failed", "error", err)
+		if writePasswordPolicyError(c, err) {
+			return
+		}
+		switch err.Error() {
+		case constants.PasswordResetTokenInvalidError:
+			c.JSON(http.StatusBadRequest, res.ErrorResponse{StatusCode: http.StatusBadRequest, ErrorMessage: err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, res.ErrorResponse{StatusCode: http.StatusInternalServerError, ErrorMessage: err.Error()})
+		return
+	}
+
+	logger.Info("Password reset successfully")
+	c.JSON(http.StatusOK, res.CommonResponse{
+		StatusCode: http.StatusOK,
+		Message:    constants.PasswordResetSuccess,
+		Data:       []interface{}{},
+	})
+}
+
+// HandleJwks publishes the public keys access tokens are verified with, as a JSON Web Key Set other services can
+// verify tokens with. It is served as is, not wrapped in a common response.
+func (a *authHandler) HandleJwks(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, utils.GetJwks())
+}
+
 func SetCookie(c *gin.Context, name string, value string, duration int) {
 	cookie := &http.Cookie{
 		Name:     name,
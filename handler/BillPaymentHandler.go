@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"PaymentAPI/constants"
+	req "PaymentAPI/dto/request"
+	res "PaymentAPI/dto/response"
+	"PaymentAPI/enums"
+	"PaymentAPI/service"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"net/http"
+)
+
+type BillPaymentHandler interface {
+	HandleGetBillerProducts(c *gin.Context)
+	HandleCreateBillPayment(c *gin.Context)
+	HandleGetBillPaymentById(c *gin.Context)
+}
+
+type billPaymentHandler struct {
+	billPaymentService service.BillPaymentService
+	walletService      service.WalletService
+}
+
+// NewBillPaymentHandler creates a new instance of BillPaymentHandler.
+func NewBillPaymentHandler(billPaymentService service.BillPaymentService, walletService service.WalletService) BillPaymentHandler {
+	return &billPaymentHandler{billPaymentService, walletService}
+}
+
+// HandleGetBillerProducts lists the products that can be bought, filtered by the optional "category" query.
+func (b *billPaymentHandler) HandleGetBillerProducts(c *gin.Context) {
+	products, err := b.billPaymentService.GetProducts(c.Query("category"))
+	if err != nil {
+		logrus.Errorf("Failed to fetch biller products, error: %v", err)
+		c.JSON(http.StatusInternalServerError, res.ErrorResponse{
+			StatusCode:   http.StatusInternalServerError,
+			ErrorMessage: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, res.CommonResponse{
+		StatusCode: http.StatusOK,
+		Message:    constants.BillerProductFindSuccess,
+		Data:       products,
+	})
+}
+
+// HandleCreateBillPayment buys a product from the authenticated user's wallet.
+func (b *billPaymentHandler) HandleCreateBillPayment(c *gin.Context) {
+	var request req.CreateBillPaymentRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		logrus.Warn("Invalid request body for bill payment")
+		c.JSON(http.StatusBadRequest, res.ErrorResponse{
+			StatusCode:   http.StatusBadRequest,
+			ErrorMessage: constants.InvalidRequestBodyError,
+		})
+		return
+	}
+
+	user, ok := getAuthenticatedUser(c)
+	if !ok {
+		return
+	}
+
+	// Only the owner of a wallet can pay bills from it
+	if !ownsWallet(b.walletService, request.WalletId, user) {
+		logrus.Warnf("User %v attempted unauthorized access to wallet ID: %s", user, request.WalletId)
+		c.JSON(http.StatusForbidden, res.ErrorResponse{
+			StatusCode:   http.StatusForbidden,
+			ErrorMessage: constants.WalletForbiddenAccess,
+		})
+		return
+	}
+
+	billPayment, err := b.billPaymentService.CreateBillPayment(request)
+	if err != nil {
+		logrus.Errorf("Failed to create bill payment, error: %v", err)
+		if writeStepUpError(c, err) {
+			return
+		}
+		status := http.StatusBadRequest
+		if err.Error() == constants.BillerProductNotFoundError {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, res.ErrorResponse{
+			StatusCode:   status,
+			ErrorMessage: err.Error(),
+		})
+		return
+	}
+
+	// A payment the biller could not deliver is returned with its refund, so the caller sees where the money went
+	if billPayment.Status != enums.BILL_PAYMENT_SUCCESS {
+		logrus.Warnf("Bill payment %s failed: %s", billPayment.Id, billPayment.FailureReason)
+		c.JSON(http.StatusUnprocessableEntity, res.CommonResponse{
+			StatusCode: http.StatusUnprocessableEntity,
+			Message:    billPayment.FailureReason,
+			Data:       billPayment,
+		})
+		return
+	}
+
+	logrus.Infof("Bill payment successfully created: %s", billPayment.Id)
+	c.JSON(http.StatusCreated, res.CommonResponse{
+		StatusCode: http.StatusCreated,
+		Message:    constants.BillPaymentCreateSuccess,
+		Data:       billPayment,
+	})
+}
+
+// HandleGetBillPaymentById returns a bill payment to the owner of the paying wallet.
+func (b *billPaymentHandler) HandleGetBillPaymentById(c *gin.Context) {
+	user, ok := getAuthenticatedUser(c)
+	if !ok {
+		return
+	}
+
+	billPayment, err := b.billPaymentService.GetBillPaymentById(c.Param("id"))
+	if err != nil {
+		logrus.Errorf("Failed to fetch bill payment with ID: %s, error: %v", c.Param("id"), err)
+		c.JSON(http.StatusNotFound, res.ErrorResponse{
+			StatusCode:   http.StatusNotFound,
+			ErrorMessage: err.Error(),
+		})
+		return
+	}
+
+	if !ownsWallet(b.walletService, billPayment.WalletId, user) {
+		logrus.Warnf("User %v attempted unauthorized access to bill payment ID: %s", user, billPayment.Id)
+		c.JSON(http.StatusForbidden, res.ErrorResponse{
+			StatusCode:   http.StatusForbidden,
+			ErrorMessage: constants.BillPaymentForbiddenAccess,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, res.CommonResponse{
+		StatusCode: http.StatusOK,
+		Message:    constants.BillPaymentFindSuccess,
+		Data:       billPayment,
+	})
+}
@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"PaymentAPI/constants"
+	req "PaymentAPI/dto/request"
+	res "PaymentAPI/dto/response"
+	"PaymentAPI/service"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"io"
+	"net/http"
+)
+
+type CheckoutHandler interface {
+	HandleGetCheckoutSessionById(c *gin.Context)
+	HandlePayCheckoutSession(c *gin.Context)
+}
+
+type checkoutHandler struct {
+	checkoutService service.CheckoutService
+	walletService   service.WalletService
+}
+
+// NewCheckoutHandler creates a new instance of CheckoutHandler.
+func NewCheckoutHandler(checkoutService service.CheckoutService, walletService service.WalletService) CheckoutHandler {
+	return &checkoutHandler{checkoutService, walletService}
+}
+
+// HandleGetCheckoutSessionById returns a checkout session, so the customer can review it and the merchant can poll its status.
+func (ch *checkoutHandler) HandleGetCheckoutSessionById(c *gin.Context) {
+	checkoutSession, err := ch.checkoutService.GetCheckoutSessionById(c.Param("id"))
+	if err != nil {
+		logrus.Errorf("Failed to fetch checkout session with ID: %s, error: %v", c.Param("id"), err)
+		c.JSON(http.StatusNotFound, res.ErrorResponse{
+			StatusCode:   http.StatusNotFound,
+			ErrorMessage: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, res.CommonResponse{
+		StatusCode: http.StatusOK,
+		Message:    constants.CheckoutSessionFindSuccess,
+		Data:       checkoutSession,
+	})
+}
+
+// HandlePayCheckoutSession pays a checkout session from the authenticated user's wallet.
+func (ch *checkoutHandler) HandlePayCheckoutSession(c *gin.Context) {
+	var request req.StepUp
+
+	// The request body is optional, it is only needed above the step-up threshold
+	if err := c.ShouldBindJSON(&request); err != nil && err != io.EOF {
+		logrus.Warn("Invalid request body for paying checkout session")
+		c.JSON(http.StatusBadRequest, res.ErrorResponse{
+			StatusCode:   http.StatusBadRequest,
+			ErrorMessage: constants.InvalidRequestBodyError,
+		})
+		return
+	}
+
+	user, ok := getAuthenticatedUser(c)
+	if !ok {
+		return
+	}
+
+	wallet, err := ch.walletService.GetWalletByCustomerId(user)
+	if err != nil {
+		logrus.Errorf("Failed to fetch wallet for customer ID: %s, error: %v", user, err)
+		c.JSON(http.StatusNotFound, res.ErrorResponse{
+			StatusCode:   http.StatusNotFound,
+			ErrorMessage: err.Error(),
+		})
+		return
+	}
+
+	checkoutSession, err := ch.checkoutService.PayCheckoutSession(c.Param("id"), wallet.Id, request)
+	if err != nil {
+		logrus.Errorf("Failed to pay checkout session ID: %s, error: %v", c.Param("id"), err)
+		if writeStepUpError(c, err) {
+			return
+		}
+		status := http.StatusBadRequest
+		if err.Error() == constants.CheckoutSessionNotFoundError {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, res.ErrorResponse{
+			StatusCode:   status,
+			ErrorMessage: err.Error(),
+		})
+		return
+	}
+
+	logrus.Infof("Checkout session ID: %s paid", checkoutSession.Id)
+	c.JSON(http.StatusOK, res.CommonResponse{
+		StatusCode: http.StatusOK,
+		Message:    constants.CheckoutSessionPaySuccess,
+		Data:       checkoutSession,
+	})
+}
@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"PaymentAPI/constants"
+	req "PaymentAPI/dto/request"
+	res "PaymentAPI/dto/response"
+	"PaymentAPI/service"
+	"PaymentAPI/utils"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// getAuthenticatedUser returns the customer ID set by the auth middleware, writing an error response when it is missing
+func getAuthenticatedUser(c *gin.Context) (string, bool) {
+	user, exists := c.Get("authenticatedUser")
+	if !exists {
+		logrus.Warn("Authenticated user not found in context")
+		c.JSON(http.StatusUnauthorized, res.ErrorResponse{
+			StatusCode:   http.StatusUnauthorized,
+			ErrorMessage: constants.AuthenticatedUserNotFoundError,
+		})
+		return "", false
+	}
+	return user.(string), true
+}
+
+// getAuthenticatedSession returns the session the access token was issued for, empty for a token issued before
+// sessions were introduced
+func getAuthenticatedSession(c *gin.Context) string {
+	session, _ := c.Get("authenticatedSession")
+	sessionId, _ := session.(string)
+	return sessionId
+}
+
+// getClientInfo describes the client of the request for the session it logs in or refreshes
+func getClientInfo(c *gin.Context, deviceName string) req.ClientInfo {
+	return req.ClientInfo{
+		Ip:         c.ClientIP(),
+		UserAgent:  c.GetHeader("User-Agent"),
+		DeviceName: deviceName,
+	}
+}
+
+// ownsWallet reports whether the wallet exists and belongs to the customer
+func ownsWallet(walletService service.WalletService, walletId string, customerId string) bool {
+	wallet, err := walletService.GetWalletById(walletId)
+	return err == nil && wallet.CustomerId == customerId
+}
+
+// writePasswordPolicyError writes every broken password rule as a field error, reporting whether the error was one
+func writePasswordPolicyError(c *gin.Context, err error) bool {
+	var policyErr *utils.PasswordPolicyError
+	if !errors.As(err, &policyErr) {
+		return false
+	}
+
+	fieldErrors := make([]res.FieldError, 0, len(policyErr.Violations))
+	for _, violation := range policyErr.Violations {
+		fieldErrors = append(fieldErrors, res.FieldError{Field: policyErr.Field, Message: violation})
+	}
+	c.JSON(http.StatusBadRequest, res.ValidationErrorResponse{
+		StatusCode:   http.StatusBadRequest,
+		ErrorMessage: policyErr.Error(),
+		Errors:       fieldErrors,
+	})
+	return true
+}
+
+// writeLoginThrottledError answers a throttled or locked out login with 429 and when to try again, reporting whether
+// the error was one
+func writeLoginThrottledError(c *gin.Context, err error) bool {
+	var throttledErr *service.LoginThrottledError
+	if !errors.As(err, &throttledErr) {
+		return false
+	}
+
+	writeTooManyRequests(c, err, throttledErr.RetryAfter)
+	return true
+}
+
+// writeTransfersLockedError answers a transfer refused while transfers are locked with 429 and when to try again,
+// reporting whether the error was one
+func writeTransfersLockedError(c *gin.Context, err error) bool {
+	var lockedErr *service.TransfersLockedError
+	if !errors.As(err, &lockedErr) {
+		return false
+	}
+
+	writeTooManyRequests(c, err, lockedErr.RetryAfter)
+	return true
+}
+
+// writeStepUpError answers a debit refused for its step-up, 429 while transfers are locked and 403 for a missing or
+// wrong PIN or code, reporting whether the error was one
+func writeStepUpError(c *gin.Context, err error) bool {
+	if writeTransfersLockedError(c, err) {
+		return true
+	}
+
+	status := 0
+	switch err.Error() {
+	case constants.TransactionStepUpRequiredError, constants.TransactionPinNotSetError,
+		constants.TransactionPinIncorrectError, constants.TwoFactorCodeInvalidError:
+		status = http.StatusForbidden
+	case constants.TwoFactorNotEnabledError:
+		status = http.StatusBadRequest
+	default:
+		return false
+	}
+	c.JSON(status, res.ErrorResponse{StatusCode: status, ErrorMessage: err.Error()})
+	return true
+}
+
+// writeTooManyRequests writes a 429 response with a Retry-After header in whole seconds
+func writeTooManyRequests(c *gin.Context, err error, retryAfter time.Duration) {
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	c.JSON(http.StatusTooManyRequests, res.ErrorResponse{StatusCode: http.StatusTooManyRequests, ErrorMessage: err.Error()})
+}
@@ -0,0 +1,200 @@
+package handler
+
+import (
+	"PaymentAPI/constants"
+	req "PaymentAPI/dto/request"
+	res "PaymentAPI/dto/response"
+	"PaymentAPI/entity"
+	"PaymentAPI/service"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"io"
+	"net/http"
+)
+
+type HoldHandler interface {
+	HandleCreateHold(c *gin.Context)
+	HandleGetHoldById(c *gin.Context)
+	HandleCaptureHold(c *gin.Context)
+	HandleVoidHold(c *gin.Context)
+}
+
+type holdHandler struct {
+	holdService   service.HoldService
+	walletService service.WalletService
+}
+
+// NewHoldHandler creates a new instance of HoldHandler.
+func NewHoldHandler(holdService service.HoldService, walletService service.WalletService) HoldHandler {
+	return &holdHandler{holdService, walletService}
+}
+
+// HandleCreateHold reserves funds on the authenticated user's wallet in favor of a receiving wallet.
+func (h *holdHandler) HandleCreateHold(c *gin.Context) {
+	var request req.CreateHoldRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		logrus.Warn("Invalid request body for hold creation")
+		c.JSON(http.StatusBadRequest, res.ErrorResponse{
+			StatusCode:   http.StatusBadRequest,
+			ErrorMessage: constants.InvalidRequestBodyError,
+		})
+		return
+	}
+
+	user, ok := getAuthenticatedUser(c)
+	if !ok {
+		return
+	}
+
+	// Only the owner of a wallet can reserve its funds
+	wallet, err := h.walletService.GetWalletById(request.WalletId)
+	if err != nil {
+		logrus.Errorf("Failed to fetch wallet with ID: %s, error: %v", request.WalletId, err)
+		c.JSON(http.StatusNotFound, res.ErrorResponse{
+			StatusCode:   http.StatusNotFound,
+			ErrorMessage: err.Error(),
+		})
+		return
+	}
+	if wallet.CustomerId != user {
+		logrus.Warnf("User %v attempted unauthorized access to wallet ID: %s", user, request.WalletId)
+		c.JSON(http.StatusForbidden, res.ErrorResponse{
+			StatusCode:   http.StatusForbidden,
+			ErrorMessage: constants.WalletForbiddenAccess,
+		})
+		return
+	}
+
+	hold, err := h.holdService.CreateHold(request)
+	if err != nil {
+		logrus.Errorf("Failed to create hold, error: %v", err)
+		if writeStepUpError(c, err) {
+			return
+		}
+		c.JSON(http.StatusBadRequest, res.ErrorResponse{
+			StatusCode:   http.StatusBadRequest,
+			ErrorMessage: err.Error(),
+		})
+		return
+	}
+
+	logrus.Infof("Hold successfully created: %s", hold.Id)
+	c.JSON(http.StatusCreated, res.CommonResponse{
+		StatusCode: http.StatusCreated,
+		Message:    constants.HoldCreateSuccess,
+		Data:       hold,
+	})
+}
+
+// HandleGetHoldById returns a hold to the owner of either the held or the receiving wallet.
+func (h *holdHandler) HandleGetHoldById(c *gin.Context) {
+	hold, ok := h.getHold(c, true, true)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, res.CommonResponse{
+		StatusCode: http.StatusOK,
+		Message:    constants.HoldFindSuccess,
+		Data:       hold,
+	})
+}
+
+// HandleCaptureHold captures a hold in full or in part, only the owner of the receiving wallet can capture.
+func (h *holdHandler) HandleCaptureHold(c *gin.Context) {
+	var request req.CaptureHoldRequest
+
+	// The request body is optional, without an amount the full hold is captured
+	if err := c.ShouldBindJSON(&request); err != nil && err != io.EOF {
+		logrus.Warn("Invalid request body for hold capture")
+		c.JSON(http.StatusBadRequest, res.ErrorResponse{
+			StatusCode:   http.StatusBadRequest,
+			ErrorMessage: constants.InvalidRequestBodyError,
+		})
+		return
+	}
+
+	hold, ok := h.getHold(c, false, true)
+	if !ok {
+		return
+	}
+
+	hold, err := h.holdService.CaptureHold(hold.Id, request.Amount)
+	if err != nil {
+		logrus.Errorf("Failed to capture hold ID: %s, error: %v", c.Param("id"), err)
+		if writeTransfersLockedError(c, err) {
+			return
+		}
+		c.JSON(http.StatusBadRequest, res.ErrorResponse{
+			StatusCode:   http.StatusBadRequest,
+			ErrorMessage: err.Error(),
+		})
+		return
+	}
+
+	logrus.Infof("Hold ID: %s captured", hold.Id)
+	c.JSON(http.StatusOK, res.CommonResponse{
+		StatusCode: http.StatusOK,
+		Message:    constants.HoldCaptureSuccess,
+		Data:       hold,
+	})
+}
+
+// HandleVoidHold releases a hold, either party can void it.
+func (h *holdHandler) HandleVoidHold(c *gin.Context) {
+	hold, ok := h.getHold(c, true, true)
+	if !ok {
+		return
+	}
+
+	hold, err := h.holdService.VoidHold(hold.Id)
+	if err != nil {
+		logrus.Errorf("Failed to void hold ID: %s, error: %v", c.Param("id"), err)
+		c.JSON(http.StatusBadRequest, res.ErrorResponse{
+			StatusCode:   http.StatusBadRequest,
+			ErrorMessage: err.Error(),
+		})
+		return
+	}
+
+	logrus.Infof("Hold ID: %s voided", hold.Id)
+	c.JSON(http.StatusOK, res.CommonResponse{
+		StatusCode: http.StatusOK,
+		Message:    constants.HoldVoidSuccess,
+		Data:       hold,
+	})
+}
+
+// getHold loads the hold from the path and checks that the authenticated user owns the held wallet
+// (when allowHolder is set) or the receiving wallet (when allowReceiver is set).
+func (h *holdHandler) getHold(c *gin.Context, allowHolder bool, allowReceiver bool) (entity.Hold, bool) {
+	user, ok := getAuthenticatedUser(c)
+	if !ok {
+		return entity.Hold{}, false
+	}
+
+	hold, err := h.holdService.GetHoldById(c.Param("id"))
+	if err != nil {
+		logrus.Errorf("Failed to fetch hold with ID: %s, error: %v", c.Param("id"), err)
+		c.JSON(http.StatusNotFound, res.ErrorResponse{
+			StatusCode:   http.StatusNotFound,
+			ErrorMessage: err.Error(),
+		})
+		return entity.Hold{}, false
+	}
+
+	if allowHolder && ownsWallet(h.walletService, hold.WalletId, user) {
+		return hold, true
+	}
+	if allowReceiver && ownsWallet(h.walletService, hold.ToWalletId, user) {
+		return hold, true
+	}
+
+	logrus.Warnf("User %v attempted unauthorized access to hold ID: %s", user, hold.Id)
+	c.JSON(http.StatusForbidden, res.ErrorResponse{
+		StatusCode:   http.StatusForbidden,
+		ErrorMessage: constants.HoldForbiddenAccess,
+	})
+	return entity.Hold{}, false
+}
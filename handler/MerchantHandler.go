@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"PaymentAPI/constants"
+	req "PaymentAPI/dto/request"
+	res "PaymentAPI/dto/response"
+	"PaymentAPI/entity"
+	"PaymentAPI/service"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"net/http"
+)
+
+type MerchantHandler interface {
+	HandleCreateMerchant(c *gin.Context)
+	HandleGetMerchantById(c *gin.Context)
+	HandleCreateCheckoutSession(c *gin.Context)
+}
+
+type merchantHandler struct {
+	merchantService service.MerchantService
+	checkoutService service.CheckoutService
+}
+
+// NewMerchantHandler creates a new instance of MerchantHandler.
+func NewMerchantHandler(merchantService service.MerchantService, checkoutService service.CheckoutService) MerchantHandler {
+	return &merchantHandler{merchantService, checkoutService}
+}
+
+// HandleCreateMerchant registers a merchant account for the authenticated user.
+func (m *merchantHandler) HandleCreateMerchant(c *gin.Context) {
+	var request req.CreateMerchantRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		logrus.Warn("Invalid request body for merchant creation")
+		c.JSON(http.StatusBadRequest, res.ErrorResponse{
+			StatusCode:   http.StatusBadRequest,
+			ErrorMessage: constants.InvalidRequestBodyError,
+		})
+		return
+	}
+
+	user, ok := getAuthenticatedUser(c)
+	if !ok {
+		return
+	}
+
+	merchant, err := m.merchantService.CreateMerchant(user, request)
+	if err != nil {
+		logrus.Errorf("Failed to create merchant, error: %v", err)
+		c.JSON(http.StatusBadRequest, res.ErrorResponse{
+			StatusCode:   http.StatusBadRequest,
+			ErrorMessage: err.Error(),
+		})
+		return
+	}
+
+	logrus.Infof("Merchant successfully created: %s", merchant.Id)
+	c.JSON(http.StatusCreated, res.CommonResponse{
+		StatusCode: http.StatusCreated,
+		Message:    constants.MerchantCreateSuccess,
+		Data:       merchant,
+	})
+}
+
+// HandleGetMerchantById returns a merchant to its owner.
+func (m *merchantHandler) HandleGetMerchantById(c *gin.Context) {
+	merchant, ok := m.getOwnMerchant(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, res.CommonResponse{
+		StatusCode: http.StatusOK,
+		Message:    constants.MerchantFindSuccess,
+		Data:       merchant,
+	})
+}
+
+// HandleCreateCheckoutSession opens a checkout session for one of the authenticated user's merchants.
+func (m *merchantHandler) HandleCreateCheckoutSession(c *gin.Context) {
+	var request req.CreateCheckoutSessionRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		logrus.Warn("Invalid request body for checkout session creation")
+		c.JSON(http.StatusBadRequest, res.ErrorResponse{
+			StatusCode:   http.StatusBadRequest,
+			ErrorMessage: constants.InvalidRequestBodyError,
+		})
+		return
+	}
+
+	merchant, ok := m.getOwnMerchant(c)
+	if !ok {
+		return
+	}
+
+	checkoutSession, err := m.checkoutService.CreateCheckoutSession(merchant.Id, request)
+	if err != nil {
+		logrus.Errorf("Failed to create checkout session for merchant ID: %s, error: %v", merchant.Id, err)
+		c.JSON(http.StatusBadRequest, res.ErrorResponse{
+			StatusCode:   http.StatusBadRequest,
+			ErrorMessage: err.Error(),
+		})
+		return
+	}
+
+	logrus.Infof("Checkout session successfully created: %s", checkoutSession.Id)
+	c.JSON(http.StatusCreated, res.CommonResponse{
+		StatusCode: http.StatusCreated,
+		Message:    constants.CheckoutSessionCreateSuccess,
+		Data:       checkoutSession,
+	})
+}
+
+// getOwnMerchant loads the merchant from the path and checks that it belongs to the authenticated user
+func (m *merchantHandler) getOwnMerchant(c *gin.Context) (entity.Merchant, bool) {
+	user, ok := getAuthenticatedUser(c)
+	if !ok {
+		return entity.Merchant{}, false
+	}
+
+	merchant, err := m.merchantService.GetMerchantById(c.Param("id"))
+	if err != nil {
+		logrus.Errorf("Failed to fetch merchant with ID: %s, error: %v", c.Param("id"), err)
+		c.JSON(http.StatusNotFound, res.ErrorResponse{
+			StatusCode:   http.StatusNotFound,
+			ErrorMessage: err.Error(),
+		})
+		return entity.Merchant{}, false
+	}
+
+	if merchant.CustomerId != user {
+		logrus.Warnf("User %v attempted unauthorized access to merchant ID: %s", user, merchant.Id)
+		c.JSON(http.StatusForbidden, res.ErrorResponse{
+			StatusCode:   http.StatusForbidden,
+			ErrorMessage: constants.MerchantForbiddenAccess,
+		})
+		return entity.Merchant{}, false
+	}
+
+	return merchant, true
+}
@@ -0,0 +1,216 @@
+package handler
+
+import (
+	"PaymentAPI/constants"
+	req "PaymentAPI/dto/request"
+	res "PaymentAPI/dto/response"
+	"PaymentAPI/entity"
+	"PaymentAPI/service"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"io"
+	"net/http"
+)
+
+type PaymentRequestHandler interface {
+	HandleCreatePaymentRequest(c *gin.Context)
+	HandleGetPaymentRequests(c *gin.Context)
+	HandleAcceptPaymentRequest(c *gin.Context)
+	HandleDeclinePaymentRequest(c *gin.Context)
+}
+
+type paymentRequestHandler struct {
+	paymentRequestService service.PaymentRequestService
+	walletService         service.WalletService
+}
+
+// NewPaymentRequestHandler creates a new instance of PaymentRequestHandler.
+func NewPaymentRequestHandler(paymentRequestService service.PaymentRequestService, walletService service.WalletService) PaymentRequestHandler {
+	return &paymentRequestHandler{paymentRequestService, walletService}
+}
+
+// HandleCreatePaymentRequest asks another wallet to pay the authenticated user's wallet.
+func (p *paymentRequestHandler) HandleCreatePaymentRequest(c *gin.Context) {
+	var request req.CreatePaymentRequestRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		logrus.Warn("Invalid request body for payment request creation")
+		c.JSON(http.StatusBadRequest, res.ErrorResponse{
+			StatusCode:   http.StatusBadRequest,
+			ErrorMessage: constants.InvalidRequestBodyError,
+		})
+		return
+	}
+
+	user, ok := getAuthenticatedUser(c)
+	if !ok {
+		return
+	}
+
+	// Only the owner of a wallet can request money into it
+	if !ownsWallet(p.walletService, request.RequesterWalletId, user) {
+		logrus.Warnf("User %v attempted unauthorized access to wallet ID: %s", user, request.RequesterWalletId)
+		c.JSON(http.StatusForbidden, res.ErrorResponse{
+			StatusCode:   http.StatusForbidden,
+			ErrorMessage: constants.WalletForbiddenAccess,
+		})
+		return
+	}
+
+	paymentRequest, err := p.paymentRequestService.CreatePaymentRequest(request)
+	if err != nil {
+		logrus.Errorf("Failed to create payment request, error: %v", err)
+		c.JSON(http.StatusBadRequest, res.ErrorResponse{
+			StatusCode:   http.StatusBadRequest,
+			ErrorMessage: err.Error(),
+		})
+		return
+	}
+
+	logrus.Infof("Payment request successfully created: %s", paymentRequest.Id)
+	c.JSON(http.StatusCreated, res.CommonResponse{
+		StatusCode: http.StatusCreated,
+		Message:    constants.PaymentRequestCreateSuccess,
+		Data:       paymentRequest,
+	})
+}
+
+// HandleGetPaymentRequests lists the incoming (default) or outgoing payment requests of the authenticated user's wallet.
+func (p *paymentRequestHandler) HandleGetPaymentRequests(c *gin.Context) {
+	user, ok := getAuthenticatedUser(c)
+	if !ok {
+		return
+	}
+
+	wallet, err := p.walletService.GetWalletByCustomerId(user)
+	if err != nil {
+		logrus.Errorf("Failed to fetch wallet for customer ID: %s, error: %v", user, err)
+		c.JSON(http.StatusNotFound, res.ErrorResponse{
+			StatusCode:   http.StatusNotFound,
+			ErrorMessage: err.Error(),
+		})
+		return
+	}
+
+	var paymentRequests []entity.PaymentRequest
+	switch c.DefaultQuery("direction", "incoming") {
+	case "incoming":
+		paymentRequests, err = p.paymentRequestService.GetIncomingPaymentRequests(wallet.Id)
+	case "outgoing":
+		paymentRequests, err = p.paymentRequestService.GetOutgoingPaymentRequests(wallet.Id)
+	default:
+		c.JSON(http.StatusBadRequest, res.ErrorResponse{
+			StatusCode:   http.StatusBadRequest,
+			ErrorMessage: constants.PaymentRequestDirectionError,
+		})
+		return
+	}
+	if err != nil {
+		logrus.Errorf("Failed to fetch payment requests for wallet ID: %s, error: %v", wallet.Id, err)
+		c.JSON(http.StatusInternalServerError, res.ErrorResponse{
+			StatusCode:   http.StatusInternalServerError,
+			ErrorMessage: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, res.CommonResponse{
+		StatusCode: http.StatusOK,
+		Message:    constants.PaymentRequestFindSuccess,
+		Data:       paymentRequests,
+	})
+}
+
+// HandleAcceptPaymentRequest pays a payment request, only the owner of the payer wallet can accept.
+func (p *paymentRequestHandler) HandleAcceptPaymentRequest(c *gin.Context) {
+	var request req.StepUp
+
+	// The request body is optional, it is only needed above the step-up threshold
+	if err := c.ShouldBindJSON(&request); err != nil && err != io.EOF {
+		logrus.Warn("Invalid request body for accepting payment request")
+		c.JSON(http.StatusBadRequest, res.ErrorResponse{
+			StatusCode:   http.StatusBadRequest,
+			ErrorMessage: constants.InvalidRequestBodyError,
+		})
+		return
+	}
+
+	paymentRequest, ok := p.getPayerPaymentRequest(c)
+	if !ok {
+		return
+	}
+
+	paymentRequest, err := p.paymentRequestService.AcceptPaymentRequest(paymentRequest.Id, request)
+	if err != nil {
+		logrus.Errorf("Failed to accept payment request ID: %s, error: %v", c.Param("id"), err)
+		if writeStepUpError(c, err) {
+			return
+		}
+		c.JSON(http.StatusBadRequest, res.ErrorResponse{
+			StatusCode:   http.StatusBadRequest,
+			ErrorMessage: err.Error(),
+		})
+		return
+	}
+
+	logrus.Infof("Payment request ID: %s accepted", paymentRequest.Id)
+	c.JSON(http.StatusOK, res.CommonResponse{
+		StatusCode: http.StatusOK,
+		Message:    constants.PaymentRequestAcceptSuccess,
+		Data:       paymentRequest,
+	})
+}
+
+// HandleDeclinePaymentRequest refuses a payment request, only the owner of the payer wallet can decline.
+func (p *paymentRequestHandler) HandleDeclinePaymentRequest(c *gin.Context) {
+	paymentRequest, ok := p.getPayerPaymentRequest(c)
+	if !ok {
+		return
+	}
+
+	paymentRequest, err := p.paymentRequestService.DeclinePaymentRequest(paymentRequest.Id)
+	if err != nil {
+		logrus.Errorf("Failed to decline payment request ID: %s, error: %v", c.Param("id"), err)
+		c.JSON(http.StatusBadRequest, res.ErrorResponse{
+			StatusCode:   http.StatusBadRequest,
+			ErrorMessage: err.Error(),
+		})
+		return
+	}
+
+	logrus.Infof("Payment request ID: %s declined", paymentRequest.Id)
+	c.JSON(http.StatusOK, res.CommonResponse{
+		StatusCode: http.StatusOK,
+		Message:    constants.PaymentRequestDeclineSuccess,
+		Data:       paymentRequest,
+	})
+}
+
+// getPayerPaymentRequest loads the payment request from the path and checks that the authenticated user owns the payer wallet
+func (p *paymentRequestHandler) getPayerPaymentRequest(c *gin.Context) (entity.PaymentRequest, bool) {
+	user, ok := getAuthenticatedUser(c)
+	if !ok {
+		return entity.PaymentRequest{}, false
+	}
+
+	paymentRequest, err := p.paymentRequestService.GetPaymentRequestById(c.Param("id"))
+	if err != nil {
+		logrus.Errorf("Failed to fetch payment request with ID: %s, error: %v", c.Param("id"), err)
+		c.JSON(http.StatusNotFound, res.ErrorResponse{
+			StatusCode:   http.StatusNotFound,
+			ErrorMessage: err.Error(),
+		})
+		return entity.PaymentRequest{}, false
+	}
+
+	if !ownsWallet(p.walletService, paymentRequest.PayerWalletId, user) {
+		logrus.Warnf("User %v attempted unauthorized access to payment request ID: %s", user, paymentRequest.Id)
+		c.JSON(http.StatusForbidden, res.ErrorResponse{
+			StatusCode:   http.StatusForbidden,
+			ErrorMessage: constants.PaymentRequestForbiddenAccess,
+		})
+		return entity.PaymentRequest{}, false
+	}
+
+	return paymentRequest, true
+}
@@ -0,0 +1,199 @@
+package handler
+
+import (
+	"PaymentAPI/constants"
+	req "PaymentAPI/dto/request"
+	res "PaymentAPI/dto/response"
+	"PaymentAPI/entity"
+	"PaymentAPI/enums"
+	"PaymentAPI/service"
+	"PaymentAPI/utils"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"strings"
+)
+
+type PayoutHandler interface {
+	HandleCreatePayoutBatch(c *gin.Context)
+	HandleGetPayoutBatchById(c *gin.Context)
+	HandleGetPayoutBatchReport(c *gin.Context)
+}
+
+type payoutHandler struct {
+	payoutService service.PayoutService
+	walletService service.WalletService
+}
+
+// NewPayoutHandler creates a new instance of PayoutHandler.
+func NewPayoutHandler(payoutService service.PayoutService, walletService service.WalletService) PayoutHandler {
+	return &payoutHandler{payoutService, walletService}
+}
+
+// HandleCreatePayoutBatch pays many recipients from the authenticated user's wallet. The rows are sent either as
+// a JSON body or as a CSV file upload in the "file" field of a multipart form, with the wallet in "wallet_id" and the
+// step-up in "pin" or "totp_code".
+func (p *payoutHandler) HandleCreatePayoutBatch(c *gin.Context) {
+	request, ok := bindPayoutBatchRequest(c)
+	if !ok {
+		return
+	}
+
+	user, ok := getAuthenticatedUser(c)
+	if !ok {
+		return
+	}
+
+	// Only the owner of a wallet can pay out of it
+	if !ownsWallet(p.walletService, request.WalletId, user) {
+		logrus.Warnf("User %v attempted unauthorized access to wallet ID: %s", user, request.WalletId)
+		c.JSON(http.StatusForbidden, res.ErrorResponse{
+			StatusCode:   http.StatusForbidden,
+			ErrorMessage: constants.WalletForbiddenAccess,
+		})
+		return
+	}
+
+	batch, err := p.payoutService.CreatePayoutBatch(request)
+	if err != nil {
+		logrus.Errorf("Failed to create payout batch, error: %v", err)
+		if writeStepUpError(c, err) {
+			return
+		}
+		c.JSON(http.StatusBadRequest, res.ErrorResponse{
+			StatusCode:   http.StatusBadRequest,
+			ErrorMessage: err.Error(),
+		})
+		return
+	}
+
+	// A rejected batch is returned with the result of every row, so the caller can fix the invalid ones
+	if batch.Status == enums.PAYOUT_BATCH_REJECTED {
+		logrus.Warnf("Payout batch %s rejected: %s", batch.Id, batch.ErrorMessage)
+		c.JSON(http.StatusUnprocessableEntity, res.CommonResponse{
+			StatusCode: http.StatusUnprocessableEntity,
+			Message:    batch.ErrorMessage,
+			Data:       batch,
+		})
+		return
+	}
+
+	logrus.Infof("Payout batch %s processed with status %s", batch.Id, batch.Status)
+	c.JSON(http.StatusCreated, res.CommonResponse{
+		StatusCode: http.StatusCreated,
+		Message:    constants.PayoutBatchCompleteSuccess,
+		Data:       batch,
+	})
+}
+
+// HandleGetPayoutBatchById returns a payout batch with the result of every row.
+func (p *payoutHandler) HandleGetPayoutBatchById(c *gin.Context) {
+	batch, ok := p.getPayoutBatch(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, res.CommonResponse{
+		StatusCode: http.StatusOK,
+		Message:    constants.PayoutBatchFindSuccess,
+		Data:       batch,
+	})
+}
+
+// HandleGetPayoutBatchReport downloads the result of every row of a payout batch as a CSV file.
+func (p *payoutHandler) HandleGetPayoutBatchReport(c *gin.Context) {
+	batch, ok := p.getPayoutBatch(c)
+	if !ok {
+		return
+	}
+
+	c.Header("Content-Type", "text/csv")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=payout-%s.csv", batch.Id))
+	c.Status(http.StatusOK)
+	if err := utils.WritePayoutReport(c.Writer, batch); err != nil {
+		logrus.Errorf("Failed to write report for payout batch ID: %s, error: %v", batch.Id, err)
+	}
+}
+
+// getPayoutBatch loads the payout batch from the path and checks that the authenticated user owns the paying wallet
+func (p *payoutHandler) getPayoutBatch(c *gin.Context) (entity.PayoutBatch, bool) {
+	user, ok := getAuthenticatedUser(c)
+	if !ok {
+		return entity.PayoutBatch{}, false
+	}
+
+	batch, err := p.payoutService.GetPayoutBatchById(c.Param("id"))
+	if err != nil {
+		logrus.Errorf("Failed to fetch payout batch with ID: %s, error: %v", c.Param("id"), err)
+		c.JSON(http.StatusNotFound, res.ErrorResponse{
+			StatusCode:   http.StatusNotFound,
+			ErrorMessage: err.Error(),
+		})
+		return entity.PayoutBatch{}, false
+	}
+
+	if !ownsWallet(p.walletService, batch.WalletId, user) {
+		logrus.Warnf("User %v attempted unauthorized access to payout batch ID: %s", user, batch.Id)
+		c.JSON(http.StatusForbidden, res.ErrorResponse{
+			StatusCode:   http.StatusForbidden,
+			ErrorMessage: constants.PayoutBatchForbiddenAccess,
+		})
+		return entity.PayoutBatch{}, false
+	}
+
+	return batch, true
+}
+
+// bindPayoutBatchRequest reads the payout rows from a CSV upload or a JSON body, writing an error response when it fails
+func bindPayoutBatchRequest(c *gin.Context) (req.CreatePayoutBatchRequest, bool) {
+	var request req.CreatePayoutBatchRequest
+
+	if !strings.HasPrefix(c.ContentType(), "multipart/form-data") {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			logrus.Warn("Invalid request body for payout batch creation")
+			c.JSON(http.StatusBadRequest, res.ErrorResponse{
+				StatusCode:   http.StatusBadRequest,
+				ErrorMessage: constants.InvalidRequestBodyError,
+			})
+			return req.CreatePayoutBatchRequest{}, false
+		}
+		return request, true
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		logrus.Warn("Missing CSV file for payout batch creation")
+		c.JSON(http.StatusBadRequest, res.ErrorResponse{
+			StatusCode:   http.StatusBadRequest,
+			ErrorMessage: constants.PayoutCsvInvalidError,
+		})
+		return req.CreatePayoutBatchRequest{}, false
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		logrus.Errorf("Failed to open uploaded CSV file, error: %v", err)
+		c.JSON(http.StatusBadRequest, res.ErrorResponse{
+			StatusCode:   http.StatusBadRequest,
+			ErrorMessage: constants.PayoutCsvInvalidError,
+		})
+		return req.CreatePayoutBatchRequest{}, false
+	}
+	defer file.Close()
+
+	request.WalletId = c.PostForm("wallet_id")
+	request.Pin = c.PostForm("pin")
+	request.TotpCode = c.PostForm("totp_code")
+	request.Items, err = utils.ParsePayoutCsv(file)
+	if err != nil {
+		logrus.Warnf("Invalid CSV file for payout batch creation, error: %v", err)
+		c.JSON(http.StatusBadRequest, res.ErrorResponse{
+			StatusCode:   http.StatusBadRequest,
+			ErrorMessage: err.Error(),
+		})
+		return req.CreatePayoutBatchRequest{}, false
+	}
+
+	return request, true
+}
@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"PaymentAPI/constants"
+	req "PaymentAPI/dto/request"
+	res "PaymentAPI/dto/response"
+	"PaymentAPI/service"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"net/http"
+)
+
+type QrHandler interface {
+	HandleGenerateWalletQr(c *gin.Context)
+	HandleGenerateCheckoutQr(c *gin.Context)
+	HandlePayQr(c *gin.Context)
+}
+
+type qrHandler struct {
+	qrPaymentService service.QrPaymentService
+	walletService    service.WalletService
+}
+
+// NewQrHandler creates a new instance of QrHandler.
+func NewQrHandler(qrPaymentService service.QrPaymentService, walletService service.WalletService) QrHandler {
+	return &qrHandler{qrPaymentService, walletService}
+}
+
+// HandleGenerateWalletQr creates a QR payload paying into the authenticated user's wallet.
+func (q *qrHandler) HandleGenerateWalletQr(c *gin.Context) {
+	var request req.GenerateWalletQrRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		logrus.Warn("Invalid request body for wallet QR generation")
+		c.JSON(http.StatusBadRequest, res.ErrorResponse{
+			StatusCode:   http.StatusBadRequest,
+			ErrorMessage: constants.InvalidRequestBodyError,
+		})
+		return
+	}
+
+	user, ok := getAuthenticatedUser(c)
+	if !ok {
+		return
+	}
+
+	// Only the owner of a wallet can ask to be paid into it
+	if !ownsWallet(q.walletService, request.WalletId, user) {
+		logrus.Warnf("User %v attempted unauthorized access to wallet ID: %s", user, request.WalletId)
+		c.JSON(http.StatusForbidden, res.ErrorResponse{
+			StatusCode:   http.StatusForbidden,
+			ErrorMessage: constants.WalletForbiddenAccess,
+		})
+		return
+	}
+
+	response, err := q.qrPaymentService.GenerateWalletQr(request)
+	if err != nil {
+		logrus.Errorf("Failed to generate QR for wallet ID: %s, error: %v", request.WalletId, err)
+		c.JSON(http.StatusBadRequest, res.ErrorResponse{
+			StatusCode:   http.StatusBadRequest,
+			ErrorMessage: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, res.CommonResponse{
+		StatusCode: http.StatusOK,
+		Message:    constants.QrGenerateSuccess,
+		Data:       response,
+	})
+}
+
+// HandleGenerateCheckoutQr creates a QR payload paying an open checkout session.
+func (q *qrHandler) HandleGenerateCheckoutQr(c *gin.Context) {
+	response, err := q.qrPaymentService.GenerateCheckoutQr(c.Param("id"))
+	if err != nil {
+		logrus.Errorf("Failed to generate QR for checkout session ID: %s, error: %v", c.Param("id"), err)
+		status := http.StatusBadRequest
+		if err.Error() == constants.CheckoutSessionNotFoundError {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, res.ErrorResponse{
+			StatusCode:   status,
+			ErrorMessage: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, res.CommonResponse{
+		StatusCode: http.StatusOK,
+		Message:    constants.QrGenerateSuccess,
+		Data:       response,
+	})
+}
+
+// HandlePayQr pays a scanned QR payload from the authenticated user's wallet.
+func (q *qrHandler) HandlePayQr(c *gin.Context) {
+	var request req.PayQrRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		logrus.Warn("Invalid request body for QR payment")
+		c.JSON(http.StatusBadRequest, res.ErrorResponse{
+			StatusCode:   http.StatusBadRequest,
+			ErrorMessage: constants.InvalidRequestBodyError,
+		})
+		return
+	}
+
+	user, ok := getAuthenticatedUser(c)
+	if !ok {
+		return
+	}
+
+	wallet, err := q.walletService.GetWalletByCustomerId(user)
+	if err != nil {
+		logrus.Errorf("Failed to fetch wallet for customer ID: %s, error: %v", user, err)
+		c.JSON(http.StatusNotFound, res.ErrorResponse{
+			StatusCode:   http.StatusNotFound,
+			ErrorMessage: err.Error(),
+		})
+		return
+	}
+
+	response, err := q.qrPaymentService.PayQr(request, wallet.Id)
+	if err != nil {
+		logrus.Errorf("Failed to pay QR from wallet ID: %s, error: %v", wallet.Id, err)
+		if writeStepUpError(c, err) {
+			return
+		}
+		c.JSON(http.StatusBadRequest, res.ErrorResponse{
+			StatusCode:   http.StatusBadRequest,
+			ErrorMessage: err.Error(),
+		})
+		return
+	}
+
+	logrus.Infof("QR paid with transaction ID: %s", response.TransactionId)
+	c.JSON(http.StatusOK, res.CommonResponse{
+		StatusCode: http.StatusOK,
+		Message:    constants.QrPaySuccess,
+		Data:       response,
+	})
+}
@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"PaymentAPI/constants"
+	res "PaymentAPI/dto/response"
+	"PaymentAPI/service"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"net/http"
+)
+
+type SessionHandler interface {
+	HandleGetSessions(c *gin.Context)
+	HandleRevokeSession(c *gin.Context)
+	HandleRevokeOtherSessions(c *gin.Context)
+}
+
+type sessionHandler struct {
+	sessionService service.SessionService
+}
+
+// NewSessionHandler creates a new instance of SessionHandler.
+func NewSessionHandler(sessionService service.SessionService) SessionHandler {
+	return &sessionHandler{sessionService}
+}
+
+// HandleGetSessions lists the active sessions of the authenticated user, marking the one of the request.
+func (s *sessionHandler) HandleGetSessions(c *gin.Context) {
+	user, ok := getAuthenticatedUser(c)
+	if !ok {
+		return
+	}
+
+	sessions, err := s.sessionService.GetSessions(user, getAuthenticatedSession(c))
+	if err != nil {
+		logrus.Errorf("Failed to retrieve sessions, error: %v", err)
+		c.JSON(http.StatusInternalServerError, res.ErrorResponse{
+			StatusCode:   http.StatusInternalServerError,
+			ErrorMessage: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, res.CommonResponse{
+		StatusCode: http.StatusOK,
+		Message:    constants.SessionFindSuccess,
+		Data:       sessions,
+	})
+}
+
+// HandleRevokeSession ends one session of the authenticated user.
+func (s *sessionHandler) HandleRevokeSession(c *gin.Context) {
+	user, ok := getAuthenticatedUser(c)
+	if !ok {
+		return
+	}
+
+	if err := s.sessionService.RevokeSession(user, c.Param("id")); err != nil {
+		logrus.Errorf("Failed to revoke session, error: %v", err)
+		writeSessionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res.CommonResponse{
+		StatusCode: http.StatusOK,
+		Message:    constants.SessionRevokeSuccess,
+		Data:       []interface{}{},
+	})
+}
+
+// HandleRevokeOtherSessions ends every session of the authenticated user except the one of the request.
+func (s *sessionHandler) HandleRevokeOtherSessions(c *gin.Context) {
+	user, ok := getAuthenticatedUser(c)
+	if !ok {
+		return
+	}
+
+	revoked, err := s.sessionService.RevokeOtherSessions(user, getAuthenticatedSession(c))
+	if err != nil {
+		logrus.Errorf("Failed to revoke other sessions, error: %v", err)
+		writeSessionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res.CommonResponse{
+		StatusCode: http.StatusOK,
+		Message:    constants.SessionRevokeOthersSuccess,
+		Data:       res.RevokedSessionsResponse{Revoked: revoked},
+	})
+}
+
+// writeSessionError maps a session error to its status code
+func writeSessionError(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	switch err.Error() {
+	case constants.SessionNotFoundError:
+		status = http.StatusNotFound
+	case constants.SessionUnknownError:
+		status = http.StatusBadRequest
+	}
+	c.JSON(status, res.ErrorResponse{
+		StatusCode:   status,
+		ErrorMessage: err.Error(),
+	})
+}
@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"PaymentAPI/constants"
+	req "PaymentAPI/dto/request"
+	res "PaymentAPI/dto/response"
+	"PaymentAPI/service"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"net/http"
+)
+
+type SplitBillHandler interface {
+	HandleCreateSplitBill(c *gin.Context)
+	HandleGetSplitBillById(c *gin.Context)
+}
+
+type splitBillHandler struct {
+	splitBillService service.SplitBillService
+	walletService    service.WalletService
+}
+
+// NewSplitBillHandler creates a new instance of SplitBillHandler.
+func NewSplitBillHandler(splitBillService service.SplitBillService, walletService service.WalletService) SplitBillHandler {
+	return &splitBillHandler{splitBillService, walletService}
+}
+
+// HandleCreateSplitBill splits a bill paid from the authenticated user's wallet and requests every participant's share.
+func (s *splitBillHandler) HandleCreateSplitBill(c *gin.Context) {
+	var request req.CreateSplitBillRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		logrus.Warn("Invalid request body for split bill creation")
+		c.JSON(http.StatusBadRequest, res.ErrorResponse{
+			StatusCode:   http.StatusBadRequest,
+			ErrorMessage: constants.InvalidRequestBodyError,
+		})
+		return
+	}
+
+	user, ok := getAuthenticatedUser(c)
+	if !ok {
+		return
+	}
+
+	// Only the owner of a wallet can request money into it
+	if !ownsWallet(s.walletService, request.CreatorWalletId, user) {
+		logrus.Warnf("User %v attempted unauthorized access to wallet ID: %s", user, request.CreatorWalletId)
+		c.JSON(http.StatusForbidden, res.ErrorResponse{
+			StatusCode:   http.StatusForbidden,
+			ErrorMessage: constants.WalletForbiddenAccess,
+		})
+		return
+	}
+
+	splitBill, err := s.splitBillService.CreateSplitBill(request)
+	if err != nil {
+		logrus.Errorf("Failed to create split bill, error: %v", err)
+		c.JSON(http.StatusBadRequest, res.ErrorResponse{
+			StatusCode:   http.StatusBadRequest,
+			ErrorMessage: err.Error(),
+		})
+		return
+	}
+
+	logrus.Infof("Split bill successfully created: %s", splitBill.Id)
+	c.JSON(http.StatusCreated, res.CommonResponse{
+		StatusCode: http.StatusCreated,
+		Message:    constants.SplitBillCreateSuccess,
+		Data:       splitBill,
+	})
+}
+
+// HandleGetSplitBillById returns the progress of a split bill to its creator or any of its participants.
+func (s *splitBillHandler) HandleGetSplitBillById(c *gin.Context) {
+	user, ok := getAuthenticatedUser(c)
+	if !ok {
+		return
+	}
+
+	splitBill, err := s.splitBillService.GetSplitBillById(c.Param("id"))
+	if err != nil {
+		logrus.Errorf("Failed to fetch split bill with ID: %s, error: %v", c.Param("id"), err)
+		c.JSON(http.StatusNotFound, res.ErrorResponse{
+			StatusCode:   http.StatusNotFound,
+			ErrorMessage: err.Error(),
+		})
+		return
+	}
+
+	allowed := ownsWallet(s.walletService, splitBill.CreatorWalletId, user)
+	for _, participant := range splitBill.Participants {
+		allowed = allowed || ownsWallet(s.walletService, participant.WalletId, user)
+	}
+	if !allowed {
+		logrus.Warnf("User %v attempted unauthorized access to split bill ID: %s", user, splitBill.Id)
+		c.JSON(http.StatusForbidden, res.ErrorResponse{
+			StatusCode:   http.StatusForbidden,
+			ErrorMessage: constants.SplitBillForbiddenAccess,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, res.CommonResponse{
+		StatusCode: http.StatusOK,
+		Message:    constants.SplitBillFindSuccess,
+		Data:       splitBill,
+	})
+}
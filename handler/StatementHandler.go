@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"PaymentAPI/constants"
+	req "PaymentAPI/dto/request"
+	res "PaymentAPI/dto/response"
+	"PaymentAPI/entity"
+	"PaymentAPI/service"
+	"PaymentAPI/utils"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"io"
+	"net/http"
+	"strings"
+)
+
+type StatementHandler interface {
+	HandleGetStatement(c *gin.Context)
+}
+
+type statementHandler struct {
+	statementService service.StatementService
+	walletService    service.WalletService
+}
+
+// statementFormat is how a statement is downloaded in a format other than JSON
+type statementFormat struct {
+	contentType string
+	extension   string
+	write       func(writer io.Writer, statement entity.Statement) error
+}
+
+var statementFormats = map[string]statementFormat{
+	"csv":     {"text/csv", "csv", utils.WriteStatementCsv},
+	"ofx":     {"application/x-ofx", "ofx", utils.WriteStatementOfx},
+	"pdf":     {"application/pdf", "pdf", utils.WriteStatementPdf},
+	"camt053": {"application/xml", "xml", utils.WriteStatementCamt053},
+}
+
+// NewStatementHandler creates a new instance of StatementHandler.
+func NewStatementHandler(statementService service.StatementService, walletService service.WalletService) StatementHandler {
+	return &statementHandler{statementService, walletService}
+}
+
+// HandleGetStatement returns the statement of the authenticated user's wallet for the period in the "month" query, or
+// the "from" and "to" queries, as JSON or downloaded as the file format in the "format" query.
+func (s *statementHandler) HandleGetStatement(c *gin.Context) {
+	user, ok := getAuthenticatedUser(c)
+	if !ok {
+		return
+	}
+
+	walletId := c.Param("id")
+	if !ownsWallet(s.walletService, walletId, user) {
+		logrus.Warnf("User %v attempted unauthorized access to statement of wallet ID: %s", user, walletId)
+		c.JSON(http.StatusForbidden, res.ErrorResponse{
+			StatusCode:   http.StatusForbidden,
+			ErrorMessage: constants.WalletForbiddenAccess,
+		})
+		return
+	}
+
+	formatName := strings.ToLower(c.DefaultQuery("format", "json"))
+	format, known := statementFormats[formatName]
+	if !known && formatName != "json" {
+		c.JSON(http.StatusBadRequest, res.ErrorResponse{
+			StatusCode:   http.StatusBadRequest,
+			ErrorMessage: constants.StatementFormatError,
+		})
+		return
+	}
+
+	statement, err := s.statementService.GetStatement(walletId, req.StatementRequest{
+		Month: c.Query("month"),
+		From:  c.Query("from"),
+		To:    c.Query("to"),
+	})
+	if err != nil {
+		logrus.Errorf("Failed to generate statement for wallet ID: %s, error: %v", walletId, err)
+		c.JSON(http.StatusBadRequest, res.ErrorResponse{
+			StatusCode:   http.StatusBadRequest,
+			ErrorMessage: err.Error(),
+		})
+		return
+	}
+
+	if !known {
+		c.JSON(http.StatusOK, res.CommonResponse{
+			StatusCode: http.StatusOK,
+			Message:    constants.StatementFindSuccess,
+			Data:       statement,
+		})
+		return
+	}
+
+	c.Header("Content-Type", format.contentType)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=statement-%s-%s.%s", statement.PeriodStart, statement.PeriodEnd, format.extension))
+	c.Status(http.StatusOK)
+	if err := format.write(c.Writer, statement); err != nil {
+		logrus.Errorf("Failed to write statement for wallet ID: %s, error: %v", walletId, err)
+	}
+}
@@ -12,16 +12,18 @@ import (
 
 type TransactionHandler interface {
 	HandleCreateTransaction(c *gin.Context)
+	HandleSetTransactionPin(c *gin.Context)
 }
 
 type transactionHandler struct {
-	transactionService service.TransactionService
-	walletService      service.WalletService
+	transactionService    service.TransactionService
+	walletService         service.WalletService
+	transactionPinService service.TransactionPinService
 }
 
 // NewTransactionHandler creates a new instance of TransactionHandler.
-func NewTransactionHandler(transactionService service.TransactionService, walletService service.WalletService) TransactionHandler {
-	return &transactionHandler{transactionService, walletService}
+func NewTransactionHandler(transactionService service.TransactionService, walletService service.WalletService, transactionPinService service.TransactionPinService) TransactionHandler {
+	return &transactionHandler{transactionService, walletService, transactionPinService}
 }
 
 // HandleCreateTransaction handles the request to create a new transaction.
@@ -74,10 +76,13 @@ func (t transactionHandler) HandleCreateTransaction(c *gin.Context) {
 
 	logrus.Infof("Creating transaction for wallet ID: %s by user: %v", request.FromWalletId, user)
 
-	// Create the transaction and handle potential errors
+	// Create the transaction and handle potential errors, the transaction PIN or a two-factor code is checked with it
 	response, err := t.transactionService.CreateNewTransaction(request)
 	if err != nil {
 		logrus.Errorf("Failed to create transaction, error: %v", err)
+		if writeStepUpError(c, err) {
+			return
+		}
 		c.JSON(http.StatusBadRequest, res.ErrorResponse{
 			StatusCode:   http.StatusBadRequest,
 			ErrorMessage: err.Error(),
@@ -94,3 +99,44 @@ func (t transactionHandler) HandleCreateTransaction(c *gin.Context) {
 	})
 	return
 }
+
+// HandleSetTransactionPin sets or replaces the transaction PIN of the authenticated user.
+func (t transactionHandler) HandleSetTransactionPin(c *gin.Context) {
+	user, ok := getAuthenticatedUser(c)
+	if !ok {
+		return
+	}
+
+	var request req.SetTransactionPinRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		logrus.Warn("Invalid request body for setting the transaction PIN")
+		c.JSON(http.StatusBadRequest, res.ErrorResponse{
+			StatusCode:   http.StatusBadRequest,
+			ErrorMessage: constants.InvalidRequestBodyError,
+		})
+		return
+	}
+
+	if err := t.transactionPinService.SetPin(user, request); err != nil {
+		logrus.Warnf("Failed to set transaction PIN for user %v, error: %v", user, err)
+		status := http.StatusInternalServerError
+		switch err.Error() {
+		case constants.TransactionPinFormatError, constants.TransactionPinWeakError:
+			status = http.StatusBadRequest
+		case constants.PasswordIncorrectError, constants.TwoFactorCodeInvalidError:
+			status = http.StatusUnauthorized
+		}
+		c.JSON(status, res.ErrorResponse{
+			StatusCode:   status,
+			ErrorMessage: err.Error(),
+		})
+		return
+	}
+
+	logrus.Infof("Transaction PIN set for user %v", user)
+	c.JSON(http.StatusOK, res.CommonResponse{
+		StatusCode: http.StatusOK,
+		Message:    constants.TransactionPinSetSuccess,
+		Data:       []interface{}{},
+	})
+}
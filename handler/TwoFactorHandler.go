@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"PaymentAPI/constants"
+	req "PaymentAPI/dto/request"
+	res "PaymentAPI/dto/response"
+	"PaymentAPI/service"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"net/http"
+)
+
+type TwoFactorHandler interface {
+	HandleEnroll(c *gin.Context)
+	HandleEnable(c *gin.Context)
+	HandleRegenerateRecoveryCodes(c *gin.Context)
+	HandleDisable(c *gin.Context)
+}
+
+type twoFactorHandler struct {
+	twoFactorService service.TwoFactorService
+}
+
+// NewTwoFactorHandler creates a new instance of TwoFactorHandler.
+func NewTwoFactorHandler(twoFactorService service.TwoFactorService) TwoFactorHandler {
+	return &twoFactorHandler{twoFactorService}
+}
+
+// HandleEnroll creates a TOTP secret for the authenticated user and returns it with its provisioning URI.
+func (t *twoFactorHandler) HandleEnroll(c *gin.Context) {
+	user, ok := getAuthenticatedUser(c)
+	if !ok {
+		return
+	}
+
+	enrollment, err := t.twoFactorService.Enroll(user)
+	if err != nil {
+		logrus.Errorf("Failed to enroll two-factor authentication, error: %v", err)
+		writeTwoFactorError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res.CommonResponse{
+		StatusCode: http.StatusOK,
+		Message:    constants.TwoFactorEnrollSuccess,
+		Data:       enrollment,
+	})
+}
+
+// HandleEnable turns on two-factor authentication with a first code and returns the recovery codes.
+func (t *twoFactorHandler) HandleEnable(c *gin.Context) {
+	user, ok := getAuthenticatedUser(c)
+	if !ok {
+		return
+	}
+
+	var request req.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		logrus.Warnf("Invalid enable two-factor request: %v", err)
+		c.JSON(http.StatusBadRequest, res.ErrorResponse{
+			StatusCode:   http.StatusBadRequest,
+			ErrorMessage: constants.InvalidRequestBodyError,
+		})
+		return
+	}
+
+	recoveryCodes, err := t.twoFactorService.Enable(user, request)
+	if err != nil {
+		logrus.Errorf("Failed to enable two-factor authentication, error: %v", err)
+		writeTwoFactorError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res.CommonResponse{
+		StatusCode: http.StatusOK,
+		Message:    constants.TwoFactorEnableSuccess,
+		Data:       recoveryCodes,
+	})
+}
+
+// HandleRegenerateRecoveryCodes replaces the recovery codes of the authenticated user.
+func (t *twoFactorHandler) HandleRegenerateRecoveryCodes(c *gin.Context) {
+	user, ok := getAuthenticatedUser(c)
+	if !ok {
+		return
+	}
+
+	var request req.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		logrus.Warnf("Invalid regenerate recovery codes request: %v", err)
+		c.JSON(http.StatusBadRequest, res.ErrorResponse{
+			StatusCode:   http.StatusBadRequest,
+			ErrorMessage: constants.InvalidRequestBodyError,
+		})
+		return
+	}
+
+	recoveryCodes, err := t.twoFactorService.RegenerateRecoveryCodes(user, request)
+	if err != nil {
+		logrus.Errorf("Failed to regenerate recovery codes, error: %v", err)
+		writeTwoFactorError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res.CommonResponse{
+		StatusCode: http.StatusOK,
+		Message:    constants.TwoFactorRecoveryCodesSuccess,
+		Data:       recoveryCodes,
+	})
+}
+
+// HandleDisable turns off two-factor authentication for the authenticated user.
+func (t *twoFactorHandler) HandleDisable(c *gin.Context) {
+	user, ok := getAuthenticatedUser(c)
+	if !ok {
+		return
+	}
+
+	var request req.DisableTwoFactorRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		logrus.Warnf("Invalid disable two-factor request: %v", err)
+		c.JSON(http.StatusBadRequest, res.ErrorResponse{
+			StatusCode:   http.StatusBadRequest,
+			ErrorMessage: constants.InvalidRequestBodyError,
+		})
+		return
+	}
+
+	if err := t.twoFactorService.Disable(user, request); err != nil {
+		logrus.Errorf("Failed to disable two-factor authentication, error: %v", err)
+		writeTwoFactorError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res.CommonResponse{
+		StatusCode: http.StatusOK,
+		Message:    constants.TwoFactorDisableSuccess,
+		Data:       []interface{}{},
+	})
+}
+
+// writeTwoFactorError maps a two-factor error to its status code
+func writeTwoFactorError(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	switch err.Error() {
+	case constants.TwoFactorCodeInvalidError, constants.PasswordIncorrectError:
+		status = http.StatusUnauthorized
+	case constants.TwoFactorNotFoundError, constants.TwoFactorNotEnabledError, constants.TwoFactorAlreadyEnabledError:
+		status = http.StatusBadRequest
+	}
+	c.JSON(status, res.ErrorResponse{
+		StatusCode:   status,
+		ErrorMessage: err.Error(),
+	})
+}
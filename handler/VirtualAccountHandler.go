@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"PaymentAPI/constants"
+	res "PaymentAPI/dto/response"
+	"PaymentAPI/service"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"io"
+	"net/http"
+)
+
+type VirtualAccountHandler interface {
+	HandleGetWalletVirtualAccount(c *gin.Context)
+	HandleGetVirtualAccountByNumber(c *gin.Context)
+	HandleBankNotification(c *gin.Context)
+}
+
+type virtualAccountHandler struct {
+	virtualAccountService service.VirtualAccountService
+	walletService         service.WalletService
+}
+
+// NewVirtualAccountHandler creates a new instance of VirtualAccountHandler.
+func NewVirtualAccountHandler(virtualAccountService service.VirtualAccountService, walletService service.WalletService) VirtualAccountHandler {
+	return &virtualAccountHandler{virtualAccountService, walletService}
+}
+
+// HandleGetWalletVirtualAccount returns the virtual account number of the authenticated user's wallet, assigning one on first use.
+func (v *virtualAccountHandler) HandleGetWalletVirtualAccount(c *gin.Context) {
+	user, ok := getAuthenticatedUser(c)
+	if !ok {
+		return
+	}
+
+	walletId := c.Param("id")
+	if !ownsWallet(v.walletService, walletId, user) {
+		logrus.Warnf("User %v attempted unauthorized access to wallet ID: %s", user, walletId)
+		c.JSON(http.StatusForbidden, res.ErrorResponse{
+			StatusCode:   http.StatusForbidden,
+			ErrorMessage: constants.WalletForbiddenAccess,
+		})
+		return
+	}
+
+	virtualAccount, err := v.virtualAccountService.GetOrCreateVirtualAccount(walletId)
+	if err != nil {
+		logrus.Errorf("Failed to get virtual account for wallet ID: %s, error: %v", walletId, err)
+		c.JSON(http.StatusInternalServerError, res.ErrorResponse{
+			StatusCode:   http.StatusInternalServerError,
+			ErrorMessage: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, res.CommonResponse{
+		StatusCode: http.StatusOK,
+		Message:    constants.VirtualAccountFindSuccess,
+		Data:       virtualAccount,
+	})
+}
+
+// HandleGetVirtualAccountByNumber looks up the wallet a virtual account number credits.
+func (v *virtualAccountHandler) HandleGetVirtualAccountByNumber(c *gin.Context) {
+	virtualAccount, err := v.virtualAccountService.GetVirtualAccountByNumber(c.Param("number"))
+	if err != nil {
+		logrus.Errorf("Failed to find virtual account number: %s, error: %v", c.Param("number"), err)
+		status := http.StatusNotFound
+		if err.Error() == constants.VirtualAccountInvalidError {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, res.ErrorResponse{
+			StatusCode:   status,
+			ErrorMessage: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, res.CommonResponse{
+		StatusCode: http.StatusOK,
+		Message:    constants.VirtualAccountFindSuccess,
+		Data:       virtualAccount,
+	})
+}
+
+// HandleBankNotification credits a deposit the bank received on a virtual account. The bank signs the raw body
+// and the timestamp in the X-Bank-Timestamp and X-Bank-Signature headers instead of using a user token.
+func (v *virtualAccountHandler) HandleBankNotification(c *gin.Context) {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		logrus.Warn("Failed to read bank notification body")
+		c.JSON(http.StatusBadRequest, res.ErrorResponse{
+			StatusCode:   http.StatusBadRequest,
+			ErrorMessage: constants.InvalidRequestBodyError,
+		})
+		return
+	}
+
+	response, err := v.virtualAccountService.HandleBankNotification(body, c.GetHeader("X-Bank-Timestamp"), c.GetHeader("X-Bank-Signature"))
+	if err != nil {
+		logrus.Errorf("Failed to process bank notification, error: %v", err)
+		var status int
+		switch err.Error() {
+		case constants.BankNotificationSignatureError, constants.BankNotificationExpiredError:
+			status = http.StatusUnauthorized
+		case constants.VirtualAccountNotFoundError:
+			status = http.StatusNotFound
+		default:
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, res.ErrorResponse{
+			StatusCode:   status,
+			ErrorMessage: err.Error(),
+		})
+		return
+	}
+
+	message := constants.BankNotificationSuccess
+	if response.Duplicate {
+		message = constants.BankNotificationDuplicateSuccess
+	}
+	c.JSON(http.StatusOK, res.CommonResponse{
+		StatusCode: http.StatusOK,
+		Message:    message,
+		Data:       response,
+	})
+}
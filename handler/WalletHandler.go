@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"PaymentAPI/constants"
+	req "PaymentAPI/dto/request"
+	res "PaymentAPI/dto/response"
+	"PaymentAPI/entity"
+	"PaymentAPI/service"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"io"
+	"net/http"
+)
+
+type WalletHandler interface {
+	HandleFreezeWallet(c *gin.Context)
+	HandleUnfreezeWallet(c *gin.Context)
+	HandleCloseWallet(c *gin.Context)
+}
+
+type walletHandler struct {
+	walletService      service.WalletService
+	transactionService service.TransactionService
+}
+
+// NewWalletHandler creates a new instance of WalletHandler.
+func NewWalletHandler(walletService service.WalletService, transactionService service.TransactionService) WalletHandler {
+	return &walletHandler{walletService, transactionService}
+}
+
+// HandleFreezeWallet blocks the authenticated user's wallet from sending and receiving funds.
+func (w *walletHandler) HandleFreezeWallet(c *gin.Context) {
+	wallet, ok := w.getOwnedWallet(c)
+	if !ok {
+		return
+	}
+
+	if err := w.walletService.FreezeWallet(wallet.Id); err != nil {
+		logrus.Errorf("Failed to freeze wallet ID: %s, error: %v", wallet.Id, err)
+		c.JSON(http.StatusBadRequest, res.ErrorResponse{
+			StatusCode:   http.StatusBadRequest,
+			ErrorMessage: err.Error(),
+		})
+		return
+	}
+
+	logrus.Infof("Wallet ID: %s frozen", wallet.Id)
+	c.JSON(http.StatusOK, res.CommonResponse{
+		StatusCode: http.StatusOK,
+		Message:    constants.WalletFreezeSuccess,
+		Data:       []interface{}{},
+	})
+}
+
+// HandleUnfreezeWallet makes a frozen wallet active again. Only an admin can unfreeze, an access token of the owner
+// alone must not undo the freeze of a compromised account.
+func (w *walletHandler) HandleUnfreezeWallet(c *gin.Context) {
+	walletId := c.Param("id")
+
+	wallet, err := w.walletService.GetWalletById(walletId)
+	if err != nil {
+		logrus.Errorf("Failed to fetch wallet with ID: %s, error: %v", walletId, err)
+		c.JSON(http.StatusNotFound, res.ErrorResponse{
+			StatusCode:   http.StatusNotFound,
+			ErrorMessage: err.Error(),
+		})
+		return
+	}
+
+	if err := w.walletService.UnfreezeWallet(wallet.Id); err != nil {
+		logrus.Errorf("Failed to unfreeze wallet ID: %s, error: %v", wallet.Id, err)
+		c.JSON(http.StatusBadRequest, res.ErrorResponse{
+			StatusCode:   http.StatusBadRequest,
+			ErrorMessage: err.Error(),
+		})
+		return
+	}
+
+	logrus.Infof("Wallet ID: %s unfrozen", wallet.Id)
+	c.JSON(http.StatusOK, res.CommonResponse{
+		StatusCode: http.StatusOK,
+		Message:    constants.WalletUnfreezeSuccess,
+		Data:       []interface{}{},
+	})
+}
+
+// HandleCloseWallet closes the authenticated user's wallet.
+// A remaining balance is first paid out to the given payout wallet, otherwise the balance must already be zero.
+func (w *walletHandler) HandleCloseWallet(c *gin.Context) {
+	var request req.CloseWalletRequest
+
+	// The request body is optional, it is only needed for a payout
+	if err := c.ShouldBindJSON(&request); err != nil && err != io.EOF {
+		logrus.Warn("Invalid request body for closing wallet")
+		c.JSON(http.StatusBadRequest, res.ErrorResponse{
+			StatusCode:   http.StatusBadRequest,
+			ErrorMessage: constants.InvalidRequestBodyError,
+		})
+		return
+	}
+
+	wallet, ok := w.getOwnedWallet(c)
+	if !ok {
+		return
+	}
+
+	if err := w.transactionService.CloseWallet(wallet.Id, request); err != nil {
+		logrus.Errorf("Failed to close wallet ID: %s, error: %v", wallet.Id, err)
+		if writeStepUpError(c, err) {
+			return
+		}
+		c.JSON(http.StatusBadRequest, res.ErrorResponse{
+			StatusCode:   http.StatusBadRequest,
+			ErrorMessage: err.Error(),
+		})
+		return
+	}
+
+	logrus.Infof("Wallet ID: %s closed", wallet.Id)
+	c.JSON(http.StatusOK, res.CommonResponse{
+		StatusCode: http.StatusOK,
+		Message:    constants.WalletCloseSuccess,
+		Data:       []interface{}{},
+	})
+}
+
+// getOwnedWallet loads the wallet from the path and makes sure it belongs to the authenticated user.
+// It writes the error response itself and returns false when the request cannot continue.
+func (w *walletHandler) getOwnedWallet(c *gin.Context) (entity.Wallet, bool) {
+	walletId := c.Param("id")
+
+	user, ok := getAuthenticatedUser(c)
+	if !ok {
+		return entity.Wallet{}, false
+	}
+
+	wallet, err := w.walletService.GetWalletById(walletId)
+	if err != nil {
+		logrus.Errorf("Failed to fetch wallet with ID: %s, error: %v", walletId, err)
+		c.JSON(http.StatusNotFound, res.ErrorResponse{
+			StatusCode:   http.StatusNotFound,
+			ErrorMessage: err.Error(),
+		})
+		return entity.Wallet{}, false
+	}
+
+	if wallet.CustomerId != user {
+		logrus.Warnf("User %v attempted unauthorized access to wallet ID: %s", user, walletId)
+		c.JSON(http.StatusForbidden, res.ErrorResponse{
+			StatusCode:   http.StatusForbidden,
+			ErrorMessage: constants.WalletForbiddenAccess,
+		})
+		return entity.Wallet{}, false
+	}
+
+	return wallet, true
+}
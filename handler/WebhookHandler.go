@@ -0,0 +1,222 @@
+package handler
+
+import (
+	"PaymentAPI/constants"
+	req "PaymentAPI/dto/request"
+	res "PaymentAPI/dto/response"
+	"PaymentAPI/entity"
+	"PaymentAPI/service"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"net/http"
+)
+
+type WebhookHandler interface {
+	HandleCreateWebhookEndpoint(c *gin.Context)
+	HandleGetWebhookEndpoints(c *gin.Context)
+	HandleDeleteWebhookEndpoint(c *gin.Context)
+	HandleGetWebhookDeliveries(c *gin.Context)
+	HandleRedeliverWebhook(c *gin.Context)
+}
+
+type webhookHandler struct {
+	webhookService service.WebhookService
+}
+
+// NewWebhookHandler creates a new instance of WebhookHandler.
+func NewWebhookHandler(webhookService service.WebhookService) WebhookHandler {
+	return &webhookHandler{webhookService}
+}
+
+// HandleCreateWebhookEndpoint registers a webhook endpoint for the authenticated user's wallet events.
+// The signing secret is only returned here.
+func (w *webhookHandler) HandleCreateWebhookEndpoint(c *gin.Context) {
+	var request req.CreateWebhookEndpointRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		logrus.Warn("Invalid request body for webhook endpoint creation")
+		c.JSON(http.StatusBadRequest, res.ErrorResponse{
+			StatusCode:   http.StatusBadRequest,
+			ErrorMessage: constants.InvalidRequestBodyError,
+		})
+		return
+	}
+
+	user, ok := getAuthenticatedUser(c)
+	if !ok {
+		return
+	}
+
+	webhookEndpoint, err := w.webhookService.CreateEndpoint(user, request)
+	if err != nil {
+		logrus.Errorf("Failed to create webhook endpoint, error: %v", err)
+		c.JSON(http.StatusBadRequest, res.ErrorResponse{
+			StatusCode:   http.StatusBadRequest,
+			ErrorMessage: err.Error(),
+		})
+		return
+	}
+
+	response := toWebhookEndpointResponse(webhookEndpoint)
+	response.Secret = webhookEndpoint.Secret
+
+	logrus.Infof("Webhook endpoint successfully created: %s", webhookEndpoint.Id)
+	c.JSON(http.StatusCreated, res.CommonResponse{
+		StatusCode: http.StatusCreated,
+		Message:    constants.WebhookEndpointCreateSuccess,
+		Data:       response,
+	})
+}
+
+// HandleGetWebhookEndpoints lists the authenticated user's webhook endpoints.
+func (w *webhookHandler) HandleGetWebhookEndpoints(c *gin.Context) {
+	user, ok := getAuthenticatedUser(c)
+	if !ok {
+		return
+	}
+
+	webhookEndpoints, err := w.webhookService.GetEndpoints(user)
+	if err != nil {
+		logrus.Errorf("Failed to fetch webhook endpoints for customer ID: %s, error: %v", user, err)
+		c.JSON(http.StatusInternalServerError, res.ErrorResponse{
+			StatusCode:   http.StatusInternalServerError,
+			ErrorMessage: err.Error(),
+		})
+		return
+	}
+
+	response := []res.WebhookEndpointResponse{}
+	for _, webhookEndpoint := range webhookEndpoints {
+		response = append(response, toWebhookEndpointResponse(webhookEndpoint))
+	}
+
+	c.JSON(http.StatusOK, res.CommonResponse{
+		StatusCode: http.StatusOK,
+		Message:    constants.WebhookEndpointFindSuccess,
+		Data:       response,
+	})
+}
+
+// HandleDeleteWebhookEndpoint removes one of the authenticated user's webhook endpoints.
+func (w *webhookHandler) HandleDeleteWebhookEndpoint(c *gin.Context) {
+	webhookEndpoint, ok := w.getWebhookEndpoint(c)
+	if !ok {
+		return
+	}
+
+	if err := w.webhookService.DeleteEndpoint(webhookEndpoint.Id); err != nil {
+		logrus.Errorf("Failed to delete webhook endpoint ID: %s, error: %v", webhookEndpoint.Id, err)
+		c.JSON(http.StatusInternalServerError, res.ErrorResponse{
+			StatusCode:   http.StatusInternalServerError,
+			ErrorMessage: err.Error(),
+		})
+		return
+	}
+
+	logrus.Infof("Webhook endpoint ID: %s deleted", webhookEndpoint.Id)
+	c.JSON(http.StatusOK, res.CommonResponse{
+		StatusCode: http.StatusOK,
+		Message:    constants.WebhookEndpointDeleteSuccess,
+		Data:       []interface{}{},
+	})
+}
+
+// HandleGetWebhookDeliveries returns the delivery log of one of the authenticated user's webhook endpoints.
+func (w *webhookHandler) HandleGetWebhookDeliveries(c *gin.Context) {
+	webhookEndpoint, ok := w.getWebhookEndpoint(c)
+	if !ok {
+		return
+	}
+
+	webhookDeliveries, err := w.webhookService.GetDeliveries(webhookEndpoint.Id)
+	if err != nil {
+		logrus.Errorf("Failed to fetch deliveries of webhook endpoint ID: %s, error: %v", webhookEndpoint.Id, err)
+		c.JSON(http.StatusInternalServerError, res.ErrorResponse{
+			StatusCode:   http.StatusInternalServerError,
+			ErrorMessage: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, res.CommonResponse{
+		StatusCode: http.StatusOK,
+		Message:    constants.WebhookDeliveryFindSuccess,
+		Data:       webhookDeliveries,
+	})
+}
+
+// HandleRedeliverWebhook sends a delivery of one of the authenticated user's webhook endpoints again.
+func (w *webhookHandler) HandleRedeliverWebhook(c *gin.Context) {
+	webhookEndpoint, ok := w.getWebhookEndpoint(c)
+	if !ok {
+		return
+	}
+
+	webhookDelivery, err := w.webhookService.GetDeliveryById(c.Param("deliveryId"))
+	if err != nil || webhookDelivery.EndpointId != webhookEndpoint.Id {
+		logrus.Errorf("Failed to fetch webhook delivery with ID: %s", c.Param("deliveryId"))
+		c.JSON(http.StatusNotFound, res.ErrorResponse{
+			StatusCode:   http.StatusNotFound,
+			ErrorMessage: constants.WebhookDeliveryNotFoundError,
+		})
+		return
+	}
+
+	webhookDelivery, err = w.webhookService.Redeliver(webhookDelivery.Id)
+	if err != nil {
+		logrus.Errorf("Failed to redeliver webhook delivery ID: %s, error: %v", c.Param("deliveryId"), err)
+		status := http.StatusInternalServerError
+		if err.Error() == constants.WebhookDeliveryInProgressError {
+			status = http.StatusConflict
+		}
+		c.JSON(status, res.ErrorResponse{
+			StatusCode:   status,
+			ErrorMessage: err.Error(),
+		})
+		return
+	}
+
+	logrus.Infof("Webhook delivery ID: %s sent again with status %s", webhookDelivery.Id, webhookDelivery.Status)
+	c.JSON(http.StatusOK, res.CommonResponse{
+		StatusCode: http.StatusOK,
+		Message:    constants.WebhookRedeliverSuccess,
+		Data:       webhookDelivery,
+	})
+}
+
+// getWebhookEndpoint loads the webhook endpoint from the path and checks that the authenticated user registered it
+func (w *webhookHandler) getWebhookEndpoint(c *gin.Context) (entity.WebhookEndpoint, bool) {
+	user, ok := getAuthenticatedUser(c)
+	if !ok {
+		return entity.WebhookEndpoint{}, false
+	}
+
+	webhookEndpoint, err := w.webhookService.GetEndpointById(c.Param("id"))
+	if err != nil {
+		logrus.Errorf("Failed to fetch webhook endpoint with ID: %s, error: %v", c.Param("id"), err)
+		c.JSON(http.StatusNotFound, res.ErrorResponse{
+			StatusCode:   http.StatusNotFound,
+			ErrorMessage: err.Error(),
+		})
+		return entity.WebhookEndpoint{}, false
+	}
+
+	if webhookEndpoint.CustomerId != user {
+		logrus.Warnf("User %v attempted unauthorized access to webhook endpoint ID: %s", user, webhookEndpoint.Id)
+		c.JSON(http.StatusForbidden, res.ErrorResponse{
+			StatusCode:   http.StatusForbidden,
+			ErrorMessage: constants.WebhookEndpointForbiddenAccess,
+		})
+		return entity.WebhookEndpoint{}, false
+	}
+	return webhookEndpoint, true
+}
+
+func toWebhookEndpointResponse(webhookEndpoint entity.WebhookEndpoint) res.WebhookEndpointResponse {
+	return res.WebhookEndpointResponse{
+		Id:        webhookEndpoint.Id,
+		Url:       webhookEndpoint.Url,
+		Events:    webhookEndpoint.Events,
+		CreatedAt: webhookEndpoint.CreatedAt,
+	}
+}
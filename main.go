@@ -3,45 +3,206 @@ package main
 import (
 	"PaymentAPI/config"
 	"PaymentAPI/entity"
+	"PaymentAPI/enums"
 	"PaymentAPI/handler"
 	"PaymentAPI/middleware"
 	"PaymentAPI/repository"
 	"PaymentAPI/service"
 	"PaymentAPI/storage"
+	"PaymentAPI/utils"
 	"github.com/gin-gonic/gin"
+	"log"
+	"os"
+	"time"
 )
 
 func main() {
 	config.InitConfig()
+	keyring := initStorageEncryption()
+	initJwtKeys()
 
 	customerRepository := repository.NewCustomerRepository(storage.NewJsonFileHandler[entity.Customer]())
 	walletRepository := repository.NewWalletRepository(storage.NewJsonFileHandler[entity.Wallet]())
+	holdRepository := repository.NewHoldRepository(storage.NewJsonFileHandler[entity.Hold]())
+	paymentRequestRepository := repository.NewPaymentRequestRepository(storage.NewJsonFileHandler[entity.PaymentRequest]())
+	splitBillRepository := repository.NewSplitBillRepository(storage.NewJsonFileHandler[entity.SplitBill]())
+	payoutBatchRepository := repository.NewPayoutBatchRepository(storage.NewJsonFileHandler[entity.PayoutBatch]())
+	merchantRepository := repository.NewMerchantRepository(storage.NewJsonFileHandler[entity.Merchant]())
+	checkoutSessionRepository := repository.NewCheckoutSessionRepository(storage.NewJsonFileHandler[entity.CheckoutSession]())
+	virtualAccountRepository := repository.NewVirtualAccountRepository(storage.NewJsonFileHandler[entity.VirtualAccount]())
+	billerProductRepository := repository.NewBillerProductRepository(storage.NewJsonFileHandler[entity.BillerProduct]())
+	billPaymentRepository := repository.NewBillPaymentRepository(storage.NewJsonFileHandler[entity.BillPayment]())
+	webhookEndpointRepository := repository.NewWebhookEndpointRepository(storage.NewJsonFileHandler[entity.WebhookEndpoint]())
+	webhookDeliveryRepository := repository.NewWebhookDeliveryRepository(storage.NewJsonFileHandler[entity.WebhookDelivery]())
+	outboxEventRepository := repository.NewOutboxEventRepository(storage.NewJsonFileHandler[entity.OutboxEvent]())
+	passwordResetTokenRepository := repository.NewPasswordResetTokenRepository(storage.NewJsonFileHandler[entity.PasswordResetToken]())
+	loginAttemptRepository := repository.NewLoginAttemptRepository(storage.NewJsonFileHandler[entity.LoginAttempt]())
+	twoFactorRepository := repository.NewTwoFactorRepository(storage.NewJsonFileHandler[entity.TwoFactor]())
+	loginChallengeRepository := repository.NewLoginChallengeRepository(storage.NewJsonFileHandler[entity.LoginChallenge]())
+	transactionPinRepository := repository.NewTransactionPinRepository(storage.NewJsonFileHandler[entity.TransactionPin]())
 	refreshTokenRepository := repository.NewRefreshTokenRepository(storage.NewJsonFileHandler[entity.RefreshToken]())
+	sessionRepository := repository.NewSessionRepository(storage.NewJsonFileHandler[entity.Session]())
 	blacklistRepository := repository.NewBlacklistRepository(storage.NewJsonFileHandler[entity.Blacklist]())
+	tokenWatermarkRepository := repository.NewTokenWatermarkRepository(storage.NewJsonFileHandler[entity.TokenWatermark]())
 	transactionRepository := repository.NewTransactionRepository(storage.NewJsonFileHandler[entity.Transaction]())
+	ledgerCheckpointRepository := repository.NewLedgerCheckpointRepository(storage.NewJsonFileHandler[entity.LedgerCheckpoint]())
+	reconciliationReportRepository := repository.NewReconciliationReportRepository(storage.NewJsonFileHandler[entity.ReconciliationReport]())
 
-	walletService := service.NewWalletService(walletRepository)
+	eventBus := service.NewEventBus(outboxEventRepository)
+	webhookService := service.NewWebhookService(webhookEndpointRepository, webhookDeliveryRepository, walletRepository)
+	analyticsService := service.NewAnalyticsService()
+	walletService := service.NewWalletService(walletRepository, holdRepository, eventBus)
 	refreshTokenService := service.NewRefreshTokenService(refreshTokenRepository)
-	blacklistService := service.NewBlacklistService(blacklistRepository)
+	sessionService := service.NewSessionService(sessionRepository, refreshTokenService)
+	blacklistService := service.NewBlacklistService(blacklistRepository, tokenWatermarkRepository)
 	customerService := service.NewCustomerService(customerRepository, walletService)
-	authService := service.NewAuthService(customerService, refreshTokenService, blacklistService)
-	transactionService := service.NewTransactionService(transactionRepository, walletService)
+	notifier := service.NewLogNotifier()
+	if config.NotifierType == "file" {
+		notifier = service.NewFileNotifier(config.NotifierFilePath)
+	}
+	loginAttemptService := service.NewLoginAttemptService(loginAttemptRepository)
+	twoFactorService := service.NewTwoFactorService(twoFactorRepository, customerService)
+	transactionPinService := service.NewTransactionPinService(transactionPinRepository, customerService, twoFactorService)
+	authService := service.NewAuthService(customerService, sessionService, blacklistService, passwordResetTokenRepository, notifier, loginAttemptService, twoFactorService, loginChallengeRepository)
+	transactionService := service.NewTransactionService(transactionRepository, walletService, transactionPinService, eventBus)
+	ledgerAuditService := service.NewLedgerAuditService(transactionRepository, ledgerCheckpointRepository)
+	holdService := service.NewHoldService(holdRepository, walletService, transactionService)
+	paymentRequestService := service.NewPaymentRequestService(paymentRequestRepository, walletService, transactionService)
+	splitBillService := service.NewSplitBillService(splitBillRepository, walletService, paymentRequestService)
+	payoutService := service.NewPayoutService(payoutBatchRepository, walletService, transactionService)
+	merchantService := service.NewMerchantService(merchantRepository, walletService, webhookService)
+	merchantCallbackService := service.NewMerchantCallbackService(webhookService)
+	checkoutService := service.NewCheckoutService(checkoutSessionRepository, merchantService, transactionService, merchantCallbackService)
+	qrPaymentService := service.NewQrPaymentService(walletService, customerService, merchantService, checkoutService, transactionService)
+	virtualAccountService := service.NewVirtualAccountService(virtualAccountRepository, walletService, transactionService)
+	billPaymentService := service.NewBillPaymentService(billerProductRepository, billPaymentRepository, transactionService, service.NewLocalBiller(), eventBus)
+	statementService := service.NewStatementService(transactionRepository, walletService)
+	reconciliationService := service.NewReconciliationService(transactionService, reconciliationReportRepository)
+
+	// Run a one-off command instead of the server when one is given
+	if len(os.Args) > 1 {
+		runCommand(os.Args[1:], commandContext{
+			keyring:               keyring,
+			customerService:       customerService,
+			ledgerAuditService:    ledgerAuditService,
+			reconciliationService: reconciliationService,
+		})
+		return
+	}
+
+	// Re-encrypt files still using a previous key while the server is already serving requests
+	if keyring != nil {
+		go storage.ReEncryptFiles(keyring, storagePaths)
+	}
+
+	// Hand events from the outbox to every subscriber, starting with the ones left pending by a previous run
+	eventBus.Subscribe("webhooks", webhookService.HandleEvent)
+	eventBus.Subscribe("analytics", analyticsService.HandleEvent)
+	// Prepared events left behind by a crash are committed when their state change was stored, and dropped when not
+	eventBus.SetCommitCheck(enums.EVENT_TRANSACTION_CREATED, transactionService.CheckEventCommitted)
+	eventBus.SetCommitCheck(enums.EVENT_TRANSACTION_SETTLED, transactionService.CheckEventCommitted)
+	eventBus.SetCommitCheck(enums.EVENT_WALLET_FROZEN, walletService.CheckEventCommitted)
+	eventBus.SetCommitCheck(enums.EVENT_WALLET_UNFROZEN, walletService.CheckEventCommitted)
+	eventBus.SetCommitCheck(enums.EVENT_WALLET_CLOSED, walletService.CheckEventCommitted)
+	go eventBus.RunDispatcher()
+
+	// Deliver webhooks as events are handled and retry failed deliveries with backoff
+	go webhookService.RunDelivery()
+
+	// Periodically sign the head of the transaction hash chain
+	go utils.RunEvery(config.LedgerCheckpointInterval, func() {
+		ledgerAuditService.CreateCheckpoint()
+	})
+
+	// Periodically reconcile wallet balances against the transaction history
+	go utils.RunEvery(config.ReconciliationInterval, func() {
+		reconciliationService.Reconcile()
+	})
+
+	// Expire holds that were neither captured nor voided in time
+	go utils.RunEvery(time.Minute, func() {
+		holdService.ExpireHolds()
+	})
+
+	// Expire payment requests that were neither accepted nor declined in time
+	go utils.RunEvery(time.Minute, func() {
+		paymentRequestService.ExpirePaymentRequests()
+	})
+
+	// Expire checkout sessions that were not paid in time
+	go utils.RunEvery(time.Minute, func() {
+		checkoutService.ExpireCheckoutSessions()
+	})
+
+	// Retry refunds of bill payments the biller failed to deliver
+	go utils.RunEvery(time.Minute, func() {
+		billPaymentService.RefundFailedBillPayments()
+	})
+
+	// Delete expired refresh tokens, rotated ones included
+	go utils.RunEvery(time.Hour, func() {
+		refreshTokenService.DeleteExpiredRefreshTokens()
+	})
+
+	// Delete blacklist entries of access tokens that have expired
+	go utils.RunEvery(time.Hour, func() {
+		blacklistService.DeleteExpired()
+	})
 
 	authHandler := handler.NewAuthHandler(authService, customerService)
-	transactionHandler := handler.NewTransactionHandler(transactionService, walletService)
+	transactionHandler := handler.NewTransactionHandler(transactionService, walletService, transactionPinService)
 	customerHandler := handler.NewCustomerHandler(customerService)
+	walletHandler := handler.NewWalletHandler(walletService, transactionService)
+	holdHandler := handler.NewHoldHandler(holdService, walletService)
+	paymentRequestHandler := handler.NewPaymentRequestHandler(paymentRequestService, walletService)
+	splitBillHandler := handler.NewSplitBillHandler(splitBillService, walletService)
+	payoutHandler := handler.NewPayoutHandler(payoutService, walletService)
+	merchantHandler := handler.NewMerchantHandler(merchantService, checkoutService)
+	checkoutHandler := handler.NewCheckoutHandler(checkoutService, walletService)
+	qrHandler := handler.NewQrHandler(qrPaymentService, walletService)
+	virtualAccountHandler := handler.NewVirtualAccountHandler(virtualAccountService, walletService)
+	billPaymentHandler := handler.NewBillPaymentHandler(billPaymentService, walletService)
+	webhookHandler := handler.NewWebhookHandler(webhookService)
+	statementHandler := handler.NewStatementHandler(statementService, walletService)
+	adminHandler := handler.NewAdminHandler(loginAttemptService)
+	twoFactorHandler := handler.NewTwoFactorHandler(twoFactorService)
+	sessionHandler := handler.NewSessionHandler(sessionService)
 
 	r := gin.Default()
+	// Only the listed proxies may set the client IP with X-Forwarded-For, anyone could pick the IP they are throttled by
+	if err := r.SetTrustedProxies(config.TrustedProxies); err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
 
 	public := r.Group("/api/public")
 	{
 		public.POST("/auth/register", authHandler.HandleRegister)
 		public.POST("/auth/login", authHandler.HandleLogin)
+		public.POST("/auth/login/2fa", authHandler.HandleLoginTwoFactor)
 		public.POST("/auth/logout", authHandler.HandleLogout)
 		public.POST("/auth/refresh-token", authHandler.HandleRefreshToken)
+		public.POST("/auth/password-reset", authHandler.HandleRequestPasswordReset)
+		public.POST("/auth/password-reset/confirm", authHandler.HandleResetPassword)
+		public.POST("/bank/notifications", virtualAccountHandler.HandleBankNotification)
 	}
 
-	r.Use(middleware.AuthMiddleware(blacklistService))
+	r.GET("/.well-known/jwks.json", authHandler.HandleJwks)
+
+	r.Use(middleware.AuthMiddleware(blacklistService, sessionService))
+
+	auth := r.Group("/api/auth")
+	{
+		auth.POST("/logout-all", authHandler.HandleLogoutAll)
+		auth.POST("/change-password", authHandler.HandleChangePassword)
+		auth.POST("/2fa/enroll", twoFactorHandler.HandleEnroll)
+		auth.POST("/2fa/enable", twoFactorHandler.HandleEnable)
+		auth.POST("/2fa/recovery-codes", twoFactorHandler.HandleRegenerateRecoveryCodes)
+		auth.POST("/2fa/disable", twoFactorHandler.HandleDisable)
+		auth.PUT("/transaction-pin", transactionHandler.HandleSetTransactionPin)
+		auth.GET("/sessions", sessionHandler.HandleGetSessions)
+		auth.DELETE("/sessions/:id", sessionHandler.HandleRevokeSession)
+		auth.POST("/sessions/revoke-others", sessionHandler.HandleRevokeOtherSessions)
+	}
 
 	transaction := r.Group("/api/transactions")
 	{
@@ -53,6 +214,91 @@ func main() {
 		customer.GET("/:id", customerHandler.HandleGetCustomerById)
 	}
 
+	wallet := r.Group("/api/wallets")
+	{
+		wallet.POST("/:id/freeze", walletHandler.HandleFreezeWallet)
+		wallet.POST("/:id/close", walletHandler.HandleCloseWallet)
+		wallet.GET("/:id/virtual-account", virtualAccountHandler.HandleGetWalletVirtualAccount)
+		wallet.GET("/:id/statement", statementHandler.HandleGetStatement)
+	}
+
+	virtualAccount := r.Group("/api/virtual-accounts")
+	{
+		virtualAccount.GET("/:number", virtualAccountHandler.HandleGetVirtualAccountByNumber)
+	}
+
+	hold := r.Group("/api/holds")
+	{
+		hold.POST("", holdHandler.HandleCreateHold)
+		hold.GET("/:id", holdHandler.HandleGetHoldById)
+		hold.POST("/:id/capture", holdHandler.HandleCaptureHold)
+		hold.POST("/:id/void", holdHandler.HandleVoidHold)
+	}
+
+	paymentRequest := r.Group("/api/payment-requests")
+	{
+		paymentRequest.POST("", paymentRequestHandler.HandleCreatePaymentRequest)
+		paymentRequest.GET("", paymentRequestHandler.HandleGetPaymentRequests)
+		paymentRequest.POST("/:id/accept", paymentRequestHandler.HandleAcceptPaymentRequest)
+		paymentRequest.POST("/:id/decline", paymentRequestHandler.HandleDeclinePaymentRequest)
+	}
+
+	splitBill := r.Group("/api/split-bills")
+	{
+		splitBill.POST("", splitBillHandler.HandleCreateSplitBill)
+		splitBill.GET("/:id", splitBillHandler.HandleGetSplitBillById)
+	}
+
+	payout := r.Group("/api/payouts")
+	{
+		payout.POST("", payoutHandler.HandleCreatePayoutBatch)
+		payout.GET("/:id", payoutHandler.HandleGetPayoutBatchById)
+		payout.GET("/:id/report", payoutHandler.HandleGetPayoutBatchReport)
+	}
+
+	merchant := r.Group("/api/merchants")
+	{
+		merchant.POST("", merchantHandler.HandleCreateMerchant)
+		merchant.GET("/:id", merchantHandler.HandleGetMerchantById)
+		merchant.POST("/:id/checkout-sessions", merchantHandler.HandleCreateCheckoutSession)
+	}
+
+	checkout := r.Group("/api/checkout-sessions")
+	{
+		checkout.GET("/:id", checkoutHandler.HandleGetCheckoutSessionById)
+		checkout.POST("/:id/pay", checkoutHandler.HandlePayCheckoutSession)
+	}
+
+	qr := r.Group("/api/qr")
+	{
+		qr.POST("/wallet", qrHandler.HandleGenerateWalletQr)
+		qr.POST("/checkout-sessions/:id", qrHandler.HandleGenerateCheckoutQr)
+		qr.POST("/pay", qrHandler.HandlePayQr)
+	}
+
+	billPayment := r.Group("/api/bill-payments")
+	{
+		billPayment.GET("/products", billPaymentHandler.HandleGetBillerProducts)
+		billPayment.POST("", billPaymentHandler.HandleCreateBillPayment)
+		billPayment.GET("/:id", billPaymentHandler.HandleGetBillPaymentById)
+	}
+
+	webhook := r.Group("/api/webhooks")
+	{
+		webhook.POST("", webhookHandler.HandleCreateWebhookEndpoint)
+		webhook.GET("", webhookHandler.HandleGetWebhookEndpoints)
+		webhook.DELETE("/:id", webhookHandler.HandleDeleteWebhookEndpoint)
+		webhook.GET("/:id/deliveries", webhookHandler.HandleGetWebhookDeliveries)
+		webhook.POST("/:id/deliveries/:deliveryId/redeliver", webhookHandler.HandleRedeliverWebhook)
+	}
+
+	admin := r.Group("/api/admin", middleware.RoleMiddleware(enums.ROLE_ADMIN))
+	{
+		admin.GET("/login-lockouts", adminHandler.HandleGetLoginLockouts)
+		admin.POST("/login-lockouts/unlock", adminHandler.HandleUnlockLogin)
+		admin.POST("/wallets/:id/unfreeze", walletHandler.HandleUnfreezeWallet)
+	}
+
 	err := r.Run(":" + config.ServerPort)
 	if err != nil {
 		return
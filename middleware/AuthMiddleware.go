@@ -10,7 +10,7 @@ import (
 	"net/http"
 )
 
-func AuthMiddleware(blacklistService service.BlacklistService) gin.HandlerFunc {
+func AuthMiddleware(blacklistService service.BlacklistService, sessionService service.SessionService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		logger := logrus.WithFields(logrus.Fields{
 			"clientIP": c.ClientIP(),
@@ -28,8 +28,8 @@ func AuthMiddleware(blacklistService service.BlacklistService) gin.HandlerFunc {
 			return
 		}
 
-		// Parse and verify the token
-		_, err = utils.ParseAndVerifyAccessToken(accessToken)
+		// Parse and verify the token once, every claim below is read from it
+		claims, err := utils.ParseAndVerifyAccessToken(accessToken)
 		if err != nil {
 			logger.Warn("Invalid access token", "token", accessToken, "error", err)
 			c.JSON(http.StatusUnauthorized, res.ErrorResponse{
@@ -40,20 +40,32 @@ func AuthMiddleware(blacklistService service.BlacklistService) gin.HandlerFunc {
 			return
 		}
 
-		// Check if the token is blacklisted
-		blacklisted, err := blacklistService.IsBlacklisted(accessToken)
+		// Extract customer ID from token claims
+		id, err := utils.GetCustomerIdFromClaims(claims)
 		if err != nil {
-			logger.Error("Error checking token blacklist", "token", accessToken, "error", err)
-			c.JSON(http.StatusInternalServerError, res.ErrorResponse{
-				StatusCode:   http.StatusInternalServerError,
+			logger.Warn("Failed to extract customer ID from token", "error", err)
+			c.JSON(http.StatusUnauthorized, res.ErrorResponse{
+				StatusCode:   http.StatusUnauthorized,
 				ErrorMessage: err.Error(),
 			})
 			c.Abort()
 			return
 		}
 
-		if blacklisted {
-			logger.Warn("Blacklisted token used", "token", accessToken)
+		// Extract the JWT ID and issue time the token is revoked by
+		tokenId, err := utils.GetTokenIdFromClaims(claims)
+		if err != nil {
+			logger.Warn("Failed to extract JWT ID from token", "error", err)
+			c.JSON(http.StatusUnauthorized, res.ErrorResponse{
+				StatusCode:   http.StatusUnauthorized,
+				ErrorMessage: err.Error(),
+			})
+			c.Abort()
+			return
+		}
+		issuedAt, err := utils.GetIssuedAtFromClaims(claims)
+		if err != nil {
+			logger.Warn("Failed to extract issue time from token", "tokenId", tokenId, "error", err)
 			c.JSON(http.StatusUnauthorized, res.ErrorResponse{
 				StatusCode:   http.StatusUnauthorized,
 				ErrorMessage: constants.JwtTokenInvalidError,
@@ -62,21 +74,61 @@ func AuthMiddleware(blacklistService service.BlacklistService) gin.HandlerFunc {
 			return
 		}
 
-		// Extract customer ID from token claims
-		id, err := utils.GetCustomerIdFromClaims(accessToken)
+		// Check if the token is blacklisted or issued before the customer logged out everywhere
+		revoked, err := blacklistService.IsRevoked(tokenId, id, issuedAt)
 		if err != nil {
-			logger.Warn("Failed to extract customer ID from token", "token", accessToken, "error", err)
+			logger.Error("Error checking token revocation", "tokenId", tokenId, "error", err)
+			c.JSON(http.StatusInternalServerError, res.ErrorResponse{
+				StatusCode:   http.StatusInternalServerError,
+				ErrorMessage: err.Error(),
+			})
+			c.Abort()
+			return
+		}
+
+		if revoked {
+			logger.Warn("Revoked token used", "tokenId", tokenId)
 			c.JSON(http.StatusUnauthorized, res.ErrorResponse{
 				StatusCode:   http.StatusUnauthorized,
-				ErrorMessage: err.Error(),
+				ErrorMessage: constants.JwtTokenInvalidError,
 			})
 			c.Abort()
 			return
 		}
 
-		// Successfully authenticated, set user ID in the context
+		// Extract the role and session from token claims
+		role := utils.GetRoleFromClaims(claims)
+		sessionId := utils.GetSessionIdFromClaims(claims)
+
+		// A revoked or ended session takes its access tokens with it. Tokens issued before sessions were introduced
+		// carry none and have long expired.
+		if sessionId != "" {
+			active, err := sessionService.IsSessionActive(id, sessionId)
+			if err != nil {
+				logger.Error("Error checking session of token", "sessionId", sessionId, "error", err)
+				c.JSON(http.StatusInternalServerError, res.ErrorResponse{
+					StatusCode:   http.StatusInternalServerError,
+					ErrorMessage: err.Error(),
+				})
+				c.Abort()
+				return
+			}
+			if !active {
+				logger.Warn("Token of a revoked session used", "tokenId", tokenId, "sessionId", sessionId)
+				c.JSON(http.StatusUnauthorized, res.ErrorResponse{
+					StatusCode:   http.StatusUnauthorized,
+					ErrorMessage: constants.JwtTokenInvalidError,
+				})
+				c.Abort()
+				return
+			}
+		}
+
+		// Successfully authenticated, set user ID, role and session in the context
 		logger.Info("Authentication successful", "customerId", id)
 		c.Set("authenticatedUser", id)
+		c.Set("authenticatedRole", role)
+		c.Set("authenticatedSession", sessionId)
 		c.Next()
 	}
 }
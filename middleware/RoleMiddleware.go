@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"PaymentAPI/constants"
+	res "PaymentAPI/dto/response"
+	"PaymentAPI/enums"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"net/http"
+)
+
+// RoleMiddleware only lets through users with the role, it must run after AuthMiddleware
+func RoleMiddleware(role enums.Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authenticatedRole, _ := c.Get("authenticatedRole")
+		if authenticatedRole != role {
+			logrus.WithFields(logrus.Fields{
+				"clientIP":     c.ClientIP(),
+				"customerId":   c.GetString("authenticatedUser"),
+				"requiredRole": role,
+			}).Warn("Access denied for role")
+			c.JSON(http.StatusForbidden, res.ErrorResponse{
+				StatusCode:   http.StatusForbidden,
+				ErrorMessage: constants.RoleForbiddenAccess,
+			})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
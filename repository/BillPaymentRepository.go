@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"PaymentAPI/constants"
+	"PaymentAPI/entity"
+	"PaymentAPI/storage"
+	"errors"
+	"github.com/sirupsen/logrus"
+)
+
+type BillPaymentRepository interface {
+	GetAll() ([]entity.BillPayment, error)
+	GetById(id string) (entity.BillPayment, error)
+	Create(billPayment entity.BillPayment) error
+	Update(billPayment entity.BillPayment) error
+}
+
+type billPaymentRepository struct {
+	JsonStorage storage.JsonFileHandler[entity.BillPayment]
+}
+
+// NewBillPaymentRepository creates a new instance of BillPaymentRepository
+func NewBillPaymentRepository(jsonStorage storage.JsonFileHandler[entity.BillPayment]) BillPaymentRepository {
+	return &billPaymentRepository{JsonStorage: jsonStorage}
+}
+
+// GetAll retrieves all bill payments from storage
+func (b *billPaymentRepository) GetAll() ([]entity.BillPayment, error) {
+	logger := logrus.WithFields(logrus.Fields{})
+
+	logger.Info("Retrieving all bill payments")
+
+	data, err := b.JsonStorage.ReadFile(constants.BillPaymentJsonPath)
+	if err != nil {
+		logger.Error("Failed to read bill payments file", err)
+		return nil, err
+	}
+
+	logger.Info("All bill payments retrieved successfully")
+	return data, nil
+}
+
+// GetById retrieves a bill payment by its ID
+func (b *billPaymentRepository) GetById(id string) (entity.BillPayment, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"billPaymentId": id,
+	})
+
+	logger.Info("Retrieving bill payment")
+
+	data, err := b.GetAll()
+	if err != nil {
+		return entity.BillPayment{}, err
+	}
+
+	for _, billPayment := range data {
+		if billPayment.Id == id {
+			logger.Info("Bill payment found")
+			return billPayment, nil
+		}
+	}
+
+	logger.Warn("Bill payment not found")
+	return entity.BillPayment{}, errors.New(constants.BillPaymentNotFoundError)
+}
+
+// Create adds a new bill payment to storage
+func (b *billPaymentRepository) Create(billPayment entity.BillPayment) error {
+	logger := logrus.WithFields(logrus.Fields{
+		"billPaymentId": billPayment.Id,
+		"walletId":      billPayment.WalletId,
+		"productCode":   billPayment.ProductCode,
+		"totalAmount":   billPayment.TotalAmount,
+	})
+
+	logger.Info("Creating new bill payment")
+
+	data, err := b.JsonStorage.ReadFile(constants.BillPaymentJsonPath)
+	if err != nil {
+		logger.Error("Failed to read bill payments file", err)
+		return err
+	}
+
+	data = append(data, billPayment)
+
+	_, err = b.JsonStorage.WriteFile(data, constants.BillPaymentJsonPath)
+	if err != nil {
+		logger.Error("Failed to write updated bill payments file", err)
+		return err
+	}
+
+	logger.Info("New bill payment created successfully")
+	return nil
+}
+
+// Update replaces a stored bill payment with the given bill payment
+func (b *billPaymentRepository) Update(billPayment entity.BillPayment) error {
+	logger := logrus.WithFields(logrus.Fields{
+		"billPaymentId": billPayment.Id,
+		"status":        billPayment.Status,
+	})
+
+	logger.Info("Updating bill payment")
+
+	data, err := b.JsonStorage.ReadFile(constants.BillPaymentJsonPath)
+	if err != nil {
+		logger.Error("Failed to read bill payments file", err)
+		return err
+	}
+
+	billPaymentFound := false
+	for i := range data {
+		if data[i].Id == billPayment.Id {
+			data[i] = billPayment
+			billPaymentFound = true
+			break
+		}
+	}
+
+	if !billPaymentFound {
+		logger.Warn("Bill payment not found")
+		return errors.New(constants.BillPaymentNotFoundError)
+	}
+
+	_, err = b.JsonStorage.WriteFile(data, constants.BillPaymentJsonPath)
+	if err != nil {
+		logger.Error("Failed to write updated bill payments file", err)
+		return err
+	}
+
+	logger.Info("Bill payment updated successfully")
+	return nil
+}
@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"PaymentAPI/entity"
+	"fmt"
+	"github.com/stretchr/testify/mock"
+)
+
+type BillPaymentRepositoryMock struct {
+	Mock mock.Mock
+}
+
+func (b *BillPaymentRepositoryMock) GetAll() ([]entity.BillPayment, error) {
+	args := b.Mock.Called()
+	billPayments, ok := args.Get(0).([]entity.BillPayment)
+	if !ok {
+		return nil, fmt.Errorf("invalid type for bill payment")
+	}
+	return billPayments, args.Error(1)
+}
+
+func (b *BillPaymentRepositoryMock) GetById(id string) (entity.BillPayment, error) {
+	args := b.Mock.Called(id)
+	return args.Get(0).(entity.BillPayment), args.Error(1)
+}
+
+func (b *BillPaymentRepositoryMock) Create(billPayment entity.BillPayment) error {
+	args := b.Mock.Called(billPayment)
+	return args.Error(0)
+}
+
+func (b *BillPaymentRepositoryMock) Update(billPayment entity.BillPayment) error {
+	args := b.Mock.Called(billPayment)
+	return args.Error(0)
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"PaymentAPI/constants"
+	"PaymentAPI/entity"
+	"PaymentAPI/storage"
+	"errors"
+	"github.com/sirupsen/logrus"
+)
+
+type BillerProductRepository interface {
+	GetAll() ([]entity.BillerProduct, error)
+	GetByCode(code string) (entity.BillerProduct, error)
+}
+
+type billerProductRepository struct {
+	JsonStorage storage.JsonFileHandler[entity.BillerProduct]
+}
+
+// NewBillerProductRepository creates a new instance of BillerProductRepository
+func NewBillerProductRepository(jsonStorage storage.JsonFileHandler[entity.BillerProduct]) BillerProductRepository {
+	return &billerProductRepository{JsonStorage: jsonStorage}
+}
+
+// GetAll retrieves the whole biller product catalog from storage
+func (b *billerProductRepository) GetAll() ([]entity.BillerProduct, error) {
+	logger := logrus.WithFields(logrus.Fields{})
+
+	logger.Info("Retrieving all biller products")
+
+	data, err := b.JsonStorage.ReadFile(constants.BillerProductJsonPath)
+	if err != nil {
+		logger.Error("Failed to read biller products file", err)
+		return nil, err
+	}
+
+	logger.Info("All biller products retrieved successfully")
+	return data, nil
+}
+
+// GetByCode retrieves a biller product by its product code
+func (b *billerProductRepository) GetByCode(code string) (entity.BillerProduct, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"productCode": code,
+	})
+
+	logger.Info("Retrieving biller product")
+
+	data, err := b.GetAll()
+	if err != nil {
+		return entity.BillerProduct{}, err
+	}
+
+	for _, product := range data {
+		if product.Code == code {
+			logger.Info("Biller product found")
+			return product, nil
+		}
+	}
+
+	logger.Warn("Biller product not found")
+	return entity.BillerProduct{}, errors.New(constants.BillerProductNotFoundError)
+}
@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"PaymentAPI/entity"
+	"fmt"
+	"github.com/stretchr/testify/mock"
+)
+
+type BillerProductRepositoryMock struct {
+	Mock mock.Mock
+}
+
+func (b *BillerProductRepositoryMock) GetAll() ([]entity.BillerProduct, error) {
+	args := b.Mock.Called()
+	products, ok := args.Get(0).([]entity.BillerProduct)
+	if !ok {
+		return nil, fmt.Errorf("invalid type for biller product")
+	}
+	return products, args.Error(1)
+}
+
+func (b *BillerProductRepositoryMock) GetByCode(code string) (entity.BillerProduct, error) {
+	args := b.Mock.Called(code)
+	return args.Get(0).(entity.BillerProduct), args.Error(1)
+}
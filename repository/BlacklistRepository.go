@@ -5,14 +5,15 @@ import (
 	"PaymentAPI/entity"
 	"PaymentAPI/logger" // Import the logger package
 	"PaymentAPI/storage"
-	"PaymentAPI/utils"
+	"time"
 
 	"github.com/sirupsen/logrus"
 )
 
 type BlacklistRepository interface {
 	GetAll() ([]entity.Blacklist, error)
-	CreateBlacklist(accessToken string) error
+	CreateBlacklist(blacklist entity.Blacklist) error
+	DeleteExpired(now time.Time) (int, error)
 }
 
 type blacklistRepository struct {
@@ -23,11 +24,11 @@ func NewBlacklistRepository(jsonStorage storage.JsonFileHandler[entity.Blacklist
 	return &blacklistRepository{JsonStorage: jsonStorage}
 }
 
-func (r *blacklistRepository) CreateBlacklist(accessToken string) error {
+func (r *blacklistRepository) CreateBlacklist(blacklist entity.Blacklist) error {
 	// Log the start of the operation
 	logger.LogInfo("Creating a new blacklist entry", logrus.Fields{
 		"operation": "CreateBlacklist",
-		"token":     accessToken,
+		"tokenId":   blacklist.TokenId,
 	})
 
 	data, err := r.JsonStorage.ReadFile(constants.BlacklistJsonPath)
@@ -39,20 +40,6 @@ func (r *blacklistRepository) CreateBlacklist(accessToken string) error {
 		return err
 	}
 
-	expStr, err := utils.GetExpirationFromClaimsAsString(accessToken)
-	if err != nil {
-		logger.LogError("Failed to get expiration from token claims", logrus.Fields{
-			"operation": "CreateBlacklist",
-			"token":     accessToken,
-			"error":     err.Error(),
-		})
-		return err
-	}
-
-	blacklist := entity.Blacklist{
-		AccessToken: accessToken,
-		ExpiresAt:   expStr,
-	}
 	data = append(data, blacklist)
 
 	_, err = r.JsonStorage.WriteFile(data, constants.BlacklistJsonPath)
@@ -67,7 +54,7 @@ func (r *blacklistRepository) CreateBlacklist(accessToken string) error {
 	// Log success
 	logger.LogInfo("Successfully created a blacklist entry", logrus.Fields{
 		"operation": "CreateBlacklist",
-		"token":     accessToken,
+		"tokenId":   blacklist.TokenId,
 	})
 	return nil
 }
@@ -95,3 +82,44 @@ func (r *blacklistRepository) GetAll() ([]entity.Blacklist, error) {
 
 	return data, nil
 }
+
+// DeleteExpired deletes the entries of tokens that have expired by now, as they are refused anyway, and returns how
+// many were deleted
+func (r *blacklistRepository) DeleteExpired(now time.Time) (int, error) {
+	data, err := r.JsonStorage.ReadFile(constants.BlacklistJsonPath)
+	if err != nil {
+		logger.LogError("Failed to read blacklist file", logrus.Fields{
+			"operation": "DeleteExpired",
+			"error":     err.Error(),
+		})
+		return 0, err
+	}
+
+	kept := make([]entity.Blacklist, 0, len(data))
+	for _, blacklist := range data {
+		expiresAt, err := time.Parse(time.RFC3339, blacklist.ExpiresAt)
+		if err == nil && now.Before(expiresAt) {
+			kept = append(kept, blacklist)
+		}
+	}
+
+	deleted := len(data) - len(kept)
+	if deleted == 0 {
+		return 0, nil
+	}
+
+	_, err = r.JsonStorage.WriteFile(kept, constants.BlacklistJsonPath)
+	if err != nil {
+		logger.LogError("Failed to write to blacklist file", logrus.Fields{
+			"operation": "DeleteExpired",
+			"error":     err.Error(),
+		})
+		return 0, err
+	}
+
+	logger.LogInfo("Successfully deleted expired blacklist entries", logrus.Fields{
+		"operation": "DeleteExpired",
+		"count":     deleted,
+	})
+	return deleted, nil
+}
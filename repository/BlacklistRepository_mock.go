@@ -3,14 +3,15 @@ package repository
 import (
 	"PaymentAPI/entity"
 	"github.com/stretchr/testify/mock"
+	"time"
 )
 
 type BlacklistRepositoryMock struct {
 	Mock mock.Mock
 }
 
-func (b *BlacklistRepositoryMock) CreateBlacklist(accessToken string) error {
-	args := b.Mock.Called(accessToken)
+func (b *BlacklistRepositoryMock) CreateBlacklist(blacklist entity.Blacklist) error {
+	args := b.Mock.Called(blacklist)
 	return args.Error(0)
 }
 
@@ -18,3 +19,8 @@ func (b *BlacklistRepositoryMock) GetAll() ([]entity.Blacklist, error) {
 	args := b.Mock.Called()
 	return args.Get(0).([]entity.Blacklist), args.Error(1)
 }
+
+func (b *BlacklistRepositoryMock) DeleteExpired(now time.Time) (int, error) {
+	args := b.Mock.Called(now)
+	return args.Int(0), args.Error(1)
+}
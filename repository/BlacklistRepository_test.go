@@ -4,7 +4,6 @@ import (
 	"PaymentAPI/constants"
 	"PaymentAPI/entity"
 	"PaymentAPI/storage"
-	"PaymentAPI/utils"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"testing"
@@ -17,8 +16,9 @@ func TestGetAll(t *testing.T) {
 
 	blacklistTokenList := []entity.Blacklist{
 		{
-			AccessToken: "access-token-1",
-			ExpiresAt:   time.Now().Add(time.Duration(5) * time.Minute).String(),
+			TokenId:    "token-id-1",
+			CustomerId: "user-1",
+			ExpiresAt:  time.Now().Add(time.Duration(5) * time.Minute).Format(time.RFC3339),
 		},
 	}
 
@@ -31,48 +31,59 @@ func TestGetAll(t *testing.T) {
 }
 
 func TestCreateBlacklist(t *testing.T) {
-	t.Run("ShouldSuccessCreateBlacklist", func(t *testing.T) {
+	mockJsonFileHandler := new(storage.BlacklistJsonFileHandlerMock[entity.Blacklist])
+	blacklistRepository := NewBlacklistRepository(mockJsonFileHandler)
+
+	blacklist := entity.Blacklist{
+		TokenId:    "token-id-1",
+		CustomerId: "user-1",
+		ExpiresAt:  time.Now().Add(time.Duration(5) * time.Minute).Format(time.RFC3339),
+	}
+
+	mockJsonFileHandler.Mock.On("ReadFile", constants.BlacklistJsonPath).
+		Return([]entity.Blacklist{}, nil)
+
+	mockJsonFileHandler.Mock.On("WriteFile", []entity.Blacklist{blacklist}, constants.BlacklistJsonPath).
+		Return(mock.Anything, nil)
+
+	err := blacklistRepository.CreateBlacklist(blacklist)
+	assert.Nil(t, err)
+	mockJsonFileHandler.Mock.AssertExpectations(t)
+}
+
+func TestDeleteExpiredBlacklist(t *testing.T) {
+	t.Run("ShouldKeepUnexpiredEntries", func(t *testing.T) {
 		mockJsonFileHandler := new(storage.BlacklistJsonFileHandlerMock[entity.Blacklist])
 		blacklistRepository := NewBlacklistRepository(mockJsonFileHandler)
 
-		customer := entity.Customer{
-			Id:       "user-1",
-			Username: "John Doe",
-			Password: "password",
-		}
-		token, _ := utils.GenerateAccessToken(customer)
-		tokenExpiryDate, _ := utils.GetExpirationFromClaimsAsString(token)
-
-		blacklist := entity.Blacklist{
-			AccessToken: token,
-			ExpiresAt:   tokenExpiryDate,
-		}
-
-		mockJsonFileHandler.Mock.On("ReadFile", constants.BlacklistJsonPath).
-			Return([]entity.Blacklist{}, nil)
-
-		mockJsonFileHandler.Mock.On("WriteFile", mock.MatchedBy(func(blacklists []entity.Blacklist) bool {
-			if len(blacklists) != 1 {
-				return false
-			}
-			blacklistedToken := blacklists[0]
-			return blacklistedToken.AccessToken == blacklist.AccessToken
-		}), constants.BlacklistJsonPath).
+		now := time.Now()
+		active := entity.Blacklist{TokenId: "token-id-2", ExpiresAt: now.Add(time.Minute).Format(time.RFC3339)}
+		mockJsonFileHandler.Mock.On("ReadFile", constants.BlacklistJsonPath).Return([]entity.Blacklist{
+			{TokenId: "token-id-1", ExpiresAt: now.Add(-time.Minute).Format(time.RFC3339)},
+			active,
+			{TokenId: "token-id-3", ExpiresAt: "1732517339"},
+		}, nil)
+		mockJsonFileHandler.Mock.On("WriteFile", []entity.Blacklist{active}, constants.BlacklistJsonPath).
 			Return(mock.Anything, nil)
 
-		err := blacklistRepository.CreateBlacklist(token)
+		deleted, err := blacklistRepository.DeleteExpired(now)
 		assert.Nil(t, err)
+		assert.Equal(t, 2, deleted)
 		mockJsonFileHandler.Mock.AssertExpectations(t)
 	})
 
-	t.Run("ShouldReturnErrorOnInvalidToken", func(t *testing.T) {
+	t.Run("ShouldNotWriteWhenNothingExpired", func(t *testing.T) {
 		mockJsonFileHandler := new(storage.BlacklistJsonFileHandlerMock[entity.Blacklist])
 		blacklistRepository := NewBlacklistRepository(mockJsonFileHandler)
 
-		mockJsonFileHandler.Mock.On("ReadFile", constants.BlacklistJsonPath).
-			Return([]entity.Blacklist{}, nil)
+		now := time.Now()
+		mockJsonFileHandler.Mock.On("ReadFile", constants.BlacklistJsonPath).Return([]entity.Blacklist{
+			{TokenId: "token-id-1", ExpiresAt: now.Add(time.Minute).Format(time.RFC3339)},
+		}, nil)
 
-		err := blacklistRepository.CreateBlacklist("")
-		assert.Equal(t, constants.JwtTokenInvalidError, err.Error())
+		deleted, err := blacklistRepository.DeleteExpired(now)
+		assert.Nil(t, err)
+		assert.Equal(t, 0, deleted)
+		mockJsonFileHandler.Mock.AssertNotCalled(t, "WriteFile", mock.Anything, mock.Anything)
 	})
 }
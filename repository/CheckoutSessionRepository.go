@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"PaymentAPI/constants"
+	"PaymentAPI/entity"
+	"PaymentAPI/storage"
+	"errors"
+	"github.com/sirupsen/logrus"
+)
+
+type CheckoutSessionRepository interface {
+	GetAll() ([]entity.CheckoutSession, error)
+	GetById(id string) (entity.CheckoutSession, error)
+	Create(checkoutSession entity.CheckoutSession) error
+	Update(checkoutSession entity.CheckoutSession) error
+}
+
+type checkoutSessionRepository struct {
+	JsonStorage storage.JsonFileHandler[entity.CheckoutSession]
+}
+
+// NewCheckoutSessionRepository creates a new instance of CheckoutSessionRepository
+func NewCheckoutSessionRepository(jsonStorage storage.JsonFileHandler[entity.CheckoutSession]) CheckoutSessionRepository {
+	return &checkoutSessionRepository{JsonStorage: jsonStorage}
+}
+
+// GetAll retrieves all checkout sessions from storage
+func (c *checkoutSessionRepository) GetAll() ([]entity.CheckoutSession, error) {
+	logger := logrus.WithFields(logrus.Fields{})
+
+	logger.Info("Retrieving all checkout sessions")
+
+	data, err := c.JsonStorage.ReadFile(constants.CheckoutSessionJsonPath)
+	if err != nil {
+		logger.Error("Failed to read checkout sessions file", err)
+		return nil, err
+	}
+
+	logger.Info("All checkout sessions retrieved successfully")
+	return data, nil
+}
+
+// GetById retrieves a checkout session by its ID
+func (c *checkoutSessionRepository) GetById(id string) (entity.CheckoutSession, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"checkoutSessionId": id,
+	})
+
+	logger.Info("Retrieving checkout session")
+
+	data, err := c.GetAll()
+	if err != nil {
+		return entity.CheckoutSession{}, err
+	}
+
+	for _, checkoutSession := range data {
+		if checkoutSession.Id == id {
+			logger.Info("Checkout session found")
+			return checkoutSession, nil
+		}
+	}
+
+	logger.Warn("Checkout session not found")
+	return entity.CheckoutSession{}, errors.New(constants.CheckoutSessionNotFoundError)
+}
+
+// Create adds a new checkout session to storage
+func (c *checkoutSessionRepository) Create(checkoutSession entity.CheckoutSession) error {
+	logger := logrus.WithFields(logrus.Fields{
+		"checkoutSessionId": checkoutSession.Id,
+		"merchantId":        checkoutSession.MerchantId,
+		"reference":         checkoutSession.Reference,
+		"amount":            checkoutSession.Amount,
+	})
+
+	logger.Info("Creating new checkout session")
+
+	data, err := c.JsonStorage.ReadFile(constants.CheckoutSessionJsonPath)
+	if err != nil {
+		logger.Error("Failed to read checkout sessions file", err)
+		return err
+	}
+
+	data = append(data, checkoutSession)
+
+	_, err = c.JsonStorage.WriteFile(data, constants.CheckoutSessionJsonPath)
+	if err != nil {
+		logger.Error("Failed to write updated checkout sessions file", err)
+		return err
+	}
+
+	logger.Info("New checkout session created successfully")
+	return nil
+}
+
+// Update replaces a stored checkout session with the given checkout session
+func (c *checkoutSessionRepository) Update(checkoutSession entity.CheckoutSession) error {
+	logger := logrus.WithFields(logrus.Fields{
+		"checkoutSessionId": checkoutSession.Id,
+		"status":            checkoutSession.Status,
+	})
+
+	logger.Info("Updating checkout session")
+
+	data, err := c.JsonStorage.ReadFile(constants.CheckoutSessionJsonPath)
+	if err != nil {
+		logger.Error("Failed to read checkout sessions file", err)
+		return err
+	}
+
+	checkoutSessionFound := false
+	for i := range data {
+		if data[i].Id == checkoutSession.Id {
+			data[i] = checkoutSession
+			checkoutSessionFound = true
+			break
+		}
+	}
+
+	if !checkoutSessionFound {
+		logger.Warn("Checkout session not found")
+		return errors.New(constants.CheckoutSessionNotFoundError)
+	}
+
+	_, err = c.JsonStorage.WriteFile(data, constants.CheckoutSessionJsonPath)
+	if err != nil {
+		logger.Error("Failed to write updated checkout sessions file", err)
+		return err
+	}
+
+	logger.Info("Checkout session updated successfully")
+	return nil
+}
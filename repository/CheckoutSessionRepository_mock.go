@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"PaymentAPI/entity"
+	"fmt"
+	"github.com/stretchr/testify/mock"
+)
+
+type CheckoutSessionRepositoryMock struct {
+	Mock mock.Mock
+}
+
+func (c *CheckoutSessionRepositoryMock) GetAll() ([]entity.CheckoutSession, error) {
+	args := c.Mock.Called()
+	checkoutSessions, ok := args.Get(0).([]entity.CheckoutSession)
+	if !ok {
+		return nil, fmt.Errorf("invalid type for checkout session")
+	}
+	return checkoutSessions, args.Error(1)
+}
+
+func (c *CheckoutSessionRepositoryMock) GetById(id string) (entity.CheckoutSession, error) {
+	args := c.Mock.Called(id)
+	return args.Get(0).(entity.CheckoutSession), args.Error(1)
+}
+
+func (c *CheckoutSessionRepositoryMock) Create(checkoutSession entity.CheckoutSession) error {
+	args := c.Mock.Called(checkoutSession)
+	return args.Error(0)
+}
+
+func (c *CheckoutSessionRepositoryMock) Update(checkoutSession entity.CheckoutSession) error {
+	args := c.Mock.Called(checkoutSession)
+	return args.Error(0)
+}
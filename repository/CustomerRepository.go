@@ -3,6 +3,7 @@ package repository
 import (
 	"PaymentAPI/constants"
 	"PaymentAPI/entity"
+	"PaymentAPI/enums"
 	"PaymentAPI/logger" // Import the logger package
 	"PaymentAPI/storage"
 	"errors"
@@ -14,6 +15,8 @@ type CustomerRepository interface {
 	GetByUsername(id string) (entity.Customer, error)
 	GetById(id string) (entity.Customer, error)
 	Create(customer entity.Customer) (entity.Customer, error)
+	UpdatePassword(id string, password string) error
+	UpdateRole(id string, role enums.Role) error
 }
 
 type customerRepository struct {
@@ -141,3 +144,101 @@ func (cr *customerRepository) GetById(id string) (entity.Customer, error) {
 	})
 	return entity.Customer{}, errors.New(constants.CustomerNotFound)
 }
+
+func (cr *customerRepository) UpdatePassword(id string, password string) error {
+	logger.LogInfo("Starting to update customer password", logrus.Fields{
+		"operation": "UpdatePassword",
+		"id":        id,
+	})
+
+	data, err := cr.JsonStorage.ReadFile(constants.CustomerJsonPath)
+	if err != nil {
+		logger.LogError("Failed to read customer file", logrus.Fields{
+			"operation": "UpdatePassword",
+			"error":     err.Error(),
+		})
+		return err
+	}
+
+	customerFound := false
+	for i := range data {
+		if data[i].Id == id {
+			data[i].Password = password
+			customerFound = true
+			break
+		}
+	}
+
+	if !customerFound {
+		logger.LogError("Customer not found", logrus.Fields{
+			"operation": "UpdatePassword",
+			"id":        id,
+		})
+		return errors.New(constants.CustomerNotFound)
+	}
+
+	_, err = cr.JsonStorage.WriteFile(data, constants.CustomerJsonPath)
+	if err != nil {
+		logger.LogError("Failed to write customer file", logrus.Fields{
+			"operation": "UpdatePassword",
+			"error":     err.Error(),
+		})
+		return err
+	}
+
+	logger.LogInfo("Successfully updated customer password", logrus.Fields{
+		"operation": "UpdatePassword",
+		"id":        id,
+	})
+	return nil
+}
+
+func (cr *customerRepository) UpdateRole(id string, role enums.Role) error {
+	logger.LogInfo("Starting to update customer role", logrus.Fields{
+		"operation": "UpdateRole",
+		"id":        id,
+		"role":      role,
+	})
+
+	data, err := cr.JsonStorage.ReadFile(constants.CustomerJsonPath)
+	if err != nil {
+		logger.LogError("Failed to read customer file", logrus.Fields{
+			"operation": "UpdateRole",
+			"error":     err.Error(),
+		})
+		return err
+	}
+
+	customerFound := false
+	for i := range data {
+		if data[i].Id == id {
+			data[i].Role = role
+			customerFound = true
+			break
+		}
+	}
+
+	if !customerFound {
+		logger.LogError("Customer not found", logrus.Fields{
+			"operation": "UpdateRole",
+			"id":        id,
+		})
+		return errors.New(constants.CustomerNotFound)
+	}
+
+	_, err = cr.JsonStorage.WriteFile(data, constants.CustomerJsonPath)
+	if err != nil {
+		logger.LogError("Failed to write customer file", logrus.Fields{
+			"operation": "UpdateRole",
+			"error":     err.Error(),
+		})
+		return err
+	}
+
+	logger.LogInfo("Successfully updated customer role", logrus.Fields{
+		"operation": "UpdateRole",
+		"id":        id,
+		"role":      role,
+	})
+	return nil
+}
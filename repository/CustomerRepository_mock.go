@@ -2,6 +2,7 @@ package repository
 
 import (
 	"PaymentAPI/entity"
+	"PaymentAPI/enums"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -23,3 +24,13 @@ func (c *CustomerRepositoryMock) GetById(id string) (entity.Customer, error) {
 	args := c.Mock.Called(id)
 	return args.Get(0).(entity.Customer), args.Error(1)
 }
+
+func (c *CustomerRepositoryMock) UpdatePassword(id string, password string) error {
+	args := c.Mock.Called(id, password)
+	return args.Error(0)
+}
+
+func (c *CustomerRepositoryMock) UpdateRole(id string, role enums.Role) error {
+	args := c.Mock.Called(id, role)
+	return args.Error(0)
+}
@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"PaymentAPI/constants"
+	"PaymentAPI/entity"
+	"PaymentAPI/storage"
+	"errors"
+	"github.com/sirupsen/logrus"
+)
+
+type HoldRepository interface {
+	GetAll() ([]entity.Hold, error)
+	GetById(id string) (entity.Hold, error)
+	GetByWalletId(walletId string) ([]entity.Hold, error)
+	Create(hold entity.Hold) error
+	Update(hold entity.Hold) error
+}
+
+type holdRepository struct {
+	JsonStorage storage.JsonFileHandler[entity.Hold]
+}
+
+// NewHoldRepository creates a new instance of HoldRepository
+func NewHoldRepository(jsonStorage storage.JsonFileHandler[entity.Hold]) HoldRepository {
+	return &holdRepository{JsonStorage: jsonStorage}
+}
+
+// GetAll retrieves all holds from storage
+func (h *holdRepository) GetAll() ([]entity.Hold, error) {
+	logger := logrus.WithFields(logrus.Fields{})
+
+	logger.Info("Retrieving all holds")
+
+	data, err := h.JsonStorage.ReadFile(constants.HoldJsonPath)
+	if err != nil {
+		logger.Error("Failed to read holds file", err)
+		return nil, err
+	}
+
+	logger.Info("All holds retrieved successfully")
+	return data, nil
+}
+
+// GetById retrieves a hold by its ID
+func (h *holdRepository) GetById(id string) (entity.Hold, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"holdId": id,
+	})
+
+	logger.Info("Retrieving hold")
+
+	data, err := h.GetAll()
+	if err != nil {
+		return entity.Hold{}, err
+	}
+
+	for _, hold := range data {
+		if hold.Id == id {
+			logger.Info("Hold found")
+			return hold, nil
+		}
+	}
+
+	logger.Warn("Hold not found")
+	return entity.Hold{}, errors.New(constants.HoldNotFoundError)
+}
+
+// GetByWalletId retrieves every hold placed on a wallet
+func (h *holdRepository) GetByWalletId(walletId string) ([]entity.Hold, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"walletId": walletId,
+	})
+
+	logger.Info("Retrieving holds for wallet")
+
+	data, err := h.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	holds := []entity.Hold{}
+	for _, hold := range data {
+		if hold.WalletId == walletId {
+			holds = append(holds, hold)
+		}
+	}
+
+	logger.Info("Holds for wallet retrieved successfully")
+	return holds, nil
+}
+
+// Create adds a new hold to storage
+func (h *holdRepository) Create(hold entity.Hold) error {
+	logger := logrus.WithFields(logrus.Fields{
+		"holdId":   hold.Id,
+		"walletId": hold.WalletId,
+		"amount":   hold.Amount,
+	})
+
+	logger.Info("Creating new hold")
+
+	data, err := h.JsonStorage.ReadFile(constants.HoldJsonPath)
+	if err != nil {
+		logger.Error("Failed to read holds file", err)
+		return err
+	}
+
+	data = append(data, hold)
+
+	_, err = h.JsonStorage.WriteFile(data, constants.HoldJsonPath)
+	if err != nil {
+		logger.Error("Failed to write updated holds file", err)
+		return err
+	}
+
+	logger.Info("New hold created successfully")
+	return nil
+}
+
+// Update replaces a stored hold with the given hold
+func (h *holdRepository) Update(hold entity.Hold) error {
+	logger := logrus.WithFields(logrus.Fields{
+		"holdId": hold.Id,
+		"status": hold.Status,
+	})
+
+	logger.Info("Updating hold")
+
+	data, err := h.JsonStorage.ReadFile(constants.HoldJsonPath)
+	if err != nil {
+		logger.Error("Failed to read holds file", err)
+		return err
+	}
+
+	holdFound := false
+	for i := range data {
+		if data[i].Id == hold.Id {
+			data[i] = hold
+			holdFound = true
+			break
+		}
+	}
+
+	if !holdFound {
+		logger.Warn("Hold not found")
+		return errors.New(constants.HoldNotFoundError)
+	}
+
+	_, err = h.JsonStorage.WriteFile(data, constants.HoldJsonPath)
+	if err != nil {
+		logger.Error("Failed to write updated holds file", err)
+		return err
+	}
+
+	logger.Info("Hold updated successfully")
+	return nil
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"PaymentAPI/entity"
+	"fmt"
+	"github.com/stretchr/testify/mock"
+)
+
+type HoldRepositoryMock struct {
+	Mock mock.Mock
+}
+
+func (h *HoldRepositoryMock) GetAll() ([]entity.Hold, error) {
+	args := h.Mock.Called()
+	holds, ok := args.Get(0).([]entity.Hold)
+	if !ok {
+		return nil, fmt.Errorf("invalid type for hold")
+	}
+	return holds, args.Error(1)
+}
+
+func (h *HoldRepositoryMock) GetById(id string) (entity.Hold, error) {
+	args := h.Mock.Called(id)
+	return args.Get(0).(entity.Hold), args.Error(1)
+}
+
+func (h *HoldRepositoryMock) GetByWalletId(walletId string) ([]entity.Hold, error) {
+	args := h.Mock.Called(walletId)
+	holds, ok := args.Get(0).([]entity.Hold)
+	if !ok {
+		return nil, fmt.Errorf("invalid type for hold")
+	}
+	return holds, args.Error(1)
+}
+
+func (h *HoldRepositoryMock) Create(hold entity.Hold) error {
+	args := h.Mock.Called(hold)
+	return args.Error(0)
+}
+
+func (h *HoldRepositoryMock) Update(hold entity.Hold) error {
+	args := h.Mock.Called(hold)
+	return args.Error(0)
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"PaymentAPI/constants"
+	"PaymentAPI/entity"
+	"PaymentAPI/storage"
+	"github.com/sirupsen/logrus"
+)
+
+type LedgerCheckpointRepository interface {
+	GetAll() ([]entity.LedgerCheckpoint, error)
+	Create(checkpoint entity.LedgerCheckpoint) error
+}
+
+type ledgerCheckpointRepository struct {
+	JsonStorage storage.JsonFileHandler[entity.LedgerCheckpoint]
+}
+
+// NewLedgerCheckpointRepository creates a new instance of LedgerCheckpointRepository
+func NewLedgerCheckpointRepository(jsonStorage storage.JsonFileHandler[entity.LedgerCheckpoint]) LedgerCheckpointRepository {
+	return &ledgerCheckpointRepository{JsonStorage: jsonStorage}
+}
+
+// GetAll retrieves all ledger checkpoints from storage
+func (l *ledgerCheckpointRepository) GetAll() ([]entity.LedgerCheckpoint, error) {
+	logger := logrus.WithFields(logrus.Fields{})
+
+	logger.Info("Retrieving all ledger checkpoints")
+
+	data, err := l.JsonStorage.ReadFile(constants.LedgerCheckpointJsonPath)
+	if err != nil {
+		logger.Error("Failed to read ledger checkpoints file", err)
+		return nil, err
+	}
+
+	logger.Info("All ledger checkpoints retrieved successfully")
+	return data, nil
+}
+
+// Create adds a new ledger checkpoint to storage
+func (l *ledgerCheckpointRepository) Create(checkpoint entity.LedgerCheckpoint) error {
+	logger := logrus.WithFields(logrus.Fields{
+		"sequence": checkpoint.Sequence,
+		"headHash": checkpoint.HeadHash,
+	})
+
+	logger.Info("Creating new ledger checkpoint")
+
+	data, err := l.JsonStorage.ReadFile(constants.LedgerCheckpointJsonPath)
+	if err != nil {
+		logger.Error("Failed to read ledger checkpoints file", err)
+		return err
+	}
+
+	data = append(data, checkpoint)
+
+	_, err = l.JsonStorage.WriteFile(data, constants.LedgerCheckpointJsonPath)
+	if err != nil {
+		logger.Error("Failed to write updated ledger checkpoints file", err)
+		return err
+	}
+
+	logger.Info("New ledger checkpoint created successfully")
+	return nil
+}
@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"PaymentAPI/entity"
+	"fmt"
+	"github.com/stretchr/testify/mock"
+)
+
+type LedgerCheckpointRepositoryMock struct {
+	Mock mock.Mock
+}
+
+func (l *LedgerCheckpointRepositoryMock) GetAll() ([]entity.LedgerCheckpoint, error) {
+	args := l.Mock.Called()
+	checkpoints, ok := args.Get(0).([]entity.LedgerCheckpoint)
+	if !ok {
+		return nil, fmt.Errorf("invalid type for ledger checkpoint")
+	}
+	return checkpoints, args.Error(1)
+}
+
+func (l *LedgerCheckpointRepositoryMock) Create(checkpoint entity.LedgerCheckpoint) error {
+	args := l.Mock.Called(checkpoint)
+	return args.Error(0)
+}
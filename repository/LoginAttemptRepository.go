@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"PaymentAPI/constants"
+	"PaymentAPI/entity"
+	"PaymentAPI/storage"
+	"errors"
+	"github.com/sirupsen/logrus"
+)
+
+type LoginAttemptRepository interface {
+	GetAll() ([]entity.LoginAttempt, error)
+	GetById(id string) (entity.LoginAttempt, error)
+	Save(loginAttempt entity.LoginAttempt) error
+	Delete(id string) error
+}
+
+type loginAttemptRepository struct {
+	JsonStorage storage.JsonFileHandler[entity.LoginAttempt]
+}
+
+// NewLoginAttemptRepository creates a new instance of LoginAttemptRepository
+func NewLoginAttemptRepository(jsonStorage storage.JsonFileHandler[entity.LoginAttempt]) LoginAttemptRepository {
+	return &loginAttemptRepository{JsonStorage: jsonStorage}
+}
+
+// GetAll retrieves all login attempts from storage
+func (l *loginAttemptRepository) GetAll() ([]entity.LoginAttempt, error) {
+	logger := logrus.WithFields(logrus.Fields{})
+
+	logger.Info("Retrieving all login attempts")
+
+	data, err := l.JsonStorage.ReadFile(constants.LoginAttemptJsonPath)
+	if err != nil {
+		logger.Error("Failed to read login attempts file", err)
+		return nil, err
+	}
+
+	logger.Info("All login attempts retrieved successfully")
+	return data, nil
+}
+
+// GetById retrieves a login attempt by its ID
+func (l *loginAttemptRepository) GetById(id string) (entity.LoginAttempt, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"loginAttemptId": id,
+	})
+
+	logger.Info("Retrieving login attempt")
+
+	data, err := l.GetAll()
+	if err != nil {
+		return entity.LoginAttempt{}, err
+	}
+
+	for _, loginAttempt := range data {
+		if loginAttempt.Id == id {
+			logger.Info("Login attempt found")
+			return loginAttempt, nil
+		}
+	}
+
+	logger.Info("Login attempt not found")
+	return entity.LoginAttempt{}, errors.New(constants.LoginAttemptNotFoundError)
+}
+
+// Save replaces the stored login attempt with the same ID, or adds it when there is none
+func (l *loginAttemptRepository) Save(loginAttempt entity.LoginAttempt) error {
+	logger := logrus.WithFields(logrus.Fields{
+		"loginAttemptId": loginAttempt.Id,
+		"failures":       loginAttempt.Failures,
+	})
+
+	logger.Info("Saving login attempt")
+
+	data, err := l.JsonStorage.ReadFile(constants.LoginAttemptJsonPath)
+	if err != nil {
+		logger.Error("Failed to read login attempts file", err)
+		return err
+	}
+
+	loginAttemptFound := false
+	for i := range data {
+		if data[i].Id == loginAttempt.Id {
+			data[i] = loginAttempt
+			loginAttemptFound = true
+			break
+		}
+	}
+	if !loginAttemptFound {
+		data = append(data, loginAttempt)
+	}
+
+	_, err = l.JsonStorage.WriteFile(data, constants.LoginAttemptJsonPath)
+	if err != nil {
+		logger.Error("Failed to write updated login attempts file", err)
+		return err
+	}
+
+	logger.Info("Login attempt saved successfully")
+	return nil
+}
+
+// Delete removes a login attempt by its ID
+func (l *loginAttemptRepository) Delete(id string) error {
+	logger := logrus.WithFields(logrus.Fields{
+		"loginAttemptId": id,
+	})
+
+	logger.Info("Deleting login attempt")
+
+	data, err := l.JsonStorage.ReadFile(constants.LoginAttemptJsonPath)
+	if err != nil {
+		logger.Error("Failed to read login attempts file", err)
+		return err
+	}
+
+	indexToDelete := -1
+	for i, loginAttempt := range data {
+		if loginAttempt.Id == id {
+			indexToDelete = i
+			break
+		}
+	}
+
+	if indexToDelete == -1 {
+		logger.Warn("Login attempt not found")
+		return errors.New(constants.LoginAttemptNotFoundError)
+	}
+
+	data = append(data[:indexToDelete], data[indexToDelete+1:]...)
+
+	_, err = l.JsonStorage.WriteFile(data, constants.LoginAttemptJsonPath)
+	if err != nil {
+		logger.Error("Failed to write updated login attempts file", err)
+		return err
+	}
+
+	logger.Info("Login attempt deleted successfully")
+	return nil
+}
@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"PaymentAPI/entity"
+	"fmt"
+	"github.com/stretchr/testify/mock"
+)
+
+type LoginAttemptRepositoryMock struct {
+	Mock mock.Mock
+}
+
+func (l *LoginAttemptRepositoryMock) GetAll() ([]entity.LoginAttempt, error) {
+	args := l.Mock.Called()
+	loginAttempts, ok := args.Get(0).([]entity.LoginAttempt)
+	if !ok {
+		return nil, fmt.Errorf("invalid type for login attempt")
+	}
+	return loginAttempts, args.Error(1)
+}
+
+func (l *LoginAttemptRepositoryMock) GetById(id string) (entity.LoginAttempt, error) {
+	args := l.Mock.Called(id)
+	return args.Get(0).(entity.LoginAttempt), args.Error(1)
+}
+
+func (l *LoginAttemptRepositoryMock) Save(loginAttempt entity.LoginAttempt) error {
+	args := l.Mock.Called(loginAttempt)
+	return args.Error(0)
+}
+
+func (l *LoginAttemptRepositoryMock) Delete(id string) error {
+	args := l.Mock.Called(id)
+	return args.Error(0)
+}
@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"PaymentAPI/constants"
+	"PaymentAPI/entity"
+	"PaymentAPI/storage"
+	"errors"
+	"github.com/sirupsen/logrus"
+)
+
+type LoginChallengeRepository interface {
+	GetByTokenHash(tokenHash string) (entity.LoginChallenge, error)
+	Create(loginChallenge entity.LoginChallenge) error
+	Update(loginChallenge entity.LoginChallenge) error
+	Delete(id string) error
+}
+
+type loginChallengeRepository struct {
+	JsonStorage storage.JsonFileHandler[entity.LoginChallenge]
+}
+
+// NewLoginChallengeRepository creates a new instance of LoginChallengeRepository
+func NewLoginChallengeRepository(jsonStorage storage.JsonFileHandler[entity.LoginChallenge]) LoginChallengeRepository {
+	return &loginChallengeRepository{JsonStorage: jsonStorage}
+}
+
+// GetByTokenHash retrieves a login challenge by the hash of its token
+func (l *loginChallengeRepository) GetByTokenHash(tokenHash string) (entity.LoginChallenge, error) {
+	logger := logrus.WithFields(logrus.Fields{})
+
+	logger.Info("Retrieving login challenge")
+
+	data, err := l.JsonStorage.ReadFile(constants.LoginChallengeJsonPath)
+	if err != nil {
+		logger.Error("Failed to read login challenges file", err)
+		return entity.LoginChallenge{}, err
+	}
+
+	for _, loginChallenge := range data {
+		if loginChallenge.TokenHash == tokenHash {
+			logger.WithField("loginChallengeId", loginChallenge.Id).Info("Login challenge found")
+			return loginChallenge, nil
+		}
+	}
+
+	logger.Warn("Login challenge not found")
+	return entity.LoginChallenge{}, errors.New(constants.LoginChallengeNotFoundError)
+}
+
+// Create adds a new login challenge to storage
+func (l *loginChallengeRepository) Create(loginChallenge entity.LoginChallenge) error {
+	logger := logrus.WithFields(logrus.Fields{
+		"loginChallengeId": loginChallenge.Id,
+		"customerId":       loginChallenge.CustomerId,
+	})
+
+	logger.Info("Creating new login challenge")
+
+	data, err := l.JsonStorage.ReadFile(constants.LoginChallengeJsonPath)
+	if err != nil {
+		logger.Error("Failed to read login challenges file", err)
+		return err
+	}
+
+	data = append(data, loginChallenge)
+
+	_, err = l.JsonStorage.WriteFile(data, constants.LoginChallengeJsonPath)
+	if err != nil {
+		logger.Error("Failed to write updated login challenges file", err)
+		return err
+	}
+
+	logger.Info("New login challenge created successfully")
+	return nil
+}
+
+// Update replaces a stored login challenge with the given login challenge
+func (l *loginChallengeRepository) Update(loginChallenge entity.LoginChallenge) error {
+	logger := logrus.WithFields(logrus.Fields{
+		"loginChallengeId": loginChallenge.Id,
+	})
+
+	logger.Info("Updating login challenge")
+
+	data, err := l.JsonStorage.ReadFile(constants.LoginChallengeJsonPath)
+	if err != nil {
+		logger.Error("Failed to read login challenges file", err)
+		return err
+	}
+
+	loginChallengeFound := false
+	for i := range data {
+		if data[i].Id == loginChallenge.Id {
+			data[i] = loginChallenge
+			loginChallengeFound = true
+			break
+		}
+	}
+
+	if !loginChallengeFound {
+		logger.Warn("Login challenge not found")
+		return errors.New(constants.LoginChallengeNotFoundError)
+	}
+
+	_, err = l.JsonStorage.WriteFile(data, constants.LoginChallengeJsonPath)
+	if err != nil {
+		logger.Error("Failed to write updated login challenges file", err)
+		return err
+	}
+
+	logger.Info("Login challenge updated successfully")
+	return nil
+}
+
+// Delete removes a login challenge by its ID
+func (l *loginChallengeRepository) Delete(id string) error {
+	logger := logrus.WithFields(logrus.Fields{
+		"loginChallengeId": id,
+	})
+
+	logger.Info("Deleting login challenge")
+
+	data, err := l.JsonStorage.ReadFile(constants.LoginChallengeJsonPath)
+	if err != nil {
+		logger.Error("Failed to read login challenges file", err)
+		return err
+	}
+
+	indexToDelete := -1
+	for i, loginChallenge := range data {
+		if loginChallenge.Id == id {
+			indexToDelete = i
+			break
+		}
+	}
+
+	if indexToDelete == -1 {
+		logger.Warn("Login challenge not found")
+		return errors.New(constants.LoginChallengeNotFoundError)
+	}
+
+	data = append(data[:indexToDelete], data[indexToDelete+1:]...)
+
+	_, err = l.JsonStorage.WriteFile(data, constants.LoginChallengeJsonPath)
+	if err != nil {
+		logger.Error("Failed to write updated login challenges file", err)
+		return err
+	}
+
+	logger.Info("Login challenge deleted successfully")
+	return nil
+}
@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"PaymentAPI/entity"
+	"github.com/stretchr/testify/mock"
+)
+
+type LoginChallengeRepositoryMock struct {
+	Mock mock.Mock
+}
+
+func (l *LoginChallengeRepositoryMock) GetByTokenHash(tokenHash string) (entity.LoginChallenge, error) {
+	args := l.Mock.Called(tokenHash)
+	return args.Get(0).(entity.LoginChallenge), args.Error(1)
+}
+
+func (l *LoginChallengeRepositoryMock) Create(loginChallenge entity.LoginChallenge) error {
+	args := l.Mock.Called(loginChallenge)
+	return args.Error(0)
+}
+
+func (l *LoginChallengeRepositoryMock) Update(loginChallenge entity.LoginChallenge) error {
+	args := l.Mock.Called(loginChallenge)
+	return args.Error(0)
+}
+
+func (l *LoginChallengeRepositoryMock) Delete(id string) error {
+	args := l.Mock.Called(id)
+	return args.Error(0)
+}
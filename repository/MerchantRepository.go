@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"PaymentAPI/constants"
+	"PaymentAPI/entity"
+	"PaymentAPI/storage"
+	"errors"
+	"github.com/sirupsen/logrus"
+)
+
+type MerchantRepository interface {
+	GetById(id string) (entity.Merchant, error)
+	Create(merchant entity.Merchant) error
+}
+
+type merchantRepository struct {
+	JsonStorage storage.JsonFileHandler[entity.Merchant]
+}
+
+// NewMerchantRepository creates a new instance of MerchantRepository
+func NewMerchantRepository(jsonStorage storage.JsonFileHandler[entity.Merchant]) MerchantRepository {
+	return &merchantRepository{JsonStorage: jsonStorage}
+}
+
+// GetById retrieves a merchant by its ID
+func (m *merchantRepository) GetById(id string) (entity.Merchant, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"merchantId": id,
+	})
+
+	logger.Info("Retrieving merchant")
+
+	data, err := m.JsonStorage.ReadFile(constants.MerchantJsonPath)
+	if err != nil {
+		logger.Error("Failed to read merchants file", err)
+		return entity.Merchant{}, err
+	}
+
+	for _, merchant := range data {
+		if merchant.Id == id {
+			logger.Info("Merchant found")
+			return merchant, nil
+		}
+	}
+
+	logger.Warn("Merchant not found")
+	return entity.Merchant{}, errors.New(constants.MerchantNotFoundError)
+}
+
+// Create adds a new merchant to storage
+func (m *merchantRepository) Create(merchant entity.Merchant) error {
+	logger := logrus.WithFields(logrus.Fields{
+		"merchantId": merchant.Id,
+		"customerId": merchant.CustomerId,
+	})
+
+	logger.Info("Creating new merchant")
+
+	data, err := m.JsonStorage.ReadFile(constants.MerchantJsonPath)
+	if err != nil {
+		logger.Error("Failed to read merchants file", err)
+		return err
+	}
+
+	data = append(data, merchant)
+
+	_, err = m.JsonStorage.WriteFile(data, constants.MerchantJsonPath)
+	if err != nil {
+		logger.Error("Failed to write updated merchants file", err)
+		return err
+	}
+
+	logger.Info("New merchant created successfully")
+	return nil
+}
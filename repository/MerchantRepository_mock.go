@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"PaymentAPI/entity"
+	"github.com/stretchr/testify/mock"
+)
+
+type MerchantRepositoryMock struct {
+	Mock mock.Mock
+}
+
+func (m *MerchantRepositoryMock) GetById(id string) (entity.Merchant, error) {
+	args := m.Mock.Called(id)
+	return args.Get(0).(entity.Merchant), args.Error(1)
+}
+
+func (m *MerchantRepositoryMock) Create(merchant entity.Merchant) error {
+	args := m.Mock.Called(merchant)
+	return args.Error(0)
+}
@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"PaymentAPI/constants"
+	"PaymentAPI/entity"
+	"PaymentAPI/storage"
+	"errors"
+	"github.com/sirupsen/logrus"
+)
+
+type OutboxEventRepository interface {
+	GetAll() ([]entity.OutboxEvent, error)
+	GetById(id string) (entity.OutboxEvent, error)
+	Create(outboxEvent entity.OutboxEvent) error
+	CreateAll(outboxEvents []entity.OutboxEvent) error
+	Update(outboxEvent entity.OutboxEvent) error
+	UpdateAll(outboxEvents []entity.OutboxEvent) error
+	DeleteAll(ids []string) error
+}
+
+type outboxEventRepository struct {
+	JsonStorage storage.JsonFileHandler[entity.OutboxEvent]
+}
+
+// NewOutboxEventRepository creates a new instance of OutboxEventRepository
+func NewOutboxEventRepository(jsonStorage storage.JsonFileHandler[entity.OutboxEvent]) OutboxEventRepository {
+	return &outboxEventRepository{JsonStorage: jsonStorage}
+}
+
+// GetAll retrieves all outbox events from storage
+func (o *outboxEventRepository) GetAll() ([]entity.OutboxEvent, error) {
+	logger := logrus.WithFields(logrus.Fields{})
+
+	logger.Info("Retrieving all outbox events")
+
+	data, err := o.JsonStorage.ReadFile(constants.OutboxEventJsonPath)
+	if err != nil {
+		logger.Error("Failed to read outbox events file", err)
+		return nil, err
+	}
+
+	logger.Info("All outbox events retrieved successfully")
+	return data, nil
+}
+
+// GetById retrieves a outbox event by its ID
+func (o *outboxEventRepository) GetById(id string) (entity.OutboxEvent, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"outboxEventId": id,
+	})
+
+	logger.Info("Retrieving outbox event")
+
+	data, err := o.GetAll()
+	if err != nil {
+		return entity.OutboxEvent{}, err
+	}
+
+	for _, outboxEvent := range data {
+		if outboxEvent.Id == id {
+			logger.Info("Outbox event found")
+			return outboxEvent, nil
+		}
+	}
+
+	logger.Warn("Outbox event not found")
+	return entity.OutboxEvent{}, errors.New(constants.OutboxEventNotFoundError)
+}
+
+// Create adds a new outbox event to storage
+func (o *outboxEventRepository) Create(outboxEvent entity.OutboxEvent) error {
+	return o.CreateAll([]entity.OutboxEvent{outboxEvent})
+}
+
+// CreateAll adds new outbox events to storage in a single write
+func (o *outboxEventRepository) CreateAll(outboxEvents []entity.OutboxEvent) error {
+	logger := logrus.WithFields(logrus.Fields{
+		"count": len(outboxEvents),
+	})
+
+	logger.Info("Creating new outbox events")
+
+	data, err := o.JsonStorage.ReadFile(constants.OutboxEventJsonPath)
+	if err != nil {
+		logger.Error("Failed to read outbox events file", err)
+		return err
+	}
+
+	data = append(data, outboxEvents...)
+
+	_, err = o.JsonStorage.WriteFile(data, constants.OutboxEventJsonPath)
+	if err != nil {
+		logger.Error("Failed to write updated outbox events file", err)
+		return err
+	}
+
+	logger.Info("New outbox events created successfully")
+	return nil
+}
+
+// Update replaces a stored outbox event with the given outbox event
+func (o *outboxEventRepository) Update(outboxEvent entity.OutboxEvent) error {
+	return o.UpdateAll([]entity.OutboxEvent{outboxEvent})
+}
+
+// UpdateAll replaces stored outbox events with the given outbox events in a single write
+func (o *outboxEventRepository) UpdateAll(outboxEvents []entity.OutboxEvent) error {
+	logger := logrus.WithFields(logrus.Fields{
+		"count": len(outboxEvents),
+	})
+
+	logger.Info("Updating outbox events")
+
+	data, err := o.JsonStorage.ReadFile(constants.OutboxEventJsonPath)
+	if err != nil {
+		logger.Error("Failed to read outbox events file", err)
+		return err
+	}
+
+	indexes := make(map[string]int, len(data))
+	for i := range data {
+		indexes[data[i].Id] = i
+	}
+	for _, outboxEvent := range outboxEvents {
+		i, found := indexes[outboxEvent.Id]
+		if !found {
+			logger.WithField("outboxEventId", outboxEvent.Id).Warn("Outbox event not found")
+			return errors.New(constants.OutboxEventNotFoundError)
+		}
+		data[i] = outboxEvent
+	}
+
+	_, err = o.JsonStorage.WriteFile(data, constants.OutboxEventJsonPath)
+	if err != nil {
+		logger.Error("Failed to write updated outbox events file", err)
+		return err
+	}
+
+	logger.Info("Outbox events updated successfully")
+	return nil
+}
+
+// DeleteAll removes the outbox events with the given IDs in a single write, IDs no longer stored are skipped
+func (o *outboxEventRepository) DeleteAll(ids []string) error {
+	logger := logrus.WithFields(logrus.Fields{
+		"count": len(ids),
+	})
+
+	logger.Info("Deleting outbox events")
+
+	data, err := o.JsonStorage.ReadFile(constants.OutboxEventJsonPath)
+	if err != nil {
+		logger.Error("Failed to read outbox events file", err)
+		return err
+	}
+
+	deleted := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		deleted[id] = true
+	}
+
+	kept := make([]entity.OutboxEvent, 0, len(data))
+	for _, outboxEvent := range data {
+		if !deleted[outboxEvent.Id] {
+			kept = append(kept, outboxEvent)
+		}
+	}
+
+	_, err = o.JsonStorage.WriteFile(kept, constants.OutboxEventJsonPath)
+	if err != nil {
+		logger.Error("Failed to write updated outbox events file", err)
+		return err
+	}
+
+	logger.Info("Outbox events deleted successfully")
+	return nil
+}
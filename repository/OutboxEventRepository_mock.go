@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"PaymentAPI/entity"
+	"fmt"
+	"github.com/stretchr/testify/mock"
+)
+
+type OutboxEventRepositoryMock struct {
+	Mock mock.Mock
+}
+
+func (o *OutboxEventRepositoryMock) GetAll() ([]entity.OutboxEvent, error) {
+	args := o.Mock.Called()
+	outboxEvents, ok := args.Get(0).([]entity.OutboxEvent)
+	if !ok {
+		return nil, fmt.Errorf("invalid type for outbox event")
+	}
+	return outboxEvents, args.Error(1)
+}
+
+func (o *OutboxEventRepositoryMock) GetById(id string) (entity.OutboxEvent, error) {
+	args := o.Mock.Called(id)
+	return args.Get(0).(entity.OutboxEvent), args.Error(1)
+}
+
+func (o *OutboxEventRepositoryMock) Create(outboxEvent entity.OutboxEvent) error {
+	args := o.Mock.Called(outboxEvent)
+	return args.Error(0)
+}
+
+func (o *OutboxEventRepositoryMock) Update(outboxEvent entity.OutboxEvent) error {
+	args := o.Mock.Called(outboxEvent)
+	return args.Error(0)
+}
+
+func (o *OutboxEventRepositoryMock) CreateAll(outboxEvents []entity.OutboxEvent) error {
+	args := o.Mock.Called(outboxEvents)
+	return args.Error(0)
+}
+
+func (o *OutboxEventRepositoryMock) UpdateAll(outboxEvents []entity.OutboxEvent) error {
+	args := o.Mock.Called(outboxEvents)
+	return args.Error(0)
+}
+
+func (o *OutboxEventRepositoryMock) DeleteAll(ids []string) error {
+	args := o.Mock.Called(ids)
+	return args.Error(0)
+}
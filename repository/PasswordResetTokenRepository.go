@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"PaymentAPI/constants"
+	"PaymentAPI/entity"
+	"PaymentAPI/storage"
+	"errors"
+	"github.com/sirupsen/logrus"
+)
+
+type PasswordResetTokenRepository interface {
+	GetAll() ([]entity.PasswordResetToken, error)
+	GetByTokenHash(tokenHash string) (entity.PasswordResetToken, error)
+	Create(passwordResetToken entity.PasswordResetToken) error
+	Update(passwordResetToken entity.PasswordResetToken) error
+}
+
+type passwordResetTokenRepository struct {
+	JsonStorage storage.JsonFileHandler[entity.PasswordResetToken]
+}
+
+// NewPasswordResetTokenRepository creates a new instance of PasswordResetTokenRepository
+func NewPasswordResetTokenRepository(jsonStorage storage.JsonFileHandler[entity.PasswordResetToken]) PasswordResetTokenRepository {
+	return &passwordResetTokenRepository{JsonStorage: jsonStorage}
+}
+
+// GetAll retrieves all password reset tokens from storage
+func (p *passwordResetTokenRepository) GetAll() ([]entity.PasswordResetToken, error) {
+	logger := logrus.WithFields(logrus.Fields{})
+
+	logger.Info("Retrieving all password reset tokens")
+
+	data, err := p.JsonStorage.ReadFile(constants.PasswordResetTokenJsonPath)
+	if err != nil {
+		logger.Error("Failed to read password reset tokens file", err)
+		return nil, err
+	}
+
+	logger.Info("All password reset tokens retrieved successfully")
+	return data, nil
+}
+
+// GetByTokenHash retrieves a password reset token by the hash of its token
+func (p *passwordResetTokenRepository) GetByTokenHash(tokenHash string) (entity.PasswordResetToken, error) {
+	logger := logrus.WithFields(logrus.Fields{})
+
+	logger.Info("Retrieving password reset token")
+
+	data, err := p.GetAll()
+	if err != nil {
+		return entity.PasswordResetToken{}, err
+	}
+
+	for _, passwordResetToken := range data {
+		if passwordResetToken.TokenHash == tokenHash {
+			logger.WithField("passwordResetTokenId", passwordResetToken.Id).Info("Password reset token found")
+			return passwordResetToken, nil
+		}
+	}
+
+	logger.Warn("Password reset token not found")
+	return entity.PasswordResetToken{}, errors.New(constants.PasswordResetTokenNotFoundError)
+}
+
+// Create adds a new password reset token to storage
+func (p *passwordResetTokenRepository) Create(passwordResetToken entity.PasswordResetToken) error {
+	logger := logrus.WithFields(logrus.Fields{
+		"passwordResetTokenId": passwordResetToken.Id,
+		"customerId":           passwordResetToken.CustomerId,
+	})
+
+	logger.Info("Creating new password reset token")
+
+	data, err := p.JsonStorage.ReadFile(constants.PasswordResetTokenJsonPath)
+	if err != nil {
+		logger.Error("Failed to read password reset tokens file", err)
+		return err
+	}
+
+	data = append(data, passwordResetToken)
+
+	_, err = p.JsonStorage.WriteFile(data, constants.PasswordResetTokenJsonPath)
+	if err != nil {
+		logger.Error("Failed to write updated password reset tokens file", err)
+		return err
+	}
+
+	logger.Info("New password reset token created successfully")
+	return nil
+}
+
+// Update replaces a stored password reset token with the given password reset token
+func (p *passwordResetTokenRepository) Update(passwordResetToken entity.PasswordResetToken) error {
+	logger := logrus.WithFields(logrus.Fields{
+		"passwordResetTokenId": passwordResetToken.Id,
+	})
+
+	logger.Info("Updating password reset token")
+
+	data, err := p.JsonStorage.ReadFile(constants.PasswordResetTokenJsonPath)
+	if err != nil {
+		logger.Error("Failed to read password reset tokens file", err)
+		return err
+	}
+
+	passwordResetTokenFound := false
+	for i := range data {
+		if data[i].Id == passwordResetToken.Id {
+			data[i] = passwordResetToken
+			passwordResetTokenFound = true
+			break
+		}
+	}
+
+	if !passwordResetTokenFound {
+		logger.Warn("Password reset token not found")
+		return errors.New(constants.PasswordResetTokenNotFoundError)
+	}
+
+	_, err = p.JsonStorage.WriteFile(data, constants.PasswordResetTokenJsonPath)
+	if err != nil {
+		logger.Error("Failed to write updated password reset tokens file", err)
+		return err
+	}
+
+	logger.Info("Password reset token updated successfully")
+	return nil
+}
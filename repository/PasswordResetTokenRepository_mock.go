@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"PaymentAPI/entity"
+	"fmt"
+	"github.com/stretchr/testify/mock"
+)
+
+type PasswordResetTokenRepositoryMock struct {
+	Mock mock.Mock
+}
+
+func (p *PasswordResetTokenRepositoryMock) GetAll() ([]entity.PasswordResetToken, error) {
+	args := p.Mock.Called()
+	passwordResetTokens, ok := args.Get(0).([]entity.PasswordResetToken)
+	if !ok {
+		return nil, fmt.Errorf("invalid type for password reset token")
+	}
+	return passwordResetTokens, args.Error(1)
+}
+
+func (p *PasswordResetTokenRepositoryMock) GetByTokenHash(tokenHash string) (entity.PasswordResetToken, error) {
+	args := p.Mock.Called(tokenHash)
+	return args.Get(0).(entity.PasswordResetToken), args.Error(1)
+}
+
+func (p *PasswordResetTokenRepositoryMock) Create(passwordResetToken entity.PasswordResetToken) error {
+	args := p.Mock.Called(passwordResetToken)
+	return args.Error(0)
+}
+
+func (p *PasswordResetTokenRepositoryMock) Update(passwordResetToken entity.PasswordResetToken) error {
+	args := p.Mock.Called(passwordResetToken)
+	return args.Error(0)
+}
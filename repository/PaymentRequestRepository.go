@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"PaymentAPI/constants"
+	"PaymentAPI/entity"
+	"PaymentAPI/storage"
+	"errors"
+	"github.com/sirupsen/logrus"
+)
+
+type PaymentRequestRepository interface {
+	GetAll() ([]entity.PaymentRequest, error)
+	GetById(id string) (entity.PaymentRequest, error)
+	Create(paymentRequest entity.PaymentRequest) error
+	Update(paymentRequest entity.PaymentRequest) error
+}
+
+type paymentRequestRepository struct {
+	JsonStorage storage.JsonFileHandler[entity.PaymentRequest]
+}
+
+// NewPaymentRequestRepository creates a new instance of PaymentRequestRepository
+func NewPaymentRequestRepository(jsonStorage storage.JsonFileHandler[entity.PaymentRequest]) PaymentRequestRepository {
+	return &paymentRequestRepository{JsonStorage: jsonStorage}
+}
+
+// GetAll retrieves all payment requests from storage
+func (p *paymentRequestRepository) GetAll() ([]entity.PaymentRequest, error) {
+	logger := logrus.WithFields(logrus.Fields{})
+
+	logger.Info("Retrieving all payment requests")
+
+	data, err := p.JsonStorage.ReadFile(constants.PaymentRequestJsonPath)
+	if err != nil {
+		logger.Error("Failed to read payment requests file", err)
+		return nil, err
+	}
+
+	logger.Info("All payment requests retrieved successfully")
+	return data, nil
+}
+
+// GetById retrieves a payment request by its ID
+func (p *paymentRequestRepository) GetById(id string) (entity.PaymentRequest, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"paymentRequestId": id,
+	})
+
+	logger.Info("Retrieving payment request")
+
+	data, err := p.GetAll()
+	if err != nil {
+		return entity.PaymentRequest{}, err
+	}
+
+	for _, paymentRequest := range data {
+		if paymentRequest.Id == id {
+			logger.Info("Payment request found")
+			return paymentRequest, nil
+		}
+	}
+
+	logger.Warn("Payment request not found")
+	return entity.PaymentRequest{}, errors.New(constants.PaymentRequestNotFoundError)
+}
+
+// Create adds a new payment request to storage
+func (p *paymentRequestRepository) Create(paymentRequest entity.PaymentRequest) error {
+	logger := logrus.WithFields(logrus.Fields{
+		"paymentRequestId":  paymentRequest.Id,
+		"requesterWalletId": paymentRequest.RequesterWalletId,
+		"payerWalletId":     paymentRequest.PayerWalletId,
+		"amount":            paymentRequest.Amount,
+	})
+
+	logger.Info("Creating new payment request")
+
+	data, err := p.JsonStorage.ReadFile(constants.PaymentRequestJsonPath)
+	if err != nil {
+		logger.Error("Failed to read payment requests file", err)
+		return err
+	}
+
+	data = append(data, paymentRequest)
+
+	_, err = p.JsonStorage.WriteFile(data, constants.PaymentRequestJsonPath)
+	if err != nil {
+		logger.Error("Failed to write updated payment requests file", err)
+		return err
+	}
+
+	logger.Info("New payment request created successfully")
+	return nil
+}
+
+// Update replaces a stored payment request with the given payment request
+func (p *paymentRequestRepository) Update(paymentRequest entity.PaymentRequest) error {
+	logger := logrus.WithFields(logrus.Fields{
+		"paymentRequestId": paymentRequest.Id,
+		"status":           paymentRequest.Status,
+	})
+
+	logger.Info("Updating payment request")
+
+	data, err := p.JsonStorage.ReadFile(constants.PaymentRequestJsonPath)
+	if err != nil {
+		logger.Error("Failed to read payment requests file", err)
+		return err
+	}
+
+	paymentRequestFound := false
+	for i := range data {
+		if data[i].Id == paymentRequest.Id {
+			data[i] = paymentRequest
+			paymentRequestFound = true
+			break
+		}
+	}
+
+	if !paymentRequestFound {
+		logger.Warn("Payment request not found")
+		return errors.New(constants.PaymentRequestNotFoundError)
+	}
+
+	_, err = p.JsonStorage.WriteFile(data, constants.PaymentRequestJsonPath)
+	if err != nil {
+		logger.Error("Failed to write updated payment requests file", err)
+		return err
+	}
+
+	logger.Info("Payment request updated successfully")
+	return nil
+}
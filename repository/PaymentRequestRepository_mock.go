@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"PaymentAPI/entity"
+	"fmt"
+	"github.com/stretchr/testify/mock"
+)
+
+type PaymentRequestRepositoryMock struct {
+	Mock mock.Mock
+}
+
+func (p *PaymentRequestRepositoryMock) GetAll() ([]entity.PaymentRequest, error) {
+	args := p.Mock.Called()
+	paymentRequests, ok := args.Get(0).([]entity.PaymentRequest)
+	if !ok {
+		return nil, fmt.Errorf("invalid type for payment request")
+	}
+	return paymentRequests, args.Error(1)
+}
+
+func (p *PaymentRequestRepositoryMock) GetById(id string) (entity.PaymentRequest, error) {
+	args := p.Mock.Called(id)
+	return args.Get(0).(entity.PaymentRequest), args.Error(1)
+}
+
+func (p *PaymentRequestRepositoryMock) Create(paymentRequest entity.PaymentRequest) error {
+	args := p.Mock.Called(paymentRequest)
+	return args.Error(0)
+}
+
+func (p *PaymentRequestRepositoryMock) Update(paymentRequest entity.PaymentRequest) error {
+	args := p.Mock.Called(paymentRequest)
+	return args.Error(0)
+}
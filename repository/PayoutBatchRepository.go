@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"PaymentAPI/constants"
+	"PaymentAPI/entity"
+	"PaymentAPI/storage"
+	"errors"
+	"github.com/sirupsen/logrus"
+)
+
+type PayoutBatchRepository interface {
+	GetById(id string) (entity.PayoutBatch, error)
+	Create(batch entity.PayoutBatch) error
+	Update(batch entity.PayoutBatch) error
+}
+
+type payoutBatchRepository struct {
+	JsonStorage storage.JsonFileHandler[entity.PayoutBatch]
+}
+
+// NewPayoutBatchRepository creates a new instance of PayoutBatchRepository
+func NewPayoutBatchRepository(jsonStorage storage.JsonFileHandler[entity.PayoutBatch]) PayoutBatchRepository {
+	return &payoutBatchRepository{JsonStorage: jsonStorage}
+}
+
+// GetById retrieves a payout batch by its ID
+func (p *payoutBatchRepository) GetById(id string) (entity.PayoutBatch, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"payoutBatchId": id,
+	})
+
+	logger.Info("Retrieving payout batch")
+
+	data, err := p.JsonStorage.ReadFile(constants.PayoutBatchJsonPath)
+	if err != nil {
+		logger.Error("Failed to read payout batches file", err)
+		return entity.PayoutBatch{}, err
+	}
+
+	for _, batch := range data {
+		if batch.Id == id {
+			logger.Info("Payout batch found")
+			return batch, nil
+		}
+	}
+
+	logger.Warn("Payout batch not found")
+	return entity.PayoutBatch{}, errors.New(constants.PayoutBatchNotFoundError)
+}
+
+// Create adds a new payout batch to storage
+func (p *payoutBatchRepository) Create(batch entity.PayoutBatch) error {
+	logger := logrus.WithFields(logrus.Fields{
+		"payoutBatchId": batch.Id,
+		"walletId":      batch.WalletId,
+		"totalAmount":   batch.TotalAmount,
+	})
+
+	logger.Info("Creating new payout batch")
+
+	data, err := p.JsonStorage.ReadFile(constants.PayoutBatchJsonPath)
+	if err != nil {
+		logger.Error("Failed to read payout batches file", err)
+		return err
+	}
+
+	data = append(data, batch)
+
+	_, err = p.JsonStorage.WriteFile(data, constants.PayoutBatchJsonPath)
+	if err != nil {
+		logger.Error("Failed to write updated payout batches file", err)
+		return err
+	}
+
+	logger.Info("New payout batch created successfully")
+	return nil
+}
+
+// Update replaces a stored payout batch with the given payout batch
+func (p *payoutBatchRepository) Update(batch entity.PayoutBatch) error {
+	logger := logrus.WithFields(logrus.Fields{
+		"payoutBatchId": batch.Id,
+		"status":        batch.Status,
+	})
+
+	logger.Info("Updating payout batch")
+
+	data, err := p.JsonStorage.ReadFile(constants.PayoutBatchJsonPath)
+	if err != nil {
+		logger.Error("Failed to read payout batches file", err)
+		return err
+	}
+
+	batchFound := false
+	for i := range data {
+		if data[i].Id == batch.Id {
+			data[i] = batch
+			batchFound = true
+			break
+		}
+	}
+
+	if !batchFound {
+		logger.Warn("Payout batch not found")
+		return errors.New(constants.PayoutBatchNotFoundError)
+	}
+
+	_, err = p.JsonStorage.WriteFile(data, constants.PayoutBatchJsonPath)
+	if err != nil {
+		logger.Error("Failed to write updated payout batches file", err)
+		return err
+	}
+
+	logger.Info("Payout batch updated successfully")
+	return nil
+}
@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"PaymentAPI/entity"
+	"github.com/stretchr/testify/mock"
+)
+
+type PayoutBatchRepositoryMock struct {
+	Mock mock.Mock
+}
+
+func (p *PayoutBatchRepositoryMock) GetById(id string) (entity.PayoutBatch, error) {
+	args := p.Mock.Called(id)
+	return args.Get(0).(entity.PayoutBatch), args.Error(1)
+}
+
+func (p *PayoutBatchRepositoryMock) Create(batch entity.PayoutBatch) error {
+	args := p.Mock.Called(batch)
+	return args.Error(0)
+}
+
+func (p *PayoutBatchRepositoryMock) Update(batch entity.PayoutBatch) error {
+	args := p.Mock.Called(batch)
+	return args.Error(0)
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"PaymentAPI/constants"
+	"PaymentAPI/entity"
+	"PaymentAPI/storage"
+	"github.com/sirupsen/logrus"
+)
+
+type ReconciliationReportRepository interface {
+	GetAll() ([]entity.ReconciliationReport, error)
+	Create(report entity.ReconciliationReport) error
+}
+
+type reconciliationReportRepository struct {
+	JsonStorage storage.JsonFileHandler[entity.ReconciliationReport]
+}
+
+// NewReconciliationReportRepository creates a new instance of ReconciliationReportRepository
+func NewReconciliationReportRepository(jsonStorage storage.JsonFileHandler[entity.ReconciliationReport]) ReconciliationReportRepository {
+	return &reconciliationReportRepository{JsonStorage: jsonStorage}
+}
+
+// GetAll retrieves all reconciliation reports from storage
+func (r *reconciliationReportRepository) GetAll() ([]entity.ReconciliationReport, error) {
+	logger := logrus.WithFields(logrus.Fields{})
+
+	logger.Info("Retrieving all reconciliation reports")
+
+	data, err := r.JsonStorage.ReadFile(constants.ReconciliationReportJsonPath)
+	if err != nil {
+		logger.Error("Failed to read reconciliation reports file", err)
+		return nil, err
+	}
+
+	logger.Info("All reconciliation reports retrieved successfully")
+	return data, nil
+}
+
+// Create adds a new reconciliation report to storage
+func (r *reconciliationReportRepository) Create(report entity.ReconciliationReport) error {
+	logger := logrus.WithFields(logrus.Fields{
+		"reportId": report.Id,
+		"balanced": report.Balanced,
+	})
+
+	logger.Info("Saving reconciliation report")
+
+	data, err := r.JsonStorage.ReadFile(constants.ReconciliationReportJsonPath)
+	if err != nil {
+		logger.Error("Failed to read reconciliation reports file", err)
+		return err
+	}
+
+	data = append(data, report)
+
+	_, err = r.JsonStorage.WriteFile(data, constants.ReconciliationReportJsonPath)
+	if err != nil {
+		logger.Error("Failed to write updated reconciliation reports file", err)
+		return err
+	}
+
+	logger.Info("Reconciliation report saved successfully")
+	return nil
+}
@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"PaymentAPI/entity"
+	"fmt"
+	"github.com/stretchr/testify/mock"
+)
+
+type ReconciliationReportRepositoryMock struct {
+	Mock mock.Mock
+}
+
+func (r *ReconciliationReportRepositoryMock) GetAll() ([]entity.ReconciliationReport, error) {
+	args := r.Mock.Called()
+	reports, ok := args.Get(0).([]entity.ReconciliationReport)
+	if !ok {
+		return nil, fmt.Errorf("invalid type for reconciliation report")
+	}
+	return reports, args.Error(1)
+}
+
+func (r *ReconciliationReportRepositoryMock) Create(report entity.ReconciliationReport) error {
+	args := r.Mock.Called(report)
+	return args.Error(0)
+}
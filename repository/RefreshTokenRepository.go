@@ -5,16 +5,15 @@ import (
 	"PaymentAPI/entity"
 	"PaymentAPI/storage"
 	"errors"
-	"github.com/google/uuid"
 	"github.com/sirupsen/logrus" // Import logrus for structured logging
-	"time"
 )
 
 type RefreshTokenRepository interface {
-	CreateRefreshToken(customerId string) (entity.RefreshToken, error)
-	GetRefreshToken(refreshToken string) (entity.RefreshToken, error)
+	CreateRefreshToken(refreshToken entity.RefreshToken) error
+	GetRefreshToken(tokenHash string) (entity.RefreshToken, error)
 	GetAllRefreshToken() ([]entity.RefreshToken, error)
-	DeleteRefreshToken(refreshToken string) error
+	UpdateRefreshToken(refreshToken entity.RefreshToken) error
+	DeleteRefreshToken(tokenHash string) error
 }
 
 type refreshTokenRepository struct {
@@ -26,26 +25,20 @@ func NewRefreshTokenRepository(jsonStorage storage.JsonFileHandler[entity.Refres
 	return &refreshTokenRepository{JsonStorage: jsonStorage}
 }
 
-// CreateRefreshToken creates a new refresh token for a given customerId
-func (r *refreshTokenRepository) CreateRefreshToken(customerId string) (entity.RefreshToken, error) {
+// CreateRefreshToken stores a new refresh token
+func (r *refreshTokenRepository) CreateRefreshToken(refreshToken entity.RefreshToken) error {
 	logger := logrus.WithFields(logrus.Fields{
-		"customerId": customerId,
+		"customerId": refreshToken.CustomerId,
+		"sessionId":  refreshToken.SessionId,
 	})
 
 	logger.Info("Creating new refresh token")
 
-	// Create new refresh token
-	refreshToken := entity.RefreshToken{
-		RefreshToken: uuid.New().String(),
-		CustomerId:   customerId,
-		ExpiresAt:    time.Now().Add(24 * time.Hour).Format(time.RFC3339),
-	}
-
 	// Read existing refresh tokens from file
 	data, err := r.JsonStorage.ReadFile(constants.RefreshTokenJsonPath)
 	if err != nil {
 		logger.Error("Failed to read refresh tokens file", err)
-		return entity.RefreshToken{}, err
+		return err
 	}
 
 	// Append the new token to the existing data
@@ -55,18 +48,16 @@ func (r *refreshTokenRepository) CreateRefreshToken(customerId string) (entity.R
 	_, err = r.JsonStorage.WriteFile(data, constants.RefreshTokenJsonPath)
 	if err != nil {
 		logger.Error("Failed to write updated refresh tokens file", err)
-		return entity.RefreshToken{}, err
+		return err
 	}
 
 	logger.Info("Refresh token created successfully")
-	return refreshToken, nil
+	return nil
 }
 
-// GetRefreshToken retrieves a refresh token by its value
-func (r *refreshTokenRepository) GetRefreshToken(refreshToken string) (entity.RefreshToken, error) {
-	logger := logrus.WithFields(logrus.Fields{
-		"refreshToken": refreshToken,
-	})
+// GetRefreshToken retrieves a refresh token by the hash of its value
+func (r *refreshTokenRepository) GetRefreshToken(tokenHash string) (entity.RefreshToken, error) {
+	logger := logrus.WithFields(logrus.Fields{})
 
 	logger.Info("Retrieving refresh token")
 
@@ -79,7 +70,7 @@ func (r *refreshTokenRepository) GetRefreshToken(refreshToken string) (entity.Re
 
 	// Search for the matching refresh token
 	for _, token := range data {
-		if token.RefreshToken == refreshToken {
+		if token.TokenHash == tokenHash {
 			logger.Info("Refresh token found")
 			return token, nil
 		}
@@ -106,12 +97,51 @@ func (r *refreshTokenRepository) GetAllRefreshToken() ([]entity.RefreshToken, er
 	return data, nil
 }
 
-// DeleteRefreshToken deletes a refresh token by its value
-func (r *refreshTokenRepository) DeleteRefreshToken(refreshToken string) error {
+// UpdateRefreshToken replaces a stored refresh token with the same hash
+func (r *refreshTokenRepository) UpdateRefreshToken(refreshToken entity.RefreshToken) error {
 	logger := logrus.WithFields(logrus.Fields{
-		"refreshToken": refreshToken,
+		"customerId": refreshToken.CustomerId,
+		"sessionId":  refreshToken.SessionId,
 	})
 
+	logger.Info("Updating refresh token")
+
+	// Read the refresh tokens from file
+	data, err := r.JsonStorage.ReadFile(constants.RefreshTokenJsonPath)
+	if err != nil {
+		logger.Error("Failed to read refresh tokens file", err)
+		return err
+	}
+
+	tokenFound := false
+	for i := range data {
+		if data[i].TokenHash == refreshToken.TokenHash {
+			data[i] = refreshToken
+			tokenFound = true
+			break
+		}
+	}
+
+	if !tokenFound {
+		logger.Error("Refresh token not found")
+		return errors.New(constants.RefreshTokenNotFoundError)
+	}
+
+	// Write the updated list back to the file
+	_, err = r.JsonStorage.WriteFile(data, constants.RefreshTokenJsonPath)
+	if err != nil {
+		logger.Error("Failed to write updated refresh tokens file", err)
+		return err
+	}
+
+	logger.Info("Refresh token updated successfully")
+	return nil
+}
+
+// DeleteRefreshToken deletes a refresh token by the hash of its value
+func (r *refreshTokenRepository) DeleteRefreshToken(tokenHash string) error {
+	logger := logrus.WithFields(logrus.Fields{})
+
 	logger.Info("Deleting refresh token")
 
 	// Read the refresh tokens from file
@@ -124,7 +154,7 @@ func (r *refreshTokenRepository) DeleteRefreshToken(refreshToken string) error {
 	// Search for the token and delete it if found
 	indexToDelete := -1
 	for i, token := range data {
-		if token.RefreshToken == refreshToken {
+		if token.TokenHash == tokenHash {
 			indexToDelete = i
 			break
 		}
@@ -2,7 +2,6 @@ package repository
 
 import (
 	"PaymentAPI/entity"
-	"fmt"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -10,17 +9,13 @@ type RefreshTokenRepositoryMock struct {
 	Mock mock.Mock
 }
 
-func (r *RefreshTokenRepositoryMock) CreateRefreshToken(customerId string) (entity.RefreshToken, error) {
-	args := r.Mock.Called(customerId)
-	refreshToken, ok := args.Get(0).(entity.RefreshToken)
-	if !ok {
-		return entity.RefreshToken{}, fmt.Errorf("invalid type for refresh token")
-	}
-	return refreshToken, args.Error(1)
+func (r *RefreshTokenRepositoryMock) CreateRefreshToken(refreshToken entity.RefreshToken) error {
+	args := r.Mock.Called(refreshToken)
+	return args.Error(0)
 }
 
-func (r *RefreshTokenRepositoryMock) GetRefreshToken(refreshToken string) (entity.RefreshToken, error) {
-	args := r.Mock.Called(refreshToken)
+func (r *RefreshTokenRepositoryMock) GetRefreshToken(tokenHash string) (entity.RefreshToken, error) {
+	args := r.Mock.Called(tokenHash)
 	return args.Get(0).(entity.RefreshToken), args.Error(1)
 }
 
@@ -29,7 +24,12 @@ func (r *RefreshTokenRepositoryMock) GetAllRefreshToken() ([]entity.RefreshToken
 	return args.Get(0).([]entity.RefreshToken), args.Error(1)
 }
 
-func (r *RefreshTokenRepositoryMock) DeleteRefreshToken(refreshToken string) error {
+func (r *RefreshTokenRepositoryMock) UpdateRefreshToken(refreshToken entity.RefreshToken) error {
 	args := r.Mock.Called(refreshToken)
 	return args.Error(0)
 }
+
+func (r *RefreshTokenRepositoryMock) DeleteRefreshToken(tokenHash string) error {
+	args := r.Mock.Called(tokenHash)
+	return args.Error(0)
+}
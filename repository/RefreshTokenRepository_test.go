@@ -2,12 +2,12 @@ package repository
 
 import (
 	"PaymentAPI/constants"
-	dto "PaymentAPI/dto/response"
 	"PaymentAPI/entity"
 	"PaymentAPI/storage"
-	"github.com/google/uuid"
+	"encoding/json"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"strings"
 	"testing"
 	"time"
 )
@@ -16,9 +16,12 @@ func TestCreateNewRefreshToken(t *testing.T) {
 	mockFileHandler := new(storage.RefreshTokenJsonFileHandlerMock[entity.RefreshToken])
 	refreshTokenRepository := NewRefreshTokenRepository(mockFileHandler)
 
-	customer := dto.CustomerResponse{
-		Id:       "id-1",
-		Username: "johndoe",
+	refreshToken := entity.RefreshToken{
+		RefreshToken: "token-1",
+		TokenHash:    "hash-1",
+		CustomerId:   "id-1",
+		SessionId:    "session-1",
+		ExpiresAt:    time.Now().Add(24 * time.Hour).Format(time.RFC3339),
 	}
 
 	mockFileHandler.Mock.On("ReadFile", constants.RefreshTokenJsonPath).
@@ -27,41 +30,32 @@ func TestCreateNewRefreshToken(t *testing.T) {
 	mockFileHandler.Mock.On("WriteFile",
 		mock.MatchedBy(func(refreshTokens []entity.RefreshToken) bool {
 			// Check if the slice has exactly one element
-			if len(refreshTokens) != 1 {
-				return false
-			}
-
-			refreshToken := refreshTokens[0]
-			if refreshToken.CustomerId != customer.Id {
+			if len(refreshTokens) != 1 || refreshTokens[0] != refreshToken {
 				return false
 			}
 
-			if _, err := uuid.Parse(refreshToken.RefreshToken); err != nil {
-				return false
-			}
-
-			return true
+			// Only the hash is written, never the token itself
+			stored, _ := json.Marshal(refreshTokens)
+			return !strings.Contains(string(stored), refreshToken.RefreshToken)
 		}),
 		constants.RefreshTokenJsonPath,
 	).Return(constants.JsonWriteSuccess, nil)
 
-	token, err := refreshTokenRepository.CreateRefreshToken(customer.Id)
+	err := refreshTokenRepository.CreateRefreshToken(refreshToken)
 
 	assert.Nil(t, err)
-	assert.NotEmpty(t, token)
-	_, err = uuid.Parse(token.RefreshToken)
-	assert.Nil(t, err)
+	mockFileHandler.Mock.AssertExpectations(t)
 }
 
 func TestGetRefreshToken(t *testing.T) {
 	mockFileHandler := new(storage.RefreshTokenJsonFileHandlerMock[entity.RefreshToken])
 	refreshTokenRepository := NewRefreshTokenRepository(mockFileHandler)
 
-	refreshToken := "token-1"
+	refreshToken := "hash-1"
 	expectedRefreshToken := entity.RefreshToken{
-		RefreshToken: "token-1",
-		CustomerId:   "user-1",
-		ExpiresAt:    time.Now().Add(24 * time.Hour).String(),
+		TokenHash:  "hash-1",
+		CustomerId: "user-1",
+		ExpiresAt:  time.Now().Add(24 * time.Hour).String(),
 	}
 
 	t.Run("ShouldReturnRefreshToken", func(t *testing.T) {
@@ -87,12 +81,12 @@ func TestDeleteRefreshToken(t *testing.T) {
 	mockFileHandler := new(storage.RefreshTokenJsonFileHandlerMock[entity.RefreshToken])
 	refreshTokenRepository := NewRefreshTokenRepository(mockFileHandler)
 
-	refreshToken := "token-1"
+	refreshToken := "hash-1"
 
 	storedRefreshToken := entity.RefreshToken{
-		RefreshToken: "token-1",
-		CustomerId:   "user-1",
-		ExpiresAt:    time.Now().Add(24 * time.Hour).String(),
+		TokenHash:  "hash-1",
+		CustomerId: "user-1",
+		ExpiresAt:  time.Now().Add(24 * time.Hour).String(),
 	}
 
 	t.Run("ShouldDeleteRefreshToken", func(t *testing.T) {
@@ -124,3 +118,42 @@ func TestDeleteRefreshToken(t *testing.T) {
 		assert.Equal(t, constants.RefreshTokenNotFoundError, err.Error())
 	})
 }
+
+func TestUpdateRefreshToken(t *testing.T) {
+	storedRefreshToken := entity.RefreshToken{
+		TokenHash:  "hash-1",
+		CustomerId: "user-1",
+		SessionId:  "session-1",
+		ExpiresAt:  time.Now().Add(24 * time.Hour).Format(time.RFC3339),
+	}
+
+	t.Run("ShouldUpdateRefreshToken", func(t *testing.T) {
+		mockFileHandler := new(storage.RefreshTokenJsonFileHandlerMock[entity.RefreshToken])
+		refreshTokenRepository := NewRefreshTokenRepository(mockFileHandler)
+
+		rotatedRefreshToken := storedRefreshToken
+		rotatedRefreshToken.RotatedAt = time.Now().Format(time.RFC3339)
+
+		mockFileHandler.Mock.On("ReadFile", constants.RefreshTokenJsonPath).
+			Return([]entity.RefreshToken{storedRefreshToken}, nil)
+		mockFileHandler.Mock.On("WriteFile", []entity.RefreshToken{rotatedRefreshToken}, constants.RefreshTokenJsonPath).
+			Return(constants.JsonWriteSuccess, nil)
+
+		err := refreshTokenRepository.UpdateRefreshToken(rotatedRefreshToken)
+
+		assert.Nil(t, err)
+		mockFileHandler.Mock.AssertExpectations(t)
+	})
+
+	t.Run("ShouldReturnError", func(t *testing.T) {
+		mockFileHandler := new(storage.RefreshTokenJsonFileHandlerMock[entity.RefreshToken])
+		refreshTokenRepository := NewRefreshTokenRepository(mockFileHandler)
+
+		mockFileHandler.Mock.On("ReadFile", constants.RefreshTokenJsonPath).
+			Return([]entity.RefreshToken{}, nil)
+
+		err := refreshTokenRepository.UpdateRefreshToken(storedRefreshToken)
+
+		assert.Equal(t, constants.RefreshTokenNotFoundError, err.Error())
+	})
+}
@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"PaymentAPI/constants"
+	"PaymentAPI/entity"
+	"PaymentAPI/storage"
+	"errors"
+	"github.com/sirupsen/logrus"
+)
+
+type SessionRepository interface {
+	GetById(id string) (entity.Session, error)
+	GetByCustomerId(customerId string) ([]entity.Session, error)
+	Save(session entity.Session) error
+	Delete(id string) error
+}
+
+type sessionRepository struct {
+	JsonStorage storage.JsonFileHandler[entity.Session]
+}
+
+// NewSessionRepository creates a new instance of SessionRepository
+func NewSessionRepository(jsonStorage storage.JsonFileHandler[entity.Session]) SessionRepository {
+	return &sessionRepository{JsonStorage: jsonStorage}
+}
+
+// GetById retrieves a session by its ID
+func (s *sessionRepository) GetById(id string) (entity.Session, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"sessionId": id,
+	})
+
+	logger.Info("Retrieving session")
+
+	data, err := s.JsonStorage.ReadFile(constants.SessionJsonPath)
+	if err != nil {
+		logger.Error("Failed to read sessions file", err)
+		return entity.Session{}, err
+	}
+
+	for _, session := range data {
+		if session.Id == id {
+			logger.Info("Session found")
+			return session, nil
+		}
+	}
+
+	logger.Warn("Session not found")
+	return entity.Session{}, errors.New(constants.SessionNotFoundError)
+}
+
+// GetByCustomerId retrieves every session of a customer
+func (s *sessionRepository) GetByCustomerId(customerId string) ([]entity.Session, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"customerId": customerId,
+	})
+
+	logger.Info("Retrieving sessions of customer")
+
+	data, err := s.JsonStorage.ReadFile(constants.SessionJsonPath)
+	if err != nil {
+		logger.Error("Failed to read sessions file", err)
+		return nil, err
+	}
+
+	sessions := []entity.Session{}
+	for _, session := range data {
+		if session.CustomerId == customerId {
+			sessions = append(sessions, session)
+		}
+	}
+
+	logger.Info("Sessions of customer retrieved successfully")
+	return sessions, nil
+}
+
+// Save stores a session, replacing the one stored before with the same ID
+func (s *sessionRepository) Save(session entity.Session) error {
+	logger := logrus.WithFields(logrus.Fields{
+		"sessionId":  session.Id,
+		"customerId": session.CustomerId,
+	})
+
+	logger.Info("Saving session")
+
+	data, err := s.JsonStorage.ReadFile(constants.SessionJsonPath)
+	if err != nil {
+		logger.Error("Failed to read sessions file", err)
+		return err
+	}
+
+	sessionFound := false
+	for i := range data {
+		if data[i].Id == session.Id {
+			data[i] = session
+			sessionFound = true
+			break
+		}
+	}
+	if !sessionFound {
+		data = append(data, session)
+	}
+
+	_, err = s.JsonStorage.WriteFile(data, constants.SessionJsonPath)
+	if err != nil {
+		logger.Error("Failed to write updated sessions file", err)
+		return err
+	}
+
+	logger.Info("Session saved successfully")
+	return nil
+}
+
+// Delete removes a session by its ID
+func (s *sessionRepository) Delete(id string) error {
+	logger := logrus.WithFields(logrus.Fields{
+		"sessionId": id,
+	})
+
+	logger.Info("Deleting session")
+
+	data, err := s.JsonStorage.ReadFile(constants.SessionJsonPath)
+	if err != nil {
+		logger.Error("Failed to read sessions file", err)
+		return err
+	}
+
+	indexToDelete := -1
+	for i, session := range data {
+		if session.Id == id {
+			indexToDelete = i
+			break
+		}
+	}
+
+	if indexToDelete == -1 {
+		logger.Warn("Session not found")
+		return errors.New(constants.SessionNotFoundError)
+	}
+
+	data = append(data[:indexToDelete], data[indexToDelete+1:]...)
+
+	_, err = s.JsonStorage.WriteFile(data, constants.SessionJsonPath)
+	if err != nil {
+		logger.Error("Failed to write updated sessions file", err)
+		return err
+	}
+
+	logger.Info("Session deleted successfully")
+	return nil
+}
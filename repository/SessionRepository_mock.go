@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"PaymentAPI/entity"
+	"github.com/stretchr/testify/mock"
+)
+
+type SessionRepositoryMock struct {
+	Mock mock.Mock
+}
+
+func (s *SessionRepositoryMock) GetById(id string) (entity.Session, error) {
+	args := s.Mock.Called(id)
+	return args.Get(0).(entity.Session), args.Error(1)
+}
+
+func (s *SessionRepositoryMock) GetByCustomerId(customerId string) ([]entity.Session, error) {
+	args := s.Mock.Called(customerId)
+	return args.Get(0).([]entity.Session), args.Error(1)
+}
+
+func (s *SessionRepositoryMock) Save(session entity.Session) error {
+	args := s.Mock.Called(session)
+	return args.Error(0)
+}
+
+func (s *SessionRepositoryMock) Delete(id string) error {
+	args := s.Mock.Called(id)
+	return args.Error(0)
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"PaymentAPI/constants"
+	"PaymentAPI/entity"
+	"PaymentAPI/storage"
+	"errors"
+	"github.com/sirupsen/logrus"
+)
+
+type SplitBillRepository interface {
+	GetById(id string) (entity.SplitBill, error)
+	Create(splitBill entity.SplitBill) error
+}
+
+type splitBillRepository struct {
+	JsonStorage storage.JsonFileHandler[entity.SplitBill]
+}
+
+// NewSplitBillRepository creates a new instance of SplitBillRepository
+func NewSplitBillRepository(jsonStorage storage.JsonFileHandler[entity.SplitBill]) SplitBillRepository {
+	return &splitBillRepository{JsonStorage: jsonStorage}
+}
+
+// GetById retrieves a split bill by its ID
+func (s *splitBillRepository) GetById(id string) (entity.SplitBill, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"splitBillId": id,
+	})
+
+	logger.Info("Retrieving split bill")
+
+	data, err := s.JsonStorage.ReadFile(constants.SplitBillJsonPath)
+	if err != nil {
+		logger.Error("Failed to read split bills file", err)
+		return entity.SplitBill{}, err
+	}
+
+	for _, splitBill := range data {
+		if splitBill.Id == id {
+			logger.Info("Split bill found")
+			return splitBill, nil
+		}
+	}
+
+	logger.Warn("Split bill not found")
+	return entity.SplitBill{}, errors.New(constants.SplitBillNotFoundError)
+}
+
+// Create adds a new split bill to storage
+func (s *splitBillRepository) Create(splitBill entity.SplitBill) error {
+	logger := logrus.WithFields(logrus.Fields{
+		"splitBillId":     splitBill.Id,
+		"creatorWalletId": splitBill.CreatorWalletId,
+		"totalAmount":     splitBill.TotalAmount,
+	})
+
+	logger.Info("Creating new split bill")
+
+	data, err := s.JsonStorage.ReadFile(constants.SplitBillJsonPath)
+	if err != nil {
+		logger.Error("Failed to read split bills file", err)
+		return err
+	}
+
+	data = append(data, splitBill)
+
+	_, err = s.JsonStorage.WriteFile(data, constants.SplitBillJsonPath)
+	if err != nil {
+		logger.Error("Failed to write updated split bills file", err)
+		return err
+	}
+
+	logger.Info("New split bill created successfully")
+	return nil
+}
@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"PaymentAPI/entity"
+	"github.com/stretchr/testify/mock"
+)
+
+type SplitBillRepositoryMock struct {
+	Mock mock.Mock
+}
+
+func (s *SplitBillRepositoryMock) GetById(id string) (entity.SplitBill, error) {
+	args := s.Mock.Called(id)
+	return args.Get(0).(entity.SplitBill), args.Error(1)
+}
+
+func (s *SplitBillRepositoryMock) Create(splitBill entity.SplitBill) error {
+	args := s.Mock.Called(splitBill)
+	return args.Error(0)
+}
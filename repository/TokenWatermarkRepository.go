@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"PaymentAPI/constants"
+	"PaymentAPI/entity"
+	"PaymentAPI/storage"
+	"github.com/sirupsen/logrus"
+)
+
+type TokenWatermarkRepository interface {
+	GetAll() ([]entity.TokenWatermark, error)
+	Save(tokenWatermark entity.TokenWatermark) error
+}
+
+type tokenWatermarkRepository struct {
+	JsonStorage storage.JsonFileHandler[entity.TokenWatermark]
+}
+
+// NewTokenWatermarkRepository creates a new instance of TokenWatermarkRepository
+func NewTokenWatermarkRepository(jsonStorage storage.JsonFileHandler[entity.TokenWatermark]) TokenWatermarkRepository {
+	return &tokenWatermarkRepository{JsonStorage: jsonStorage}
+}
+
+// GetAll retrieves the token watermark of every customer that has one
+func (t *tokenWatermarkRepository) GetAll() ([]entity.TokenWatermark, error) {
+	logger := logrus.WithFields(logrus.Fields{})
+
+	logger.Info("Retrieving token watermarks")
+
+	data, err := t.JsonStorage.ReadFile(constants.TokenWatermarkJsonPath)
+	if err != nil {
+		logger.Error("Failed to read token watermarks file", err)
+		return nil, err
+	}
+
+	logger.Info("Token watermarks retrieved successfully")
+	return data, nil
+}
+
+// Save stores the token watermark of a customer, replacing the one stored before
+func (t *tokenWatermarkRepository) Save(tokenWatermark entity.TokenWatermark) error {
+	logger := logrus.WithFields(logrus.Fields{
+		"customerId": tokenWatermark.CustomerId,
+	})
+
+	logger.Info("Saving token watermark")
+
+	data, err := t.JsonStorage.ReadFile(constants.TokenWatermarkJsonPath)
+	if err != nil {
+		logger.Error("Failed to read token watermarks file", err)
+		return err
+	}
+
+	watermarkFound := false
+	for i := range data {
+		if data[i].CustomerId == tokenWatermark.CustomerId {
+			data[i] = tokenWatermark
+			watermarkFound = true
+			break
+		}
+	}
+	if !watermarkFound {
+		data = append(data, tokenWatermark)
+	}
+
+	_, err = t.JsonStorage.WriteFile(data, constants.TokenWatermarkJsonPath)
+	if err != nil {
+		logger.Error("Failed to write updated token watermarks file", err)
+		return err
+	}
+
+	logger.Info("Token watermark saved successfully")
+	return nil
+}
@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"PaymentAPI/entity"
+	"github.com/stretchr/testify/mock"
+)
+
+type TokenWatermarkRepositoryMock struct {
+	Mock mock.Mock
+}
+
+func (t *TokenWatermarkRepositoryMock) GetAll() ([]entity.TokenWatermark, error) {
+	args := t.Mock.Called()
+	return args.Get(0).([]entity.TokenWatermark), args.Error(1)
+}
+
+func (t *TokenWatermarkRepositoryMock) Save(tokenWatermark entity.TokenWatermark) error {
+	args := t.Mock.Called(tokenWatermark)
+	return args.Error(0)
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"PaymentAPI/constants"
+	"PaymentAPI/entity"
+	"PaymentAPI/storage"
+	"errors"
+	"github.com/sirupsen/logrus"
+)
+
+type TransactionPinRepository interface {
+	GetByCustomerId(customerId string) (entity.TransactionPin, error)
+	Save(transactionPin entity.TransactionPin) error
+}
+
+type transactionPinRepository struct {
+	JsonStorage storage.JsonFileHandler[entity.TransactionPin]
+}
+
+// NewTransactionPinRepository creates a new instance of TransactionPinRepository
+func NewTransactionPinRepository(jsonStorage storage.JsonFileHandler[entity.TransactionPin]) TransactionPinRepository {
+	return &transactionPinRepository{JsonStorage: jsonStorage}
+}
+
+// GetByCustomerId retrieves the transaction PIN of a customer
+func (t *transactionPinRepository) GetByCustomerId(customerId string) (entity.TransactionPin, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"customerId": customerId,
+	})
+
+	logger.Info("Retrieving transaction PIN")
+
+	data, err := t.JsonStorage.ReadFile(constants.TransactionPinJsonPath)
+	if err != nil {
+		logger.Error("Failed to read transaction PINs file", err)
+		return entity.TransactionPin{}, err
+	}
+
+	for _, transactionPin := range data {
+		if transactionPin.CustomerId == customerId {
+			logger.Info("Transaction PIN found")
+			return transactionPin, nil
+		}
+	}
+
+	logger.Info("Transaction PIN not found")
+	return entity.TransactionPin{}, errors.New(constants.TransactionPinNotFoundError)
+}
+
+// Save stores the transaction PIN of a customer, replacing the one stored before
+func (t *transactionPinRepository) Save(transactionPin entity.TransactionPin) error {
+	logger := logrus.WithFields(logrus.Fields{
+		"customerId": transactionPin.CustomerId,
+		"failures":   transactionPin.Failures,
+	})
+
+	logger.Info("Saving transaction PIN")
+
+	data, err := t.JsonStorage.ReadFile(constants.TransactionPinJsonPath)
+	if err != nil {
+		logger.Error("Failed to read transaction PINs file", err)
+		return err
+	}
+
+	transactionPinFound := false
+	for i := range data {
+		if data[i].CustomerId == transactionPin.CustomerId {
+			data[i] = transactionPin
+			transactionPinFound = true
+			break
+		}
+	}
+	if !transactionPinFound {
+		data = append(data, transactionPin)
+	}
+
+	_, err = t.JsonStorage.WriteFile(data, constants.TransactionPinJsonPath)
+	if err != nil {
+		logger.Error("Failed to write updated transaction PINs file", err)
+		return err
+	}
+
+	logger.Info("Transaction PIN saved successfully")
+	return nil
+}
@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"PaymentAPI/entity"
+	"github.com/stretchr/testify/mock"
+)
+
+type TransactionPinRepositoryMock struct {
+	Mock mock.Mock
+}
+
+func (t *TransactionPinRepositoryMock) GetByCustomerId(customerId string) (entity.TransactionPin, error) {
+	args := t.Mock.Called(customerId)
+	return args.Get(0).(entity.TransactionPin), args.Error(1)
+}
+
+func (t *TransactionPinRepositoryMock) Save(transactionPin entity.TransactionPin) error {
+	args := t.Mock.Called(transactionPin)
+	return args.Error(0)
+}
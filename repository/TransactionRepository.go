@@ -4,16 +4,21 @@ import (
 	"PaymentAPI/constants"
 	"PaymentAPI/entity"
 	"PaymentAPI/storage"
+	"PaymentAPI/utils"
 	"github.com/sirupsen/logrus" // Importing logrus for structured logging
+	"sync"
 )
 
 type TransactionRepository interface {
 	GetAll() ([]entity.Transaction, error)
-	Create(entity.Transaction) error
+	Create(entity.Transaction) (entity.Transaction, error)
 }
 
 type transactionRepository struct {
 	JsonStorage storage.JsonFileHandler[entity.Transaction]
+
+	// createLock serializes Create, so two transactions are never linked to the same previous record
+	createLock sync.Mutex
 }
 
 // NewTransactionRepository creates a new instance of TransactionRepository
@@ -38,8 +43,8 @@ func (t *transactionRepository) GetAll() ([]entity.Transaction, error) {
 	return data, nil
 }
 
-// Create adds a new transaction to storage
-func (t *transactionRepository) Create(transaction entity.Transaction) error {
+// Create links a new transaction to the hash chain and adds it to storage
+func (t *transactionRepository) Create(transaction entity.Transaction) (entity.Transaction, error) {
 	logger := logrus.WithFields(logrus.Fields{
 		"transactionId": transaction.Id,
 		"fromWalletId":  transaction.FromWalletId,
@@ -49,11 +54,26 @@ func (t *transactionRepository) Create(transaction entity.Transaction) error {
 
 	logger.Info("Creating new transaction")
 
+	// Hold the lock from reading the chain to writing the new record
+	t.createLock.Lock()
+	defer t.createLock.Unlock()
+
 	// Read the current transactions from storage
 	data, err := t.JsonStorage.ReadFile(constants.TransactionJsonPath)
 	if err != nil {
 		logger.Error("Failed to read transactions file", err)
-		return err
+		return entity.Transaction{}, err
+	}
+
+	// Link the new transaction to the last record of the chain
+	transaction.PrevHash = ""
+	if len(data) > 0 {
+		transaction.PrevHash = data[len(data)-1].Hash
+	}
+	transaction.Hash, err = utils.ComputeTransactionHash(transaction)
+	if err != nil {
+		logger.Error("Failed to compute transaction hash", err)
+		return entity.Transaction{}, err
 	}
 
 	// Append the new transaction to the data
@@ -63,9 +83,9 @@ func (t *transactionRepository) Create(transaction entity.Transaction) error {
 	_, err = t.JsonStorage.WriteFile(data, constants.TransactionJsonPath)
 	if err != nil {
 		logger.Error("Failed to write updated transactions file", err)
-		return err
+		return entity.Transaction{}, err
 	}
 
 	logger.Info("New transaction created successfully")
-	return nil
+	return transaction, nil
 }
@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"PaymentAPI/entity"
+	"fmt"
+	"github.com/stretchr/testify/mock"
+)
+
+type TransactionRepositoryMock struct {
+	Mock mock.Mock
+}
+
+func (t *TransactionRepositoryMock) GetAll() ([]entity.Transaction, error) {
+	args := t.Mock.Called()
+	transactions, ok := args.Get(0).([]entity.Transaction)
+	if !ok {
+		return nil, fmt.Errorf("invalid type for transaction")
+	}
+	return transactions, args.Error(1)
+}
+
+func (t *TransactionRepositoryMock) Create(transaction entity.Transaction) (entity.Transaction, error) {
+	args := t.Mock.Called(transaction)
+	return args.Get(0).(entity.Transaction), args.Error(1)
+}
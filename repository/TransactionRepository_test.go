@@ -4,6 +4,7 @@ import (
 	"PaymentAPI/constants"
 	"PaymentAPI/entity"
 	"PaymentAPI/storage"
+	"PaymentAPI/utils"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"testing"
@@ -42,9 +43,44 @@ func TestCreateTransaction(t *testing.T) {
 		constants.TransactionJsonPath,
 	).Return(constants.JsonWriteSuccess, nil)
 
-	err := transactionRepository.Create(newTransaction)
+	transaction, err := transactionRepository.Create(newTransaction)
 
 	assert.Nil(t, err)
+	assert.Empty(t, transaction.PrevHash)
+	assert.NotEmpty(t, transaction.Hash)
+}
+
+func TestCreateTransactionLinksHashChain(t *testing.T) {
+	mockFileHandler := new(storage.TransactionJsonFileHandlerMock[entity.Transaction])
+	transactionRepository := NewTransactionRepository(mockFileHandler)
+
+	previousTransaction := entity.Transaction{
+		Id:   "transaction-1",
+		Hash: "previous-hash",
+	}
+
+	newTransaction := entity.Transaction{
+		Id:           "transaction-2",
+		FromWalletId: "wallet-1",
+		ToWalletId:   "wallet-2",
+		Amount:       5000,
+		CreatedAt:    time.Now().Format(time.RFC3339),
+		Message:      "transaction",
+	}
+
+	mockFileHandler.Mock.On("ReadFile", constants.TransactionJsonPath).
+		Return([]entity.Transaction{previousTransaction}, nil)
+
+	mockFileHandler.Mock.On("WriteFile", mock.Anything, constants.TransactionJsonPath).
+		Return(constants.JsonWriteSuccess, nil)
+
+	transaction, err := transactionRepository.Create(newTransaction)
+	assert.Nil(t, err)
+	assert.Equal(t, previousTransaction.Hash, transaction.PrevHash)
+
+	expectedHash, err := utils.ComputeTransactionHash(transaction)
+	assert.Nil(t, err)
+	assert.Equal(t, expectedHash, transaction.Hash)
 }
 
 func TestGetAllTransactions(t *testing.T) {
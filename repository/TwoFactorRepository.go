@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"PaymentAPI/constants"
+	"PaymentAPI/entity"
+	"PaymentAPI/storage"
+	"errors"
+	"github.com/sirupsen/logrus"
+)
+
+type TwoFactorRepository interface {
+	GetByCustomerId(customerId string) (entity.TwoFactor, error)
+	Save(twoFactor entity.TwoFactor) error
+	Delete(customerId string) error
+}
+
+type twoFactorRepository struct {
+	JsonStorage storage.JsonFileHandler[entity.TwoFactor]
+}
+
+// NewTwoFactorRepository creates a new instance of TwoFactorRepository
+func NewTwoFactorRepository(jsonStorage storage.JsonFileHandler[entity.TwoFactor]) TwoFactorRepository {
+	return &twoFactorRepository{JsonStorage: jsonStorage}
+}
+
+// GetByCustomerId retrieves the two-factor setup of a customer
+func (t *twoFactorRepository) GetByCustomerId(customerId string) (entity.TwoFactor, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"customerId": customerId,
+	})
+
+	logger.Info("Retrieving two-factor setup")
+
+	data, err := t.JsonStorage.ReadFile(constants.TwoFactorJsonPath)
+	if err != nil {
+		logger.Error("Failed to read two-factor file", err)
+		return entity.TwoFactor{}, err
+	}
+
+	for _, twoFactor := range data {
+		if twoFactor.CustomerId == customerId {
+			logger.Info("Two-factor setup found")
+			return twoFactor, nil
+		}
+	}
+
+	logger.Info("Two-factor setup not found")
+	return entity.TwoFactor{}, errors.New(constants.TwoFactorNotFoundError)
+}
+
+// Save stores the two-factor setup of a customer, replacing the one stored before
+func (t *twoFactorRepository) Save(twoFactor entity.TwoFactor) error {
+	logger := logrus.WithFields(logrus.Fields{
+		"customerId": twoFactor.CustomerId,
+		"enabled":    twoFactor.Enabled,
+	})
+
+	logger.Info("Saving two-factor setup")
+
+	data, err := t.JsonStorage.ReadFile(constants.TwoFactorJsonPath)
+	if err != nil {
+		logger.Error("Failed to read two-factor file", err)
+		return err
+	}
+
+	twoFactorFound := false
+	for i := range data {
+		if data[i].CustomerId == twoFactor.CustomerId {
+			data[i] = twoFactor
+			twoFactorFound = true
+			break
+		}
+	}
+	if !twoFactorFound {
+		data = append(data, twoFactor)
+	}
+
+	_, err = t.JsonStorage.WriteFile(data, constants.TwoFactorJsonPath)
+	if err != nil {
+		logger.Error("Failed to write updated two-factor file", err)
+		return err
+	}
+
+	logger.Info("Two-factor setup saved successfully")
+	return nil
+}
+
+// Delete removes the two-factor setup of a customer
+func (t *twoFactorRepository) Delete(customerId string) error {
+	logger := logrus.WithFields(logrus.Fields{
+		"customerId": customerId,
+	})
+
+	logger.Info("Deleting two-factor setup")
+
+	data, err := t.JsonStorage.ReadFile(constants.TwoFactorJsonPath)
+	if err != nil {
+		logger.Error("Failed to read two-factor file", err)
+		return err
+	}
+
+	indexToDelete := -1
+	for i, twoFactor := range data {
+		if twoFactor.CustomerId == customerId {
+			indexToDelete = i
+			break
+		}
+	}
+
+	if indexToDelete == -1 {
+		logger.Warn("Two-factor setup not found")
+		return errors.New(constants.TwoFactorNotFoundError)
+	}
+
+	data = append(data[:indexToDelete], data[indexToDelete+1:]...)
+
+	_, err = t.JsonStorage.WriteFile(data, constants.TwoFactorJsonPath)
+	if err != nil {
+		logger.Error("Failed to write updated two-factor file", err)
+		return err
+	}
+
+	logger.Info("Two-factor setup deleted successfully")
+	return nil
+}
@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"PaymentAPI/entity"
+	"github.com/stretchr/testify/mock"
+)
+
+type TwoFactorRepositoryMock struct {
+	Mock mock.Mock
+}
+
+func (t *TwoFactorRepositoryMock) GetByCustomerId(customerId string) (entity.TwoFactor, error) {
+	args := t.Mock.Called(customerId)
+	return args.Get(0).(entity.TwoFactor), args.Error(1)
+}
+
+func (t *TwoFactorRepositoryMock) Save(twoFactor entity.TwoFactor) error {
+	args := t.Mock.Called(twoFactor)
+	return args.Error(0)
+}
+
+func (t *TwoFactorRepositoryMock) Delete(customerId string) error {
+	args := t.Mock.Called(customerId)
+	return args.Error(0)
+}
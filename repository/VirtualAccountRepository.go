@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"PaymentAPI/constants"
+	"PaymentAPI/entity"
+	"PaymentAPI/storage"
+	"errors"
+	"github.com/sirupsen/logrus"
+)
+
+type VirtualAccountRepository interface {
+	GetAll() ([]entity.VirtualAccount, error)
+	GetByNumber(number string) (entity.VirtualAccount, error)
+	GetByWalletId(walletId string) (entity.VirtualAccount, error)
+	Create(virtualAccount entity.VirtualAccount) error
+}
+
+type virtualAccountRepository struct {
+	JsonStorage storage.JsonFileHandler[entity.VirtualAccount]
+}
+
+// NewVirtualAccountRepository creates a new instance of VirtualAccountRepository
+func NewVirtualAccountRepository(jsonStorage storage.JsonFileHandler[entity.VirtualAccount]) VirtualAccountRepository {
+	return &virtualAccountRepository{JsonStorage: jsonStorage}
+}
+
+// GetAll retrieves all virtual accounts from storage
+func (v *virtualAccountRepository) GetAll() ([]entity.VirtualAccount, error) {
+	logger := logrus.WithFields(logrus.Fields{})
+
+	logger.Info("Retrieving all virtual accounts")
+
+	data, err := v.JsonStorage.ReadFile(constants.VirtualAccountJsonPath)
+	if err != nil {
+		logger.Error("Failed to read virtual accounts file", err)
+		return nil, err
+	}
+
+	logger.Info("All virtual accounts retrieved successfully")
+	return data, nil
+}
+
+// GetByNumber retrieves a virtual account by its number
+func (v *virtualAccountRepository) GetByNumber(number string) (entity.VirtualAccount, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"virtualAccountNumber": number,
+	})
+
+	logger.Info("Retrieving virtual account")
+
+	data, err := v.GetAll()
+	if err != nil {
+		return entity.VirtualAccount{}, err
+	}
+
+	for _, virtualAccount := range data {
+		if virtualAccount.Number == number {
+			logger.Info("Virtual account found")
+			return virtualAccount, nil
+		}
+	}
+
+	logger.Warn("Virtual account not found")
+	return entity.VirtualAccount{}, errors.New(constants.VirtualAccountNotFoundError)
+}
+
+// GetByWalletId retrieves the virtual account of a wallet
+func (v *virtualAccountRepository) GetByWalletId(walletId string) (entity.VirtualAccount, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"walletId": walletId,
+	})
+
+	logger.Info("Retrieving virtual account of wallet")
+
+	data, err := v.GetAll()
+	if err != nil {
+		return entity.VirtualAccount{}, err
+	}
+
+	for _, virtualAccount := range data {
+		if virtualAccount.WalletId == walletId {
+			logger.Info("Virtual account found")
+			return virtualAccount, nil
+		}
+	}
+
+	logger.Warn("Virtual account not found")
+	return entity.VirtualAccount{}, errors.New(constants.VirtualAccountNotFoundError)
+}
+
+// Create adds a new virtual account to storage
+func (v *virtualAccountRepository) Create(virtualAccount entity.VirtualAccount) error {
+	logger := logrus.WithFields(logrus.Fields{
+		"virtualAccountNumber": virtualAccount.Number,
+		"walletId":             virtualAccount.WalletId,
+	})
+
+	logger.Info("Creating new virtual account")
+
+	data, err := v.JsonStorage.ReadFile(constants.VirtualAccountJsonPath)
+	if err != nil {
+		logger.Error("Failed to read virtual accounts file", err)
+		return err
+	}
+
+	data = append(data, virtualAccount)
+
+	_, err = v.JsonStorage.WriteFile(data, constants.VirtualAccountJsonPath)
+	if err != nil {
+		logger.Error("Failed to write updated virtual accounts file", err)
+		return err
+	}
+
+	logger.Info("New virtual account created successfully")
+	return nil
+}
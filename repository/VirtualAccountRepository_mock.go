@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"PaymentAPI/entity"
+	"fmt"
+	"github.com/stretchr/testify/mock"
+)
+
+type VirtualAccountRepositoryMock struct {
+	Mock mock.Mock
+}
+
+func (v *VirtualAccountRepositoryMock) GetAll() ([]entity.VirtualAccount, error) {
+	args := v.Mock.Called()
+	virtualAccounts, ok := args.Get(0).([]entity.VirtualAccount)
+	if !ok {
+		return nil, fmt.Errorf("invalid type for virtual account")
+	}
+	return virtualAccounts, args.Error(1)
+}
+
+func (v *VirtualAccountRepositoryMock) GetByNumber(number string) (entity.VirtualAccount, error) {
+	args := v.Mock.Called(number)
+	return args.Get(0).(entity.VirtualAccount), args.Error(1)
+}
+
+func (v *VirtualAccountRepositoryMock) GetByWalletId(walletId string) (entity.VirtualAccount, error) {
+	args := v.Mock.Called(walletId)
+	return args.Get(0).(entity.VirtualAccount), args.Error(1)
+}
+
+func (v *VirtualAccountRepositoryMock) Create(virtualAccount entity.VirtualAccount) error {
+	args := v.Mock.Called(virtualAccount)
+	return args.Error(0)
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"PaymentAPI/constants"
 	"PaymentAPI/entity"
+	"PaymentAPI/enums"
 	"PaymentAPI/storage"
 	"errors"
 	"github.com/google/uuid"
@@ -15,6 +16,7 @@ type WalletRepository interface {
 	GetById(id string) (entity.Wallet, error)
 	Create(customerId string) error
 	Update(id string, balance float64) error
+	UpdateStatus(id string, status enums.WalletStatus) error
 }
 
 type walletRepository struct {
@@ -34,6 +36,13 @@ func (w *walletRepository) GetAll() ([]entity.Wallet, error) {
 		logrus.Errorf("Error reading wallet data: %v", err)
 		return nil, err
 	}
+
+	// Wallets stored before statuses existed are active
+	for i := range data {
+		if data[i].Status == "" {
+			data[i].Status = enums.WALLET_ACTIVE
+		}
+	}
 	return data, nil
 }
 
@@ -91,6 +100,7 @@ func (w *walletRepository) Create(customerId string) error {
 		Id:         uuid.New().String(),
 		CustomerId: customerId,
 		Balance:    0,
+		Status:     enums.WALLET_ACTIVE,
 	}
 
 	data, err := w.JsonStorage.ReadFile(constants.WalletJsonPath)
@@ -143,3 +153,36 @@ func (w *walletRepository) Update(id string, balance float64) error {
 
 	return nil
 }
+
+// UpdateStatus changes the lifecycle status of an existing wallet.
+func (w *walletRepository) UpdateStatus(id string, status enums.WalletStatus) error {
+	logrus.Infof("Updating wallet ID: %s with status: %s", id, status)
+
+	data, err := w.GetAll()
+	if err != nil {
+		return err
+	}
+
+	walletFound := false
+	for i := range data {
+		if data[i].Id == id {
+			data[i].Status = status
+			walletFound = true
+			break
+		}
+	}
+
+	if !walletFound {
+		logrus.Warnf("Wallet not found for ID: %s", id)
+		return errors.New(constants.WalletNotFoundError)
+	}
+
+	_, err = w.JsonStorage.WriteFile(data, constants.WalletJsonPath)
+	if err != nil {
+		logrus.Errorf("Error writing updated wallet to storage: %v", err)
+		return err
+	}
+
+	logrus.Infof("Wallet status updated successfully. New status: %s", status)
+	return nil
+}
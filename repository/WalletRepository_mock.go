@@ -2,6 +2,7 @@ package repository
 
 import (
 	"PaymentAPI/entity"
+	"PaymentAPI/enums"
 	"fmt"
 	"github.com/stretchr/testify/mock"
 )
@@ -38,3 +39,8 @@ func (w *WalletRepositoryMock) Update(customerId string, balance float64) error
 	args := w.Mock.Called(customerId, balance)
 	return args.Error(0)
 }
+
+func (w *WalletRepositoryMock) UpdateStatus(id string, status enums.WalletStatus) error {
+	args := w.Mock.Called(id, status)
+	return args.Error(0)
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"PaymentAPI/constants"
 	"PaymentAPI/entity"
+	"PaymentAPI/enums"
 	"PaymentAPI/storage"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -157,3 +158,40 @@ func TestUpdateWallet(t *testing.T) {
 		assert.Equal(t, constants.WalletNotFoundError, err.Error())
 	})
 }
+
+func TestUpdateWalletStatus(t *testing.T) {
+	t.Run("ShouldUpdateStatus", func(t *testing.T) {
+		mockJsonFileHandler := new(storage.WalletJsonFileHandlerMock[entity.Wallet])
+		walletRepository := NewWalletRepository(mockJsonFileHandler)
+
+		walletResponse := []entity.Wallet{
+			{
+				Id:         "wallet-1",
+				CustomerId: "customer-1",
+				Balance:    0,
+			},
+		}
+
+		mockJsonFileHandler.Mock.On("ReadFile", constants.WalletJsonPath).
+			Return(walletResponse, nil)
+
+		mockJsonFileHandler.Mock.On("WriteFile", mock.MatchedBy(func(wallets []entity.Wallet) bool {
+			return len(wallets) == 1 && wallets[0].Status == enums.WALLET_FROZEN
+		}), constants.WalletJsonPath).
+			Return(mock.Anything, nil)
+
+		err := walletRepository.UpdateStatus("wallet-1", enums.WALLET_FROZEN)
+		assert.Nil(t, err)
+	})
+
+	t.Run("ShouldReturnError", func(t *testing.T) {
+		mockJsonFileHandler := new(storage.WalletJsonFileHandlerMock[entity.Wallet])
+		walletRepository := NewWalletRepository(mockJsonFileHandler)
+
+		mockJsonFileHandler.Mock.On("ReadFile", constants.WalletJsonPath).
+			Return([]entity.Wallet{}, nil)
+
+		err := walletRepository.UpdateStatus("wallet-1", enums.WALLET_FROZEN)
+		assert.Equal(t, constants.WalletNotFoundError, err.Error())
+	})
+}
@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"PaymentAPI/constants"
+	"PaymentAPI/entity"
+	"PaymentAPI/storage"
+	"errors"
+	"github.com/sirupsen/logrus"
+)
+
+type WebhookDeliveryRepository interface {
+	GetAll() ([]entity.WebhookDelivery, error)
+	GetById(id string) (entity.WebhookDelivery, error)
+	Create(webhookDelivery entity.WebhookDelivery) error
+	Update(webhookDelivery entity.WebhookDelivery) error
+}
+
+type webhookDeliveryRepository struct {
+	JsonStorage storage.JsonFileHandler[entity.WebhookDelivery]
+}
+
+// NewWebhookDeliveryRepository creates a new instance of WebhookDeliveryRepository
+func NewWebhookDeliveryRepository(jsonStorage storage.JsonFileHandler[entity.WebhookDelivery]) WebhookDeliveryRepository {
+	return &webhookDeliveryRepository{JsonStorage: jsonStorage}
+}
+
+// GetAll retrieves all webhook deliveries from storage
+func (w *webhookDeliveryRepository) GetAll() ([]entity.WebhookDelivery, error) {
+	logger := logrus.WithFields(logrus.Fields{})
+
+	logger.Info("Retrieving all webhook deliveries")
+
+	data, err := w.JsonStorage.ReadFile(constants.WebhookDeliveryJsonPath)
+	if err != nil {
+		logger.Error("Failed to read webhook deliveries file", err)
+		return nil, err
+	}
+
+	logger.Info("All webhook deliveries retrieved successfully")
+	return data, nil
+}
+
+// GetById retrieves a webhook delivery by its ID
+func (w *webhookDeliveryRepository) GetById(id string) (entity.WebhookDelivery, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"webhookDeliveryId": id,
+	})
+
+	logger.Info("Retrieving webhook delivery")
+
+	data, err := w.GetAll()
+	if err != nil {
+		return entity.WebhookDelivery{}, err
+	}
+
+	for _, webhookDelivery := range data {
+		if webhookDelivery.Id == id {
+			logger.Info("Webhook delivery found")
+			return webhookDelivery, nil
+		}
+	}
+
+	logger.Warn("Webhook delivery not found")
+	return entity.WebhookDelivery{}, errors.New(constants.WebhookDeliveryNotFoundError)
+}
+
+// Create adds a new webhook delivery to storage
+func (w *webhookDeliveryRepository) Create(webhookDelivery entity.WebhookDelivery) error {
+	logger := logrus.WithFields(logrus.Fields{
+		"webhookDeliveryId": webhookDelivery.Id,
+		"endpointId":        webhookDelivery.EndpointId,
+		"eventType":         webhookDelivery.EventType,
+	})
+
+	logger.Info("Creating new webhook delivery")
+
+	data, err := w.JsonStorage.ReadFile(constants.WebhookDeliveryJsonPath)
+	if err != nil {
+		logger.Error("Failed to read webhook deliveries file", err)
+		return err
+	}
+
+	data = append(data, webhookDelivery)
+
+	_, err = w.JsonStorage.WriteFile(data, constants.WebhookDeliveryJsonPath)
+	if err != nil {
+		logger.Error("Failed to write updated webhook deliveries file", err)
+		return err
+	}
+
+	logger.Info("New webhook delivery created successfully")
+	return nil
+}
+
+// Update replaces a stored webhook delivery with the given webhook delivery
+func (w *webhookDeliveryRepository) Update(webhookDelivery entity.WebhookDelivery) error {
+	logger := logrus.WithFields(logrus.Fields{
+		"webhookDeliveryId": webhookDelivery.Id,
+		"status":            webhookDelivery.Status,
+	})
+
+	logger.Info("Updating webhook delivery")
+
+	data, err := w.JsonStorage.ReadFile(constants.WebhookDeliveryJsonPath)
+	if err != nil {
+		logger.Error("Failed to read webhook deliveries file", err)
+		return err
+	}
+
+	webhookDeliveryFound := false
+	for i := range data {
+		if data[i].Id == webhookDelivery.Id {
+			data[i] = webhookDelivery
+			webhookDeliveryFound = true
+			break
+		}
+	}
+
+	if !webhookDeliveryFound {
+		logger.Warn("Webhook delivery not found")
+		return errors.New(constants.WebhookDeliveryNotFoundError)
+	}
+
+	_, err = w.JsonStorage.WriteFile(data, constants.WebhookDeliveryJsonPath)
+	if err != nil {
+		logger.Error("Failed to write updated webhook deliveries file", err)
+		return err
+	}
+
+	logger.Info("Webhook delivery updated successfully")
+	return nil
+}
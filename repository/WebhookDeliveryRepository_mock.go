@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"PaymentAPI/entity"
+	"fmt"
+	"github.com/stretchr/testify/mock"
+)
+
+type WebhookDeliveryRepositoryMock struct {
+	Mock mock.Mock
+}
+
+func (w *WebhookDeliveryRepositoryMock) GetAll() ([]entity.WebhookDelivery, error) {
+	args := w.Mock.Called()
+	webhookDeliverys, ok := args.Get(0).([]entity.WebhookDelivery)
+	if !ok {
+		return nil, fmt.Errorf("invalid type for webhook delivery")
+	}
+	return webhookDeliverys, args.Error(1)
+}
+
+func (w *WebhookDeliveryRepositoryMock) GetById(id string) (entity.WebhookDelivery, error) {
+	args := w.Mock.Called(id)
+	return args.Get(0).(entity.WebhookDelivery), args.Error(1)
+}
+
+func (w *WebhookDeliveryRepositoryMock) Create(webhookDelivery entity.WebhookDelivery) error {
+	args := w.Mock.Called(webhookDelivery)
+	return args.Error(0)
+}
+
+func (w *WebhookDeliveryRepositoryMock) Update(webhookDelivery entity.WebhookDelivery) error {
+	args := w.Mock.Called(webhookDelivery)
+	return args.Error(0)
+}
@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"PaymentAPI/constants"
+	"PaymentAPI/entity"
+	"PaymentAPI/storage"
+	"errors"
+	"github.com/sirupsen/logrus"
+)
+
+type WebhookEndpointRepository interface {
+	GetAll() ([]entity.WebhookEndpoint, error)
+	GetById(id string) (entity.WebhookEndpoint, error)
+	Create(webhookEndpoint entity.WebhookEndpoint) error
+	Delete(id string) error
+}
+
+type webhookEndpointRepository struct {
+	JsonStorage storage.JsonFileHandler[entity.WebhookEndpoint]
+}
+
+// NewWebhookEndpointRepository creates a new instance of WebhookEndpointRepository
+func NewWebhookEndpointRepository(jsonStorage storage.JsonFileHandler[entity.WebhookEndpoint]) WebhookEndpointRepository {
+	return &webhookEndpointRepository{JsonStorage: jsonStorage}
+}
+
+// GetAll retrieves all webhook endpoints from storage
+func (w *webhookEndpointRepository) GetAll() ([]entity.WebhookEndpoint, error) {
+	logger := logrus.WithFields(logrus.Fields{})
+
+	logger.Info("Retrieving all webhook endpoints")
+
+	data, err := w.JsonStorage.ReadFile(constants.WebhookEndpointJsonPath)
+	if err != nil {
+		logger.Error("Failed to read webhook endpoints file", err)
+		return nil, err
+	}
+
+	logger.Info("All webhook endpoints retrieved successfully")
+	return data, nil
+}
+
+// GetById retrieves a webhook endpoint by its ID
+func (w *webhookEndpointRepository) GetById(id string) (entity.WebhookEndpoint, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"webhookEndpointId": id,
+	})
+
+	logger.Info("Retrieving webhook endpoint")
+
+	data, err := w.GetAll()
+	if err != nil {
+		return entity.WebhookEndpoint{}, err
+	}
+
+	for _, webhookEndpoint := range data {
+		if webhookEndpoint.Id == id {
+			logger.Info("Webhook endpoint found")
+			return webhookEndpoint, nil
+		}
+	}
+
+	logger.Warn("Webhook endpoint not found")
+	return entity.WebhookEndpoint{}, errors.New(constants.WebhookEndpointNotFoundError)
+}
+
+// Create adds a new webhook endpoint to storage
+func (w *webhookEndpointRepository) Create(webhookEndpoint entity.WebhookEndpoint) error {
+	logger := logrus.WithFields(logrus.Fields{
+		"webhookEndpointId": webhookEndpoint.Id,
+		"customerId":        webhookEndpoint.CustomerId,
+	})
+
+	logger.Info("Creating new webhook endpoint")
+
+	data, err := w.JsonStorage.ReadFile(constants.WebhookEndpointJsonPath)
+	if err != nil {
+		logger.Error("Failed to read webhook endpoints file", err)
+		return err
+	}
+
+	data = append(data, webhookEndpoint)
+
+	_, err = w.JsonStorage.WriteFile(data, constants.WebhookEndpointJsonPath)
+	if err != nil {
+		logger.Error("Failed to write updated webhook endpoints file", err)
+		return err
+	}
+
+	logger.Info("New webhook endpoint created successfully")
+	return nil
+}
+
+// Delete removes a webhook endpoint from storage
+func (w *webhookEndpointRepository) Delete(id string) error {
+	logger := logrus.WithFields(logrus.Fields{
+		"webhookEndpointId": id,
+	})
+
+	logger.Info("Deleting webhook endpoint")
+
+	data, err := w.JsonStorage.ReadFile(constants.WebhookEndpointJsonPath)
+	if err != nil {
+		logger.Error("Failed to read webhook endpoints file", err)
+		return err
+	}
+
+	indexToDelete := -1
+	for i, webhookEndpoint := range data {
+		if webhookEndpoint.Id == id {
+			indexToDelete = i
+			break
+		}
+	}
+
+	if indexToDelete == -1 {
+		logger.Warn("Webhook endpoint not found")
+		return errors.New(constants.WebhookEndpointNotFoundError)
+	}
+
+	data = append(data[:indexToDelete], data[indexToDelete+1:]...)
+
+	_, err = w.JsonStorage.WriteFile(data, constants.WebhookEndpointJsonPath)
+	if err != nil {
+		logger.Error("Failed to write updated webhook endpoints file", err)
+		return err
+	}
+
+	logger.Info("Webhook endpoint deleted successfully")
+	return nil
+}
@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"PaymentAPI/entity"
+	"fmt"
+	"github.com/stretchr/testify/mock"
+)
+
+type WebhookEndpointRepositoryMock struct {
+	Mock mock.Mock
+}
+
+func (w *WebhookEndpointRepositoryMock) GetAll() ([]entity.WebhookEndpoint, error) {
+	args := w.Mock.Called()
+	webhookEndpoints, ok := args.Get(0).([]entity.WebhookEndpoint)
+	if !ok {
+		return nil, fmt.Errorf("invalid type for webhook endpoint")
+	}
+	return webhookEndpoints, args.Error(1)
+}
+
+func (w *WebhookEndpointRepositoryMock) GetById(id string) (entity.WebhookEndpoint, error) {
+	args := w.Mock.Called(id)
+	return args.Get(0).(entity.WebhookEndpoint), args.Error(1)
+}
+
+func (w *WebhookEndpointRepositoryMock) Create(webhookEndpoint entity.WebhookEndpoint) error {
+	args := w.Mock.Called(webhookEndpoint)
+	return args.Error(0)
+}
+
+func (w *WebhookEndpointRepositoryMock) Delete(id string) error {
+	args := w.Mock.Called(id)
+	return args.Error(0)
+}
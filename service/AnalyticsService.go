@@ -0,0 +1,32 @@
+package service
+
+import (
+	"PaymentAPI/entity"
+	"PaymentAPI/logger"
+	"github.com/sirupsen/logrus"
+)
+
+type AnalyticsService interface {
+	HandleEvent(outboxEvent entity.OutboxEvent) error
+}
+
+type analyticsService struct{}
+
+// NewAnalyticsService creates a new instance of AnalyticsService
+func NewAnalyticsService() AnalyticsService {
+	return &analyticsService{}
+}
+
+// HandleEvent writes the event to the JSON log file, where it can be collected for reporting. The event ID is
+// included so a collector can drop the copies written when an event is delivered more than once.
+func (a *analyticsService) HandleEvent(outboxEvent entity.OutboxEvent) error {
+	logger.LogInfo("Domain event", logrus.Fields{
+		"analytics": true,
+		"eventId":   outboxEvent.Id,
+		"eventType": outboxEvent.Type,
+		"walletIds": outboxEvent.WalletIds,
+		"createdAt": outboxEvent.CreatedAt,
+		"data":      outboxEvent.Payload,
+	})
+	return nil
+}
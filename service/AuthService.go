@@ -1,46 +1,86 @@
 package service
 
 import (
+	"PaymentAPI/config"
 	"PaymentAPI/constants"
 	req "PaymentAPI/dto/request"
 	res "PaymentAPI/dto/response"
+	"PaymentAPI/entity"
 	"PaymentAPI/logger" // Import the logger package
+	"PaymentAPI/repository"
 	"PaymentAPI/utils"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"sync"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 )
 
 type AuthService interface {
-	Login(request req.CustomerRequest) (res.AuthResponse, error)
+	Login(request req.CustomerRequest, client req.ClientInfo) (res.AuthResponse, error)
+	LoginWithTwoFactor(request req.LoginTwoFactorRequest, client req.ClientInfo) (res.AuthResponse, error)
 	Logout(accessToken string, refreshToken string) error
-	GetNewAccessToken(refreshToken string) (res.AuthResponse, error)
+	LogoutAll(customerId string) error
+	GetNewAccessToken(refreshToken string, client req.ClientInfo) (res.AuthResponse, error)
+	ChangePassword(customerId string, request req.ChangePasswordRequest, client req.ClientInfo) error
+	RequestPasswordReset(request req.PasswordResetRequest) error
+	ResetPassword(request req.ConfirmPasswordResetRequest) error
 }
 
 type authService struct {
-	customerService     CustomerService
-	refreshTokenService RefreshTokenService
-	blacklistService    BlacklistService
+	customerService              CustomerService
+	sessionService               SessionService
+	blacklistService             BlacklistService
+	passwordResetTokenRepository repository.PasswordResetTokenRepository
+	notifier                     Notifier
+	loginAttemptService          LoginAttemptService
+	twoFactorService             TwoFactorService
+	loginChallengeRepository     repository.LoginChallengeRepository
+	// resetLock keeps a password reset token from being checked by one reset while another is using it up
+	resetLock sync.Mutex
 }
 
+// loginChallengeMaxAttempts is how many wrong codes a login challenge takes before it is thrown away
+const loginChallengeMaxAttempts = 5
+
 // Constructor for AuthService
-func NewAuthService(customerService CustomerService, refreshTokenService RefreshTokenService, blacklistService BlacklistService) AuthService {
+func NewAuthService(customerService CustomerService, sessionService SessionService, blacklistService BlacklistService, passwordResetTokenRepository repository.PasswordResetTokenRepository, notifier Notifier, loginAttemptService LoginAttemptService, twoFactorService TwoFactorService, loginChallengeRepository repository.LoginChallengeRepository) AuthService {
 	return &authService{
-		customerService:     customerService,
-		refreshTokenService: refreshTokenService,
-		blacklistService:    blacklistService,
+		customerService:              customerService,
+		sessionService:               sessionService,
+		blacklistService:             blacklistService,
+		passwordResetTokenRepository: passwordResetTokenRepository,
+		notifier:                     notifier,
+		loginAttemptService:          loginAttemptService,
+		twoFactorService:             twoFactorService,
+		loginChallengeRepository:     loginChallengeRepository,
 	}
 }
 
-// Login handles user authentication and token generation
-func (a *authService) Login(request req.CustomerRequest) (res.AuthResponse, error) {
+// Login handles user authentication and token generation. An unknown username fails the same way as a wrong
+// password, and failures from the username or the client IP are throttled and eventually locked out. A customer with
+// two-factor authentication enabled gets a login challenge instead of tokens, see LoginWithTwoFactor. Every login
+// starts a session of its own, next to the sessions on other devices.
+func (a *authService) Login(request req.CustomerRequest, client req.ClientInfo) (res.AuthResponse, error) {
+	clientIp := client.Ip
 	logger.LogInfo("Attempting to log in", logrus.Fields{
 		"username": request.Username,
+		"clientIp": clientIp,
 	})
 
+	// Refuse the attempt without checking the password while the username or IP is throttled or locked out
+	if err := a.loginAttemptService.CheckAllowed(request.Username, clientIp); err != nil {
+		return res.AuthResponse{}, err
+	}
+
 	// Fetch customer details by username
 	customer, err := a.customerService.GetCustomerByUsernameAuth(request.Username)
-	if err != nil {
+	if err != nil && err.Error() != constants.CustomerNotFound {
 		logger.LogError("Failed to fetch customer by username", logrus.Fields{
 			"username": request.Username,
 			"error":    err.Error(),
@@ -48,91 +88,212 @@ func (a *authService) Login(request req.CustomerRequest) (res.AuthResponse, erro
 		return res.AuthResponse{}, err
 	}
 
-	// Compare provided password with stored hashed password
-	if !utils.BCryptCompare(request.Password, []byte(customer.Password)) {
+	// Compare provided password with stored hashed password. An unknown username is compared with a dummy hash, so
+	// it takes as long as a wrong password.
+	hashedPassword := customer.Password
+	if err != nil {
+		hashedPassword = unknownCustomerPasswordHash()
+	}
+	if !utils.VerifyPassword(request.Password, hashedPassword) || err != nil {
 		logger.LogError("Unauthorized login attempt", logrus.Fields{
 			"username": request.Username,
+			"clientIp": clientIp,
 		})
+		if err := a.loginAttemptService.RecordFailure(request.Username, clientIp); err != nil {
+			return res.AuthResponse{}, err
+		}
 		return res.AuthResponse{}, errors.New(constants.LoginUnauthorizedError)
 	}
 
-	// Generate access token
-	accessToken, err := utils.GenerateAccessToken(customer)
+	if err := a.loginAttemptService.RecordSuccess(request.Username); err != nil {
+		return res.AuthResponse{}, err
+	}
+
+	// Upgrade a hash made with another algorithm or weaker settings now that the password is known. Logging in does
+	// not depend on it, the old hash still works and is upgraded on a later login.
+	if utils.PasswordNeedsRehash(customer.Password) {
+		if err := a.customerService.UpdatePassword(customer.Id, request.Password); err != nil {
+			logger.LogWarning("Failed to upgrade password hash", logrus.Fields{
+				"customerId": customer.Id,
+				"error":      err.Error(),
+			})
+		} else {
+			logger.LogInfo("Upgraded password hash", logrus.Fields{
+				"customerId": customer.Id,
+			})
+		}
+	}
+
+	// The password alone is not enough when two-factor authentication is enabled
+	twoFactorEnabled, err := a.twoFactorService.IsEnabled(customer.Id)
 	if err != nil {
-		logger.LogError("Failed to generate access token", logrus.Fields{
-			"username": request.Username,
-			"error":    err.Error(),
-		})
 		return res.AuthResponse{}, err
 	}
+	if twoFactorEnabled {
+		return a.createLoginChallenge(customer, request.DeviceName)
+	}
 
-	// Generate refresh token
-	refreshToken, err := a.refreshTokenService.GenerateRefreshToken(customer.Id)
+	return a.issueTokens(customer, client)
+}
+
+// LoginWithTwoFactor exchanges the challenge token from the password step and a code from the authenticator, or a
+// recovery code, for access and refresh tokens. Wrong codes count as failed logins, and a challenge is thrown away
+// after too many of them.
+func (a *authService) LoginWithTwoFactor(request req.LoginTwoFactorRequest, client req.ClientInfo) (res.AuthResponse, error) {
+	clientIp := client.Ip
+	loginChallenge, err := a.loginChallengeRepository.GetByTokenHash(hashSecretToken(request.ChallengeToken))
 	if err != nil {
-		logger.LogError("Failed to generate refresh token", logrus.Fields{
-			"username": request.Username,
-			"error":    err.Error(),
-		})
+		if err.Error() == constants.LoginChallengeNotFoundError {
+			logger.LogWarning("Two-factor login attempted with an unknown challenge", logrus.Fields{
+				"clientIp": clientIp,
+			})
+			return res.AuthResponse{}, errors.New(constants.LoginChallengeInvalidError)
+		}
 		return res.AuthResponse{}, err
 	}
 
-	logger.LogInfo("Successfully logged in", logrus.Fields{
-		"username":   request.Username,
-		"customerId": customer.Id,
-	})
+	fields := logrus.Fields{
+		"customerId":       loginChallenge.CustomerId,
+		"loginChallengeId": loginChallenge.Id,
+		"clientIp":         clientIp,
+	}
+	logger.LogInfo("Attempting two-factor login", fields)
 
-	// Return authentication response with tokens
-	return res.AuthResponse{
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken.RefreshToken,
-		CustomerId:   customer.Id,
-	}, nil
+	expiresAt, err := time.Parse(time.RFC3339, loginChallenge.ExpiresAt)
+	if err != nil || !time.Now().Before(expiresAt) {
+		logger.LogWarning("Two-factor login attempted with an expired challenge", fields)
+		if err := a.loginChallengeRepository.Delete(loginChallenge.Id); err != nil {
+			return res.AuthResponse{}, err
+		}
+		return res.AuthResponse{}, errors.New(constants.LoginChallengeInvalidError)
+	}
+
+	customer, err := a.customerService.GetCustomerByIdAuth(loginChallenge.CustomerId)
+	if err != nil {
+		return res.AuthResponse{}, err
+	}
+
+	if err := a.loginAttemptService.CheckAllowed(customer.Username, clientIp); err != nil {
+		return res.AuthResponse{}, err
+	}
+
+	if err := a.twoFactorService.VerifyCode(customer.Id, request.Code); err != nil {
+		if err.Error() != constants.TwoFactorCodeInvalidError {
+			return res.AuthResponse{}, err
+		}
+		if err := a.loginAttemptService.RecordFailure(customer.Username, clientIp); err != nil {
+			return res.AuthResponse{}, err
+		}
+
+		loginChallenge.Attempts++
+		if loginChallenge.Attempts >= loginChallengeMaxAttempts {
+			logger.LogWarning("Login challenge thrown away after too many wrong codes", fields)
+			if err := a.loginChallengeRepository.Delete(loginChallenge.Id); err != nil {
+				return res.AuthResponse{}, err
+			}
+			return res.AuthResponse{}, errors.New(constants.LoginChallengeInvalidError)
+		}
+		if err := a.loginChallengeRepository.Update(loginChallenge); err != nil {
+			return res.AuthResponse{}, err
+		}
+		return res.AuthResponse{}, err
+	}
+
+	// Use up the challenge before issuing tokens, so it cannot be exchanged twice
+	if err := a.loginChallengeRepository.Delete(loginChallenge.Id); err != nil {
+		return res.AuthResponse{}, err
+	}
+	if err := a.loginAttemptService.RecordSuccess(customer.Username); err != nil {
+		return res.AuthResponse{}, err
+	}
+
+	// The device was named on the password step
+	client.DeviceName = loginChallenge.DeviceName
+	return a.issueTokens(customer, client)
 }
 
-// Logout invalidates the user's access and refresh tokens
+// Logout invalidates the user's access token and ends the session of the refresh token
 func (a *authService) Logout(accessToken string, refreshToken string) error {
+	claims, err := utils.ParseAndVerifyAccessToken(accessToken)
+	if err != nil {
+		return err
+	}
+	tokenId, err := utils.GetTokenIdFromClaims(claims)
+	if err != nil {
+		return err
+	}
+	customerId, err := utils.GetCustomerIdFromClaims(claims)
+	if err != nil {
+		return err
+	}
+	expiresAt, err := utils.GetExpirationFromClaims(claims)
+	if err != nil {
+		return err
+	}
+
 	logger.LogInfo("Attempting to log out user", logrus.Fields{
-		"accessToken": accessToken,
+		"tokenId":    tokenId,
+		"customerId": customerId,
 	})
 
 	// Add access token to the blacklist
-	err := a.blacklistService.BlacklistToken(accessToken)
+	err = a.blacklistService.BlacklistToken(tokenId, customerId, expiresAt)
 	if err != nil {
 		logger.LogError("Failed to blacklist access token", logrus.Fields{
-			"accessToken": accessToken,
-			"error":       err.Error(),
+			"tokenId": tokenId,
+			"error":   err.Error(),
 		})
 		return err
 	}
 
-	// Delete the refresh token
-	err = a.refreshTokenService.DeleteRefreshToken(refreshToken)
+	// End the session, deleting its refresh token
+	err = a.sessionService.EndSession(refreshToken)
 	if err != nil {
-		logger.LogError("Failed to delete refresh token", logrus.Fields{
-			"refreshToken": refreshToken,
-			"error":        err.Error(),
+		logger.LogError("Failed to end session", logrus.Fields{
+			"error": err.Error(),
 		})
 		return err
 	}
 
 	logger.LogInfo("Successfully logged out user", logrus.Fields{
-		"accessToken": accessToken,
+		"tokenId":    tokenId,
+		"customerId": customerId,
+	})
+	return nil
+}
+
+// LogoutAll logs the customer out of every device, ending every session and revoking every access token issued so far
+func (a *authService) LogoutAll(customerId string) error {
+	logger.LogInfo("Attempting to log out user from all devices", logrus.Fields{
+		"customerId": customerId,
+	})
+
+	if err := a.revokeAllTokens(customerId); err != nil {
+		logger.LogError("Failed to log out user from all devices", logrus.Fields{
+			"customerId": customerId,
+			"error":      err.Error(),
+		})
+		return err
+	}
+
+	logger.LogInfo("Successfully logged out user from all devices", logrus.Fields{
+		"customerId": customerId,
 	})
 	return nil
 }
 
-// GetNewAccessToken generates a new access token and rotates the refresh token
-func (a *authService) GetNewAccessToken(refreshToken string) (res.AuthResponse, error) {
+// GetNewAccessToken generates a new access token and rotates the refresh token, recording the use of the session
+func (a *authService) GetNewAccessToken(refreshToken string, client req.ClientInfo) (res.AuthResponse, error) {
 	logger.LogInfo("Attempting to rotate refresh token and generate a new access token", logrus.Fields{
-		"refreshToken": refreshToken,
+		"clientIp": client.Ip,
 	})
 
 	// Rotate refresh token
-	newRefreshToken, err := a.refreshTokenService.RotateRefreshToken(refreshToken)
+	session, newRefreshToken, err := a.sessionService.RefreshSession(refreshToken, client)
 	if err != nil {
 		logger.LogError("Failed to rotate refresh token", logrus.Fields{
-			"refreshToken": refreshToken,
-			"error":        err.Error(),
+			"clientIp": client.Ip,
+			"error":    err.Error(),
 		})
 		return res.AuthResponse{}, err
 	}
@@ -148,7 +309,7 @@ func (a *authService) GetNewAccessToken(refreshToken string) (res.AuthResponse,
 	}
 
 	// Generate a new access token
-	accessToken, err := utils.GenerateAccessToken(customer)
+	accessToken, err := utils.GenerateAccessToken(customer, session.Id)
 	if err != nil {
 		logger.LogError("Failed to generate access token", logrus.Fields{
 			"customerId": customer.Id,
@@ -168,3 +329,312 @@ func (a *authService) GetNewAccessToken(refreshToken string) (res.AuthResponse,
 		CustomerId:   customer.Id,
 	}, nil
 }
+
+// ChangePassword replaces the password of an authenticated customer after checking their current password. A wrong
+// current password counts as a failed login, so it cannot be guessed here past the login throttle.
+func (a *authService) ChangePassword(customerId string, request req.ChangePasswordRequest, client req.ClientInfo) error {
+	clientIp := client.Ip
+	logger.LogInfo("Attempting to change password", logrus.Fields{
+		"customerId": customerId,
+		"clientIp":   clientIp,
+	})
+
+	// Fetch customer details by ID
+	customer, err := a.customerService.GetCustomerByIdAuth(customerId)
+	if err != nil {
+		logger.LogError("Failed to fetch customer by ID", logrus.Fields{
+			"customerId": customerId,
+			"error":      err.Error(),
+		})
+		return err
+	}
+
+	// Refuse the attempt without checking the password while the username or IP is throttled or locked out
+	if err := a.loginAttemptService.CheckAllowed(customer.Username, clientIp); err != nil {
+		return err
+	}
+
+	// The current password must be known, a stolen access token alone cannot take over the account
+	if !utils.VerifyPassword(request.OldPassword, customer.Password) {
+		logger.LogError("Incorrect current password on password change", logrus.Fields{
+			"customerId": customerId,
+			"clientIp":   clientIp,
+		})
+		if err := a.loginAttemptService.RecordFailure(customer.Username, clientIp); err != nil {
+			return err
+		}
+		return errors.New(constants.PasswordIncorrectError)
+	}
+	if err := a.loginAttemptService.RecordSuccess(customer.Username); err != nil {
+		return err
+	}
+	if request.NewPassword == request.OldPassword {
+		return errors.New(constants.PasswordUnchangedError)
+	}
+	if err := utils.CheckPasswordPolicy("new_password", request.NewPassword, customer.Username); err != nil {
+		return err
+	}
+
+	if err := a.customerService.UpdatePassword(customerId, request.NewPassword); err != nil {
+		logger.LogError("Failed to update password", logrus.Fields{
+			"customerId": customerId,
+			"error":      err.Error(),
+		})
+		return err
+	}
+
+	logger.LogInfo("Successfully changed password", logrus.Fields{
+		"customerId": customerId,
+	})
+	return nil
+}
+
+// RequestPasswordReset sends a single-use password reset token to the customer through the notifier. An unknown
+// username is not an error, so the response does not reveal which usernames exist.
+func (a *authService) RequestPasswordReset(request req.PasswordResetRequest) error {
+	logger.LogInfo("Password reset requested", logrus.Fields{
+		"username": request.Username,
+	})
+
+	customer, err := a.customerService.GetCustomerByUsernameAuth(request.Username)
+	if err != nil {
+		if err.Error() == constants.CustomerNotFound {
+			logger.LogWarning("Password reset requested for unknown username", logrus.Fields{
+				"username": request.Username,
+			})
+			return nil
+		}
+		return err
+	}
+
+	token, err := generateSecretToken()
+	if err != nil {
+		logger.LogError("Failed to generate password reset token", logrus.Fields{
+			"customerId": customer.Id,
+			"error":      err.Error(),
+		})
+		return err
+	}
+
+	now := time.Now()
+	passwordResetToken := entity.PasswordResetToken{
+		Id:         uuid.New().String(),
+		TokenHash:  hashSecretToken(token),
+		CustomerId: customer.Id,
+		ExpiresAt:  now.Add(config.PasswordResetTokenExpirationDuration).Format(time.RFC3339),
+		CreatedAt:  now.Format(time.RFC3339),
+	}
+	if err := a.passwordResetTokenRepository.Create(passwordResetToken); err != nil {
+		return err
+	}
+
+	err = a.notifier.Send(Notification{
+		Recipient: customer.Username,
+		Subject:   "Reset your password",
+		Body:      fmt.Sprintf("Use this token to reset your password: %s. It can be used once and expires at %s.", token, passwordResetToken.ExpiresAt),
+		SentAt:    now.Format(time.RFC3339),
+	})
+	if err != nil {
+		logger.LogError("Failed to send password reset token", logrus.Fields{
+			"customerId": customer.Id,
+			"error":      err.Error(),
+		})
+		return err
+	}
+
+	logger.LogInfo("Password reset token sent", logrus.Fields{
+		"customerId":           customer.Id,
+		"passwordResetTokenId": passwordResetToken.Id,
+	})
+	return nil
+}
+
+// ResetPassword sets a new password with a password reset token. The token and every other outstanding token of the
+// customer are used up, and every session of the customer is revoked so every device has to log in again.
+func (a *authService) ResetPassword(request req.ConfirmPasswordResetRequest) error {
+	passwordResetToken, err := a.usePasswordResetToken(request)
+	if err != nil {
+		return err
+	}
+
+	if err := a.customerService.UpdatePassword(passwordResetToken.CustomerId, request.NewPassword); err != nil {
+		logger.LogError("Failed to update password", logrus.Fields{
+			"customerId": passwordResetToken.CustomerId,
+			"error":      err.Error(),
+		})
+		return err
+	}
+
+	// Revoke every session and access token, whoever knew the old password must not stay logged in
+	if err := a.revokeAllTokens(passwordResetToken.CustomerId); err != nil {
+		logger.LogError("Failed to revoke sessions after password reset", logrus.Fields{
+			"customerId": passwordResetToken.CustomerId,
+			"error":      err.Error(),
+		})
+		return err
+	}
+
+	logger.LogInfo("Successfully reset password", logrus.Fields{
+		"customerId":           passwordResetToken.CustomerId,
+		"passwordResetTokenId": passwordResetToken.Id,
+	})
+	return nil
+}
+
+// usePasswordResetToken checks the password reset token and the new password, and uses up the token and every other
+// outstanding token of the customer. The check and the use run under resetLock, so a token confirmed twice at once
+// is only accepted by the first.
+func (a *authService) usePasswordResetToken(request req.ConfirmPasswordResetRequest) (entity.PasswordResetToken, error) {
+	a.resetLock.Lock()
+	defer a.resetLock.Unlock()
+
+	passwordResetToken, err := a.passwordResetTokenRepository.GetByTokenHash(hashSecretToken(request.Token))
+	if err != nil {
+		if err.Error() == constants.PasswordResetTokenNotFoundError {
+			logger.LogWarning("Password reset attempted with an unknown token", logrus.Fields{})
+			return entity.PasswordResetToken{}, errors.New(constants.PasswordResetTokenInvalidError)
+		}
+		return entity.PasswordResetToken{}, err
+	}
+
+	fields := logrus.Fields{
+		"customerId":           passwordResetToken.CustomerId,
+		"passwordResetTokenId": passwordResetToken.Id,
+	}
+	logger.LogInfo("Attempting to reset password", fields)
+
+	now := time.Now()
+	expiresAt, err := time.Parse(time.RFC3339, passwordResetToken.ExpiresAt)
+	if err != nil || passwordResetToken.UsedAt != "" || !now.Before(expiresAt) {
+		logger.LogWarning("Password reset attempted with a used or expired token", fields)
+		return entity.PasswordResetToken{}, errors.New(constants.PasswordResetTokenInvalidError)
+	}
+
+	// Check the new password before the token is used up, so a rejected password can be corrected and sent again
+	customer, err := a.customerService.GetCustomerByIdAuth(passwordResetToken.CustomerId)
+	if err != nil {
+		return entity.PasswordResetToken{}, err
+	}
+	if err := utils.CheckPasswordPolicy("new_password", request.NewPassword, customer.Username); err != nil {
+		return entity.PasswordResetToken{}, err
+	}
+
+	// Use up the token before changing the password, so it cannot be replayed if a later step fails
+	passwordResetTokens, err := a.passwordResetTokenRepository.GetAll()
+	if err != nil {
+		return entity.PasswordResetToken{}, err
+	}
+	for _, outstanding := range passwordResetTokens {
+		if outstanding.CustomerId != passwordResetToken.CustomerId || outstanding.UsedAt != "" {
+			continue
+		}
+		outstanding.UsedAt = now.Format(time.RFC3339)
+		if err := a.passwordResetTokenRepository.Update(outstanding); err != nil {
+			return entity.PasswordResetToken{}, err
+		}
+	}
+
+	return passwordResetToken, nil
+}
+
+// revokeAllTokens ends every session of the customer and moves their watermark, so access tokens issued until now
+// are refused
+func (a *authService) revokeAllTokens(customerId string) error {
+	if err := a.sessionService.RevokeAllSessions(customerId); err != nil {
+		return err
+	}
+
+	// Taken once the refresh tokens are gone, so no access token refreshed meanwhile slips past
+	return a.blacklistService.RevokeTokensIssuedBefore(customerId, time.Now())
+}
+
+// createLoginChallenge stores a login challenge for the customer and returns its token, only sent in the response
+func (a *authService) createLoginChallenge(customer entity.Customer, deviceName string) (res.AuthResponse, error) {
+	token, err := generateSecretToken()
+	if err != nil {
+		return res.AuthResponse{}, err
+	}
+
+	now := time.Now()
+	loginChallenge := entity.LoginChallenge{
+		Id:         uuid.New().String(),
+		TokenHash:  hashSecretToken(token),
+		CustomerId: customer.Id,
+		DeviceName: deviceName,
+		ExpiresAt:  now.Add(config.LoginChallengeExpirationDuration).Format(time.RFC3339),
+		CreatedAt:  now.Format(time.RFC3339),
+	}
+	if err := a.loginChallengeRepository.Create(loginChallenge); err != nil {
+		return res.AuthResponse{}, err
+	}
+
+	logger.LogInfo("Password accepted, waiting for two-factor code", logrus.Fields{
+		"customerId":       customer.Id,
+		"loginChallengeId": loginChallenge.Id,
+	})
+	return res.AuthResponse{
+		CustomerId:         customer.Id,
+		TwoFactorRequired:  true,
+		ChallengeToken:     token,
+		ChallengeExpiresAt: loginChallenge.ExpiresAt,
+	}, nil
+}
+
+// issueTokens starts a session for the client and generates the access and refresh tokens that finish a login
+func (a *authService) issueTokens(customer entity.Customer, client req.ClientInfo) (res.AuthResponse, error) {
+	// Start a session with its refresh token
+	session, refreshToken, err := a.sessionService.StartSession(customer.Id, client)
+	if err != nil {
+		logger.LogError("Failed to start session", logrus.Fields{
+			"username": customer.Username,
+			"error":    err.Error(),
+		})
+		return res.AuthResponse{}, err
+	}
+
+	// Generate access token
+	accessToken, err := utils.GenerateAccessToken(customer, session.Id)
+	if err != nil {
+		logger.LogError("Failed to generate access token", logrus.Fields{
+			"username": customer.Username,
+			"error":    err.Error(),
+		})
+		return res.AuthResponse{}, err
+	}
+
+	logger.LogInfo("Successfully logged in", logrus.Fields{
+		"username":   customer.Username,
+		"customerId": customer.Id,
+		"sessionId":  session.Id,
+	})
+
+	// Return authentication response with tokens
+	return res.AuthResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken.RefreshToken,
+		CustomerId:   customer.Id,
+	}, nil
+}
+
+// unknownCustomerPasswordHash hashes a random password no one knows with the configured algorithm, once
+var unknownCustomerPasswordHash = sync.OnceValue(func() string {
+	password, _ := generateSecretToken()
+	hashedPassword, _ := utils.HashPassword(password)
+	return hashedPassword
+})
+
+// generateSecretToken returns 32 random bytes as hex, sent to the customer and never stored
+func generateSecretToken() (string, error) {
+	token := make([]byte, 32)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(token), nil
+}
+
+// hashSecretToken returns the SHA-256 hash a password reset token, login challenge or refresh token is stored and
+// looked up by
+func hashSecretToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
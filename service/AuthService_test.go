@@ -1,12 +1,18 @@
 package service
 
 import (
+	"PaymentAPI/config"
 	"PaymentAPI/constants"
 	req "PaymentAPI/dto/request"
 	dto "PaymentAPI/dto/response"
 	"PaymentAPI/entity"
+	"PaymentAPI/repository"
 	"PaymentAPI/utils"
+	"errors"
+	"github.com/golang-jwt/jwt/v4"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"strings"
 	"testing"
 	"time"
 )
@@ -14,9 +20,9 @@ import (
 func TestLogin(t *testing.T) {
 	t.Run("ShouldReturnAuth", func(t *testing.T) {
 		mockCustomerService := new(CustomerServiceMock)
-		mockRefreshTokenService := new(RefreshTokenServiceMock)
+		mockSessionService := new(SessionServiceMock)
 		mockBlacklistService := new(BlacklistServiceMock)
-		authService := NewAuthService(mockCustomerService, mockRefreshTokenService, mockBlacklistService)
+		authService := NewAuthService(mockCustomerService, mockSessionService, mockBlacklistService, new(repository.PasswordResetTokenRepositoryMock), new(NotifierMock), newLoginAttemptServiceMock(), newTwoFactorServiceMock(), new(repository.LoginChallengeRepositoryMock))
 
 		request := req.CustomerRequest{
 			Username: "johndoe",
@@ -38,24 +44,27 @@ func TestLogin(t *testing.T) {
 		mockCustomerService.Mock.On("GetCustomerByUsernameAuth", request.Username).
 			Return(customer, nil)
 
-		mockRefreshTokenService.Mock.On("GenerateRefreshToken", customer.Id).
-			Return(refreshToken, nil)
+		mockSessionService.Mock.On("StartSession", customer.Id, req.ClientInfo{Ip: "127.0.0.1"}).
+			Return(entity.Session{Id: "session-1"}, refreshToken, nil)
 
-		login, err := authService.Login(request)
+		login, err := authService.Login(request, req.ClientInfo{Ip: "127.0.0.1"})
 		assert.Nil(t, err)
 		assert.NotNil(t, login.AccessToken)
 		assert.NotNil(t, login.RefreshToken)
 
-		id, err := utils.GetCustomerIdFromClaims(login.AccessToken)
+		claims, err := utils.ParseAndVerifyAccessToken(login.AccessToken)
+		assert.Nil(t, err)
+		id, err := utils.GetCustomerIdFromClaims(claims)
 		assert.Nil(t, err)
 		assert.Equal(t, customer.Id, id)
+		assert.Equal(t, "session-1", utils.GetSessionIdFromClaims(claims))
 	})
 
 	t.Run("ShouldReturnError", func(t *testing.T) {
 		mockCustomerService := new(CustomerServiceMock)
-		mockRefreshTokenService := new(RefreshTokenServiceMock)
+		mockSessionService := new(SessionServiceMock)
 		mockBlacklistService := new(BlacklistServiceMock)
-		authService := NewAuthService(mockCustomerService, mockRefreshTokenService, mockBlacklistService)
+		authService := NewAuthService(mockCustomerService, mockSessionService, mockBlacklistService, new(repository.PasswordResetTokenRepositoryMock), new(NotifierMock), newLoginAttemptServiceMock(), newTwoFactorServiceMock(), new(repository.LoginChallengeRepositoryMock))
 
 		request := req.CustomerRequest{
 			Username: "johndoe",
@@ -71,36 +80,108 @@ func TestLogin(t *testing.T) {
 		mockCustomerService.Mock.On("GetCustomerByUsernameAuth", request.Username).
 			Return(customer, nil)
 
-		login, err := authService.Login(request)
+		login, err := authService.Login(request, req.ClientInfo{Ip: "127.0.0.1"})
 		assert.Equal(t, constants.LoginUnauthorizedError, err.Error())
 		assert.Equal(t, dto.AuthResponse{}, login)
 	})
+
+	t.Run("ShouldNotRevealUnknownUsername", func(t *testing.T) {
+		mockCustomerService := new(CustomerServiceMock)
+		mockLoginAttemptService := newLoginAttemptServiceMock()
+		authService := NewAuthService(mockCustomerService, new(SessionServiceMock), new(BlacklistServiceMock), new(repository.PasswordResetTokenRepositoryMock), new(NotifierMock), mockLoginAttemptService, newTwoFactorServiceMock(), new(repository.LoginChallengeRepositoryMock))
+
+		mockCustomerService.Mock.On("GetCustomerByUsernameAuth", "nobody").
+			Return(entity.Customer{}, errors.New(constants.CustomerNotFound))
+
+		login, err := authService.Login(req.CustomerRequest{Username: "nobody", Password: "password"}, req.ClientInfo{Ip: "127.0.0.1"})
+		assert.Equal(t, constants.LoginUnauthorizedError, err.Error())
+		assert.Equal(t, dto.AuthResponse{}, login)
+		mockLoginAttemptService.Mock.AssertCalled(t, "RecordFailure", "nobody", "127.0.0.1")
+	})
+
+	t.Run("ShouldRefuseThrottledLoginWithoutCheckingPassword", func(t *testing.T) {
+		mockCustomerService := new(CustomerServiceMock)
+		mockLoginAttemptService := new(LoginAttemptServiceMock)
+		authService := NewAuthService(mockCustomerService, new(SessionServiceMock), new(BlacklistServiceMock), new(repository.PasswordResetTokenRepositoryMock), new(NotifierMock), mockLoginAttemptService, newTwoFactorServiceMock(), new(repository.LoginChallengeRepositoryMock))
+
+		mockLoginAttemptService.Mock.On("CheckAllowed", "johndoe", "127.0.0.1").
+			Return(&LoginThrottledError{Message: constants.LoginLockedError, RetryAfter: time.Minute})
+
+		_, err := authService.Login(req.CustomerRequest{Username: "johndoe", Password: "password"}, req.ClientInfo{Ip: "127.0.0.1"})
+		var throttledErr *LoginThrottledError
+		assert.ErrorAs(t, err, &throttledErr)
+		assert.Equal(t, time.Minute, throttledErr.RetryAfter)
+		mockCustomerService.Mock.AssertNotCalled(t, "GetCustomerByUsernameAuth", "johndoe")
+	})
+}
+
+// newLoginAttemptServiceMock allows every login and accepts every recorded attempt
+func newLoginAttemptServiceMock() *LoginAttemptServiceMock {
+	mockLoginAttemptService := new(LoginAttemptServiceMock)
+	mockLoginAttemptService.Mock.On("CheckAllowed", mock.Anything, mock.Anything).Return(nil)
+	mockLoginAttemptService.Mock.On("RecordFailure", mock.Anything, mock.Anything).Return(nil)
+	mockLoginAttemptService.Mock.On("RecordSuccess", mock.Anything).Return(nil)
+	return mockLoginAttemptService
+}
+
+// newTwoFactorServiceMock reports two-factor authentication as disabled for every customer
+func newTwoFactorServiceMock() *TwoFactorServiceMock {
+	mockTwoFactorService := new(TwoFactorServiceMock)
+	mockTwoFactorService.Mock.On("IsEnabled", mock.Anything).Return(false, nil)
+	return mockTwoFactorService
 }
 
 func TestLogout(t *testing.T) {
+	config.LoginExpirationDuration = 5 * time.Minute
+	config.JwtSigningMethod = jwt.SigningMethodHS256
+	config.JwtSignatureKey = []byte("secret")
+
 	mockCustomerService := new(CustomerServiceMock)
-	mockRefreshTokenService := new(RefreshTokenServiceMock)
+	mockSessionService := new(SessionServiceMock)
 	mockBlacklistService := new(BlacklistServiceMock)
-	authService := NewAuthService(mockCustomerService, mockRefreshTokenService, mockBlacklistService)
+	authService := NewAuthService(mockCustomerService, mockSessionService, mockBlacklistService, new(repository.PasswordResetTokenRepositoryMock), new(NotifierMock), new(LoginAttemptServiceMock), new(TwoFactorServiceMock), new(repository.LoginChallengeRepositoryMock))
 
-	accessToken := "access-token-1"
+	accessToken, _ := utils.GenerateAccessToken(entity.Customer{Id: "customer-id-1", Username: "johndoe"}, "session-1")
+	claims, _ := utils.ParseAndVerifyAccessToken(accessToken)
+	tokenId, _ := utils.GetTokenIdFromClaims(claims)
+	expiresAt, _ := utils.GetExpirationFromClaims(claims)
 	refreshToken := "refresh-token-1"
 
-	mockBlacklistService.Mock.On("BlacklistToken", accessToken).
+	// Only the JWT ID and expiry of the token are blacklisted
+	mockBlacklistService.Mock.On("BlacklistToken", tokenId, "customer-id-1", expiresAt).
 		Return(nil)
 
-	mockRefreshTokenService.Mock.On("DeleteRefreshToken", refreshToken).
+	mockSessionService.Mock.On("EndSession", refreshToken).
 		Return(nil)
 
 	err := authService.Logout(accessToken, refreshToken)
 	assert.Nil(t, err)
+	mockBlacklistService.Mock.AssertExpectations(t)
+}
+
+func TestLogoutAll(t *testing.T) {
+	mockSessionService := new(SessionServiceMock)
+	mockBlacklistService := new(BlacklistServiceMock)
+	authService := NewAuthService(new(CustomerServiceMock), mockSessionService, mockBlacklistService, new(repository.PasswordResetTokenRepositoryMock), new(NotifierMock), new(LoginAttemptServiceMock), new(TwoFactorServiceMock), new(repository.LoginChallengeRepositoryMock))
+
+	mockSessionService.Mock.On("RevokeAllSessions", "customer-id-1").Return(nil)
+	mockBlacklistService.Mock.On("RevokeTokensIssuedBefore", "customer-id-1", mock.Anything).Return(nil)
+
+	before := time.Now()
+	err := authService.LogoutAll("customer-id-1")
+	assert.Nil(t, err)
+
+	// Every access token issued until now is revoked
+	issuedBefore := mockBlacklistService.Mock.Calls[0].Arguments.Get(1).(time.Time)
+	assert.False(t, issuedBefore.Before(before))
+	mockSessionService.Mock.AssertExpectations(t)
 }
 
 func TestGetNewAccessToken(t *testing.T) {
 	mockCustomerService := new(CustomerServiceMock)
-	mockRefreshTokenService := new(RefreshTokenServiceMock)
+	mockSessionService := new(SessionServiceMock)
 	mockBlacklistService := new(BlacklistServiceMock)
-	authService := NewAuthService(mockCustomerService, mockRefreshTokenService, mockBlacklistService)
+	authService := NewAuthService(mockCustomerService, mockSessionService, mockBlacklistService, new(repository.PasswordResetTokenRepositoryMock), new(NotifierMock), new(LoginAttemptServiceMock), new(TwoFactorServiceMock), new(repository.LoginChallengeRepositoryMock))
 
 	refreshToken := "refresh-token-1"
 
@@ -116,18 +197,396 @@ func TestGetNewAccessToken(t *testing.T) {
 		Password: "password",
 	}
 
-	mockRefreshTokenService.Mock.On("RotateRefreshToken", refreshToken).
-		Return(newRefreshToken, nil)
+	mockSessionService.Mock.On("RefreshSession", refreshToken, req.ClientInfo{Ip: "127.0.0.1"}).
+		Return(entity.Session{Id: "session-1"}, newRefreshToken, nil)
 
 	mockCustomerService.Mock.On("GetCustomerByIdAuth", newRefreshToken.CustomerId).
 		Return(customer, nil)
 
-	token, err := authService.GetNewAccessToken(refreshToken)
+	token, err := authService.GetNewAccessToken(refreshToken, req.ClientInfo{Ip: "127.0.0.1"})
 	assert.Nil(t, err)
 	assert.NotNil(t, token.AccessToken)
 	assert.NotNil(t, token.RefreshToken)
 
-	id, err := utils.GetCustomerIdFromClaims(token.AccessToken)
+	claims, err := utils.ParseAndVerifyAccessToken(token.AccessToken)
+	assert.Nil(t, err)
+	id, err := utils.GetCustomerIdFromClaims(claims)
 	assert.Nil(t, err)
 	assert.Equal(t, customer.Id, id)
+	assert.Equal(t, "session-1", utils.GetSessionIdFromClaims(claims))
+}
+
+func TestChangePassword(t *testing.T) {
+	customer := entity.Customer{
+		Id:       "customer-id-1",
+		Username: "johndoe",
+		Password: "$2a$10$Ghu/KGIz/UnyrAnvNG7JcODckVqHgXA/Un7/MFKqz/CqhQ2BFGJlK",
+	}
+
+	client := req.ClientInfo{Ip: "127.0.0.1"}
+
+	t.Run("ShouldUpdatePassword", func(t *testing.T) {
+		mockCustomerService := new(CustomerServiceMock)
+		mockLoginAttemptService := newLoginAttemptServiceMock()
+		authService := NewAuthService(mockCustomerService, new(SessionServiceMock), new(BlacklistServiceMock), new(repository.PasswordResetTokenRepositoryMock), new(NotifierMock), mockLoginAttemptService, new(TwoFactorServiceMock), new(repository.LoginChallengeRepositoryMock))
+
+		mockCustomerService.Mock.On("GetCustomerByIdAuth", customer.Id).Return(customer, nil)
+		mockCustomerService.Mock.On("UpdatePassword", customer.Id, "new-password").Return(nil)
+
+		err := authService.ChangePassword(customer.Id, req.ChangePasswordRequest{OldPassword: "password", NewPassword: "new-password"}, client)
+		assert.Nil(t, err)
+		mockCustomerService.Mock.AssertExpectations(t)
+		mockLoginAttemptService.Mock.AssertCalled(t, "RecordSuccess", customer.Username)
+	})
+
+	t.Run("ShouldRejectIncorrectOldPassword", func(t *testing.T) {
+		mockCustomerService := new(CustomerServiceMock)
+		mockLoginAttemptService := newLoginAttemptServiceMock()
+		authService := NewAuthService(mockCustomerService, new(SessionServiceMock), new(BlacklistServiceMock), new(repository.PasswordResetTokenRepositoryMock), new(NotifierMock), mockLoginAttemptService, new(TwoFactorServiceMock), new(repository.LoginChallengeRepositoryMock))
+
+		mockCustomerService.Mock.On("GetCustomerByIdAuth", customer.Id).Return(customer, nil)
+
+		err := authService.ChangePassword(customer.Id, req.ChangePasswordRequest{OldPassword: "wrong", NewPassword: "new-password"}, client)
+		assert.Equal(t, constants.PasswordIncorrectError, err.Error())
+		mockCustomerService.Mock.AssertNotCalled(t, "UpdatePassword", customer.Id, "new-password")
+		mockLoginAttemptService.Mock.AssertCalled(t, "RecordFailure", customer.Username, "127.0.0.1")
+	})
+
+	t.Run("ShouldRefuseThrottledChangeWithoutCheckingPassword", func(t *testing.T) {
+		mockCustomerService := new(CustomerServiceMock)
+		mockLoginAttemptService := new(LoginAttemptServiceMock)
+		authService := NewAuthService(mockCustomerService, new(SessionServiceMock), new(BlacklistServiceMock), new(repository.PasswordResetTokenRepositoryMock), new(NotifierMock), mockLoginAttemptService, new(TwoFactorServiceMock), new(repository.LoginChallengeRepositoryMock))
+
+		mockCustomerService.Mock.On("GetCustomerByIdAuth", customer.Id).Return(customer, nil)
+		mockLoginAttemptService.Mock.On("CheckAllowed", customer.Username, "127.0.0.1").
+			Return(&LoginThrottledError{Message: constants.LoginLockedError, RetryAfter: time.Minute})
+
+		err := authService.ChangePassword(customer.Id, req.ChangePasswordRequest{OldPassword: "password", NewPassword: "new-password"}, client)
+		var throttledErr *LoginThrottledError
+		assert.ErrorAs(t, err, &throttledErr)
+		mockLoginAttemptService.Mock.AssertNotCalled(t, "RecordSuccess", customer.Username)
+		mockCustomerService.Mock.AssertNotCalled(t, "UpdatePassword", customer.Id, "new-password")
+	})
+}
+
+func TestRequestPasswordReset(t *testing.T) {
+	t.Run("ShouldSendTokenAndStoreItsHash", func(t *testing.T) {
+		mockCustomerService := new(CustomerServiceMock)
+		mockPasswordResetTokenRepository := new(repository.PasswordResetTokenRepositoryMock)
+		mockNotifier := new(NotifierMock)
+		authService := NewAuthService(mockCustomerService, new(SessionServiceMock), new(BlacklistServiceMock), mockPasswordResetTokenRepository, mockNotifier, new(LoginAttemptServiceMock), new(TwoFactorServiceMock), new(repository.LoginChallengeRepositoryMock))
+
+		customer := entity.Customer{Id: "customer-id-1", Username: "johndoe"}
+		mockCustomerService.Mock.On("GetCustomerByUsernameAuth", customer.Username).Return(customer, nil)
+		mockPasswordResetTokenRepository.Mock.On("Create", mock.Anything).Return(nil)
+		mockNotifier.Mock.On("Send", mock.Anything).Return(nil)
+
+		err := authService.RequestPasswordReset(req.PasswordResetRequest{Username: customer.Username})
+		assert.Nil(t, err)
+
+		stored := mockPasswordResetTokenRepository.Mock.Calls[0].Arguments.Get(0).(entity.PasswordResetToken)
+		notification := mockNotifier.Mock.Calls[0].Arguments.Get(0).(Notification)
+		assert.Equal(t, customer.Id, stored.CustomerId)
+		assert.Equal(t, customer.Username, notification.Recipient)
+
+		// The notification carries the token, storage only its hash
+		token := strings.TrimSuffix(strings.Fields(notification.Body)[7], ".")
+		assert.Len(t, token, 64)
+		assert.Equal(t, hashSecretToken(token), stored.TokenHash)
+		assert.NotContains(t, stored.TokenHash, token)
+	})
+
+	t.Run("ShouldNotRevealUnknownUsername", func(t *testing.T) {
+		mockCustomerService := new(CustomerServiceMock)
+		mockNotifier := new(NotifierMock)
+		authService := NewAuthService(mockCustomerService, new(SessionServiceMock), new(BlacklistServiceMock), new(repository.PasswordResetTokenRepositoryMock), mockNotifier, new(LoginAttemptServiceMock), new(TwoFactorServiceMock), new(repository.LoginChallengeRepositoryMock))
+
+		mockCustomerService.Mock.On("GetCustomerByUsernameAuth", "nobody").Return(entity.Customer{}, errors.New(constants.CustomerNotFound))
+
+		err := authService.RequestPasswordReset(req.PasswordResetRequest{Username: "nobody"})
+		assert.Nil(t, err)
+		mockNotifier.Mock.AssertNotCalled(t, "Send", mock.Anything)
+	})
+}
+
+func TestResetPassword(t *testing.T) {
+	token := "reset-token-1"
+	validToken := entity.PasswordResetToken{
+		Id:         "reset-id-1",
+		TokenHash:  hashSecretToken(token),
+		CustomerId: "customer-id-1",
+		ExpiresAt:  time.Now().Add(30 * time.Minute).Format(time.RFC3339),
+	}
+
+	t.Run("ShouldResetPasswordAndRevokeSessions", func(t *testing.T) {
+		mockCustomerService := new(CustomerServiceMock)
+		mockSessionService := new(SessionServiceMock)
+		mockBlacklistService := new(BlacklistServiceMock)
+		mockPasswordResetTokenRepository := new(repository.PasswordResetTokenRepositoryMock)
+		authService := NewAuthService(mockCustomerService, mockSessionService, mockBlacklistService, mockPasswordResetTokenRepository, new(NotifierMock), new(LoginAttemptServiceMock), new(TwoFactorServiceMock), new(repository.LoginChallengeRepositoryMock))
+
+		otherToken := entity.PasswordResetToken{Id: "reset-id-2", CustomerId: validToken.CustomerId, ExpiresAt: validToken.ExpiresAt}
+		mockPasswordResetTokenRepository.Mock.On("GetByTokenHash", validToken.TokenHash).Return(validToken, nil)
+		mockPasswordResetTokenRepository.Mock.On("GetAll").Return([]entity.PasswordResetToken{validToken, otherToken}, nil)
+		mockPasswordResetTokenRepository.Mock.On("Update", mock.Anything).Return(nil)
+		mockCustomerService.Mock.On("GetCustomerByIdAuth", validToken.CustomerId).Return(entity.Customer{Id: validToken.CustomerId, Username: "johndoe"}, nil)
+		mockCustomerService.Mock.On("UpdatePassword", validToken.CustomerId, "new-password").Return(nil)
+		mockSessionService.Mock.On("RevokeAllSessions", validToken.CustomerId).Return(nil)
+		mockBlacklistService.Mock.On("RevokeTokensIssuedBefore", validToken.CustomerId, mock.Anything).Return(nil)
+
+		err := authService.ResetPassword(req.ConfirmPasswordResetRequest{Token: token, NewPassword: "new-password"})
+		assert.Nil(t, err)
+
+		// Both outstanding tokens of the customer are used up
+		mockPasswordResetTokenRepository.Mock.AssertNumberOfCalls(t, "Update", 2)
+		for _, call := range mockPasswordResetTokenRepository.Mock.Calls {
+			if call.Method == "Update" {
+				assert.NotEmpty(t, call.Arguments.Get(0).(entity.PasswordResetToken).UsedAt)
+			}
+		}
+		mockCustomerService.Mock.AssertExpectations(t)
+		mockSessionService.Mock.AssertExpectations(t)
+		mockBlacklistService.Mock.AssertExpectations(t)
+	})
+
+	t.Run("ShouldRejectUsedOrExpiredToken", func(t *testing.T) {
+		usedToken := validToken
+		usedToken.UsedAt = time.Now().Format(time.RFC3339)
+		expiredToken := validToken
+		expiredToken.ExpiresAt = time.Now().Add(-time.Minute).Format(time.RFC3339)
+
+		for _, passwordResetToken := range []entity.PasswordResetToken{usedToken, expiredToken} {
+			mockCustomerService := new(CustomerServiceMock)
+			mockPasswordResetTokenRepository := new(repository.PasswordResetTokenRepositoryMock)
+			authService := NewAuthService(mockCustomerService, new(SessionServiceMock), new(BlacklistServiceMock), mockPasswordResetTokenRepository, new(NotifierMock), new(LoginAttemptServiceMock), new(TwoFactorServiceMock), new(repository.LoginChallengeRepositoryMock))
+
+			mockPasswordResetTokenRepository.Mock.On("GetByTokenHash", validToken.TokenHash).Return(passwordResetToken, nil)
+
+			err := authService.ResetPassword(req.ConfirmPasswordResetRequest{Token: token, NewPassword: "new-password"})
+			assert.Equal(t, constants.PasswordResetTokenInvalidError, err.Error())
+			mockCustomerService.Mock.AssertNotCalled(t, "UpdatePassword", validToken.CustomerId, "new-password")
+		}
+	})
+
+	t.Run("ShouldKeepTokenWhenPasswordIsRejected", func(t *testing.T) {
+		mockCustomerService := new(CustomerServiceMock)
+		mockPasswordResetTokenRepository := new(repository.PasswordResetTokenRepositoryMock)
+		authService := NewAuthService(mockCustomerService, new(SessionServiceMock), new(BlacklistServiceMock), mockPasswordResetTokenRepository, new(NotifierMock), new(LoginAttemptServiceMock), new(TwoFactorServiceMock), new(repository.LoginChallengeRepositoryMock))
+
+		mockPasswordResetTokenRepository.Mock.On("GetByTokenHash", validToken.TokenHash).Return(validToken, nil)
+		mockCustomerService.Mock.On("GetCustomerByIdAuth", validToken.CustomerId).Return(entity.Customer{Id: validToken.CustomerId, Username: "johndoe"}, nil)
+
+		err := authService.ResetPassword(req.ConfirmPasswordResetRequest{Token: token, NewPassword: "johndoe123"})
+		var policyErr *utils.PasswordPolicyError
+		assert.ErrorAs(t, err, &policyErr)
+		assert.Equal(t, "new_password", policyErr.Field)
+		assert.Contains(t, policyErr.Violations, constants.PasswordUsernameError)
+		mockPasswordResetTokenRepository.Mock.AssertNotCalled(t, "Update", mock.Anything)
+	})
+
+	t.Run("ShouldRejectUnknownToken", func(t *testing.T) {
+		mockPasswordResetTokenRepository := new(repository.PasswordResetTokenRepositoryMock)
+		authService := NewAuthService(new(CustomerServiceMock), new(SessionServiceMock), new(BlacklistServiceMock), mockPasswordResetTokenRepository, new(NotifierMock), new(LoginAttemptServiceMock), new(TwoFactorServiceMock), new(repository.LoginChallengeRepositoryMock))
+
+		mockPasswordResetTokenRepository.Mock.On("GetByTokenHash", hashSecretToken("unknown")).
+			Return(entity.PasswordResetToken{}, errors.New(constants.PasswordResetTokenNotFoundError))
+
+		err := authService.ResetPassword(req.ConfirmPasswordResetRequest{Token: "unknown", NewPassword: "new-password"})
+		assert.Equal(t, constants.PasswordResetTokenInvalidError, err.Error())
+	})
+
+	t.Run("ShouldCheckTokenOnlyOnceItIsUsedUp", func(t *testing.T) {
+		mockCustomerService := new(CustomerServiceMock)
+		mockSessionService := new(SessionServiceMock)
+		mockBlacklistService := new(BlacklistServiceMock)
+		mockPasswordResetTokenRepository := new(repository.PasswordResetTokenRepositoryMock)
+		authService := NewAuthService(mockCustomerService, mockSessionService, mockBlacklistService, mockPasswordResetTokenRepository, new(NotifierMock), new(LoginAttemptServiceMock), new(TwoFactorServiceMock), new(repository.LoginChallengeRepositoryMock))
+
+		// The first confirm stops while it uses up the token, the second finds it used
+		usedToken := validToken
+		usedToken.UsedAt = time.Now().Format(time.RFC3339)
+		usingUp, release := make(chan struct{}), make(chan struct{})
+		mockPasswordResetTokenRepository.Mock.On("GetByTokenHash", validToken.TokenHash).Return(validToken, nil).Once()
+		mockPasswordResetTokenRepository.Mock.On("GetByTokenHash", validToken.TokenHash).Return(usedToken, nil)
+		mockPasswordResetTokenRepository.Mock.On("GetAll").Run(func(args mock.Arguments) {
+			close(usingUp)
+			<-release
+		}).Return([]entity.PasswordResetToken{validToken}, nil)
+		mockPasswordResetTokenRepository.Mock.On("Update", mock.Anything).Return(nil)
+		mockCustomerService.Mock.On("GetCustomerByIdAuth", validToken.CustomerId).Return(entity.Customer{Id: validToken.CustomerId, Username: "johndoe"}, nil)
+		mockCustomerService.Mock.On("UpdatePassword", validToken.CustomerId, mock.Anything).Return(nil)
+		mockSessionService.Mock.On("RevokeAllSessions", validToken.CustomerId).Return(nil)
+		mockBlacklistService.Mock.On("RevokeTokensIssuedBefore", validToken.CustomerId, mock.Anything).Return(nil)
+
+		first := make(chan error)
+		go func() {
+			first <- authService.ResetPassword(req.ConfirmPasswordResetRequest{Token: token, NewPassword: "new-password"})
+		}()
+		<-usingUp
+
+		second := make(chan error)
+		go func() {
+			second <- authService.ResetPassword(req.ConfirmPasswordResetRequest{Token: token, NewPassword: "other-password"})
+		}()
+		time.Sleep(50 * time.Millisecond)
+		mockPasswordResetTokenRepository.Mock.AssertNumberOfCalls(t, "GetByTokenHash", 1)
+
+		close(release)
+		assert.Nil(t, <-first)
+		assert.Equal(t, constants.PasswordResetTokenInvalidError, (<-second).Error())
+		mockCustomerService.Mock.AssertNumberOfCalls(t, "UpdatePassword", 1)
+	})
+}
+
+func TestLoginUpgradesPasswordHash(t *testing.T) {
+	defer func(algorithm string, memory, iterations, parallelism int) {
+		config.PasswordHashAlgorithm, config.Argon2Memory, config.Argon2Iterations, config.Argon2Parallelism = algorithm, memory, iterations, parallelism
+	}(config.PasswordHashAlgorithm, config.Argon2Memory, config.Argon2Iterations, config.Argon2Parallelism)
+	config.PasswordHashAlgorithm, config.Argon2Memory, config.Argon2Iterations, config.Argon2Parallelism = "argon2id", 1024, 1, 1
+
+	defer func(signingMethod jwt.SigningMethod, signatureKey []byte) {
+		config.JwtSigningMethod, config.JwtSignatureKey = signingMethod, signatureKey
+	}(config.JwtSigningMethod, config.JwtSignatureKey)
+	config.JwtSigningMethod, config.JwtSignatureKey = jwt.SigningMethodHS256, []byte("secret")
+
+	mockCustomerService := new(CustomerServiceMock)
+	mockSessionService := new(SessionServiceMock)
+	authService := NewAuthService(mockCustomerService, mockSessionService, new(BlacklistServiceMock), new(repository.PasswordResetTokenRepositoryMock), new(NotifierMock), newLoginAttemptServiceMock(), newTwoFactorServiceMock(), new(repository.LoginChallengeRepositoryMock))
+
+	// A bcrypt hash from before argon2id was configured
+	customer := entity.Customer{
+		Id:       "id-1",
+		Username: "johndoe",
+		Password: "$2a$10$Ghu/KGIz/UnyrAnvNG7JcODckVqHgXA/Un7/MFKqz/CqhQ2BFGJlK",
+	}
+	mockCustomerService.Mock.On("GetCustomerByUsernameAuth", customer.Username).Return(customer, nil)
+	mockCustomerService.Mock.On("UpdatePassword", customer.Id, "password").Return(nil)
+	mockSessionService.Mock.On("StartSession", customer.Id, mock.Anything).Return(entity.Session{Id: "session-1"}, entity.RefreshToken{RefreshToken: "refresh-token-1"}, nil)
+
+	_, err := authService.Login(req.CustomerRequest{Username: customer.Username, Password: "password"}, req.ClientInfo{Ip: "127.0.0.1"})
+	assert.Nil(t, err)
+	mockCustomerService.Mock.AssertCalled(t, "UpdatePassword", customer.Id, "password")
+}
+
+func TestLoginWithTwoFactor(t *testing.T) {
+	defer func(signingMethod jwt.SigningMethod, signatureKey []byte) {
+		config.JwtSigningMethod, config.JwtSignatureKey = signingMethod, signatureKey
+	}(config.JwtSigningMethod, config.JwtSignatureKey)
+	config.JwtSigningMethod, config.JwtSignatureKey = jwt.SigningMethodHS256, []byte("secret")
+
+	customer := entity.Customer{Id: "id-1", Username: "johndoe"}
+	loginChallenge := func(attempts int, expiresAt time.Time) entity.LoginChallenge {
+		return entity.LoginChallenge{
+			Id:         "challenge-1",
+			TokenHash:  hashSecretToken("challenge-token"),
+			CustomerId: customer.Id,
+			DeviceName: "Pixel 8",
+			Attempts:   attempts,
+			ExpiresAt:  expiresAt.Format(time.RFC3339),
+		}
+	}
+
+	t.Run("ShouldReturnChallengeInsteadOfTokens", func(t *testing.T) {
+		hashedPassword, _ := utils.BCryptEncoder("password", 4)
+		mockCustomerService := new(CustomerServiceMock)
+		mockSessionService := new(SessionServiceMock)
+		mockTwoFactorService := new(TwoFactorServiceMock)
+		mockLoginChallengeRepository := new(repository.LoginChallengeRepositoryMock)
+		authService := NewAuthService(mockCustomerService, mockSessionService, new(BlacklistServiceMock), new(repository.PasswordResetTokenRepositoryMock), new(NotifierMock), newLoginAttemptServiceMock(), mockTwoFactorService, mockLoginChallengeRepository)
+
+		mockCustomerService.Mock.On("GetCustomerByUsernameAuth", customer.Username).
+			Return(entity.Customer{Id: customer.Id, Username: customer.Username, Password: hashedPassword}, nil)
+		mockCustomerService.Mock.On("UpdatePassword", customer.Id, "password").Return(nil)
+		mockTwoFactorService.Mock.On("IsEnabled", customer.Id).Return(true, nil)
+		mockLoginChallengeRepository.Mock.On("Create", mock.Anything).Return(nil)
+
+		login, err := authService.Login(req.CustomerRequest{Username: customer.Username, Password: "password", DeviceName: "Pixel 8"}, req.ClientInfo{Ip: "127.0.0.1", DeviceName: "Pixel 8"})
+		assert.Nil(t, err)
+		assert.True(t, login.TwoFactorRequired)
+		assert.Empty(t, login.AccessToken)
+		assert.Empty(t, login.RefreshToken)
+
+		stored := mockLoginChallengeRepository.Mock.Calls[0].Arguments.Get(0).(entity.LoginChallenge)
+		assert.Equal(t, hashSecretToken(login.ChallengeToken), stored.TokenHash)
+		assert.Equal(t, customer.Id, stored.CustomerId)
+		assert.Equal(t, "Pixel 8", stored.DeviceName)
+		mockSessionService.Mock.AssertNotCalled(t, "StartSession", customer.Id, mock.Anything)
+	})
+
+	t.Run("ShouldIssueTokensForValidCode", func(t *testing.T) {
+		mockCustomerService := new(CustomerServiceMock)
+		mockSessionService := new(SessionServiceMock)
+		mockTwoFactorService := new(TwoFactorServiceMock)
+		mockLoginChallengeRepository := new(repository.LoginChallengeRepositoryMock)
+		mockLoginAttemptService := newLoginAttemptServiceMock()
+		authService := NewAuthService(mockCustomerService, mockSessionService, new(BlacklistServiceMock), new(repository.PasswordResetTokenRepositoryMock), new(NotifierMock), mockLoginAttemptService, mockTwoFactorService, mockLoginChallengeRepository)
+
+		mockLoginChallengeRepository.Mock.On("GetByTokenHash", hashSecretToken("challenge-token")).
+			Return(loginChallenge(0, time.Now().Add(time.Minute)), nil)
+		mockLoginChallengeRepository.Mock.On("Delete", "challenge-1").Return(nil)
+		mockCustomerService.Mock.On("GetCustomerByIdAuth", customer.Id).Return(customer, nil)
+		mockTwoFactorService.Mock.On("VerifyCode", customer.Id, "123456").Return(nil)
+		// The session is named after the device given on the password step
+		mockSessionService.Mock.On("StartSession", customer.Id, req.ClientInfo{Ip: "127.0.0.1", DeviceName: "Pixel 8"}).
+			Return(entity.Session{Id: "session-1"}, entity.RefreshToken{RefreshToken: "refresh-token-1"}, nil)
+
+		login, err := authService.LoginWithTwoFactor(req.LoginTwoFactorRequest{ChallengeToken: "challenge-token", Code: "123456"}, req.ClientInfo{Ip: "127.0.0.1"})
+		assert.Nil(t, err)
+		assert.NotEmpty(t, login.AccessToken)
+		assert.Equal(t, "refresh-token-1", login.RefreshToken)
+		mockLoginChallengeRepository.Mock.AssertCalled(t, "Delete", "challenge-1")
+		mockLoginAttemptService.Mock.AssertCalled(t, "RecordSuccess", customer.Username)
+	})
+
+	t.Run("ShouldCountWrongCodeAsFailedLogin", func(t *testing.T) {
+		mockCustomerService := new(CustomerServiceMock)
+		mockTwoFactorService := new(TwoFactorServiceMock)
+		mockLoginChallengeRepository := new(repository.LoginChallengeRepositoryMock)
+		mockLoginAttemptService := newLoginAttemptServiceMock()
+		authService := NewAuthService(mockCustomerService, new(SessionServiceMock), new(BlacklistServiceMock), new(repository.PasswordResetTokenRepositoryMock), new(NotifierMock), mockLoginAttemptService, mockTwoFactorService, mockLoginChallengeRepository)
+
+		mockLoginChallengeRepository.Mock.On("GetByTokenHash", hashSecretToken("challenge-token")).
+			Return(loginChallenge(0, time.Now().Add(time.Minute)), nil)
+		mockLoginChallengeRepository.Mock.On("Update", mock.Anything).Return(nil)
+		mockCustomerService.Mock.On("GetCustomerByIdAuth", customer.Id).Return(customer, nil)
+		mockTwoFactorService.Mock.On("VerifyCode", customer.Id, "000000").Return(errors.New(constants.TwoFactorCodeInvalidError))
+
+		_, err := authService.LoginWithTwoFactor(req.LoginTwoFactorRequest{ChallengeToken: "challenge-token", Code: "000000"}, req.ClientInfo{Ip: "127.0.0.1"})
+		assert.Equal(t, constants.TwoFactorCodeInvalidError, err.Error())
+		mockLoginAttemptService.Mock.AssertCalled(t, "RecordFailure", customer.Username, "127.0.0.1")
+
+		updated := mockLoginChallengeRepository.Mock.Calls[1].Arguments.Get(0).(entity.LoginChallenge)
+		assert.Equal(t, 1, updated.Attempts)
+	})
+
+	t.Run("ShouldThrowAwayChallengeAfterTooManyWrongCodes", func(t *testing.T) {
+		mockCustomerService := new(CustomerServiceMock)
+		mockTwoFactorService := new(TwoFactorServiceMock)
+		mockLoginChallengeRepository := new(repository.LoginChallengeRepositoryMock)
+		authService := NewAuthService(mockCustomerService, new(SessionServiceMock), new(BlacklistServiceMock), new(repository.PasswordResetTokenRepositoryMock), new(NotifierMock), newLoginAttemptServiceMock(), mockTwoFactorService, mockLoginChallengeRepository)
+
+		mockLoginChallengeRepository.Mock.On("GetByTokenHash", hashSecretToken("challenge-token")).
+			Return(loginChallenge(loginChallengeMaxAttempts-1, time.Now().Add(time.Minute)), nil)
+		mockLoginChallengeRepository.Mock.On("Delete", "challenge-1").Return(nil)
+		mockCustomerService.Mock.On("GetCustomerByIdAuth", customer.Id).Return(customer, nil)
+		mockTwoFactorService.Mock.On("VerifyCode", customer.Id, "000000").Return(errors.New(constants.TwoFactorCodeInvalidError))
+
+		_, err := authService.LoginWithTwoFactor(req.LoginTwoFactorRequest{ChallengeToken: "challenge-token", Code: "000000"}, req.ClientInfo{Ip: "127.0.0.1"})
+		assert.Equal(t, constants.LoginChallengeInvalidError, err.Error())
+		mockLoginChallengeRepository.Mock.AssertCalled(t, "Delete", "challenge-1")
+	})
+
+	t.Run("ShouldRefuseExpiredChallenge", func(t *testing.T) {
+		mockTwoFactorService := new(TwoFactorServiceMock)
+		mockLoginChallengeRepository := new(repository.LoginChallengeRepositoryMock)
+		authService := NewAuthService(new(CustomerServiceMock), new(SessionServiceMock), new(BlacklistServiceMock), new(repository.PasswordResetTokenRepositoryMock), new(NotifierMock), newLoginAttemptServiceMock(), mockTwoFactorService, mockLoginChallengeRepository)
+
+		mockLoginChallengeRepository.Mock.On("GetByTokenHash", hashSecretToken("challenge-token")).
+			Return(loginChallenge(0, time.Now().Add(-time.Minute)), nil)
+		mockLoginChallengeRepository.Mock.On("Delete", "challenge-1").Return(nil)
+
+		_, err := authService.LoginWithTwoFactor(req.LoginTwoFactorRequest{ChallengeToken: "challenge-token", Code: "123456"}, req.ClientInfo{Ip: "127.0.0.1"})
+		assert.Equal(t, constants.LoginChallengeInvalidError, err.Error())
+		mockTwoFactorService.Mock.AssertNotCalled(t, "VerifyCode", customer.Id, "123456")
+	})
 }
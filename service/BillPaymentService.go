@@ -0,0 +1,217 @@
+package service
+
+import (
+	"PaymentAPI/constants"
+	req "PaymentAPI/dto/request"
+	"PaymentAPI/entity"
+	"PaymentAPI/enums"
+	"PaymentAPI/repository"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"strings"
+	"time"
+)
+
+// billerAccountPrefix names the external account a bill payment is paid to, it is not a wallet of this system
+const billerAccountPrefix = "BILLER_"
+
+type BillPaymentService interface {
+	GetProducts(category string) ([]entity.BillerProduct, error)
+	CreateBillPayment(request req.CreateBillPaymentRequest) (entity.BillPayment, error)
+	GetBillPaymentById(id string) (entity.BillPayment, error)
+	RefundFailedBillPayments() error
+}
+
+type billPaymentService struct {
+	billerProductRepository repository.BillerProductRepository
+	billPaymentRepository   repository.BillPaymentRepository
+	transactionService      TransactionService
+	biller                  Biller
+	eventBus                EventBus
+}
+
+// NewBillPaymentService creates a new instance of BillPaymentService
+func NewBillPaymentService(billerProductRepository repository.BillerProductRepository, billPaymentRepository repository.BillPaymentRepository, transactionService TransactionService, biller Biller, eventBus EventBus) BillPaymentService {
+	return &billPaymentService{billerProductRepository, billPaymentRepository, transactionService, biller, eventBus}
+}
+
+// GetProducts returns the active products of the catalog, optionally only those of one category
+func (b *billPaymentService) GetProducts(category string) ([]entity.BillerProduct, error) {
+	products, err := b.billerProductRepository.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := []entity.BillerProduct{}
+	for _, product := range products {
+		if !product.Active {
+			continue
+		}
+		if category != "" && !strings.EqualFold(string(product.Category), category) {
+			continue
+		}
+		filtered = append(filtered, product)
+	}
+	return filtered, nil
+}
+
+// CreateBillPayment debits the wallet for the product price and fee, then asks the biller to deliver the product.
+// When the biller fails the payment is refunded, a refund that fails is retried by RefundFailedBillPayments.
+func (b *billPaymentService) CreateBillPayment(request req.CreateBillPaymentRequest) (entity.BillPayment, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"walletId":    request.WalletId,
+		"productCode": request.ProductCode,
+	})
+
+	logger.Info("Creating new bill payment")
+
+	product, err := b.billerProductRepository.GetByCode(request.ProductCode)
+	if err != nil {
+		logger.Error("Failed to retrieve biller product", err)
+		return entity.BillPayment{}, err
+	}
+	if !product.Active {
+		return entity.BillPayment{}, errors.New(constants.BillerProductInactiveError)
+	}
+
+	customerNumber := strings.TrimSpace(request.CustomerNumber)
+	if !isDigits(customerNumber) {
+		return entity.BillPayment{}, errors.New(constants.BillerCustomerNumberError)
+	}
+
+	billPayment := entity.BillPayment{
+		Id:             uuid.New().String(),
+		WalletId:       request.WalletId,
+		ProductCode:    product.Code,
+		BillerCode:     product.BillerCode,
+		CustomerNumber: customerNumber,
+		Price:          product.Price,
+		Fee:            product.Fee,
+		TotalAmount:    product.Price + product.Fee,
+		Status:         enums.BILL_PAYMENT_PENDING,
+		CreatedAt:      time.Now().Format(time.RFC3339),
+	}
+
+	// Debit the wallet before calling the biller, so a product is never delivered without being paid
+	transaction, err := b.transactionService.CreateWithdrawal(req.CreateWithdrawalRequest{
+		FromWalletId: billPayment.WalletId,
+		ToAccount:    billerAccountPrefix + product.BillerCode,
+		Amount:       billPayment.TotalAmount,
+		ExternalId:   billPayment.Id,
+		Message:      product.Name + " " + customerNumber,
+		StepUp:       request.StepUp,
+	})
+	if err != nil {
+		logger.Error("Failed to debit wallet for bill payment", err)
+		return entity.BillPayment{}, err
+	}
+	billPayment.TransactionId = transaction.Id
+
+	if err := b.billPaymentRepository.Create(billPayment); err != nil {
+		logger.Error("Failed to create bill payment", err)
+		b.refund(billPayment)
+		return entity.BillPayment{}, err
+	}
+
+	result, err := b.biller.Purchase(BillerPurchaseRequest{
+		Reference:      billPayment.Id,
+		BillerCode:     product.BillerCode,
+		ProductCode:    product.Code,
+		Category:       product.Category,
+		CustomerNumber: customerNumber,
+		Price:          product.Price,
+	})
+	if err != nil {
+		logger.Error("Biller failed to deliver the product", err)
+		billPayment.Status = enums.BILL_PAYMENT_FAILED
+		billPayment.FailureReason = err.Error()
+		if err := b.billPaymentRepository.Update(billPayment); err != nil {
+			logger.Error("Failed to update bill payment", err)
+		}
+		return b.refund(billPayment), nil
+	}
+
+	billPayment.Status = enums.BILL_PAYMENT_SUCCESS
+	billPayment.SerialNumber = result.SerialNumber
+	billPayment.Voucher = result.Voucher
+	billPayment.CompletedAt = time.Now().Format(time.RFC3339)
+	if err := b.billPaymentRepository.Update(billPayment); err != nil {
+		logger.Error("Failed to update bill payment", err)
+		return entity.BillPayment{}, err
+	}
+
+	logger.WithFields(logrus.Fields{"billPaymentId": billPayment.Id}).Info("Bill payment completed successfully")
+	return billPayment, nil
+}
+
+// GetBillPaymentById retrieves a bill payment by its ID
+func (b *billPaymentService) GetBillPaymentById(id string) (entity.BillPayment, error) {
+	return b.billPaymentRepository.GetById(id)
+}
+
+// RefundFailedBillPayments retries the refund of every failed bill payment that was not refunded yet
+func (b *billPaymentService) RefundFailedBillPayments() error {
+	billPayments, err := b.billPaymentRepository.GetAll()
+	if err != nil {
+		return err
+	}
+
+	for _, billPayment := range billPayments {
+		if billPayment.Status == enums.BILL_PAYMENT_FAILED {
+			b.refund(billPayment)
+		}
+	}
+	return nil
+}
+
+// refund credits the paid amount back to the wallet. The deposit is keyed by the bill payment ID,
+// so retrying a refund that was credited but not recorded does not credit the wallet twice.
+func (b *billPaymentService) refund(billPayment entity.BillPayment) entity.BillPayment {
+	logger := logrus.WithFields(logrus.Fields{
+		"billPaymentId": billPayment.Id,
+		"walletId":      billPayment.WalletId,
+		"amount":        billPayment.TotalAmount,
+	})
+
+	transaction, _, err := b.transactionService.CreateDeposit(req.CreateDepositRequest{
+		FromAccount: billerAccountPrefix + billPayment.BillerCode,
+		ToWalletId:  billPayment.WalletId,
+		Amount:      billPayment.TotalAmount,
+		ExternalId:  billPayment.Id,
+		Message:     "Refund " + billPayment.ProductCode + " " + billPayment.CustomerNumber,
+		Refund:      true,
+	})
+	if err != nil && err.Error() == constants.WalletReceiverClosedError {
+		// A closed wallet is never reopened, retrying would fail forever. The payment is left for an operator to
+		// refund by other means.
+		logger.Error("Failed to refund bill payment, the wallet is closed", err)
+		billPayment.Status = enums.BILL_PAYMENT_REFUND_FAILED
+		billPayment.CompletedAt = time.Now().Format(time.RFC3339)
+		if err := b.billPaymentRepository.Update(billPayment); err != nil {
+			logger.Error("Failed to record bill payment refund failure", err)
+		}
+		return billPayment
+	}
+	if err != nil {
+		logger.Error("Failed to refund bill payment, it will be retried", err)
+		return billPayment
+	}
+
+	// Recorded again when a retry finds the refund already credited, events are delivered at least once anyway
+	if err := b.eventBus.Publish(enums.EVENT_TRANSACTION_REFUNDED, []string{billPayment.WalletId}, transaction); err != nil {
+		logger.Error("Failed to record bill payment refund event, it will be retried", err)
+		return billPayment
+	}
+
+	billPayment.Status = enums.BILL_PAYMENT_REFUNDED
+	billPayment.RefundTransactionId = transaction.Id
+	billPayment.CompletedAt = time.Now().Format(time.RFC3339)
+	if err := b.billPaymentRepository.Update(billPayment); err != nil {
+		logger.Error("Failed to record bill payment refund", err)
+		return billPayment
+	}
+
+	logger.Info("Bill payment refunded")
+	return billPayment
+}
@@ -0,0 +1,197 @@
+package service
+
+import (
+	"PaymentAPI/constants"
+	req "PaymentAPI/dto/request"
+	"PaymentAPI/entity"
+	"PaymentAPI/enums"
+	"PaymentAPI/repository"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"regexp"
+	"testing"
+)
+
+type billPaymentTest struct {
+	mockBillerProductRepository *repository.BillerProductRepositoryMock
+	mockBillPaymentRepository   *repository.BillPaymentRepositoryMock
+	mockTransactionService      *TransactionServiceMock
+	mockBiller                  *BillerMock
+	service                     BillPaymentService
+}
+
+func setupBillPaymentTest() billPaymentTest {
+	mockBillerProductRepository := new(repository.BillerProductRepositoryMock)
+	mockBillPaymentRepository := new(repository.BillPaymentRepositoryMock)
+	mockTransactionService := new(TransactionServiceMock)
+	mockBiller := new(BillerMock)
+	return billPaymentTest{
+		mockBillerProductRepository: mockBillerProductRepository,
+		mockBillPaymentRepository:   mockBillPaymentRepository,
+		mockTransactionService:      mockTransactionService,
+		mockBiller:                  mockBiller,
+		service:                     NewBillPaymentService(mockBillerProductRepository, mockBillPaymentRepository, mockTransactionService, mockBiller, newEventBusMock()),
+	}
+}
+
+var pulsaProduct = entity.BillerProduct{
+	Code:       "TSEL10",
+	BillerCode: "TELKOMSEL",
+	Category:   enums.BILLER_PULSA,
+	Name:       "Pulsa Telkomsel 10.000",
+	Price:      10000,
+	Fee:        1500,
+	Active:     true,
+}
+
+func TestGetProducts(t *testing.T) {
+	test := setupBillPaymentTest()
+	inactive := pulsaProduct
+	inactive.Code = "XL10"
+	inactive.Active = false
+	electricity := pulsaProduct
+	electricity.Code = "PLN20"
+	electricity.Category = enums.BILLER_ELECTRICITY
+
+	test.mockBillerProductRepository.Mock.On("GetAll").Return([]entity.BillerProduct{pulsaProduct, inactive, electricity}, nil)
+
+	products, err := test.service.GetProducts("pulsa")
+	assert.Nil(t, err)
+	assert.Equal(t, []entity.BillerProduct{pulsaProduct}, products)
+}
+
+func TestCreateBillPayment(t *testing.T) {
+	request := req.CreateBillPaymentRequest{WalletId: "wallet-1", ProductCode: "TSEL10", CustomerNumber: "081234567890"}
+
+	t.Run("ShouldDebitTotalAndDeliverProduct", func(t *testing.T) {
+		test := setupBillPaymentTest()
+		test.mockBillerProductRepository.Mock.On("GetByCode", "TSEL10").Return(pulsaProduct, nil)
+		test.mockTransactionService.On("CreateWithdrawal", mock.MatchedBy(func(withdrawal req.CreateWithdrawalRequest) bool {
+			return withdrawal.FromWalletId == "wallet-1" && withdrawal.ToAccount == "BILLER_TELKOMSEL" && withdrawal.Amount == 11500
+		})).Return(entity.Transaction{Id: "transaction-1"}, nil)
+		test.mockBillPaymentRepository.Mock.On("Create", mock.Anything).Return(nil)
+		test.mockBiller.On("Purchase", mock.Anything).Return(BillerPurchaseResult{SerialNumber: "1234567890123456"}, nil)
+		test.mockBillPaymentRepository.Mock.On("Update", mock.Anything).Return(nil)
+
+		billPayment, err := test.service.CreateBillPayment(request)
+		assert.Nil(t, err)
+		assert.Equal(t, enums.BILL_PAYMENT_SUCCESS, billPayment.Status)
+		assert.Equal(t, "1234567890123456", billPayment.SerialNumber)
+		assert.Equal(t, "transaction-1", billPayment.TransactionId)
+		test.mockTransactionService.AssertNotCalled(t, "CreateDeposit", mock.Anything)
+	})
+
+	t.Run("ShouldRefundWhenBillerFails", func(t *testing.T) {
+		test := setupBillPaymentTest()
+		test.mockBillerProductRepository.Mock.On("GetByCode", "TSEL10").Return(pulsaProduct, nil)
+		test.mockTransactionService.On("CreateWithdrawal", mock.Anything).Return(entity.Transaction{Id: "transaction-1"}, nil)
+		test.mockBillPaymentRepository.Mock.On("Create", mock.Anything).Return(nil)
+		test.mockBiller.On("Purchase", mock.Anything).Return(BillerPurchaseResult{}, errors.New(constants.BillerUnavailableError))
+		test.mockBillPaymentRepository.Mock.On("Update", mock.Anything).Return(nil)
+		test.mockTransactionService.On("CreateDeposit", mock.MatchedBy(func(deposit req.CreateDepositRequest) bool {
+			return deposit.FromAccount == "BILLER_TELKOMSEL" && deposit.ToWalletId == "wallet-1" && deposit.Amount == 11500
+		})).Return(entity.Transaction{Id: "transaction-2"}, true, nil)
+
+		billPayment, err := test.service.CreateBillPayment(request)
+		assert.Nil(t, err)
+		assert.Equal(t, enums.BILL_PAYMENT_REFUNDED, billPayment.Status)
+		assert.Equal(t, constants.BillerUnavailableError, billPayment.FailureReason)
+		assert.Equal(t, "transaction-2", billPayment.RefundTransactionId)
+	})
+
+	t.Run("ShouldKeepFailedWhenRefundFails", func(t *testing.T) {
+		test := setupBillPaymentTest()
+		test.mockBillerProductRepository.Mock.On("GetByCode", "TSEL10").Return(pulsaProduct, nil)
+		test.mockTransactionService.On("CreateWithdrawal", mock.Anything).Return(entity.Transaction{Id: "transaction-1"}, nil)
+		test.mockBillPaymentRepository.Mock.On("Create", mock.Anything).Return(nil)
+		test.mockBiller.On("Purchase", mock.Anything).Return(BillerPurchaseResult{}, errors.New(constants.BillerUnavailableError))
+		test.mockBillPaymentRepository.Mock.On("Update", mock.Anything).Return(nil)
+		test.mockTransactionService.On("CreateDeposit", mock.Anything).Return(entity.Transaction{}, false, errors.New("storage unavailable"))
+
+		billPayment, err := test.service.CreateBillPayment(request)
+		assert.Nil(t, err)
+		assert.Equal(t, enums.BILL_PAYMENT_FAILED, billPayment.Status)
+	})
+
+	t.Run("ShouldStopRetryingRefundToClosedWallet", func(t *testing.T) {
+		test := setupBillPaymentTest()
+		test.mockBillerProductRepository.Mock.On("GetByCode", "TSEL10").Return(pulsaProduct, nil)
+		test.mockTransactionService.On("CreateWithdrawal", mock.Anything).Return(entity.Transaction{Id: "transaction-1"}, nil)
+		test.mockBillPaymentRepository.Mock.On("Create", mock.Anything).Return(nil)
+		test.mockBiller.On("Purchase", mock.Anything).Return(BillerPurchaseResult{}, errors.New(constants.BillerUnavailableError))
+		test.mockBillPaymentRepository.Mock.On("Update", mock.Anything).Return(nil)
+		test.mockTransactionService.On("CreateDeposit", mock.MatchedBy(func(deposit req.CreateDepositRequest) bool {
+			return deposit.Refund
+		})).Return(entity.Transaction{}, false, errors.New(constants.WalletReceiverClosedError))
+
+		billPayment, err := test.service.CreateBillPayment(request)
+		assert.Nil(t, err)
+		assert.Equal(t, enums.BILL_PAYMENT_REFUND_FAILED, billPayment.Status)
+		test.mockBillPaymentRepository.Mock.AssertCalled(t, "Update", mock.MatchedBy(func(billPayment entity.BillPayment) bool {
+			return billPayment.Status == enums.BILL_PAYMENT_REFUND_FAILED
+		}))
+	})
+
+	t.Run("ShouldNotCallBillerWhenDebitFails", func(t *testing.T) {
+		test := setupBillPaymentTest()
+		test.mockBillerProductRepository.Mock.On("GetByCode", "TSEL10").Return(pulsaProduct, nil)
+		test.mockTransactionService.On("CreateWithdrawal", mock.Anything).Return(entity.Transaction{}, errors.New(constants.TransactionInsufficientError))
+
+		_, err := test.service.CreateBillPayment(request)
+		assert.Equal(t, constants.TransactionInsufficientError, err.Error())
+		test.mockBiller.AssertNotCalled(t, "Purchase", mock.Anything)
+		test.mockBillPaymentRepository.Mock.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	t.Run("ShouldRejectInactiveProduct", func(t *testing.T) {
+		test := setupBillPaymentTest()
+		inactive := pulsaProduct
+		inactive.Active = false
+		test.mockBillerProductRepository.Mock.On("GetByCode", "TSEL10").Return(inactive, nil)
+
+		_, err := test.service.CreateBillPayment(request)
+		assert.Equal(t, constants.BillerProductInactiveError, err.Error())
+		test.mockTransactionService.AssertNotCalled(t, "CreateWithdrawal", mock.Anything)
+	})
+}
+
+func TestRefundFailedBillPayments(t *testing.T) {
+	test := setupBillPaymentTest()
+	failed := entity.BillPayment{Id: "bill-1", WalletId: "wallet-1", BillerCode: "PLN", TotalAmount: 22500, Status: enums.BILL_PAYMENT_FAILED}
+	succeeded := entity.BillPayment{Id: "bill-2", WalletId: "wallet-1", BillerCode: "PLN", TotalAmount: 22500, Status: enums.BILL_PAYMENT_SUCCESS}
+
+	test.mockBillPaymentRepository.Mock.On("GetAll").Return([]entity.BillPayment{failed, succeeded}, nil)
+	test.mockTransactionService.On("CreateDeposit", mock.MatchedBy(func(deposit req.CreateDepositRequest) bool {
+		return deposit.ExternalId == "bill-1"
+	})).Return(entity.Transaction{Id: "transaction-2"}, true, nil)
+	test.mockBillPaymentRepository.Mock.On("Update", mock.MatchedBy(func(billPayment entity.BillPayment) bool {
+		return billPayment.Id == "bill-1" && billPayment.Status == enums.BILL_PAYMENT_REFUNDED
+	})).Return(nil)
+
+	err := test.service.RefundFailedBillPayments()
+	assert.Nil(t, err)
+	test.mockTransactionService.AssertNumberOfCalls(t, "CreateDeposit", 1)
+	test.mockBillPaymentRepository.Mock.AssertExpectations(t)
+}
+
+func TestLocalBillerPurchase(t *testing.T) {
+	biller := NewLocalBiller()
+
+	t.Run("ShouldReturnTokenForElectricity", func(t *testing.T) {
+		result, err := biller.Purchase(BillerPurchaseRequest{Category: enums.BILLER_ELECTRICITY, CustomerNumber: "53123456789"})
+		assert.Nil(t, err)
+		assert.Len(t, result.SerialNumber, 16)
+		assert.Regexp(t, regexp.MustCompile(`^\d{4}(-\d{4}){4}$`), result.Voucher)
+	})
+
+	t.Run("ShouldRejectInvalidCustomerNumber", func(t *testing.T) {
+		_, err := biller.Purchase(BillerPurchaseRequest{Category: enums.BILLER_PULSA, CustomerNumber: "0812"})
+		assert.Equal(t, constants.BillerCustomerNumberError, err.Error())
+	})
+
+	t.Run("ShouldSimulateOutage", func(t *testing.T) {
+		_, err := biller.Purchase(BillerPurchaseRequest{Category: enums.BILLER_PULSA, CustomerNumber: "081234560000"})
+		assert.Equal(t, constants.BillerUnavailableError, err.Error())
+	})
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"PaymentAPI/constants"
+	"PaymentAPI/enums"
+	"crypto/rand"
+	"errors"
+	"github.com/sirupsen/logrus"
+	"math/big"
+	"strings"
+)
+
+// localBillerOutageSuffix makes the local biller fail for customer numbers ending with it, to exercise refunds
+const localBillerOutageSuffix = "0000"
+
+type BillerPurchaseRequest struct {
+	Reference      string
+	BillerCode     string
+	ProductCode    string
+	Category       enums.BillerCategory
+	CustomerNumber string
+	Price          float64
+}
+
+type BillerPurchaseResult struct {
+	SerialNumber string
+	Voucher      string
+}
+
+// Biller is the provider that delivers a purchased product, such as a mobile operator or the electricity company
+type Biller interface {
+	Purchase(request BillerPurchaseRequest) (BillerPurchaseResult, error)
+}
+
+type localBiller struct{}
+
+// NewLocalBiller creates a Biller that simulates every biller locally without calling an external provider
+func NewLocalBiller() Biller {
+	return &localBiller{}
+}
+
+// Purchase validates the customer number and returns a serial number, plus a token voucher for electricity.
+// Customer numbers ending with 0000 simulate a biller outage.
+func (l *localBiller) Purchase(request BillerPurchaseRequest) (BillerPurchaseResult, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"reference":   request.Reference,
+		"billerCode":  request.BillerCode,
+		"productCode": request.ProductCode,
+	})
+
+	if !isDigits(request.CustomerNumber) || len(request.CustomerNumber) < 8 || len(request.CustomerNumber) > 16 {
+		logger.Warn("Biller rejected customer number")
+		return BillerPurchaseResult{}, errors.New(constants.BillerCustomerNumberError)
+	}
+	if strings.HasSuffix(request.CustomerNumber, localBillerOutageSuffix) {
+		logger.Warn("Biller is unavailable")
+		return BillerPurchaseResult{}, errors.New(constants.BillerUnavailableError)
+	}
+
+	serialNumber, err := randomDigits(16)
+	if err != nil {
+		return BillerPurchaseResult{}, err
+	}
+	result := BillerPurchaseResult{SerialNumber: serialNumber}
+
+	// An electricity token is entered on the meter in groups of four digits
+	if request.Category == enums.BILLER_ELECTRICITY {
+		token, err := randomDigits(20)
+		if err != nil {
+			return BillerPurchaseResult{}, err
+		}
+		groups := make([]string, 0, 5)
+		for i := 0; i < len(token); i += 4 {
+			groups = append(groups, token[i:i+4])
+		}
+		result.Voucher = strings.Join(groups, "-")
+	}
+
+	logger.Info("Biller purchase delivered")
+	return result, nil
+}
+
+func isDigits(value string) bool {
+	if value == "" {
+		return false
+	}
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func randomDigits(length int) (string, error) {
+	digits := make([]byte, length)
+	for i := range digits {
+		n, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			return "", err
+		}
+		digits[i] = byte('0' + n.Int64())
+	}
+	return string(digits), nil
+}
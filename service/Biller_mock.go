@@ -0,0 +1,12 @@
+package service
+
+import "github.com/stretchr/testify/mock"
+
+type BillerMock struct {
+	mock.Mock
+}
+
+func (b *BillerMock) Purchase(request BillerPurchaseRequest) (BillerPurchaseResult, error) {
+	args := b.Called(request)
+	return args.Get(0).(BillerPurchaseResult), args.Error(1)
+}
@@ -1,95 +1,213 @@
 package service
 
 import (
+	"PaymentAPI/config"
+	"PaymentAPI/entity"
 	"PaymentAPI/logger" // Import the logger package
 	"PaymentAPI/repository"
+	"sync"
+	"time"
+
 	"github.com/sirupsen/logrus"
 )
 
 type BlacklistService interface {
-	IsBlacklisted(accessToken string) (bool, error)
-	BlacklistToken(accessToken string) error
+	IsRevoked(tokenId string, customerId string, issuedAt time.Time) (bool, error)
+	BlacklistToken(tokenId string, customerId string, expiresAt time.Time) error
+	RevokeTokensIssuedBefore(customerId string, issuedBefore time.Time) error
+	DeleteExpired() error
 }
 
 type blacklistService struct {
-	blacklistRepository repository.BlacklistRepository
+	blacklistRepository      repository.BlacklistRepository
+	tokenWatermarkRepository repository.TokenWatermarkRepository
+
+	// blacklist and watermarks index the stored revocations by JWT ID and by customer, they are loaded on first use
+	// as every authenticated request is checked against them
+	blacklist  map[string]time.Time
+	watermarks map[string]time.Time
+	loaded     bool
+	lock       sync.Mutex
 }
 
 // Constructor for BlacklistService
-func NewBlacklistService(blacklistRepository repository.BlacklistRepository) BlacklistService {
-	return &blacklistService{blacklistRepository: blacklistRepository}
+func NewBlacklistService(blacklistRepository repository.BlacklistRepository, tokenWatermarkRepository repository.TokenWatermarkRepository) BlacklistService {
+	return &blacklistService{
+		blacklistRepository:      blacklistRepository,
+		tokenWatermarkRepository: tokenWatermarkRepository,
+	}
 }
 
-// BlacklistToken checks if the token is blacklisted, and if not, adds it to the blacklist
-func (b *blacklistService) BlacklistToken(accessToken string) error {
+// BlacklistToken revokes a single access token by its JWT ID until it expires, as on logout
+func (b *blacklistService) BlacklistToken(tokenId string, customerId string, expiresAt time.Time) error {
 	logger.LogInfo("Attempting to blacklist token", logrus.Fields{
-		"accessToken": accessToken,
+		"tokenId":    tokenId,
+		"customerId": customerId,
 	})
 
-	// Check if the token is already blacklisted
-	isBlacklisted, err := b.IsBlacklisted(accessToken)
-	if err != nil {
-		logger.LogError("Failed to check if token is blacklisted", logrus.Fields{
-			"accessToken": accessToken,
-			"error":       err.Error(),
-		})
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if err := b.load(); err != nil {
 		return err
 	}
 
-	// If the token is already blacklisted, return nil (no action needed)
-	if isBlacklisted {
+	// If the token is already blacklisted, no action is needed
+	if _, blacklisted := b.blacklist[tokenId]; blacklisted {
 		logger.LogInfo("Token is already blacklisted", logrus.Fields{
-			"accessToken": accessToken,
+			"tokenId": tokenId,
 		})
 		return nil
 	}
 
-	// Add the token to the blacklist repository
-	err = b.blacklistRepository.CreateBlacklist(accessToken)
+	err := b.blacklistRepository.CreateBlacklist(entity.Blacklist{
+		TokenId:    tokenId,
+		CustomerId: customerId,
+		ExpiresAt:  expiresAt.Format(time.RFC3339),
+	})
 	if err != nil {
 		logger.LogError("Failed to add token to blacklist", logrus.Fields{
-			"accessToken": accessToken,
-			"error":       err.Error(),
+			"tokenId": tokenId,
+			"error":   err.Error(),
 		})
 		return err
 	}
+	b.blacklist[tokenId] = expiresAt
 
 	logger.LogInfo("Successfully blacklisted token", logrus.Fields{
-		"accessToken": accessToken,
+		"tokenId": tokenId,
 	})
-
 	return nil
 }
 
-// IsBlacklisted checks if the token is blacklisted
-func (b *blacklistService) IsBlacklisted(accessToken string) (bool, error) {
-	logger.LogInfo("Checking if token is blacklisted", logrus.Fields{
-		"accessToken": accessToken,
+// IsRevoked checks if the access token is blacklisted, or was issued before the watermark of its customer
+func (b *blacklistService) IsRevoked(tokenId string, customerId string, issuedAt time.Time) (bool, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if err := b.load(); err != nil {
+		return false, err
+	}
+
+	if _, blacklisted := b.blacklist[tokenId]; blacklisted {
+		logger.LogInfo("Token is blacklisted", logrus.Fields{
+			"tokenId": tokenId,
+		})
+		return true, nil
+	}
+
+	// Compared in whole seconds like the iat of the token, a token issued in the same second as the watermark may have
+	// been issued before it, so it is revoked too
+	if issuedBefore, ok := b.watermarks[customerId]; ok && issuedAt.Unix() <= issuedBefore.Unix() {
+		logger.LogInfo("Token was issued before the watermark of the customer", logrus.Fields{
+			"tokenId":      tokenId,
+			"customerId":   customerId,
+			"issuedBefore": issuedBefore.Format(time.RFC3339),
+		})
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// RevokeTokensIssuedBefore revokes every access token of the customer issued before the time, as on logout from all
+// devices, and every token issued in the same second. The watermark is kept in whole seconds and is never moved back.
+func (b *blacklistService) RevokeTokensIssuedBefore(customerId string, issuedBefore time.Time) error {
+	issuedBefore = issuedBefore.Truncate(time.Second)
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if err := b.load(); err != nil {
+		return err
+	}
+
+	if current, ok := b.watermarks[customerId]; ok && !issuedBefore.After(current) {
+		return nil
+	}
+
+	err := b.tokenWatermarkRepository.Save(entity.TokenWatermark{
+		CustomerId:   customerId,
+		IssuedBefore: issuedBefore.Format(time.RFC3339),
 	})
+	if err != nil {
+		logger.LogError("Failed to save token watermark", logrus.Fields{
+			"customerId": customerId,
+			"error":      err.Error(),
+		})
+		return err
+	}
+	b.watermarks[customerId] = issuedBefore
+
+	logger.LogInfo("Successfully revoked access tokens of customer", logrus.Fields{
+		"customerId":   customerId,
+		"issuedBefore": issuedBefore.Format(time.RFC3339),
+	})
+	return nil
+}
+
+// DeleteExpired deletes the blacklist entries of access tokens that have expired, they are refused anyway
+func (b *blacklistService) DeleteExpired() error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if err := b.load(); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if _, err := b.blacklistRepository.DeleteExpired(now); err != nil {
+		return err
+	}
+	for tokenId, expiresAt := range b.blacklist {
+		if !now.Before(expiresAt) {
+			delete(b.blacklist, tokenId)
+		}
+	}
+	return nil
+}
+
+// load builds the indexes from the repositories the first time they are needed, the caller must hold the lock
+func (b *blacklistService) load() error {
+	if b.loaded {
+		return nil
+	}
 
-	// Retrieve all blacklisted tokens from the repository
 	blacklists, err := b.blacklistRepository.GetAll()
 	if err != nil {
 		logger.LogError("Failed to retrieve blacklists", logrus.Fields{
 			"error": err.Error(),
 		})
-		return false, err
+		return err
+	}
+	watermarks, err := b.tokenWatermarkRepository.GetAll()
+	if err != nil {
+		logger.LogError("Failed to retrieve token watermarks", logrus.Fields{
+			"error": err.Error(),
+		})
+		return err
 	}
 
-	// Check if the access token is in the blacklist
+	b.blacklist = make(map[string]time.Time, len(blacklists))
 	for _, blacklist := range blacklists {
-		if blacklist.AccessToken == accessToken {
-			logger.LogInfo("Token is blacklisted", logrus.Fields{
-				"accessToken": accessToken,
-			})
-			return true, nil
+		// An entry without a valid expiry is kept for as long as any access token issued by now could be valid
+		expiresAt, err := time.Parse(time.RFC3339, blacklist.ExpiresAt)
+		if err != nil {
+			expiresAt = time.Now().Add(config.LoginExpirationDuration)
 		}
+		b.blacklist[blacklist.TokenId] = expiresAt
 	}
 
-	// Token is not blacklisted
-	logger.LogInfo("Token is not blacklisted", logrus.Fields{
-		"accessToken": accessToken,
-	})
+	b.watermarks = make(map[string]time.Time, len(watermarks))
+	for _, watermark := range watermarks {
+		// Parsing RFC 3339 accepts the fractional seconds of watermarks stored before they were kept in whole seconds
+		issuedBefore, err := time.Parse(time.RFC3339, watermark.IssuedBefore)
+		if err != nil {
+			continue
+		}
+		b.watermarks[watermark.CustomerId] = issuedBefore
+	}
 
-	return false, nil
+	b.loaded = true
+	return nil
 }
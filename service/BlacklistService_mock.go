@@ -2,18 +2,29 @@ package service
 
 import (
 	"github.com/stretchr/testify/mock"
+	"time"
 )
 
 type BlacklistServiceMock struct {
 	mock.Mock
 }
 
-func (b *BlacklistServiceMock) BlacklistToken(accessToken string) error {
-	args := b.Called(accessToken)
+func (b *BlacklistServiceMock) BlacklistToken(tokenId string, customerId string, expiresAt time.Time) error {
+	args := b.Called(tokenId, customerId, expiresAt)
 	return args.Error(0)
 }
 
-func (b *BlacklistServiceMock) IsBlacklisted(accessToken string) (bool, error) {
-	args := b.Called(accessToken)
+func (b *BlacklistServiceMock) IsRevoked(tokenId string, customerId string, issuedAt time.Time) (bool, error) {
+	args := b.Called(tokenId, customerId, issuedAt)
 	return args.Bool(0), args.Error(1)
 }
+
+func (b *BlacklistServiceMock) RevokeTokensIssuedBefore(customerId string, issuedBefore time.Time) error {
+	args := b.Called(customerId, issuedBefore)
+	return args.Error(0)
+}
+
+func (b *BlacklistServiceMock) DeleteExpired() error {
+	args := b.Called()
+	return args.Error(0)
+}
@@ -4,80 +4,172 @@ import (
 	"PaymentAPI/entity"
 	"PaymentAPI/repository"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"testing"
 	"time"
 )
 
-func TestIsBlacklisted(t *testing.T) {
-	t.Run("ShouldReturnTrue", func(t *testing.T) {
-		mockBlacklistRepository := new(repository.BlacklistRepositoryMock)
-		blacklistService := NewBlacklistService(mockBlacklistRepository)
+func newBlacklistServiceTest(blacklists []entity.Blacklist, watermarks []entity.TokenWatermark) (BlacklistService, *repository.BlacklistRepositoryMock, *repository.TokenWatermarkRepositoryMock) {
+	mockBlacklistRepository := new(repository.BlacklistRepositoryMock)
+	mockTokenWatermarkRepository := new(repository.TokenWatermarkRepositoryMock)
+	mockBlacklistRepository.Mock.On("GetAll").Return(blacklists, nil)
+	mockTokenWatermarkRepository.Mock.On("GetAll").Return(watermarks, nil)
+	return NewBlacklistService(mockBlacklistRepository, mockTokenWatermarkRepository), mockBlacklistRepository, mockTokenWatermarkRepository
+}
 
-		accessToken := "access-token-1"
-		blacklists := []entity.Blacklist{
-			{
-				AccessToken: "access-token-1",
-				ExpiresAt:   time.Now().Add(time.Duration(5) * time.Minute).Format(time.RFC3339),
-			},
-		}
+func TestIsRevoked(t *testing.T) {
+	now := time.Now()
+	blacklists := []entity.Blacklist{
+		{
+			TokenId:    "token-id-1",
+			CustomerId: "id-1",
+			ExpiresAt:  now.Add(time.Duration(5) * time.Minute).Format(time.RFC3339),
+		},
+	}
+	watermarks := []entity.TokenWatermark{
+		{CustomerId: "id-2", IssuedBefore: now.Format(time.RFC3339)},
+	}
+
+	t.Run("ShouldReturnTrueForBlacklistedToken", func(t *testing.T) {
+		blacklistService, _, _ := newBlacklistServiceTest(blacklists, watermarks)
+
+		revoked, err := blacklistService.IsRevoked("token-id-1", "id-1", now)
+		assert.Nil(t, err)
+		assert.True(t, revoked)
+	})
 
-		mockBlacklistRepository.Mock.On("GetAll").
-			Return(blacklists, nil)
+	t.Run("ShouldReturnTrueForTokenIssuedBeforeWatermark", func(t *testing.T) {
+		blacklistService, _, _ := newBlacklistServiceTest(blacklists, watermarks)
 
-		blacklisted, err := blacklistService.IsBlacklisted(accessToken)
+		revoked, err := blacklistService.IsRevoked("token-id-2", "id-2", now.Add(-time.Second))
 		assert.Nil(t, err)
-		assert.True(t, blacklisted)
+		assert.True(t, revoked)
 	})
 
-	t.Run("ShouldReturnFalse", func(t *testing.T) {
-		mockBlacklistRepository := new(repository.BlacklistRepositoryMock)
-		blacklistService := NewBlacklistService(mockBlacklistRepository)
+	t.Run("ShouldReturnTrueForTokenIssuedInSameSecondAsWatermark", func(t *testing.T) {
+		blacklistService, _, _ := newBlacklistServiceTest(blacklists, watermarks)
 
-		accessToken := "access-token-1"
+		// iat is in whole seconds, so the token may have been issued before the watermark within that second
+		revoked, err := blacklistService.IsRevoked("token-id-2", "id-2", now.Truncate(time.Second))
+		assert.Nil(t, err)
+		assert.True(t, revoked)
+	})
+
+	t.Run("ShouldReturnFalse", func(t *testing.T) {
+		blacklistService, mockBlacklistRepository, _ := newBlacklistServiceTest(blacklists, watermarks)
 
-		mockBlacklistRepository.Mock.On("GetAll").
-			Return([]entity.Blacklist{}, nil)
+		revoked, err := blacklistService.IsRevoked("token-id-2", "id-1", now.Add(-time.Second))
+		assert.Nil(t, err)
+		assert.False(t, revoked)
 
-		blacklisted, err := blacklistService.IsBlacklisted(accessToken)
+		// A token issued after the watermark of its customer is valid
+		revoked, err = blacklistService.IsRevoked("token-id-3", "id-2", now.Add(time.Second))
 		assert.Nil(t, err)
-		assert.False(t, blacklisted)
+		assert.False(t, revoked)
+
+		// The storage is read once, later checks use the index
+		mockBlacklistRepository.Mock.AssertNumberOfCalls(t, "GetAll", 1)
 	})
 }
 
 func TestBlacklistToken(t *testing.T) {
+	expiresAt := time.Now().Add(time.Duration(5) * time.Minute).Truncate(time.Second)
+
 	t.Run("ShouldBlacklistToken", func(t *testing.T) {
-		mockBlacklistRepository := new(repository.BlacklistRepositoryMock)
-		blacklistService := NewBlacklistService(mockBlacklistRepository)
+		blacklistService, mockBlacklistRepository, _ := newBlacklistServiceTest([]entity.Blacklist{}, []entity.TokenWatermark{})
+
+		mockBlacklistRepository.Mock.On("CreateBlacklist", entity.Blacklist{
+			TokenId:    "token-id-1",
+			CustomerId: "id-1",
+			ExpiresAt:  expiresAt.Format(time.RFC3339),
+		}).Return(nil)
+
+		err := blacklistService.BlacklistToken("token-id-1", "id-1", expiresAt)
+		assert.Nil(t, err)
+
+		revoked, err := blacklistService.IsRevoked("token-id-1", "id-1", time.Now())
+		assert.Nil(t, err)
+		assert.True(t, revoked)
+	})
+
+	t.Run("ShouldNotBlacklistTokenTwice", func(t *testing.T) {
+		blacklistService, mockBlacklistRepository, _ := newBlacklistServiceTest([]entity.Blacklist{
+			{TokenId: "token-id-1", CustomerId: "id-1", ExpiresAt: expiresAt.Format(time.RFC3339)},
+		}, []entity.TokenWatermark{})
 
-		accessToken := "access-token-1"
+		err := blacklistService.BlacklistToken("token-id-1", "id-1", expiresAt)
+		assert.Nil(t, err)
+		mockBlacklistRepository.Mock.AssertNotCalled(t, "CreateBlacklist", mock.Anything)
+	})
+}
+
+func TestRevokeTokensIssuedBefore(t *testing.T) {
+	now := time.Now()
+
+	t.Run("ShouldMoveWatermark", func(t *testing.T) {
+		blacklistService, _, mockTokenWatermarkRepository := newBlacklistServiceTest([]entity.Blacklist{}, []entity.TokenWatermark{
+			{CustomerId: "id-1", IssuedBefore: now.Add(-time.Hour).Format(time.RFC3339)},
+		})
 
-		mockBlacklistRepository.Mock.On("GetAll").
-			Return([]entity.Blacklist{}, nil)
+		mockTokenWatermarkRepository.Mock.On("Save", entity.TokenWatermark{
+			CustomerId:   "id-1",
+			IssuedBefore: now.Format(time.RFC3339),
+		}).Return(nil)
+
+		err := blacklistService.RevokeTokensIssuedBefore("id-1", now)
+		assert.Nil(t, err)
+
+		revoked, err := blacklistService.IsRevoked("token-id-1", "id-1", now.Add(-time.Minute))
+		assert.Nil(t, err)
+		assert.True(t, revoked)
 
-		mockBlacklistRepository.Mock.On("CreateBlacklist", accessToken).
-			Return(nil)
+		// A token refreshed in the same second as the logout does not slip past
+		revoked, err = blacklistService.IsRevoked("token-id-2", "id-1", now.Truncate(time.Second))
+		assert.Nil(t, err)
+		assert.True(t, revoked)
 
-		err := blacklistService.BlacklistToken(accessToken)
+		revoked, err = blacklistService.IsRevoked("token-id-3", "id-1", now.Add(time.Second))
 		assert.Nil(t, err)
+		assert.False(t, revoked)
 	})
 
-	t.Run("ShouldNotBlacklistToken", func(t *testing.T) {
-		mockBlacklistRepository := new(repository.BlacklistRepositoryMock)
-		blacklistService := NewBlacklistService(mockBlacklistRepository)
+	t.Run("ShouldLoadWatermarkWithFractionalSeconds", func(t *testing.T) {
+		blacklistService, _, _ := newBlacklistServiceTest([]entity.Blacklist{}, []entity.TokenWatermark{
+			{CustomerId: "id-1", IssuedBefore: now.Format(time.RFC3339Nano)},
+		})
 
-		accessToken := "access-token-1"
+		revoked, err := blacklistService.IsRevoked("token-id-1", "id-1", now.Add(-time.Second))
+		assert.Nil(t, err)
+		assert.True(t, revoked)
 
-		blacklists := []entity.Blacklist{
-			{
-				AccessToken: "access-token-1",
-				ExpiresAt:   time.Now().Add(time.Duration(5) * time.Minute).Format(time.RFC3339),
-			},
-		}
+		revoked, err = blacklistService.IsRevoked("token-id-2", "id-1", now.Add(time.Second))
+		assert.Nil(t, err)
+		assert.False(t, revoked)
+	})
 
-		mockBlacklistRepository.Mock.On("GetAll").
-			Return(blacklists, nil)
+	t.Run("ShouldNotMoveWatermarkBack", func(t *testing.T) {
+		blacklistService, _, mockTokenWatermarkRepository := newBlacklistServiceTest([]entity.Blacklist{}, []entity.TokenWatermark{
+			{CustomerId: "id-1", IssuedBefore: now.Format(time.RFC3339)},
+		})
 
-		err := blacklistService.BlacklistToken(accessToken)
+		err := blacklistService.RevokeTokensIssuedBefore("id-1", now.Add(-time.Hour))
 		assert.Nil(t, err)
+		mockTokenWatermarkRepository.Mock.AssertNotCalled(t, "Save", mock.Anything)
 	})
 }
+
+func TestDeleteExpiredBlacklist(t *testing.T) {
+	blacklistService, mockBlacklistRepository, _ := newBlacklistServiceTest([]entity.Blacklist{
+		{TokenId: "token-id-1", CustomerId: "id-1", ExpiresAt: time.Now().Add(-time.Minute).Format(time.RFC3339)},
+	}, []entity.TokenWatermark{})
+
+	mockBlacklistRepository.Mock.On("DeleteExpired", mock.Anything).Return(1, nil)
+
+	err := blacklistService.DeleteExpired()
+	assert.Nil(t, err)
+
+	// The expired entry is dropped from the index too
+	revoked, err := blacklistService.IsRevoked("token-id-1", "id-1", time.Now())
+	assert.Nil(t, err)
+	assert.False(t, revoked)
+}
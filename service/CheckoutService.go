@@ -0,0 +1,231 @@
+package service
+
+import (
+	"PaymentAPI/config"
+	"PaymentAPI/constants"
+	req "PaymentAPI/dto/request"
+	"PaymentAPI/entity"
+	"PaymentAPI/enums"
+	"PaymentAPI/repository"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"strings"
+	"sync"
+	"time"
+)
+
+type CheckoutService interface {
+	CreateCheckoutSession(merchantId string, request req.CreateCheckoutSessionRequest) (entity.CheckoutSession, error)
+	GetCheckoutSessionById(id string) (entity.CheckoutSession, error)
+	PayCheckoutSession(id string, payerWalletId string, stepUp req.StepUp) (entity.CheckoutSession, error)
+	ExpireCheckoutSessions() error
+}
+
+type checkoutService struct {
+	checkoutSessionRepository repository.CheckoutSessionRepository
+	merchantService           MerchantService
+	transactionService        TransactionService
+	merchantCallbackService   MerchantCallbackService
+
+	// lock serializes every status change of a checkout session, so a session is paid or expired only once
+	lock sync.Mutex
+}
+
+// NewCheckoutService creates a new instance of CheckoutService
+func NewCheckoutService(checkoutSessionRepository repository.CheckoutSessionRepository, merchantService MerchantService, transactionService TransactionService, merchantCallbackService MerchantCallbackService) CheckoutService {
+	return &checkoutService{
+		checkoutSessionRepository: checkoutSessionRepository,
+		merchantService:           merchantService,
+		transactionService:        transactionService,
+		merchantCallbackService:   merchantCallbackService,
+	}
+}
+
+// CreateCheckoutSession opens a checkout session a customer can pay to the merchant
+func (c *checkoutService) CreateCheckoutSession(merchantId string, request req.CreateCheckoutSessionRequest) (entity.CheckoutSession, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"merchantId": merchantId,
+		"reference":  request.Reference,
+		"amount":     request.Amount,
+	})
+
+	logger.Info("Creating new checkout session")
+
+	if request.Amount <= 0 {
+		return entity.CheckoutSession{}, errors.New(constants.TransactionInvalidAmountError)
+	}
+
+	merchant, err := c.merchantService.GetMerchantById(merchantId)
+	if err != nil {
+		logger.Error("Failed to retrieve merchant", err)
+		return entity.CheckoutSession{}, err
+	}
+
+	// The reference identifies the order on the merchant side, so it must not be reused
+	reference := strings.TrimSpace(request.Reference)
+	if reference == "" {
+		return entity.CheckoutSession{}, errors.New(constants.CheckoutSessionReferenceError)
+	}
+	checkoutSessions, err := c.checkoutSessionRepository.GetAll()
+	if err != nil {
+		logger.Error("Failed to retrieve checkout sessions", err)
+		return entity.CheckoutSession{}, err
+	}
+	for _, checkoutSession := range checkoutSessions {
+		if checkoutSession.MerchantId == merchant.Id && checkoutSession.Reference == reference {
+			return entity.CheckoutSession{}, errors.New(constants.CheckoutSessionReferenceError)
+		}
+	}
+
+	expiresIn := config.CheckoutSessionExpirationDuration
+	if request.ExpiresInMinutes > 0 {
+		expiresIn = time.Duration(request.ExpiresInMinutes) * time.Minute
+	}
+
+	now := time.Now()
+	checkoutSession := entity.CheckoutSession{
+		Id:          uuid.New().String(),
+		MerchantId:  merchant.Id,
+		Amount:      request.Amount,
+		Reference:   reference,
+		Description: request.Description,
+		Status:      enums.CHECKOUT_OPEN,
+		CreatedAt:   now.Format(time.RFC3339),
+		ExpiresAt:   now.Add(expiresIn).Format(time.RFC3339),
+	}
+
+	if err := c.checkoutSessionRepository.Create(checkoutSession); err != nil {
+		logger.Error("Failed to create checkout session", err)
+		return entity.CheckoutSession{}, err
+	}
+
+	logger.Info("Checkout session created successfully")
+	return checkoutSession, nil
+}
+
+// GetCheckoutSessionById retrieves a checkout session, marking it expired when its expiry has passed
+func (c *checkoutService) GetCheckoutSessionById(id string) (entity.CheckoutSession, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.getCheckoutSession(id)
+}
+
+// getCheckoutSession is GetCheckoutSessionById for a caller that already holds the lock
+func (c *checkoutService) getCheckoutSession(id string) (entity.CheckoutSession, error) {
+	checkoutSession, err := c.checkoutSessionRepository.GetById(id)
+	if err != nil {
+		return entity.CheckoutSession{}, err
+	}
+	return c.expireIfDue(checkoutSession, time.Now())
+}
+
+// PayCheckoutSession pays an open checkout session from the payer wallet to the merchant's wallet, with the step-up of
+// the payer
+func (c *checkoutService) PayCheckoutSession(id string, payerWalletId string, stepUp req.StepUp) (entity.CheckoutSession, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"checkoutSessionId": id,
+		"payerWalletId":     payerWalletId,
+	})
+
+	logger.Info("Paying checkout session")
+
+	// The lock is held until the transfer is done, a concurrent payment waits and then finds the session paid
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	checkoutSession, err := c.getCheckoutSession(id)
+	if err != nil {
+		return entity.CheckoutSession{}, err
+	}
+	if checkoutSession.Status != enums.CHECKOUT_OPEN {
+		return entity.CheckoutSession{}, errors.New(constants.CheckoutSessionNotOpenError)
+	}
+
+	merchant, err := c.merchantService.GetMerchantById(checkoutSession.MerchantId)
+	if err != nil {
+		logger.Error("Failed to retrieve merchant", err)
+		return entity.CheckoutSession{}, err
+	}
+	if merchant.WalletId == payerWalletId {
+		return entity.CheckoutSession{}, errors.New(constants.CheckoutSessionOwnWalletError)
+	}
+
+	// Mark the session paid before paying, it is put back to open if the transfer fails
+	openCheckoutSession := checkoutSession
+	checkoutSession.Status = enums.CHECKOUT_PAID
+	checkoutSession.PayerWalletId = payerWalletId
+	checkoutSession.PaidAt = time.Now().Format(time.RFC3339)
+	if err := c.checkoutSessionRepository.Update(checkoutSession); err != nil {
+		logger.Error("Failed to update checkout session", err)
+		return entity.CheckoutSession{}, err
+	}
+
+	transaction, err := c.transactionService.CreateNewTransaction(req.CreateTransactionRequest{
+		FromWalletId: payerWalletId,
+		ToWalletId:   merchant.WalletId,
+		Amount:       checkoutSession.Amount,
+		Message:      merchant.Name + " " + checkoutSession.Reference,
+		StepUp:       stepUp,
+	})
+	if err != nil {
+		// Keep the session open when the transfer fails, so the customer can retry
+		logger.Error("Failed to pay checkout session", err)
+		c.checkoutSessionRepository.Update(openCheckoutSession)
+		return entity.CheckoutSession{}, err
+	}
+
+	checkoutSession.TransactionId = transaction.Id
+	if err := c.checkoutSessionRepository.Update(checkoutSession); err != nil {
+		logger.Error("Failed to link transaction to checkout session", err)
+		return entity.CheckoutSession{}, err
+	}
+
+	c.merchantCallbackService.NotifyCheckoutSession(merchant, checkoutSession)
+
+	logger.Info("Checkout session paid successfully")
+	return checkoutSession, nil
+}
+
+// ExpireCheckoutSessions marks every open checkout session past its expiry as expired
+func (c *checkoutService) ExpireCheckoutSessions() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	checkoutSessions, err := c.checkoutSessionRepository.GetAll()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, checkoutSession := range checkoutSessions {
+		if _, err := c.expireIfDue(checkoutSession, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *checkoutService) expireIfDue(checkoutSession entity.CheckoutSession, now time.Time) (entity.CheckoutSession, error) {
+	if checkoutSession.Status != enums.CHECKOUT_OPEN {
+		return checkoutSession, nil
+	}
+	expiresAt, err := time.Parse(time.RFC3339, checkoutSession.ExpiresAt)
+	if err != nil || now.Before(expiresAt) {
+		return checkoutSession, nil
+	}
+
+	checkoutSession.Status = enums.CHECKOUT_EXPIRED
+	if err := c.checkoutSessionRepository.Update(checkoutSession); err != nil {
+		return entity.CheckoutSession{}, err
+	}
+
+	logrus.WithFields(logrus.Fields{"checkoutSessionId": checkoutSession.Id}).Info("Checkout session expired")
+
+	// The merchant learns about an expired session the same way as about a paid one
+	if merchant, err := c.merchantService.GetMerchantById(checkoutSession.MerchantId); err == nil {
+		c.merchantCallbackService.NotifyCheckoutSession(merchant, checkoutSession)
+	}
+	return checkoutSession, nil
+}
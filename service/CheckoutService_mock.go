@@ -0,0 +1,31 @@
+package service
+
+import (
+	req "PaymentAPI/dto/request"
+	"PaymentAPI/entity"
+	"github.com/stretchr/testify/mock"
+)
+
+type CheckoutServiceMock struct {
+	mock.Mock
+}
+
+func (c *CheckoutServiceMock) CreateCheckoutSession(merchantId string, request req.CreateCheckoutSessionRequest) (entity.CheckoutSession, error) {
+	args := c.Called(merchantId, request)
+	return args.Get(0).(entity.CheckoutSession), args.Error(1)
+}
+
+func (c *CheckoutServiceMock) GetCheckoutSessionById(id string) (entity.CheckoutSession, error) {
+	args := c.Called(id)
+	return args.Get(0).(entity.CheckoutSession), args.Error(1)
+}
+
+func (c *CheckoutServiceMock) PayCheckoutSession(id string, payerWalletId string, stepUp req.StepUp) (entity.CheckoutSession, error) {
+	args := c.Called(id, payerWalletId, stepUp)
+	return args.Get(0).(entity.CheckoutSession), args.Error(1)
+}
+
+func (c *CheckoutServiceMock) ExpireCheckoutSessions() error {
+	args := c.Called()
+	return args.Error(0)
+}
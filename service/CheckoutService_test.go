@@ -0,0 +1,147 @@
+package service
+
+import (
+	"PaymentAPI/constants"
+	req "PaymentAPI/dto/request"
+	"PaymentAPI/entity"
+	"PaymentAPI/enums"
+	"PaymentAPI/repository"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+	"time"
+)
+
+type checkoutTest struct {
+	mockCheckoutSessionRepository *repository.CheckoutSessionRepositoryMock
+	mockMerchantService           *MerchantServiceMock
+	mockTransactionService        *TransactionServiceMock
+	mockMerchantCallbackService   *MerchantCallbackServiceMock
+	service                       CheckoutService
+}
+
+var testMerchant = entity.Merchant{Id: "merchant-1", WalletId: "wallet-1", Name: "Coffee Shop", CallbackUrl: "https://shop.example/callback", CallbackEndpointId: "endpoint-1"}
+
+func setupCheckoutTest() checkoutTest {
+	mockCheckoutSessionRepository := new(repository.CheckoutSessionRepositoryMock)
+	mockMerchantService := new(MerchantServiceMock)
+	mockTransactionService := new(TransactionServiceMock)
+	mockMerchantCallbackService := new(MerchantCallbackServiceMock)
+
+	mockMerchantService.On("GetMerchantById", testMerchant.Id).Return(testMerchant, nil)
+	mockMerchantCallbackService.On("NotifyCheckoutSession", mock.Anything, mock.Anything).Return()
+
+	return checkoutTest{
+		mockCheckoutSessionRepository: mockCheckoutSessionRepository,
+		mockMerchantService:           mockMerchantService,
+		mockTransactionService:        mockTransactionService,
+		mockMerchantCallbackService:   mockMerchantCallbackService,
+		service:                       NewCheckoutService(mockCheckoutSessionRepository, mockMerchantService, mockTransactionService, mockMerchantCallbackService),
+	}
+}
+
+func openCheckoutSession() entity.CheckoutSession {
+	return entity.CheckoutSession{
+		Id:         "checkout-1",
+		MerchantId: testMerchant.Id,
+		Amount:     25000,
+		Reference:  "ORDER-1",
+		Status:     enums.CHECKOUT_OPEN,
+		ExpiresAt:  time.Now().Add(time.Hour).Format(time.RFC3339),
+	}
+}
+
+func TestCreateCheckoutSession(t *testing.T) {
+	tests := []struct {
+		name          string
+		request       req.CreateCheckoutSessionRequest
+		expectedError string
+	}{
+		{name: "Should Create Checkout Session", request: req.CreateCheckoutSessionRequest{Amount: 25000, Reference: "ORDER-2"}},
+		{name: "Should Fail On Reused Reference", request: req.CreateCheckoutSessionRequest{Amount: 25000, Reference: "ORDER-1"}, expectedError: constants.CheckoutSessionReferenceError},
+		{name: "Should Fail On Invalid Amount", request: req.CreateCheckoutSessionRequest{Amount: -1, Reference: "ORDER-2"}, expectedError: constants.TransactionInvalidAmountError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			test := setupCheckoutTest()
+			test.mockCheckoutSessionRepository.Mock.On("GetAll").Return([]entity.CheckoutSession{openCheckoutSession()}, nil)
+			test.mockCheckoutSessionRepository.Mock.On("Create", mock.Anything).Return(nil)
+
+			checkoutSession, err := test.service.CreateCheckoutSession(testMerchant.Id, tt.request)
+			if tt.expectedError != "" {
+				assert.Equal(t, tt.expectedError, err.Error())
+				test.mockCheckoutSessionRepository.Mock.AssertNotCalled(t, "Create", mock.Anything)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, enums.CHECKOUT_OPEN, checkoutSession.Status)
+				assert.Equal(t, tt.request.Reference, checkoutSession.Reference)
+			}
+		})
+	}
+}
+
+func TestPayCheckoutSession(t *testing.T) {
+	t.Run("ShouldPayMerchantAndNotify", func(t *testing.T) {
+		test := setupCheckoutTest()
+		checkoutSession := openCheckoutSession()
+
+		test.mockCheckoutSessionRepository.Mock.On("GetById", checkoutSession.Id).Return(checkoutSession, nil)
+		test.mockCheckoutSessionRepository.Mock.On("Update", mock.Anything).Return(nil)
+		test.mockTransactionService.On("CreateNewTransaction", req.CreateTransactionRequest{
+			FromWalletId: "wallet-2",
+			ToWalletId:   "wallet-1",
+			Amount:       25000,
+			Message:      "Coffee Shop ORDER-1",
+		}).Return(entity.Transaction{Id: "transaction-1"}, nil)
+
+		paid, err := test.service.PayCheckoutSession(checkoutSession.Id, "wallet-2", req.StepUp{})
+		assert.Nil(t, err)
+		assert.Equal(t, enums.CHECKOUT_PAID, paid.Status)
+		assert.Equal(t, "transaction-1", paid.TransactionId)
+		test.mockMerchantCallbackService.AssertCalled(t, "NotifyCheckoutSession", testMerchant, paid)
+	})
+
+	t.Run("ShouldKeepSessionOpenWhenTransferFails", func(t *testing.T) {
+		test := setupCheckoutTest()
+		checkoutSession := openCheckoutSession()
+
+		test.mockCheckoutSessionRepository.Mock.On("GetById", checkoutSession.Id).Return(checkoutSession, nil)
+		test.mockCheckoutSessionRepository.Mock.On("Update", mock.Anything).Return(nil)
+		test.mockTransactionService.On("CreateNewTransaction", mock.Anything).
+			Return(entity.Transaction{}, errors.New(constants.TransactionInsufficientError))
+
+		_, err := test.service.PayCheckoutSession(checkoutSession.Id, "wallet-2", req.StepUp{})
+		assert.Equal(t, constants.TransactionInsufficientError, err.Error())
+		test.mockCheckoutSessionRepository.Mock.AssertCalled(t, "Update", checkoutSession)
+		test.mockMerchantCallbackService.AssertNotCalled(t, "NotifyCheckoutSession", mock.Anything, mock.Anything)
+	})
+
+	t.Run("ShouldRejectPaidSession", func(t *testing.T) {
+		test := setupCheckoutTest()
+		checkoutSession := openCheckoutSession()
+		checkoutSession.Status = enums.CHECKOUT_PAID
+
+		test.mockCheckoutSessionRepository.Mock.On("GetById", checkoutSession.Id).Return(checkoutSession, nil)
+
+		_, err := test.service.PayCheckoutSession(checkoutSession.Id, "wallet-2", req.StepUp{})
+		assert.Equal(t, constants.CheckoutSessionNotOpenError, err.Error())
+		test.mockTransactionService.AssertNotCalled(t, "CreateNewTransaction", mock.Anything)
+	})
+
+	t.Run("ShouldExpireAndNotifyMerchant", func(t *testing.T) {
+		test := setupCheckoutTest()
+		checkoutSession := openCheckoutSession()
+		checkoutSession.ExpiresAt = time.Now().Add(-time.Minute).Format(time.RFC3339)
+
+		test.mockCheckoutSessionRepository.Mock.On("GetById", checkoutSession.Id).Return(checkoutSession, nil)
+		test.mockCheckoutSessionRepository.Mock.On("Update", mock.Anything).Return(nil)
+
+		_, err := test.service.PayCheckoutSession(checkoutSession.Id, "wallet-2", req.StepUp{})
+		assert.Equal(t, constants.CheckoutSessionNotOpenError, err.Error())
+		test.mockMerchantCallbackService.AssertCalled(t, "NotifyCheckoutSession", testMerchant, mock.MatchedBy(func(expired entity.CheckoutSession) bool {
+			return expired.Status == enums.CHECKOUT_EXPIRED
+		}))
+	})
+}
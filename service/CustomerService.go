@@ -5,6 +5,7 @@ import (
 	req "PaymentAPI/dto/request"
 	res "PaymentAPI/dto/response"
 	"PaymentAPI/entity"
+	"PaymentAPI/enums"
 	"PaymentAPI/repository"
 	"PaymentAPI/utils"
 	"github.com/google/uuid"
@@ -17,6 +18,8 @@ type CustomerService interface {
 	GetCustomerById(id string) (res.CustomerResponse, error)
 	GetCustomerByIdAuth(id string) (entity.Customer, error)
 	CreateNewCustomer(request req.CustomerRequest) (string, error)
+	UpdatePassword(id string, password string) error
+	SetRole(username string, role enums.Role) error
 }
 
 type CustomerServiceImpl struct {
@@ -48,9 +51,16 @@ func (c *CustomerServiceImpl) GetCustomerByUsername(username string) (res.Custom
 		return res.CustomerResponse{}, err
 	}
 
+	// Fetch the balance not reserved by holds
+	availableBalance, err := c.walletService.GetAvailableBalance(wallet.Id)
+	if err != nil {
+		logger.Error("Failed to calculate available balance for customer", err)
+		return res.CustomerResponse{}, err
+	}
+
 	// Map the customer and wallet details to response
 	logger.Info("Successfully fetched customer and wallet")
-	return mapCustomerToCustomerResponse(customer, wallet), nil
+	return mapCustomerToCustomerResponse(customer, wallet, availableBalance), nil
 }
 
 // GetCustomerByUsernameAuth retrieves a customer by username for authentication
@@ -88,9 +98,16 @@ func (c *CustomerServiceImpl) GetCustomerById(id string) (res.CustomerResponse,
 		return res.CustomerResponse{}, err
 	}
 
+	// Fetch the balance not reserved by holds
+	availableBalance, err := c.walletService.GetAvailableBalance(wallet.Id)
+	if err != nil {
+		logger.Error("Failed to calculate available balance for customer", err)
+		return res.CustomerResponse{}, err
+	}
+
 	// Map the customer and wallet details to response
 	logger.Info("Successfully fetched customer and wallet")
-	return mapCustomerToCustomerResponse(customer, wallet), nil
+	return mapCustomerToCustomerResponse(customer, wallet, availableBalance), nil
 }
 
 // GetCustomerByIdAuth retrieves a customer by ID for authentication
@@ -116,8 +133,18 @@ func (c *CustomerServiceImpl) CreateNewCustomer(request req.CustomerRequest) (st
 	})
 	logger.Info("Creating new customer")
 
+	// Check the password against the password policy
+	if err := utils.CheckPasswordPolicy("password", request.Password, request.Username); err != nil {
+		logger.Warn("Password does not meet the password policy")
+		return "", err
+	}
+
 	// Map the customer request to customer entity
-	customerRequest := mapCreateCustomerToCustomer(request)
+	customerRequest, err := mapCreateCustomerToCustomer(request)
+	if err != nil {
+		logger.Error("Failed to hash password of new customer", err)
+		return "", err
+	}
 
 	// Create the customer in the repository
 	customer, err := c.customerRepository.Create(customerRequest)
@@ -137,25 +164,73 @@ func (c *CustomerServiceImpl) CreateNewCustomer(request req.CustomerRequest) (st
 	return constants.CustomerCreateSuccess, nil
 }
 
+// UpdatePassword replaces the password of a customer with the hash of the given password
+func (c *CustomerServiceImpl) UpdatePassword(id string, password string) error {
+	logger := logrus.WithFields(logrus.Fields{"customerId": id})
+	logger.Info("Updating customer password")
+
+	// Store only the hash of the new password
+	hashedPassword, err := utils.HashPassword(password)
+	if err != nil {
+		logger.Error("Failed to hash customer password", err)
+		return err
+	}
+
+	err = c.customerRepository.UpdatePassword(id, hashedPassword)
+	if err != nil {
+		logger.Error("Failed to update customer password", err)
+		return err
+	}
+
+	logger.Info("Successfully updated customer password")
+	return nil
+}
+
+// SetRole gives the customer with the username a role, which their access tokens carry from their next login
+func (c *CustomerServiceImpl) SetRole(username string, role enums.Role) error {
+	logger := logrus.WithFields(logrus.Fields{"username": username, "role": role})
+	logger.Info("Setting customer role")
+
+	customer, err := c.customerRepository.GetByUsername(username)
+	if err != nil {
+		logger.Error("Failed to retrieve customer by username", err)
+		return err
+	}
+
+	err = c.customerRepository.UpdateRole(customer.Id, role)
+	if err != nil {
+		logger.Error("Failed to update customer role", err)
+		return err
+	}
+
+	logger.Info("Successfully set customer role")
+	return nil
+}
+
 // mapCreateCustomerToCustomer maps the customer request to a customer entity
-func mapCreateCustomerToCustomer(request req.CustomerRequest) entity.Customer {
-	// Encrypt the password with Bcrypt
-	encryptedPassword := utils.BCryptEncoder(request.Password)
+func mapCreateCustomerToCustomer(request req.CustomerRequest) (entity.Customer, error) {
+	// Hash the password with the configured algorithm
+	hashedPassword, err := utils.HashPassword(request.Password)
+	if err != nil {
+		return entity.Customer{}, err
+	}
 
 	// Map to customer entity
 	return entity.Customer{
 		Id:       uuid.New().String(),
 		Username: request.Username,
-		Password: encryptedPassword,
-	}
+		Password: hashedPassword,
+	}, nil
 }
 
 // mapCustomerToCustomerResponse maps the customer and wallet details to response format
-func mapCustomerToCustomerResponse(customer entity.Customer, wallet entity.Wallet) res.CustomerResponse {
+func mapCustomerToCustomerResponse(customer entity.Customer, wallet entity.Wallet, availableBalance float64) res.CustomerResponse {
 	return res.CustomerResponse{
-		Id:       customer.Id,
-		Username: customer.Username,
-		WalletId: wallet.Id,
-		Balance:  wallet.Balance,
+		Id:               customer.Id,
+		Username:         customer.Username,
+		WalletId:         wallet.Id,
+		Balance:          wallet.Balance,
+		AvailableBalance: availableBalance,
+		WalletStatus:     wallet.Status,
 	}
 }
@@ -4,6 +4,7 @@ import (
 	req "PaymentAPI/dto/request"
 	dto "PaymentAPI/dto/response"
 	"PaymentAPI/entity"
+	"PaymentAPI/enums"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -35,3 +36,13 @@ func (c *CustomerServiceMock) CreateNewCustomer(request req.CustomerRequest) (st
 	args := c.Mock.Called(request)
 	return args.String(0), args.Error(1)
 }
+
+func (c *CustomerServiceMock) UpdatePassword(id string, password string) error {
+	args := c.Mock.Called(id, password)
+	return args.Error(0)
+}
+
+func (c *CustomerServiceMock) SetRole(username string, role enums.Role) error {
+	args := c.Mock.Called(username, role)
+	return args.Error(0)
+}
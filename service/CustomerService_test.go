@@ -6,6 +6,7 @@ import (
 	res "PaymentAPI/dto/response"
 	"PaymentAPI/entity"
 	"PaymentAPI/repository"
+	"PaymentAPI/utils"
 	"errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -166,7 +167,7 @@ func TestCreateNewCustomer(t *testing.T) {
 
 		request := req.CustomerRequest{
 			Username: "customer-1",
-			Password: "password",
+			Password: "Correct-Horse-42",
 		}
 
 		mappedRequest := entity.Customer{
@@ -193,7 +194,7 @@ func TestCreateNewCustomer(t *testing.T) {
 
 		request := req.CustomerRequest{
 			Username: "customer-1",
-			Password: "password",
+			Password: "Correct-Horse-42",
 		}
 
 		mockCustomerRepository.Mock.On("Create", mock.Anything).
@@ -211,7 +212,7 @@ func TestCreateNewCustomer(t *testing.T) {
 
 		request := req.CustomerRequest{
 			Username: "customer-1",
-			Password: "password",
+			Password: "Correct-Horse-42",
 		}
 
 		mappedRequest := entity.Customer{
@@ -235,4 +236,23 @@ func TestCreateNewCustomer(t *testing.T) {
 		assert.Equal(t, constants.WalletDuplicateError, err.Error())
 		assert.Equal(t, "", result)
 	})
+
+	t.Run("ShouldRejectPasswordAgainstPolicy", func(t *testing.T) {
+		mockCustomerRepository := new(repository.CustomerRepositoryMock)
+		mockWalletService := new(WalletServiceMock)
+		customerService := NewCustomerService(mockCustomerRepository, mockWalletService)
+
+		request := req.CustomerRequest{
+			Username: "customer-1",
+			Password: "password",
+		}
+
+		result, err := customerService.CreateNewCustomer(request)
+		var policyErr *utils.PasswordPolicyError
+		assert.ErrorAs(t, err, &policyErr)
+		assert.Equal(t, "password", policyErr.Field)
+		assert.Contains(t, policyErr.Violations, constants.PasswordCommonError)
+		assert.Equal(t, "", result)
+		mockCustomerRepository.Mock.AssertNotCalled(t, "Create", mock.Anything)
+	})
 }
@@ -0,0 +1,372 @@
+package service
+
+import (
+	"PaymentAPI/entity"
+	"PaymentAPI/enums"
+	"PaymentAPI/repository"
+	"encoding/json"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"sync"
+	"time"
+)
+
+// eventDispatchInterval is how often pending events are retried when no new event wakes the dispatcher up
+const eventDispatchInterval = 5 * time.Second
+
+// eventCommitTimeout is how long a prepared event waits for its operation to commit it before the dispatcher checks
+// whether the state change was stored, long past the time an operation holds its lock
+const eventCommitTimeout = time.Minute
+
+// EventHandler handles a domain event for a subscriber. Events are delivered at least once, so a handler
+// must tolerate seeing the same event ID again; returning an error makes the event be delivered again later.
+type EventHandler func(event entity.OutboxEvent) error
+
+// EventCommitCheck looks up the state change a prepared event describes, for an event its operation did not commit.
+// It returns the stored state to deliver as the payload, or false when the change was never stored.
+type EventCommitCheck func(event entity.OutboxEvent) (interface{}, bool, error)
+
+type EventBus interface {
+	Publish(eventType enums.EventType, walletIds []string, data interface{}) error
+	Prepare(eventTypes []enums.EventType, walletIds []string, data interface{}) ([]entity.OutboxEvent, error)
+	Commit(outboxEvents []entity.OutboxEvent, data interface{})
+	Discard(outboxEvents []entity.OutboxEvent)
+	SetCommitCheck(eventType enums.EventType, check EventCommitCheck)
+	Subscribe(name string, handler EventHandler)
+	DispatchPending() error
+	RunDispatcher()
+}
+
+type eventSubscriber struct {
+	name    string
+	handler EventHandler
+}
+
+type eventBus struct {
+	outboxEventRepository repository.OutboxEventRepository
+	subscribers           []eventSubscriber
+	commitChecks          map[enums.EventType]EventCommitCheck
+
+	// wakeup starts a dispatch round as soon as an event is published, instead of waiting for the next poll
+	wakeup chan struct{}
+	// dispatchLock keeps two dispatch rounds from handing the same event to a subscriber at once
+	dispatchLock sync.Mutex
+	// storeLock keeps recording new events and updating dispatched ones from overwriting each other
+	storeLock sync.Mutex
+}
+
+// NewEventBus creates a new instance of EventBus
+func NewEventBus(outboxEventRepository repository.OutboxEventRepository) EventBus {
+	return &eventBus{
+		outboxEventRepository: outboxEventRepository,
+		commitChecks:          map[enums.EventType]EventCommitCheck{},
+		wakeup:                make(chan struct{}, 1),
+	}
+}
+
+// Publish records an event that needs no state change of its own to be stored, such as a refused transaction, and
+// wakes the dispatcher up. Subscribers only ever see events from the outbox, so an event recorded before a crash is
+// still delivered after a restart.
+func (e *eventBus) Publish(eventType enums.EventType, walletIds []string, data interface{}) error {
+	logger := logrus.WithFields(logrus.Fields{
+		"eventType": eventType,
+		"walletIds": walletIds,
+	})
+
+	outboxEvents, err := newOutboxEvents([]enums.EventType{eventType}, walletIds, data, enums.OUTBOX_PENDING)
+	if err != nil {
+		logger.Error("Failed to encode event", err)
+		return err
+	}
+
+	e.storeLock.Lock()
+	err = e.outboxEventRepository.Create(outboxEvents[0])
+	e.storeLock.Unlock()
+	if err != nil {
+		logger.Error("Failed to record event in the outbox", err)
+		return err
+	}
+
+	e.wake()
+	return nil
+}
+
+// Prepare records the events of a state change before it is stored, under the same lock as the change. They are not
+// delivered until committed, an operation that cannot prepare its events fails before anything changed. Events whose
+// operation crashed or could not commit them are committed or dropped by the dispatcher, with the commit check of
+// their type.
+func (e *eventBus) Prepare(eventTypes []enums.EventType, walletIds []string, data interface{}) ([]entity.OutboxEvent, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"eventTypes": eventTypes,
+		"walletIds":  walletIds,
+	})
+
+	outboxEvents, err := newOutboxEvents(eventTypes, walletIds, data, enums.OUTBOX_PREPARED)
+	if err != nil {
+		logger.Error("Failed to encode events", err)
+		return nil, err
+	}
+
+	e.storeLock.Lock()
+	err = e.outboxEventRepository.CreateAll(outboxEvents)
+	e.storeLock.Unlock()
+	if err != nil {
+		logger.Error("Failed to prepare events in the outbox", err)
+		return nil, err
+	}
+	return outboxEvents, nil
+}
+
+// Commit releases prepared events for delivery once their state change is stored, with the stored state as the
+// payload. The change already happened, so a failure is only logged, the dispatcher commits the events later.
+func (e *eventBus) Commit(outboxEvents []entity.OutboxEvent, data interface{}) {
+	if len(outboxEvents) == 0 {
+		return
+	}
+
+	payload, err := json.Marshal(data)
+	if err != nil {
+		logrus.Error("Failed to encode committed events, the dispatcher commits them later", err)
+		return
+	}
+
+	committed := make([]entity.OutboxEvent, len(outboxEvents))
+	for i, outboxEvent := range outboxEvents {
+		outboxEvent.Payload = payload
+		outboxEvent.Status = enums.OUTBOX_PENDING
+		committed[i] = outboxEvent
+	}
+
+	e.storeLock.Lock()
+	err = e.outboxEventRepository.UpdateAll(committed)
+	e.storeLock.Unlock()
+	if err != nil {
+		logrus.Error("Failed to commit events, the dispatcher commits them later", err)
+		return
+	}
+
+	e.wake()
+}
+
+// Discard drops prepared events whose state change was not stored. A failure is only logged, the dispatcher finds
+// the change missing and drops them later.
+func (e *eventBus) Discard(outboxEvents []entity.OutboxEvent) {
+	if len(outboxEvents) == 0 {
+		return
+	}
+
+	ids := make([]string, len(outboxEvents))
+	for i, outboxEvent := range outboxEvents {
+		ids[i] = outboxEvent.Id
+	}
+
+	e.storeLock.Lock()
+	err := e.outboxEventRepository.DeleteAll(ids)
+	e.storeLock.Unlock()
+	if err != nil {
+		logrus.Error("Failed to discard events, the dispatcher drops them later", err)
+	}
+}
+
+// SetCommitCheck registers how the dispatcher finds out whether a prepared event of the type was stored. Checks are
+// registered before the dispatcher runs, a prepared event without one stays prepared.
+func (e *eventBus) SetCommitCheck(eventType enums.EventType, check EventCommitCheck) {
+	e.commitChecks[eventType] = check
+}
+
+// Subscribe registers a handler for every published event. The name identifies the subscriber in the outbox,
+// so it must stay the same across restarts. Subscribers are registered before the dispatcher runs.
+func (e *eventBus) Subscribe(name string, handler EventHandler) {
+	e.subscribers = append(e.subscribers, eventSubscriber{name: name, handler: handler})
+}
+
+// DispatchPending hands every pending event, oldest first, to the subscribers that did not handle it yet.
+// An event is dispatched once all subscribers handled it, a subscriber that fails gets it again in the next round.
+// Prepared events left behind by their operation are committed or dropped first, and dispatched events are removed
+// from the outbox at the end of the round.
+func (e *eventBus) DispatchPending() error {
+	e.dispatchLock.Lock()
+	defer e.dispatchLock.Unlock()
+
+	outboxEvents, err := e.outboxEventRepository.GetAll()
+	if err != nil {
+		return err
+	}
+
+	var dispatchedIds []string
+	now := time.Now()
+	for _, outboxEvent := range outboxEvents {
+		switch outboxEvent.Status {
+		case enums.OUTBOX_DISPATCHED:
+			dispatchedIds = append(dispatchedIds, outboxEvent.Id)
+			continue
+		case enums.OUTBOX_PREPARED:
+			committed, ok := e.resolvePrepared(outboxEvent, now)
+			if !ok {
+				continue
+			}
+			outboxEvent = committed
+		}
+
+		dispatched, err := e.dispatch(outboxEvent)
+		if err != nil {
+			return err
+		}
+		if dispatched {
+			dispatchedIds = append(dispatchedIds, outboxEvent.Id)
+		}
+	}
+
+	return e.compact(dispatchedIds)
+}
+
+// RunDispatcher dispatches events as soon as they are published and retries the ones a subscriber failed to handle.
+// The first round picks up the events left pending by a previous run.
+func (e *eventBus) RunDispatcher() {
+	ticker := time.NewTicker(eventDispatchInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := e.DispatchPending(); err != nil {
+			logrus.Error("Failed to dispatch pending events", err)
+		}
+
+		select {
+		case <-e.wakeup:
+		case <-ticker.C:
+		}
+	}
+}
+
+// wake starts a dispatch round, a round already waiting to start picks the new events up as well
+func (e *eventBus) wake() {
+	select {
+	case e.wakeup <- struct{}{}:
+	default:
+	}
+}
+
+// resolvePrepared commits a prepared event its operation left behind once it is older than the commit timeout, or
+// drops it when its state change was never stored. It reports whether the event is committed and can be dispatched.
+func (e *eventBus) resolvePrepared(outboxEvent entity.OutboxEvent, now time.Time) (entity.OutboxEvent, bool) {
+	logger := logrus.WithFields(logrus.Fields{
+		"outboxEventId": outboxEvent.Id,
+		"eventType":     outboxEvent.Type,
+	})
+
+	createdAt, err := time.Parse(time.RFC3339, outboxEvent.CreatedAt)
+	if err == nil && now.Before(createdAt.Add(eventCommitTimeout)) {
+		return entity.OutboxEvent{}, false
+	}
+
+	check, ok := e.commitChecks[outboxEvent.Type]
+	if !ok {
+		logger.Warn("No commit check for prepared event, leaving it prepared")
+		return entity.OutboxEvent{}, false
+	}
+
+	data, stored, err := check(outboxEvent)
+	if err != nil {
+		logger.Error("Failed to check whether prepared event was stored", err)
+		return entity.OutboxEvent{}, false
+	}
+
+	if !stored {
+		logger.Warn("Dropping prepared event, its state change was never stored")
+		e.storeLock.Lock()
+		err = e.outboxEventRepository.DeleteAll([]string{outboxEvent.Id})
+		e.storeLock.Unlock()
+		if err != nil {
+			logger.Error("Failed to drop prepared event", err)
+		}
+		return entity.OutboxEvent{}, false
+	}
+
+	outboxEvent.Payload, err = json.Marshal(data)
+	if err != nil {
+		logger.Error("Failed to encode prepared event", err)
+		return entity.OutboxEvent{}, false
+	}
+	outboxEvent.Status = enums.OUTBOX_PENDING
+	logger.Info("Committing prepared event left behind by its operation")
+	return outboxEvent, true
+}
+
+// compact removes dispatched events from the outbox, so it only ever holds the events still to be delivered
+func (e *eventBus) compact(dispatchedIds []string) error {
+	if len(dispatchedIds) == 0 {
+		return nil
+	}
+
+	e.storeLock.Lock()
+	defer e.storeLock.Unlock()
+	if err := e.outboxEventRepository.DeleteAll(dispatchedIds); err != nil {
+		logrus.Error("Failed to remove dispatched events from the outbox", err)
+		return err
+	}
+	return nil
+}
+
+// dispatch hands the event to each subscriber that did not handle it yet and records which ones succeeded. It reports
+// whether every subscriber has handled the event.
+func (e *eventBus) dispatch(outboxEvent entity.OutboxEvent) (bool, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"outboxEventId": outboxEvent.Id,
+		"eventType":     outboxEvent.Type,
+	})
+
+	handled := map[string]bool{}
+	for _, name := range outboxEvent.HandledBy {
+		handled[name] = true
+	}
+
+	outboxEvent.Attempts++
+	outboxEvent.LastError = ""
+	for _, subscriber := range e.subscribers {
+		if handled[subscriber.name] {
+			continue
+		}
+		if err := subscriber.handler(outboxEvent); err != nil {
+			logger.Warnf("Subscriber %s failed to handle event, retrying: %v", subscriber.name, err)
+			outboxEvent.LastError = subscriber.name + ": " + err.Error()
+			continue
+		}
+		outboxEvent.HandledBy = append(outboxEvent.HandledBy, subscriber.name)
+	}
+
+	if outboxEvent.LastError == "" {
+		outboxEvent.Status = enums.OUTBOX_DISPATCHED
+		outboxEvent.DispatchedAt = time.Now().Format(time.RFC3339)
+	}
+
+	e.storeLock.Lock()
+	defer e.storeLock.Unlock()
+	if err := e.outboxEventRepository.Update(outboxEvent); err != nil {
+		logger.Error("Failed to update outbox event", err)
+		return false, err
+	}
+	return outboxEvent.Status == enums.OUTBOX_DISPATCHED, nil
+}
+
+// newOutboxEvents builds an outbox event of each type with the same wallets and payload
+func newOutboxEvents(eventTypes []enums.EventType, walletIds []string, data interface{}, status enums.OutboxEventStatus) ([]entity.OutboxEvent, error) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	createdAt := time.Now().Format(time.RFC3339)
+	outboxEvents := make([]entity.OutboxEvent, len(eventTypes))
+	for i, eventType := range eventTypes {
+		outboxEvents[i] = entity.OutboxEvent{
+			Id:        uuid.New().String(),
+			Type:      eventType,
+			WalletIds: walletIds,
+			Payload:   payload,
+			Status:    status,
+			HandledBy: []string{},
+			CreatedAt: createdAt,
+		}
+	}
+	return outboxEvents, nil
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"PaymentAPI/entity"
+	"PaymentAPI/enums"
+	"github.com/stretchr/testify/mock"
+)
+
+type EventBusMock struct {
+	mock.Mock
+}
+
+func (e *EventBusMock) Publish(eventType enums.EventType, walletIds []string, data interface{}) error {
+	args := e.Called(eventType, walletIds, data)
+	return args.Error(0)
+}
+
+func (e *EventBusMock) Prepare(eventTypes []enums.EventType, walletIds []string, data interface{}) ([]entity.OutboxEvent, error) {
+	args := e.Called(eventTypes, walletIds, data)
+	outboxEvents, _ := args.Get(0).([]entity.OutboxEvent)
+	return outboxEvents, args.Error(1)
+}
+
+func (e *EventBusMock) Commit(outboxEvents []entity.OutboxEvent, data interface{}) {
+	e.Called(outboxEvents, data)
+}
+
+func (e *EventBusMock) Discard(outboxEvents []entity.OutboxEvent) {
+	e.Called(outboxEvents)
+}
+
+func (e *EventBusMock) SetCommitCheck(eventType enums.EventType, check EventCommitCheck) {
+	e.Called(eventType, check)
+}
+
+func (e *EventBusMock) Subscribe(name string, handler EventHandler) {
+	e.Called(name, handler)
+}
+
+func (e *EventBusMock) DispatchPending() error {
+	args := e.Called()
+	return args.Error(0)
+}
+
+func (e *EventBusMock) RunDispatcher() {
+	e.Called()
+}
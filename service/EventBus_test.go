@@ -0,0 +1,296 @@
+package service
+
+import (
+	"PaymentAPI/constants"
+	"PaymentAPI/entity"
+	"PaymentAPI/enums"
+	"PaymentAPI/repository"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newEventBusMock returns an event bus that accepts any published or prepared event
+func newEventBusMock() *EventBusMock {
+	eventBus := new(EventBusMock)
+	eventBus.On("Publish", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	eventBus.On("Prepare", mock.Anything, mock.Anything, mock.Anything).Return([]entity.OutboxEvent{}, nil)
+	eventBus.On("Commit", mock.Anything, mock.Anything).Return()
+	eventBus.On("Discard", mock.Anything).Return()
+	return eventBus
+}
+
+func TestPublishEvent(t *testing.T) {
+	mockOutboxEventRepository := new(repository.OutboxEventRepositoryMock)
+	mockOutboxEventRepository.Mock.On("Create", mock.Anything).Return(nil)
+	eventBus := NewEventBus(mockOutboxEventRepository)
+
+	err := eventBus.Publish(enums.EVENT_TRANSACTION_SETTLED, []string{"wallet-1", "wallet-2"}, entity.Transaction{Id: "transaction-1"})
+	assert.Nil(t, err)
+
+	mockOutboxEventRepository.Mock.AssertCalled(t, "Create", mock.MatchedBy(func(outboxEvent entity.OutboxEvent) bool {
+		return outboxEvent.Id != "" &&
+			outboxEvent.Type == enums.EVENT_TRANSACTION_SETTLED &&
+			outboxEvent.Status == enums.OUTBOX_PENDING &&
+			len(outboxEvent.WalletIds) == 2 &&
+			string(outboxEvent.Payload) != "" &&
+			len(outboxEvent.HandledBy) == 0
+	}))
+}
+
+func TestPublishEventFailsWhenOutboxCannotBeWritten(t *testing.T) {
+	mockOutboxEventRepository := new(repository.OutboxEventRepositoryMock)
+	mockOutboxEventRepository.Mock.On("Create", mock.Anything).Return(errors.New("disk full"))
+	eventBus := NewEventBus(mockOutboxEventRepository)
+
+	err := eventBus.Publish(enums.EVENT_WALLET_FROZEN, []string{"wallet-1"}, entity.Wallet{Id: "wallet-1"})
+	assert.Equal(t, "disk full", err.Error())
+}
+
+func TestPrepareEvents(t *testing.T) {
+	t.Run("ShouldRecordPreparedEvents", func(t *testing.T) {
+		mockOutboxEventRepository := new(repository.OutboxEventRepositoryMock)
+		mockOutboxEventRepository.Mock.On("CreateAll", mock.Anything).Return(nil)
+		eventBus := NewEventBus(mockOutboxEventRepository)
+
+		outboxEvents, err := eventBus.Prepare([]enums.EventType{enums.EVENT_TRANSACTION_CREATED, enums.EVENT_TRANSACTION_SETTLED}, []string{"wallet-1"}, entity.Transaction{Id: "transaction-1"})
+		assert.Nil(t, err)
+		assert.Len(t, outboxEvents, 2)
+		assert.Equal(t, enums.EVENT_TRANSACTION_CREATED, outboxEvents[0].Type)
+		assert.Equal(t, enums.EVENT_TRANSACTION_SETTLED, outboxEvents[1].Type)
+		for _, outboxEvent := range outboxEvents {
+			assert.Equal(t, enums.OUTBOX_PREPARED, outboxEvent.Status)
+		}
+		// Both events are recorded in a single write
+		mockOutboxEventRepository.Mock.AssertNumberOfCalls(t, "CreateAll", 1)
+	})
+
+	t.Run("ShouldFailWhenOutboxCannotBeWritten", func(t *testing.T) {
+		mockOutboxEventRepository := new(repository.OutboxEventRepositoryMock)
+		mockOutboxEventRepository.Mock.On("CreateAll", mock.Anything).Return(errors.New("disk full"))
+		eventBus := NewEventBus(mockOutboxEventRepository)
+
+		_, err := eventBus.Prepare([]enums.EventType{enums.EVENT_WALLET_FROZEN}, []string{"wallet-1"}, entity.Wallet{Id: "wallet-1"})
+		assert.Equal(t, "disk full", err.Error())
+	})
+}
+
+func TestCommitEvents(t *testing.T) {
+	prepared := []entity.OutboxEvent{{Id: "event-1", Status: enums.OUTBOX_PREPARED, Payload: []byte(`{"id":"transaction-1"}`)}}
+
+	t.Run("ShouldReleaseEventsWithStoredState", func(t *testing.T) {
+		mockOutboxEventRepository := new(repository.OutboxEventRepositoryMock)
+		mockOutboxEventRepository.Mock.On("UpdateAll", mock.Anything).Return(nil)
+		eventBus := NewEventBus(mockOutboxEventRepository)
+
+		eventBus.Commit(prepared, entity.Transaction{Id: "transaction-1", Hash: "hash-1"})
+
+		mockOutboxEventRepository.Mock.AssertCalled(t, "UpdateAll", mock.MatchedBy(func(outboxEvents []entity.OutboxEvent) bool {
+			return len(outboxEvents) == 1 &&
+				outboxEvents[0].Status == enums.OUTBOX_PENDING &&
+				strings.Contains(string(outboxEvents[0].Payload), "hash-1")
+		}))
+	})
+
+	t.Run("ShouldOnlyLogWhenOutboxCannotBeWritten", func(t *testing.T) {
+		mockOutboxEventRepository := new(repository.OutboxEventRepositoryMock)
+		mockOutboxEventRepository.Mock.On("UpdateAll", mock.Anything).Return(errors.New("disk full"))
+		eventBus := NewEventBus(mockOutboxEventRepository)
+
+		eventBus.Commit(prepared, entity.Transaction{Id: "transaction-1"})
+		mockOutboxEventRepository.Mock.AssertNumberOfCalls(t, "UpdateAll", 1)
+	})
+
+	t.Run("ShouldDiscardPreparedEvents", func(t *testing.T) {
+		mockOutboxEventRepository := new(repository.OutboxEventRepositoryMock)
+		mockOutboxEventRepository.Mock.On("DeleteAll", []string{"event-1"}).Return(nil)
+		eventBus := NewEventBus(mockOutboxEventRepository)
+
+		eventBus.Discard(prepared)
+		mockOutboxEventRepository.Mock.AssertExpectations(t)
+	})
+}
+
+func TestDispatchPendingEvents(t *testing.T) {
+	pendingEvent := func() entity.OutboxEvent {
+		return entity.OutboxEvent{
+			Id:        "event-1",
+			Type:      enums.EVENT_TRANSACTION_SETTLED,
+			WalletIds: []string{"wallet-1"},
+			Payload:   []byte(`{}`),
+			Status:    enums.OUTBOX_PENDING,
+			HandledBy: []string{},
+		}
+	}
+
+	t.Run("ShouldDispatchToEverySubscriber", func(t *testing.T) {
+		mockOutboxEventRepository := new(repository.OutboxEventRepositoryMock)
+		mockOutboxEventRepository.Mock.On("GetAll").Return([]entity.OutboxEvent{
+			pendingEvent(),
+			{Id: "event-0", Status: enums.OUTBOX_DISPATCHED},
+		}, nil)
+		mockOutboxEventRepository.Mock.On("Update", mock.MatchedBy(func(outboxEvent entity.OutboxEvent) bool {
+			return outboxEvent.Status == enums.OUTBOX_DISPATCHED &&
+				assert.ObjectsAreEqual([]string{"webhooks", "analytics"}, outboxEvent.HandledBy) &&
+				outboxEvent.DispatchedAt != ""
+		})).Return(nil)
+
+		var handled []string
+		eventBus := NewEventBus(mockOutboxEventRepository)
+		eventBus.Subscribe("webhooks", func(outboxEvent entity.OutboxEvent) error {
+			handled = append(handled, "webhooks:"+outboxEvent.Id)
+			return nil
+		})
+		eventBus.Subscribe("analytics", func(outboxEvent entity.OutboxEvent) error {
+			handled = append(handled, "analytics:"+outboxEvent.Id)
+			return nil
+		})
+
+		mockOutboxEventRepository.Mock.On("DeleteAll", []string{"event-1", "event-0"}).Return(nil)
+
+		err := eventBus.DispatchPending()
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"webhooks:event-1", "analytics:event-1"}, handled)
+		mockOutboxEventRepository.Mock.AssertNumberOfCalls(t, "Update", 1)
+		// Dispatched events are removed from the outbox
+		mockOutboxEventRepository.Mock.AssertCalled(t, "DeleteAll", []string{"event-1", "event-0"})
+	})
+
+	t.Run("ShouldKeepEventPendingForFailedSubscriber", func(t *testing.T) {
+		mockOutboxEventRepository := new(repository.OutboxEventRepositoryMock)
+		mockOutboxEventRepository.Mock.On("GetAll").Return([]entity.OutboxEvent{pendingEvent()}, nil)
+
+		var updated entity.OutboxEvent
+		mockOutboxEventRepository.Mock.On("Update", mock.Anything).Run(func(args mock.Arguments) {
+			updated = args.Get(0).(entity.OutboxEvent)
+		}).Return(nil)
+
+		eventBus := NewEventBus(mockOutboxEventRepository)
+		eventBus.Subscribe("webhooks", func(outboxEvent entity.OutboxEvent) error {
+			return errors.New(constants.JsonFileNotFound)
+		})
+		eventBus.Subscribe("analytics", func(outboxEvent entity.OutboxEvent) error {
+			return nil
+		})
+
+		err := eventBus.DispatchPending()
+		assert.Nil(t, err)
+		assert.Equal(t, enums.OUTBOX_PENDING, updated.Status)
+		assert.Equal(t, []string{"analytics"}, updated.HandledBy)
+		assert.Equal(t, 1, updated.Attempts)
+		assert.Equal(t, "webhooks: "+constants.JsonFileNotFound, updated.LastError)
+		mockOutboxEventRepository.Mock.AssertNotCalled(t, "DeleteAll", mock.Anything)
+	})
+
+	t.Run("ShouldOnlyRedeliverToSubscribersThatDidNotHandleItAfterRestart", func(t *testing.T) {
+		// An event left pending by a previous run, the analytics subscriber already handled it
+		leftPending := pendingEvent()
+		leftPending.HandledBy = []string{"analytics"}
+		leftPending.Attempts = 1
+
+		mockOutboxEventRepository := new(repository.OutboxEventRepositoryMock)
+		mockOutboxEventRepository.Mock.On("GetAll").Return([]entity.OutboxEvent{leftPending}, nil)
+		mockOutboxEventRepository.Mock.On("Update", mock.MatchedBy(func(outboxEvent entity.OutboxEvent) bool {
+			return outboxEvent.Status == enums.OUTBOX_DISPATCHED && outboxEvent.Attempts == 2
+		})).Return(nil)
+		mockOutboxEventRepository.Mock.On("DeleteAll", []string{"event-1"}).Return(nil)
+
+		var handled []string
+		eventBus := NewEventBus(mockOutboxEventRepository)
+		eventBus.Subscribe("webhooks", func(outboxEvent entity.OutboxEvent) error {
+			handled = append(handled, "webhooks")
+			return nil
+		})
+		eventBus.Subscribe("analytics", func(outboxEvent entity.OutboxEvent) error {
+			handled = append(handled, "analytics")
+			return nil
+		})
+
+		err := eventBus.DispatchPending()
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"webhooks"}, handled)
+		mockOutboxEventRepository.Mock.AssertExpectations(t)
+	})
+}
+
+func TestDispatchPreparedEvents(t *testing.T) {
+	preparedEvent := func(createdAt time.Time) entity.OutboxEvent {
+		return entity.OutboxEvent{
+			Id:        "event-1",
+			Type:      enums.EVENT_TRANSACTION_SETTLED,
+			WalletIds: []string{"wallet-1"},
+			Payload:   []byte(`{"id":"transaction-1"}`),
+			Status:    enums.OUTBOX_PREPARED,
+			HandledBy: []string{},
+			CreatedAt: createdAt.Format(time.RFC3339),
+		}
+	}
+	stale := time.Now().Add(-2 * eventCommitTimeout)
+
+	t.Run("ShouldLeaveRecentEventToItsOperation", func(t *testing.T) {
+		mockOutboxEventRepository := new(repository.OutboxEventRepositoryMock)
+		mockOutboxEventRepository.Mock.On("GetAll").Return([]entity.OutboxEvent{preparedEvent(time.Now())}, nil)
+
+		checked := false
+		eventBus := NewEventBus(mockOutboxEventRepository)
+		eventBus.SetCommitCheck(enums.EVENT_TRANSACTION_SETTLED, func(outboxEvent entity.OutboxEvent) (interface{}, bool, error) {
+			checked = true
+			return nil, false, nil
+		})
+
+		err := eventBus.DispatchPending()
+		assert.Nil(t, err)
+		assert.False(t, checked)
+		mockOutboxEventRepository.Mock.AssertNotCalled(t, "DeleteAll", mock.Anything)
+		mockOutboxEventRepository.Mock.AssertNotCalled(t, "Update", mock.Anything)
+	})
+
+	t.Run("ShouldDispatchStaleEventWhoseChangeWasStored", func(t *testing.T) {
+		mockOutboxEventRepository := new(repository.OutboxEventRepositoryMock)
+		mockOutboxEventRepository.Mock.On("GetAll").Return([]entity.OutboxEvent{preparedEvent(stale)}, nil)
+		mockOutboxEventRepository.Mock.On("Update", mock.MatchedBy(func(outboxEvent entity.OutboxEvent) bool {
+			return outboxEvent.Status == enums.OUTBOX_DISPATCHED
+		})).Return(nil)
+		mockOutboxEventRepository.Mock.On("DeleteAll", []string{"event-1"}).Return(nil)
+
+		var delivered string
+		eventBus := NewEventBus(mockOutboxEventRepository)
+		eventBus.SetCommitCheck(enums.EVENT_TRANSACTION_SETTLED, func(outboxEvent entity.OutboxEvent) (interface{}, bool, error) {
+			return entity.Transaction{Id: "transaction-1", Hash: "hash-1"}, true, nil
+		})
+		eventBus.Subscribe("webhooks", func(outboxEvent entity.OutboxEvent) error {
+			delivered = string(outboxEvent.Payload)
+			return nil
+		})
+
+		err := eventBus.DispatchPending()
+		assert.Nil(t, err)
+		// The stored transaction is delivered, not the one prepared before it was stored
+		assert.Contains(t, delivered, "hash-1")
+		mockOutboxEventRepository.Mock.AssertExpectations(t)
+	})
+
+	t.Run("ShouldDropStaleEventWhoseChangeWasNeverStored", func(t *testing.T) {
+		mockOutboxEventRepository := new(repository.OutboxEventRepositoryMock)
+		mockOutboxEventRepository.Mock.On("GetAll").Return([]entity.OutboxEvent{preparedEvent(stale)}, nil)
+		mockOutboxEventRepository.Mock.On("DeleteAll", []string{"event-1"}).Return(nil)
+
+		delivered := false
+		eventBus := NewEventBus(mockOutboxEventRepository)
+		eventBus.SetCommitCheck(enums.EVENT_TRANSACTION_SETTLED, func(outboxEvent entity.OutboxEvent) (interface{}, bool, error) {
+			return nil, false, nil
+		})
+		eventBus.Subscribe("webhooks", func(outboxEvent entity.OutboxEvent) error {
+			delivered = true
+			return nil
+		})
+
+		err := eventBus.DispatchPending()
+		assert.Nil(t, err)
+		assert.False(t, delivered)
+		mockOutboxEventRepository.Mock.AssertExpectations(t)
+	})
+}
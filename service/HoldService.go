@@ -0,0 +1,266 @@
+package service
+
+import (
+	"PaymentAPI/config"
+	"PaymentAPI/constants"
+	req "PaymentAPI/dto/request"
+	"PaymentAPI/entity"
+	"PaymentAPI/enums"
+	"PaymentAPI/repository"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"sync"
+	"time"
+)
+
+type HoldService interface {
+	CreateHold(request req.CreateHoldRequest) (entity.Hold, error)
+	GetHoldById(id string) (entity.Hold, error)
+	CaptureHold(id string, amount float64) (entity.Hold, error)
+	VoidHold(id string) (entity.Hold, error)
+	ExpireHolds() error
+}
+
+type holdService struct {
+	holdRepository     repository.HoldRepository
+	walletService      WalletService
+	transactionService TransactionService
+
+	// lock serializes every status change of a hold, so a hold is captured, voided or expired only once
+	lock sync.Mutex
+}
+
+// NewHoldService creates a new instance of HoldService
+func NewHoldService(holdRepository repository.HoldRepository, walletService WalletService, transactionService TransactionService) HoldService {
+	return &holdService{holdRepository: holdRepository, walletService: walletService, transactionService: transactionService}
+}
+
+// CreateHold reserves funds on a wallet, lowering its available balance but not its ledger balance. The owner of the
+// wallet steps up here, as the capture is made by the receiver.
+func (h *holdService) CreateHold(request req.CreateHoldRequest) (entity.Hold, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"walletId":   request.WalletId,
+		"toWalletId": request.ToWalletId,
+		"amount":     request.Amount,
+	})
+
+	logger.Info("Creating new hold")
+
+	if request.Amount <= 0 {
+		return entity.Hold{}, errors.New(constants.TransactionInvalidAmountError)
+	}
+	if request.WalletId == request.ToWalletId {
+		return entity.Hold{}, errors.New(constants.HoldSameWalletError)
+	}
+
+	// Both wallets must exist and be active, the same rules as a transfer
+	wallet, err := h.walletService.GetWalletById(request.WalletId)
+	if err != nil {
+		logger.Error("Failed to retrieve wallet", err)
+		return entity.Hold{}, err
+	}
+	toWallet, err := h.walletService.GetWalletById(request.ToWalletId)
+	if err != nil {
+		logger.Error("Failed to retrieve receiving wallet", err)
+		return entity.Hold{}, err
+	}
+	if err := checkWalletStatus(wallet, toWallet); err != nil {
+		logger.Error("Wallet is not active", err)
+		return entity.Hold{}, err
+	}
+
+	if err := h.transactionService.AuthorizeDebit(wallet.Id, request.Amount, request.StepUp); err != nil {
+		logger.Warn("Hold step-up failed", err)
+		return entity.Hold{}, err
+	}
+
+	expiresIn := config.HoldExpirationDuration
+	if request.ExpiresInMinutes > 0 {
+		expiresIn = time.Duration(request.ExpiresInMinutes) * time.Minute
+	}
+
+	now := time.Now()
+	hold := entity.Hold{
+		Id:         uuid.New().String(),
+		WalletId:   wallet.Id,
+		ToWalletId: toWallet.Id,
+		Amount:     request.Amount,
+		Message:    request.Message,
+		Status:     enums.HOLD_ACTIVE,
+		CreatedAt:  now.Format(time.RFC3339),
+		ExpiresAt:  now.Add(expiresIn).Format(time.RFC3339),
+		UpdatedAt:  now.Format(time.RFC3339),
+	}
+
+	// The hold can only reserve funds that are not already reserved or spent. Checked and stored under the ledger
+	// lock, so no transfer or other hold spends the same funds in between.
+	err = h.transactionService.WithLedgerLock(func() error {
+		availableBalance, err := h.walletService.GetAvailableBalance(wallet.Id)
+		if err != nil {
+			logger.Error("Failed to calculate available balance", err)
+			return err
+		}
+		if availableBalance-request.Amount < 0 {
+			logger.Error("Insufficient balance for hold")
+			return errors.New(constants.TransactionInsufficientError)
+		}
+
+		if err := h.holdRepository.Create(hold); err != nil {
+			logger.Error("Failed to create hold", err)
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return entity.Hold{}, err
+	}
+
+	logger.Info("Hold created successfully")
+	return hold, nil
+}
+
+// GetHoldById retrieves a hold, marking it expired when its expiry has passed
+func (h *holdService) GetHoldById(id string) (entity.Hold, error) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	return h.getHold(id)
+}
+
+// getHold is GetHoldById for a caller that already holds the lock
+func (h *holdService) getHold(id string) (entity.Hold, error) {
+	hold, err := h.holdRepository.GetById(id)
+	if err != nil {
+		return entity.Hold{}, err
+	}
+	return h.expireIfDue(hold, time.Now())
+}
+
+// CaptureHold transfers the captured amount to the receiving wallet and releases the rest of the hold.
+// An amount of zero captures the full hold.
+func (h *holdService) CaptureHold(id string, amount float64) (entity.Hold, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"holdId": id,
+		"amount": amount,
+	})
+
+	logger.Info("Capturing hold")
+
+	// The lock is held until the transfer is done, a concurrent capture or void waits and then finds the hold captured
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	hold, err := h.getHold(id)
+	if err != nil {
+		return entity.Hold{}, err
+	}
+	if hold.Status != enums.HOLD_ACTIVE {
+		return entity.Hold{}, errors.New(constants.HoldNotActiveError)
+	}
+
+	if amount == 0 {
+		amount = hold.Amount
+	}
+	if amount < 0 || amount > hold.Amount {
+		return entity.Hold{}, errors.New(constants.HoldCaptureAmountError)
+	}
+
+	// Release the reservation first, so the transfer can spend the held funds
+	activeHold := hold
+	hold.Status = enums.HOLD_CAPTURED
+	hold.CapturedAmount = amount
+	hold.UpdatedAt = time.Now().Format(time.RFC3339)
+	if err := h.holdRepository.Update(hold); err != nil {
+		logger.Error("Failed to update hold", err)
+		return entity.Hold{}, err
+	}
+
+	transaction, err := h.transactionService.CreateNewTransaction(req.CreateTransactionRequest{
+		FromWalletId: hold.WalletId,
+		ToWalletId:   hold.ToWalletId,
+		Amount:       amount,
+		Message:      hold.Message,
+		// Stepped up when the hold was created, a lock on transfers of the owner still refuses the capture
+		StepUp: req.StepUp{Authorized: true},
+	})
+	if err != nil {
+		// Put the reservation back when the transfer fails
+		logger.Error("Failed to transfer captured funds", err)
+		h.holdRepository.Update(activeHold)
+		return entity.Hold{}, err
+	}
+
+	hold.TransactionId = transaction.Id
+	if err := h.holdRepository.Update(hold); err != nil {
+		logger.Error("Failed to link transaction to hold", err)
+		return entity.Hold{}, err
+	}
+
+	logger.Info("Hold captured successfully")
+	return hold, nil
+}
+
+// VoidHold releases an active hold without moving any funds
+func (h *holdService) VoidHold(id string) (entity.Hold, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"holdId": id,
+	})
+
+	logger.Info("Voiding hold")
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	hold, err := h.getHold(id)
+	if err != nil {
+		return entity.Hold{}, err
+	}
+	if hold.Status != enums.HOLD_ACTIVE {
+		return entity.Hold{}, errors.New(constants.HoldNotActiveError)
+	}
+
+	hold.Status = enums.HOLD_VOIDED
+	hold.UpdatedAt = time.Now().Format(time.RFC3339)
+	if err := h.holdRepository.Update(hold); err != nil {
+		logger.Error("Failed to void hold", err)
+		return entity.Hold{}, err
+	}
+
+	logger.Info("Hold voided successfully")
+	return hold, nil
+}
+
+// ExpireHolds marks every active hold past its expiry as expired
+func (h *holdService) ExpireHolds() error {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	holds, err := h.holdRepository.GetAll()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, hold := range holds {
+		if _, err := h.expireIfDue(hold, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (h *holdService) expireIfDue(hold entity.Hold, now time.Time) (entity.Hold, error) {
+	if hold.Status != enums.HOLD_ACTIVE || isHoldReserving(hold, now) {
+		return hold, nil
+	}
+
+	hold.Status = enums.HOLD_EXPIRED
+	hold.UpdatedAt = now.Format(time.RFC3339)
+	if err := h.holdRepository.Update(hold); err != nil {
+		return entity.Hold{}, err
+	}
+
+	logrus.WithFields(logrus.Fields{"holdId": hold.Id}).Info("Hold expired")
+	return hold, nil
+}
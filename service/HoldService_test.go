@@ -0,0 +1,179 @@
+package service
+
+import (
+	"PaymentAPI/constants"
+	req "PaymentAPI/dto/request"
+	"PaymentAPI/entity"
+	"PaymentAPI/enums"
+	"PaymentAPI/repository"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+	"time"
+)
+
+type holdTest struct {
+	mockHoldRepository     *repository.HoldRepositoryMock
+	mockWalletService      *WalletServiceMock
+	mockTransactionService *TransactionServiceMock
+	service                HoldService
+}
+
+func setupHoldTest() holdTest {
+	mockHoldRepository := new(repository.HoldRepositoryMock)
+	mockWalletService := new(WalletServiceMock)
+	mockTransactionService := new(TransactionServiceMock)
+	mockTransactionService.On("AuthorizeDebit", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	return holdTest{
+		mockHoldRepository:     mockHoldRepository,
+		mockWalletService:      mockWalletService,
+		mockTransactionService: mockTransactionService,
+		service:                NewHoldService(mockHoldRepository, mockWalletService, mockTransactionService),
+	}
+}
+
+func activeHold(amount float64) entity.Hold {
+	return entity.Hold{
+		Id:         "hold-1",
+		WalletId:   "wallet-1",
+		ToWalletId: "wallet-2",
+		Amount:     amount,
+		Message:    "Hotel deposit",
+		Status:     enums.HOLD_ACTIVE,
+		ExpiresAt:  time.Now().Add(time.Hour).Format(time.RFC3339),
+	}
+}
+
+func TestCreateHold(t *testing.T) {
+	request := req.CreateHoldRequest{
+		WalletId:   "wallet-1",
+		ToWalletId: "wallet-2",
+		Amount:     5000,
+		Message:    "Hotel deposit",
+	}
+
+	tests := []struct {
+		name             string
+		availableBalance float64
+		expectedError    string
+	}{
+		{name: "Should Create Hold", availableBalance: 10000},
+		{name: "Should Fail On Insufficient Available Balance", availableBalance: 4000, expectedError: constants.TransactionInsufficientError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			test := setupHoldTest()
+
+			test.mockWalletService.On("GetWalletById", "wallet-1").Return(entity.Wallet{Id: "wallet-1", Balance: 10000, Status: enums.WALLET_ACTIVE}, nil)
+			test.mockWalletService.On("GetWalletById", "wallet-2").Return(entity.Wallet{Id: "wallet-2", Status: enums.WALLET_ACTIVE}, nil)
+			test.mockWalletService.On("GetAvailableBalance", "wallet-1").Return(tt.availableBalance, nil)
+			test.mockHoldRepository.Mock.On("Create", mock.Anything).Return(nil)
+
+			hold, err := test.service.CreateHold(request)
+			if tt.expectedError != "" {
+				assert.Equal(t, tt.expectedError, err.Error())
+				test.mockHoldRepository.Mock.AssertNotCalled(t, "Create", mock.Anything)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, enums.HOLD_ACTIVE, hold.Status)
+				assert.Equal(t, request.Amount, hold.Amount)
+			}
+		})
+	}
+
+	t.Run("Should Fail Without Step-Up", func(t *testing.T) {
+		test := setupHoldTest()
+		test.mockTransactionService = new(TransactionServiceMock)
+		test.service = NewHoldService(test.mockHoldRepository, test.mockWalletService, test.mockTransactionService)
+
+		test.mockWalletService.On("GetWalletById", "wallet-1").Return(entity.Wallet{Id: "wallet-1", Balance: 10000, Status: enums.WALLET_ACTIVE}, nil)
+		test.mockWalletService.On("GetWalletById", "wallet-2").Return(entity.Wallet{Id: "wallet-2", Status: enums.WALLET_ACTIVE}, nil)
+		test.mockWalletService.On("GetAvailableBalance", "wallet-1").Return(float64(10000), nil)
+		test.mockTransactionService.On("AuthorizeDebit", "wallet-1", request.Amount, req.StepUp{}).
+			Return(errors.New(constants.TransactionStepUpRequiredError))
+
+		_, err := test.service.CreateHold(request)
+		assert.Equal(t, constants.TransactionStepUpRequiredError, err.Error())
+		test.mockHoldRepository.Mock.AssertNotCalled(t, "Create", mock.Anything)
+	})
+}
+
+func TestCaptureHold(t *testing.T) {
+	t.Run("ShouldCapturePartially", func(t *testing.T) {
+		test := setupHoldTest()
+		hold := activeHold(5000)
+
+		test.mockHoldRepository.Mock.On("GetById", hold.Id).Return(hold, nil)
+		test.mockHoldRepository.Mock.On("Update", mock.Anything).Return(nil)
+		test.mockTransactionService.On("CreateNewTransaction", req.CreateTransactionRequest{
+			FromWalletId: "wallet-1",
+			ToWalletId:   "wallet-2",
+			Amount:       3000,
+			Message:      "Hotel deposit",
+			StepUp:       req.StepUp{Authorized: true},
+		}).Return(entity.Transaction{Id: "transaction-1"}, nil)
+
+		captured, err := test.service.CaptureHold(hold.Id, 3000)
+		assert.Nil(t, err)
+		assert.Equal(t, enums.HOLD_CAPTURED, captured.Status)
+		assert.Equal(t, float64(3000), captured.CapturedAmount)
+		assert.Equal(t, "transaction-1", captured.TransactionId)
+	})
+
+	t.Run("ShouldRejectAmountAboveHold", func(t *testing.T) {
+		test := setupHoldTest()
+		hold := activeHold(5000)
+
+		test.mockHoldRepository.Mock.On("GetById", hold.Id).Return(hold, nil)
+
+		_, err := test.service.CaptureHold(hold.Id, 6000)
+		assert.Equal(t, constants.HoldCaptureAmountError, err.Error())
+		test.mockTransactionService.AssertNotCalled(t, "CreateNewTransaction", mock.Anything)
+	})
+
+	t.Run("ShouldRestoreHoldWhenTransferFails", func(t *testing.T) {
+		test := setupHoldTest()
+		hold := activeHold(5000)
+
+		test.mockHoldRepository.Mock.On("GetById", hold.Id).Return(hold, nil)
+		test.mockHoldRepository.Mock.On("Update", mock.Anything).Return(nil)
+		test.mockTransactionService.On("CreateNewTransaction", mock.Anything).
+			Return(entity.Transaction{}, errors.New(constants.WalletReceiverFrozenError))
+
+		_, err := test.service.CaptureHold(hold.Id, 0)
+		assert.Equal(t, constants.WalletReceiverFrozenError, err.Error())
+		test.mockHoldRepository.Mock.AssertCalled(t, "Update", hold)
+	})
+
+	t.Run("ShouldRejectExpiredHold", func(t *testing.T) {
+		test := setupHoldTest()
+		hold := activeHold(5000)
+		hold.ExpiresAt = time.Now().Add(-time.Minute).Format(time.RFC3339)
+
+		test.mockHoldRepository.Mock.On("GetById", hold.Id).Return(hold, nil)
+		test.mockHoldRepository.Mock.On("Update", mock.MatchedBy(func(updated entity.Hold) bool {
+			return updated.Status == enums.HOLD_EXPIRED
+		})).Return(nil)
+
+		_, err := test.service.CaptureHold(hold.Id, 0)
+		assert.Equal(t, constants.HoldNotActiveError, err.Error())
+		test.mockHoldRepository.Mock.AssertExpectations(t)
+	})
+}
+
+func TestVoidHold(t *testing.T) {
+	test := setupHoldTest()
+	hold := activeHold(5000)
+
+	test.mockHoldRepository.Mock.On("GetById", hold.Id).Return(hold, nil)
+	test.mockHoldRepository.Mock.On("Update", mock.MatchedBy(func(updated entity.Hold) bool {
+		return updated.Status == enums.HOLD_VOIDED
+	})).Return(nil)
+
+	voided, err := test.service.VoidHold(hold.Id)
+	assert.Nil(t, err)
+	assert.Equal(t, enums.HOLD_VOIDED, voided.Status)
+	test.mockTransactionService.AssertNotCalled(t, "CreateNewTransaction", mock.Anything)
+}
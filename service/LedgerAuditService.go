@@ -0,0 +1,144 @@
+package service
+
+import (
+	"PaymentAPI/constants"
+	res "PaymentAPI/dto/response"
+	"PaymentAPI/entity"
+	"PaymentAPI/logger"
+	"PaymentAPI/repository"
+	"PaymentAPI/utils"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+type LedgerAuditService interface {
+	VerifyChain() (res.LedgerVerificationResponse, error)
+	CreateCheckpoint() (entity.LedgerCheckpoint, error)
+}
+
+type ledgerAuditService struct {
+	transactionRepository      repository.TransactionRepository
+	ledgerCheckpointRepository repository.LedgerCheckpointRepository
+}
+
+// NewLedgerAuditService creates a new instance of LedgerAuditService
+func NewLedgerAuditService(transactionRepository repository.TransactionRepository, ledgerCheckpointRepository repository.LedgerCheckpointRepository) LedgerAuditService {
+	return &ledgerAuditService{transactionRepository, ledgerCheckpointRepository}
+}
+
+// VerifyChain walks the transaction hash chain and the signed checkpoints and reports the first broken link
+func (l *ledgerAuditService) VerifyChain() (res.LedgerVerificationResponse, error) {
+	logger.LogInfo("Verifying transaction hash chain", logrus.Fields{
+		"operation": "VerifyChain",
+	})
+
+	transactions, err := l.transactionRepository.GetAll()
+	if err != nil {
+		return res.LedgerVerificationResponse{}, err
+	}
+
+	checkpoints, err := l.ledgerCheckpointRepository.GetAll()
+	if err != nil {
+		return res.LedgerVerificationResponse{}, err
+	}
+
+	report := res.LedgerVerificationResponse{
+		Valid:            true,
+		TransactionCount: len(transactions),
+	}
+
+	// Every record must point to its predecessor and hash to its stored value
+	prevHash := ""
+	for i, transaction := range transactions {
+		if transaction.PrevHash != prevHash {
+			return brokenLedger(report, i, transaction.Id, constants.LedgerPrevHashMismatch), nil
+		}
+
+		hash, err := utils.ComputeTransactionHash(transaction)
+		if err != nil {
+			return res.LedgerVerificationResponse{}, err
+		}
+		if hash != transaction.Hash {
+			return brokenLedger(report, i, transaction.Id, constants.LedgerHashMismatch), nil
+		}
+
+		prevHash = transaction.Hash
+	}
+	report.HeadHash = prevHash
+
+	// Every signed checkpoint must still be part of the chain, which catches a truncated or rewritten log
+	for _, checkpoint := range checkpoints {
+		if !utils.VerifyLedgerCheckpoint(checkpoint) {
+			return brokenLedger(report, checkpoint.Sequence-1, "", constants.LedgerCheckpointSignatureInvalid), nil
+		}
+		if checkpoint.Sequence > len(transactions) {
+			return brokenLedger(report, len(transactions), "", constants.LedgerTruncated), nil
+		}
+		if checkpoint.Sequence > 0 && transactions[checkpoint.Sequence-1].Hash != checkpoint.HeadHash {
+			return brokenLedger(report, checkpoint.Sequence-1, transactions[checkpoint.Sequence-1].Id, constants.LedgerCheckpointHeadMismatch), nil
+		}
+		report.CheckpointsVerified++
+	}
+
+	logger.LogInfo("Transaction hash chain verified", logrus.Fields{
+		"operation":   "VerifyChain",
+		"count":       report.TransactionCount,
+		"headHash":    report.HeadHash,
+		"checkpoints": report.CheckpointsVerified,
+	})
+	return report, nil
+}
+
+// CreateCheckpoint signs the current head of the chain, skipping it when the head has not moved
+func (l *ledgerAuditService) CreateCheckpoint() (entity.LedgerCheckpoint, error) {
+	transactions, err := l.transactionRepository.GetAll()
+	if err != nil {
+		return entity.LedgerCheckpoint{}, err
+	}
+
+	checkpoints, err := l.ledgerCheckpointRepository.GetAll()
+	if err != nil {
+		return entity.LedgerCheckpoint{}, err
+	}
+
+	checkpoint := entity.LedgerCheckpoint{
+		Sequence:  len(transactions),
+		CreatedAt: time.Now().Format(time.RFC3339),
+	}
+	if len(transactions) > 0 {
+		checkpoint.HeadHash = transactions[len(transactions)-1].Hash
+	}
+
+	if len(checkpoints) > 0 && checkpoints[len(checkpoints)-1].Sequence == checkpoint.Sequence {
+		return checkpoints[len(checkpoints)-1], nil
+	}
+
+	checkpoint.Signature = utils.SignLedgerCheckpoint(checkpoint)
+	if err := l.ledgerCheckpointRepository.Create(checkpoint); err != nil {
+		return entity.LedgerCheckpoint{}, err
+	}
+
+	logger.LogInfo("Ledger checkpoint created", logrus.Fields{
+		"operation": "CreateCheckpoint",
+		"sequence":  checkpoint.Sequence,
+		"headHash":  checkpoint.HeadHash,
+	})
+	return checkpoint, nil
+}
+
+// brokenLedger marks the report as invalid and logs the first broken link
+func brokenLedger(report res.LedgerVerificationResponse, index int, transactionId string, reason string) res.LedgerVerificationResponse {
+	report.Valid = false
+	report.BrokenAtIndex = &index
+	report.BrokenTransactionId = transactionId
+	report.Reason = reason
+
+	logger.LogError("Transaction hash chain is broken", logrus.Fields{
+		"operation":     "VerifyChain",
+		"index":         index,
+		"transactionId": transactionId,
+		"reason":        reason,
+	})
+	return report
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"PaymentAPI/constants"
+	"PaymentAPI/entity"
+	"PaymentAPI/repository"
+	"PaymentAPI/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+	"time"
+)
+
+// buildChain links the given transactions the same way the transaction repository does
+func buildChain(t *testing.T, transactions ...entity.Transaction) []entity.Transaction {
+	prevHash := ""
+	for i := range transactions {
+		transactions[i].PrevHash = prevHash
+		hash, err := utils.ComputeTransactionHash(transactions[i])
+		assert.Nil(t, err)
+		transactions[i].Hash = hash
+		prevHash = hash
+	}
+	return transactions
+}
+
+func signedCheckpoint(sequence int, headHash string) entity.LedgerCheckpoint {
+	checkpoint := entity.LedgerCheckpoint{
+		Sequence:  sequence,
+		HeadHash:  headHash,
+		CreatedAt: time.Now().Format(time.RFC3339),
+	}
+	checkpoint.Signature = utils.SignLedgerCheckpoint(checkpoint)
+	return checkpoint
+}
+
+func newLedgerChain(t *testing.T) []entity.Transaction {
+	return buildChain(t,
+		entity.Transaction{Id: "transaction-1", FromWalletId: "wallet-1", ToWalletId: "wallet-2", Amount: 5000, Message: "Beli pulsa"},
+		entity.Transaction{Id: "transaction-2", FromWalletId: "wallet-1", ToWalletId: "wallet-2", Amount: 7000, Message: "Salary"},
+		entity.Transaction{Id: "transaction-3", FromWalletId: "wallet-2", ToWalletId: "wallet-1", Amount: 1000, Message: "Refund"},
+	)
+}
+
+func TestVerifyChain(t *testing.T) {
+	tests := []struct {
+		name          string
+		transactions  func([]entity.Transaction) []entity.Transaction
+		checkpoints   func([]entity.Transaction) []entity.LedgerCheckpoint
+		expectedValid bool
+		expectedIndex int
+		expectedError string
+	}{
+		{
+			name:         "Should Accept Intact Chain",
+			transactions: func(chain []entity.Transaction) []entity.Transaction { return chain },
+			checkpoints: func(chain []entity.Transaction) []entity.LedgerCheckpoint {
+				return []entity.LedgerCheckpoint{signedCheckpoint(3, chain[2].Hash)}
+			},
+			expectedValid: true,
+		},
+		{
+			name: "Should Detect Edited Amount",
+			transactions: func(chain []entity.Transaction) []entity.Transaction {
+				chain[1].Amount = 1
+				return chain
+			},
+			checkpoints:   func(chain []entity.Transaction) []entity.LedgerCheckpoint { return nil },
+			expectedIndex: 1,
+			expectedError: constants.LedgerHashMismatch,
+		},
+		{
+			name: "Should Detect Removed Record",
+			transactions: func(chain []entity.Transaction) []entity.Transaction {
+				return append(chain[:1], chain[2:]...)
+			},
+			checkpoints:   func(chain []entity.Transaction) []entity.LedgerCheckpoint { return nil },
+			expectedIndex: 1,
+			expectedError: constants.LedgerPrevHashMismatch,
+		},
+		{
+			name:         "Should Detect Truncated Log",
+			transactions: func(chain []entity.Transaction) []entity.Transaction { return chain[:2] },
+			checkpoints: func(chain []entity.Transaction) []entity.LedgerCheckpoint {
+				return []entity.LedgerCheckpoint{signedCheckpoint(3, chain[2].Hash)}
+			},
+			expectedIndex: 2,
+			expectedError: constants.LedgerTruncated,
+		},
+		{
+			name:         "Should Detect Forged Checkpoint",
+			transactions: func(chain []entity.Transaction) []entity.Transaction { return chain[:2] },
+			checkpoints: func(chain []entity.Transaction) []entity.LedgerCheckpoint {
+				checkpoint := signedCheckpoint(3, chain[2].Hash)
+				checkpoint.Sequence = 2
+				return []entity.LedgerCheckpoint{checkpoint}
+			},
+			expectedIndex: 1,
+			expectedError: constants.LedgerCheckpointSignatureInvalid,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockTransactionRepository := new(repository.TransactionRepositoryMock)
+			mockCheckpointRepository := new(repository.LedgerCheckpointRepositoryMock)
+			ledgerAuditService := NewLedgerAuditService(mockTransactionRepository, mockCheckpointRepository)
+
+			chain := newLedgerChain(t)
+			checkpoints := tt.checkpoints(chain)
+			mockTransactionRepository.Mock.On("GetAll").Return(tt.transactions(chain), nil)
+			mockCheckpointRepository.Mock.On("GetAll").Return(checkpoints, nil)
+
+			report, err := ledgerAuditService.VerifyChain()
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expectedValid, report.Valid)
+			if !tt.expectedValid {
+				assert.Equal(t, tt.expectedError, report.Reason)
+				assert.Equal(t, tt.expectedIndex, *report.BrokenAtIndex)
+			}
+		})
+	}
+}
+
+func TestCreateCheckpoint(t *testing.T) {
+	mockTransactionRepository := new(repository.TransactionRepositoryMock)
+	mockCheckpointRepository := new(repository.LedgerCheckpointRepositoryMock)
+	ledgerAuditService := NewLedgerAuditService(mockTransactionRepository, mockCheckpointRepository)
+
+	chain := newLedgerChain(t)
+	mockTransactionRepository.Mock.On("GetAll").Return(chain, nil)
+	mockCheckpointRepository.Mock.On("GetAll").Return([]entity.LedgerCheckpoint{}, nil)
+	mockCheckpointRepository.Mock.On("Create", mock.MatchedBy(func(checkpoint entity.LedgerCheckpoint) bool {
+		return checkpoint.Sequence == 3 && checkpoint.HeadHash == chain[2].Hash && utils.VerifyLedgerCheckpoint(checkpoint)
+	})).Return(nil)
+
+	checkpoint, err := ledgerAuditService.CreateCheckpoint()
+	assert.Nil(t, err)
+	assert.Equal(t, chain[2].Hash, checkpoint.HeadHash)
+	mockCheckpointRepository.Mock.AssertExpectations(t)
+}
@@ -0,0 +1,222 @@
+package service
+
+import (
+	"PaymentAPI/config"
+	"PaymentAPI/constants"
+	req "PaymentAPI/dto/request"
+	"PaymentAPI/entity"
+	"PaymentAPI/enums"
+	"PaymentAPI/repository"
+	"errors"
+	"github.com/sirupsen/logrus"
+	"strings"
+	"sync"
+	"time"
+)
+
+type LoginAttemptService interface {
+	CheckAllowed(username string, clientIp string) error
+	RecordFailure(username string, clientIp string) error
+	RecordSuccess(username string) error
+	GetLockouts() ([]entity.LoginAttempt, error)
+	Unlock(adminId string, request req.UnlockLoginRequest) error
+}
+
+// LoginThrottledError refuses a login before it is checked, telling the client how long to wait
+type LoginThrottledError struct {
+	Message    string
+	RetryAfter time.Duration
+}
+
+func (l *LoginThrottledError) Error() string {
+	return l.Message
+}
+
+type loginAttemptService struct {
+	loginAttemptRepository repository.LoginAttemptRepository
+	lock                   sync.Mutex
+}
+
+// NewLoginAttemptService creates a new instance of LoginAttemptService
+func NewLoginAttemptService(loginAttemptRepository repository.LoginAttemptRepository) LoginAttemptService {
+	return &loginAttemptService{loginAttemptRepository: loginAttemptRepository}
+}
+
+// CheckAllowed refuses a login while the username or the client IP is locked out, or until the delay after its
+// last failure has passed. The LoginThrottledError it returns says how long to wait before trying again.
+func (l *loginAttemptService) CheckAllowed(username string, clientIp string) error {
+	now := time.Now()
+	var lockedFor, throttledFor time.Duration
+
+	for _, id := range []string{loginAttemptId(enums.LOGIN_ATTEMPT_USERNAME, username), loginAttemptId(enums.LOGIN_ATTEMPT_IP, clientIp)} {
+		loginAttempt, err := l.loginAttemptRepository.GetById(id)
+		if err != nil {
+			if err.Error() == constants.LoginAttemptNotFoundError {
+				continue
+			}
+			return err
+		}
+
+		if lockedUntil, err := time.Parse(time.RFC3339, loginAttempt.LockedUntil); err == nil && now.Before(lockedUntil) {
+			lockedFor = max(lockedFor, lockedUntil.Sub(now))
+		} else if allowedAt, ok := loginAllowedAt(loginAttempt); ok && now.Before(allowedAt) {
+			throttledFor = max(throttledFor, allowedAt.Sub(now))
+		}
+	}
+
+	// A lockout outlasts any delay, so it is the one reported
+	reason, wait := "", time.Duration(0)
+	switch {
+	case lockedFor > 0:
+		reason, wait = constants.LoginLockedError, lockedFor
+	case throttledFor > 0:
+		reason, wait = constants.LoginThrottledError, throttledFor
+	default:
+		return nil
+	}
+
+	logSecurityEvent(enums.SECURITY_LOGIN_THROTTLED, logrus.Fields{
+		"username": username,
+		"clientIp": clientIp,
+		"reason":   reason,
+	})
+	return &LoginThrottledError{Message: reason, RetryAfter: wait}
+}
+
+// RecordFailure counts a failed login for the username and the client IP, and locks either out once it reaches its
+// maximum. Failures are forgotten when none happened for a lockout duration.
+func (l *loginAttemptService) RecordFailure(username string, clientIp string) error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	logSecurityEvent(enums.SECURITY_LOGIN_FAILED, logrus.Fields{
+		"username": username,
+		"clientIp": clientIp,
+	})
+
+	now := time.Now()
+	subjects := []struct {
+		attemptType enums.LoginAttemptType
+		subject     string
+		maxFailures int
+		lockEvent   enums.SecurityEventType
+	}{
+		{enums.LOGIN_ATTEMPT_USERNAME, strings.ToLower(username), config.LoginMaxFailures, enums.SECURITY_ACCOUNT_LOCKED},
+		{enums.LOGIN_ATTEMPT_IP, clientIp, config.LoginIpMaxFailures, enums.SECURITY_IP_LOCKED},
+	}
+
+	for _, subject := range subjects {
+		id := loginAttemptId(subject.attemptType, subject.subject)
+		loginAttempt, err := l.loginAttemptRepository.GetById(id)
+		if err != nil && err.Error() != constants.LoginAttemptNotFoundError {
+			return err
+		}
+
+		lastFailureAt, _ := time.Parse(time.RFC3339, loginAttempt.LastFailureAt)
+		lockedUntil, _ := time.Parse(time.RFC3339, loginAttempt.LockedUntil)
+		if err != nil || now.Sub(lastFailureAt) > config.LoginLockoutDuration || (loginAttempt.LockedUntil != "" && !now.Before(lockedUntil)) {
+			loginAttempt = entity.LoginAttempt{Id: id, Type: subject.attemptType, Subject: subject.subject}
+		}
+
+		loginAttempt.Failures++
+		loginAttempt.LastFailureAt = now.Format(time.RFC3339)
+		if subject.maxFailures > 0 && loginAttempt.Failures >= subject.maxFailures && loginAttempt.LockedUntil == "" {
+			loginAttempt.LockedUntil = now.Add(config.LoginLockoutDuration).Format(time.RFC3339)
+			logSecurityEvent(subject.lockEvent, logrus.Fields{
+				"subject":     subject.subject,
+				"failures":    loginAttempt.Failures,
+				"lockedUntil": loginAttempt.LockedUntil,
+			})
+		}
+
+		if err := l.loginAttemptRepository.Save(loginAttempt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RecordSuccess forgets the failed logins of the username. Those of the client IP are kept, logging in to one's own
+// account must not reset the count of an IP guessing the passwords of others.
+func (l *loginAttemptService) RecordSuccess(username string) error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	err := l.loginAttemptRepository.Delete(loginAttemptId(enums.LOGIN_ATTEMPT_USERNAME, username))
+	if err != nil && err.Error() != constants.LoginAttemptNotFoundError {
+		return err
+	}
+	return nil
+}
+
+// GetLockouts lists the usernames and client IPs that are currently locked out
+func (l *loginAttemptService) GetLockouts() ([]entity.LoginAttempt, error) {
+	loginAttempts, err := l.loginAttemptRepository.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	lockouts := []entity.LoginAttempt{}
+	for _, loginAttempt := range loginAttempts {
+		if lockedUntil, err := time.Parse(time.RFC3339, loginAttempt.LockedUntil); err == nil && now.Before(lockedUntil) {
+			lockouts = append(lockouts, loginAttempt)
+		}
+	}
+	return lockouts, nil
+}
+
+// Unlock forgets the failed logins of a username, a client IP or both, lifting their lockout
+func (l *loginAttemptService) Unlock(adminId string, request req.UnlockLoginRequest) error {
+	if request.Username == "" && request.Ip == "" {
+		return errors.New(constants.LoginUnlockRequestError)
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	unlocks := []struct {
+		attemptType enums.LoginAttemptType
+		subject     string
+		event       enums.SecurityEventType
+	}{
+		{enums.LOGIN_ATTEMPT_USERNAME, request.Username, enums.SECURITY_ACCOUNT_UNLOCKED},
+		{enums.LOGIN_ATTEMPT_IP, request.Ip, enums.SECURITY_IP_UNLOCKED},
+	}
+	for _, unlock := range unlocks {
+		if unlock.subject == "" {
+			continue
+		}
+		err := l.loginAttemptRepository.Delete(loginAttemptId(unlock.attemptType, unlock.subject))
+		if err != nil && err.Error() != constants.LoginAttemptNotFoundError {
+			return err
+		}
+		logSecurityEvent(unlock.event, logrus.Fields{
+			"subject": unlock.subject,
+			"adminId": adminId,
+		})
+	}
+	return nil
+}
+
+// loginAllowedAt returns when the next login is accepted after the last failure, the delay doubles with every failure
+func loginAllowedAt(loginAttempt entity.LoginAttempt) (time.Time, bool) {
+	lastFailureAt, err := time.Parse(time.RFC3339, loginAttempt.LastFailureAt)
+	if err != nil || loginAttempt.Failures == 0 {
+		return time.Time{}, false
+	}
+
+	delay := config.LoginFailureBaseDelay
+	for i := 1; i < loginAttempt.Failures && delay < config.LoginFailureMaxDelay; i++ {
+		delay *= 2
+	}
+	return lastFailureAt.Add(min(delay, config.LoginFailureMaxDelay)), true
+}
+
+// loginAttemptId keys the failures of a username case-insensitively, as usernames differing in case look the same
+func loginAttemptId(attemptType enums.LoginAttemptType, subject string) string {
+	if attemptType == enums.LOGIN_ATTEMPT_USERNAME {
+		subject = strings.ToLower(subject)
+	}
+	return string(attemptType) + ":" + subject
+}
@@ -0,0 +1,36 @@
+package service
+
+import (
+	req "PaymentAPI/dto/request"
+	"PaymentAPI/entity"
+	"github.com/stretchr/testify/mock"
+)
+
+type LoginAttemptServiceMock struct {
+	Mock mock.Mock
+}
+
+func (l *LoginAttemptServiceMock) CheckAllowed(username string, clientIp string) error {
+	args := l.Mock.Called(username, clientIp)
+	return args.Error(0)
+}
+
+func (l *LoginAttemptServiceMock) RecordFailure(username string, clientIp string) error {
+	args := l.Mock.Called(username, clientIp)
+	return args.Error(0)
+}
+
+func (l *LoginAttemptServiceMock) RecordSuccess(username string) error {
+	args := l.Mock.Called(username)
+	return args.Error(0)
+}
+
+func (l *LoginAttemptServiceMock) GetLockouts() ([]entity.LoginAttempt, error) {
+	args := l.Mock.Called()
+	return args.Get(0).([]entity.LoginAttempt), args.Error(1)
+}
+
+func (l *LoginAttemptServiceMock) Unlock(adminId string, request req.UnlockLoginRequest) error {
+	args := l.Mock.Called(adminId, request)
+	return args.Error(0)
+}
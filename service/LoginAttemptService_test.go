@@ -0,0 +1,133 @@
+package service
+
+import (
+	"PaymentAPI/config"
+	"PaymentAPI/constants"
+	req "PaymentAPI/dto/request"
+	"PaymentAPI/entity"
+	"PaymentAPI/enums"
+	"PaymentAPI/repository"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+	"time"
+)
+
+func setLoginLimits(t *testing.T) {
+	maxFailures, ipMaxFailures, lockout := config.LoginMaxFailures, config.LoginIpMaxFailures, config.LoginLockoutDuration
+	baseDelay, maxDelay := config.LoginFailureBaseDelay, config.LoginFailureMaxDelay
+	t.Cleanup(func() {
+		config.LoginMaxFailures, config.LoginIpMaxFailures, config.LoginLockoutDuration = maxFailures, ipMaxFailures, lockout
+		config.LoginFailureBaseDelay, config.LoginFailureMaxDelay = baseDelay, maxDelay
+	})
+
+	config.LoginMaxFailures, config.LoginIpMaxFailures, config.LoginLockoutDuration = 5, 20, 15*time.Minute
+	config.LoginFailureBaseDelay, config.LoginFailureMaxDelay = time.Second, 30*time.Second
+}
+
+func TestCheckLoginAllowed(t *testing.T) {
+	setLoginLimits(t)
+	notFound := errors.New(constants.LoginAttemptNotFoundError)
+
+	t.Run("ShouldAllowWithoutFailures", func(t *testing.T) {
+		mockRepository := new(repository.LoginAttemptRepositoryMock)
+		mockRepository.Mock.On("GetById", mock.Anything).Return(entity.LoginAttempt{}, notFound)
+
+		assert.Nil(t, NewLoginAttemptService(mockRepository).CheckAllowed("JohnDoe", "10.0.0.1"))
+		mockRepository.Mock.AssertCalled(t, "GetById", "USERNAME:johndoe")
+		mockRepository.Mock.AssertCalled(t, "GetById", "IP:10.0.0.1")
+	})
+
+	t.Run("ShouldDelayAfterFailures", func(t *testing.T) {
+		mockRepository := new(repository.LoginAttemptRepositoryMock)
+		mockRepository.Mock.On("GetById", "USERNAME:johndoe").Return(entity.LoginAttempt{
+			Id:            "USERNAME:johndoe",
+			Failures:      3,
+			LastFailureAt: time.Now().Format(time.RFC3339),
+		}, nil)
+		mockRepository.Mock.On("GetById", "IP:10.0.0.1").Return(entity.LoginAttempt{}, notFound)
+
+		err := NewLoginAttemptService(mockRepository).CheckAllowed("johndoe", "10.0.0.1")
+		var throttledErr *LoginThrottledError
+		assert.ErrorAs(t, err, &throttledErr)
+		assert.Equal(t, constants.LoginThrottledError, throttledErr.Message)
+		assert.InDelta(t, 4*time.Second, throttledErr.RetryAfter, float64(time.Second))
+	})
+
+	t.Run("ShouldRefuseLockedIp", func(t *testing.T) {
+		mockRepository := new(repository.LoginAttemptRepositoryMock)
+		mockRepository.Mock.On("GetById", "USERNAME:johndoe").Return(entity.LoginAttempt{}, notFound)
+		mockRepository.Mock.On("GetById", "IP:10.0.0.1").Return(entity.LoginAttempt{
+			Id:            "IP:10.0.0.1",
+			Failures:      20,
+			LastFailureAt: time.Now().Format(time.RFC3339),
+			LockedUntil:   time.Now().Add(10 * time.Minute).Format(time.RFC3339),
+		}, nil)
+
+		err := NewLoginAttemptService(mockRepository).CheckAllowed("johndoe", "10.0.0.1")
+		var throttledErr *LoginThrottledError
+		assert.ErrorAs(t, err, &throttledErr)
+		assert.Equal(t, constants.LoginLockedError, throttledErr.Message)
+		assert.InDelta(t, 10*time.Minute, throttledErr.RetryAfter, float64(time.Second))
+	})
+}
+
+func TestRecordLoginFailure(t *testing.T) {
+	setLoginLimits(t)
+
+	t.Run("ShouldLockUsernameAtMaxFailures", func(t *testing.T) {
+		mockRepository := new(repository.LoginAttemptRepositoryMock)
+		mockRepository.Mock.On("GetById", "USERNAME:johndoe").Return(entity.LoginAttempt{
+			Id:            "USERNAME:johndoe",
+			Type:          enums.LOGIN_ATTEMPT_USERNAME,
+			Subject:       "johndoe",
+			Failures:      4,
+			LastFailureAt: time.Now().Add(-time.Minute).Format(time.RFC3339),
+		}, nil)
+		mockRepository.Mock.On("GetById", "IP:10.0.0.1").Return(entity.LoginAttempt{}, errors.New(constants.LoginAttemptNotFoundError))
+		mockRepository.Mock.On("Save", mock.Anything).Return(nil)
+
+		assert.Nil(t, NewLoginAttemptService(mockRepository).RecordFailure("johndoe", "10.0.0.1"))
+
+		username := mockRepository.Mock.Calls[1].Arguments.Get(0).(entity.LoginAttempt)
+		assert.Equal(t, 5, username.Failures)
+		assert.NotEmpty(t, username.LockedUntil)
+
+		ip := mockRepository.Mock.Calls[3].Arguments.Get(0).(entity.LoginAttempt)
+		assert.Equal(t, entity.LoginAttempt{Id: "IP:10.0.0.1", Type: enums.LOGIN_ATTEMPT_IP, Subject: "10.0.0.1", Failures: 1, LastFailureAt: ip.LastFailureAt}, ip)
+	})
+
+	t.Run("ShouldForgetOldFailures", func(t *testing.T) {
+		mockRepository := new(repository.LoginAttemptRepositoryMock)
+		mockRepository.Mock.On("GetById", mock.Anything).Return(entity.LoginAttempt{
+			Id:            "USERNAME:johndoe",
+			Failures:      4,
+			LastFailureAt: time.Now().Add(-time.Hour).Format(time.RFC3339),
+		}, nil)
+		mockRepository.Mock.On("Save", mock.Anything).Return(nil)
+
+		assert.Nil(t, NewLoginAttemptService(mockRepository).RecordFailure("johndoe", "10.0.0.1"))
+
+		username := mockRepository.Mock.Calls[1].Arguments.Get(0).(entity.LoginAttempt)
+		assert.Equal(t, 1, username.Failures)
+		assert.Empty(t, username.LockedUntil)
+	})
+}
+
+func TestUnlockLogin(t *testing.T) {
+	t.Run("ShouldDeleteUsernameAndIp", func(t *testing.T) {
+		mockRepository := new(repository.LoginAttemptRepositoryMock)
+		mockRepository.Mock.On("Delete", "USERNAME:johndoe").Return(nil)
+		mockRepository.Mock.On("Delete", "IP:10.0.0.1").Return(errors.New(constants.LoginAttemptNotFoundError))
+
+		err := NewLoginAttemptService(mockRepository).Unlock("admin-1", req.UnlockLoginRequest{Username: "JohnDoe", Ip: "10.0.0.1"})
+		assert.Nil(t, err)
+		mockRepository.Mock.AssertExpectations(t)
+	})
+
+	t.Run("ShouldRequireUsernameOrIp", func(t *testing.T) {
+		err := NewLoginAttemptService(new(repository.LoginAttemptRepositoryMock)).Unlock("admin-1", req.UnlockLoginRequest{})
+		assert.Equal(t, constants.LoginUnlockRequestError, err.Error())
+	})
+}
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"PaymentAPI/entity"
+	"PaymentAPI/enums"
+	"github.com/sirupsen/logrus"
+)
+
+type MerchantCallbackService interface {
+	NotifyCheckoutSession(merchant entity.Merchant, checkoutSession entity.CheckoutSession)
+}
+
+type merchantCallbackService struct {
+	webhookService WebhookService
+}
+
+// NewMerchantCallbackService creates a new instance of MerchantCallbackService
+func NewMerchantCallbackService(webhookService WebhookService) MerchantCallbackService {
+	return &merchantCallbackService{webhookService: webhookService}
+}
+
+// NotifyCheckoutSession sends the checkout session to the merchant's callback endpoint as a checkout.paid or
+// checkout.expired event. It goes through the webhook delivery queue, so it is signed with the callback secret and
+// retried until it is delivered. A callback that cannot be queued is only logged, the merchant can always poll the session.
+func (m *merchantCallbackService) NotifyCheckoutSession(merchant entity.Merchant, checkoutSession entity.CheckoutSession) {
+	if merchant.CallbackEndpointId == "" {
+		return
+	}
+
+	eventType := enums.EVENT_CHECKOUT_PAID
+	if checkoutSession.Status == enums.CHECKOUT_EXPIRED {
+		eventType = enums.EVENT_CHECKOUT_EXPIRED
+	}
+
+	if err := m.webhookService.Enqueue(merchant.CallbackEndpointId, eventType, checkoutSession); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"merchantId":        merchant.Id,
+			"checkoutSessionId": checkoutSession.Id,
+			"status":            checkoutSession.Status,
+		}).Error("Failed to queue checkout session callback", err)
+	}
+}
@@ -0,0 +1,14 @@
+package service
+
+import (
+	"PaymentAPI/entity"
+	"github.com/stretchr/testify/mock"
+)
+
+type MerchantCallbackServiceMock struct {
+	mock.Mock
+}
+
+func (m *MerchantCallbackServiceMock) NotifyCheckoutSession(merchant entity.Merchant, checkoutSession entity.CheckoutSession) {
+	m.Called(merchant, checkoutSession)
+}
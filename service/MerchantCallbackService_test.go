@@ -0,0 +1,40 @@
+package service
+
+import (
+	"PaymentAPI/entity"
+	"PaymentAPI/enums"
+	"github.com/stretchr/testify/mock"
+	"testing"
+)
+
+func TestNotifyCheckoutSession(t *testing.T) {
+	tests := []struct {
+		name              string
+		status            enums.CheckoutSessionStatus
+		expectedEventType enums.EventType
+	}{
+		{name: "Should Queue Paid Event", status: enums.CHECKOUT_PAID, expectedEventType: enums.EVENT_CHECKOUT_PAID},
+		{name: "Should Queue Expired Event", status: enums.CHECKOUT_EXPIRED, expectedEventType: enums.EVENT_CHECKOUT_EXPIRED},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockWebhookService := new(WebhookServiceMock)
+			merchantCallbackService := NewMerchantCallbackService(mockWebhookService)
+
+			checkoutSession := entity.CheckoutSession{Id: "checkout-1", MerchantId: "merchant-1", Status: tt.status}
+			mockWebhookService.On("Enqueue", "endpoint-1", tt.expectedEventType, checkoutSession).Return(nil)
+
+			merchantCallbackService.NotifyCheckoutSession(entity.Merchant{Id: "merchant-1", CallbackEndpointId: "endpoint-1"}, checkoutSession)
+			mockWebhookService.AssertExpectations(t)
+		})
+	}
+
+	t.Run("ShouldSkipMerchantWithoutCallback", func(t *testing.T) {
+		mockWebhookService := new(WebhookServiceMock)
+		merchantCallbackService := NewMerchantCallbackService(mockWebhookService)
+
+		merchantCallbackService.NotifyCheckoutSession(entity.Merchant{Id: "merchant-1"}, entity.CheckoutSession{Id: "checkout-1", Status: enums.CHECKOUT_PAID})
+		mockWebhookService.AssertNotCalled(t, "Enqueue", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
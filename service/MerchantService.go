@@ -0,0 +1,95 @@
+package service
+
+import (
+	"PaymentAPI/constants"
+	req "PaymentAPI/dto/request"
+	"PaymentAPI/entity"
+	"PaymentAPI/enums"
+	"PaymentAPI/repository"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type MerchantService interface {
+	CreateMerchant(customerId string, request req.CreateMerchantRequest) (entity.Merchant, error)
+	GetMerchantById(id string) (entity.Merchant, error)
+}
+
+type merchantService struct {
+	merchantRepository repository.MerchantRepository
+	walletService      WalletService
+	webhookService     WebhookService
+}
+
+// NewMerchantService creates a new instance of MerchantService
+func NewMerchantService(merchantRepository repository.MerchantRepository, walletService WalletService, webhookService WebhookService) MerchantService {
+	return &merchantService{merchantRepository, walletService, webhookService}
+}
+
+// CreateMerchant registers a merchant account for a customer, checkout payments are settled into the customer's wallet.
+// The callback URL is registered as a webhook endpoint of the customer, so callbacks are signed and retried like webhooks.
+func (m *merchantService) CreateMerchant(customerId string, request req.CreateMerchantRequest) (entity.Merchant, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"customerId": customerId,
+		"name":       request.Name,
+	})
+
+	logger.Info("Creating new merchant")
+
+	if strings.TrimSpace(request.Name) == "" {
+		return entity.Merchant{}, errors.New(constants.MerchantNameRequiredError)
+	}
+	if request.CallbackUrl != "" && !isCallbackUrl(request.CallbackUrl) {
+		return entity.Merchant{}, errors.New(constants.MerchantCallbackUrlError)
+	}
+
+	wallet, err := m.walletService.GetWalletByCustomerId(customerId)
+	if err != nil {
+		logger.Error("Failed to retrieve customer wallet", err)
+		return entity.Merchant{}, err
+	}
+
+	merchant := entity.Merchant{
+		Id:          uuid.New().String(),
+		CustomerId:  customerId,
+		WalletId:    wallet.Id,
+		Name:        strings.TrimSpace(request.Name),
+		CallbackUrl: request.CallbackUrl,
+		CreatedAt:   time.Now().Format(time.RFC3339),
+	}
+
+	var callbackSecret string
+	if merchant.CallbackUrl != "" {
+		webhookEndpoint, err := m.webhookService.CreateCallbackEndpoint(customerId, merchant.CallbackUrl, enums.CheckoutEventTypes)
+		if err != nil {
+			logger.Error("Failed to register merchant callback", err)
+			return entity.Merchant{}, err
+		}
+		merchant.CallbackEndpointId = webhookEndpoint.Id
+		callbackSecret = webhookEndpoint.Secret
+	}
+
+	if err := m.merchantRepository.Create(merchant); err != nil {
+		logger.Error("Failed to create merchant", err)
+		return entity.Merchant{}, err
+	}
+	merchant.CallbackSecret = callbackSecret
+
+	logger.Info("Merchant created successfully")
+	return merchant, nil
+}
+
+// GetMerchantById retrieves a merchant by its ID
+func (m *merchantService) GetMerchantById(id string) (entity.Merchant, error) {
+	return m.merchantRepository.GetById(id)
+}
+
+// isCallbackUrl accepts an absolute http or https URL whose host is public, see isPublicHost
+func isCallbackUrl(rawUrl string) bool {
+	parsed, err := url.ParseRequestURI(rawUrl)
+	return err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != "" && isPublicHost(parsed.Hostname())
+}
@@ -0,0 +1,21 @@
+package service
+
+import (
+	req "PaymentAPI/dto/request"
+	"PaymentAPI/entity"
+	"github.com/stretchr/testify/mock"
+)
+
+type MerchantServiceMock struct {
+	mock.Mock
+}
+
+func (m *MerchantServiceMock) CreateMerchant(customerId string, request req.CreateMerchantRequest) (entity.Merchant, error) {
+	args := m.Called(customerId, request)
+	return args.Get(0).(entity.Merchant), args.Error(1)
+}
+
+func (m *MerchantServiceMock) GetMerchantById(id string) (entity.Merchant, error) {
+	args := m.Called(id)
+	return args.Get(0).(entity.Merchant), args.Error(1)
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"encoding/json"
+	"github.com/sirupsen/logrus"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Notification is a message for a customer, such as the token to reset their password
+type Notification struct {
+	Recipient string `json:"recipient"`
+	Subject   string `json:"subject"`
+	Body      string `json:"body"`
+	SentAt    string `json:"sent_at"`
+}
+
+// Notifier delivers notifications to customers, by email or SMS in production and locally during development
+type Notifier interface {
+	Send(notification Notification) error
+}
+
+type logNotifier struct{}
+
+// NewLogNotifier creates a Notifier that writes every notification to the application log
+func NewLogNotifier() Notifier {
+	return &logNotifier{}
+}
+
+// Send logs the notification including its body, so it must not be used where the log is shared
+func (l *logNotifier) Send(notification Notification) error {
+	logrus.WithFields(logrus.Fields{
+		"recipient": notification.Recipient,
+		"subject":   notification.Subject,
+		"body":      notification.Body,
+	}).Info("Notification sent")
+	return nil
+}
+
+type fileNotifier struct {
+	path string
+	lock sync.Mutex
+}
+
+// NewFileNotifier creates a Notifier that appends every notification as a JSON line to the file at the path
+func NewFileNotifier(path string) Notifier {
+	return &fileNotifier{path: path}
+}
+
+// Send appends the notification to the file, creating the file and its directory when they do not exist yet
+func (f *fileNotifier) Send(notification Notification) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if notification.SentAt == "" {
+		notification.SentAt = time.Now().Format(time.RFC3339)
+	}
+	line, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(f.path), 0o700); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(f.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(line, '\n'))
+	return err
+}
@@ -0,0 +1,14 @@
+package service
+
+import (
+	"github.com/stretchr/testify/mock"
+)
+
+type NotifierMock struct {
+	Mock mock.Mock
+}
+
+func (n *NotifierMock) Send(notification Notification) error {
+	args := n.Mock.Called(notification)
+	return args.Error(0)
+}
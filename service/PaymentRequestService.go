@@ -0,0 +1,264 @@
+package service
+
+import (
+	"PaymentAPI/config"
+	"PaymentAPI/constants"
+	req "PaymentAPI/dto/request"
+	"PaymentAPI/entity"
+	"PaymentAPI/enums"
+	"PaymentAPI/repository"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"sync"
+	"time"
+)
+
+type PaymentRequestService interface {
+	CreatePaymentRequest(request req.CreatePaymentRequestRequest) (entity.PaymentRequest, error)
+	GetPaymentRequestById(id string) (entity.PaymentRequest, error)
+	GetIncomingPaymentRequests(walletId string) ([]entity.PaymentRequest, error)
+	GetOutgoingPaymentRequests(walletId string) ([]entity.PaymentRequest, error)
+	AcceptPaymentRequest(id string, stepUp req.StepUp) (entity.PaymentRequest, error)
+	DeclinePaymentRequest(id string) (entity.PaymentRequest, error)
+	ExpirePaymentRequests() error
+}
+
+type paymentRequestService struct {
+	paymentRequestRepository repository.PaymentRequestRepository
+	walletService            WalletService
+	transactionService       TransactionService
+
+	// lock serializes every status change of a payment request, so a request is accepted, declined or expired only once
+	lock sync.Mutex
+}
+
+// NewPaymentRequestService creates a new instance of PaymentRequestService
+func NewPaymentRequestService(paymentRequestRepository repository.PaymentRequestRepository, walletService WalletService, transactionService TransactionService) PaymentRequestService {
+	return &paymentRequestService{paymentRequestRepository: paymentRequestRepository, walletService: walletService, transactionService: transactionService}
+}
+
+// CreatePaymentRequest asks the payer wallet to pay an amount to the requester wallet
+func (p *paymentRequestService) CreatePaymentRequest(request req.CreatePaymentRequestRequest) (entity.PaymentRequest, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"requesterWalletId": request.RequesterWalletId,
+		"payerWalletId":     request.PayerWalletId,
+		"amount":            request.Amount,
+	})
+
+	logger.Info("Creating new payment request")
+
+	if request.Amount <= 0 {
+		return entity.PaymentRequest{}, errors.New(constants.TransactionInvalidAmountError)
+	}
+	if request.RequesterWalletId == request.PayerWalletId {
+		return entity.PaymentRequest{}, errors.New(constants.PaymentRequestSameWalletError)
+	}
+
+	// Both wallets must exist, the balance is only checked once the payer accepts
+	if _, err := p.walletService.GetWalletById(request.RequesterWalletId); err != nil {
+		logger.Error("Failed to retrieve requester wallet", err)
+		return entity.PaymentRequest{}, err
+	}
+	if _, err := p.walletService.GetWalletById(request.PayerWalletId); err != nil {
+		logger.Error("Failed to retrieve payer wallet", err)
+		return entity.PaymentRequest{}, err
+	}
+
+	now := time.Now()
+	paymentRequest := entity.PaymentRequest{
+		Id:                uuid.New().String(),
+		RequesterWalletId: request.RequesterWalletId,
+		PayerWalletId:     request.PayerWalletId,
+		Amount:            request.Amount,
+		Message:           request.Message,
+		Status:            enums.PAYMENT_REQUEST_PENDING,
+		CreatedAt:         now.Format(time.RFC3339),
+		ExpiresAt:         now.Add(config.PaymentRequestExpirationDuration).Format(time.RFC3339),
+		SplitBillId:       request.SplitBillId,
+	}
+
+	if err := p.paymentRequestRepository.Create(paymentRequest); err != nil {
+		logger.Error("Failed to create payment request", err)
+		return entity.PaymentRequest{}, err
+	}
+
+	logger.Info("Payment request created successfully")
+	return paymentRequest, nil
+}
+
+// GetPaymentRequestById retrieves a payment request, marking it expired when its expiry has passed
+func (p *paymentRequestService) GetPaymentRequestById(id string) (entity.PaymentRequest, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	return p.getPaymentRequest(id)
+}
+
+// getPaymentRequest is GetPaymentRequestById for a caller that already holds the lock
+func (p *paymentRequestService) getPaymentRequest(id string) (entity.PaymentRequest, error) {
+	paymentRequest, err := p.paymentRequestRepository.GetById(id)
+	if err != nil {
+		return entity.PaymentRequest{}, err
+	}
+	return p.expireIfDue(paymentRequest, time.Now())
+}
+
+// GetIncomingPaymentRequests lists the payment requests the wallet has been asked to pay
+func (p *paymentRequestService) GetIncomingPaymentRequests(walletId string) ([]entity.PaymentRequest, error) {
+	return p.filterPaymentRequests(func(paymentRequest entity.PaymentRequest) bool {
+		return paymentRequest.PayerWalletId == walletId
+	})
+}
+
+// GetOutgoingPaymentRequests lists the payment requests the wallet has sent
+func (p *paymentRequestService) GetOutgoingPaymentRequests(walletId string) ([]entity.PaymentRequest, error) {
+	return p.filterPaymentRequests(func(paymentRequest entity.PaymentRequest) bool {
+		return paymentRequest.RequesterWalletId == walletId
+	})
+}
+
+// AcceptPaymentRequest pays a pending payment request by transferring from the payer to the requester, with the
+// step-up of the payer
+func (p *paymentRequestService) AcceptPaymentRequest(id string, stepUp req.StepUp) (entity.PaymentRequest, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"paymentRequestId": id,
+	})
+
+	logger.Info("Accepting payment request")
+
+	// The lock is held until the transfer is done, a concurrent accept or decline waits and then finds the request accepted
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	paymentRequest, err := p.getPaymentRequest(id)
+	if err != nil {
+		return entity.PaymentRequest{}, err
+	}
+	if paymentRequest.Status != enums.PAYMENT_REQUEST_PENDING {
+		return entity.PaymentRequest{}, errors.New(constants.PaymentRequestNotPendingError)
+	}
+
+	// Mark the request accepted before paying, it is put back to pending if the transfer fails
+	pendingPaymentRequest := paymentRequest
+	paymentRequest.Status = enums.PAYMENT_REQUEST_ACCEPTED
+	paymentRequest.RespondedAt = time.Now().Format(time.RFC3339)
+	if err := p.paymentRequestRepository.Update(paymentRequest); err != nil {
+		logger.Error("Failed to update payment request", err)
+		return entity.PaymentRequest{}, err
+	}
+
+	transaction, err := p.transactionService.CreateNewTransaction(req.CreateTransactionRequest{
+		FromWalletId: paymentRequest.PayerWalletId,
+		ToWalletId:   paymentRequest.RequesterWalletId,
+		Amount:       paymentRequest.Amount,
+		Message:      paymentRequest.Message,
+		StepUp:       stepUp,
+	})
+	if err != nil {
+		// Keep the request pending when the transfer fails, so the payer can retry
+		logger.Error("Failed to pay payment request", err)
+		p.paymentRequestRepository.Update(pendingPaymentRequest)
+		return entity.PaymentRequest{}, err
+	}
+
+	paymentRequest.TransactionId = transaction.Id
+	if err := p.paymentRequestRepository.Update(paymentRequest); err != nil {
+		logger.Error("Failed to link transaction to payment request", err)
+		return entity.PaymentRequest{}, err
+	}
+
+	logger.Info("Payment request accepted successfully")
+	return paymentRequest, nil
+}
+
+// DeclinePaymentRequest refuses a pending payment request without moving any funds
+func (p *paymentRequestService) DeclinePaymentRequest(id string) (entity.PaymentRequest, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"paymentRequestId": id,
+	})
+
+	logger.Info("Declining payment request")
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	paymentRequest, err := p.getPaymentRequest(id)
+	if err != nil {
+		return entity.PaymentRequest{}, err
+	}
+	if paymentRequest.Status != enums.PAYMENT_REQUEST_PENDING {
+		return entity.PaymentRequest{}, errors.New(constants.PaymentRequestNotPendingError)
+	}
+
+	paymentRequest.Status = enums.PAYMENT_REQUEST_DECLINED
+	paymentRequest.RespondedAt = time.Now().Format(time.RFC3339)
+	if err := p.paymentRequestRepository.Update(paymentRequest); err != nil {
+		logger.Error("Failed to decline payment request", err)
+		return entity.PaymentRequest{}, err
+	}
+
+	logger.Info("Payment request declined successfully")
+	return paymentRequest, nil
+}
+
+// ExpirePaymentRequests marks every pending payment request past its expiry as expired
+func (p *paymentRequestService) ExpirePaymentRequests() error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	paymentRequests, err := p.paymentRequestRepository.GetAll()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, paymentRequest := range paymentRequests {
+		if _, err := p.expireIfDue(paymentRequest, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *paymentRequestService) filterPaymentRequests(match func(entity.PaymentRequest) bool) ([]entity.PaymentRequest, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	paymentRequests, err := p.paymentRequestRepository.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	result := []entity.PaymentRequest{}
+	for _, paymentRequest := range paymentRequests {
+		if !match(paymentRequest) {
+			continue
+		}
+		paymentRequest, err := p.expireIfDue(paymentRequest, now)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, paymentRequest)
+	}
+	return result, nil
+}
+
+func (p *paymentRequestService) expireIfDue(paymentRequest entity.PaymentRequest, now time.Time) (entity.PaymentRequest, error) {
+	if paymentRequest.Status != enums.PAYMENT_REQUEST_PENDING {
+		return paymentRequest, nil
+	}
+	expiresAt, err := time.Parse(time.RFC3339, paymentRequest.ExpiresAt)
+	if err != nil || now.Before(expiresAt) {
+		return paymentRequest, nil
+	}
+
+	paymentRequest.Status = enums.PAYMENT_REQUEST_EXPIRED
+	if err := p.paymentRequestRepository.Update(paymentRequest); err != nil {
+		return entity.PaymentRequest{}, err
+	}
+
+	logrus.WithFields(logrus.Fields{"paymentRequestId": paymentRequest.Id}).Info("Payment request expired")
+	return paymentRequest, nil
+}
@@ -0,0 +1,46 @@
+package service
+
+import (
+	req "PaymentAPI/dto/request"
+	"PaymentAPI/entity"
+	"github.com/stretchr/testify/mock"
+)
+
+type PaymentRequestServiceMock struct {
+	mock.Mock
+}
+
+func (p *PaymentRequestServiceMock) CreatePaymentRequest(request req.CreatePaymentRequestRequest) (entity.PaymentRequest, error) {
+	args := p.Called(request)
+	return args.Get(0).(entity.PaymentRequest), args.Error(1)
+}
+
+func (p *PaymentRequestServiceMock) GetPaymentRequestById(id string) (entity.PaymentRequest, error) {
+	args := p.Called(id)
+	return args.Get(0).(entity.PaymentRequest), args.Error(1)
+}
+
+func (p *PaymentRequestServiceMock) GetIncomingPaymentRequests(walletId string) ([]entity.PaymentRequest, error) {
+	args := p.Called(walletId)
+	return args.Get(0).([]entity.PaymentRequest), args.Error(1)
+}
+
+func (p *PaymentRequestServiceMock) GetOutgoingPaymentRequests(walletId string) ([]entity.PaymentRequest, error) {
+	args := p.Called(walletId)
+	return args.Get(0).([]entity.PaymentRequest), args.Error(1)
+}
+
+func (p *PaymentRequestServiceMock) AcceptPaymentRequest(id string, stepUp req.StepUp) (entity.PaymentRequest, error) {
+	args := p.Called(id, stepUp)
+	return args.Get(0).(entity.PaymentRequest), args.Error(1)
+}
+
+func (p *PaymentRequestServiceMock) DeclinePaymentRequest(id string) (entity.PaymentRequest, error) {
+	args := p.Called(id)
+	return args.Get(0).(entity.PaymentRequest), args.Error(1)
+}
+
+func (p *PaymentRequestServiceMock) ExpirePaymentRequests() error {
+	args := p.Called()
+	return args.Error(0)
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"PaymentAPI/constants"
+	req "PaymentAPI/dto/request"
+	"PaymentAPI/entity"
+	"PaymentAPI/enums"
+	"PaymentAPI/repository"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+	"time"
+)
+
+type paymentRequestTest struct {
+	mockPaymentRequestRepository *repository.PaymentRequestRepositoryMock
+	mockWalletService            *WalletServiceMock
+	mockTransactionService       *TransactionServiceMock
+	service                      PaymentRequestService
+}
+
+func setupPaymentRequestTest() paymentRequestTest {
+	mockPaymentRequestRepository := new(repository.PaymentRequestRepositoryMock)
+	mockWalletService := new(WalletServiceMock)
+	mockTransactionService := new(TransactionServiceMock)
+	return paymentRequestTest{
+		mockPaymentRequestRepository: mockPaymentRequestRepository,
+		mockWalletService:            mockWalletService,
+		mockTransactionService:       mockTransactionService,
+		service:                      NewPaymentRequestService(mockPaymentRequestRepository, mockWalletService, mockTransactionService),
+	}
+}
+
+func pendingPaymentRequest() entity.PaymentRequest {
+	return entity.PaymentRequest{
+		Id:                "payment-request-1",
+		RequesterWalletId: "wallet-1",
+		PayerWalletId:     "wallet-2",
+		Amount:            5000,
+		Message:           "Dinner",
+		Status:            enums.PAYMENT_REQUEST_PENDING,
+		ExpiresAt:         time.Now().Add(time.Hour).Format(time.RFC3339),
+	}
+}
+
+func TestCreatePaymentRequest(t *testing.T) {
+	tests := []struct {
+		name          string
+		request       req.CreatePaymentRequestRequest
+		expectedError string
+	}{
+		{
+			name:    "Should Create Payment Request",
+			request: req.CreatePaymentRequestRequest{RequesterWalletId: "wallet-1", PayerWalletId: "wallet-2", Amount: 5000},
+		},
+		{
+			name:          "Should Fail On Same Wallet",
+			request:       req.CreatePaymentRequestRequest{RequesterWalletId: "wallet-1", PayerWalletId: "wallet-1", Amount: 5000},
+			expectedError: constants.PaymentRequestSameWalletError,
+		},
+		{
+			name:          "Should Fail On Invalid Amount",
+			request:       req.CreatePaymentRequestRequest{RequesterWalletId: "wallet-1", PayerWalletId: "wallet-2", Amount: 0},
+			expectedError: constants.TransactionInvalidAmountError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			test := setupPaymentRequestTest()
+
+			test.mockWalletService.On("GetWalletById", mock.Anything).Return(entity.Wallet{}, nil)
+			test.mockPaymentRequestRepository.Mock.On("Create", mock.Anything).Return(nil)
+
+			paymentRequest, err := test.service.CreatePaymentRequest(tt.request)
+			if tt.expectedError != "" {
+				assert.Equal(t, tt.expectedError, err.Error())
+				test.mockPaymentRequestRepository.Mock.AssertNotCalled(t, "Create", mock.Anything)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, enums.PAYMENT_REQUEST_PENDING, paymentRequest.Status)
+				assert.Equal(t, tt.request.Amount, paymentRequest.Amount)
+			}
+		})
+	}
+}
+
+func TestAcceptPaymentRequest(t *testing.T) {
+	t.Run("ShouldTransferFromPayerToRequester", func(t *testing.T) {
+		test := setupPaymentRequestTest()
+		paymentRequest := pendingPaymentRequest()
+
+		test.mockPaymentRequestRepository.Mock.On("GetById", paymentRequest.Id).Return(paymentRequest, nil)
+		test.mockPaymentRequestRepository.Mock.On("Update", mock.Anything).Return(nil)
+		test.mockTransactionService.On("CreateNewTransaction", req.CreateTransactionRequest{
+			FromWalletId: "wallet-2",
+			ToWalletId:   "wallet-1",
+			Amount:       5000,
+			Message:      "Dinner",
+		}).Return(entity.Transaction{Id: "transaction-1"}, nil)
+
+		accepted, err := test.service.AcceptPaymentRequest(paymentRequest.Id, req.StepUp{})
+		assert.Nil(t, err)
+		assert.Equal(t, enums.PAYMENT_REQUEST_ACCEPTED, accepted.Status)
+		assert.Equal(t, "transaction-1", accepted.TransactionId)
+	})
+
+	t.Run("ShouldKeepRequestPendingWhenTransferFails", func(t *testing.T) {
+		test := setupPaymentRequestTest()
+		paymentRequest := pendingPaymentRequest()
+
+		test.mockPaymentRequestRepository.Mock.On("GetById", paymentRequest.Id).Return(paymentRequest, nil)
+		test.mockPaymentRequestRepository.Mock.On("Update", mock.Anything).Return(nil)
+		test.mockTransactionService.On("CreateNewTransaction", mock.Anything).
+			Return(entity.Transaction{}, errors.New(constants.TransactionInsufficientError))
+
+		_, err := test.service.AcceptPaymentRequest(paymentRequest.Id, req.StepUp{})
+		assert.Equal(t, constants.TransactionInsufficientError, err.Error())
+		test.mockPaymentRequestRepository.Mock.AssertCalled(t, "Update", paymentRequest)
+	})
+
+	t.Run("ShouldRejectExpiredRequest", func(t *testing.T) {
+		test := setupPaymentRequestTest()
+		paymentRequest := pendingPaymentRequest()
+		paymentRequest.ExpiresAt = time.Now().Add(-time.Minute).Format(time.RFC3339)
+
+		test.mockPaymentRequestRepository.Mock.On("GetById", paymentRequest.Id).Return(paymentRequest, nil)
+		test.mockPaymentRequestRepository.Mock.On("Update", mock.MatchedBy(func(updated entity.PaymentRequest) bool {
+			return updated.Status == enums.PAYMENT_REQUEST_EXPIRED
+		})).Return(nil)
+
+		_, err := test.service.AcceptPaymentRequest(paymentRequest.Id, req.StepUp{})
+		assert.Equal(t, constants.PaymentRequestNotPendingError, err.Error())
+		test.mockTransactionService.AssertNotCalled(t, "CreateNewTransaction", mock.Anything)
+	})
+}
+
+func TestDeclinePaymentRequest(t *testing.T) {
+	test := setupPaymentRequestTest()
+	paymentRequest := pendingPaymentRequest()
+
+	test.mockPaymentRequestRepository.Mock.On("GetById", paymentRequest.Id).Return(paymentRequest, nil)
+	test.mockPaymentRequestRepository.Mock.On("Update", mock.MatchedBy(func(updated entity.PaymentRequest) bool {
+		return updated.Status == enums.PAYMENT_REQUEST_DECLINED
+	})).Return(nil)
+
+	declined, err := test.service.DeclinePaymentRequest(paymentRequest.Id)
+	assert.Nil(t, err)
+	assert.Equal(t, enums.PAYMENT_REQUEST_DECLINED, declined.Status)
+	test.mockTransactionService.AssertNotCalled(t, "CreateNewTransaction", mock.Anything)
+}
+
+func TestGetIncomingPaymentRequests(t *testing.T) {
+	test := setupPaymentRequestTest()
+	incoming := pendingPaymentRequest()
+	outgoing := pendingPaymentRequest()
+	outgoing.Id, outgoing.RequesterWalletId, outgoing.PayerWalletId = "payment-request-2", "wallet-2", "wallet-1"
+
+	test.mockPaymentRequestRepository.Mock.On("GetAll").Return([]entity.PaymentRequest{incoming, outgoing}, nil)
+
+	paymentRequests, err := test.service.GetIncomingPaymentRequests("wallet-2")
+	assert.Nil(t, err)
+	assert.Equal(t, []entity.PaymentRequest{incoming}, paymentRequests)
+}
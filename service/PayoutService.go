@@ -0,0 +1,190 @@
+package service
+
+import (
+	"PaymentAPI/config"
+	"PaymentAPI/constants"
+	req "PaymentAPI/dto/request"
+	"PaymentAPI/entity"
+	"PaymentAPI/enums"
+	"PaymentAPI/repository"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"time"
+)
+
+type PayoutService interface {
+	CreatePayoutBatch(request req.CreatePayoutBatchRequest) (entity.PayoutBatch, error)
+	GetPayoutBatchById(id string) (entity.PayoutBatch, error)
+}
+
+type payoutService struct {
+	payoutBatchRepository repository.PayoutBatchRepository
+	walletService         WalletService
+	transactionService    TransactionService
+}
+
+// NewPayoutService creates a new instance of PayoutService
+func NewPayoutService(payoutBatchRepository repository.PayoutBatchRepository, walletService WalletService, transactionService TransactionService) PayoutService {
+	return &payoutService{payoutBatchRepository, walletService, transactionService}
+}
+
+// CreatePayoutBatch pays every row of the batch from one wallet. All rows are validated and the total is checked
+// against the available balance before the first transfer, a batch failing either check is stored as rejected
+// without moving any funds. The step-up is checked once for the total, then every row is transferred on its own and
+// gets its own result.
+func (p *payoutService) CreatePayoutBatch(request req.CreatePayoutBatchRequest) (entity.PayoutBatch, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"walletId": request.WalletId,
+		"rows":     len(request.Items),
+	})
+
+	logger.Info("Creating new payout batch")
+
+	if len(request.Items) == 0 {
+		return entity.PayoutBatch{}, errors.New(constants.PayoutBatchEmptyError)
+	}
+	if len(request.Items) > config.PayoutMaxRows {
+		return entity.PayoutBatch{}, errors.New(constants.PayoutBatchTooLargeError)
+	}
+
+	wallet, err := p.walletService.GetWalletById(request.WalletId)
+	if err != nil {
+		logger.Error("Failed to retrieve paying wallet", err)
+		return entity.PayoutBatch{}, err
+	}
+
+	batch := entity.PayoutBatch{
+		Id:        uuid.New().String(),
+		WalletId:  wallet.Id,
+		Status:    enums.PAYOUT_BATCH_PROCESSING,
+		CreatedAt: time.Now().Format(time.RFC3339),
+	}
+
+	// Validate every row before any transfer is made
+	invalidRows := 0
+	for i, itemRequest := range request.Items {
+		item := entity.PayoutItem{
+			Row:               i + 1,
+			RecipientWalletId: itemRequest.RecipientWalletId,
+			Amount:            itemRequest.Amount,
+			Message:           itemRequest.Message,
+			Status:            enums.PAYOUT_ITEM_PENDING,
+		}
+		if err := p.validatePayoutItem(wallet, item); err != nil {
+			item.Status = enums.PAYOUT_ITEM_INVALID
+			item.ErrorMessage = err.Error()
+			invalidRows++
+		}
+		batch.TotalAmount += item.Amount
+		batch.Items = append(batch.Items, item)
+	}
+
+	if invalidRows > 0 {
+		logger.Warn("Payout batch rejected, it has invalid rows")
+		return p.rejectPayoutBatch(batch, constants.PayoutBatchInvalidRowsError)
+	}
+
+	// The whole batch must be covered before the first transfer, so it is not left half paid for lack of funds
+	availableBalance, err := p.walletService.GetAvailableBalance(wallet.Id)
+	if err != nil {
+		logger.Error("Failed to calculate available balance", err)
+		return entity.PayoutBatch{}, err
+	}
+	if availableBalance-batch.TotalAmount < 0 {
+		logger.Warn("Payout batch rejected, insufficient balance")
+		return p.rejectPayoutBatch(batch, constants.TransactionInsufficientError)
+	}
+
+	// Rows below the step-up threshold could add up to any amount, so the whole batch is stepped up at once
+	if err := p.transactionService.AuthorizeDebit(wallet.Id, batch.TotalAmount, request.StepUp); err != nil {
+		logger.Warn("Payout batch step-up failed", err)
+		return entity.PayoutBatch{}, err
+	}
+
+	// Store the batch before the transfers, so it can be tracked while it is processing
+	if err := p.payoutBatchRepository.Create(batch); err != nil {
+		logger.Error("Failed to create payout batch", err)
+		return entity.PayoutBatch{}, err
+	}
+
+	for i := range batch.Items {
+		item := &batch.Items[i]
+		transaction, err := p.transactionService.CreateNewTransaction(req.CreateTransactionRequest{
+			FromWalletId: batch.WalletId,
+			ToWalletId:   item.RecipientWalletId,
+			Amount:       item.Amount,
+			Message:      item.Message,
+			StepUp:       req.StepUp{Authorized: true},
+		})
+		if err != nil {
+			logrus.WithFields(logrus.Fields{"payoutBatchId": batch.Id, "row": item.Row}).Error("Payout transfer failed", err)
+			item.Status = enums.PAYOUT_ITEM_FAILED
+			item.ErrorMessage = err.Error()
+			batch.FailedCount++
+			continue
+		}
+		item.Status = enums.PAYOUT_ITEM_SUCCEEDED
+		item.TransactionId = transaction.Id
+		batch.SucceededCount++
+	}
+
+	switch {
+	case batch.FailedCount == 0:
+		batch.Status = enums.PAYOUT_BATCH_COMPLETED
+	case batch.SucceededCount == 0:
+		batch.Status = enums.PAYOUT_BATCH_FAILED
+	default:
+		batch.Status = enums.PAYOUT_BATCH_PARTIALLY_COMPLETED
+	}
+	batch.CompletedAt = time.Now().Format(time.RFC3339)
+
+	if err := p.payoutBatchRepository.Update(batch); err != nil {
+		logger.Error("Failed to update payout batch", err)
+		return entity.PayoutBatch{}, err
+	}
+
+	logger.WithFields(logrus.Fields{
+		"payoutBatchId": batch.Id,
+		"status":        batch.Status,
+		"succeeded":     batch.SucceededCount,
+		"failed":        batch.FailedCount,
+	}).Info("Payout batch processed")
+	return batch, nil
+}
+
+// GetPayoutBatchById retrieves a payout batch with the result of every row
+func (p *payoutService) GetPayoutBatchById(id string) (entity.PayoutBatch, error) {
+	return p.payoutBatchRepository.GetById(id)
+}
+
+// validatePayoutItem applies the checks of a transfer that do not depend on the balance
+func (p *payoutService) validatePayoutItem(wallet entity.Wallet, item entity.PayoutItem) error {
+	if item.RecipientWalletId == "" {
+		return errors.New(constants.PayoutRecipientRequiredError)
+	}
+	if item.Amount <= 0 {
+		return errors.New(constants.PayoutAmountInvalidError)
+	}
+	if item.RecipientWalletId == wallet.Id {
+		return errors.New(constants.PayoutSameWalletError)
+	}
+
+	recipientWallet, err := p.walletService.GetWalletById(item.RecipientWalletId)
+	if err != nil {
+		return err
+	}
+	return checkWalletStatus(wallet, recipientWallet)
+}
+
+// rejectPayoutBatch stores a batch that failed its checks, so its report can still be downloaded
+func (p *payoutService) rejectPayoutBatch(batch entity.PayoutBatch, reason string) (entity.PayoutBatch, error) {
+	batch.Status = enums.PAYOUT_BATCH_REJECTED
+	batch.ErrorMessage = reason
+	batch.CompletedAt = time.Now().Format(time.RFC3339)
+
+	if err := p.payoutBatchRepository.Create(batch); err != nil {
+		return entity.PayoutBatch{}, err
+	}
+	return batch, nil
+}
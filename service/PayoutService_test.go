@@ -0,0 +1,150 @@
+package service
+
+import (
+	"PaymentAPI/config"
+	"PaymentAPI/constants"
+	req "PaymentAPI/dto/request"
+	"PaymentAPI/entity"
+	"PaymentAPI/enums"
+	"PaymentAPI/repository"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+)
+
+type payoutTest struct {
+	mockPayoutBatchRepository *repository.PayoutBatchRepositoryMock
+	mockWalletService         *WalletServiceMock
+	mockTransactionService    *TransactionServiceMock
+	service                   PayoutService
+}
+
+func setupPayoutTest() payoutTest {
+	config.PayoutMaxRows = 10
+
+	mockPayoutBatchRepository := new(repository.PayoutBatchRepositoryMock)
+	mockWalletService := new(WalletServiceMock)
+	mockTransactionService := new(TransactionServiceMock)
+	mockTransactionService.On("AuthorizeDebit", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	mockWalletService.On("GetWalletById", "wallet-1").Return(entity.Wallet{Id: "wallet-1", Status: enums.WALLET_ACTIVE}, nil)
+	mockWalletService.On("GetWalletById", "wallet-2").Return(entity.Wallet{Id: "wallet-2", Status: enums.WALLET_ACTIVE}, nil)
+	mockWalletService.On("GetWalletById", "wallet-3").Return(entity.Wallet{Id: "wallet-3", Status: enums.WALLET_FROZEN}, nil)
+	mockPayoutBatchRepository.Mock.On("Create", mock.Anything).Return(nil)
+	mockPayoutBatchRepository.Mock.On("Update", mock.Anything).Return(nil)
+
+	return payoutTest{
+		mockPayoutBatchRepository: mockPayoutBatchRepository,
+		mockWalletService:         mockWalletService,
+		mockTransactionService:    mockTransactionService,
+		service:                   NewPayoutService(mockPayoutBatchRepository, mockWalletService, mockTransactionService),
+	}
+}
+
+func TestCreatePayoutBatch(t *testing.T) {
+	t.Run("ShouldPayEveryRow", func(t *testing.T) {
+		test := setupPayoutTest()
+		test.mockWalletService.On("GetAvailableBalance", "wallet-1").Return(float64(10000), nil)
+		test.mockTransactionService.On("CreateNewTransaction", mock.Anything).Return(entity.Transaction{Id: "transaction-1"}, nil)
+
+		batch, err := test.service.CreatePayoutBatch(req.CreatePayoutBatchRequest{
+			WalletId: "wallet-1",
+			Items:    []req.PayoutItemRequest{{RecipientWalletId: "wallet-2", Amount: 3000}, {RecipientWalletId: "wallet-2", Amount: 2000}},
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, enums.PAYOUT_BATCH_COMPLETED, batch.Status)
+		assert.Equal(t, float64(5000), batch.TotalAmount)
+		assert.Equal(t, 2, batch.SucceededCount)
+		assert.Equal(t, "transaction-1", batch.Items[1].TransactionId)
+
+		// The batch is stepped up once for its total, the rows are not stepped up again
+		test.mockTransactionService.AssertCalled(t, "AuthorizeDebit", "wallet-1", float64(5000), req.StepUp{})
+		test.mockTransactionService.AssertCalled(t, "CreateNewTransaction", mock.MatchedBy(func(request req.CreateTransactionRequest) bool {
+			return request.StepUp.Authorized
+		}))
+	})
+
+	t.Run("ShouldNotPayWithoutStepUp", func(t *testing.T) {
+		test := setupPayoutTest()
+		test.mockTransactionService = new(TransactionServiceMock)
+		test.service = NewPayoutService(test.mockPayoutBatchRepository, test.mockWalletService, test.mockTransactionService)
+		test.mockWalletService.On("GetAvailableBalance", "wallet-1").Return(float64(10000), nil)
+		test.mockTransactionService.On("AuthorizeDebit", "wallet-1", float64(5000), req.StepUp{}).
+			Return(errors.New(constants.TransactionStepUpRequiredError))
+
+		_, err := test.service.CreatePayoutBatch(req.CreatePayoutBatchRequest{
+			WalletId: "wallet-1",
+			Items:    []req.PayoutItemRequest{{RecipientWalletId: "wallet-2", Amount: 3000}, {RecipientWalletId: "wallet-2", Amount: 2000}},
+		})
+		assert.Equal(t, constants.TransactionStepUpRequiredError, err.Error())
+		test.mockPayoutBatchRepository.Mock.AssertNotCalled(t, "Create", mock.Anything)
+		test.mockTransactionService.AssertNotCalled(t, "CreateNewTransaction", mock.Anything)
+	})
+
+	t.Run("ShouldRejectBatchWithInvalidRows", func(t *testing.T) {
+		test := setupPayoutTest()
+
+		batch, err := test.service.CreatePayoutBatch(req.CreatePayoutBatchRequest{
+			WalletId: "wallet-1",
+			Items: []req.PayoutItemRequest{
+				{RecipientWalletId: "wallet-2", Amount: 3000},
+				{RecipientWalletId: "wallet-2", Amount: 0},
+				{RecipientWalletId: "wallet-3", Amount: 1000},
+				{RecipientWalletId: "wallet-1", Amount: 1000},
+			},
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, enums.PAYOUT_BATCH_REJECTED, batch.Status)
+		assert.Equal(t, constants.PayoutBatchInvalidRowsError, batch.ErrorMessage)
+		assert.Equal(t, enums.PAYOUT_ITEM_PENDING, batch.Items[0].Status)
+		assert.Equal(t, constants.PayoutAmountInvalidError, batch.Items[1].ErrorMessage)
+		assert.Equal(t, constants.WalletReceiverFrozenError, batch.Items[2].ErrorMessage)
+		assert.Equal(t, constants.PayoutSameWalletError, batch.Items[3].ErrorMessage)
+		test.mockTransactionService.AssertNotCalled(t, "CreateNewTransaction", mock.Anything)
+	})
+
+	t.Run("ShouldRejectBatchAboveAvailableBalance", func(t *testing.T) {
+		test := setupPayoutTest()
+		test.mockWalletService.On("GetAvailableBalance", "wallet-1").Return(float64(4000), nil)
+
+		batch, err := test.service.CreatePayoutBatch(req.CreatePayoutBatchRequest{
+			WalletId: "wallet-1",
+			Items:    []req.PayoutItemRequest{{RecipientWalletId: "wallet-2", Amount: 3000}, {RecipientWalletId: "wallet-2", Amount: 2000}},
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, enums.PAYOUT_BATCH_REJECTED, batch.Status)
+		assert.Equal(t, constants.TransactionInsufficientError, batch.ErrorMessage)
+		test.mockTransactionService.AssertNotCalled(t, "CreateNewTransaction", mock.Anything)
+	})
+
+	t.Run("ShouldRecordFailedTransfers", func(t *testing.T) {
+		test := setupPayoutTest()
+		test.mockWalletService.On("GetAvailableBalance", "wallet-1").Return(float64(10000), nil)
+		test.mockTransactionService.On("CreateNewTransaction", mock.MatchedBy(func(r req.CreateTransactionRequest) bool {
+			return r.Amount == 3000
+		})).Return(entity.Transaction{Id: "transaction-1"}, nil)
+		test.mockTransactionService.On("CreateNewTransaction", mock.Anything).
+			Return(entity.Transaction{}, errors.New(constants.WalletReceiverFrozenError))
+
+		batch, err := test.service.CreatePayoutBatch(req.CreatePayoutBatchRequest{
+			WalletId: "wallet-1",
+			Items:    []req.PayoutItemRequest{{RecipientWalletId: "wallet-2", Amount: 3000}, {RecipientWalletId: "wallet-2", Amount: 2000}},
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, enums.PAYOUT_BATCH_PARTIALLY_COMPLETED, batch.Status)
+		assert.Equal(t, enums.PAYOUT_ITEM_FAILED, batch.Items[1].Status)
+		assert.Equal(t, constants.WalletReceiverFrozenError, batch.Items[1].ErrorMessage)
+	})
+
+	t.Run("ShouldFailOnTooManyRows", func(t *testing.T) {
+		test := setupPayoutTest()
+		config.PayoutMaxRows = 1
+
+		_, err := test.service.CreatePayoutBatch(req.CreatePayoutBatchRequest{
+			WalletId: "wallet-1",
+			Items:    []req.PayoutItemRequest{{RecipientWalletId: "wallet-2", Amount: 3000}, {RecipientWalletId: "wallet-2", Amount: 2000}},
+		})
+		assert.Equal(t, constants.PayoutBatchTooLargeError, err.Error())
+	})
+}
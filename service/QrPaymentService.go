@@ -0,0 +1,154 @@
+package service
+
+import (
+	"PaymentAPI/constants"
+	req "PaymentAPI/dto/request"
+	res "PaymentAPI/dto/response"
+	"PaymentAPI/enums"
+	"PaymentAPI/utils"
+	"errors"
+	"github.com/sirupsen/logrus"
+)
+
+type QrPaymentService interface {
+	GenerateWalletQr(request req.GenerateWalletQrRequest) (res.QrPayloadResponse, error)
+	GenerateCheckoutQr(checkoutSessionId string) (res.QrPayloadResponse, error)
+	PayQr(request req.PayQrRequest, payerWalletId string) (res.QrPaymentResponse, error)
+}
+
+type qrPaymentService struct {
+	walletService      WalletService
+	customerService    CustomerService
+	merchantService    MerchantService
+	checkoutService    CheckoutService
+	transactionService TransactionService
+}
+
+// NewQrPaymentService creates a new instance of QrPaymentService
+func NewQrPaymentService(walletService WalletService, customerService CustomerService, merchantService MerchantService, checkoutService CheckoutService, transactionService TransactionService) QrPaymentService {
+	return &qrPaymentService{walletService, customerService, merchantService, checkoutService, transactionService}
+}
+
+// GenerateWalletQr creates a QR payload paying into a wallet. Without an amount the payer enters the amount.
+func (q *qrPaymentService) GenerateWalletQr(request req.GenerateWalletQrRequest) (res.QrPayloadResponse, error) {
+	if request.Amount < 0 {
+		return res.QrPayloadResponse{}, errors.New(constants.TransactionInvalidAmountError)
+	}
+
+	wallet, err := q.walletService.GetWalletById(request.WalletId)
+	if err != nil {
+		return res.QrPayloadResponse{}, err
+	}
+	customer, err := q.customerService.GetCustomerByIdAuth(wallet.CustomerId)
+	if err != nil {
+		return res.QrPayloadResponse{}, err
+	}
+
+	payload := utils.BuildQrPayload(utils.QrPayment{
+		WalletId:     wallet.Id,
+		Amount:       request.Amount,
+		MerchantName: customer.Username,
+	})
+	return res.QrPayloadResponse{Payload: payload}, nil
+}
+
+// GenerateCheckoutQr creates a QR payload paying an open checkout session, with the session amount fixed
+func (q *qrPaymentService) GenerateCheckoutQr(checkoutSessionId string) (res.QrPayloadResponse, error) {
+	checkoutSession, err := q.checkoutService.GetCheckoutSessionById(checkoutSessionId)
+	if err != nil {
+		return res.QrPayloadResponse{}, err
+	}
+	if checkoutSession.Status != enums.CHECKOUT_OPEN {
+		return res.QrPayloadResponse{}, errors.New(constants.CheckoutSessionNotOpenError)
+	}
+	merchant, err := q.merchantService.GetMerchantById(checkoutSession.MerchantId)
+	if err != nil {
+		return res.QrPayloadResponse{}, err
+	}
+
+	payload := utils.BuildQrPayload(utils.QrPayment{
+		CheckoutSessionId: checkoutSession.Id,
+		Amount:            checkoutSession.Amount,
+		MerchantName:      merchant.Name,
+		Reference:         checkoutSession.Reference,
+	})
+	return res.QrPayloadResponse{Payload: payload}, nil
+}
+
+// PayQr validates a scanned QR payload and pays it from the payer wallet. The amount of the request is only
+// used for a payload without a fixed amount, for a payload with one it must be left out or be the same.
+func (q *qrPaymentService) PayQr(request req.PayQrRequest, payerWalletId string) (res.QrPaymentResponse, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"payerWalletId": payerWalletId,
+	})
+
+	logger.Info("Paying QR payload")
+
+	payment, err := utils.ParseQrPayload(request.Payload)
+	if err != nil {
+		logger.Warn("Invalid QR payload", err)
+		return res.QrPaymentResponse{}, err
+	}
+
+	amount := payment.Amount
+	if amount == 0 {
+		if request.Amount <= 0 {
+			return res.QrPaymentResponse{}, errors.New(constants.QrAmountRequiredError)
+		}
+		amount = request.Amount
+	} else if request.Amount != 0 && request.Amount != amount {
+		return res.QrPaymentResponse{}, errors.New(constants.QrAmountMismatchError)
+	}
+
+	// A checkout payload pays the session, so the merchant sees it paid
+	if payment.CheckoutSessionId != "" {
+		checkoutSession, err := q.checkoutService.GetCheckoutSessionById(payment.CheckoutSessionId)
+		if err != nil {
+			return res.QrPaymentResponse{}, err
+		}
+		if checkoutSession.Amount != amount {
+			return res.QrPaymentResponse{}, errors.New(constants.QrAmountMismatchError)
+		}
+
+		checkoutSession, err = q.checkoutService.PayCheckoutSession(checkoutSession.Id, payerWalletId, request.StepUp)
+		if err != nil {
+			logger.Error("Failed to pay checkout session by QR", err)
+			return res.QrPaymentResponse{}, err
+		}
+		merchant, err := q.merchantService.GetMerchantById(checkoutSession.MerchantId)
+		if err != nil {
+			return res.QrPaymentResponse{}, err
+		}
+
+		logger.Info("Checkout session paid by QR")
+		return res.QrPaymentResponse{
+			TransactionId:     checkoutSession.TransactionId,
+			ToWalletId:        merchant.WalletId,
+			Amount:            checkoutSession.Amount,
+			CheckoutSessionId: checkoutSession.Id,
+		}, nil
+	}
+
+	if payment.WalletId == payerWalletId {
+		return res.QrPaymentResponse{}, errors.New(constants.QrOwnWalletError)
+	}
+
+	transaction, err := q.transactionService.CreateNewTransaction(req.CreateTransactionRequest{
+		FromWalletId: payerWalletId,
+		ToWalletId:   payment.WalletId,
+		Amount:       amount,
+		Message:      request.Message,
+		StepUp:       request.StepUp,
+	})
+	if err != nil {
+		logger.Error("Failed to pay wallet by QR", err)
+		return res.QrPaymentResponse{}, err
+	}
+
+	logger.Info("Wallet paid by QR")
+	return res.QrPaymentResponse{
+		TransactionId: transaction.Id,
+		ToWalletId:    transaction.ToWalletId,
+		Amount:        transaction.Amount,
+	}, nil
+}
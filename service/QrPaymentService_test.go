@@ -0,0 +1,106 @@
+package service
+
+import (
+	"PaymentAPI/constants"
+	req "PaymentAPI/dto/request"
+	"PaymentAPI/entity"
+	"PaymentAPI/enums"
+	"PaymentAPI/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+)
+
+type qrPaymentTest struct {
+	mockWalletService      *WalletServiceMock
+	mockCustomerService    *CustomerServiceMock
+	mockMerchantService    *MerchantServiceMock
+	mockCheckoutService    *CheckoutServiceMock
+	mockTransactionService *TransactionServiceMock
+	service                QrPaymentService
+}
+
+func setupQrPaymentTest() qrPaymentTest {
+	test := qrPaymentTest{
+		mockWalletService:      new(WalletServiceMock),
+		mockCustomerService:    new(CustomerServiceMock),
+		mockMerchantService:    new(MerchantServiceMock),
+		mockCheckoutService:    new(CheckoutServiceMock),
+		mockTransactionService: new(TransactionServiceMock),
+	}
+	test.service = NewQrPaymentService(test.mockWalletService, test.mockCustomerService, test.mockMerchantService, test.mockCheckoutService, test.mockTransactionService)
+	return test
+}
+
+func TestGenerateWalletQr(t *testing.T) {
+	test := setupQrPaymentTest()
+	test.mockWalletService.On("GetWalletById", "wallet-1").Return(entity.Wallet{Id: "wallet-1", CustomerId: "customer-1"}, nil)
+	test.mockCustomerService.Mock.On("GetCustomerByIdAuth", "customer-1").Return(entity.Customer{Username: "johndoe"}, nil)
+
+	response, err := test.service.GenerateWalletQr(req.GenerateWalletQrRequest{WalletId: "wallet-1", Amount: 20000})
+	assert.Nil(t, err)
+
+	payment, err := utils.ParseQrPayload(response.Payload)
+	assert.Nil(t, err)
+	assert.Equal(t, utils.QrPayment{WalletId: "wallet-1", Amount: 20000, MerchantName: "johndoe"}, payment)
+}
+
+func TestPayQr(t *testing.T) {
+	staticPayload := utils.BuildQrPayload(utils.QrPayment{WalletId: "wallet-1", MerchantName: "johndoe"})
+	dynamicPayload := utils.BuildQrPayload(utils.QrPayment{WalletId: "wallet-1", Amount: 20000, MerchantName: "johndoe"})
+
+	tests := []struct {
+		name           string
+		request        req.PayQrRequest
+		payerWalletId  string
+		expectedAmount float64
+		expectedError  string
+	}{
+		{name: "Should Pay Fixed Amount", request: req.PayQrRequest{Payload: dynamicPayload}, payerWalletId: "wallet-2", expectedAmount: 20000},
+		{name: "Should Pay Entered Amount", request: req.PayQrRequest{Payload: staticPayload, Amount: 5000}, payerWalletId: "wallet-2", expectedAmount: 5000},
+		{name: "Should Fail Without Amount", request: req.PayQrRequest{Payload: staticPayload}, payerWalletId: "wallet-2", expectedError: constants.QrAmountRequiredError},
+		{name: "Should Fail On Different Amount", request: req.PayQrRequest{Payload: dynamicPayload, Amount: 100}, payerWalletId: "wallet-2", expectedError: constants.QrAmountMismatchError},
+		{name: "Should Fail On Own Wallet", request: req.PayQrRequest{Payload: dynamicPayload}, payerWalletId: "wallet-1", expectedError: constants.QrOwnWalletError},
+		{name: "Should Fail On Invalid Payload", request: req.PayQrRequest{Payload: "not a qr"}, payerWalletId: "wallet-2", expectedError: constants.QrPayloadInvalidError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			test := setupQrPaymentTest()
+			test.mockTransactionService.On("CreateNewTransaction", mock.Anything).Return(entity.Transaction{Id: "transaction-1", ToWalletId: "wallet-1", Amount: tt.expectedAmount}, nil)
+
+			response, err := test.service.PayQr(tt.request, tt.payerWalletId)
+			if tt.expectedError != "" {
+				assert.Equal(t, tt.expectedError, err.Error())
+				test.mockTransactionService.AssertNotCalled(t, "CreateNewTransaction", mock.Anything)
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, "transaction-1", response.TransactionId)
+			test.mockTransactionService.AssertCalled(t, "CreateNewTransaction", req.CreateTransactionRequest{
+				FromWalletId: tt.payerWalletId,
+				ToWalletId:   "wallet-1",
+				Amount:       tt.expectedAmount,
+			})
+		})
+	}
+
+	t.Run("ShouldPayCheckoutSession", func(t *testing.T) {
+		test := setupQrPaymentTest()
+		checkoutSession := entity.CheckoutSession{Id: "checkout-1", MerchantId: testMerchant.Id, Amount: 25000, Status: enums.CHECKOUT_OPEN}
+		payload := utils.BuildQrPayload(utils.QrPayment{CheckoutSessionId: checkoutSession.Id, Amount: 25000, MerchantName: testMerchant.Name})
+
+		paid := checkoutSession
+		paid.Status, paid.TransactionId = enums.CHECKOUT_PAID, "transaction-1"
+		test.mockCheckoutService.On("GetCheckoutSessionById", checkoutSession.Id).Return(checkoutSession, nil)
+		test.mockCheckoutService.On("PayCheckoutSession", checkoutSession.Id, "wallet-2", req.StepUp{}).Return(paid, nil)
+		test.mockMerchantService.On("GetMerchantById", testMerchant.Id).Return(testMerchant, nil)
+
+		response, err := test.service.PayQr(req.PayQrRequest{Payload: payload}, "wallet-2")
+		assert.Nil(t, err)
+		assert.Equal(t, "transaction-1", response.TransactionId)
+		assert.Equal(t, testMerchant.WalletId, response.ToWalletId)
+		test.mockTransactionService.AssertNotCalled(t, "CreateNewTransaction", mock.Anything)
+	})
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"PaymentAPI/entity"
+	"PaymentAPI/logger"
+	"PaymentAPI/repository"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// reconciliationTolerance absorbs floating point noise when comparing balances
+const reconciliationTolerance = 0.005
+
+type ReconciliationService interface {
+	Reconcile() (entity.ReconciliationReport, error)
+}
+
+type reconciliationService struct {
+	transactionService             TransactionService
+	reconciliationReportRepository repository.ReconciliationReportRepository
+}
+
+// NewReconciliationService creates a new instance of ReconciliationService
+func NewReconciliationService(transactionService TransactionService, reconciliationReportRepository repository.ReconciliationReportRepository) ReconciliationService {
+	return &reconciliationService{transactionService, reconciliationReportRepository}
+}
+
+// Reconcile replays every transaction per wallet, compares the result with the stored balances,
+// checks that money is conserved, and saves the report
+func (r *reconciliationService) Reconcile() (entity.ReconciliationReport, error) {
+	logger.LogInfo("Starting ledger reconciliation", logrus.Fields{
+		"operation": "Reconcile",
+	})
+
+	// Wallets and transactions are read as of the same moment, a transfer in flight would look like a discrepancy
+	wallets, transactions, err := r.transactionService.GetLedgerSnapshot()
+	if err != nil {
+		return entity.ReconciliationReport{}, err
+	}
+
+	report := entity.ReconciliationReport{
+		Id:               uuid.New().String(),
+		GeneratedAt:      time.Now().Format(time.RFC3339),
+		WalletCount:      len(wallets),
+		TransactionCount: len(transactions),
+		Discrepancies:    []entity.WalletDiscrepancy{},
+	}
+
+	// Replay the transactions of every stored wallet
+	replayed := map[string]float64{}
+	for _, wallet := range wallets {
+		replayed[wallet.Id] = 0
+	}
+	for _, transaction := range transactions {
+		if _, ok := replayed[transaction.FromWalletId]; ok {
+			replayed[transaction.FromWalletId] -= transaction.Amount
+		}
+		if _, ok := replayed[transaction.ToWalletId]; ok {
+			replayed[transaction.ToWalletId] += transaction.Amount
+		}
+	}
+
+	// Only deposits and withdrawals move money in or out of the system, and they are the only transactions with the
+	// external ID of the bank or biller. Counting them apart from the replay catches a transfer to or from a wallet
+	// missing from storage, which no per-wallet comparison can see.
+	for _, transaction := range transactions {
+		if transaction.ExternalId == "" {
+			continue
+		}
+		if _, ok := replayed[transaction.ToWalletId]; ok {
+			report.TotalExternalInflow += transaction.Amount
+		} else {
+			report.TotalExternalOutflow += transaction.Amount
+		}
+	}
+
+	// Compare every stored balance with its replayed balance
+	for _, wallet := range wallets {
+		report.TotalStoredBalance += wallet.Balance
+
+		difference := wallet.Balance - replayed[wallet.Id]
+		if math.Abs(difference) > reconciliationTolerance {
+			report.Discrepancies = append(report.Discrepancies, entity.WalletDiscrepancy{
+				WalletId:        wallet.Id,
+				StoredBalance:   wallet.Balance,
+				ReplayedBalance: replayed[wallet.Id],
+				Difference:      difference,
+			})
+		}
+	}
+
+	// The money held by all wallets must equal the money that entered minus the money that left
+	report.ConservationDifference = report.TotalStoredBalance - (report.TotalExternalInflow - report.TotalExternalOutflow)
+	report.Balanced = len(report.Discrepancies) == 0 && math.Abs(report.ConservationDifference) <= reconciliationTolerance
+
+	if err := r.reconciliationReportRepository.Create(report); err != nil {
+		return entity.ReconciliationReport{}, err
+	}
+
+	if !report.Balanced {
+		for _, discrepancy := range report.Discrepancies {
+			logger.LogError("Wallet balance does not match transaction history", logrus.Fields{
+				"operation":       "Reconcile",
+				"alert":           "LEDGER_DISCREPANCY",
+				"reportId":        report.Id,
+				"walletId":        discrepancy.WalletId,
+				"storedBalance":   discrepancy.StoredBalance,
+				"replayedBalance": discrepancy.ReplayedBalance,
+				"difference":      discrepancy.Difference,
+			})
+		}
+		logger.LogError("Ledger reconciliation found discrepancies", logrus.Fields{
+			"operation":              "Reconcile",
+			"alert":                  "LEDGER_DISCREPANCY",
+			"reportId":               report.Id,
+			"discrepancies":          len(report.Discrepancies),
+			"conservationDifference": report.ConservationDifference,
+		})
+		return report, nil
+	}
+
+	logger.LogInfo("Ledger reconciliation completed without discrepancies", logrus.Fields{
+		"operation": "Reconcile",
+		"reportId":  report.Id,
+		"wallets":   report.WalletCount,
+	})
+	return report, nil
+}
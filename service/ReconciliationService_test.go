@@ -0,0 +1,78 @@
+package service
+
+import (
+	"PaymentAPI/entity"
+	"PaymentAPI/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+)
+
+func TestReconcile(t *testing.T) {
+	transactions := []entity.Transaction{
+		{Id: "transaction-1", FromWalletId: "EXTERNAL", ToWalletId: "wallet-1", Amount: 10000, ExternalId: "bank-ref-1"},
+		{Id: "transaction-2", FromWalletId: "wallet-1", ToWalletId: "wallet-2", Amount: 4000},
+		{Id: "transaction-3", FromWalletId: "wallet-2", ToWalletId: "EXTERNAL", Amount: 1000, ExternalId: "biller-ref-1"},
+	}
+
+	tests := []struct {
+		name                  string
+		wallets               []entity.Wallet
+		expectedBalanced      bool
+		expectedDiscrepancies []string
+		expectedConservation  float64
+	}{
+		{
+			name: "Should Report Balanced Ledger",
+			wallets: []entity.Wallet{
+				{Id: "wallet-1", CustomerId: "customer-1", Balance: 6000},
+				{Id: "wallet-2", CustomerId: "customer-2", Balance: 3000},
+			},
+			expectedBalanced:      true,
+			expectedDiscrepancies: []string{},
+		},
+		{
+			name: "Should Report Edited Balance",
+			wallets: []entity.Wallet{
+				{Id: "wallet-1", CustomerId: "customer-1", Balance: 6000},
+				{Id: "wallet-2", CustomerId: "customer-2", Balance: 8000},
+			},
+			expectedBalanced:      false,
+			expectedDiscrepancies: []string{"wallet-2"},
+			expectedConservation:  5000,
+		},
+		{
+			// The transfer to wallet-2 left wallet-1 as recorded, only conservation shows the money is gone
+			name: "Should Report Transfer To Missing Wallet",
+			wallets: []entity.Wallet{
+				{Id: "wallet-1", CustomerId: "customer-1", Balance: 6000},
+			},
+			expectedBalanced:      false,
+			expectedDiscrepancies: []string{},
+			expectedConservation:  -3000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockTransactionService := new(TransactionServiceMock)
+			mockReportRepository := new(repository.ReconciliationReportRepositoryMock)
+			reconciliationService := NewReconciliationService(mockTransactionService, mockReportRepository)
+
+			mockTransactionService.Mock.On("GetLedgerSnapshot").Return(tt.wallets, transactions, nil)
+			mockReportRepository.Mock.On("Create", mock.Anything).Return(nil)
+
+			report, err := reconciliationService.Reconcile()
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expectedBalanced, report.Balanced)
+			assert.Equal(t, tt.expectedConservation, report.ConservationDifference)
+
+			walletIds := []string{}
+			for _, discrepancy := range report.Discrepancies {
+				walletIds = append(walletIds, discrepancy.WalletId)
+			}
+			assert.Equal(t, tt.expectedDiscrepancies, walletIds)
+			mockReportRepository.Mock.AssertExpectations(t)
+		})
+	}
+}
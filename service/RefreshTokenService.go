@@ -3,6 +3,7 @@ package service
 import (
 	"PaymentAPI/constants"
 	"PaymentAPI/entity"
+	"PaymentAPI/enums"
 	"PaymentAPI/repository"
 	"errors"
 	"github.com/sirupsen/logrus" // Import logrus for structured logging
@@ -10,9 +11,24 @@ import (
 )
 
 type RefreshTokenService interface {
-	GenerateRefreshToken(customerId string) (entity.RefreshToken, error)
+	GenerateRefreshToken(customerId string, sessionId string) (entity.RefreshToken, error)
+	GetRefreshToken(refreshToken string) (entity.RefreshToken, error)
 	RotateRefreshToken(refreshToken string) (entity.RefreshToken, error)
 	DeleteRefreshToken(refreshToken string) error
+	DeleteRefreshTokensBySessionId(sessionId string) error
+	DeleteRefreshTokensByCustomerId(customerId string) error
+	DeleteExpiredRefreshTokens() error
+}
+
+// RefreshTokenReusedError refuses a refresh token that was already rotated. Its whole family has been revoked, as
+// either the customer or whoever replayed it holds a stolen token.
+type RefreshTokenReusedError struct {
+	CustomerId string
+	SessionId  string
+}
+
+func (r *RefreshTokenReusedError) Error() string {
+	return constants.RefreshTokenReusedError
 }
 
 type refreshTokenService struct {
@@ -24,54 +40,75 @@ func NewRefreshTokenService(refreshTokenRepository repository.RefreshTokenReposi
 	return &refreshTokenService{refreshTokenRepository: refreshTokenRepository}
 }
 
-// GenerateRefreshToken generates a new refresh token for a customer
-func (r *refreshTokenService) GenerateRefreshToken(customerId string) (entity.RefreshToken, error) {
+// GenerateRefreshToken generates a new refresh token for a session of a customer. Only its hash is stored, the token
+// itself is returned this once.
+func (r *refreshTokenService) GenerateRefreshToken(customerId string, sessionId string) (entity.RefreshToken, error) {
 	logger := logrus.WithFields(logrus.Fields{
 		"customerId": customerId,
+		"sessionId":  sessionId,
 	})
 	logger.Info("Generating refresh token")
 
-	// Retrieve all refresh tokens to check if there's an existing one for the customer
-	refreshTokens, err := r.refreshTokenRepository.GetAllRefreshToken()
+	token, err := generateSecretToken()
 	if err != nil {
-		logger.Error("Failed to retrieve refresh tokens", err)
+		logger.Error("Failed to generate refresh token", err)
 		return entity.RefreshToken{}, err
 	}
 
-	// Remove the existing refresh token for the customer if present
-	for _, token := range refreshTokens {
-		if token.CustomerId == customerId {
-			logger.Info("Deleting existing refresh token for customer")
-			r.refreshTokenRepository.DeleteRefreshToken(token.RefreshToken)
-			break
-		}
-	}
-
 	// Create a new refresh token
-	token, err := r.refreshTokenRepository.CreateRefreshToken(customerId)
-	if err != nil {
+	refreshToken := entity.RefreshToken{
+		RefreshToken: token,
+		TokenHash:    hashSecretToken(token),
+		CustomerId:   customerId,
+		SessionId:    sessionId,
+		ExpiresAt:    time.Now().Add(24 * time.Hour).Format(time.RFC3339),
+	}
+	if err := r.refreshTokenRepository.CreateRefreshToken(refreshToken); err != nil {
 		logger.Error("Failed to create new refresh token", err)
 		return entity.RefreshToken{}, err
 	}
 
 	logger.Info("Successfully generated new refresh token")
-	return token, nil
+	return refreshToken, nil
 }
 
-// RotateRefreshToken rotates the refresh token by verifying and generating a new one
+// GetRefreshToken retrieves a refresh token by its value
+func (r *refreshTokenService) GetRefreshToken(refreshToken string) (entity.RefreshToken, error) {
+	return r.refreshTokenRepository.GetRefreshToken(hashSecretToken(refreshToken))
+}
+
+// RotateRefreshToken rotates the refresh token by verifying and generating a new one in the same family. The old
+// token is kept as rotated until it expires, and a replay of it revokes the whole family.
 func (r *refreshTokenService) RotateRefreshToken(refreshToken string) (entity.RefreshToken, error) {
-	logger := logrus.WithFields(logrus.Fields{
-		"refreshToken": refreshToken,
-	})
+	logger := logrus.WithFields(logrus.Fields{})
 	logger.Info("Rotating refresh token")
 
 	// Retrieve the existing refresh token
-	token, err := r.refreshTokenRepository.GetRefreshToken(refreshToken)
+	token, err := r.GetRefreshToken(refreshToken)
 	if err != nil {
 		logger.Error("Failed to retrieve refresh token", err)
 		return entity.RefreshToken{}, err
 	}
 
+	logger = logger.WithFields(logrus.Fields{
+		"customerId": token.CustomerId,
+		"sessionId":  token.SessionId,
+	})
+
+	// A rotated token comes back only when it was copied, revoke every token of the family
+	if token.RotatedAt != "" {
+		logSecurityEvent(enums.SECURITY_REFRESH_TOKEN_REUSED, logrus.Fields{
+			"customerId": token.CustomerId,
+			"sessionId":  token.SessionId,
+			"rotatedAt":  token.RotatedAt,
+		})
+		if err := r.DeleteRefreshTokensBySessionId(token.SessionId); err != nil {
+			logger.Error("Failed to revoke refresh token family", err)
+			return entity.RefreshToken{}, err
+		}
+		return entity.RefreshToken{}, &RefreshTokenReusedError{CustomerId: token.CustomerId, SessionId: token.SessionId}
+	}
+
 	// Parse the expiration time from the refresh token
 	parsedTime, err := time.Parse(time.RFC3339, token.ExpiresAt)
 	if err != nil {
@@ -80,16 +117,21 @@ func (r *refreshTokenService) RotateRefreshToken(refreshToken string) (entity.Re
 	}
 
 	// If the refresh token is expired, return an error
-	if time.Now().After(parsedTime) {
+	now := time.Now()
+	if now.After(parsedTime) {
 		logger.Error("Refresh token has expired")
 		return entity.RefreshToken{}, errors.New(constants.RefreshTokenExpiredError)
 	}
 
-	// Delete the expired refresh token
-	r.refreshTokenRepository.DeleteRefreshToken(token.RefreshToken)
+	// Mark the token as rotated instead of deleting it, so a replay is recognized
+	token.RotatedAt = now.Format(time.RFC3339)
+	if err := r.refreshTokenRepository.UpdateRefreshToken(token); err != nil {
+		logger.Error("Failed to mark refresh token as rotated", err)
+		return entity.RefreshToken{}, err
+	}
 
-	// Generate a new refresh token for the customer
-	newRefreshToken, err := r.GenerateRefreshToken(token.CustomerId)
+	// Generate a new refresh token for the same session
+	newRefreshToken, err := r.GenerateRefreshToken(token.CustomerId, token.SessionId)
 	if err != nil {
 		logger.Error("Failed to generate new refresh token", err)
 		return entity.RefreshToken{}, err
@@ -101,13 +143,11 @@ func (r *refreshTokenService) RotateRefreshToken(refreshToken string) (entity.Re
 
 // DeleteRefreshToken deletes a refresh token
 func (r *refreshTokenService) DeleteRefreshToken(refreshToken string) error {
-	logger := logrus.WithFields(logrus.Fields{
-		"refreshToken": refreshToken,
-	})
+	logger := logrus.WithFields(logrus.Fields{})
 	logger.Info("Deleting refresh token")
 
 	// Delete the refresh token from the repository
-	err := r.refreshTokenRepository.DeleteRefreshToken(refreshToken)
+	err := r.refreshTokenRepository.DeleteRefreshToken(hashSecretToken(refreshToken))
 	if err != nil {
 		logger.Error("Failed to delete refresh token", err)
 		return err
@@ -116,3 +156,68 @@ func (r *refreshTokenService) DeleteRefreshToken(refreshToken string) error {
 	logger.Info("Successfully deleted refresh token")
 	return nil
 }
+
+// DeleteRefreshTokensBySessionId deletes every refresh token of a session, the rotated ones included
+func (r *refreshTokenService) DeleteRefreshTokensBySessionId(sessionId string) error {
+	// A token without a session is in no family, never match all of them at once
+	if sessionId == "" {
+		return nil
+	}
+
+	logger := logrus.WithFields(logrus.Fields{
+		"sessionId": sessionId,
+	})
+
+	return r.deleteRefreshTokens(logger, func(token entity.RefreshToken) bool {
+		return token.SessionId == sessionId
+	})
+}
+
+// DeleteRefreshTokensByCustomerId deletes every refresh token of a customer, ending all of their sessions
+func (r *refreshTokenService) DeleteRefreshTokensByCustomerId(customerId string) error {
+	logger := logrus.WithFields(logrus.Fields{
+		"customerId": customerId,
+	})
+	logger.Info("Deleting all refresh tokens of customer")
+
+	if err := r.deleteRefreshTokens(logger, func(token entity.RefreshToken) bool {
+		return token.CustomerId == customerId
+	}); err != nil {
+		return err
+	}
+
+	logger.Info("Successfully deleted all refresh tokens of customer")
+	return nil
+}
+
+// DeleteExpiredRefreshTokens deletes the refresh tokens past their expiration, rotated ones are no longer needed to
+// recognize a replay by then
+func (r *refreshTokenService) DeleteExpiredRefreshTokens() error {
+	logger := logrus.WithFields(logrus.Fields{})
+
+	now := time.Now()
+	return r.deleteRefreshTokens(logger, func(token entity.RefreshToken) bool {
+		expiresAt, err := time.Parse(time.RFC3339, token.ExpiresAt)
+		return err != nil || now.After(expiresAt)
+	})
+}
+
+// deleteRefreshTokens deletes every refresh token matching the filter
+func (r *refreshTokenService) deleteRefreshTokens(logger *logrus.Entry, matches func(token entity.RefreshToken) bool) error {
+	refreshTokens, err := r.refreshTokenRepository.GetAllRefreshToken()
+	if err != nil {
+		logger.Error("Failed to retrieve refresh tokens", err)
+		return err
+	}
+
+	for _, token := range refreshTokens {
+		if !matches(token) {
+			continue
+		}
+		if err := r.refreshTokenRepository.DeleteRefreshToken(token.TokenHash); err != nil {
+			logger.Error("Failed to delete refresh token", err)
+			return err
+		}
+	}
+	return nil
+}
@@ -10,8 +10,8 @@ type RefreshTokenServiceMock struct {
 	mock.Mock
 }
 
-func (m *RefreshTokenServiceMock) GenerateRefreshToken(customerId string) (entity.RefreshToken, error) {
-	args := m.Called(customerId)
+func (m *RefreshTokenServiceMock) GenerateRefreshToken(customerId string, sessionId string) (entity.RefreshToken, error) {
+	args := m.Called(customerId, sessionId)
 
 	refreshToken, ok := args.Get(0).(entity.RefreshToken)
 	if !ok {
@@ -20,6 +20,11 @@ func (m *RefreshTokenServiceMock) GenerateRefreshToken(customerId string) (entit
 	return refreshToken, args.Error(1)
 }
 
+func (m *RefreshTokenServiceMock) GetRefreshToken(refreshToken string) (entity.RefreshToken, error) {
+	args := m.Called(refreshToken)
+	return args.Get(0).(entity.RefreshToken), args.Error(1)
+}
+
 func (m *RefreshTokenServiceMock) RotateRefreshToken(refreshToken string) (entity.RefreshToken, error) {
 	args := m.Called(refreshToken)
 
@@ -34,3 +39,18 @@ func (m *RefreshTokenServiceMock) DeleteRefreshToken(refreshToken string) error
 	args := m.Called(refreshToken)
 	return args.Error(0)
 }
+
+func (m *RefreshTokenServiceMock) DeleteRefreshTokensBySessionId(sessionId string) error {
+	args := m.Called(sessionId)
+	return args.Error(0)
+}
+
+func (m *RefreshTokenServiceMock) DeleteRefreshTokensByCustomerId(customerId string) error {
+	args := m.Called(customerId)
+	return args.Error(0)
+}
+
+func (m *RefreshTokenServiceMock) DeleteExpiredRefreshTokens() error {
+	args := m.Called()
+	return args.Error(0)
+}
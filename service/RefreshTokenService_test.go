@@ -6,6 +6,7 @@ import (
 	"PaymentAPI/repository"
 	"errors"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"testing"
 	"time"
 )
@@ -25,75 +26,29 @@ func setupRefreshTokenTest() refreshTokenTest {
 }
 
 func TestGenerateRefreshToken(t *testing.T) {
-	tests := []struct {
-		name           string
-		customerId     string
-		existingTokens []entity.RefreshToken
-		newToken       entity.RefreshToken
-		expectedError  error
-	}{
-		{
-			name:       "Should Generate New Token When User Has Existing Token",
-			customerId: "user-1",
-			existingTokens: []entity.RefreshToken{
-				{
-					RefreshToken: "refresh-token-1",
-					CustomerId:   "user-1",
-					ExpiresAt:    time.Now().Add(time.Hour).Format(time.RFC3339),
-				},
-			},
-			newToken: entity.RefreshToken{
-				RefreshToken: "new-refresh-token",
-				CustomerId:   "user-1",
-				ExpiresAt:    time.Now().Add(time.Duration(24) * time.Hour).Format(time.RFC3339),
-			},
-			expectedError: nil,
-		},
-		{
-			name:           "Should Generate New Token When User Has No Existing Token",
-			customerId:     "user-2",
-			existingTokens: []entity.RefreshToken{},
-			newToken: entity.RefreshToken{
-				RefreshToken: "new-refresh-token",
-				CustomerId:   "user-2",
-				ExpiresAt:    time.Now().Add(time.Duration(24) * time.Hour).Format(time.RFC3339),
-			},
-			expectedError: nil,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			// Setup
-			test := setupRefreshTokenTest()
+	// Setup
+	test := setupRefreshTokenTest()
 
-			// Mock GetAllRefreshToken
-			test.mockRepo.Mock.On("GetAllRefreshToken").
-				Return(tt.existingTokens, nil)
+	// Mock CreateRefreshToken
+	test.mockRepo.Mock.On("CreateRefreshToken", mock.Anything).
+		Return(nil)
 
-			// Mock DeleteRefreshToken if there are existing tokens
-			if len(tt.existingTokens) > 0 {
-				test.mockRepo.Mock.On("DeleteRefreshToken", tt.existingTokens[0].RefreshToken).
-					Return(nil)
-			}
+	// Execute
+	token, err := test.service.GenerateRefreshToken("user-1", "session-1")
 
-			// Mock CreateRefreshToken
-			test.mockRepo.Mock.On("CreateRefreshToken", tt.customerId).
-				Return(tt.newToken, nil)
+	// Assert
+	assert.Nil(t, err)
+	assert.NotEmpty(t, token.RefreshToken)
+	assert.Equal(t, "user-1", token.CustomerId)
+	assert.Equal(t, "session-1", token.SessionId)
 
-			// Execute
-			token, err := test.service.GenerateRefreshToken(tt.customerId)
+	// Only the hash of the token is stored
+	stored := test.mockRepo.Mock.Calls[0].Arguments.Get(0).(entity.RefreshToken)
+	assert.Equal(t, hashSecretToken(token.RefreshToken), stored.TokenHash)
+	assert.NotEqual(t, token.RefreshToken, stored.TokenHash)
 
-			// Assert
-			if tt.expectedError != nil {
-				assert.Equal(t, tt.expectedError, err)
-			} else {
-				assert.Nil(t, err)
-				assert.Equal(t, tt.newToken, token)
-			}
-			test.mockRepo.Mock.AssertExpectations(t)
-		})
-	}
+	// Other tokens, of this session or another, are left alone
+	test.mockRepo.Mock.AssertNotCalled(t, "DeleteRefreshToken", mock.Anything)
 }
 
 func TestRotateRefreshToken(t *testing.T) {
@@ -101,21 +56,16 @@ func TestRotateRefreshToken(t *testing.T) {
 		name          string
 		refreshToken  string
 		storedToken   entity.RefreshToken
-		newToken      entity.RefreshToken
 		expectedError error
 	}{
 		{
 			name:         "Should Rotate Valid Token",
 			refreshToken: "refresh-token-1",
 			storedToken: entity.RefreshToken{
-				RefreshToken: "refresh-token-1",
-				CustomerId:   "user-1",
-				ExpiresAt:    time.Now().Add(24 * time.Hour).Format(time.RFC3339),
-			},
-			newToken: entity.RefreshToken{
-				RefreshToken: "new-refresh-token",
-				CustomerId:   "user-1",
-				ExpiresAt:    time.Now().Add(time.Duration(24) * time.Hour).Format(time.RFC3339),
+				TokenHash:  hashSecretToken("refresh-token-1"),
+				CustomerId: "user-1",
+				SessionId:  "session-1",
+				ExpiresAt:  time.Now().Add(24 * time.Hour).Format(time.RFC3339),
 			},
 			expectedError: nil,
 		},
@@ -123,18 +73,17 @@ func TestRotateRefreshToken(t *testing.T) {
 			name:         "Should Fail For Expired Token",
 			refreshToken: "expired-token",
 			storedToken: entity.RefreshToken{
-				RefreshToken: "expired-token",
-				CustomerId:   "user-1",
-				ExpiresAt:    time.Now().Add(-24 * time.Hour).Format(time.RFC3339),
+				TokenHash:  hashSecretToken("expired-token"),
+				CustomerId: "user-1",
+				SessionId:  "session-1",
+				ExpiresAt:  time.Now().Add(-24 * time.Hour).Format(time.RFC3339),
 			},
-			newToken:      entity.RefreshToken{},
 			expectedError: errors.New(constants.RefreshTokenExpiredError),
 		},
 		{
 			name:          "Should Fail For Non-existent Token",
 			refreshToken:  "non-existent-token",
 			storedToken:   entity.RefreshToken{},
-			newToken:      entity.RefreshToken{},
 			expectedError: errors.New(constants.RefreshTokenNotFoundError),
 		},
 	}
@@ -145,21 +94,22 @@ func TestRotateRefreshToken(t *testing.T) {
 			test := setupRefreshTokenTest()
 
 			// Mock GetRefreshToken
-			test.mockRepo.Mock.On("GetRefreshToken", tt.refreshToken).
-				Return(tt.storedToken, tt.expectedError)
+			var lookupError error
+			if tt.storedToken.TokenHash == "" {
+				lookupError = tt.expectedError
+			}
+			test.mockRepo.Mock.On("GetRefreshToken", hashSecretToken(tt.refreshToken)).
+				Return(tt.storedToken, lookupError)
 
 			if tt.expectedError == nil {
-				// Mock DeleteRefreshToken
-				test.mockRepo.Mock.On("DeleteRefreshToken", tt.refreshToken).
-					Return(nil)
-
-				// Mock GetAllRefreshToken (called by GenerateRefreshToken)
-				test.mockRepo.Mock.On("GetAllRefreshToken").
-					Return([]entity.RefreshToken{}, nil)
+				// Mock UpdateRefreshToken, the old token is kept as rotated
+				test.mockRepo.Mock.On("UpdateRefreshToken", mock.MatchedBy(func(token entity.RefreshToken) bool {
+					return token.TokenHash == tt.storedToken.TokenHash && token.RotatedAt != ""
+				})).Return(nil)
 
 				// Mock CreateRefreshToken (called by GenerateRefreshToken)
-				test.mockRepo.Mock.On("CreateRefreshToken", tt.storedToken.CustomerId).
-					Return(tt.newToken, nil)
+				test.mockRepo.Mock.On("CreateRefreshToken", mock.Anything).
+					Return(nil)
 			}
 
 			// Execute
@@ -171,10 +121,67 @@ func TestRotateRefreshToken(t *testing.T) {
 				assert.Empty(t, token)
 			} else {
 				assert.Nil(t, err)
-				assert.Equal(t, tt.newToken, token)
-				assert.NotEqual(t, tt.refreshToken, token)
+				assert.NotEqual(t, tt.refreshToken, token.RefreshToken)
+				assert.Equal(t, tt.storedToken.CustomerId, token.CustomerId)
+				assert.Equal(t, tt.storedToken.SessionId, token.SessionId)
 			}
 			test.mockRepo.Mock.AssertExpectations(t)
+			test.mockRepo.Mock.AssertNotCalled(t, "DeleteRefreshToken", mock.Anything)
 		})
 	}
 }
+
+func TestRotateRefreshTokenReuse(t *testing.T) {
+	// Setup
+	test := setupRefreshTokenTest()
+
+	rotatedToken := entity.RefreshToken{
+		TokenHash:  hashSecretToken("refresh-token-1"),
+		CustomerId: "user-1",
+		SessionId:  "session-1",
+		ExpiresAt:  time.Now().Add(24 * time.Hour).Format(time.RFC3339),
+		RotatedAt:  time.Now().Add(-time.Minute).Format(time.RFC3339),
+	}
+	test.mockRepo.Mock.On("GetRefreshToken", rotatedToken.TokenHash).
+		Return(rotatedToken, nil)
+	test.mockRepo.Mock.On("GetAllRefreshToken").Return([]entity.RefreshToken{
+		rotatedToken,
+		{TokenHash: "hash-2", CustomerId: "user-1", SessionId: "session-1"},
+		{TokenHash: "hash-3", CustomerId: "user-1", SessionId: "session-2"},
+	}, nil)
+	test.mockRepo.Mock.On("DeleteRefreshToken", mock.Anything).Return(nil)
+
+	// Execute
+	token, err := test.service.RotateRefreshToken("refresh-token-1")
+
+	// Assert
+	var reusedErr *RefreshTokenReusedError
+	assert.ErrorAs(t, err, &reusedErr)
+	assert.Equal(t, "session-1", reusedErr.SessionId)
+	assert.Empty(t, token)
+
+	// Every token of the family is revoked, other sessions keep theirs
+	test.mockRepo.Mock.AssertCalled(t, "DeleteRefreshToken", rotatedToken.TokenHash)
+	test.mockRepo.Mock.AssertCalled(t, "DeleteRefreshToken", "hash-2")
+	test.mockRepo.Mock.AssertNotCalled(t, "DeleteRefreshToken", "hash-3")
+	test.mockRepo.Mock.AssertNotCalled(t, "CreateRefreshToken", mock.Anything)
+}
+
+func TestDeleteExpiredRefreshTokens(t *testing.T) {
+	// Setup
+	test := setupRefreshTokenTest()
+
+	test.mockRepo.Mock.On("GetAllRefreshToken").Return([]entity.RefreshToken{
+		{TokenHash: "hash-1", ExpiresAt: time.Now().Add(-time.Minute).Format(time.RFC3339), RotatedAt: time.Now().Add(-time.Hour).Format(time.RFC3339)},
+		{TokenHash: "hash-2", ExpiresAt: time.Now().Add(time.Hour).Format(time.RFC3339)},
+	}, nil)
+	test.mockRepo.Mock.On("DeleteRefreshToken", "hash-1").Return(nil)
+
+	// Execute
+	err := test.service.DeleteExpiredRefreshTokens()
+
+	// Assert
+	assert.Nil(t, err)
+	test.mockRepo.Mock.AssertExpectations(t)
+	test.mockRepo.Mock.AssertNotCalled(t, "DeleteRefreshToken", "hash-2")
+}
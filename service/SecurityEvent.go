@@ -0,0 +1,16 @@
+package service
+
+import (
+	"PaymentAPI/enums"
+	"PaymentAPI/logger"
+	"github.com/sirupsen/logrus"
+)
+
+// logSecurityEvent writes a security relevant event to the JSON log file, tagged so it can be filtered and alerted on
+func logSecurityEvent(eventType enums.SecurityEventType, fields logrus.Fields) {
+	securityFields := logrus.Fields{"securityEvent": eventType}
+	for key, value := range fields {
+		securityFields[key] = value
+	}
+	logger.LogWarning("Security event", securityFields)
+}
@@ -0,0 +1,324 @@
+package service
+
+import (
+	"PaymentAPI/constants"
+	req "PaymentAPI/dto/request"
+	res "PaymentAPI/dto/response"
+	"PaymentAPI/entity"
+	"PaymentAPI/enums"
+	"PaymentAPI/logger"
+	"PaymentAPI/repository"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// sessionDeviceNameMaxLength and sessionUserAgentMaxLength cap what a client can make us store about itself
+	sessionDeviceNameMaxLength = 64
+	sessionUserAgentMaxLength  = 256
+)
+
+type SessionService interface {
+	StartSession(customerId string, client req.ClientInfo) (entity.Session, entity.RefreshToken, error)
+	RefreshSession(refreshToken string, client req.ClientInfo) (entity.Session, entity.RefreshToken, error)
+	EndSession(refreshToken string) error
+	GetSessions(customerId string, currentSessionId string) ([]res.SessionResponse, error)
+	RevokeSession(customerId string, sessionId string) error
+	RevokeOtherSessions(customerId string, currentSessionId string) (int, error)
+	RevokeAllSessions(customerId string) error
+	IsSessionActive(customerId string, sessionId string) (bool, error)
+}
+
+type sessionService struct {
+	sessionRepository   repository.SessionRepository
+	refreshTokenService RefreshTokenService
+	lock                sync.Mutex
+}
+
+// NewSessionService creates a new instance of SessionService
+func NewSessionService(sessionRepository repository.SessionRepository, refreshTokenService RefreshTokenService) SessionService {
+	return &sessionService{
+		sessionRepository:   sessionRepository,
+		refreshTokenService: refreshTokenService,
+	}
+}
+
+// StartSession starts a new session for a login from the client and generates its first refresh token. Sessions on
+// other devices are left alone.
+func (s *sessionService) StartSession(customerId string, client req.ClientInfo) (entity.Session, entity.RefreshToken, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.startSession(customerId, client)
+}
+
+// RefreshSession rotates the refresh token of a session and records the client as its latest use. Replaying a
+// refresh token that was already rotated revokes the session.
+func (s *sessionService) RefreshSession(refreshToken string, client req.ClientInfo) (entity.Session, entity.RefreshToken, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	newRefreshToken, err := s.refreshTokenService.RotateRefreshToken(refreshToken)
+	if err != nil {
+		// The refresh tokens of the session are revoked already, end the session with them
+		var reusedErr *RefreshTokenReusedError
+		if errors.As(err, &reusedErr) {
+			if err := s.sessionRepository.Delete(reusedErr.SessionId); err != nil && err.Error() != constants.SessionNotFoundError {
+				return entity.Session{}, entity.RefreshToken{}, err
+			}
+			logger.LogWarning("Session revoked after refresh token reuse", logrus.Fields{
+				"customerId": reusedErr.CustomerId,
+				"sessionId":  reusedErr.SessionId,
+			})
+		}
+		return entity.Session{}, entity.RefreshToken{}, err
+	}
+
+	session, err := s.sessionRepository.GetById(newRefreshToken.SessionId)
+	if err != nil {
+		return entity.Session{}, entity.RefreshToken{}, err
+	}
+
+	session.Ip = client.Ip
+	session.UserAgent = truncate(client.UserAgent, sessionUserAgentMaxLength)
+	session.LastUsedAt = time.Now().Format(time.RFC3339)
+	session.ExpiresAt = newRefreshToken.ExpiresAt
+	if err := s.sessionRepository.Save(session); err != nil {
+		return entity.Session{}, entity.RefreshToken{}, err
+	}
+	return session, newRefreshToken, nil
+}
+
+// EndSession ends the session a refresh token belongs to, as on logout
+func (s *sessionService) EndSession(refreshToken string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	token, err := s.refreshTokenService.GetRefreshToken(refreshToken)
+	if err != nil {
+		return err
+	}
+
+	if err := s.deleteSession(token.SessionId); err != nil {
+		return err
+	}
+	logger.LogInfo("Session ended", logrus.Fields{
+		"customerId": token.CustomerId,
+		"sessionId":  token.SessionId,
+	})
+	return nil
+}
+
+// GetSessions returns the active sessions of the customer, most recently used first, marking the one the request
+// was made from. Expired sessions are cleaned up on the way.
+func (s *sessionService) GetSessions(customerId string, currentSessionId string) ([]res.SessionResponse, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	sessions, err := s.getActiveSessions(customerId)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return sessions[i].LastUsedAt > sessions[j].LastUsedAt
+	})
+
+	responses := make([]res.SessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		responses = append(responses, res.SessionResponse{
+			Id:         session.Id,
+			DeviceName: session.DeviceName,
+			Ip:         session.Ip,
+			UserAgent:  session.UserAgent,
+			CreatedAt:  session.CreatedAt,
+			LastUsedAt: session.LastUsedAt,
+			ExpiresAt:  session.ExpiresAt,
+			Current:    session.Id == currentSessionId,
+		})
+	}
+	return responses, nil
+}
+
+// RevokeSession ends one session of the customer, its refresh token stops working. A session of another customer is
+// reported as not found.
+func (s *sessionService) RevokeSession(customerId string, sessionId string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	session, err := s.sessionRepository.GetById(sessionId)
+	if err != nil {
+		return err
+	}
+	if session.CustomerId != customerId {
+		return errors.New(constants.SessionNotFoundError)
+	}
+
+	if err := s.deleteSession(sessionId); err != nil {
+		return err
+	}
+
+	logSecurityEvent(enums.SECURITY_SESSION_REVOKED, logrus.Fields{
+		"customerId": customerId,
+		"sessionId":  sessionId,
+	})
+	return nil
+}
+
+// RevokeOtherSessions ends every session of the customer except the current one, and returns how many were ended
+func (s *sessionService) RevokeOtherSessions(customerId string, currentSessionId string) (int, error) {
+	if currentSessionId == "" {
+		return 0, errors.New(constants.SessionUnknownError)
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	sessions, err := s.sessionRepository.GetByCustomerId(customerId)
+	if err != nil {
+		return 0, err
+	}
+
+	revoked := 0
+	for _, session := range sessions {
+		if session.Id == currentSessionId {
+			continue
+		}
+		if err := s.deleteSession(session.Id); err != nil {
+			return revoked, err
+		}
+		revoked++
+	}
+
+	logSecurityEvent(enums.SECURITY_OTHER_SESSIONS_REVOKED, logrus.Fields{
+		"customerId":       customerId,
+		"currentSessionId": currentSessionId,
+		"revoked":          revoked,
+	})
+	return revoked, nil
+}
+
+// RevokeAllSessions ends every session of the customer
+func (s *sessionService) RevokeAllSessions(customerId string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	sessions, err := s.sessionRepository.GetByCustomerId(customerId)
+	if err != nil {
+		return err
+	}
+	for _, session := range sessions {
+		if err := s.sessionRepository.Delete(session.Id); err != nil {
+			return err
+		}
+	}
+	if err := s.refreshTokenService.DeleteRefreshTokensByCustomerId(customerId); err != nil {
+		return err
+	}
+
+	logSecurityEvent(enums.SECURITY_ALL_SESSIONS_REVOKED, logrus.Fields{
+		"customerId": customerId,
+		"revoked":    len(sessions),
+	})
+	return nil
+}
+
+// startSession is StartSession for callers already holding the lock
+func (s *sessionService) startSession(customerId string, client req.ClientInfo) (entity.Session, entity.RefreshToken, error) {
+	now := time.Now().Format(time.RFC3339)
+	session := entity.Session{
+		Id:         uuid.New().String(),
+		CustomerId: customerId,
+		DeviceName: truncate(client.DeviceName, sessionDeviceNameMaxLength),
+		Ip:         client.Ip,
+		UserAgent:  truncate(client.UserAgent, sessionUserAgentMaxLength),
+		CreatedAt:  now,
+		LastUsedAt: now,
+	}
+
+	refreshToken, err := s.refreshTokenService.GenerateRefreshToken(customerId, session.Id)
+	if err != nil {
+		return entity.Session{}, entity.RefreshToken{}, err
+	}
+
+	session.ExpiresAt = refreshToken.ExpiresAt
+	if err := s.sessionRepository.Save(session); err != nil {
+		return entity.Session{}, entity.RefreshToken{}, err
+	}
+
+	logger.LogInfo("Session started", logrus.Fields{
+		"customerId": customerId,
+		"sessionId":  session.Id,
+		"deviceName": session.DeviceName,
+		"clientIp":   session.Ip,
+	})
+	return session, refreshToken, nil
+}
+
+// IsSessionActive reports whether the session an access token was issued for still exists and has not expired. A
+// revoked or ended session is deleted, so its access tokens stop working with it.
+func (s *sessionService) IsSessionActive(customerId string, sessionId string) (bool, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	session, err := s.sessionRepository.GetById(sessionId)
+	if err != nil {
+		if err.Error() == constants.SessionNotFoundError {
+			return false, nil
+		}
+		return false, err
+	}
+	if session.CustomerId != customerId {
+		return false, nil
+	}
+
+	expiresAt, err := time.Parse(time.RFC3339, session.ExpiresAt)
+	return err == nil && time.Now().Before(expiresAt), nil
+}
+
+// getActiveSessions returns the sessions of the customer that have not expired, deleting the ones that have
+func (s *sessionService) getActiveSessions(customerId string) ([]entity.Session, error) {
+	sessions, err := s.sessionRepository.GetByCustomerId(customerId)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	active := make([]entity.Session, 0, len(sessions))
+	for _, session := range sessions {
+		expiresAt, err := time.Parse(time.RFC3339, session.ExpiresAt)
+		if err == nil && now.Before(expiresAt) {
+			active = append(active, session)
+			continue
+		}
+		if err := s.deleteSession(session.Id); err != nil {
+			return nil, err
+		}
+	}
+	return active, nil
+}
+
+// deleteSession deletes a session and the refresh tokens issued for it
+func (s *sessionService) deleteSession(sessionId string) error {
+	if err := s.refreshTokenService.DeleteRefreshTokensBySessionId(sessionId); err != nil {
+		return err
+	}
+	return s.sessionRepository.Delete(sessionId)
+}
+
+// truncate cuts a string to at most maxLength bytes without splitting a character
+func truncate(value string, maxLength int) string {
+	if len(value) <= maxLength {
+		return value
+	}
+	for maxLength > 0 && !utf8.RuneStart(value[maxLength]) {
+		maxLength--
+	}
+	return value[:maxLength]
+}
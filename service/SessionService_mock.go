@@ -0,0 +1,52 @@
+package service
+
+import (
+	req "PaymentAPI/dto/request"
+	res "PaymentAPI/dto/response"
+	"PaymentAPI/entity"
+	"github.com/stretchr/testify/mock"
+)
+
+type SessionServiceMock struct {
+	mock.Mock
+}
+
+func (m *SessionServiceMock) StartSession(customerId string, client req.ClientInfo) (entity.Session, entity.RefreshToken, error) {
+	args := m.Called(customerId, client)
+	return args.Get(0).(entity.Session), args.Get(1).(entity.RefreshToken), args.Error(2)
+}
+
+func (m *SessionServiceMock) RefreshSession(refreshToken string, client req.ClientInfo) (entity.Session, entity.RefreshToken, error) {
+	args := m.Called(refreshToken, client)
+	return args.Get(0).(entity.Session), args.Get(1).(entity.RefreshToken), args.Error(2)
+}
+
+func (m *SessionServiceMock) EndSession(refreshToken string) error {
+	args := m.Called(refreshToken)
+	return args.Error(0)
+}
+
+func (m *SessionServiceMock) GetSessions(customerId string, currentSessionId string) ([]res.SessionResponse, error) {
+	args := m.Called(customerId, currentSessionId)
+	return args.Get(0).([]res.SessionResponse), args.Error(1)
+}
+
+func (m *SessionServiceMock) RevokeSession(customerId string, sessionId string) error {
+	args := m.Called(customerId, sessionId)
+	return args.Error(0)
+}
+
+func (m *SessionServiceMock) RevokeOtherSessions(customerId string, currentSessionId string) (int, error) {
+	args := m.Called(customerId, currentSessionId)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *SessionServiceMock) RevokeAllSessions(customerId string) error {
+	args := m.Called(customerId)
+	return args.Error(0)
+}
+
+func (m *SessionServiceMock) IsSessionActive(customerId string, sessionId string) (bool, error) {
+	args := m.Called(customerId, sessionId)
+	return args.Bool(0), args.Error(1)
+}
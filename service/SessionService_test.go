@@ -0,0 +1,244 @@
+package service
+
+import (
+	"PaymentAPI/constants"
+	req "PaymentAPI/dto/request"
+	"PaymentAPI/entity"
+	"PaymentAPI/repository"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStartSession(t *testing.T) {
+	mockRepository := new(repository.SessionRepositoryMock)
+	mockRefreshTokenService := new(RefreshTokenServiceMock)
+	sessionService := NewSessionService(mockRepository, mockRefreshTokenService)
+
+	expiresAt := time.Now().Add(24 * time.Hour).Format(time.RFC3339)
+	mockRefreshTokenService.Mock.On("GenerateRefreshToken", "id-1", mock.Anything).
+		Return(entity.RefreshToken{RefreshToken: "refresh-token-1", CustomerId: "id-1", ExpiresAt: expiresAt}, nil)
+	mockRepository.Mock.On("Save", mock.Anything).Return(nil)
+
+	client := req.ClientInfo{Ip: "10.0.0.1", UserAgent: "okhttp/4.12", DeviceName: strings.Repeat("a", 100)}
+	session, refreshToken, err := sessionService.StartSession("id-1", client)
+	assert.Nil(t, err)
+	assert.Equal(t, "refresh-token-1", refreshToken.RefreshToken)
+	assert.Equal(t, "id-1", session.CustomerId)
+	assert.Equal(t, "10.0.0.1", session.Ip)
+	assert.Equal(t, "okhttp/4.12", session.UserAgent)
+	assert.Len(t, session.DeviceName, sessionDeviceNameMaxLength)
+	assert.Equal(t, expiresAt, session.ExpiresAt)
+
+	// The refresh token belongs to the stored session
+	mockRefreshTokenService.Mock.AssertCalled(t, "GenerateRefreshToken", "id-1", session.Id)
+	mockRepository.Mock.AssertCalled(t, "Save", session)
+}
+
+func TestRefreshSession(t *testing.T) {
+	client := req.ClientInfo{Ip: "10.0.0.2", UserAgent: "okhttp/4.12"}
+	expiresAt := time.Now().Add(24 * time.Hour).Format(time.RFC3339)
+
+	t.Run("ShouldRecordUseOfSession", func(t *testing.T) {
+		mockRepository := new(repository.SessionRepositoryMock)
+		mockRefreshTokenService := new(RefreshTokenServiceMock)
+		sessionService := NewSessionService(mockRepository, mockRefreshTokenService)
+
+		mockRefreshTokenService.Mock.On("RotateRefreshToken", "refresh-token-1").
+			Return(entity.RefreshToken{RefreshToken: "refresh-token-2", CustomerId: "id-1", SessionId: "session-1", ExpiresAt: expiresAt}, nil)
+		mockRepository.Mock.On("GetById", "session-1").
+			Return(entity.Session{Id: "session-1", CustomerId: "id-1", DeviceName: "Pixel 8", Ip: "10.0.0.1", LastUsedAt: "2026-01-01T00:00:00Z"}, nil)
+		mockRepository.Mock.On("Save", mock.Anything).Return(nil)
+
+		session, refreshToken, err := sessionService.RefreshSession("refresh-token-1", client)
+		assert.Nil(t, err)
+		assert.Equal(t, "refresh-token-2", refreshToken.RefreshToken)
+		assert.Equal(t, "session-1", session.Id)
+		assert.Equal(t, "Pixel 8", session.DeviceName)
+		assert.Equal(t, "10.0.0.2", session.Ip)
+		assert.Equal(t, expiresAt, session.ExpiresAt)
+		assert.NotEqual(t, "2026-01-01T00:00:00Z", session.LastUsedAt)
+	})
+
+	t.Run("ShouldRevokeSessionOnReuse", func(t *testing.T) {
+		mockRepository := new(repository.SessionRepositoryMock)
+		mockRefreshTokenService := new(RefreshTokenServiceMock)
+		sessionService := NewSessionService(mockRepository, mockRefreshTokenService)
+
+		mockRefreshTokenService.Mock.On("RotateRefreshToken", "refresh-token-1").
+			Return(entity.RefreshToken{}, &RefreshTokenReusedError{CustomerId: "id-1", SessionId: "session-1"})
+		mockRepository.Mock.On("Delete", "session-1").Return(nil)
+
+		_, _, err := sessionService.RefreshSession("refresh-token-1", client)
+		assert.Equal(t, constants.RefreshTokenReusedError, err.Error())
+		mockRepository.Mock.AssertCalled(t, "Delete", "session-1")
+	})
+
+	t.Run("ShouldReturnErrorForRevokedToken", func(t *testing.T) {
+		mockRepository := new(repository.SessionRepositoryMock)
+		mockRefreshTokenService := new(RefreshTokenServiceMock)
+		sessionService := NewSessionService(mockRepository, mockRefreshTokenService)
+
+		mockRefreshTokenService.Mock.On("RotateRefreshToken", "refresh-token-1").
+			Return(entity.RefreshToken{}, errors.New(constants.RefreshTokenNotFoundError))
+
+		_, _, err := sessionService.RefreshSession("refresh-token-1", client)
+		assert.Equal(t, constants.RefreshTokenNotFoundError, err.Error())
+		mockRepository.Mock.AssertNotCalled(t, "Save", mock.Anything)
+	})
+}
+
+func TestEndSession(t *testing.T) {
+	t.Run("ShouldDeleteSessionOfRefreshToken", func(t *testing.T) {
+		mockRepository := new(repository.SessionRepositoryMock)
+		mockRefreshTokenService := new(RefreshTokenServiceMock)
+		sessionService := NewSessionService(mockRepository, mockRefreshTokenService)
+
+		mockRefreshTokenService.Mock.On("GetRefreshToken", "refresh-token-1").
+			Return(entity.RefreshToken{RefreshToken: "refresh-token-1", CustomerId: "id-1", SessionId: "session-1"}, nil)
+		mockRefreshTokenService.Mock.On("DeleteRefreshTokensBySessionId", "session-1").Return(nil)
+		mockRepository.Mock.On("Delete", "session-1").Return(nil)
+
+		err := sessionService.EndSession("refresh-token-1")
+		assert.Nil(t, err)
+		mockRepository.Mock.AssertCalled(t, "Delete", "session-1")
+	})
+}
+
+func TestGetSessions(t *testing.T) {
+	mockRepository := new(repository.SessionRepositoryMock)
+	mockRefreshTokenService := new(RefreshTokenServiceMock)
+	sessionService := NewSessionService(mockRepository, mockRefreshTokenService)
+
+	now := time.Now()
+	active := now.Add(time.Hour).Format(time.RFC3339)
+	mockRepository.Mock.On("GetByCustomerId", "id-1").Return([]entity.Session{
+		{Id: "session-1", CustomerId: "id-1", LastUsedAt: now.Add(-2 * time.Hour).Format(time.RFC3339), ExpiresAt: active},
+		{Id: "session-2", CustomerId: "id-1", LastUsedAt: now.Add(-time.Hour).Format(time.RFC3339), ExpiresAt: active},
+		{Id: "session-3", CustomerId: "id-1", LastUsedAt: now.Add(-48 * time.Hour).Format(time.RFC3339), ExpiresAt: now.Add(-time.Hour).Format(time.RFC3339)},
+	}, nil)
+	mockRefreshTokenService.Mock.On("DeleteRefreshTokensBySessionId", "session-3").Return(nil)
+	mockRepository.Mock.On("Delete", "session-3").Return(nil)
+
+	sessions, err := sessionService.GetSessions("id-1", "session-1")
+	assert.Nil(t, err)
+	assert.Len(t, sessions, 2)
+
+	// Most recently used first, the expired session is cleaned up
+	assert.Equal(t, "session-2", sessions[0].Id)
+	assert.False(t, sessions[0].Current)
+	assert.Equal(t, "session-1", sessions[1].Id)
+	assert.True(t, sessions[1].Current)
+	mockRepository.Mock.AssertCalled(t, "Delete", "session-3")
+}
+
+func TestRevokeSession(t *testing.T) {
+	t.Run("ShouldRevokeOwnSession", func(t *testing.T) {
+		mockRepository := new(repository.SessionRepositoryMock)
+		mockRefreshTokenService := new(RefreshTokenServiceMock)
+		sessionService := NewSessionService(mockRepository, mockRefreshTokenService)
+
+		mockRepository.Mock.On("GetById", "session-1").Return(entity.Session{Id: "session-1", CustomerId: "id-1"}, nil)
+		mockRefreshTokenService.Mock.On("DeleteRefreshTokensBySessionId", "session-1").Return(nil)
+		mockRepository.Mock.On("Delete", "session-1").Return(nil)
+
+		err := sessionService.RevokeSession("id-1", "session-1")
+		assert.Nil(t, err)
+		mockRefreshTokenService.Mock.AssertCalled(t, "DeleteRefreshTokensBySessionId", "session-1")
+	})
+
+	t.Run("ShouldNotRevokeSessionOfAnotherCustomer", func(t *testing.T) {
+		mockRepository := new(repository.SessionRepositoryMock)
+		mockRefreshTokenService := new(RefreshTokenServiceMock)
+		sessionService := NewSessionService(mockRepository, mockRefreshTokenService)
+
+		mockRepository.Mock.On("GetById", "session-1").Return(entity.Session{Id: "session-1", CustomerId: "id-2"}, nil)
+
+		err := sessionService.RevokeSession("id-1", "session-1")
+		assert.Equal(t, constants.SessionNotFoundError, err.Error())
+		mockRepository.Mock.AssertNotCalled(t, "Delete", mock.Anything)
+	})
+}
+
+func TestRevokeOtherSessions(t *testing.T) {
+	t.Run("ShouldKeepCurrentSession", func(t *testing.T) {
+		mockRepository := new(repository.SessionRepositoryMock)
+		mockRefreshTokenService := new(RefreshTokenServiceMock)
+		sessionService := NewSessionService(mockRepository, mockRefreshTokenService)
+
+		mockRepository.Mock.On("GetByCustomerId", "id-1").Return([]entity.Session{
+			{Id: "session-1", CustomerId: "id-1"},
+			{Id: "session-2", CustomerId: "id-1"},
+			{Id: "session-3", CustomerId: "id-1"},
+		}, nil)
+		mockRefreshTokenService.Mock.On("DeleteRefreshTokensBySessionId", mock.Anything).Return(nil)
+		mockRepository.Mock.On("Delete", mock.Anything).Return(nil)
+
+		revoked, err := sessionService.RevokeOtherSessions("id-1", "session-2")
+		assert.Nil(t, err)
+		assert.Equal(t, 2, revoked)
+		mockRepository.Mock.AssertCalled(t, "Delete", "session-1")
+		mockRepository.Mock.AssertCalled(t, "Delete", "session-3")
+		mockRepository.Mock.AssertNotCalled(t, "Delete", "session-2")
+	})
+
+	t.Run("ShouldReturnErrorWithoutCurrentSession", func(t *testing.T) {
+		mockRepository := new(repository.SessionRepositoryMock)
+		sessionService := NewSessionService(mockRepository, new(RefreshTokenServiceMock))
+
+		_, err := sessionService.RevokeOtherSessions("id-1", "")
+		assert.Equal(t, constants.SessionUnknownError, err.Error())
+		mockRepository.Mock.AssertNotCalled(t, "GetByCustomerId", mock.Anything)
+	})
+}
+
+func TestRevokeAllSessions(t *testing.T) {
+	mockRepository := new(repository.SessionRepositoryMock)
+	mockRefreshTokenService := new(RefreshTokenServiceMock)
+	sessionService := NewSessionService(mockRepository, mockRefreshTokenService)
+
+	mockRepository.Mock.On("GetByCustomerId", "id-1").Return([]entity.Session{
+		{Id: "session-1", CustomerId: "id-1"},
+		{Id: "session-2", CustomerId: "id-1"},
+	}, nil)
+	mockRepository.Mock.On("Delete", mock.Anything).Return(nil)
+	mockRefreshTokenService.Mock.On("DeleteRefreshTokensByCustomerId", "id-1").Return(nil)
+
+	err := sessionService.RevokeAllSessions("id-1")
+	assert.Nil(t, err)
+	mockRepository.Mock.AssertNumberOfCalls(t, "Delete", 2)
+	mockRefreshTokenService.Mock.AssertCalled(t, "DeleteRefreshTokensByCustomerId", "id-1")
+}
+
+func TestIsSessionActive(t *testing.T) {
+	mockRepository := new(repository.SessionRepositoryMock)
+	sessionService := NewSessionService(mockRepository, new(RefreshTokenServiceMock))
+
+	expiresAt := time.Now().Add(24 * time.Hour).Format(time.RFC3339)
+	mockRepository.Mock.On("GetById", "session-1").Return(entity.Session{Id: "session-1", CustomerId: "id-1", ExpiresAt: expiresAt}, nil)
+	mockRepository.Mock.On("GetById", "session-2").Return(entity.Session{Id: "session-2", CustomerId: "id-1", ExpiresAt: time.Now().Add(-time.Hour).Format(time.RFC3339)}, nil)
+	mockRepository.Mock.On("GetById", "session-3").Return(entity.Session{}, errors.New(constants.SessionNotFoundError))
+
+	tests := []struct {
+		name       string
+		customerId string
+		sessionId  string
+		active     bool
+	}{
+		{name: "Should Accept Active Session", customerId: "id-1", sessionId: "session-1", active: true},
+		{name: "Should Refuse Session Of Another Customer", customerId: "id-2", sessionId: "session-1"},
+		{name: "Should Refuse Expired Session", customerId: "id-1", sessionId: "session-2"},
+		{name: "Should Refuse Revoked Session", customerId: "id-1", sessionId: "session-3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			active, err := sessionService.IsSessionActive(tt.customerId, tt.sessionId)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.active, active)
+		})
+	}
+}
@@ -0,0 +1,210 @@
+package service
+
+import (
+	"PaymentAPI/constants"
+	req "PaymentAPI/dto/request"
+	res "PaymentAPI/dto/response"
+	"PaymentAPI/entity"
+	"PaymentAPI/enums"
+	"PaymentAPI/repository"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"math"
+	"time"
+)
+
+type SplitBillService interface {
+	CreateSplitBill(request req.CreateSplitBillRequest) (res.SplitBillResponse, error)
+	GetSplitBillById(id string) (res.SplitBillResponse, error)
+}
+
+type splitBillService struct {
+	splitBillRepository   repository.SplitBillRepository
+	walletService         WalletService
+	paymentRequestService PaymentRequestService
+}
+
+// NewSplitBillService creates a new instance of SplitBillService
+func NewSplitBillService(splitBillRepository repository.SplitBillRepository, walletService WalletService, paymentRequestService PaymentRequestService) SplitBillService {
+	return &splitBillService{splitBillRepository, walletService, paymentRequestService}
+}
+
+// CreateSplitBill divides a bill between the creator and the participants and sends every participant
+// a payment request for their share. The creator pays their own share directly, so it is never requested.
+func (s *splitBillService) CreateSplitBill(request req.CreateSplitBillRequest) (res.SplitBillResponse, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"creatorWalletId": request.CreatorWalletId,
+		"totalAmount":     request.TotalAmount,
+		"splitType":       request.SplitType,
+	})
+
+	logger.Info("Creating new split bill")
+
+	participants, err := splitShares(request)
+	if err != nil {
+		logger.Error("Invalid split bill", err)
+		return res.SplitBillResponse{}, err
+	}
+
+	// Check every wallet upfront, so no payment request is sent for a bill that cannot be created
+	if _, err := s.walletService.GetWalletById(request.CreatorWalletId); err != nil {
+		logger.Error("Failed to retrieve creator wallet", err)
+		return res.SplitBillResponse{}, err
+	}
+	for _, participant := range participants {
+		if _, err := s.walletService.GetWalletById(participant.WalletId); err != nil {
+			logger.Error("Failed to retrieve participant wallet", err)
+			return res.SplitBillResponse{}, err
+		}
+	}
+
+	splitBill := entity.SplitBill{
+		Id:              uuid.New().String(),
+		CreatorWalletId: request.CreatorWalletId,
+		TotalAmount:     request.TotalAmount,
+		Message:         request.Message,
+		SplitType:       request.SplitType,
+		CreatedAt:       time.Now().Format(time.RFC3339),
+	}
+
+	paymentRequests := make([]entity.PaymentRequest, 0, len(participants))
+	for i, participant := range participants {
+		paymentRequest, err := s.paymentRequestService.CreatePaymentRequest(req.CreatePaymentRequestRequest{
+			RequesterWalletId: splitBill.CreatorWalletId,
+			PayerWalletId:     participant.WalletId,
+			Amount:            participant.Amount,
+			Message:           splitBill.Message,
+			SplitBillId:       splitBill.Id,
+		})
+		if err != nil {
+			logger.Error("Failed to create payment request for participant", err)
+			s.cancelPaymentRequests(paymentRequests)
+			return res.SplitBillResponse{}, err
+		}
+		participants[i].PaymentRequestId = paymentRequest.Id
+		paymentRequests = append(paymentRequests, paymentRequest)
+	}
+	splitBill.Participants = participants
+
+	if err := s.splitBillRepository.Create(splitBill); err != nil {
+		logger.Error("Failed to create split bill", err)
+		s.cancelPaymentRequests(paymentRequests)
+		return res.SplitBillResponse{}, err
+	}
+
+	logger.Info("Split bill created successfully")
+	return mapSplitBillToSplitBillResponse(splitBill, paymentRequests), nil
+}
+
+// GetSplitBillById retrieves a split bill with the current status of every participant's payment request
+func (s *splitBillService) GetSplitBillById(id string) (res.SplitBillResponse, error) {
+	splitBill, err := s.splitBillRepository.GetById(id)
+	if err != nil {
+		return res.SplitBillResponse{}, err
+	}
+
+	paymentRequests := make([]entity.PaymentRequest, 0, len(splitBill.Participants))
+	for _, participant := range splitBill.Participants {
+		paymentRequest, err := s.paymentRequestService.GetPaymentRequestById(participant.PaymentRequestId)
+		if err != nil {
+			return res.SplitBillResponse{}, err
+		}
+		paymentRequests = append(paymentRequests, paymentRequest)
+	}
+
+	return mapSplitBillToSplitBillResponse(splitBill, paymentRequests), nil
+}
+
+// cancelPaymentRequests declines the payment requests of a split bill that could not be created
+func (s *splitBillService) cancelPaymentRequests(paymentRequests []entity.PaymentRequest) {
+	for _, paymentRequest := range paymentRequests {
+		s.paymentRequestService.DeclinePaymentRequest(paymentRequest.Id)
+	}
+}
+
+// splitShares validates the participants and returns the share each of them has to pay
+func splitShares(request req.CreateSplitBillRequest) ([]entity.SplitBillParticipant, error) {
+	if request.TotalAmount <= 0 {
+		return nil, errors.New(constants.TransactionInvalidAmountError)
+	}
+	if len(request.Participants) == 0 {
+		return nil, errors.New(constants.SplitBillNoParticipantsError)
+	}
+
+	seen := map[string]bool{request.CreatorWalletId: true}
+	for _, participant := range request.Participants {
+		if seen[participant.WalletId] {
+			return nil, errors.New(constants.SplitBillParticipantError)
+		}
+		seen[participant.WalletId] = true
+	}
+
+	participants := make([]entity.SplitBillParticipant, 0, len(request.Participants))
+	switch request.SplitType {
+	case enums.SPLIT_EQUAL:
+		// The share is rounded down to the cent, the creator covers the remainder
+		share := math.Floor(request.TotalAmount/float64(len(request.Participants)+1)*100) / 100
+		if share <= 0 {
+			return nil, errors.New(constants.SplitBillShareError)
+		}
+		for _, participant := range request.Participants {
+			participants = append(participants, entity.SplitBillParticipant{WalletId: participant.WalletId, Amount: share})
+		}
+	case enums.SPLIT_CUSTOM:
+		// Whatever the participants do not cover is the creator's own share
+		var requested float64
+		for _, participant := range request.Participants {
+			if participant.Amount <= 0 {
+				return nil, errors.New(constants.SplitBillShareError)
+			}
+			requested += participant.Amount
+			participants = append(participants, entity.SplitBillParticipant{WalletId: participant.WalletId, Amount: participant.Amount})
+		}
+		if requested > request.TotalAmount {
+			return nil, errors.New(constants.SplitBillShareTotalError)
+		}
+	default:
+		return nil, errors.New(constants.SplitBillTypeError)
+	}
+
+	return participants, nil
+}
+
+func mapSplitBillToSplitBillResponse(splitBill entity.SplitBill, paymentRequests []entity.PaymentRequest) res.SplitBillResponse {
+	response := res.SplitBillResponse{
+		Id:              splitBill.Id,
+		CreatorWalletId: splitBill.CreatorWalletId,
+		TotalAmount:     splitBill.TotalAmount,
+		Message:         splitBill.Message,
+		SplitType:       splitBill.SplitType,
+		Status:          enums.SPLIT_BILL_SETTLED,
+		CreatedAt:       splitBill.CreatedAt,
+	}
+
+	for i, participant := range splitBill.Participants {
+		status := paymentRequests[i].Status
+		response.Participants = append(response.Participants, res.SplitBillParticipantResponse{
+			WalletId:         participant.WalletId,
+			Amount:           participant.Amount,
+			PaymentRequestId: participant.PaymentRequestId,
+			Status:           status,
+		})
+
+		switch status {
+		case enums.PAYMENT_REQUEST_ACCEPTED:
+			response.PaidAmount += participant.Amount
+		case enums.PAYMENT_REQUEST_PENDING:
+			response.OutstandingAmount += participant.Amount
+			response.Status = enums.SPLIT_BILL_OPEN
+		default:
+			// A declined or expired share is never paid, the bill cannot settle anymore
+			response.OutstandingAmount += participant.Amount
+			if response.Status == enums.SPLIT_BILL_SETTLED {
+				response.Status = enums.SPLIT_BILL_INCOMPLETE
+			}
+		}
+	}
+
+	return response
+}
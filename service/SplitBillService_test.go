@@ -0,0 +1,155 @@
+package service
+
+import (
+	"PaymentAPI/constants"
+	req "PaymentAPI/dto/request"
+	"PaymentAPI/entity"
+	"PaymentAPI/enums"
+	"PaymentAPI/repository"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+)
+
+type splitBillTest struct {
+	mockSplitBillRepository   *repository.SplitBillRepositoryMock
+	mockWalletService         *WalletServiceMock
+	mockPaymentRequestService *PaymentRequestServiceMock
+	service                   SplitBillService
+}
+
+func setupSplitBillTest() splitBillTest {
+	mockSplitBillRepository := new(repository.SplitBillRepositoryMock)
+	mockWalletService := new(WalletServiceMock)
+	mockPaymentRequestService := new(PaymentRequestServiceMock)
+	return splitBillTest{
+		mockSplitBillRepository:   mockSplitBillRepository,
+		mockWalletService:         mockWalletService,
+		mockPaymentRequestService: mockPaymentRequestService,
+		service:                   NewSplitBillService(mockSplitBillRepository, mockWalletService, mockPaymentRequestService),
+	}
+}
+
+func TestCreateSplitBill(t *testing.T) {
+	participants := []req.SplitBillParticipantRequest{{WalletId: "wallet-2", Amount: 40000}, {WalletId: "wallet-3", Amount: 20000}}
+
+	tests := []struct {
+		name           string
+		request        req.CreateSplitBillRequest
+		expectedShares []float64
+		expectedError  string
+	}{
+		{
+			name:           "Should Split Equally Including Creator",
+			request:        req.CreateSplitBillRequest{CreatorWalletId: "wallet-1", TotalAmount: 100, SplitType: enums.SPLIT_EQUAL, Participants: participants},
+			expectedShares: []float64{33.33, 33.33},
+		},
+		{
+			name:           "Should Use Custom Shares",
+			request:        req.CreateSplitBillRequest{CreatorWalletId: "wallet-1", TotalAmount: 90000, SplitType: enums.SPLIT_CUSTOM, Participants: participants},
+			expectedShares: []float64{40000, 20000},
+		},
+		{
+			name:          "Should Fail When Custom Shares Exceed Total",
+			request:       req.CreateSplitBillRequest{CreatorWalletId: "wallet-1", TotalAmount: 50000, SplitType: enums.SPLIT_CUSTOM, Participants: participants},
+			expectedError: constants.SplitBillShareTotalError,
+		},
+		{
+			name: "Should Fail When Creator Is A Participant",
+			request: req.CreateSplitBillRequest{CreatorWalletId: "wallet-1", TotalAmount: 100, SplitType: enums.SPLIT_EQUAL,
+				Participants: []req.SplitBillParticipantRequest{{WalletId: "wallet-1"}}},
+			expectedError: constants.SplitBillParticipantError,
+		},
+		{
+			name:          "Should Fail On Unknown Split Type",
+			request:       req.CreateSplitBillRequest{CreatorWalletId: "wallet-1", TotalAmount: 100, SplitType: "HALF", Participants: participants},
+			expectedError: constants.SplitBillTypeError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			test := setupSplitBillTest()
+
+			test.mockWalletService.On("GetWalletById", mock.Anything).Return(entity.Wallet{}, nil)
+			test.mockPaymentRequestService.On("CreatePaymentRequest", mock.Anything).Return(entity.PaymentRequest{Status: enums.PAYMENT_REQUEST_PENDING}, nil)
+			test.mockSplitBillRepository.Mock.On("Create", mock.Anything).Return(nil)
+
+			splitBill, err := test.service.CreateSplitBill(tt.request)
+			if tt.expectedError != "" {
+				assert.Equal(t, tt.expectedError, err.Error())
+				test.mockPaymentRequestService.AssertNotCalled(t, "CreatePaymentRequest", mock.Anything)
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, enums.SPLIT_BILL_OPEN, splitBill.Status)
+			for i, share := range tt.expectedShares {
+				assert.Equal(t, share, splitBill.Participants[i].Amount)
+				test.mockPaymentRequestService.AssertCalled(t, "CreatePaymentRequest", req.CreatePaymentRequestRequest{
+					RequesterWalletId: "wallet-1",
+					PayerWalletId:     splitBill.Participants[i].WalletId,
+					Amount:            share,
+					SplitBillId:       splitBill.Id,
+				})
+			}
+		})
+	}
+
+	t.Run("ShouldDeclineSentRequestsWhenOneFails", func(t *testing.T) {
+		test := setupSplitBillTest()
+		request := req.CreateSplitBillRequest{CreatorWalletId: "wallet-1", TotalAmount: 90000, SplitType: enums.SPLIT_CUSTOM, Participants: participants}
+
+		test.mockWalletService.On("GetWalletById", mock.Anything).Return(entity.Wallet{}, nil)
+		test.mockPaymentRequestService.On("CreatePaymentRequest", mock.MatchedBy(func(r req.CreatePaymentRequestRequest) bool {
+			return r.PayerWalletId == "wallet-2"
+		})).Return(entity.PaymentRequest{Id: "payment-request-1"}, nil)
+		test.mockPaymentRequestService.On("CreatePaymentRequest", mock.Anything).Return(entity.PaymentRequest{}, errors.New(constants.JsonWriteError))
+		test.mockPaymentRequestService.On("DeclinePaymentRequest", "payment-request-1").Return(entity.PaymentRequest{}, nil)
+
+		_, err := test.service.CreateSplitBill(request)
+		assert.Equal(t, constants.JsonWriteError, err.Error())
+		test.mockPaymentRequestService.AssertCalled(t, "DeclinePaymentRequest", "payment-request-1")
+		test.mockSplitBillRepository.Mock.AssertNotCalled(t, "Create", mock.Anything)
+	})
+}
+
+func TestGetSplitBillById(t *testing.T) {
+	splitBill := entity.SplitBill{
+		Id:              "split-bill-1",
+		CreatorWalletId: "wallet-1",
+		TotalAmount:     90000,
+		Participants: []entity.SplitBillParticipant{
+			{WalletId: "wallet-2", Amount: 30000, PaymentRequestId: "payment-request-1"},
+			{WalletId: "wallet-3", Amount: 30000, PaymentRequestId: "payment-request-2"},
+		},
+	}
+
+	tests := []struct {
+		name           string
+		secondStatus   enums.PaymentRequestStatus
+		expectedStatus enums.SplitBillStatus
+		expectedPaid   float64
+	}{
+		{name: "Should Be Open While A Share Is Pending", secondStatus: enums.PAYMENT_REQUEST_PENDING, expectedStatus: enums.SPLIT_BILL_OPEN, expectedPaid: 30000},
+		{name: "Should Be Settled When Everyone Paid", secondStatus: enums.PAYMENT_REQUEST_ACCEPTED, expectedStatus: enums.SPLIT_BILL_SETTLED, expectedPaid: 60000},
+		{name: "Should Be Incomplete When A Share Is Declined", secondStatus: enums.PAYMENT_REQUEST_DECLINED, expectedStatus: enums.SPLIT_BILL_INCOMPLETE, expectedPaid: 30000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			test := setupSplitBillTest()
+
+			test.mockSplitBillRepository.Mock.On("GetById", splitBill.Id).Return(splitBill, nil)
+			test.mockPaymentRequestService.On("GetPaymentRequestById", "payment-request-1").Return(entity.PaymentRequest{Status: enums.PAYMENT_REQUEST_ACCEPTED}, nil)
+			test.mockPaymentRequestService.On("GetPaymentRequestById", "payment-request-2").Return(entity.PaymentRequest{Status: tt.secondStatus}, nil)
+
+			response, err := test.service.GetSplitBillById(splitBill.Id)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expectedStatus, response.Status)
+			assert.Equal(t, tt.expectedPaid, response.PaidAmount)
+			assert.Equal(t, 60000-tt.expectedPaid, response.OutstandingAmount)
+		})
+	}
+}
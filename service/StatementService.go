@@ -0,0 +1,177 @@
+package service
+
+import (
+	"PaymentAPI/constants"
+	req "PaymentAPI/dto/request"
+	"PaymentAPI/entity"
+	"PaymentAPI/enums"
+	"PaymentAPI/repository"
+	"errors"
+	"github.com/sirupsen/logrus"
+	"sort"
+	"time"
+)
+
+// statementCurrency is the currency of every wallet balance
+const statementCurrency = "IDR"
+
+// statementMaxDays keeps a statement from replaying more than a year of transactions into one document
+const statementMaxDays = 366
+
+type StatementService interface {
+	GetStatement(walletId string, request req.StatementRequest) (entity.Statement, error)
+}
+
+type statementService struct {
+	transactionRepository repository.TransactionRepository
+	walletService         WalletService
+}
+
+// NewStatementService creates a new instance of StatementService
+func NewStatementService(transactionRepository repository.TransactionRepository, walletService WalletService) StatementService {
+	return &statementService{transactionRepository, walletService}
+}
+
+// GetStatement lists the transactions of the wallet in the period. The closing balance is the stored wallet balance
+// less everything booked after the period, the opening balance is the closing balance less everything booked in it,
+// and every transaction is listed with the balance after it. Without a month or dates the statement covers the
+// current month.
+func (s *statementService) GetStatement(walletId string, request req.StatementRequest) (entity.Statement, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"walletId": walletId,
+		"month":    request.Month,
+		"from":     request.From,
+		"to":       request.To,
+	})
+
+	logger.Info("Generating statement")
+
+	start, end, err := parseStatementPeriod(request, time.Now())
+	if err != nil {
+		return entity.Statement{}, err
+	}
+
+	wallet, err := s.walletService.GetWalletById(walletId)
+	if err != nil {
+		logger.Error("Failed to retrieve wallet", err)
+		return entity.Statement{}, err
+	}
+
+	transactions, err := s.transactionRepository.GetAll()
+	if err != nil {
+		logger.Error("Failed to retrieve transactions", err)
+		return entity.Statement{}, err
+	}
+
+	// The transaction log is in the order transactions were recorded, sort by time in case clocks disagreed
+	type booking struct {
+		entry    entity.StatementEntry
+		bookedAt time.Time
+	}
+	var bookings []booking
+	bookedAfter := 0.0
+	for _, transaction := range transactions {
+		if transaction.FromWalletId == transaction.ToWalletId {
+			continue
+		}
+		if transaction.FromWalletId != wallet.Id && transaction.ToWalletId != wallet.Id {
+			continue
+		}
+		bookedAt, err := time.Parse(time.RFC3339, transaction.CreatedAt)
+		if err != nil {
+			logger.Warnf("Skipping transaction %s with an invalid date", transaction.Id)
+			continue
+		}
+
+		entry := toStatementEntry(wallet.Id, transaction)
+		switch {
+		case !bookedAt.Before(end):
+			bookedAfter += entry.Amount
+		case !bookedAt.Before(start):
+			bookings = append(bookings, booking{entry, bookedAt})
+		}
+	}
+	sort.SliceStable(bookings, func(i, j int) bool {
+		return bookings[i].bookedAt.Before(bookings[j].bookedAt)
+	})
+
+	statement := entity.Statement{
+		WalletId:       wallet.Id,
+		CustomerId:     wallet.CustomerId,
+		Currency:       statementCurrency,
+		PeriodStart:    start.Format(time.DateOnly),
+		PeriodEnd:      end.AddDate(0, 0, -1).Format(time.DateOnly),
+		ClosingBalance: wallet.Balance - bookedAfter,
+		Entries:        []entity.StatementEntry{},
+		GeneratedAt:    time.Now().Format(time.RFC3339),
+	}
+
+	for _, booking := range bookings {
+		if booking.entry.Type == enums.STATEMENT_CREDIT {
+			statement.TotalCredits += booking.entry.Amount
+		} else {
+			statement.TotalDebits -= booking.entry.Amount
+		}
+	}
+	statement.OpeningBalance = statement.ClosingBalance - statement.TotalCredits + statement.TotalDebits
+
+	balance := statement.OpeningBalance
+	for _, booking := range bookings {
+		balance += booking.entry.Amount
+		booking.entry.Balance = balance
+		statement.Entries = append(statement.Entries, booking.entry)
+	}
+
+	logger.WithFields(logrus.Fields{"entries": len(statement.Entries)}).Info("Statement generated successfully")
+	return statement, nil
+}
+
+// toStatementEntry describes the transaction from the side of the wallet, money leaving it is a debit
+func toStatementEntry(walletId string, transaction entity.Transaction) entity.StatementEntry {
+	entry := entity.StatementEntry{
+		TransactionId: transaction.Id,
+		BookedAt:      transaction.CreatedAt,
+		Description:   transaction.Message,
+	}
+	if transaction.FromWalletId == walletId {
+		entry.Type = enums.STATEMENT_DEBIT
+		entry.Counterparty = transaction.ToWalletId
+		entry.Amount = -transaction.Amount
+	} else {
+		entry.Type = enums.STATEMENT_CREDIT
+		entry.Counterparty = transaction.FromWalletId
+		entry.Amount = transaction.Amount
+	}
+	return entry
+}
+
+// parseStatementPeriod returns the start of the first day and the start of the day after the last day of the period,
+// in the server's time zone. A month takes precedence over dates, and a missing date defaults to the current month.
+func parseStatementPeriod(request req.StatementRequest, now time.Time) (time.Time, time.Time, error) {
+	if request.Month != "" {
+		start, err := time.ParseInLocation("2006-01", request.Month, time.Local)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New(constants.StatementPeriodError)
+		}
+		return start, start.AddDate(0, 1, 0), nil
+	}
+
+	if request.From == "" && request.To == "" {
+		start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local)
+		return start, start.AddDate(0, 1, 0), nil
+	}
+
+	start, err := time.ParseInLocation(time.DateOnly, request.From, time.Local)
+	if err != nil {
+		return time.Time{}, time.Time{}, errors.New(constants.StatementPeriodError)
+	}
+	last, err := time.ParseInLocation(time.DateOnly, request.To, time.Local)
+	if err != nil || last.Before(start) {
+		return time.Time{}, time.Time{}, errors.New(constants.StatementPeriodError)
+	}
+	end := last.AddDate(0, 0, 1)
+	if end.After(start.AddDate(0, 0, statementMaxDays)) {
+		return time.Time{}, time.Time{}, errors.New(constants.StatementPeriodTooLongError)
+	}
+	return start, end, nil
+}
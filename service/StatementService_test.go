@@ -0,0 +1,77 @@
+package service
+
+import (
+	"PaymentAPI/constants"
+	req "PaymentAPI/dto/request"
+	"PaymentAPI/entity"
+	"PaymentAPI/enums"
+	"PaymentAPI/repository"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestGetStatement(t *testing.T) {
+	// Timestamps in the server's time zone, so the month boundaries are the same as the statement's
+	at := func(date string) string {
+		parsed, _ := time.ParseInLocation("2006-01-02 15:04", date, time.Local)
+		return parsed.Format(time.RFC3339)
+	}
+	transactions := []entity.Transaction{
+		{Id: "transaction-1", FromWalletId: "EXTERNAL_BANK", ToWalletId: "wallet-1", Amount: 10000, CreatedAt: at("2024-09-30 23:59")},
+		{Id: "transaction-2", FromWalletId: "wallet-1", ToWalletId: "wallet-2", Amount: 2500, Message: "Lunch", CreatedAt: at("2024-10-01 00:00")},
+		{Id: "transaction-3", FromWalletId: "wallet-2", ToWalletId: "wallet-3", Amount: 700, CreatedAt: at("2024-10-05 10:00")},
+		{Id: "transaction-5", FromWalletId: "wallet-1", ToWalletId: "BILLER_PLN", Amount: 1000, CreatedAt: at("2024-10-20 12:00")},
+		{Id: "transaction-4", FromWalletId: "wallet-2", ToWalletId: "wallet-1", Amount: 300.5, CreatedAt: at("2024-10-12 09:30")},
+		{Id: "transaction-6", FromWalletId: "wallet-2", ToWalletId: "wallet-1", Amount: 5000, CreatedAt: at("2024-11-01 00:00")},
+	}
+
+	setup := func() StatementService {
+		mockTransactionRepository := new(repository.TransactionRepositoryMock)
+		mockWalletService := new(WalletServiceMock)
+		mockTransactionRepository.Mock.On("GetAll").Return(transactions, nil)
+		mockWalletService.On("GetWalletById", "wallet-1").Return(entity.Wallet{Id: "wallet-1", CustomerId: "customer-1", Balance: 11800.5}, nil)
+		return NewStatementService(mockTransactionRepository, mockWalletService)
+	}
+
+	t.Run("ShouldWorkBackFromWalletBalanceOverTheMonth", func(t *testing.T) {
+		statement, err := setup().GetStatement("wallet-1", req.StatementRequest{Month: "2024-10"})
+		assert.Nil(t, err)
+		assert.Equal(t, "2024-10-01", statement.PeriodStart)
+		assert.Equal(t, "2024-10-31", statement.PeriodEnd)
+		assert.Equal(t, "customer-1", statement.CustomerId)
+		assert.Equal(t, 10000.0, statement.OpeningBalance)
+		assert.Equal(t, 300.5, statement.TotalCredits)
+		assert.Equal(t, 3500.0, statement.TotalDebits)
+		assert.Equal(t, 6800.5, statement.ClosingBalance)
+
+		// Entries are in time order, whatever the order in the log
+		assert.Equal(t, []entity.StatementEntry{
+			{TransactionId: "transaction-2", BookedAt: at("2024-10-01 00:00"), Type: enums.STATEMENT_DEBIT, Counterparty: "wallet-2", Description: "Lunch", Amount: -2500, Balance: 7500},
+			{TransactionId: "transaction-4", BookedAt: at("2024-10-12 09:30"), Type: enums.STATEMENT_CREDIT, Counterparty: "wallet-2", Amount: 300.5, Balance: 7800.5},
+			{TransactionId: "transaction-5", BookedAt: at("2024-10-20 12:00"), Type: enums.STATEMENT_DEBIT, Counterparty: "BILLER_PLN", Amount: -1000, Balance: 6800.5},
+		}, statement.Entries)
+	})
+
+	t.Run("ShouldCoverDateRangeInclusive", func(t *testing.T) {
+		statement, err := setup().GetStatement("wallet-1", req.StatementRequest{From: "2024-09-30", To: "2024-10-01"})
+		assert.Nil(t, err)
+		assert.Equal(t, 0.0, statement.OpeningBalance)
+		assert.Len(t, statement.Entries, 2)
+		assert.Equal(t, 7500.0, statement.ClosingBalance)
+	})
+
+	t.Run("ShouldRejectInvalidPeriod", func(t *testing.T) {
+		for _, request := range []req.StatementRequest{
+			{Month: "October"},
+			{From: "2024-10-01"},
+			{From: "2024-10-10", To: "2024-10-01"},
+		} {
+			_, err := setup().GetStatement("wallet-1", request)
+			assert.Equal(t, constants.StatementPeriodError, err.Error())
+		}
+
+		_, err := setup().GetStatement("wallet-1", req.StatementRequest{From: "2023-01-01", To: "2024-10-01"})
+		assert.Equal(t, constants.StatementPeriodTooLongError, err.Error())
+	})
+}
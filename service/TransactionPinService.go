@@ -0,0 +1,248 @@
+package service
+
+import (
+	"PaymentAPI/config"
+	"PaymentAPI/constants"
+	req "PaymentAPI/dto/request"
+	"PaymentAPI/entity"
+	"PaymentAPI/enums"
+	"PaymentAPI/logger"
+	"PaymentAPI/repository"
+	"PaymentAPI/utils"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+type TransactionPinService interface {
+	SetPin(customerId string, request req.SetTransactionPinRequest) error
+	AuthorizeTransfer(customerId string, walletId string, amount float64, stepUp req.StepUp) error
+}
+
+// TransfersLockedError refuses a transfer while transfers are locked after failed step-ups, telling the client how
+// long to wait
+type TransfersLockedError struct {
+	RetryAfter time.Duration
+}
+
+func (t *TransfersLockedError) Error() string {
+	return constants.TransactionPinLockedError
+}
+
+type transactionPinService struct {
+	transactionPinRepository repository.TransactionPinRepository
+	customerService          CustomerService
+	twoFactorService         TwoFactorService
+	// customerLocks holds a mutex per customer, so verifying the PIN of one customer does not hold up the others
+	customerLocks sync.Map
+}
+
+// NewTransactionPinService creates a new instance of TransactionPinService
+func NewTransactionPinService(transactionPinRepository repository.TransactionPinRepository, customerService CustomerService, twoFactorService TwoFactorService) TransactionPinService {
+	return &transactionPinService{
+		transactionPinRepository: transactionPinRepository,
+		customerService:          customerService,
+		twoFactorService:         twoFactorService,
+	}
+}
+
+// SetPin sets or replaces the transaction PIN of the customer. The password is required, and a two-factor code too
+// when two-factor authentication is enabled, so a stolen access token cannot set a PIN of its own.
+func (t *transactionPinService) SetPin(customerId string, request req.SetTransactionPinRequest) error {
+	logger.LogInfo("Attempting to set transaction PIN", logrus.Fields{
+		"customerId": customerId,
+	})
+
+	if err := checkTransactionPin(request.Pin); err != nil {
+		return err
+	}
+
+	customer, err := t.customerService.GetCustomerByIdAuth(customerId)
+	if err != nil {
+		return err
+	}
+	if !utils.VerifyPassword(request.Password, customer.Password) {
+		logger.LogWarning("Incorrect password on transaction PIN change", logrus.Fields{
+			"customerId": customerId,
+		})
+		return errors.New(constants.PasswordIncorrectError)
+	}
+
+	twoFactorEnabled, err := t.twoFactorService.IsEnabled(customerId)
+	if err != nil {
+		return err
+	}
+	if twoFactorEnabled {
+		if err := t.twoFactorService.VerifyCode(customerId, request.Code); err != nil {
+			return err
+		}
+	}
+
+	pinHash, err := utils.HashPassword(request.Pin)
+	if err != nil {
+		return err
+	}
+
+	lock := t.lockFor(customerId)
+	lock.Lock()
+	defer lock.Unlock()
+
+	// Failures and a lock are kept, setting a new PIN is no way around them
+	transactionPin, err := t.getTransactionPin(customerId)
+	if err != nil {
+		return err
+	}
+	transactionPin.PinHash = pinHash
+	transactionPin.UpdatedAt = time.Now().Format(time.RFC3339)
+	if err := t.transactionPinRepository.Save(transactionPin); err != nil {
+		return err
+	}
+
+	logSecurityEvent(enums.SECURITY_TRANSACTION_PIN_SET, logrus.Fields{
+		"customerId": customerId,
+	})
+	return nil
+}
+
+// AuthorizeTransfer refuses every debit of the wallet while transfers of its owner are locked, and requires the
+// transaction PIN or a two-factor code for a debit above the step-up threshold that was not already authorized as part
+// of its operation. Wrong PINs and codes lock transfers after too many. Step-ups of the same customer are checked one
+// at a time, so concurrent guesses cannot get past the count of failures.
+func (t *transactionPinService) AuthorizeTransfer(customerId string, walletId string, amount float64, stepUp req.StepUp) error {
+	lock := t.lockFor(customerId)
+	lock.Lock()
+	defer lock.Unlock()
+
+	transactionPin, err := t.getTransactionPin(customerId)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if lockedUntil, err := time.Parse(time.RFC3339, transactionPin.LockedUntil); err == nil && now.Before(lockedUntil) {
+		return &TransfersLockedError{RetryAfter: lockedUntil.Sub(now)}
+	}
+
+	if amount <= config.TransactionStepUpThreshold || stepUp.Authorized {
+		return nil
+	}
+
+	fields := logrus.Fields{
+		"customerId":   customerId,
+		"fromWalletId": walletId,
+		"amount":       amount,
+	}
+
+	switch {
+	case stepUp.TotpCode != "":
+		err := t.twoFactorService.VerifyCode(customerId, stepUp.TotpCode)
+		if err != nil && err.Error() == constants.TwoFactorCodeInvalidError {
+			logSecurityEvent(enums.SECURITY_STEP_UP_FAILED, fields)
+			return t.recordFailure(transactionPin, err)
+		}
+		if err != nil {
+			return err
+		}
+	case stepUp.Pin != "":
+		if transactionPin.PinHash == "" {
+			return errors.New(constants.TransactionPinNotSetError)
+		}
+		if !utils.VerifyPassword(stepUp.Pin, transactionPin.PinHash) {
+			logSecurityEvent(enums.SECURITY_TRANSACTION_PIN_FAILED, fields)
+			return t.recordFailure(transactionPin, errors.New(constants.TransactionPinIncorrectError))
+		}
+	default:
+		twoFactorEnabled, err := t.twoFactorService.IsEnabled(customerId)
+		if err != nil {
+			return err
+		}
+		if transactionPin.PinHash == "" && !twoFactorEnabled {
+			return errors.New(constants.TransactionPinNotSetError)
+		}
+		return errors.New(constants.TransactionStepUpRequiredError)
+	}
+
+	logger.LogInfo("Transfer step-up passed", fields)
+	if transactionPin.Failures == 0 && transactionPin.LockedUntil == "" {
+		return nil
+	}
+	transactionPin.Failures = 0
+	transactionPin.LockedUntil = ""
+	transactionPin.UpdatedAt = now.Format(time.RFC3339)
+	return t.transactionPinRepository.Save(transactionPin)
+}
+
+// recordFailure counts a failed step-up and locks transfers at the maximum, returning the error to refuse it with
+func (t *transactionPinService) recordFailure(transactionPin entity.TransactionPin, failure error) error {
+	now := time.Now()
+
+	// Failures from before an expired lock start over
+	if transactionPin.LockedUntil != "" {
+		transactionPin.Failures = 0
+		transactionPin.LockedUntil = ""
+	}
+	transactionPin.Failures++
+	transactionPin.UpdatedAt = now.Format(time.RFC3339)
+
+	locked := transactionPin.Failures >= config.TransactionPinMaxFailures
+	if locked {
+		transactionPin.LockedUntil = now.Add(config.TransactionPinLockoutDuration).Format(time.RFC3339)
+		logSecurityEvent(enums.SECURITY_TRANSFERS_LOCKED, logrus.Fields{
+			"customerId":  transactionPin.CustomerId,
+			"failures":    transactionPin.Failures,
+			"lockedUntil": transactionPin.LockedUntil,
+		})
+	}
+
+	if err := t.transactionPinRepository.Save(transactionPin); err != nil {
+		return err
+	}
+	if locked {
+		return &TransfersLockedError{RetryAfter: config.TransactionPinLockoutDuration}
+	}
+	return failure
+}
+
+// lockFor returns the mutex of the customer, creating it on first use
+func (t *transactionPinService) lockFor(customerId string) *sync.Mutex {
+	lock, _ := t.customerLocks.LoadOrStore(customerId, &sync.Mutex{})
+	return lock.(*sync.Mutex)
+}
+
+// getTransactionPin returns the transaction PIN record of the customer, or a new one without a PIN
+func (t *transactionPinService) getTransactionPin(customerId string) (entity.TransactionPin, error) {
+	transactionPin, err := t.transactionPinRepository.GetByCustomerId(customerId)
+	if err != nil {
+		if err.Error() == constants.TransactionPinNotFoundError {
+			return entity.TransactionPin{CustomerId: customerId}, nil
+		}
+		return entity.TransactionPin{}, err
+	}
+	return transactionPin, nil
+}
+
+// checkTransactionPin accepts exactly six digits that are not all the same or a run like 123456 or 987654
+func checkTransactionPin(pin string) error {
+	if len(pin) != 6 {
+		return errors.New(constants.TransactionPinFormatError)
+	}
+	for _, digit := range pin {
+		if digit < '0' || digit > '9' {
+			return errors.New(constants.TransactionPinFormatError)
+		}
+	}
+
+	repeated, ascending, descending := true, true, true
+	for i := 1; i < len(pin); i++ {
+		step := int(pin[i]) - int(pin[i-1])
+		repeated = repeated && step == 0
+		ascending = ascending && step == 1
+		descending = descending && step == -1
+	}
+	if repeated || ascending || descending {
+		return errors.New(constants.TransactionPinWeakError)
+	}
+	return nil
+}
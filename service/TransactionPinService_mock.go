@@ -0,0 +1,20 @@
+package service
+
+import (
+	req "PaymentAPI/dto/request"
+	"github.com/stretchr/testify/mock"
+)
+
+type TransactionPinServiceMock struct {
+	Mock mock.Mock
+}
+
+func (t *TransactionPinServiceMock) SetPin(customerId string, request req.SetTransactionPinRequest) error {
+	args := t.Mock.Called(customerId, request)
+	return args.Error(0)
+}
+
+func (t *TransactionPinServiceMock) AuthorizeTransfer(customerId string, walletId string, amount float64, stepUp req.StepUp) error {
+	args := t.Mock.Called(customerId, walletId, amount, stepUp)
+	return args.Error(0)
+}
@@ -0,0 +1,224 @@
+package service
+
+import (
+	"PaymentAPI/config"
+	"PaymentAPI/constants"
+	req "PaymentAPI/dto/request"
+	"PaymentAPI/entity"
+	"PaymentAPI/repository"
+	"PaymentAPI/utils"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+	"time"
+)
+
+func setTransactionPinConfig(t *testing.T) {
+	threshold, maxFailures, lockout := config.TransactionStepUpThreshold, config.TransactionPinMaxFailures, config.TransactionPinLockoutDuration
+	algorithm, cost := config.PasswordHashAlgorithm, config.BCryptCost
+	t.Cleanup(func() {
+		config.TransactionStepUpThreshold, config.TransactionPinMaxFailures, config.TransactionPinLockoutDuration = threshold, maxFailures, lockout
+		config.PasswordHashAlgorithm, config.BCryptCost = algorithm, cost
+	})
+
+	config.TransactionStepUpThreshold, config.TransactionPinMaxFailures, config.TransactionPinLockoutDuration = 1000000, 5, 30*time.Minute
+	config.PasswordHashAlgorithm, config.BCryptCost = "bcrypt", 4
+}
+
+func TestCheckTransactionPin(t *testing.T) {
+	tests := map[string]string{
+		"482915":  "",
+		"48291":   constants.TransactionPinFormatError,
+		"4829150": constants.TransactionPinFormatError,
+		"48a915":  constants.TransactionPinFormatError,
+		"777777":  constants.TransactionPinWeakError,
+		"123456":  constants.TransactionPinWeakError,
+		"987654":  constants.TransactionPinWeakError,
+	}
+
+	for pin, expected := range tests {
+		err := checkTransactionPin(pin)
+		if expected == "" {
+			assert.Nil(t, err, pin)
+		} else {
+			assert.Equal(t, expected, err.Error(), pin)
+		}
+	}
+}
+
+func TestSetTransactionPin(t *testing.T) {
+	setTransactionPinConfig(t)
+	hashedPassword, _ := utils.BCryptEncoder("password", 4)
+	customer := entity.Customer{Id: "id-1", Username: "johndoe", Password: hashedPassword}
+
+	t.Run("ShouldStoreHashedPin", func(t *testing.T) {
+		mockRepository := new(repository.TransactionPinRepositoryMock)
+		mockCustomerService := new(CustomerServiceMock)
+		mockCustomerService.Mock.On("GetCustomerByIdAuth", customer.Id).Return(customer, nil)
+		mockRepository.Mock.On("GetByCustomerId", customer.Id).Return(entity.TransactionPin{}, errors.New(constants.TransactionPinNotFoundError))
+		mockRepository.Mock.On("Save", mock.Anything).Return(nil)
+
+		err := NewTransactionPinService(mockRepository, mockCustomerService, newTwoFactorServiceMock()).
+			SetPin(customer.Id, req.SetTransactionPinRequest{Password: "password", Pin: "482915"})
+		assert.Nil(t, err)
+
+		stored := mockRepository.Mock.Calls[1].Arguments.Get(0).(entity.TransactionPin)
+		assert.Equal(t, customer.Id, stored.CustomerId)
+		assert.NotEqual(t, "482915", stored.PinHash)
+		assert.True(t, utils.VerifyPassword("482915", stored.PinHash))
+	})
+
+	t.Run("ShouldRequirePassword", func(t *testing.T) {
+		mockRepository := new(repository.TransactionPinRepositoryMock)
+		mockCustomerService := new(CustomerServiceMock)
+		mockCustomerService.Mock.On("GetCustomerByIdAuth", customer.Id).Return(customer, nil)
+
+		err := NewTransactionPinService(mockRepository, mockCustomerService, newTwoFactorServiceMock()).
+			SetPin(customer.Id, req.SetTransactionPinRequest{Password: "wrong", Pin: "482915"})
+		assert.Equal(t, constants.PasswordIncorrectError, err.Error())
+		mockRepository.Mock.AssertNotCalled(t, "Save", mock.Anything)
+	})
+
+	t.Run("ShouldRequireCodeWithTwoFactor", func(t *testing.T) {
+		mockRepository := new(repository.TransactionPinRepositoryMock)
+		mockCustomerService := new(CustomerServiceMock)
+		mockTwoFactorService := new(TwoFactorServiceMock)
+		mockCustomerService.Mock.On("GetCustomerByIdAuth", customer.Id).Return(customer, nil)
+		mockTwoFactorService.Mock.On("IsEnabled", customer.Id).Return(true, nil)
+		mockTwoFactorService.Mock.On("VerifyCode", customer.Id, "").Return(errors.New(constants.TwoFactorCodeInvalidError))
+
+		err := NewTransactionPinService(mockRepository, mockCustomerService, mockTwoFactorService).
+			SetPin(customer.Id, req.SetTransactionPinRequest{Password: "password", Pin: "482915"})
+		assert.Equal(t, constants.TwoFactorCodeInvalidError, err.Error())
+		mockRepository.Mock.AssertNotCalled(t, "Save", mock.Anything)
+	})
+}
+
+// newTransactionPinServiceMock returns a transaction PIN service that authorizes every transfer
+func newTransactionPinServiceMock() *TransactionPinServiceMock {
+	transactionPinService := new(TransactionPinServiceMock)
+	transactionPinService.Mock.On("AuthorizeTransfer", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	return transactionPinService
+}
+
+func TestAuthorizeTransfer(t *testing.T) {
+	setTransactionPinConfig(t)
+	pinHash, _ := utils.BCryptEncoder("482915", 4)
+	transfer := func(amount float64, pin string, totpCode string) (string, string, float64, req.StepUp) {
+		return "id-1", "wallet-1", amount, req.StepUp{Pin: pin, TotpCode: totpCode}
+	}
+
+	t.Run("ShouldAllowTransferAtThresholdWithoutPin", func(t *testing.T) {
+		mockRepository := new(repository.TransactionPinRepositoryMock)
+		mockRepository.Mock.On("GetByCustomerId", "id-1").Return(entity.TransactionPin{}, errors.New(constants.TransactionPinNotFoundError))
+
+		err := NewTransactionPinService(mockRepository, new(CustomerServiceMock), newTwoFactorServiceMock()).AuthorizeTransfer(transfer(1000000, "", ""))
+		assert.Nil(t, err)
+	})
+
+	t.Run("ShouldRequireStepUpAboveThreshold", func(t *testing.T) {
+		mockRepository := new(repository.TransactionPinRepositoryMock)
+		mockRepository.Mock.On("GetByCustomerId", "id-1").Return(entity.TransactionPin{CustomerId: "id-1", PinHash: pinHash}, nil)
+
+		err := NewTransactionPinService(mockRepository, new(CustomerServiceMock), newTwoFactorServiceMock()).AuthorizeTransfer(transfer(1000001, "", ""))
+		assert.Equal(t, constants.TransactionStepUpRequiredError, err.Error())
+	})
+
+	t.Run("ShouldAskToSetPinWithoutPinOrTwoFactor", func(t *testing.T) {
+		mockRepository := new(repository.TransactionPinRepositoryMock)
+		mockRepository.Mock.On("GetByCustomerId", "id-1").Return(entity.TransactionPin{}, errors.New(constants.TransactionPinNotFoundError))
+
+		err := NewTransactionPinService(mockRepository, new(CustomerServiceMock), newTwoFactorServiceMock()).AuthorizeTransfer(transfer(2000000, "", ""))
+		assert.Equal(t, constants.TransactionPinNotSetError, err.Error())
+	})
+
+	t.Run("ShouldAllowCorrectPinAndResetFailures", func(t *testing.T) {
+		mockRepository := new(repository.TransactionPinRepositoryMock)
+		mockRepository.Mock.On("GetByCustomerId", "id-1").Return(entity.TransactionPin{CustomerId: "id-1", PinHash: pinHash, Failures: 2}, nil)
+		mockRepository.Mock.On("Save", mock.Anything).Return(nil)
+
+		err := NewTransactionPinService(mockRepository, new(CustomerServiceMock), newTwoFactorServiceMock()).AuthorizeTransfer(transfer(2000000, "482915", ""))
+		assert.Nil(t, err)
+
+		stored := mockRepository.Mock.Calls[1].Arguments.Get(0).(entity.TransactionPin)
+		assert.Equal(t, 0, stored.Failures)
+	})
+
+	t.Run("ShouldCountWrongPin", func(t *testing.T) {
+		mockRepository := new(repository.TransactionPinRepositoryMock)
+		mockRepository.Mock.On("GetByCustomerId", "id-1").Return(entity.TransactionPin{CustomerId: "id-1", PinHash: pinHash, Failures: 1}, nil)
+		mockRepository.Mock.On("Save", mock.Anything).Return(nil)
+
+		err := NewTransactionPinService(mockRepository, new(CustomerServiceMock), newTwoFactorServiceMock()).AuthorizeTransfer(transfer(2000000, "000001", ""))
+		assert.Equal(t, constants.TransactionPinIncorrectError, err.Error())
+
+		stored := mockRepository.Mock.Calls[1].Arguments.Get(0).(entity.TransactionPin)
+		assert.Equal(t, 2, stored.Failures)
+		assert.Empty(t, stored.LockedUntil)
+	})
+
+	t.Run("ShouldLockTransfersAtMaxFailures", func(t *testing.T) {
+		mockRepository := new(repository.TransactionPinRepositoryMock)
+		mockTwoFactorService := new(TwoFactorServiceMock)
+		mockRepository.Mock.On("GetByCustomerId", "id-1").Return(entity.TransactionPin{CustomerId: "id-1", PinHash: pinHash, Failures: 4}, nil)
+		mockRepository.Mock.On("Save", mock.Anything).Return(nil)
+		mockTwoFactorService.Mock.On("VerifyCode", "id-1", "000000").Return(errors.New(constants.TwoFactorCodeInvalidError))
+
+		err := NewTransactionPinService(mockRepository, new(CustomerServiceMock), mockTwoFactorService).AuthorizeTransfer(transfer(2000000, "", "000000"))
+		var lockedErr *TransfersLockedError
+		assert.ErrorAs(t, err, &lockedErr)
+		assert.Equal(t, 30*time.Minute, lockedErr.RetryAfter)
+
+		stored := mockRepository.Mock.Calls[1].Arguments.Get(0).(entity.TransactionPin)
+		assert.Equal(t, 5, stored.Failures)
+		assert.NotEmpty(t, stored.LockedUntil)
+	})
+
+	t.Run("ShouldRefuseEveryTransferWhileLocked", func(t *testing.T) {
+		mockRepository := new(repository.TransactionPinRepositoryMock)
+		mockRepository.Mock.On("GetByCustomerId", "id-1").Return(entity.TransactionPin{
+			CustomerId:  "id-1",
+			PinHash:     pinHash,
+			Failures:    5,
+			LockedUntil: time.Now().Add(10 * time.Minute).Format(time.RFC3339),
+		}, nil)
+
+		err := NewTransactionPinService(mockRepository, new(CustomerServiceMock), newTwoFactorServiceMock()).AuthorizeTransfer(transfer(100, "482915", ""))
+		var lockedErr *TransfersLockedError
+		assert.ErrorAs(t, err, &lockedErr)
+		assert.InDelta(t, 10*time.Minute, lockedErr.RetryAfter, float64(time.Second))
+	})
+}
+
+func TestAuthorizeTransferLocksPerCustomer(t *testing.T) {
+	setTransactionPinConfig(t)
+	started, release := make(chan struct{}), make(chan struct{})
+	mockRepository := new(repository.TransactionPinRepositoryMock)
+	mockRepository.Mock.On("GetByCustomerId", "id-1").Run(func(args mock.Arguments) {
+		close(started)
+		<-release
+	}).Return(entity.TransactionPin{CustomerId: "id-1"}, nil)
+	mockRepository.Mock.On("GetByCustomerId", "id-2").Return(entity.TransactionPin{CustomerId: "id-2"}, nil)
+	transactionPinService := NewTransactionPinService(mockRepository, new(CustomerServiceMock), newTwoFactorServiceMock())
+
+	firstDone := make(chan error)
+	go func() {
+		firstDone <- transactionPinService.AuthorizeTransfer("id-1", "wallet-1", 100, req.StepUp{})
+	}()
+	<-started
+
+	secondDone := make(chan error)
+	go func() {
+		secondDone <- transactionPinService.AuthorizeTransfer("id-2", "wallet-2", 100, req.StepUp{})
+	}()
+	select {
+	case err := <-secondDone:
+		assert.Nil(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("transfer of another customer waited for the step-up in progress")
+	}
+
+	close(release)
+	assert.Nil(t, <-firstDone)
+}
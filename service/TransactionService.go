@@ -4,25 +4,46 @@ import (
 	"PaymentAPI/constants"
 	req "PaymentAPI/dto/request"
 	"PaymentAPI/entity"
+	"PaymentAPI/enums"
 	"PaymentAPI/repository"
+	"encoding/json"
 	"errors"
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus" // Import logrus for structured logging
+	"sync"
 	"time"
 )
 
 type TransactionService interface {
 	CreateNewTransaction(request req.CreateTransactionRequest) (entity.Transaction, error)
+	CreateDeposit(request req.CreateDepositRequest) (entity.Transaction, bool, error)
+	CreateWithdrawal(request req.CreateWithdrawalRequest) (entity.Transaction, error)
+	AuthorizeDebit(walletId string, amount float64, stepUp req.StepUp) error
+	CloseWallet(walletId string, request req.CloseWalletRequest) error
+	GetLedgerSnapshot() ([]entity.Wallet, []entity.Transaction, error)
+	WithLedgerLock(fn func() error) error
+	CheckEventCommitted(outboxEvent entity.OutboxEvent) (interface{}, bool, error)
 }
 
 type transactionService struct {
 	transactionRepository repository.TransactionRepository
 	walletService         WalletService
+	transactionPinService TransactionPinService
+	eventBus              EventBus
+
+	// ledgerLock serializes every change to the transaction log and the wallet balances, from the balance or
+	// duplicate check to the last balance update, so a snapshot read under it never sees a transaction half applied
+	ledgerLock sync.RWMutex
 }
 
 // NewTransactionService creates a new instance of TransactionService
-func NewTransactionService(transactionRepository repository.TransactionRepository, walletService WalletService) TransactionService {
-	return &transactionService{transactionRepository, walletService}
+func NewTransactionService(transactionRepository repository.TransactionRepository, walletService WalletService, transactionPinService TransactionPinService, eventBus EventBus) TransactionService {
+	return &transactionService{
+		transactionRepository: transactionRepository,
+		walletService:         walletService,
+		transactionPinService: transactionPinService,
+		eventBus:              eventBus,
+	}
 }
 
 // CreateNewTransaction creates a new transaction, transferring funds between wallets
@@ -35,6 +56,32 @@ func (t *transactionService) CreateNewTransaction(request req.CreateTransactionR
 
 	logger.Info("Starting to create a new transaction")
 
+	rejected := entity.RejectedTransaction{
+		FromWalletId: request.FromWalletId,
+		ToWalletId:   request.ToWalletId,
+		Amount:       request.Amount,
+		Message:      request.Message,
+	}
+
+	// Only positive amounts can be transferred
+	if request.Amount <= 0 {
+		logger.Error("Invalid transaction amount")
+		return entity.Transaction{}, t.reject(request.FromWalletId, rejected, errors.New(constants.TransactionInvalidAmountError))
+	}
+
+	if err := t.AuthorizeDebit(request.FromWalletId, request.Amount, request.StepUp); err != nil {
+		logger.Warn("Transfer step-up failed", err)
+		return entity.Transaction{}, err
+	}
+
+	t.ledgerLock.Lock()
+	defer t.ledgerLock.Unlock()
+
+	return t.transfer(request, rejected, logger)
+}
+
+// transfer moves funds between two wallets for a caller that holds the ledger lock and checked the step-up
+func (t *transactionService) transfer(request req.CreateTransactionRequest, rejected entity.RejectedTransaction, logger *logrus.Entry) (entity.Transaction, error) {
 	// Retrieve the 'from' wallet
 	fromWallet, err := t.walletService.GetWalletById(request.FromWalletId)
 	if err != nil {
@@ -42,18 +89,29 @@ func (t *transactionService) CreateNewTransaction(request req.CreateTransactionR
 		return entity.Transaction{}, err
 	}
 
-	// Check if the balance is sufficient for the transaction
-	if fromWallet.Balance-request.Amount < 0 {
-		logger.Error("Insufficient balance for transaction")
-		return entity.Transaction{}, errors.New(constants.TransactionInsufficientError)
-	}
-
 	// Retrieve the 'to' wallet
 	toWallet, err := t.walletService.GetWalletById(request.ToWalletId)
 	if err != nil {
 		logger.Error("Failed to retrieve 'to' wallet", err)
+		return entity.Transaction{}, t.reject(fromWallet.Id, rejected, err)
+	}
+
+	// Both wallets must be active to move funds
+	if err := checkWalletStatus(fromWallet, toWallet); err != nil {
+		logger.Error("Wallet is not active", err)
+		return entity.Transaction{}, t.reject(fromWallet.Id, rejected, err)
+	}
+
+	// Check if the balance not reserved by holds is sufficient for the transaction
+	availableBalance, err := t.walletService.GetAvailableBalance(fromWallet.Id)
+	if err != nil {
+		logger.Error("Failed to calculate available balance", err)
 		return entity.Transaction{}, err
 	}
+	if availableBalance-request.Amount < 0 {
+		logger.Error("Insufficient balance for transaction")
+		return entity.Transaction{}, t.reject(fromWallet.Id, rejected, errors.New(constants.TransactionInsufficientError))
+	}
 
 	// Prepare the transaction entity
 	transaction := entity.Transaction{
@@ -65,10 +123,18 @@ func (t *transactionService) CreateNewTransaction(request req.CreateTransactionR
 		Message:      request.Message,
 	}
 
+	// Record the events before the transaction, so a transfer is never stored without them
+	outboxEvents, err := t.prepareSettled([]string{fromWallet.Id, toWallet.Id}, transaction)
+	if err != nil {
+		logger.Error("Failed to record transaction events", err)
+		return entity.Transaction{}, err
+	}
+
 	// Create the transaction record in the repository
-	err = t.transactionRepository.Create(transaction)
+	transaction, err = t.transactionRepository.Create(transaction)
 	if err != nil {
 		logger.Error("Failed to create transaction in the repository", err)
+		t.eventBus.Discard(outboxEvents)
 		return entity.Transaction{}, err
 	}
 
@@ -86,6 +152,345 @@ func (t *transactionService) CreateNewTransaction(request req.CreateTransactionR
 		return entity.Transaction{}, err
 	}
 
+	t.eventBus.Commit(outboxEvents, transaction)
+
 	logger.Info("Transaction successfully created")
 	return transaction, nil
 }
+
+// CreateDeposit credits a wallet with funds received from an external account, such as a bank. The external ID makes it
+// idempotent per account, a deposit that was already credited returns the existing transaction and false instead of crediting again.
+func (t *transactionService) CreateDeposit(request req.CreateDepositRequest) (entity.Transaction, bool, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"toWalletId": request.ToWalletId,
+		"amount":     request.Amount,
+		"externalId": request.ExternalId,
+	})
+
+	logger.Info("Starting to create a new deposit")
+
+	if request.Amount <= 0 {
+		logger.Error("Invalid deposit amount")
+		return entity.Transaction{}, false, errors.New(constants.TransactionInvalidAmountError)
+	}
+
+	t.ledgerLock.Lock()
+	defer t.ledgerLock.Unlock()
+
+	transactions, err := t.transactionRepository.GetAll()
+	if err != nil {
+		logger.Error("Failed to retrieve transactions", err)
+		return entity.Transaction{}, false, err
+	}
+	for _, transaction := range transactions {
+		if transaction.FromWalletId == request.FromAccount && transaction.ExternalId == request.ExternalId {
+			logger.Info("Deposit was already credited")
+			return transaction, false, nil
+		}
+	}
+
+	// The receiving wallet must be able to receive funds, the same rule as a transfer. A refund only gives back what
+	// was debited, so a frozen wallet still receives it.
+	toWallet, err := t.walletService.GetWalletById(request.ToWalletId)
+	if err != nil {
+		logger.Error("Failed to retrieve 'to' wallet", err)
+		return entity.Transaction{}, false, err
+	}
+	if err := checkWalletStatus(entity.Wallet{}, toWallet); err != nil && !(request.Refund && toWallet.Status == enums.WALLET_FROZEN) {
+		logger.Error("Wallet is not active", err)
+		return entity.Transaction{}, false, t.reject(toWallet.Id, entity.RejectedTransaction{
+			FromWalletId: request.FromAccount,
+			ToWalletId:   toWallet.Id,
+			Amount:       request.Amount,
+			Message:      request.Message,
+		}, err)
+	}
+
+	transaction := entity.Transaction{
+		Id:           uuid.New().String(),
+		FromWalletId: request.FromAccount,
+		ToWalletId:   toWallet.Id,
+		CreatedAt:    time.Now().Format(time.RFC3339),
+		Amount:       request.Amount,
+		Message:      request.Message,
+		ExternalId:   request.ExternalId,
+	}
+
+	outboxEvents, err := t.prepareSettled([]string{toWallet.Id}, transaction)
+	if err != nil {
+		logger.Error("Failed to record deposit events", err)
+		return entity.Transaction{}, false, err
+	}
+
+	transaction, err = t.transactionRepository.Create(transaction)
+	if err != nil {
+		logger.Error("Failed to create deposit in the repository", err)
+		t.eventBus.Discard(outboxEvents)
+		return entity.Transaction{}, false, err
+	}
+
+	if err := t.walletService.UpdateWallet(toWallet.Id, request.Amount); err != nil {
+		logger.Error("Failed to update 'to' wallet balance", err)
+		return entity.Transaction{}, false, err
+	}
+
+	t.eventBus.Commit(outboxEvents, transaction)
+
+	logger.Info("Deposit successfully created")
+	return transaction, true, nil
+}
+
+// CreateWithdrawal debits a wallet with funds sent to an external account, such as a biller. The external account
+// is not a wallet of this system, so only the sender balance changes.
+func (t *transactionService) CreateWithdrawal(request req.CreateWithdrawalRequest) (entity.Transaction, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"fromWalletId": request.FromWalletId,
+		"toAccount":    request.ToAccount,
+		"amount":       request.Amount,
+	})
+
+	logger.Info("Starting to create a new withdrawal")
+
+	rejected := entity.RejectedTransaction{
+		FromWalletId: request.FromWalletId,
+		ToWalletId:   request.ToAccount,
+		Amount:       request.Amount,
+		Message:      request.Message,
+	}
+
+	if request.Amount <= 0 {
+		logger.Error("Invalid withdrawal amount")
+		return entity.Transaction{}, t.reject(request.FromWalletId, rejected, errors.New(constants.TransactionInvalidAmountError))
+	}
+
+	if err := t.AuthorizeDebit(request.FromWalletId, request.Amount, request.StepUp); err != nil {
+		logger.Warn("Withdrawal step-up failed", err)
+		return entity.Transaction{}, err
+	}
+
+	t.ledgerLock.Lock()
+	defer t.ledgerLock.Unlock()
+
+	fromWallet, err := t.walletService.GetWalletById(request.FromWalletId)
+	if err != nil {
+		logger.Error("Failed to retrieve 'from' wallet", err)
+		return entity.Transaction{}, err
+	}
+	if err := checkWalletStatus(fromWallet, entity.Wallet{}); err != nil {
+		logger.Error("Wallet is not active", err)
+		return entity.Transaction{}, t.reject(fromWallet.Id, rejected, err)
+	}
+
+	availableBalance, err := t.walletService.GetAvailableBalance(fromWallet.Id)
+	if err != nil {
+		logger.Error("Failed to calculate available balance", err)
+		return entity.Transaction{}, err
+	}
+	if availableBalance-request.Amount < 0 {
+		logger.Error("Insufficient balance for withdrawal")
+		return entity.Transaction{}, t.reject(fromWallet.Id, rejected, errors.New(constants.TransactionInsufficientError))
+	}
+
+	transaction := entity.Transaction{
+		Id:           uuid.New().String(),
+		FromWalletId: fromWallet.Id,
+		ToWalletId:   request.ToAccount,
+		CreatedAt:    time.Now().Format(time.RFC3339),
+		Amount:       request.Amount,
+		Message:      request.Message,
+		ExternalId:   request.ExternalId,
+	}
+
+	outboxEvents, err := t.prepareSettled([]string{fromWallet.Id}, transaction)
+	if err != nil {
+		logger.Error("Failed to record withdrawal events", err)
+		return entity.Transaction{}, err
+	}
+
+	transaction, err = t.transactionRepository.Create(transaction)
+	if err != nil {
+		logger.Error("Failed to create withdrawal in the repository", err)
+		t.eventBus.Discard(outboxEvents)
+		return entity.Transaction{}, err
+	}
+
+	if err := t.walletService.UpdateWallet(fromWallet.Id, request.Amount*-1); err != nil {
+		logger.Error("Failed to update 'from' wallet balance", err)
+		return entity.Transaction{}, err
+	}
+
+	t.eventBus.Commit(outboxEvents, transaction)
+
+	logger.Info("Withdrawal successfully created")
+	return transaction, nil
+}
+
+// AuthorizeDebit checks the step-up of a debit with the owner of the wallet. Every transfer and withdrawal is checked
+// here, whichever endpoint it came from, so an access token alone cannot move large amounts and a lock on transfers
+// stops them all. An operation made of several debits, such as a payout batch, is authorized once for its total and
+// marks its debits as authorized. It runs before the ledger lock, so verifying a PIN does not hold up other transfers.
+func (t *transactionService) AuthorizeDebit(walletId string, amount float64, stepUp req.StepUp) error {
+	wallet, err := t.walletService.GetWalletById(walletId)
+	if err != nil {
+		return err
+	}
+	return t.transactionPinService.AuthorizeTransfer(wallet.CustomerId, wallet.Id, amount, stepUp)
+}
+
+// CloseWallet pays out the balance of a wallet to the payout wallet of the request and closes it. Active holds are
+// refused before anything is paid out, so a wallet is never left drained but open. The checks, the payout and the
+// status change run under the ledger lock, so no credit lands on the wallet once its balance was read.
+func (t *transactionService) CloseWallet(walletId string, request req.CloseWalletRequest) error {
+	logger := logrus.WithFields(logrus.Fields{
+		"walletId":       walletId,
+		"payoutWalletId": request.PayoutWalletId,
+	})
+
+	logger.Info("Starting to close wallet")
+
+	// The balance is stepped up before the lock, a credit landing meanwhile is paid out to the same payout wallet
+	wallet, err := t.walletService.GetWalletById(walletId)
+	if err != nil {
+		logger.Error("Failed to retrieve wallet", err)
+		return err
+	}
+	if request.PayoutWalletId != "" && wallet.Balance > 0 {
+		if request.PayoutWalletId == wallet.Id {
+			return errors.New(constants.WalletPayoutSameWalletError)
+		}
+		if err := t.AuthorizeDebit(wallet.Id, wallet.Balance, request.StepUp); err != nil {
+			logger.Warn("Payout step-up failed", err)
+			return err
+		}
+	}
+
+	t.ledgerLock.Lock()
+	defer t.ledgerLock.Unlock()
+
+	wallet, err = t.walletService.GetWalletById(walletId)
+	if err != nil {
+		logger.Error("Failed to retrieve wallet", err)
+		return err
+	}
+
+	availableBalance, err := t.walletService.GetAvailableBalance(wallet.Id)
+	if err != nil {
+		logger.Error("Failed to calculate available balance", err)
+		return err
+	}
+	if availableBalance != wallet.Balance {
+		logger.Warn("Wallet still has active holds")
+		return errors.New(constants.WalletCloseActiveHoldsError)
+	}
+
+	if request.PayoutWalletId != "" && wallet.Balance > 0 {
+		payout := req.CreateTransactionRequest{
+			FromWalletId: wallet.Id,
+			ToWalletId:   request.PayoutWalletId,
+			Amount:       wallet.Balance,
+			Message:      "Payout before closing wallet",
+		}
+		rejected := entity.RejectedTransaction{
+			FromWalletId: payout.FromWalletId,
+			ToWalletId:   payout.ToWalletId,
+			Amount:       payout.Amount,
+			Message:      payout.Message,
+		}
+		if _, err := t.transfer(payout, rejected, logger); err != nil {
+			logger.Error("Failed to pay out wallet", err)
+			return err
+		}
+	}
+
+	return t.walletService.CloseWallet(wallet.Id)
+}
+
+// GetLedgerSnapshot returns every wallet and every transaction as of the same moment, with no transaction in flight
+func (t *transactionService) GetLedgerSnapshot() ([]entity.Wallet, []entity.Transaction, error) {
+	logger := logrus.WithFields(logrus.Fields{})
+
+	t.ledgerLock.RLock()
+	defer t.ledgerLock.RUnlock()
+
+	wallets, err := t.walletService.GetAllWallets()
+	if err != nil {
+		logger.Error("Failed to retrieve wallets for ledger snapshot", err)
+		return nil, nil, err
+	}
+
+	transactions, err := t.transactionRepository.GetAll()
+	if err != nil {
+		logger.Error("Failed to retrieve transactions for ledger snapshot", err)
+		return nil, nil, err
+	}
+
+	return wallets, transactions, nil
+}
+
+// WithLedgerLock runs fn holding the ledger lock, for a change outside the transaction log that must be checked
+// against the balances, such as a hold reserving funds. No transfer runs meanwhile, so fn must not start one.
+func (t *transactionService) WithLedgerLock(fn func() error) error {
+	t.ledgerLock.Lock()
+	defer t.ledgerLock.Unlock()
+
+	return fn()
+}
+
+// CheckEventCommitted finds the stored transaction of a prepared transaction event its operation did not commit. The
+// ledger lock is taken first, so an operation still in flight has finished.
+func (t *transactionService) CheckEventCommitted(outboxEvent entity.OutboxEvent) (interface{}, bool, error) {
+	var prepared entity.Transaction
+	if err := json.Unmarshal(outboxEvent.Payload, &prepared); err != nil {
+		return nil, false, err
+	}
+
+	t.ledgerLock.RLock()
+	defer t.ledgerLock.RUnlock()
+
+	transactions, err := t.transactionRepository.GetAll()
+	if err != nil {
+		return nil, false, err
+	}
+	for _, transaction := range transactions {
+		if transaction.Id == prepared.Id {
+			return transaction, true, nil
+		}
+	}
+	return nil, false, nil
+}
+
+// prepareSettled records the created and settled events of a transaction before it is stored, while the ledger lock
+// is held. The caller fails the transaction when they cannot be recorded, and commits them once every balance is
+// updated. From then on the transaction is done, a failure to commit is left to the dispatcher.
+func (t *transactionService) prepareSettled(walletIds []string, transaction entity.Transaction) ([]entity.OutboxEvent, error) {
+	return t.eventBus.Prepare([]enums.EventType{enums.EVENT_TRANSACTION_CREATED, enums.EVENT_TRANSACTION_SETTLED}, walletIds, transaction)
+}
+
+// reject tells the owner of the wallet that a transaction was refused and returns the reason unchanged. The
+// transaction already failed, so an event that cannot be recorded is only logged.
+func (t *transactionService) reject(walletId string, rejected entity.RejectedTransaction, err error) error {
+	rejected.Reason = err.Error()
+	if publishErr := t.eventBus.Publish(enums.EVENT_TRANSACTION_REJECTED, []string{walletId}, rejected); publishErr != nil {
+		logrus.WithFields(logrus.Fields{"walletId": walletId}).Error("Failed to record rejected transaction event", publishErr)
+	}
+	return err
+}
+
+// checkWalletStatus returns a distinct error when the sender or receiver wallet is frozen or closed
+func checkWalletStatus(fromWallet entity.Wallet, toWallet entity.Wallet) error {
+	switch fromWallet.Status {
+	case enums.WALLET_FROZEN:
+		return errors.New(constants.WalletSenderFrozenError)
+	case enums.WALLET_CLOSED:
+		return errors.New(constants.WalletSenderClosedError)
+	}
+
+	switch toWallet.Status {
+	case enums.WALLET_FROZEN:
+		return errors.New(constants.WalletReceiverFrozenError)
+	case enums.WALLET_CLOSED:
+		return errors.New(constants.WalletReceiverClosedError)
+	}
+
+	return nil
+}
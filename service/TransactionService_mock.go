@@ -0,0 +1,56 @@
+package service
+
+import (
+	req "PaymentAPI/dto/request"
+	"PaymentAPI/entity"
+	"fmt"
+	"github.com/stretchr/testify/mock"
+)
+
+type TransactionServiceMock struct {
+	mock.Mock
+}
+
+func (t *TransactionServiceMock) CreateNewTransaction(request req.CreateTransactionRequest) (entity.Transaction, error) {
+	args := t.Called(request)
+
+	transaction, ok := args.Get(0).(entity.Transaction)
+	if !ok {
+		return entity.Transaction{}, fmt.Errorf("invalid type for transaction")
+	}
+	return transaction, args.Error(1)
+}
+
+func (t *TransactionServiceMock) CreateDeposit(request req.CreateDepositRequest) (entity.Transaction, bool, error) {
+	args := t.Called(request)
+	return args.Get(0).(entity.Transaction), args.Bool(1), args.Error(2)
+}
+
+func (t *TransactionServiceMock) CreateWithdrawal(request req.CreateWithdrawalRequest) (entity.Transaction, error) {
+	args := t.Called(request)
+	return args.Get(0).(entity.Transaction), args.Error(1)
+}
+
+func (t *TransactionServiceMock) AuthorizeDebit(walletId string, amount float64, stepUp req.StepUp) error {
+	args := t.Called(walletId, amount, stepUp)
+	return args.Error(0)
+}
+
+func (t *TransactionServiceMock) GetLedgerSnapshot() ([]entity.Wallet, []entity.Transaction, error) {
+	args := t.Called()
+	return args.Get(0).([]entity.Wallet), args.Get(1).([]entity.Transaction), args.Error(2)
+}
+
+func (t *TransactionServiceMock) CheckEventCommitted(outboxEvent entity.OutboxEvent) (interface{}, bool, error) {
+	args := t.Called(outboxEvent)
+	return args.Get(0), args.Bool(1), args.Error(2)
+}
+
+func (t *TransactionServiceMock) WithLedgerLock(fn func() error) error {
+	return fn()
+}
+
+func (t *TransactionServiceMock) CloseWallet(walletId string, request req.CloseWalletRequest) error {
+	args := t.Called(walletId, request)
+	return args.Error(0)
+}
@@ -0,0 +1,444 @@
+package service
+
+import (
+	"PaymentAPI/constants"
+	req "PaymentAPI/dto/request"
+	"PaymentAPI/entity"
+	"PaymentAPI/enums"
+	"PaymentAPI/repository"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+	"time"
+)
+
+func TestCreateNewTransaction(t *testing.T) {
+	request := req.CreateTransactionRequest{
+		FromWalletId: "wallet-1",
+		ToWalletId:   "wallet-2",
+		Amount:       5000,
+		Message:      "Beli pulsa",
+	}
+
+	tests := []struct {
+		name             string
+		fromWallet       entity.Wallet
+		toWallet         entity.Wallet
+		availableBalance float64
+		expectedError    string
+	}{
+		{
+			name:             "Should Create Transaction",
+			fromWallet:       entity.Wallet{Id: "wallet-1", Balance: 10000, Status: enums.WALLET_ACTIVE},
+			toWallet:         entity.Wallet{Id: "wallet-2", Status: enums.WALLET_ACTIVE},
+			availableBalance: 10000,
+		},
+		{
+			name:             "Should Fail On Insufficient Balance",
+			fromWallet:       entity.Wallet{Id: "wallet-1", Balance: 1000, Status: enums.WALLET_ACTIVE},
+			toWallet:         entity.Wallet{Id: "wallet-2", Status: enums.WALLET_ACTIVE},
+			availableBalance: 1000,
+			expectedError:    constants.TransactionInsufficientError,
+		},
+		{
+			name:             "Should Fail When Balance Is Reserved By Holds",
+			fromWallet:       entity.Wallet{Id: "wallet-1", Balance: 10000, Status: enums.WALLET_ACTIVE},
+			toWallet:         entity.Wallet{Id: "wallet-2", Status: enums.WALLET_ACTIVE},
+			availableBalance: 4000,
+			expectedError:    constants.TransactionInsufficientError,
+		},
+		{
+			name:          "Should Fail When Sender Is Frozen",
+			fromWallet:    entity.Wallet{Id: "wallet-1", Balance: 10000, Status: enums.WALLET_FROZEN},
+			toWallet:      entity.Wallet{Id: "wallet-2", Status: enums.WALLET_ACTIVE},
+			expectedError: constants.WalletSenderFrozenError,
+		},
+		{
+			name:          "Should Fail When Sender Is Closed",
+			fromWallet:    entity.Wallet{Id: "wallet-1", Status: enums.WALLET_CLOSED},
+			toWallet:      entity.Wallet{Id: "wallet-2", Status: enums.WALLET_ACTIVE},
+			expectedError: constants.WalletSenderClosedError,
+		},
+		{
+			name:          "Should Fail When Receiver Is Frozen",
+			fromWallet:    entity.Wallet{Id: "wallet-1", Balance: 10000, Status: enums.WALLET_ACTIVE},
+			toWallet:      entity.Wallet{Id: "wallet-2", Status: enums.WALLET_FROZEN},
+			expectedError: constants.WalletReceiverFrozenError,
+		},
+		{
+			name:          "Should Fail When Receiver Is Closed",
+			fromWallet:    entity.Wallet{Id: "wallet-1", Balance: 10000, Status: enums.WALLET_ACTIVE},
+			toWallet:      entity.Wallet{Id: "wallet-2", Status: enums.WALLET_CLOSED},
+			expectedError: constants.WalletReceiverClosedError,
+		},
+	}
+
+	t.Run("Should Fail On Non Positive Amount", func(t *testing.T) {
+		transactionService := NewTransactionService(new(repository.TransactionRepositoryMock), new(WalletServiceMock), newTransactionPinServiceMock(), newEventBusMock())
+
+		transaction, err := transactionService.CreateNewTransaction(req.CreateTransactionRequest{
+			FromWalletId: "wallet-1",
+			ToWalletId:   "wallet-2",
+			Amount:       -5000,
+		})
+		assert.Equal(t, constants.TransactionInvalidAmountError, err.Error())
+		assert.Equal(t, entity.Transaction{}, transaction)
+	})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockTransactionRepository := new(repository.TransactionRepositoryMock)
+			mockWalletService := new(WalletServiceMock)
+			mockEventBus := newEventBusMock()
+			transactionService := NewTransactionService(mockTransactionRepository, mockWalletService, newTransactionPinServiceMock(), mockEventBus)
+
+			mockWalletService.On("GetWalletById", "wallet-1").Return(tt.fromWallet, nil)
+			mockWalletService.On("GetWalletById", "wallet-2").Return(tt.toWallet, nil)
+			mockWalletService.On("GetAvailableBalance", "wallet-1").Return(tt.availableBalance, nil)
+			mockTransactionRepository.Mock.On("Create", mock.Anything).
+				Return(entity.Transaction{Id: "transaction-1", FromWalletId: "wallet-1", ToWalletId: "wallet-2", Amount: request.Amount}, nil)
+			mockWalletService.On("UpdateWallet", "wallet-1", -request.Amount).Return(nil)
+			mockWalletService.On("UpdateWallet", "wallet-2", request.Amount).Return(nil)
+
+			transaction, err := transactionService.CreateNewTransaction(request)
+			if tt.expectedError != "" {
+				assert.Equal(t, tt.expectedError, err.Error())
+				assert.Equal(t, entity.Transaction{}, transaction)
+				mockTransactionRepository.Mock.AssertNotCalled(t, "Create", mock.Anything)
+				mockWalletService.AssertNotCalled(t, "UpdateWallet", mock.Anything, mock.Anything)
+				mockEventBus.AssertCalled(t, "Publish", enums.EVENT_TRANSACTION_REJECTED, []string{"wallet-1"}, mock.Anything)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, "transaction-1", transaction.Id)
+				mockWalletService.AssertExpectations(t)
+				mockEventBus.AssertCalled(t, "Prepare", []enums.EventType{enums.EVENT_TRANSACTION_CREATED, enums.EVENT_TRANSACTION_SETTLED}, []string{"wallet-1", "wallet-2"}, mock.Anything)
+				mockEventBus.AssertCalled(t, "Commit", mock.Anything, transaction)
+			}
+		})
+	}
+}
+
+func TestCreateDeposit(t *testing.T) {
+	request := req.CreateDepositRequest{FromAccount: externalBankSource, ToWalletId: "wallet-1", Amount: 50000, ExternalId: "bank-ref-1"}
+
+	t.Run("ShouldCreditWallet", func(t *testing.T) {
+		mockTransactionRepository := new(repository.TransactionRepositoryMock)
+		mockWalletService := new(WalletServiceMock)
+		mockEventBus := newEventBusMock()
+		transactionService := NewTransactionService(mockTransactionRepository, mockWalletService, newTransactionPinServiceMock(), mockEventBus)
+
+		mockTransactionRepository.Mock.On("GetAll").Return([]entity.Transaction{}, nil)
+		mockWalletService.On("GetWalletById", "wallet-1").Return(entity.Wallet{Id: "wallet-1", Status: enums.WALLET_ACTIVE}, nil)
+		mockTransactionRepository.Mock.On("Create", mock.Anything).Return(entity.Transaction{Id: "transaction-1"}, nil)
+		mockWalletService.On("UpdateWallet", "wallet-1", float64(50000)).Return(nil)
+
+		transaction, created, err := transactionService.CreateDeposit(request)
+		assert.Nil(t, err)
+		assert.True(t, created)
+		assert.Equal(t, "transaction-1", transaction.Id)
+		mockWalletService.AssertCalled(t, "UpdateWallet", "wallet-1", float64(50000))
+	})
+
+	t.Run("ShouldNotCreditTwice", func(t *testing.T) {
+		mockTransactionRepository := new(repository.TransactionRepositoryMock)
+		mockWalletService := new(WalletServiceMock)
+		mockEventBus := newEventBusMock()
+		transactionService := NewTransactionService(mockTransactionRepository, mockWalletService, newTransactionPinServiceMock(), mockEventBus)
+
+		existing := entity.Transaction{Id: "transaction-1", FromWalletId: externalBankSource, ToWalletId: "wallet-1", ExternalId: "bank-ref-1"}
+		mockTransactionRepository.Mock.On("GetAll").Return([]entity.Transaction{existing}, nil)
+
+		transaction, created, err := transactionService.CreateDeposit(request)
+		assert.Nil(t, err)
+		assert.False(t, created)
+		assert.Equal(t, existing, transaction)
+		mockWalletService.AssertNotCalled(t, "UpdateWallet", mock.Anything, mock.Anything)
+	})
+
+	t.Run("ShouldFailBeforeCreditingWhenEventsCannotBeRecorded", func(t *testing.T) {
+		mockTransactionRepository := new(repository.TransactionRepositoryMock)
+		mockWalletService := new(WalletServiceMock)
+		mockEventBus := new(EventBusMock)
+		transactionService := NewTransactionService(mockTransactionRepository, mockWalletService, newTransactionPinServiceMock(), mockEventBus)
+
+		mockTransactionRepository.Mock.On("GetAll").Return([]entity.Transaction{}, nil)
+		mockWalletService.On("GetWalletById", "wallet-1").Return(entity.Wallet{Id: "wallet-1", Status: enums.WALLET_ACTIVE}, nil)
+		mockEventBus.On("Prepare", mock.Anything, []string{"wallet-1"}, mock.Anything).Return(nil, errors.New("disk full"))
+
+		_, created, err := transactionService.CreateDeposit(request)
+		assert.Equal(t, "disk full", err.Error())
+		assert.False(t, created)
+		mockTransactionRepository.Mock.AssertNotCalled(t, "Create", mock.Anything)
+		mockWalletService.AssertNotCalled(t, "UpdateWallet", mock.Anything, mock.Anything)
+	})
+
+	t.Run("ShouldSucceedWhenEventsCannotBeCommitted", func(t *testing.T) {
+		mockTransactionRepository := new(repository.TransactionRepositoryMock)
+		mockWalletService := new(WalletServiceMock)
+		mockEventBus := newEventBusMock()
+		transactionService := NewTransactionService(mockTransactionRepository, mockWalletService, newTransactionPinServiceMock(), mockEventBus)
+
+		mockTransactionRepository.Mock.On("GetAll").Return([]entity.Transaction{}, nil)
+		mockWalletService.On("GetWalletById", "wallet-1").Return(entity.Wallet{Id: "wallet-1", Status: enums.WALLET_ACTIVE}, nil)
+		mockTransactionRepository.Mock.On("Create", mock.Anything).Return(entity.Transaction{Id: "transaction-1"}, nil)
+		mockWalletService.On("UpdateWallet", "wallet-1", float64(50000)).Return(nil)
+
+		// Commit only logs its failures, the funds moved so the deposit is reported as credited
+		transaction, created, err := transactionService.CreateDeposit(request)
+		assert.Nil(t, err)
+		assert.True(t, created)
+		assert.Equal(t, "transaction-1", transaction.Id)
+		mockEventBus.AssertCalled(t, "Commit", mock.Anything, transaction)
+	})
+
+	t.Run("ShouldDiscardEventsWhenDepositIsNotStored", func(t *testing.T) {
+		mockTransactionRepository := new(repository.TransactionRepositoryMock)
+		mockWalletService := new(WalletServiceMock)
+		mockEventBus := newEventBusMock()
+		transactionService := NewTransactionService(mockTransactionRepository, mockWalletService, newTransactionPinServiceMock(), mockEventBus)
+
+		mockTransactionRepository.Mock.On("GetAll").Return([]entity.Transaction{}, nil)
+		mockWalletService.On("GetWalletById", "wallet-1").Return(entity.Wallet{Id: "wallet-1", Status: enums.WALLET_ACTIVE}, nil)
+		mockTransactionRepository.Mock.On("Create", mock.Anything).Return(entity.Transaction{}, errors.New(constants.JsonFileNotFound))
+
+		_, _, err := transactionService.CreateDeposit(request)
+		assert.Equal(t, constants.JsonFileNotFound, err.Error())
+		mockEventBus.AssertCalled(t, "Discard", mock.Anything)
+		mockEventBus.AssertNotCalled(t, "Commit", mock.Anything, mock.Anything)
+	})
+
+	t.Run("ShouldCreditRefundToFrozenWallet", func(t *testing.T) {
+		mockTransactionRepository := new(repository.TransactionRepositoryMock)
+		mockWalletService := new(WalletServiceMock)
+		mockEventBus := newEventBusMock()
+		transactionService := NewTransactionService(mockTransactionRepository, mockWalletService, newTransactionPinServiceMock(), mockEventBus)
+
+		mockTransactionRepository.Mock.On("GetAll").Return([]entity.Transaction{}, nil)
+		mockWalletService.On("GetWalletById", "wallet-1").Return(entity.Wallet{Id: "wallet-1", Status: enums.WALLET_FROZEN}, nil)
+		mockTransactionRepository.Mock.On("Create", mock.Anything).Return(entity.Transaction{Id: "transaction-1"}, nil)
+		mockWalletService.On("UpdateWallet", "wallet-1", float64(50000)).Return(nil)
+
+		// A deposit is refused while the wallet is frozen, a refund of what it paid is not
+		_, _, err := transactionService.CreateDeposit(request)
+		assert.Equal(t, constants.WalletReceiverFrozenError, err.Error())
+
+		refund := request
+		refund.Refund = true
+		_, created, err := transactionService.CreateDeposit(refund)
+		assert.Nil(t, err)
+		assert.True(t, created)
+		mockWalletService.AssertCalled(t, "UpdateWallet", "wallet-1", float64(50000))
+	})
+}
+
+func TestCreateWithdrawal(t *testing.T) {
+	request := req.CreateWithdrawalRequest{FromWalletId: "wallet-1", ToAccount: "BILLER_PLN", Amount: 22500, ExternalId: "bill-1"}
+
+	t.Run("ShouldDebitOnlySender", func(t *testing.T) {
+		mockTransactionRepository := new(repository.TransactionRepositoryMock)
+		mockWalletService := new(WalletServiceMock)
+		mockEventBus := newEventBusMock()
+		transactionService := NewTransactionService(mockTransactionRepository, mockWalletService, newTransactionPinServiceMock(), mockEventBus)
+
+		mockWalletService.On("GetWalletById", "wallet-1").Return(entity.Wallet{Id: "wallet-1", Balance: 50000, Status: enums.WALLET_ACTIVE}, nil)
+		mockWalletService.On("GetAvailableBalance", "wallet-1").Return(float64(50000), nil)
+		mockTransactionRepository.Mock.On("Create", mock.MatchedBy(func(transaction entity.Transaction) bool {
+			return transaction.ToWalletId == "BILLER_PLN" && transaction.ExternalId == "bill-1"
+		})).Return(entity.Transaction{Id: "transaction-1"}, nil)
+		mockWalletService.On("UpdateWallet", "wallet-1", float64(-22500)).Return(nil)
+
+		transaction, err := transactionService.CreateWithdrawal(request)
+		assert.Nil(t, err)
+		assert.Equal(t, "transaction-1", transaction.Id)
+		mockWalletService.AssertNumberOfCalls(t, "UpdateWallet", 1)
+	})
+
+	t.Run("ShouldFailOnInsufficientBalance", func(t *testing.T) {
+		mockTransactionRepository := new(repository.TransactionRepositoryMock)
+		mockWalletService := new(WalletServiceMock)
+		mockEventBus := newEventBusMock()
+		transactionService := NewTransactionService(mockTransactionRepository, mockWalletService, newTransactionPinServiceMock(), mockEventBus)
+
+		mockWalletService.On("GetWalletById", "wallet-1").Return(entity.Wallet{Id: "wallet-1", Balance: 50000, Status: enums.WALLET_ACTIVE}, nil)
+		mockWalletService.On("GetAvailableBalance", "wallet-1").Return(float64(20000), nil)
+
+		_, err := transactionService.CreateWithdrawal(request)
+		assert.Equal(t, constants.TransactionInsufficientError, err.Error())
+		mockTransactionRepository.Mock.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	t.Run("ShouldStepUpWithWalletOwner", func(t *testing.T) {
+		mockTransactionRepository := new(repository.TransactionRepositoryMock)
+		mockWalletService := new(WalletServiceMock)
+		mockTransactionPinService := new(TransactionPinServiceMock)
+		transactionService := NewTransactionService(mockTransactionRepository, mockWalletService, mockTransactionPinService, newEventBusMock())
+
+		mockWalletService.On("GetWalletById", "wallet-1").Return(entity.Wallet{Id: "wallet-1", CustomerId: "id-1", Balance: 50000, Status: enums.WALLET_ACTIVE}, nil)
+		stepUp := req.StepUp{Pin: "000001"}
+		mockTransactionPinService.Mock.On("AuthorizeTransfer", "id-1", "wallet-1", float64(22500), stepUp).
+			Return(errors.New(constants.TransactionPinIncorrectError))
+
+		withPin := request
+		withPin.StepUp = stepUp
+		_, err := transactionService.CreateWithdrawal(withPin)
+		assert.Equal(t, constants.TransactionPinIncorrectError, err.Error())
+		mockTransactionRepository.Mock.AssertNotCalled(t, "Create", mock.Anything)
+		mockWalletService.AssertNotCalled(t, "UpdateWallet", mock.Anything, mock.Anything)
+	})
+}
+
+func TestGetLedgerSnapshot(t *testing.T) {
+	mockTransactionRepository := new(repository.TransactionRepositoryMock)
+	mockWalletService := new(WalletServiceMock)
+	mockEventBus := newEventBusMock()
+	transactionService := NewTransactionService(mockTransactionRepository, mockWalletService, newTransactionPinServiceMock(), mockEventBus)
+
+	wallets := []entity.Wallet{{Id: "wallet-1", Balance: 5000}, {Id: "wallet-2", Balance: 5000}}
+	transactions := []entity.Transaction{{Id: "transaction-1", FromWalletId: "wallet-1", ToWalletId: "wallet-2", Amount: 5000}}
+	mockWalletService.On("GetAllWallets").Return(wallets, nil)
+	mockTransactionRepository.Mock.On("GetAll").Return(transactions, nil)
+
+	// A transfer stops between debiting the sender and crediting the receiver
+	debited, release := make(chan bool), make(chan bool)
+	mockWalletService.On("GetWalletById", "wallet-1").Return(entity.Wallet{Id: "wallet-1", Balance: 10000, Status: enums.WALLET_ACTIVE}, nil)
+	mockWalletService.On("GetWalletById", "wallet-2").Return(entity.Wallet{Id: "wallet-2", Status: enums.WALLET_ACTIVE}, nil)
+	mockWalletService.On("GetAvailableBalance", "wallet-1").Return(float64(10000), nil)
+	mockTransactionRepository.Mock.On("Create", mock.Anything).Return(transactions[0], nil)
+	mockWalletService.On("UpdateWallet", "wallet-1", float64(-5000)).Return(nil).Run(func(mock.Arguments) {
+		debited <- true
+		<-release
+	})
+	mockWalletService.On("UpdateWallet", "wallet-2", float64(5000)).Return(nil)
+
+	go transactionService.CreateNewTransaction(req.CreateTransactionRequest{FromWalletId: "wallet-1", ToWalletId: "wallet-2", Amount: 5000})
+	<-debited
+
+	snapshotTaken := make(chan bool)
+	go func() {
+		snapshotWallets, snapshotTransactions, err := transactionService.GetLedgerSnapshot()
+		assert.Nil(t, err)
+		assert.Equal(t, wallets, snapshotWallets)
+		assert.Equal(t, transactions, snapshotTransactions)
+		snapshotTaken <- true
+	}()
+
+	// The snapshot waits for the transfer to be fully applied
+	select {
+	case <-snapshotTaken:
+		t.Fatal("snapshot was taken while a transfer was in flight")
+	case <-time.After(50 * time.Millisecond):
+	}
+	release <- true
+	<-snapshotTaken
+}
+
+func TestCheckEventCommitted(t *testing.T) {
+	mockTransactionRepository := new(repository.TransactionRepositoryMock)
+	transactionService := NewTransactionService(mockTransactionRepository, new(WalletServiceMock), newTransactionPinServiceMock(), newEventBusMock())
+
+	stored := entity.Transaction{Id: "transaction-1", Amount: 50000, Hash: "hash-1"}
+	mockTransactionRepository.Mock.On("GetAll").Return([]entity.Transaction{stored}, nil)
+
+	t.Run("ShouldReturnStoredTransaction", func(t *testing.T) {
+		data, committed, err := transactionService.CheckEventCommitted(entity.OutboxEvent{Payload: []byte(`{"id":"transaction-1"}`)})
+		assert.Nil(t, err)
+		assert.True(t, committed)
+		assert.Equal(t, stored, data)
+	})
+
+	t.Run("ShouldReportTransactionNeverStored", func(t *testing.T) {
+		_, committed, err := transactionService.CheckEventCommitted(entity.OutboxEvent{Payload: []byte(`{"id":"transaction-2"}`)})
+		assert.Nil(t, err)
+		assert.False(t, committed)
+	})
+}
+
+func TestWithLedgerLock(t *testing.T) {
+	mockTransactionRepository := new(repository.TransactionRepositoryMock)
+	mockWalletService := new(WalletServiceMock)
+	transactionService := NewTransactionService(mockTransactionRepository, mockWalletService, newTransactionPinServiceMock(), newEventBusMock())
+
+	mockWalletService.On("GetWalletById", "wallet-1").Return(entity.Wallet{Id: "wallet-1", Balance: 10000, Status: enums.WALLET_ACTIVE}, nil)
+	mockWalletService.On("GetWalletById", "wallet-2").Return(entity.Wallet{Id: "wallet-2", Status: enums.WALLET_ACTIVE}, nil)
+	mockWalletService.On("GetAvailableBalance", "wallet-1").Return(float64(10000), nil)
+	mockTransactionRepository.Mock.On("Create", mock.Anything).Return(entity.Transaction{Id: "transaction-1"}, nil)
+	mockWalletService.On("UpdateWallet", mock.Anything, mock.Anything).Return(nil)
+
+	// A transfer started while a hold is being checked and stored waits for it
+	transferred := make(chan bool)
+	err := transactionService.WithLedgerLock(func() error {
+		go func() {
+			transactionService.CreateNewTransaction(req.CreateTransactionRequest{FromWalletId: "wallet-1", ToWalletId: "wallet-2", Amount: 5000})
+			transferred <- true
+		}()
+
+		select {
+		case <-transferred:
+			t.Fatal("transfer ran while the ledger lock was held")
+		case <-time.After(50 * time.Millisecond):
+		}
+		return errors.New(constants.TransactionInsufficientError)
+	})
+	assert.Equal(t, constants.TransactionInsufficientError, err.Error())
+	<-transferred
+}
+
+func TestCloseWalletWithPayout(t *testing.T) {
+	request := req.CloseWalletRequest{PayoutWalletId: "wallet-2", StepUp: req.StepUp{Pin: "482915"}}
+
+	newCloseTest := func(balance float64, availableBalance float64) (TransactionService, *WalletServiceMock, *TransactionPinServiceMock) {
+		mockTransactionRepository := new(repository.TransactionRepositoryMock)
+		mockWalletService := new(WalletServiceMock)
+		mockTransactionPinService := newTransactionPinServiceMock()
+		transactionService := NewTransactionService(mockTransactionRepository, mockWalletService, mockTransactionPinService, newEventBusMock())
+
+		mockWalletService.On("GetWalletById", "wallet-1").Return(entity.Wallet{Id: "wallet-1", CustomerId: "customer-1", Balance: balance, Status: enums.WALLET_ACTIVE}, nil)
+		mockWalletService.On("GetWalletById", "wallet-2").Return(entity.Wallet{Id: "wallet-2", Status: enums.WALLET_ACTIVE}, nil)
+		mockWalletService.On("GetAvailableBalance", "wallet-1").Return(availableBalance, nil)
+		mockTransactionRepository.Mock.On("Create", mock.Anything).Return(entity.Transaction{Id: "transaction-1"}, nil)
+		mockWalletService.On("UpdateWallet", mock.Anything, mock.Anything).Return(nil)
+		mockWalletService.On("CloseWallet", "wallet-1").Return(nil)
+		return transactionService, mockWalletService, mockTransactionPinService
+	}
+
+	t.Run("ShouldPayOutBalanceAndClose", func(t *testing.T) {
+		transactionService, mockWalletService, mockTransactionPinService := newCloseTest(5000, 5000)
+
+		err := transactionService.CloseWallet("wallet-1", request)
+		assert.Nil(t, err)
+		mockTransactionPinService.Mock.AssertCalled(t, "AuthorizeTransfer", "customer-1", "wallet-1", float64(5000), request.StepUp)
+		mockWalletService.AssertCalled(t, "UpdateWallet", "wallet-1", float64(-5000))
+		mockWalletService.AssertCalled(t, "UpdateWallet", "wallet-2", float64(5000))
+		mockWalletService.AssertCalled(t, "CloseWallet", "wallet-1")
+	})
+
+	t.Run("ShouldRefuseActiveHoldsBeforePayingOut", func(t *testing.T) {
+		transactionService, mockWalletService, _ := newCloseTest(5000, 3000)
+
+		err := transactionService.CloseWallet("wallet-1", request)
+		assert.Equal(t, constants.WalletCloseActiveHoldsError, err.Error())
+		mockWalletService.AssertNotCalled(t, "UpdateWallet", mock.Anything, mock.Anything)
+		mockWalletService.AssertNotCalled(t, "CloseWallet", mock.Anything)
+	})
+
+	t.Run("ShouldRefusePayoutToSameWallet", func(t *testing.T) {
+		transactionService, mockWalletService, _ := newCloseTest(5000, 5000)
+
+		err := transactionService.CloseWallet("wallet-1", req.CloseWalletRequest{PayoutWalletId: "wallet-1"})
+		assert.Equal(t, constants.WalletPayoutSameWalletError, err.Error())
+		mockWalletService.AssertNotCalled(t, "CloseWallet", mock.Anything)
+	})
+
+	t.Run("ShouldNotPayOutOrCloseWithoutStepUp", func(t *testing.T) {
+		transactionService, mockWalletService, mockTransactionPinService := newCloseTest(5000, 5000)
+		mockTransactionPinService.Mock.ExpectedCalls = nil
+		mockTransactionPinService.Mock.On("AuthorizeTransfer", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+			Return(errors.New(constants.TransactionPinIncorrectError))
+
+		err := transactionService.CloseWallet("wallet-1", request)
+		assert.Equal(t, constants.TransactionPinIncorrectError, err.Error())
+		mockWalletService.AssertNotCalled(t, "UpdateWallet", mock.Anything, mock.Anything)
+		mockWalletService.AssertNotCalled(t, "CloseWallet", mock.Anything)
+	})
+}
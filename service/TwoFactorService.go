@@ -0,0 +1,294 @@
+package service
+
+import (
+	"PaymentAPI/config"
+	"PaymentAPI/constants"
+	req "PaymentAPI/dto/request"
+	res "PaymentAPI/dto/response"
+	"PaymentAPI/entity"
+	"PaymentAPI/enums"
+	"PaymentAPI/logger"
+	"PaymentAPI/repository"
+	"PaymentAPI/utils"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// recoveryCodeCount is how many recovery codes are handed out at a time, each usable once
+const recoveryCodeCount = 10
+
+type TwoFactorService interface {
+	Enroll(customerId string) (res.TwoFactorEnrollmentResponse, error)
+	Enable(customerId string, request req.TwoFactorCodeRequest) (res.RecoveryCodesResponse, error)
+	RegenerateRecoveryCodes(customerId string, request req.TwoFactorCodeRequest) (res.RecoveryCodesResponse, error)
+	Disable(customerId string, request req.DisableTwoFactorRequest) error
+	IsEnabled(customerId string) (bool, error)
+	VerifyCode(customerId string, code string) error
+}
+
+type twoFactorService struct {
+	twoFactorRepository repository.TwoFactorRepository
+	customerService     CustomerService
+	lock                sync.Mutex
+}
+
+// NewTwoFactorService creates a new instance of TwoFactorService
+func NewTwoFactorService(twoFactorRepository repository.TwoFactorRepository, customerService CustomerService) TwoFactorService {
+	return &twoFactorService{
+		twoFactorRepository: twoFactorRepository,
+		customerService:     customerService,
+	}
+}
+
+// Enroll creates a new TOTP secret for the customer, pending until a code made with it is verified. Enrolling again
+// before that replaces the pending secret.
+func (t *twoFactorService) Enroll(customerId string) (res.TwoFactorEnrollmentResponse, error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	twoFactor, err := t.twoFactorRepository.GetByCustomerId(customerId)
+	if err != nil && err.Error() != constants.TwoFactorNotFoundError {
+		return res.TwoFactorEnrollmentResponse{}, err
+	}
+	if err == nil && twoFactor.Enabled {
+		return res.TwoFactorEnrollmentResponse{}, errors.New(constants.TwoFactorAlreadyEnabledError)
+	}
+
+	customer, err := t.customerService.GetCustomerByIdAuth(customerId)
+	if err != nil {
+		return res.TwoFactorEnrollmentResponse{}, err
+	}
+
+	secret, err := utils.GenerateTotpSecret()
+	if err != nil {
+		return res.TwoFactorEnrollmentResponse{}, err
+	}
+
+	err = t.twoFactorRepository.Save(entity.TwoFactor{
+		CustomerId: customerId,
+		Secret:     secret,
+		CreatedAt:  time.Now().Format(time.RFC3339),
+	})
+	if err != nil {
+		return res.TwoFactorEnrollmentResponse{}, err
+	}
+
+	logger.LogInfo("Started two-factor enrollment", logrus.Fields{
+		"customerId": customerId,
+	})
+	return res.TwoFactorEnrollmentResponse{
+		Secret:          secret,
+		ProvisioningUri: utils.TotpProvisioningUri(config.TotpIssuer, customer.Username, secret),
+	}, nil
+}
+
+// Enable turns on two-factor authentication once a code from the pending secret is verified, and hands out the
+// recovery codes. They are only shown this once.
+func (t *twoFactorService) Enable(customerId string, request req.TwoFactorCodeRequest) (res.RecoveryCodesResponse, error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	twoFactor, err := t.twoFactorRepository.GetByCustomerId(customerId)
+	if err != nil {
+		return res.RecoveryCodesResponse{}, err
+	}
+	if twoFactor.Enabled {
+		return res.RecoveryCodesResponse{}, errors.New(constants.TwoFactorAlreadyEnabledError)
+	}
+
+	now := time.Now()
+	step, ok := utils.VerifyTotpCode(twoFactor.Secret, request.Code, now, twoFactor.LastUsedStep)
+	if !ok {
+		logger.LogWarning("Invalid code on two-factor enrollment", logrus.Fields{
+			"customerId": customerId,
+		})
+		return res.RecoveryCodesResponse{}, errors.New(constants.TwoFactorCodeInvalidError)
+	}
+
+	recoveryCodes, recoveryCodeHashes, err := generateRecoveryCodes()
+	if err != nil {
+		return res.RecoveryCodesResponse{}, err
+	}
+
+	twoFactor.Enabled = true
+	twoFactor.EnabledAt = now.Format(time.RFC3339)
+	twoFactor.LastUsedStep = step
+	twoFactor.RecoveryCodeHashes = recoveryCodeHashes
+	if err := t.twoFactorRepository.Save(twoFactor); err != nil {
+		return res.RecoveryCodesResponse{}, err
+	}
+
+	logSecurityEvent(enums.SECURITY_TWO_FACTOR_ENABLED, logrus.Fields{
+		"customerId": customerId,
+	})
+	return res.RecoveryCodesResponse{RecoveryCodes: recoveryCodes}, nil
+}
+
+// RegenerateRecoveryCodes replaces every recovery code of the customer after checking a code from the authenticator
+func (t *twoFactorService) RegenerateRecoveryCodes(customerId string, request req.TwoFactorCodeRequest) (res.RecoveryCodesResponse, error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	twoFactor, err := t.getEnabled(customerId)
+	if err != nil {
+		return res.RecoveryCodesResponse{}, err
+	}
+
+	// A recovery code cannot be used here, or one leaked code would be enough to mint a fresh set
+	step, ok := utils.VerifyTotpCode(twoFactor.Secret, request.Code, time.Now(), twoFactor.LastUsedStep)
+	if !ok {
+		logSecurityEvent(enums.SECURITY_TWO_FACTOR_FAILED, logrus.Fields{
+			"customerId": customerId,
+			"action":     "regenerateRecoveryCodes",
+		})
+		return res.RecoveryCodesResponse{}, errors.New(constants.TwoFactorCodeInvalidError)
+	}
+
+	recoveryCodes, recoveryCodeHashes, err := generateRecoveryCodes()
+	if err != nil {
+		return res.RecoveryCodesResponse{}, err
+	}
+
+	twoFactor.LastUsedStep = step
+	twoFactor.RecoveryCodeHashes = recoveryCodeHashes
+	if err := t.twoFactorRepository.Save(twoFactor); err != nil {
+		return res.RecoveryCodesResponse{}, err
+	}
+
+	logSecurityEvent(enums.SECURITY_RECOVERY_CODES_REGENERATED, logrus.Fields{
+		"customerId": customerId,
+	})
+	return res.RecoveryCodesResponse{RecoveryCodes: recoveryCodes}, nil
+}
+
+// Disable turns off two-factor authentication. Both the password and a code are required, so neither a stolen access
+// token nor a lost phone alone can turn it off.
+func (t *twoFactorService) Disable(customerId string, request req.DisableTwoFactorRequest) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	customer, err := t.customerService.GetCustomerByIdAuth(customerId)
+	if err != nil {
+		return err
+	}
+	if !utils.VerifyPassword(request.Password, customer.Password) {
+		logger.LogWarning("Incorrect password on two-factor disable", logrus.Fields{
+			"customerId": customerId,
+		})
+		return errors.New(constants.PasswordIncorrectError)
+	}
+
+	if err := t.verifyCode(customerId, request.Code); err != nil {
+		return err
+	}
+
+	if err := t.twoFactorRepository.Delete(customerId); err != nil {
+		return err
+	}
+
+	logSecurityEvent(enums.SECURITY_TWO_FACTOR_DISABLED, logrus.Fields{
+		"customerId": customerId,
+	})
+	return nil
+}
+
+// IsEnabled reports whether logging in as the customer requires a code. A pending enrollment does not.
+func (t *twoFactorService) IsEnabled(customerId string) (bool, error) {
+	twoFactor, err := t.twoFactorRepository.GetByCustomerId(customerId)
+	if err != nil {
+		if err.Error() == constants.TwoFactorNotFoundError {
+			return false, nil
+		}
+		return false, err
+	}
+	return twoFactor.Enabled, nil
+}
+
+// VerifyCode checks a code from the authenticator or one of the recovery codes of the customer. Either is used up, a
+// TOTP code cannot be given twice and a recovery code is removed.
+func (t *twoFactorService) VerifyCode(customerId string, code string) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	return t.verifyCode(customerId, code)
+}
+
+// verifyCode is VerifyCode for callers already holding the lock
+func (t *twoFactorService) verifyCode(customerId string, code string) error {
+	twoFactor, err := t.getEnabled(customerId)
+	if err != nil {
+		return err
+	}
+
+	if step, ok := utils.VerifyTotpCode(twoFactor.Secret, code, time.Now(), twoFactor.LastUsedStep); ok {
+		twoFactor.LastUsedStep = step
+		return t.twoFactorRepository.Save(twoFactor)
+	}
+
+	recoveryCodeHash := hashSecretToken(normalizeRecoveryCode(code))
+	for i, storedHash := range twoFactor.RecoveryCodeHashes {
+		if storedHash != recoveryCodeHash {
+			continue
+		}
+
+		twoFactor.RecoveryCodeHashes = append(twoFactor.RecoveryCodeHashes[:i:i], twoFactor.RecoveryCodeHashes[i+1:]...)
+		if err := t.twoFactorRepository.Save(twoFactor); err != nil {
+			return err
+		}
+		logSecurityEvent(enums.SECURITY_RECOVERY_CODE_USED, logrus.Fields{
+			"customerId":             customerId,
+			"recoveryCodesRemaining": len(twoFactor.RecoveryCodeHashes),
+		})
+		return nil
+	}
+
+	logSecurityEvent(enums.SECURITY_TWO_FACTOR_FAILED, logrus.Fields{
+		"customerId": customerId,
+	})
+	return errors.New(constants.TwoFactorCodeInvalidError)
+}
+
+// getEnabled returns the two-factor setup of the customer, refusing one that is missing or still pending
+func (t *twoFactorService) getEnabled(customerId string) (entity.TwoFactor, error) {
+	twoFactor, err := t.twoFactorRepository.GetByCustomerId(customerId)
+	if err != nil {
+		if err.Error() == constants.TwoFactorNotFoundError {
+			return entity.TwoFactor{}, errors.New(constants.TwoFactorNotEnabledError)
+		}
+		return entity.TwoFactor{}, err
+	}
+	if !twoFactor.Enabled {
+		return entity.TwoFactor{}, errors.New(constants.TwoFactorNotEnabledError)
+	}
+	return twoFactor, nil
+}
+
+// generateRecoveryCodes returns new recovery codes as xxxxx-xxxxx, and the hashes they are stored by
+func generateRecoveryCodes() ([]string, []string, error) {
+	recoveryCodes := make([]string, 0, recoveryCodeCount)
+	recoveryCodeHashes := make([]string, 0, recoveryCodeCount)
+
+	for i := 0; i < recoveryCodeCount; i++ {
+		random := make([]byte, 10)
+		if _, err := rand.Read(random); err != nil {
+			return nil, nil, err
+		}
+		code := strings.ToLower(base32.StdEncoding.EncodeToString(random))[:10]
+
+		recoveryCodes = append(recoveryCodes, code[:5]+"-"+code[5:])
+		recoveryCodeHashes = append(recoveryCodeHashes, hashSecretToken(code))
+	}
+	return recoveryCodes, recoveryCodeHashes, nil
+}
+
+// normalizeRecoveryCode accepts a recovery code typed in upper case or without its hyphen
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.ReplaceAll(code, "-", "")
+}
@@ -0,0 +1,41 @@
+package service
+
+import (
+	req "PaymentAPI/dto/request"
+	res "PaymentAPI/dto/response"
+	"github.com/stretchr/testify/mock"
+)
+
+type TwoFactorServiceMock struct {
+	Mock mock.Mock
+}
+
+func (t *TwoFactorServiceMock) Enroll(customerId string) (res.TwoFactorEnrollmentResponse, error) {
+	args := t.Mock.Called(customerId)
+	return args.Get(0).(res.TwoFactorEnrollmentResponse), args.Error(1)
+}
+
+func (t *TwoFactorServiceMock) Enable(customerId string, request req.TwoFactorCodeRequest) (res.RecoveryCodesResponse, error) {
+	args := t.Mock.Called(customerId, request)
+	return args.Get(0).(res.RecoveryCodesResponse), args.Error(1)
+}
+
+func (t *TwoFactorServiceMock) RegenerateRecoveryCodes(customerId string, request req.TwoFactorCodeRequest) (res.RecoveryCodesResponse, error) {
+	args := t.Mock.Called(customerId, request)
+	return args.Get(0).(res.RecoveryCodesResponse), args.Error(1)
+}
+
+func (t *TwoFactorServiceMock) Disable(customerId string, request req.DisableTwoFactorRequest) error {
+	args := t.Mock.Called(customerId, request)
+	return args.Error(0)
+}
+
+func (t *TwoFactorServiceMock) IsEnabled(customerId string) (bool, error) {
+	args := t.Mock.Called(customerId)
+	return args.Bool(0), args.Error(1)
+}
+
+func (t *TwoFactorServiceMock) VerifyCode(customerId string, code string) error {
+	args := t.Mock.Called(customerId, code)
+	return args.Error(0)
+}
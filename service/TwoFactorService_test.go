@@ -0,0 +1,145 @@
+package service
+
+import (
+	"PaymentAPI/constants"
+	req "PaymentAPI/dto/request"
+	"PaymentAPI/entity"
+	"PaymentAPI/repository"
+	"PaymentAPI/utils"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEnrollTwoFactor(t *testing.T) {
+	t.Run("ShouldReturnSecretAndProvisioningUri", func(t *testing.T) {
+		mockRepository := new(repository.TwoFactorRepositoryMock)
+		mockCustomerService := new(CustomerServiceMock)
+		mockRepository.Mock.On("GetByCustomerId", "id-1").Return(entity.TwoFactor{}, errors.New(constants.TwoFactorNotFoundError))
+		mockRepository.Mock.On("Save", mock.Anything).Return(nil)
+		mockCustomerService.Mock.On("GetCustomerByIdAuth", "id-1").Return(entity.Customer{Id: "id-1", Username: "johndoe"}, nil)
+
+		enrollment, err := NewTwoFactorService(mockRepository, mockCustomerService).Enroll("id-1")
+		assert.Nil(t, err)
+		assert.Contains(t, enrollment.ProvisioningUri, "secret="+enrollment.Secret)
+		assert.Contains(t, enrollment.ProvisioningUri, ":johndoe?")
+
+		stored := mockRepository.Mock.Calls[1].Arguments.Get(0).(entity.TwoFactor)
+		assert.Equal(t, enrollment.Secret, stored.Secret)
+		assert.False(t, stored.Enabled)
+	})
+
+	t.Run("ShouldRefuseWhenAlreadyEnabled", func(t *testing.T) {
+		mockRepository := new(repository.TwoFactorRepositoryMock)
+		mockRepository.Mock.On("GetByCustomerId", "id-1").Return(entity.TwoFactor{CustomerId: "id-1", Enabled: true}, nil)
+
+		_, err := NewTwoFactorService(mockRepository, new(CustomerServiceMock)).Enroll("id-1")
+		assert.Equal(t, constants.TwoFactorAlreadyEnabledError, err.Error())
+	})
+}
+
+func TestEnableTwoFactor(t *testing.T) {
+	secret, _ := utils.GenerateTotpSecret()
+
+	t.Run("ShouldEnableAndReturnRecoveryCodes", func(t *testing.T) {
+		mockRepository := new(repository.TwoFactorRepositoryMock)
+		mockRepository.Mock.On("GetByCustomerId", "id-1").Return(entity.TwoFactor{CustomerId: "id-1", Secret: secret}, nil)
+		mockRepository.Mock.On("Save", mock.Anything).Return(nil)
+
+		code, _ := utils.TotpCode(secret, utils.TotpStep(time.Now()))
+		recoveryCodes, err := NewTwoFactorService(mockRepository, new(CustomerServiceMock)).Enable("id-1", req.TwoFactorCodeRequest{Code: code})
+		assert.Nil(t, err)
+		assert.Len(t, recoveryCodes.RecoveryCodes, recoveryCodeCount)
+
+		stored := mockRepository.Mock.Calls[1].Arguments.Get(0).(entity.TwoFactor)
+		assert.True(t, stored.Enabled)
+		assert.Equal(t, utils.TotpStep(time.Now()), stored.LastUsedStep)
+		assert.Equal(t, hashSecretToken(strings.ReplaceAll(recoveryCodes.RecoveryCodes[0], "-", "")), stored.RecoveryCodeHashes[0])
+	})
+
+	t.Run("ShouldRefuseWrongCode", func(t *testing.T) {
+		mockRepository := new(repository.TwoFactorRepositoryMock)
+		mockRepository.Mock.On("GetByCustomerId", "id-1").Return(entity.TwoFactor{CustomerId: "id-1", Secret: secret}, nil)
+
+		code, _ := utils.TotpCode(secret, utils.TotpStep(time.Now())-5)
+		_, err := NewTwoFactorService(mockRepository, new(CustomerServiceMock)).Enable("id-1", req.TwoFactorCodeRequest{Code: code})
+		assert.Equal(t, constants.TwoFactorCodeInvalidError, err.Error())
+		mockRepository.Mock.AssertNotCalled(t, "Save", mock.Anything)
+	})
+}
+
+func TestVerifyTwoFactorCode(t *testing.T) {
+	secret, _ := utils.GenerateTotpSecret()
+	enabled := func(lastUsedStep int64) entity.TwoFactor {
+		return entity.TwoFactor{
+			CustomerId:         "id-1",
+			Secret:             secret,
+			Enabled:            true,
+			LastUsedStep:       lastUsedStep,
+			RecoveryCodeHashes: []string{hashSecretToken("aaaaabbbbb"), hashSecretToken("cccccddddd")},
+		}
+	}
+
+	t.Run("ShouldRefuseReplayedCode", func(t *testing.T) {
+		mockRepository := new(repository.TwoFactorRepositoryMock)
+		mockRepository.Mock.On("GetByCustomerId", "id-1").Return(enabled(utils.TotpStep(time.Now())+1), nil)
+
+		code, _ := utils.TotpCode(secret, utils.TotpStep(time.Now()))
+		err := NewTwoFactorService(mockRepository, new(CustomerServiceMock)).VerifyCode("id-1", code)
+		assert.Equal(t, constants.TwoFactorCodeInvalidError, err.Error())
+	})
+
+	t.Run("ShouldUseUpRecoveryCode", func(t *testing.T) {
+		mockRepository := new(repository.TwoFactorRepositoryMock)
+		mockRepository.Mock.On("GetByCustomerId", "id-1").Return(enabled(0), nil)
+		mockRepository.Mock.On("Save", mock.Anything).Return(nil)
+
+		err := NewTwoFactorService(mockRepository, new(CustomerServiceMock)).VerifyCode("id-1", "AAAAA-BBBBB")
+		assert.Nil(t, err)
+
+		stored := mockRepository.Mock.Calls[1].Arguments.Get(0).(entity.TwoFactor)
+		assert.Equal(t, []string{hashSecretToken("cccccddddd")}, stored.RecoveryCodeHashes)
+	})
+
+	t.Run("ShouldRefuseWhenNotEnabled", func(t *testing.T) {
+		mockRepository := new(repository.TwoFactorRepositoryMock)
+		mockRepository.Mock.On("GetByCustomerId", "id-1").Return(entity.TwoFactor{CustomerId: "id-1", Secret: secret}, nil)
+
+		err := NewTwoFactorService(mockRepository, new(CustomerServiceMock)).VerifyCode("id-1", "aaaaa-bbbbb")
+		assert.Equal(t, constants.TwoFactorNotEnabledError, err.Error())
+	})
+}
+
+func TestDisableTwoFactor(t *testing.T) {
+	hashedPassword, _ := utils.BCryptEncoder("password", 4)
+
+	t.Run("ShouldRequirePassword", func(t *testing.T) {
+		mockRepository := new(repository.TwoFactorRepositoryMock)
+		mockCustomerService := new(CustomerServiceMock)
+		mockCustomerService.Mock.On("GetCustomerByIdAuth", "id-1").Return(entity.Customer{Id: "id-1", Password: hashedPassword}, nil)
+
+		err := NewTwoFactorService(mockRepository, mockCustomerService).Disable("id-1", req.DisableTwoFactorRequest{Password: "wrong", Code: "aaaaa-bbbbb"})
+		assert.Equal(t, constants.PasswordIncorrectError, err.Error())
+		mockRepository.Mock.AssertNotCalled(t, "Delete", "id-1")
+	})
+
+	t.Run("ShouldDeleteWithPasswordAndCode", func(t *testing.T) {
+		mockRepository := new(repository.TwoFactorRepositoryMock)
+		mockCustomerService := new(CustomerServiceMock)
+		mockCustomerService.Mock.On("GetCustomerByIdAuth", "id-1").Return(entity.Customer{Id: "id-1", Password: hashedPassword}, nil)
+		mockRepository.Mock.On("GetByCustomerId", "id-1").Return(entity.TwoFactor{
+			CustomerId:         "id-1",
+			Enabled:            true,
+			RecoveryCodeHashes: []string{hashSecretToken("aaaaabbbbb")},
+		}, nil)
+		mockRepository.Mock.On("Save", mock.Anything).Return(nil)
+		mockRepository.Mock.On("Delete", "id-1").Return(nil)
+
+		err := NewTwoFactorService(mockRepository, mockCustomerService).Disable("id-1", req.DisableTwoFactorRequest{Password: "password", Code: "aaaaa-bbbbb"})
+		assert.Nil(t, err)
+		mockRepository.Mock.AssertCalled(t, "Delete", "id-1")
+	})
+}
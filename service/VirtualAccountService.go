@@ -0,0 +1,177 @@
+package service
+
+import (
+	"PaymentAPI/config"
+	"PaymentAPI/constants"
+	req "PaymentAPI/dto/request"
+	res "PaymentAPI/dto/response"
+	"PaymentAPI/entity"
+	"PaymentAPI/repository"
+	"PaymentAPI/utils"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// virtualAccountSequenceDigits is the length of the per-wallet part of a virtual account number,
+// together with a four digit prefix and the check digit a number is 16 digits long
+const virtualAccountSequenceDigits = 11
+
+// externalBankSource is the sending side of deposits credited by an external bank, it is not a wallet of this system
+const externalBankSource = "EXTERNAL_BANK"
+
+type VirtualAccountService interface {
+	GetOrCreateVirtualAccount(walletId string) (res.VirtualAccountResponse, error)
+	GetVirtualAccountByNumber(number string) (res.VirtualAccountResponse, error)
+	HandleBankNotification(body []byte, timestamp string, signature string) (res.BankNotificationResponse, error)
+}
+
+type virtualAccountService struct {
+	virtualAccountRepository repository.VirtualAccountRepository
+	walletService            WalletService
+	transactionService       TransactionService
+
+	// createLock keeps two wallets from being given the same sequence number
+	createLock sync.Mutex
+}
+
+// NewVirtualAccountService creates a new instance of VirtualAccountService
+func NewVirtualAccountService(virtualAccountRepository repository.VirtualAccountRepository, walletService WalletService, transactionService TransactionService) VirtualAccountService {
+	return &virtualAccountService{
+		virtualAccountRepository: virtualAccountRepository,
+		walletService:            walletService,
+		transactionService:       transactionService,
+	}
+}
+
+// GetOrCreateVirtualAccount returns the virtual account of a wallet, assigning one on first use.
+// A wallet keeps the same number for its whole life.
+func (v *virtualAccountService) GetOrCreateVirtualAccount(walletId string) (res.VirtualAccountResponse, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"walletId": walletId,
+	})
+
+	v.createLock.Lock()
+	defer v.createLock.Unlock()
+
+	virtualAccounts, err := v.virtualAccountRepository.GetAll()
+	if err != nil {
+		return res.VirtualAccountResponse{}, err
+	}
+	for _, virtualAccount := range virtualAccounts {
+		if virtualAccount.WalletId == walletId {
+			return mapVirtualAccountToResponse(virtualAccount), nil
+		}
+	}
+
+	if _, err := v.walletService.GetWalletById(walletId); err != nil {
+		logger.Error("Failed to retrieve wallet", err)
+		return res.VirtualAccountResponse{}, err
+	}
+
+	virtualAccount := entity.VirtualAccount{
+		Number:    newVirtualAccountNumber(len(virtualAccounts) + 1),
+		WalletId:  walletId,
+		CreatedAt: time.Now().Format(time.RFC3339),
+	}
+	if err := v.virtualAccountRepository.Create(virtualAccount); err != nil {
+		logger.Error("Failed to create virtual account", err)
+		return res.VirtualAccountResponse{}, err
+	}
+
+	logger.WithFields(logrus.Fields{"virtualAccountNumber": virtualAccount.Number}).Info("Virtual account assigned")
+	return mapVirtualAccountToResponse(virtualAccount), nil
+}
+
+// GetVirtualAccountByNumber looks up the wallet a virtual account number credits
+func (v *virtualAccountService) GetVirtualAccountByNumber(number string) (res.VirtualAccountResponse, error) {
+	// A mistyped number is caught by its check digit before any lookup
+	if !utils.IsValidLuhn(number) || !strings.HasPrefix(number, config.VirtualAccountPrefix) {
+		return res.VirtualAccountResponse{}, errors.New(constants.VirtualAccountInvalidError)
+	}
+
+	virtualAccount, err := v.virtualAccountRepository.GetByNumber(number)
+	if err != nil {
+		return res.VirtualAccountResponse{}, err
+	}
+	return mapVirtualAccountToResponse(virtualAccount), nil
+}
+
+// HandleBankNotification verifies a signed deposit notification from the bank and credits the matching wallet.
+// The bank may resend a notification, a notification that was already credited is reported as a duplicate.
+func (v *virtualAccountService) HandleBankNotification(body []byte, timestamp string, signature string) (res.BankNotificationResponse, error) {
+	// The signature covers the timestamp, so a captured notification cannot be replayed later with a new one
+	if !utils.VerifyBankNotification(timestamp, body, signature) {
+		logrus.Warn("Bank notification with invalid signature rejected")
+		return res.BankNotificationResponse{}, errors.New(constants.BankNotificationSignatureError)
+	}
+	sentAt, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || time.Since(time.Unix(sentAt, 0)).Abs() > config.BankNotificationMaxClockSkew {
+		logrus.Warn("Bank notification outside the accepted window rejected")
+		return res.BankNotificationResponse{}, errors.New(constants.BankNotificationExpiredError)
+	}
+
+	var notification req.BankNotificationRequest
+	if err := json.Unmarshal(body, &notification); err != nil {
+		return res.BankNotificationResponse{}, errors.New(constants.InvalidRequestBodyError)
+	}
+	if notification.ExternalId == "" || notification.VirtualAccountNumber == "" || notification.Amount <= 0 {
+		return res.BankNotificationResponse{}, errors.New(constants.BankNotificationInvalidError)
+	}
+
+	logger := logrus.WithFields(logrus.Fields{
+		"externalId":           notification.ExternalId,
+		"virtualAccountNumber": notification.VirtualAccountNumber,
+		"amount":               notification.Amount,
+	})
+
+	logger.Info("Processing bank notification")
+
+	virtualAccount, err := v.GetVirtualAccountByNumber(notification.VirtualAccountNumber)
+	if err != nil {
+		logger.Error("Failed to find virtual account", err)
+		return res.BankNotificationResponse{}, err
+	}
+
+	message := "Top up via virtual account"
+	if notification.PayerName != "" {
+		message = fmt.Sprintf("%s from %s", message, notification.PayerName)
+	}
+
+	transaction, created, err := v.transactionService.CreateDeposit(req.CreateDepositRequest{
+		FromAccount: externalBankSource,
+		ToWalletId:  virtualAccount.WalletId,
+		Amount:      notification.Amount,
+		ExternalId:  notification.ExternalId,
+		Message:     message,
+	})
+	if err != nil {
+		logger.Error("Failed to credit deposit", err)
+		return res.BankNotificationResponse{}, err
+	}
+
+	logger.WithFields(logrus.Fields{"transactionId": transaction.Id, "duplicate": !created}).Info("Bank notification processed")
+	return res.BankNotificationResponse{
+		ExternalId:    notification.ExternalId,
+		TransactionId: transaction.Id,
+		Duplicate:     !created,
+	}, nil
+}
+
+// newVirtualAccountNumber builds the number from the bank prefix, a zero padded sequence and a Luhn check digit
+func newVirtualAccountNumber(sequence int) string {
+	number := fmt.Sprintf("%s%0*d", config.VirtualAccountPrefix, virtualAccountSequenceDigits, sequence)
+	return number + string(utils.LuhnCheckDigit(number))
+}
+
+func mapVirtualAccountToResponse(virtualAccount entity.VirtualAccount) res.VirtualAccountResponse {
+	return res.VirtualAccountResponse{
+		Number:   virtualAccount.Number,
+		WalletId: virtualAccount.WalletId,
+	}
+}
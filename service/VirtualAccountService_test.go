@@ -0,0 +1,125 @@
+package service
+
+import (
+	"PaymentAPI/config"
+	"PaymentAPI/constants"
+	req "PaymentAPI/dto/request"
+	"PaymentAPI/entity"
+	"PaymentAPI/repository"
+	"PaymentAPI/utils"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"strconv"
+	"testing"
+	"time"
+)
+
+type virtualAccountTest struct {
+	mockVirtualAccountRepository *repository.VirtualAccountRepositoryMock
+	mockWalletService            *WalletServiceMock
+	mockTransactionService       *TransactionServiceMock
+	service                      VirtualAccountService
+}
+
+func setupVirtualAccountTest() virtualAccountTest {
+	config.VirtualAccountPrefix = "8808"
+	config.BankNotificationSigningKey = []byte("bank-secret")
+	config.BankNotificationMaxClockSkew = 5 * time.Minute
+
+	test := virtualAccountTest{
+		mockVirtualAccountRepository: new(repository.VirtualAccountRepositoryMock),
+		mockWalletService:            new(WalletServiceMock),
+		mockTransactionService:       new(TransactionServiceMock),
+	}
+	test.service = NewVirtualAccountService(test.mockVirtualAccountRepository, test.mockWalletService, test.mockTransactionService)
+	return test
+}
+
+func signedNotification(notification req.BankNotificationRequest, sentAt time.Time) ([]byte, string, string) {
+	body, _ := json.Marshal(notification)
+	timestamp := strconv.FormatInt(sentAt.Unix(), 10)
+	return body, timestamp, utils.SignBankNotification(timestamp, body)
+}
+
+func TestGetOrCreateVirtualAccount(t *testing.T) {
+	t.Run("ShouldAssignNextNumberWithCheckDigit", func(t *testing.T) {
+		test := setupVirtualAccountTest()
+		test.mockVirtualAccountRepository.Mock.On("GetAll").Return([]entity.VirtualAccount{{Number: "8808000000000015", WalletId: "wallet-9"}}, nil)
+		test.mockWalletService.On("GetWalletById", "wallet-1").Return(entity.Wallet{Id: "wallet-1"}, nil)
+		test.mockVirtualAccountRepository.Mock.On("Create", mock.Anything).Return(nil)
+
+		virtualAccount, err := test.service.GetOrCreateVirtualAccount("wallet-1")
+		assert.Nil(t, err)
+		assert.Equal(t, "8808000000000023", virtualAccount.Number)
+		assert.True(t, utils.IsValidLuhn(virtualAccount.Number))
+	})
+
+	t.Run("ShouldKeepExistingNumber", func(t *testing.T) {
+		test := setupVirtualAccountTest()
+		test.mockVirtualAccountRepository.Mock.On("GetAll").Return([]entity.VirtualAccount{{Number: "8808000000000015", WalletId: "wallet-1"}}, nil)
+
+		virtualAccount, err := test.service.GetOrCreateVirtualAccount("wallet-1")
+		assert.Nil(t, err)
+		assert.Equal(t, "8808000000000015", virtualAccount.Number)
+		test.mockVirtualAccountRepository.Mock.AssertNotCalled(t, "Create", mock.Anything)
+	})
+}
+
+func TestHandleBankNotification(t *testing.T) {
+	notification := req.BankNotificationRequest{ExternalId: "bank-ref-1", VirtualAccountNumber: "8808000000000015", Amount: 50000, PayerName: "John"}
+
+	t.Run("ShouldCreditMatchingWallet", func(t *testing.T) {
+		test := setupVirtualAccountTest()
+		test.mockVirtualAccountRepository.Mock.On("GetByNumber", "8808000000000015").Return(entity.VirtualAccount{Number: "8808000000000015", WalletId: "wallet-1"}, nil)
+		test.mockTransactionService.On("CreateDeposit", req.CreateDepositRequest{
+			FromAccount: externalBankSource,
+			ToWalletId:  "wallet-1",
+			Amount:      50000,
+			ExternalId:  "bank-ref-1",
+			Message:     "Top up via virtual account from John",
+		}).Return(entity.Transaction{Id: "transaction-1"}, true, nil)
+
+		response, err := test.service.HandleBankNotification(signedNotification(notification, time.Now()))
+		assert.Nil(t, err)
+		assert.Equal(t, "transaction-1", response.TransactionId)
+		assert.False(t, response.Duplicate)
+	})
+
+	t.Run("ShouldReportDuplicate", func(t *testing.T) {
+		test := setupVirtualAccountTest()
+		test.mockVirtualAccountRepository.Mock.On("GetByNumber", "8808000000000015").Return(entity.VirtualAccount{Number: "8808000000000015", WalletId: "wallet-1"}, nil)
+		test.mockTransactionService.On("CreateDeposit", mock.Anything).Return(entity.Transaction{Id: "transaction-1"}, false, nil)
+
+		response, err := test.service.HandleBankNotification(signedNotification(notification, time.Now()))
+		assert.Nil(t, err)
+		assert.True(t, response.Duplicate)
+	})
+
+	t.Run("ShouldRejectTamperedBody", func(t *testing.T) {
+		test := setupVirtualAccountTest()
+		body, timestamp, signature := signedNotification(notification, time.Now())
+		tampered := []byte(string(body[:len(body)-1]) + " ")
+
+		_, err := test.service.HandleBankNotification(tampered, timestamp, signature)
+		assert.Equal(t, constants.BankNotificationSignatureError, err.Error())
+		test.mockTransactionService.AssertNotCalled(t, "CreateDeposit", mock.Anything)
+	})
+
+	t.Run("ShouldRejectReplayedNotification", func(t *testing.T) {
+		test := setupVirtualAccountTest()
+
+		_, err := test.service.HandleBankNotification(signedNotification(notification, time.Now().Add(-time.Hour)))
+		assert.Equal(t, constants.BankNotificationExpiredError, err.Error())
+		test.mockTransactionService.AssertNotCalled(t, "CreateDeposit", mock.Anything)
+	})
+
+	t.Run("ShouldRejectInvalidCheckDigit", func(t *testing.T) {
+		test := setupVirtualAccountTest()
+		mistyped := notification
+		mistyped.VirtualAccountNumber = "8808000000000016"
+
+		_, err := test.service.HandleBankNotification(signedNotification(mistyped, time.Now()))
+		assert.Equal(t, constants.VirtualAccountInvalidError, err.Error())
+	})
+}
@@ -1,25 +1,38 @@
 package service
 
 import (
+	"PaymentAPI/constants"
 	"PaymentAPI/entity"
+	"PaymentAPI/enums"
 	"PaymentAPI/repository"
+	"encoding/json"
+	"errors"
 	"github.com/sirupsen/logrus" // Import logrus for structured logging
+	"time"
 )
 
 type WalletService interface {
 	CreateWallet(customerId string) error
 	GetWalletByCustomerId(customerId string) (entity.Wallet, error)
 	GetWalletById(id string) (entity.Wallet, error)
+	GetAllWallets() ([]entity.Wallet, error)
+	GetAvailableBalance(id string) (float64, error)
 	UpdateWallet(id string, balance float64) error
+	FreezeWallet(id string) error
+	UnfreezeWallet(id string) error
+	CloseWallet(id string) error
+	CheckEventCommitted(outboxEvent entity.OutboxEvent) (interface{}, bool, error)
 }
 
 type walletService struct {
 	WalletRepository repository.WalletRepository
+	HoldRepository   repository.HoldRepository
+	EventBus         EventBus
 }
 
 // NewWalletService creates a new instance of WalletService
-func NewWalletService(walletRepository repository.WalletRepository) WalletService {
-	return &walletService{WalletRepository: walletRepository}
+func NewWalletService(walletRepository repository.WalletRepository, holdRepository repository.HoldRepository, eventBus EventBus) WalletService {
+	return &walletService{WalletRepository: walletRepository, HoldRepository: holdRepository, EventBus: eventBus}
 }
 
 // CreateWallet creates a new wallet for a customer
@@ -79,6 +92,63 @@ func (w *walletService) GetWalletById(id string) (entity.Wallet, error) {
 	return wallet, nil
 }
 
+// GetAllWallets retrieves every wallet
+func (w *walletService) GetAllWallets() ([]entity.Wallet, error) {
+	logger := logrus.WithFields(logrus.Fields{})
+
+	logger.Info("Retrieving all wallets")
+
+	wallets, err := w.WalletRepository.GetAll()
+	if err != nil {
+		logger.Error("Failed to retrieve wallets", err)
+		return nil, err
+	}
+
+	logger.Info("All wallets retrieved successfully")
+	return wallets, nil
+}
+
+// GetAvailableBalance returns the wallet balance minus the funds reserved by active holds
+func (w *walletService) GetAvailableBalance(id string) (float64, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"walletId": id,
+	})
+
+	logger.Info("Calculating available balance")
+
+	wallet, err := w.WalletRepository.GetById(id)
+	if err != nil {
+		logger.Error("Failed to retrieve wallet by ID", err)
+		return 0, err
+	}
+
+	holds, err := w.HoldRepository.GetByWalletId(id)
+	if err != nil {
+		logger.Error("Failed to retrieve holds for wallet", err)
+		return 0, err
+	}
+
+	available := wallet.Balance
+	now := time.Now()
+	for _, hold := range holds {
+		if isHoldReserving(hold, now) {
+			available -= hold.Amount
+		}
+	}
+
+	logger.Info("Available balance calculated successfully")
+	return available, nil
+}
+
+// isHoldReserving reports whether a hold still reserves funds, an expired hold stops reserving even before it is swept
+func isHoldReserving(hold entity.Hold, now time.Time) bool {
+	if hold.Status != enums.HOLD_ACTIVE {
+		return false
+	}
+	expiresAt, err := time.Parse(time.RFC3339, hold.ExpiresAt)
+	return err != nil || now.Before(expiresAt)
+}
+
 // UpdateWallet updates the balance of a wallet
 func (w *walletService) UpdateWallet(id string, balance float64) error {
 	logger := logrus.WithFields(logrus.Fields{
@@ -98,3 +168,155 @@ func (w *walletService) UpdateWallet(id string, balance float64) error {
 	logger.Info("Wallet balance updated successfully")
 	return nil
 }
+
+// FreezeWallet blocks an active wallet from sending and receiving funds
+func (w *walletService) FreezeWallet(id string) error {
+	logger := logrus.WithFields(logrus.Fields{
+		"walletId": id,
+	})
+
+	logger.Info("Freezing wallet")
+
+	wallet, err := w.WalletRepository.GetById(id)
+	if err != nil {
+		logger.Error("Failed to retrieve wallet by ID", err)
+		return err
+	}
+
+	switch wallet.Status {
+	case enums.WALLET_FROZEN:
+		return errors.New(constants.WalletAlreadyFrozenError)
+	case enums.WALLET_CLOSED:
+		return errors.New(constants.WalletAlreadyClosedError)
+	}
+
+	wallet.Status = enums.WALLET_FROZEN
+	outboxEvents, err := w.EventBus.Prepare([]enums.EventType{enums.EVENT_WALLET_FROZEN}, []string{id}, wallet)
+	if err != nil {
+		logger.Error("Failed to record wallet freeze event", err)
+		return err
+	}
+
+	err = w.WalletRepository.UpdateStatus(id, enums.WALLET_FROZEN)
+	if err != nil {
+		logger.Error("Failed to freeze wallet", err)
+		w.EventBus.Discard(outboxEvents)
+		return err
+	}
+	w.EventBus.Commit(outboxEvents, wallet)
+
+	logger.Info("Wallet frozen successfully")
+	return nil
+}
+
+// UnfreezeWallet makes a frozen wallet active again
+func (w *walletService) UnfreezeWallet(id string) error {
+	logger := logrus.WithFields(logrus.Fields{
+		"walletId": id,
+	})
+
+	logger.Info("Unfreezing wallet")
+
+	wallet, err := w.WalletRepository.GetById(id)
+	if err != nil {
+		logger.Error("Failed to retrieve wallet by ID", err)
+		return err
+	}
+
+	if wallet.Status != enums.WALLET_FROZEN {
+		return errors.New(constants.WalletNotFrozenError)
+	}
+
+	wallet.Status = enums.WALLET_ACTIVE
+	outboxEvents, err := w.EventBus.Prepare([]enums.EventType{enums.EVENT_WALLET_UNFROZEN}, []string{id}, wallet)
+	if err != nil {
+		logger.Error("Failed to record wallet unfreeze event", err)
+		return err
+	}
+
+	err = w.WalletRepository.UpdateStatus(id, enums.WALLET_ACTIVE)
+	if err != nil {
+		logger.Error("Failed to unfreeze wallet", err)
+		w.EventBus.Discard(outboxEvents)
+		return err
+	}
+	w.EventBus.Commit(outboxEvents, wallet)
+
+	logger.Info("Wallet unfrozen successfully")
+	return nil
+}
+
+// CloseWallet permanently closes a wallet, which is only allowed once it has no active holds and its balance is zero.
+// TransactionService closes wallets under the ledger lock, so no credit lands between the checks and the close.
+func (w *walletService) CloseWallet(id string) error {
+	logger := logrus.WithFields(logrus.Fields{
+		"walletId": id,
+	})
+
+	logger.Info("Closing wallet")
+
+	wallet, err := w.WalletRepository.GetById(id)
+	if err != nil {
+		logger.Error("Failed to retrieve wallet by ID", err)
+		return err
+	}
+
+	if wallet.Status == enums.WALLET_CLOSED {
+		return errors.New(constants.WalletAlreadyClosedError)
+	}
+
+	// Funds reserved by a hold still belong to its receiving wallet until it is captured or voided
+	holds, err := w.HoldRepository.GetByWalletId(id)
+	if err != nil {
+		logger.Error("Failed to retrieve holds for wallet", err)
+		return err
+	}
+	now := time.Now()
+	for _, hold := range holds {
+		if isHoldReserving(hold, now) {
+			logger.Warn("Wallet still has active holds")
+			return errors.New(constants.WalletCloseActiveHoldsError)
+		}
+	}
+
+	if wallet.Balance != 0 {
+		logger.Warn("Wallet still holds a balance")
+		return errors.New(constants.WalletCloseBalanceError)
+	}
+
+	wallet.Status = enums.WALLET_CLOSED
+	outboxEvents, err := w.EventBus.Prepare([]enums.EventType{enums.EVENT_WALLET_CLOSED}, []string{id}, wallet)
+	if err != nil {
+		logger.Error("Failed to record wallet close event", err)
+		return err
+	}
+
+	err = w.WalletRepository.UpdateStatus(id, enums.WALLET_CLOSED)
+	if err != nil {
+		logger.Error("Failed to close wallet", err)
+		w.EventBus.Discard(outboxEvents)
+		return err
+	}
+	w.EventBus.Commit(outboxEvents, wallet)
+
+	logger.Info("Wallet closed successfully")
+	return nil
+}
+
+// CheckEventCommitted finds the stored wallet of a prepared wallet event its operation did not commit, the status
+// change was stored when the wallet still has the status of the event
+func (w *walletService) CheckEventCommitted(outboxEvent entity.OutboxEvent) (interface{}, bool, error) {
+	var prepared entity.Wallet
+	if err := json.Unmarshal(outboxEvent.Payload, &prepared); err != nil {
+		return nil, false, err
+	}
+
+	wallet, err := w.WalletRepository.GetById(prepared.Id)
+	if err != nil {
+		return nil, false, err
+	}
+	if wallet.Status != prepared.Status {
+		return nil, false, nil
+	}
+	return wallet, true, nil
+}
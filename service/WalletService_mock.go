@@ -36,7 +36,37 @@ func (w *WalletServiceMock) GetWalletById(id string) (entity.Wallet, error) {
 	return wallet, args.Error(1)
 }
 
+func (w *WalletServiceMock) GetAllWallets() ([]entity.Wallet, error) {
+	args := w.Called()
+	return args.Get(0).([]entity.Wallet), args.Error(1)
+}
+
+func (w *WalletServiceMock) GetAvailableBalance(id string) (float64, error) {
+	args := w.Called(id)
+	return args.Get(0).(float64), args.Error(1)
+}
+
 func (w *WalletServiceMock) UpdateWallet(id string, balance float64) error {
 	args := w.Called(id, balance)
 	return args.Error(0)
 }
+
+func (w *WalletServiceMock) FreezeWallet(id string) error {
+	args := w.Called(id)
+	return args.Error(0)
+}
+
+func (w *WalletServiceMock) UnfreezeWallet(id string) error {
+	args := w.Called(id)
+	return args.Error(0)
+}
+
+func (w *WalletServiceMock) CloseWallet(id string) error {
+	args := w.Called(id)
+	return args.Error(0)
+}
+
+func (w *WalletServiceMock) CheckEventCommitted(outboxEvent entity.OutboxEvent) (interface{}, bool, error) {
+	args := w.Called(outboxEvent)
+	return args.Get(0), args.Bool(1), args.Error(2)
+}
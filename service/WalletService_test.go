@@ -3,16 +3,19 @@ package service
 import (
 	"PaymentAPI/constants"
 	"PaymentAPI/entity"
+	"PaymentAPI/enums"
 	"PaymentAPI/repository"
 	"errors"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"testing"
+	"time"
 )
 
 func TestCreateWallet(t *testing.T) {
 	t.Run("ShouldCreateWallet", func(t *testing.T) {
 		mockWalletRepository := new(repository.WalletRepositoryMock)
-		walletService := NewWalletService(mockWalletRepository)
+		walletService := NewWalletService(mockWalletRepository, new(repository.HoldRepositoryMock), newEventBusMock())
 
 		customerId := "customer-1"
 
@@ -25,7 +28,7 @@ func TestCreateWallet(t *testing.T) {
 
 	t.Run("ShouldReturnError", func(t *testing.T) {
 		mockWalletRepository := new(repository.WalletRepositoryMock)
-		walletService := NewWalletService(mockWalletRepository)
+		walletService := NewWalletService(mockWalletRepository, new(repository.HoldRepositoryMock), newEventBusMock())
 
 		customerId := "customer-1"
 		mockWalletRepository.Mock.On("Create", customerId).
@@ -39,7 +42,7 @@ func TestCreateWallet(t *testing.T) {
 func TestGetWallet(t *testing.T) {
 	t.Run("ShouldGetWallet", func(t *testing.T) {
 		mockWalletRepository := new(repository.WalletRepositoryMock)
-		walletService := NewWalletService(mockWalletRepository)
+		walletService := NewWalletService(mockWalletRepository, new(repository.HoldRepositoryMock), newEventBusMock())
 
 		customerId := "customer-1"
 
@@ -59,7 +62,7 @@ func TestGetWallet(t *testing.T) {
 
 	t.Run("ShouldReturnError", func(t *testing.T) {
 		mockWalletRepository := new(repository.WalletRepositoryMock)
-		walletService := NewWalletService(mockWalletRepository)
+		walletService := NewWalletService(mockWalletRepository, new(repository.HoldRepositoryMock), newEventBusMock())
 
 		customerId := "customer-1"
 
@@ -75,7 +78,7 @@ func TestGetWallet(t *testing.T) {
 func TestUpdateWallet(t *testing.T) {
 	t.Run("ShouldUpdateWallet", func(t *testing.T) {
 		mockWalletRepository := new(repository.WalletRepositoryMock)
-		walletService := NewWalletService(mockWalletRepository)
+		walletService := NewWalletService(mockWalletRepository, new(repository.HoldRepositoryMock), newEventBusMock())
 
 		walletId := "wallet-1"
 		var balance float64 = 5000
@@ -89,7 +92,7 @@ func TestUpdateWallet(t *testing.T) {
 
 	t.Run("ShouldReturnError", func(t *testing.T) {
 		mockWalletRepository := new(repository.WalletRepositoryMock)
-		walletService := NewWalletService(mockWalletRepository)
+		walletService := NewWalletService(mockWalletRepository, new(repository.HoldRepositoryMock), newEventBusMock())
 
 		walletId := "wallet-1"
 		var balance float64 = 5000
@@ -101,3 +104,139 @@ func TestUpdateWallet(t *testing.T) {
 		assert.Equal(t, constants.WalletNotFoundError, err.Error())
 	})
 }
+
+func TestFreezeWallet(t *testing.T) {
+	tests := []struct {
+		name          string
+		status        enums.WalletStatus
+		expectedError string
+	}{
+		{name: "Should Freeze Active Wallet", status: enums.WALLET_ACTIVE},
+		{name: "Should Fail For Frozen Wallet", status: enums.WALLET_FROZEN, expectedError: constants.WalletAlreadyFrozenError},
+		{name: "Should Fail For Closed Wallet", status: enums.WALLET_CLOSED, expectedError: constants.WalletAlreadyClosedError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockWalletRepository := new(repository.WalletRepositoryMock)
+			walletService := NewWalletService(mockWalletRepository, new(repository.HoldRepositoryMock), newEventBusMock())
+
+			mockWalletRepository.Mock.On("GetById", "wallet-1").
+				Return(entity.Wallet{Id: "wallet-1", Status: tt.status}, nil)
+			mockWalletRepository.Mock.On("UpdateStatus", "wallet-1", enums.WALLET_FROZEN).
+				Return(nil)
+
+			err := walletService.FreezeWallet("wallet-1")
+			if tt.expectedError != "" {
+				assert.Equal(t, tt.expectedError, err.Error())
+				mockWalletRepository.Mock.AssertNotCalled(t, "UpdateStatus", "wallet-1", enums.WALLET_FROZEN)
+			} else {
+				assert.Nil(t, err)
+				mockWalletRepository.Mock.AssertExpectations(t)
+			}
+		})
+	}
+
+	t.Run("ShouldNotFreezeWhenEventCannotBeRecorded", func(t *testing.T) {
+		mockWalletRepository := new(repository.WalletRepositoryMock)
+		mockEventBus := new(EventBusMock)
+		walletService := NewWalletService(mockWalletRepository, new(repository.HoldRepositoryMock), mockEventBus)
+
+		mockWalletRepository.Mock.On("GetById", "wallet-1").
+			Return(entity.Wallet{Id: "wallet-1", Status: enums.WALLET_ACTIVE}, nil)
+		mockEventBus.On("Prepare", []enums.EventType{enums.EVENT_WALLET_FROZEN}, []string{"wallet-1"}, mock.Anything).
+			Return(nil, errors.New("disk full"))
+
+		err := walletService.FreezeWallet("wallet-1")
+		assert.Equal(t, "disk full", err.Error())
+		mockWalletRepository.Mock.AssertNotCalled(t, "UpdateStatus", "wallet-1", enums.WALLET_FROZEN)
+	})
+}
+
+func TestCheckWalletEventCommitted(t *testing.T) {
+	mockWalletRepository := new(repository.WalletRepositoryMock)
+	walletService := NewWalletService(mockWalletRepository, new(repository.HoldRepositoryMock), newEventBusMock())
+
+	stored := entity.Wallet{Id: "wallet-1", Status: enums.WALLET_FROZEN}
+	mockWalletRepository.Mock.On("GetById", "wallet-1").Return(stored, nil)
+
+	data, committed, err := walletService.CheckEventCommitted(entity.OutboxEvent{Payload: []byte(`{"id":"wallet-1","status":"FROZEN"}`)})
+	assert.Nil(t, err)
+	assert.True(t, committed)
+	assert.Equal(t, stored, data)
+
+	// A close that was never stored leaves the wallet in its previous status
+	_, committed, err = walletService.CheckEventCommitted(entity.OutboxEvent{Payload: []byte(`{"id":"wallet-1","status":"CLOSED"}`)})
+	assert.Nil(t, err)
+	assert.False(t, committed)
+}
+
+func TestCloseWallet(t *testing.T) {
+	tests := []struct {
+		name          string
+		wallet        entity.Wallet
+		holds         []entity.Hold
+		expectedError string
+	}{
+		{name: "Should Close Empty Wallet", wallet: entity.Wallet{Id: "wallet-1", Status: enums.WALLET_FROZEN}},
+		{
+			name:   "Should Close Wallet With Expired Hold",
+			wallet: entity.Wallet{Id: "wallet-1", Status: enums.WALLET_ACTIVE},
+			holds:  []entity.Hold{{Id: "hold-1", WalletId: "wallet-1", Amount: 3000, Status: enums.HOLD_ACTIVE, ExpiresAt: time.Now().Add(-time.Hour).Format(time.RFC3339)}},
+		},
+		{name: "Should Fail With Remaining Balance", wallet: entity.Wallet{Id: "wallet-1", Balance: 5000, Status: enums.WALLET_ACTIVE}, expectedError: constants.WalletCloseBalanceError},
+		{
+			name:          "Should Fail With Active Hold",
+			wallet:        entity.Wallet{Id: "wallet-1", Balance: 3000, Status: enums.WALLET_ACTIVE},
+			holds:         []entity.Hold{{Id: "hold-1", WalletId: "wallet-1", Amount: 3000, Status: enums.HOLD_ACTIVE, ExpiresAt: time.Now().Add(time.Hour).Format(time.RFC3339)}},
+			expectedError: constants.WalletCloseActiveHoldsError,
+		},
+		{name: "Should Fail For Closed Wallet", wallet: entity.Wallet{Id: "wallet-1", Status: enums.WALLET_CLOSED}, expectedError: constants.WalletAlreadyClosedError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockWalletRepository := new(repository.WalletRepositoryMock)
+			mockHoldRepository := new(repository.HoldRepositoryMock)
+			walletService := NewWalletService(mockWalletRepository, mockHoldRepository, newEventBusMock())
+
+			mockWalletRepository.Mock.On("GetById", "wallet-1").
+				Return(tt.wallet, nil)
+			mockHoldRepository.Mock.On("GetByWalletId", "wallet-1").
+				Return(append([]entity.Hold{}, tt.holds...), nil)
+			mockWalletRepository.Mock.On("UpdateStatus", "wallet-1", enums.WALLET_CLOSED).
+				Return(nil)
+
+			err := walletService.CloseWallet("wallet-1")
+			if tt.expectedError != "" {
+				assert.Equal(t, tt.expectedError, err.Error())
+				mockWalletRepository.Mock.AssertNotCalled(t, "UpdateStatus", "wallet-1", enums.WALLET_CLOSED)
+			} else {
+				assert.Nil(t, err)
+				mockWalletRepository.Mock.AssertExpectations(t)
+			}
+		})
+	}
+}
+
+func TestGetAvailableBalance(t *testing.T) {
+	mockWalletRepository := new(repository.WalletRepositoryMock)
+	mockHoldRepository := new(repository.HoldRepositoryMock)
+	walletService := NewWalletService(mockWalletRepository, mockHoldRepository, newEventBusMock())
+
+	future := time.Now().Add(time.Hour).Format(time.RFC3339)
+	past := time.Now().Add(-time.Hour).Format(time.RFC3339)
+
+	mockWalletRepository.Mock.On("GetById", "wallet-1").
+		Return(entity.Wallet{Id: "wallet-1", Balance: 10000, Status: enums.WALLET_ACTIVE}, nil)
+	mockHoldRepository.Mock.On("GetByWalletId", "wallet-1").
+		Return([]entity.Hold{
+			{Id: "hold-1", WalletId: "wallet-1", Amount: 3000, Status: enums.HOLD_ACTIVE, ExpiresAt: future},
+			{Id: "hold-2", WalletId: "wallet-1", Amount: 2000, Status: enums.HOLD_ACTIVE, ExpiresAt: past},
+			{Id: "hold-3", WalletId: "wallet-1", Amount: 1000, Status: enums.HOLD_CAPTURED, ExpiresAt: future},
+		}, nil)
+
+	available, err := walletService.GetAvailableBalance("wallet-1")
+	assert.Nil(t, err)
+	assert.Equal(t, float64(7000), available)
+}
@@ -0,0 +1,581 @@
+package service
+
+import (
+	"PaymentAPI/config"
+	"PaymentAPI/constants"
+	req "PaymentAPI/dto/request"
+	"PaymentAPI/entity"
+	"PaymentAPI/enums"
+	"PaymentAPI/repository"
+	"PaymentAPI/utils"
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// webhookPollInterval is how often pending deliveries are checked when no new event wakes the dispatcher up
+const webhookPollInterval = 5 * time.Second
+
+// webhookDeliveryConcurrency is how many deliveries of a round are attempted at once
+const webhookDeliveryConcurrency = 8
+
+// sharedAddressSpace is the carrier-grade NAT range, not public although it is not a private range either
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+type WebhookService interface {
+	CreateEndpoint(customerId string, request req.CreateWebhookEndpointRequest) (entity.WebhookEndpoint, error)
+	CreateCallbackEndpoint(customerId string, url string, events []enums.EventType) (entity.WebhookEndpoint, error)
+	GetEndpoints(customerId string) ([]entity.WebhookEndpoint, error)
+	GetEndpointById(id string) (entity.WebhookEndpoint, error)
+	DeleteEndpoint(id string) error
+	GetDeliveries(endpointId string) ([]entity.WebhookDelivery, error)
+	GetDeliveryById(id string) (entity.WebhookDelivery, error)
+	Redeliver(id string) (entity.WebhookDelivery, error)
+	HandleEvent(outboxEvent entity.OutboxEvent) error
+	Enqueue(endpointId string, eventType enums.EventType, data interface{}) error
+	DeliverPending() error
+	RunDelivery()
+}
+
+type webhookService struct {
+	webhookEndpointRepository repository.WebhookEndpointRepository
+	webhookDeliveryRepository repository.WebhookDeliveryRepository
+	walletRepository          repository.WalletRepository
+	client                    *http.Client
+
+	// wakeup starts a delivery round as soon as an event is handled, instead of waiting for the next poll
+	wakeup chan struct{}
+	// inFlight holds the deliveries being attempted, so the dispatcher and a manual redelivery never attempt one at
+	// once. Only claiming and releasing a delivery is done under deliverLock, not the attempt itself.
+	inFlight    map[string]bool
+	deliverLock sync.Mutex
+	// storeLock keeps recording new deliveries and updating attempted ones from overwriting each other
+	storeLock sync.Mutex
+}
+
+// NewWebhookService creates a new instance of WebhookService
+func NewWebhookService(webhookEndpointRepository repository.WebhookEndpointRepository, webhookDeliveryRepository repository.WebhookDeliveryRepository, walletRepository repository.WalletRepository) WebhookService {
+	return &webhookService{
+		webhookEndpointRepository: webhookEndpointRepository,
+		webhookDeliveryRepository: webhookDeliveryRepository,
+		walletRepository:          walletRepository,
+		client: &http.Client{
+			Timeout: config.WebhookDeliveryTimeout,
+			// Every dialed address is checked, a host may resolve differently than when its endpoint was registered
+			Transport: &http.Transport{
+				DialContext: (&net.Dialer{Timeout: config.WebhookDeliveryTimeout, Control: checkDialedAddress}).DialContext,
+			},
+		},
+		wakeup:   make(chan struct{}, 1),
+		inFlight: map[string]bool{},
+	}
+}
+
+// CreateEndpoint registers a URL that receives the given events of the customer's wallets, all events when none are given.
+// The endpoint gets its own secret to sign deliveries with.
+func (w *webhookService) CreateEndpoint(customerId string, request req.CreateWebhookEndpointRequest) (entity.WebhookEndpoint, error) {
+	events := request.Events
+	if len(events) == 0 {
+		events = enums.EventTypes
+	}
+	for _, event := range events {
+		if !isEventType(event) {
+			return entity.WebhookEndpoint{}, errors.New(constants.WebhookEventTypeError)
+		}
+	}
+
+	return w.createEndpoint(customerId, request.Url, events)
+}
+
+// CreateCallbackEndpoint registers a URL that only receives the deliveries enqueued for it, such as the checkout
+// events sent to a merchant's callback. The events it is created with are not recorded in the outbox.
+func (w *webhookService) CreateCallbackEndpoint(customerId string, url string, events []enums.EventType) (entity.WebhookEndpoint, error) {
+	return w.createEndpoint(customerId, url, events)
+}
+
+func (w *webhookService) createEndpoint(customerId string, url string, events []enums.EventType) (entity.WebhookEndpoint, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"customerId": customerId,
+		"url":        url,
+	})
+
+	logger.Info("Creating new webhook endpoint")
+
+	if !isCallbackUrl(url) {
+		return entity.WebhookEndpoint{}, errors.New(constants.WebhookEndpointUrlError)
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		logger.Error("Failed to generate webhook secret", err)
+		return entity.WebhookEndpoint{}, err
+	}
+
+	webhookEndpoint := entity.WebhookEndpoint{
+		Id:         uuid.New().String(),
+		CustomerId: customerId,
+		Url:        url,
+		Secret:     "whsec_" + hex.EncodeToString(secret),
+		Events:     events,
+		CreatedAt:  time.Now().Format(time.RFC3339),
+	}
+
+	if err := w.webhookEndpointRepository.Create(webhookEndpoint); err != nil {
+		logger.Error("Failed to create webhook endpoint", err)
+		return entity.WebhookEndpoint{}, err
+	}
+
+	logger.Info("Webhook endpoint created successfully")
+	return webhookEndpoint, nil
+}
+
+// GetEndpoints returns the webhook endpoints registered by the customer
+func (w *webhookService) GetEndpoints(customerId string) ([]entity.WebhookEndpoint, error) {
+	webhookEndpoints, err := w.webhookEndpointRepository.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := []entity.WebhookEndpoint{}
+	for _, webhookEndpoint := range webhookEndpoints {
+		if webhookEndpoint.CustomerId == customerId {
+			filtered = append(filtered, webhookEndpoint)
+		}
+	}
+	return filtered, nil
+}
+
+// GetEndpointById retrieves a webhook endpoint by its ID
+func (w *webhookService) GetEndpointById(id string) (entity.WebhookEndpoint, error) {
+	return w.webhookEndpointRepository.GetById(id)
+}
+
+// DeleteEndpoint removes a webhook endpoint, its pending deliveries fail on their next attempt
+func (w *webhookService) DeleteEndpoint(id string) error {
+	return w.webhookEndpointRepository.Delete(id)
+}
+
+// GetDeliveries returns the delivery log of a webhook endpoint
+func (w *webhookService) GetDeliveries(endpointId string) ([]entity.WebhookDelivery, error) {
+	webhookDeliveries, err := w.webhookDeliveryRepository.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := []entity.WebhookDelivery{}
+	for _, webhookDelivery := range webhookDeliveries {
+		if webhookDelivery.EndpointId == endpointId {
+			filtered = append(filtered, webhookDelivery)
+		}
+	}
+	return filtered, nil
+}
+
+// GetDeliveryById retrieves a webhook delivery by its ID
+func (w *webhookService) GetDeliveryById(id string) (entity.WebhookDelivery, error) {
+	return w.webhookDeliveryRepository.GetById(id)
+}
+
+// Redeliver sends a delivery again right away with the same event, whatever its current status is. A delivery that
+// is being attempted already is refused.
+func (w *webhookService) Redeliver(id string) (entity.WebhookDelivery, error) {
+	if !w.claim(id) {
+		return entity.WebhookDelivery{}, errors.New(constants.WebhookDeliveryInProgressError)
+	}
+	defer w.release(id)
+
+	webhookDelivery, err := w.webhookDeliveryRepository.GetById(id)
+	if err != nil {
+		return entity.WebhookDelivery{}, err
+	}
+
+	webhookDelivery.Status = enums.WEBHOOK_DELIVERY_PENDING
+	return w.attempt(webhookDelivery)
+}
+
+// HandleEvent records a delivery of the event for every endpoint of the owners of its wallets that subscribes to it.
+// Wallet IDs that are not wallets of this system, such as an external bank, are skipped. An event handled again
+// after a failure or restart skips the endpoints it was already recorded for, so each endpoint gets one delivery.
+func (w *webhookService) HandleEvent(outboxEvent entity.OutboxEvent) error {
+	logger := logrus.WithFields(logrus.Fields{
+		"outboxEventId": outboxEvent.Id,
+		"eventType":     outboxEvent.Type,
+	})
+
+	customerIds := map[string]bool{}
+	for _, walletId := range outboxEvent.WalletIds {
+		if wallet, err := w.walletRepository.GetById(walletId); err == nil {
+			customerIds[wallet.CustomerId] = true
+		}
+	}
+	if len(customerIds) == 0 {
+		return nil
+	}
+
+	webhookEndpoints, err := w.webhookEndpointRepository.GetAll()
+	if err != nil {
+		logger.Error("Failed to retrieve webhook endpoints", err)
+		return err
+	}
+
+	webhookDeliveries, err := w.webhookDeliveryRepository.GetAll()
+	if err != nil {
+		logger.Error("Failed to retrieve webhook deliveries", err)
+		return err
+	}
+	recordedFor := map[string]bool{}
+	for _, webhookDelivery := range webhookDeliveries {
+		if webhookDelivery.EventId == outboxEvent.Id {
+			recordedFor[webhookDelivery.EndpointId] = true
+		}
+	}
+
+	// The event ID is the outbox event ID, so a receiver can tell a redelivered event from a new one
+	payload, err := json.Marshal(entity.WebhookEvent{
+		Id:        outboxEvent.Id,
+		Type:      outboxEvent.Type,
+		CreatedAt: outboxEvent.CreatedAt,
+		Data:      outboxEvent.Payload,
+	})
+	if err != nil {
+		logger.Error("Failed to encode webhook event", err)
+		return err
+	}
+
+	now := time.Now().Format(time.RFC3339)
+	recorded := false
+	for _, webhookEndpoint := range webhookEndpoints {
+		if !customerIds[webhookEndpoint.CustomerId] || !subscribesTo(webhookEndpoint, outboxEvent.Type) || recordedFor[webhookEndpoint.Id] {
+			continue
+		}
+
+		webhookDelivery := entity.WebhookDelivery{
+			Id:            uuid.New().String(),
+			EndpointId:    webhookEndpoint.Id,
+			EventId:       outboxEvent.Id,
+			EventType:     outboxEvent.Type,
+			Payload:       payload,
+			Status:        enums.WEBHOOK_DELIVERY_PENDING,
+			NextAttemptAt: now,
+			CreatedAt:     now,
+		}
+		w.storeLock.Lock()
+		err := w.webhookDeliveryRepository.Create(webhookDelivery)
+		w.storeLock.Unlock()
+		if err != nil {
+			logger.Error("Failed to record webhook delivery", err)
+			return err
+		}
+		recorded = true
+	}
+
+	if recorded {
+		w.wake()
+	}
+	return nil
+}
+
+// Enqueue records a delivery of a new event to a single endpoint, for an event that is not recorded in the outbox,
+// such as a checkout session sent to the callback of its merchant. It is signed and retried like any other delivery.
+func (w *webhookService) Enqueue(endpointId string, eventType enums.EventType, data interface{}) error {
+	logger := logrus.WithFields(logrus.Fields{
+		"endpointId": endpointId,
+		"eventType":  eventType,
+	})
+
+	body, err := json.Marshal(data)
+	if err != nil {
+		logger.Error("Failed to encode webhook event data", err)
+		return err
+	}
+
+	now := time.Now().Format(time.RFC3339)
+	webhookEvent := entity.WebhookEvent{
+		Id:        uuid.New().String(),
+		Type:      eventType,
+		CreatedAt: now,
+		Data:      body,
+	}
+	payload, err := json.Marshal(webhookEvent)
+	if err != nil {
+		logger.Error("Failed to encode webhook event", err)
+		return err
+	}
+
+	webhookDelivery := entity.WebhookDelivery{
+		Id:            uuid.New().String(),
+		EndpointId:    endpointId,
+		EventId:       webhookEvent.Id,
+		EventType:     eventType,
+		Payload:       payload,
+		Status:        enums.WEBHOOK_DELIVERY_PENDING,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	}
+	w.storeLock.Lock()
+	err = w.webhookDeliveryRepository.Create(webhookDelivery)
+	w.storeLock.Unlock()
+	if err != nil {
+		logger.Error("Failed to record webhook delivery", err)
+		return err
+	}
+
+	w.wake()
+	return nil
+}
+
+// DeliverPending attempts every pending delivery whose next attempt is due. Deliveries are attempted concurrently, so
+// a slow endpoint does not hold up the others, and one that cannot be recorded does not stop the rest of the round.
+func (w *webhookService) DeliverPending() error {
+	webhookDeliveries, err := w.webhookDeliveryRepository.GetAll()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var wg sync.WaitGroup
+	var errorsLock sync.Mutex
+	var deliveryErrors []error
+	slots := make(chan struct{}, webhookDeliveryConcurrency)
+	for _, webhookDelivery := range webhookDeliveries {
+		if !isDue(webhookDelivery, now) {
+			continue
+		}
+
+		wg.Add(1)
+		slots <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			if err := w.deliver(webhookDelivery.Id, now); err != nil {
+				errorsLock.Lock()
+				deliveryErrors = append(deliveryErrors, err)
+				errorsLock.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return errors.Join(deliveryErrors...)
+}
+
+// deliver attempts a due delivery unless it is being attempted already. It is read again once claimed, as a manual
+// redelivery may have attempted it since the round started.
+func (w *webhookService) deliver(id string, now time.Time) error {
+	if !w.claim(id) {
+		return nil
+	}
+	defer w.release(id)
+
+	webhookDelivery, err := w.webhookDeliveryRepository.GetById(id)
+	if err != nil {
+		return err
+	}
+	if !isDue(webhookDelivery, now) {
+		return nil
+	}
+	_, err = w.attempt(webhookDelivery)
+	return err
+}
+
+// claim marks the delivery as being attempted, false when it is already
+func (w *webhookService) claim(id string) bool {
+	w.deliverLock.Lock()
+	defer w.deliverLock.Unlock()
+
+	if w.inFlight[id] {
+		return false
+	}
+	w.inFlight[id] = true
+	return true
+}
+
+// release marks the delivery as no longer being attempted
+func (w *webhookService) release(id string) {
+	w.deliverLock.Lock()
+	defer w.deliverLock.Unlock()
+
+	delete(w.inFlight, id)
+}
+
+// RunDelivery delivers pending webhooks as soon as they are recorded and retries failed ones when they are due
+func (w *webhookService) RunDelivery() {
+	ticker := time.NewTicker(webhookPollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := w.DeliverPending(); err != nil {
+			logrus.Error("Failed to deliver pending webhooks", err)
+		}
+
+		select {
+		case <-w.wakeup:
+		case <-ticker.C:
+		}
+	}
+}
+
+// wake starts a delivery round unless one is already due
+func (w *webhookService) wake() {
+	select {
+	case w.wakeup <- struct{}{}:
+	default:
+	}
+}
+
+// attempt posts the delivery to its endpoint once and records the result. A failed attempt is retried with an
+// exponential backoff until the maximum number of attempts is reached.
+func (w *webhookService) attempt(webhookDelivery entity.WebhookDelivery) (entity.WebhookDelivery, error) {
+	logger := logrus.WithFields(logrus.Fields{
+		"webhookDeliveryId": webhookDelivery.Id,
+		"endpointId":        webhookDelivery.EndpointId,
+		"eventType":         webhookDelivery.EventType,
+	})
+
+	now := time.Now()
+	webhookDelivery.Attempts++
+	webhookDelivery.LastAttemptAt = now.Format(time.RFC3339)
+
+	responseStatus, err := w.post(webhookDelivery, now)
+	webhookDelivery.ResponseStatus = responseStatus
+	switch {
+	case err == nil:
+		webhookDelivery.Status = enums.WEBHOOK_DELIVERY_DELIVERED
+		webhookDelivery.ErrorMessage = ""
+		webhookDelivery.NextAttemptAt = ""
+		webhookDelivery.DeliveredAt = now.Format(time.RFC3339)
+		logger.Info("Webhook delivered")
+	case webhookDelivery.Attempts >= config.WebhookMaxAttempts:
+		webhookDelivery.Status = enums.WEBHOOK_DELIVERY_FAILED
+		webhookDelivery.ErrorMessage = err.Error()
+		webhookDelivery.NextAttemptAt = ""
+		logger.Error("Webhook delivery failed, giving up", err)
+	default:
+		webhookDelivery.ErrorMessage = err.Error()
+		webhookDelivery.NextAttemptAt = now.Add(webhookRetryDelay(webhookDelivery.Attempts)).Format(time.RFC3339)
+		logger.Warnf("Webhook delivery failed, retrying at %s: %v", webhookDelivery.NextAttemptAt, err)
+	}
+
+	w.storeLock.Lock()
+	defer w.storeLock.Unlock()
+	if err := w.webhookDeliveryRepository.Update(webhookDelivery); err != nil {
+		logger.Error("Failed to update webhook delivery", err)
+		return entity.WebhookDelivery{}, err
+	}
+	return webhookDelivery, nil
+}
+
+// post sends the signed payload to the endpoint, a response outside 2xx counts as a failure
+func (w *webhookService) post(webhookDelivery entity.WebhookDelivery, now time.Time) (int, error) {
+	webhookEndpoint, err := w.webhookEndpointRepository.GetById(webhookDelivery.EndpointId)
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	request, err := http.NewRequest(http.MethodPost, webhookEndpoint.Url, bytes.NewReader(webhookDelivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("X-Webhook-Id", webhookDelivery.EventId)
+	request.Header.Set("X-Webhook-Delivery", webhookDelivery.Id)
+	request.Header.Set("X-Webhook-Event", string(webhookDelivery.EventType))
+	request.Header.Set("X-Webhook-Timestamp", timestamp)
+	request.Header.Set("X-Webhook-Signature", utils.SignWebhookPayload(webhookEndpoint.Secret, timestamp, webhookDelivery.Payload))
+
+	response, err := w.client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return response.StatusCode, fmt.Errorf("endpoint responded with status %d", response.StatusCode)
+	}
+	return response.StatusCode, nil
+}
+
+// webhookRetryDelay doubles the base delay for every failed attempt: 30s, 1m, 2m, 4m and so on with the default base
+func webhookRetryDelay(attempts int) time.Duration {
+	return config.WebhookRetryBaseDelay << (attempts - 1)
+}
+
+func isDue(webhookDelivery entity.WebhookDelivery, now time.Time) bool {
+	if webhookDelivery.Status != enums.WEBHOOK_DELIVERY_PENDING {
+		return false
+	}
+	nextAttemptAt, err := time.Parse(time.RFC3339, webhookDelivery.NextAttemptAt)
+	return err != nil || !now.Before(nextAttemptAt)
+}
+
+// isPublicHost resolves the host of a webhook or callback URL and accepts it when every address is public. A host
+// that does not resolve yet is accepted, as every address is checked again when a delivery dials it.
+func isPublicHost(host string) bool {
+	if config.WebhookAllowPrivateUrls {
+		return true
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		return isPublicIP(ip)
+	}
+
+	ips, err := net.LookupIP(host)
+	if err != nil {
+		return true
+	}
+	for _, ip := range ips {
+		if !isPublicIP(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// isPublicIP refuses loopback, private, link-local (including the cloud metadata address), shared, multicast and
+// unspecified addresses
+func isPublicIP(ip net.IP) bool {
+	return ip.IsGlobalUnicast() && !ip.IsPrivate() && !sharedAddressSpace.Contains(ip)
+}
+
+// checkDialedAddress refuses to connect a webhook delivery to an address that is not public
+func checkDialedAddress(network string, address string, _ syscall.RawConn) error {
+	if config.WebhookAllowPrivateUrls {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || !isPublicIP(ip) {
+		return errors.New(constants.WebhookAddressNotPublicError)
+	}
+	return nil
+}
+
+func subscribesTo(webhookEndpoint entity.WebhookEndpoint, eventType enums.EventType) bool {
+	for _, event := range webhookEndpoint.Events {
+		if event == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+func isEventType(eventType enums.EventType) bool {
+	for _, known := range enums.EventTypes {
+		if known == eventType {
+			return true
+		}
+	}
+	return false
+}
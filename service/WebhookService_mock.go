@@ -0,0 +1,71 @@
+package service
+
+import (
+	req "PaymentAPI/dto/request"
+	"PaymentAPI/entity"
+	"PaymentAPI/enums"
+	"github.com/stretchr/testify/mock"
+)
+
+type WebhookServiceMock struct {
+	mock.Mock
+}
+
+func (w *WebhookServiceMock) CreateEndpoint(customerId string, request req.CreateWebhookEndpointRequest) (entity.WebhookEndpoint, error) {
+	args := w.Called(customerId, request)
+	return args.Get(0).(entity.WebhookEndpoint), args.Error(1)
+}
+
+func (w *WebhookServiceMock) CreateCallbackEndpoint(customerId string, url string, events []enums.EventType) (entity.WebhookEndpoint, error) {
+	args := w.Called(customerId, url, events)
+	return args.Get(0).(entity.WebhookEndpoint), args.Error(1)
+}
+
+func (w *WebhookServiceMock) GetEndpoints(customerId string) ([]entity.WebhookEndpoint, error) {
+	args := w.Called(customerId)
+	return args.Get(0).([]entity.WebhookEndpoint), args.Error(1)
+}
+
+func (w *WebhookServiceMock) GetEndpointById(id string) (entity.WebhookEndpoint, error) {
+	args := w.Called(id)
+	return args.Get(0).(entity.WebhookEndpoint), args.Error(1)
+}
+
+func (w *WebhookServiceMock) DeleteEndpoint(id string) error {
+	args := w.Called(id)
+	return args.Error(0)
+}
+
+func (w *WebhookServiceMock) GetDeliveries(endpointId string) ([]entity.WebhookDelivery, error) {
+	args := w.Called(endpointId)
+	return args.Get(0).([]entity.WebhookDelivery), args.Error(1)
+}
+
+func (w *WebhookServiceMock) GetDeliveryById(id string) (entity.WebhookDelivery, error) {
+	args := w.Called(id)
+	return args.Get(0).(entity.WebhookDelivery), args.Error(1)
+}
+
+func (w *WebhookServiceMock) Redeliver(id string) (entity.WebhookDelivery, error) {
+	args := w.Called(id)
+	return args.Get(0).(entity.WebhookDelivery), args.Error(1)
+}
+
+func (w *WebhookServiceMock) HandleEvent(outboxEvent entity.OutboxEvent) error {
+	args := w.Called(outboxEvent)
+	return args.Error(0)
+}
+
+func (w *WebhookServiceMock) Enqueue(endpointId string, eventType enums.EventType, data interface{}) error {
+	args := w.Called(endpointId, eventType, data)
+	return args.Error(0)
+}
+
+func (w *WebhookServiceMock) DeliverPending() error {
+	args := w.Called()
+	return args.Error(0)
+}
+
+func (w *WebhookServiceMock) RunDelivery() {
+	w.Called()
+}
@@ -0,0 +1,412 @@
+package service
+
+import (
+	"PaymentAPI/config"
+	"PaymentAPI/constants"
+	req "PaymentAPI/dto/request"
+	"PaymentAPI/entity"
+	"PaymentAPI/enums"
+	"PaymentAPI/repository"
+	"PaymentAPI/utils"
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type webhookTest struct {
+	mockWebhookEndpointRepository *repository.WebhookEndpointRepositoryMock
+	mockWebhookDeliveryRepository *repository.WebhookDeliveryRepositoryMock
+	mockWalletRepository          *repository.WalletRepositoryMock
+	service                       WebhookService
+}
+
+func setupWebhookTest() webhookTest {
+	config.WebhookMaxAttempts = 3
+	config.WebhookRetryBaseDelay = time.Minute
+	// The receivers of the tests listen on the loopback address
+	config.WebhookAllowPrivateUrls = true
+
+	mockWebhookEndpointRepository := new(repository.WebhookEndpointRepositoryMock)
+	mockWebhookDeliveryRepository := new(repository.WebhookDeliveryRepositoryMock)
+	mockWalletRepository := new(repository.WalletRepositoryMock)
+	return webhookTest{
+		mockWebhookEndpointRepository: mockWebhookEndpointRepository,
+		mockWebhookDeliveryRepository: mockWebhookDeliveryRepository,
+		mockWalletRepository:          mockWalletRepository,
+		service:                       NewWebhookService(mockWebhookEndpointRepository, mockWebhookDeliveryRepository, mockWalletRepository),
+	}
+}
+
+func TestCreateWebhookEndpoint(t *testing.T) {
+	t.Run("ShouldSubscribeToAllEventsByDefault", func(t *testing.T) {
+		test := setupWebhookTest()
+		test.mockWebhookEndpointRepository.Mock.On("Create", mock.Anything).Return(nil)
+
+		webhookEndpoint, err := test.service.CreateEndpoint("customer-1", req.CreateWebhookEndpointRequest{Url: "https://example.com/hooks"})
+		assert.Nil(t, err)
+		assert.Equal(t, enums.EventTypes, webhookEndpoint.Events)
+		assert.True(t, strings.HasPrefix(webhookEndpoint.Secret, "whsec_"))
+	})
+
+	t.Run("ShouldRejectUnknownEvent", func(t *testing.T) {
+		test := setupWebhookTest()
+
+		_, err := test.service.CreateEndpoint("customer-1", req.CreateWebhookEndpointRequest{
+			Url:    "https://example.com/hooks",
+			Events: []enums.EventType{"transaction.deleted"},
+		})
+		assert.Equal(t, constants.WebhookEventTypeError, err.Error())
+		test.mockWebhookEndpointRepository.Mock.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	t.Run("ShouldRejectRelativeUrl", func(t *testing.T) {
+		test := setupWebhookTest()
+
+		_, err := test.service.CreateEndpoint("customer-1", req.CreateWebhookEndpointRequest{Url: "/hooks"})
+		assert.Equal(t, constants.WebhookEndpointUrlError, err.Error())
+	})
+
+	t.Run("ShouldRejectAddressThatIsNotPublic", func(t *testing.T) {
+		test := setupWebhookTest()
+		config.WebhookAllowPrivateUrls = false
+
+		for _, url := range []string{
+			"http://127.0.0.1:8080/hooks",
+			"http://localhost/hooks",
+			"http://10.0.0.5/hooks",
+			"http://169.254.169.254/latest/meta-data",
+			"http://100.100.100.200/hooks",
+			"http://[::1]/hooks",
+			"http://0.0.0.0/hooks",
+		} {
+			_, err := test.service.CreateEndpoint("customer-1", req.CreateWebhookEndpointRequest{Url: url})
+			assert.Equal(t, constants.WebhookEndpointUrlError, err.Error(), url)
+		}
+		test.mockWebhookEndpointRepository.Mock.AssertNotCalled(t, "Create", mock.Anything)
+	})
+}
+
+func TestCreateWebhookCallbackEndpoint(t *testing.T) {
+	test := setupWebhookTest()
+	test.mockWebhookEndpointRepository.Mock.On("Create", mock.Anything).Return(nil)
+
+	// Checkout events cannot be subscribed to, but a callback endpoint is created for them
+	webhookEndpoint, err := test.service.CreateCallbackEndpoint("customer-1", "https://shop.example/callback", enums.CheckoutEventTypes)
+	assert.Nil(t, err)
+	assert.Equal(t, enums.CheckoutEventTypes, webhookEndpoint.Events)
+	assert.True(t, strings.HasPrefix(webhookEndpoint.Secret, "whsec_"))
+}
+
+func TestHandleWebhookEvent(t *testing.T) {
+	outboxEvent := entity.OutboxEvent{
+		Id:        "event-1",
+		Type:      enums.EVENT_TRANSACTION_SETTLED,
+		WalletIds: []string{"EXTERNAL_BANK", "wallet-1"},
+		Payload:   []byte(`{"id":"transaction-1"}`),
+	}
+	setup := func() webhookTest {
+		test := setupWebhookTest()
+		test.mockWalletRepository.Mock.On("GetById", "wallet-1").Return(entity.Wallet{Id: "wallet-1", CustomerId: "customer-1"}, nil)
+		test.mockWalletRepository.Mock.On("GetById", "EXTERNAL_BANK").Return(entity.Wallet{}, errors.New(constants.WalletNotFoundError))
+		test.mockWebhookEndpointRepository.Mock.On("GetAll").Return([]entity.WebhookEndpoint{
+			{Id: "endpoint-1", CustomerId: "customer-1", Events: []enums.EventType{enums.EVENT_TRANSACTION_SETTLED}},
+			{Id: "endpoint-2", CustomerId: "customer-1", Events: []enums.EventType{enums.EVENT_WALLET_FROZEN}},
+			{Id: "endpoint-3", CustomerId: "customer-2", Events: enums.EventTypes},
+			{Id: "endpoint-4", CustomerId: "customer-1", Events: enums.EventTypes},
+		}, nil)
+		return test
+	}
+
+	t.Run("ShouldRecordDeliveryForSubscribedEndpoints", func(t *testing.T) {
+		test := setup()
+		test.mockWebhookDeliveryRepository.Mock.On("GetAll").Return([]entity.WebhookDelivery{}, nil)
+		test.mockWebhookDeliveryRepository.Mock.On("Create", mock.Anything).Return(nil)
+
+		err := test.service.HandleEvent(outboxEvent)
+		assert.Nil(t, err)
+		test.mockWebhookDeliveryRepository.Mock.AssertNumberOfCalls(t, "Create", 2)
+		test.mockWebhookDeliveryRepository.Mock.AssertCalled(t, "Create", mock.MatchedBy(func(webhookDelivery entity.WebhookDelivery) bool {
+			var event entity.WebhookEvent
+			json.Unmarshal(webhookDelivery.Payload, &event)
+			return webhookDelivery.EndpointId == "endpoint-1" &&
+				webhookDelivery.Status == enums.WEBHOOK_DELIVERY_PENDING &&
+				webhookDelivery.EventId == "event-1" &&
+				event.Id == "event-1" &&
+				event.Type == enums.EVENT_TRANSACTION_SETTLED &&
+				string(event.Data) == `{"id":"transaction-1"}`
+		}))
+	})
+
+	t.Run("ShouldSkipEndpointsAlreadyRecordedWhenHandledAgain", func(t *testing.T) {
+		test := setup()
+		test.mockWebhookDeliveryRepository.Mock.On("GetAll").Return([]entity.WebhookDelivery{
+			{Id: "delivery-1", EndpointId: "endpoint-1", EventId: "event-1"},
+		}, nil)
+		test.mockWebhookDeliveryRepository.Mock.On("Create", mock.Anything).Return(nil)
+
+		err := test.service.HandleEvent(outboxEvent)
+		assert.Nil(t, err)
+		test.mockWebhookDeliveryRepository.Mock.AssertNumberOfCalls(t, "Create", 1)
+		test.mockWebhookDeliveryRepository.Mock.AssertCalled(t, "Create", mock.MatchedBy(func(webhookDelivery entity.WebhookDelivery) bool {
+			return webhookDelivery.EndpointId == "endpoint-4"
+		}))
+	})
+
+	t.Run("ShouldFailWhenDeliveryCannotBeRecorded", func(t *testing.T) {
+		test := setup()
+		test.mockWebhookDeliveryRepository.Mock.On("GetAll").Return([]entity.WebhookDelivery{}, nil)
+		test.mockWebhookDeliveryRepository.Mock.On("Create", mock.Anything).Return(errors.New(constants.JsonFileNotFound))
+
+		err := test.service.HandleEvent(outboxEvent)
+		assert.Equal(t, constants.JsonFileNotFound, err.Error())
+	})
+}
+
+func TestEnqueueWebhook(t *testing.T) {
+	t.Run("ShouldRecordPendingDeliveryOfNewEvent", func(t *testing.T) {
+		test := setupWebhookTest()
+		test.mockWebhookDeliveryRepository.Mock.On("Create", mock.Anything).Return(nil)
+
+		err := test.service.Enqueue("endpoint-1", enums.EVENT_CHECKOUT_PAID, map[string]string{"id": "checkout-1"})
+		assert.Nil(t, err)
+		test.mockWebhookDeliveryRepository.Mock.AssertCalled(t, "Create", mock.MatchedBy(func(webhookDelivery entity.WebhookDelivery) bool {
+			var event entity.WebhookEvent
+			json.Unmarshal(webhookDelivery.Payload, &event)
+			return webhookDelivery.EndpointId == "endpoint-1" &&
+				webhookDelivery.Status == enums.WEBHOOK_DELIVERY_PENDING &&
+				webhookDelivery.EventId == event.Id &&
+				event.Type == enums.EVENT_CHECKOUT_PAID &&
+				string(event.Data) == `{"id":"checkout-1"}`
+		}))
+	})
+
+	t.Run("ShouldFailWhenDeliveryCannotBeRecorded", func(t *testing.T) {
+		test := setupWebhookTest()
+		test.mockWebhookDeliveryRepository.Mock.On("Create", mock.Anything).Return(errors.New(constants.JsonFileNotFound))
+
+		err := test.service.Enqueue("endpoint-1", enums.EVENT_CHECKOUT_PAID, map[string]string{"id": "checkout-1"})
+		assert.Equal(t, constants.JsonFileNotFound, err.Error())
+	})
+}
+
+func TestDeliverPendingWebhooks(t *testing.T) {
+	payload := []byte(`{"id":"event-1","type":"transaction.settled","created_at":"2024-10-19T10:00:00Z","data":{}}`)
+	pendingDelivery := func() entity.WebhookDelivery {
+		return entity.WebhookDelivery{
+			Id:            "delivery-1",
+			EndpointId:    "endpoint-1",
+			EventId:       "event-1",
+			EventType:     enums.EVENT_TRANSACTION_SETTLED,
+			Payload:       payload,
+			Status:        enums.WEBHOOK_DELIVERY_PENDING,
+			NextAttemptAt: time.Now().Add(-time.Second).Format(time.RFC3339),
+		}
+	}
+
+	t.Run("ShouldDeliverSignedPayload", func(t *testing.T) {
+		var verified bool
+		receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			verified = utils.VerifyWebhookSignature("whsec_test", r.Header.Get("X-Webhook-Timestamp"), body, r.Header.Get("X-Webhook-Signature")) &&
+				r.Header.Get("X-Webhook-Id") == "event-1" &&
+				string(body) == string(payload)
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer receiver.Close()
+
+		test := setupWebhookTest()
+		test.mockWebhookDeliveryRepository.Mock.On("GetAll").Return([]entity.WebhookDelivery{pendingDelivery()}, nil)
+		test.mockWebhookDeliveryRepository.Mock.On("GetById", "delivery-1").Return(pendingDelivery(), nil)
+		test.mockWebhookEndpointRepository.Mock.On("GetById", "endpoint-1").Return(entity.WebhookEndpoint{Id: "endpoint-1", Url: receiver.URL, Secret: "whsec_test"}, nil)
+		test.mockWebhookDeliveryRepository.Mock.On("Update", mock.MatchedBy(func(webhookDelivery entity.WebhookDelivery) bool {
+			return webhookDelivery.Status == enums.WEBHOOK_DELIVERY_DELIVERED && webhookDelivery.Attempts == 1 && webhookDelivery.ResponseStatus == http.StatusNoContent
+		})).Return(nil)
+
+		err := test.service.DeliverPending()
+		assert.Nil(t, err)
+		assert.True(t, verified)
+		test.mockWebhookDeliveryRepository.Mock.AssertExpectations(t)
+	})
+
+	t.Run("ShouldRetryWithExponentialBackoff", func(t *testing.T) {
+		receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer receiver.Close()
+
+		test := setupWebhookTest()
+		failedTwice := pendingDelivery()
+		failedTwice.Attempts = 1
+		test.mockWebhookDeliveryRepository.Mock.On("GetAll").Return([]entity.WebhookDelivery{failedTwice}, nil)
+		test.mockWebhookDeliveryRepository.Mock.On("GetById", "delivery-1").Return(failedTwice, nil)
+		test.mockWebhookEndpointRepository.Mock.On("GetById", "endpoint-1").Return(entity.WebhookEndpoint{Id: "endpoint-1", Url: receiver.URL, Secret: "whsec_test"}, nil)
+
+		var updated entity.WebhookDelivery
+		test.mockWebhookDeliveryRepository.Mock.On("Update", mock.Anything).Run(func(args mock.Arguments) {
+			updated = args.Get(0).(entity.WebhookDelivery)
+		}).Return(nil)
+
+		before := time.Now()
+		err := test.service.DeliverPending()
+		assert.Nil(t, err)
+		assert.Equal(t, enums.WEBHOOK_DELIVERY_PENDING, updated.Status)
+		assert.Equal(t, 2, updated.Attempts)
+		assert.Equal(t, http.StatusInternalServerError, updated.ResponseStatus)
+
+		// The second attempt failed, so the third waits twice the base delay
+		nextAttemptAt, _ := time.Parse(time.RFC3339, updated.NextAttemptAt)
+		assert.WithinDuration(t, before.Add(2*time.Minute), nextAttemptAt, 2*time.Second)
+	})
+
+	t.Run("ShouldGiveUpAfterMaxAttempts", func(t *testing.T) {
+		test := setupWebhookTest()
+		lastAttempt := pendingDelivery()
+		lastAttempt.Attempts = 2
+		test.mockWebhookDeliveryRepository.Mock.On("GetAll").Return([]entity.WebhookDelivery{lastAttempt}, nil)
+		test.mockWebhookDeliveryRepository.Mock.On("GetById", "delivery-1").Return(lastAttempt, nil)
+		test.mockWebhookEndpointRepository.Mock.On("GetById", "endpoint-1").Return(entity.WebhookEndpoint{}, errors.New(constants.WebhookEndpointNotFoundError))
+		test.mockWebhookDeliveryRepository.Mock.On("Update", mock.MatchedBy(func(webhookDelivery entity.WebhookDelivery) bool {
+			return webhookDelivery.Status == enums.WEBHOOK_DELIVERY_FAILED && webhookDelivery.ErrorMessage == constants.WebhookEndpointNotFoundError
+		})).Return(nil)
+
+		err := test.service.DeliverPending()
+		assert.Nil(t, err)
+		test.mockWebhookDeliveryRepository.Mock.AssertExpectations(t)
+	})
+
+	t.Run("ShouldSkipDeliveryNotDueYet", func(t *testing.T) {
+		test := setupWebhookTest()
+		notDue := pendingDelivery()
+		notDue.NextAttemptAt = time.Now().Add(time.Minute).Format(time.RFC3339)
+		test.mockWebhookDeliveryRepository.Mock.On("GetAll").Return([]entity.WebhookDelivery{notDue}, nil)
+
+		err := test.service.DeliverPending()
+		assert.Nil(t, err)
+		test.mockWebhookDeliveryRepository.Mock.AssertNotCalled(t, "Update", mock.Anything)
+	})
+
+	t.Run("ShouldRefuseToDialAddressThatIsNotPublic", func(t *testing.T) {
+		var received bool
+		receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			received = true
+		}))
+		defer receiver.Close()
+
+		test := setupWebhookTest()
+		config.WebhookAllowPrivateUrls = false
+		test.mockWebhookDeliveryRepository.Mock.On("GetAll").Return([]entity.WebhookDelivery{pendingDelivery()}, nil)
+		test.mockWebhookDeliveryRepository.Mock.On("GetById", "delivery-1").Return(pendingDelivery(), nil)
+		test.mockWebhookEndpointRepository.Mock.On("GetById", "endpoint-1").Return(entity.WebhookEndpoint{Id: "endpoint-1", Url: receiver.URL, Secret: "whsec_test"}, nil)
+
+		var updated entity.WebhookDelivery
+		test.mockWebhookDeliveryRepository.Mock.On("Update", mock.Anything).Run(func(args mock.Arguments) {
+			updated = args.Get(0).(entity.WebhookDelivery)
+		}).Return(nil)
+
+		err := test.service.DeliverPending()
+		assert.Nil(t, err)
+		assert.False(t, received)
+		assert.Equal(t, enums.WEBHOOK_DELIVERY_PENDING, updated.Status)
+		assert.Contains(t, updated.ErrorMessage, constants.WebhookAddressNotPublicError)
+	})
+
+	t.Run("ShouldContinueAfterFailedUpdate", func(t *testing.T) {
+		receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer receiver.Close()
+
+		test := setupWebhookTest()
+		first, second := pendingDelivery(), pendingDelivery()
+		second.Id = "delivery-2"
+		test.mockWebhookDeliveryRepository.Mock.On("GetAll").Return([]entity.WebhookDelivery{first, second}, nil)
+		test.mockWebhookDeliveryRepository.Mock.On("GetById", "delivery-1").Return(first, nil)
+		test.mockWebhookDeliveryRepository.Mock.On("GetById", "delivery-2").Return(second, nil)
+		test.mockWebhookEndpointRepository.Mock.On("GetById", "endpoint-1").Return(entity.WebhookEndpoint{Id: "endpoint-1", Url: receiver.URL, Secret: "whsec_test"}, nil)
+		test.mockWebhookDeliveryRepository.Mock.On("Update", mock.MatchedBy(func(webhookDelivery entity.WebhookDelivery) bool {
+			return webhookDelivery.Id == "delivery-1"
+		})).Return(errors.New("failed to write file"))
+		test.mockWebhookDeliveryRepository.Mock.On("Update", mock.MatchedBy(func(webhookDelivery entity.WebhookDelivery) bool {
+			return webhookDelivery.Id == "delivery-2" && webhookDelivery.Status == enums.WEBHOOK_DELIVERY_DELIVERED
+		})).Return(nil)
+
+		err := test.service.DeliverPending()
+		assert.ErrorContains(t, err, "failed to write file")
+		test.mockWebhookDeliveryRepository.Mock.AssertExpectations(t)
+	})
+
+	t.Run("ShouldNotWaitForSlowEndpoint", func(t *testing.T) {
+		release := make(chan struct{})
+		slowReceiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-release
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer slowReceiver.Close()
+		receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer receiver.Close()
+
+		test := setupWebhookTest()
+		slow, fast := pendingDelivery(), pendingDelivery()
+		fast.Id, fast.EndpointId = "delivery-2", "endpoint-2"
+		test.mockWebhookDeliveryRepository.Mock.On("GetAll").Return([]entity.WebhookDelivery{slow, fast}, nil)
+		test.mockWebhookDeliveryRepository.Mock.On("GetById", "delivery-1").Return(slow, nil)
+		test.mockWebhookDeliveryRepository.Mock.On("GetById", "delivery-2").Return(fast, nil)
+		test.mockWebhookEndpointRepository.Mock.On("GetById", "endpoint-1").Return(entity.WebhookEndpoint{Id: "endpoint-1", Url: slowReceiver.URL, Secret: "whsec_test"}, nil)
+		test.mockWebhookEndpointRepository.Mock.On("GetById", "endpoint-2").Return(entity.WebhookEndpoint{Id: "endpoint-2", Url: receiver.URL, Secret: "whsec_test"}, nil)
+
+		fastDelivered := make(chan struct{})
+		test.mockWebhookDeliveryRepository.Mock.On("Update", mock.MatchedBy(func(webhookDelivery entity.WebhookDelivery) bool {
+			return webhookDelivery.Id == "delivery-1"
+		})).Return(nil)
+		test.mockWebhookDeliveryRepository.Mock.On("Update", mock.MatchedBy(func(webhookDelivery entity.WebhookDelivery) bool {
+			return webhookDelivery.Id == "delivery-2"
+		})).Run(func(args mock.Arguments) {
+			close(fastDelivered)
+		}).Return(nil)
+
+		done := make(chan error)
+		go func() {
+			done <- test.service.DeliverPending()
+		}()
+
+		select {
+		case <-fastDelivered:
+		case <-time.After(5 * time.Second):
+			t.Fatal("delivery waited for the slow endpoint")
+		}
+
+		// The slow delivery is still being attempted, a manual redelivery of it is refused meanwhile
+		_, err := test.service.Redeliver("delivery-1")
+		assert.Equal(t, constants.WebhookDeliveryInProgressError, err.Error())
+
+		close(release)
+		assert.Nil(t, <-done)
+	})
+}
+
+func TestRedeliverWebhook(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer receiver.Close()
+
+	test := setupWebhookTest()
+	failed := entity.WebhookDelivery{Id: "delivery-1", EndpointId: "endpoint-1", Status: enums.WEBHOOK_DELIVERY_FAILED, Attempts: 3, Payload: []byte(`{}`)}
+	test.mockWebhookDeliveryRepository.Mock.On("GetById", "delivery-1").Return(failed, nil)
+	test.mockWebhookEndpointRepository.Mock.On("GetById", "endpoint-1").Return(entity.WebhookEndpoint{Id: "endpoint-1", Url: receiver.URL, Secret: "whsec_test"}, nil)
+	test.mockWebhookDeliveryRepository.Mock.On("Update", mock.Anything).Return(nil)
+
+	redelivered, err := test.service.Redeliver("delivery-1")
+	assert.Nil(t, err)
+	assert.Equal(t, enums.WEBHOOK_DELIVERY_DELIVERED, redelivered.Status)
+	assert.Equal(t, 4, redelivered.Attempts)
+}
@@ -5,6 +5,10 @@ import (
 	"encoding/json"
 	"errors"
 	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/sirupsen/logrus"
 )
 
 type JsonFileHandler[T any] interface {
@@ -12,24 +16,50 @@ type JsonFileHandler[T any] interface {
 	WriteFile(data []T, path string) (string, error)
 }
 
-type JsonFileHandlerImpl[T any] struct{}
+type JsonFileHandlerImpl[T any] struct {
+	keyring *Keyring
+}
+
+// defaultKeyring is used by every handler created through NewJsonFileHandler, nil disables encryption
+var defaultKeyring *Keyring
+
+// fileLocks serializes access to a storage file so a key rotation never interleaves with a write
+var fileLocks sync.Map
+
+// UseKeyring enables encryption at rest for every handler created afterwards
+func UseKeyring(keyring *Keyring) {
+	defaultKeyring = keyring
+}
 
 func NewJsonFileHandler[T any]() JsonFileHandler[T] {
-	return &JsonFileHandlerImpl[T]{}
+	return &JsonFileHandlerImpl[T]{keyring: defaultKeyring}
+}
+
+// NewEncryptedJsonFileHandler creates a handler that encrypts with the given keyring
+func NewEncryptedJsonFileHandler[T any](keyring *Keyring) JsonFileHandler[T] {
+	return &JsonFileHandlerImpl[T]{keyring: keyring}
 }
 
 func (j *JsonFileHandlerImpl[T]) ReadFile(path string) ([]T, error) {
-	// Open Json File
-	file, err := os.Open(path)
+	lock := lockFor(path)
+	lock.Lock()
+	defer lock.Unlock()
+
+	// Read Json File
+	raw, err := os.ReadFile(path)
 	if err != nil {
 		return nil, errors.New(constants.JsonFileNotFound)
 	}
-	defer file.Close()
+
+	// Decrypt the file content when it is stored encrypted
+	content, err := decodeFile(raw, path, j.keyring)
+	if err != nil {
+		return nil, err
+	}
 
 	// Map the json file to golang slice
 	var data []T
-	decoder := json.NewDecoder(file)
-	if err := decoder.Decode(&data); err != nil {
+	if err := json.Unmarshal(content, &data); err != nil {
 		return nil, errors.New(constants.JsonMappingError)
 	}
 
@@ -37,23 +67,121 @@ func (j *JsonFileHandlerImpl[T]) ReadFile(path string) ([]T, error) {
 }
 
 func (j *JsonFileHandlerImpl[T]) WriteFile(data []T, path string) (string, error) {
-	// Rewrite the file
-	file, err := os.Create(path)
-	if err != nil {
-		return constants.JsonCreateError, err
-	}
-	defer file.Close()
-
 	// Encode golang slice to json string
 	updatedData, err := json.Marshal(data)
 	if err != nil {
 		return constants.JsonMarshalError, err
 	}
 
-	_, err = file.Write(updatedData)
-	if err != nil {
+	// Encrypt the json string when encryption is enabled
+	if j.keyring != nil {
+		updatedData, err = j.keyring.Encrypt(updatedData, associatedDataFor(path))
+		if err != nil {
+			return constants.JsonEncryptError, err
+		}
+	}
+
+	lock := lockFor(path)
+	lock.Lock()
+	defer lock.Unlock()
+
+	// Rewrite the file
+	if err := writeFileAtomic(path, updatedData); err != nil {
 		return constants.JsonWriteError, err
 	}
 
 	return constants.JsonWriteSuccess, nil
 }
+
+// ReEncryptFiles rewrites every file that is plaintext or encrypted with a previous key using the current key.
+// Each file is locked while it is rewritten, so it is safe to run while the server is serving requests.
+func ReEncryptFiles(keyring *Keyring, paths []string) error {
+	for _, path := range paths {
+		rotated, err := reEncryptFile(keyring, path)
+		if err != nil {
+			logrus.WithFields(logrus.Fields{"path": path}).Error("Failed to re-encrypt storage file", err)
+			return err
+		}
+		if rotated {
+			logrus.WithFields(logrus.Fields{"path": path, "keyId": keyring.CurrentKeyId()}).Info("Storage file re-encrypted")
+		}
+	}
+	return nil
+}
+
+func reEncryptFile(keyring *Keyring, path string) (bool, error) {
+	lock := lockFor(path)
+	lock.Lock()
+	defer lock.Unlock()
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return false, errors.New(constants.JsonFileNotFound)
+	}
+	if !keyring.NeedsRotation(raw) {
+		return false, nil
+	}
+
+	// Plaintext files are migrated here regardless of the plaintext setting, since this is an explicit operation
+	content := raw
+	if isEncrypted(raw) {
+		content, err = keyring.Decrypt(raw, associatedDataFor(path))
+		if err != nil {
+			return false, err
+		}
+	} else if !json.Valid(raw) {
+		return false, errors.New(constants.JsonMappingError)
+	}
+
+	encrypted, err := keyring.Encrypt(content, associatedDataFor(path))
+	if err != nil {
+		return false, err
+	}
+	if err := writeFileAtomic(path, encrypted); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// decodeFile returns the plain JSON content of a storage file, refusing anything that fails authentication
+func decodeFile(raw []byte, path string, keyring *Keyring) ([]byte, error) {
+	if !isEncrypted(raw) {
+		if keyring != nil && !keyring.allowsPlaintext {
+			return nil, errors.New(constants.StoragePlaintextRejectedError)
+		}
+		return raw, nil
+	}
+
+	if keyring == nil {
+		return nil, errors.New(constants.StorageKeyNotFoundError)
+	}
+	return keyring.Decrypt(raw, associatedDataFor(path))
+}
+
+// associatedDataFor binds ciphertext to its file name, so swapping two encrypted files is detected
+func associatedDataFor(path string) []byte {
+	return []byte(filepath.Base(path))
+}
+
+// writeFileAtomic writes to a temporary file and renames it, so readers never see a partially written file
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func lockFor(path string) *sync.Mutex {
+	lock, _ := fileLocks.LoadOrStore(filepath.Clean(path), &sync.Mutex{})
+	return lock.(*sync.Mutex)
+}
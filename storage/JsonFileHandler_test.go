@@ -0,0 +1,168 @@
+package storage
+
+import (
+	"PaymentAPI/constants"
+	"PaymentAPI/entity"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newTestKey(t *testing.T) []byte {
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	assert.Nil(t, err)
+	return key
+}
+
+func newTestKeyring(t *testing.T, current []byte, previous ...[]byte) *Keyring {
+	keyring, err := NewKeyring(current, previous, false)
+	assert.Nil(t, err)
+	return keyring
+}
+
+var testCustomers = []entity.Customer{
+	{Id: "id-1", Username: "johndoe", Password: "$2a$10$hash"},
+}
+
+func TestEncryptedJsonFileHandler(t *testing.T) {
+	t.Run("ShouldRoundTripWithoutPlaintextOnDisk", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "customers.json")
+		handler := NewEncryptedJsonFileHandler[entity.Customer](newTestKeyring(t, newTestKey(t)))
+
+		_, err := handler.WriteFile(testCustomers, path)
+		assert.Nil(t, err)
+
+		raw, err := os.ReadFile(path)
+		assert.Nil(t, err)
+		assert.NotContains(t, string(raw), "johndoe")
+		assert.NotContains(t, string(raw), "$2a$10$hash")
+
+		data, err := handler.ReadFile(path)
+		assert.Nil(t, err)
+		assert.Equal(t, testCustomers, data)
+	})
+
+	t.Run("ShouldDetectTamperedCiphertext", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "customers.json")
+		handler := NewEncryptedJsonFileHandler[entity.Customer](newTestKeyring(t, newTestKey(t)))
+
+		_, err := handler.WriteFile(testCustomers, path)
+		assert.Nil(t, err)
+
+		// Flip a single bit of the ciphertext
+		raw, _ := os.ReadFile(path)
+		var envelope encryptedFile
+		assert.Nil(t, json.Unmarshal(raw, &envelope))
+		ciphertext, _ := base64.StdEncoding.DecodeString(envelope.Ciphertext)
+		ciphertext[0] ^= 0x01
+		envelope.Ciphertext = base64.StdEncoding.EncodeToString(ciphertext)
+		raw, _ = json.Marshal(envelope)
+		assert.Nil(t, os.WriteFile(path, raw, 0600))
+
+		data, err := handler.ReadFile(path)
+		assert.Equal(t, constants.StorageIntegrityError, err.Error())
+		assert.Nil(t, data)
+	})
+
+	t.Run("ShouldDetectFileSwappedFromAnotherPath", func(t *testing.T) {
+		dir := t.TempDir()
+		handler := NewEncryptedJsonFileHandler[entity.Customer](newTestKeyring(t, newTestKey(t)))
+
+		_, err := handler.WriteFile(testCustomers, filepath.Join(dir, "customers.json"))
+		assert.Nil(t, err)
+
+		raw, _ := os.ReadFile(filepath.Join(dir, "customers.json"))
+		assert.Nil(t, os.WriteFile(filepath.Join(dir, "wallets.json"), raw, 0600))
+
+		data, err := handler.ReadFile(filepath.Join(dir, "wallets.json"))
+		assert.Equal(t, constants.StorageIntegrityError, err.Error())
+		assert.Nil(t, data)
+	})
+
+	t.Run("ShouldRejectPlaintextReplacement", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "customers.json")
+		handler := NewEncryptedJsonFileHandler[entity.Customer](newTestKeyring(t, newTestKey(t)))
+
+		assert.Nil(t, os.WriteFile(path, []byte(`[{"id":"attacker","username":"johndoe","password":"x"}]`), 0600))
+
+		data, err := handler.ReadFile(path)
+		assert.Equal(t, constants.StoragePlaintextRejectedError, err.Error())
+		assert.Nil(t, data)
+	})
+
+	t.Run("ShouldRejectUnknownKey", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "customers.json")
+
+		_, err := NewEncryptedJsonFileHandler[entity.Customer](newTestKeyring(t, newTestKey(t))).WriteFile(testCustomers, path)
+		assert.Nil(t, err)
+
+		data, err := NewEncryptedJsonFileHandler[entity.Customer](newTestKeyring(t, newTestKey(t))).ReadFile(path)
+		assert.Equal(t, constants.StorageKeyNotFoundError, err.Error())
+		assert.Nil(t, data)
+	})
+}
+
+func TestReEncryptFiles(t *testing.T) {
+	t.Run("ShouldRotateToCurrentKey", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "customers.json")
+		oldKey, newKey := newTestKey(t), newTestKey(t)
+
+		_, err := NewEncryptedJsonFileHandler[entity.Customer](newTestKeyring(t, oldKey)).WriteFile(testCustomers, path)
+		assert.Nil(t, err)
+
+		// During the rotation both keys are accepted
+		rotatingKeyring := newTestKeyring(t, newKey, oldKey)
+		data, err := NewEncryptedJsonFileHandler[entity.Customer](rotatingKeyring).ReadFile(path)
+		assert.Nil(t, err)
+		assert.Equal(t, testCustomers, data)
+
+		assert.Nil(t, ReEncryptFiles(rotatingKeyring, []string{path}))
+
+		// After the rotation the old key is no longer needed
+		data, err = NewEncryptedJsonFileHandler[entity.Customer](newTestKeyring(t, newKey)).ReadFile(path)
+		assert.Nil(t, err)
+		assert.Equal(t, testCustomers, data)
+	})
+
+	t.Run("ShouldEncryptPlaintextFile", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "customers.json")
+		raw, _ := json.Marshal(testCustomers)
+		assert.Nil(t, os.WriteFile(path, raw, 0600))
+
+		keyring := newTestKeyring(t, newTestKey(t))
+		assert.Nil(t, ReEncryptFiles(keyring, []string{path}))
+
+		raw, _ = os.ReadFile(path)
+		assert.NotContains(t, string(raw), "johndoe")
+
+		data, err := NewEncryptedJsonFileHandler[entity.Customer](keyring).ReadFile(path)
+		assert.Nil(t, err)
+		assert.Equal(t, testCustomers, data)
+	})
+
+	t.Run("ShouldNotRewriteTamperedFile", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "customers.json")
+		oldKey, newKey := newTestKey(t), newTestKey(t)
+
+		_, err := NewEncryptedJsonFileHandler[entity.Customer](newTestKeyring(t, oldKey)).WriteFile(testCustomers, path)
+		assert.Nil(t, err)
+
+		raw, _ := os.ReadFile(path)
+		var envelope encryptedFile
+		assert.Nil(t, json.Unmarshal(raw, &envelope))
+		envelope.Nonce = base64.StdEncoding.EncodeToString(make([]byte, 12))
+		tampered, _ := json.Marshal(envelope)
+		assert.Nil(t, os.WriteFile(path, tampered, 0600))
+
+		err = ReEncryptFiles(newTestKeyring(t, newKey, oldKey), []string{path})
+		assert.Equal(t, constants.StorageIntegrityError, err.Error())
+
+		raw, _ = os.ReadFile(path)
+		assert.Equal(t, tampered, raw)
+	})
+}
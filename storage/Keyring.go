@@ -0,0 +1,177 @@
+package storage
+
+import (
+	"PaymentAPI/constants"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+const encryptedFileVersion = 1
+const encryptedFileAlgorithm = "AES-256-GCM"
+
+// encryptedFile is the on-disk envelope of an encrypted storage file
+type encryptedFile struct {
+	Version    int    `json:"version"`
+	Algorithm  string `json:"algorithm"`
+	KeyId      string `json:"key_id"`
+	Nonce      string `json:"nonce"`
+	Ciphertext string `json:"ciphertext"`
+}
+
+// Keyring holds the key used to encrypt storage files and every key that is still accepted for decryption
+type Keyring struct {
+	currentKeyId    string
+	keys            map[string]cipher.AEAD
+	allowsPlaintext bool
+}
+
+// NewKeyring creates a keyring that encrypts with currentKey and decrypts with currentKey or any of previousKeys
+func NewKeyring(currentKey []byte, previousKeys [][]byte, allowPlaintext bool) (*Keyring, error) {
+	keyring := &Keyring{keys: map[string]cipher.AEAD{}, allowsPlaintext: allowPlaintext}
+
+	currentKeyId, err := keyring.addKey(currentKey)
+	if err != nil {
+		return nil, err
+	}
+	keyring.currentKeyId = currentKeyId
+
+	for _, key := range previousKeys {
+		if _, err := keyring.addKey(key); err != nil {
+			return nil, err
+		}
+	}
+
+	return keyring, nil
+}
+
+// DecodeKey decodes a base64 encoded 256-bit storage key
+func DecodeKey(encodedKey string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(encodedKey)
+	if err != nil || len(key) != 32 {
+		return nil, errors.New(constants.StorageKeyInvalidError)
+	}
+	return key, nil
+}
+
+// CurrentKeyId returns the identifier of the key used for new writes
+func (k *Keyring) CurrentKeyId() string {
+	return k.currentKeyId
+}
+
+func (k *Keyring) addKey(key []byte) (string, error) {
+	if len(key) != 32 {
+		return "", errors.New(constants.StorageKeyInvalidError)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return "", err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", err
+	}
+
+	keyId := keyIdOf(key)
+	k.keys[keyId] = aead
+	return keyId, nil
+}
+
+// keyIdOf derives a stable, non-secret identifier from a key
+func keyIdOf(key []byte) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:8])
+}
+
+// Encrypt seals plaintext with the current key, binding it to associatedData
+func (k *Keyring) Encrypt(plaintext []byte, associatedData []byte) ([]byte, error) {
+	aead := k.keys[k.currentKeyId]
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	envelope := encryptedFile{
+		Version:    encryptedFileVersion,
+		Algorithm:  encryptedFileAlgorithm,
+		KeyId:      k.currentKeyId,
+		Nonce:      base64.StdEncoding.EncodeToString(nonce),
+		Ciphertext: base64.StdEncoding.EncodeToString(aead.Seal(nil, nonce, plaintext, associatedData)),
+	}
+	return json.Marshal(envelope)
+}
+
+// Decrypt opens an envelope produced by Encrypt. Any modification of the envelope,
+// the ciphertext or the associated data is reported as an integrity error.
+func (k *Keyring) Decrypt(data []byte, associatedData []byte) ([]byte, error) {
+	envelope, err := parseEnvelope(data)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, ok := k.keys[envelope.KeyId]
+	if !ok {
+		return nil, errors.New(constants.StorageKeyNotFoundError)
+	}
+
+	nonce, err := base64.StdEncoding.DecodeString(envelope.Nonce)
+	if err != nil || len(nonce) != aead.NonceSize() {
+		return nil, errors.New(constants.StorageIntegrityError)
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(envelope.Ciphertext)
+	if err != nil {
+		return nil, errors.New(constants.StorageIntegrityError)
+	}
+
+	plaintext, err := aead.Open(nil, nonce, ciphertext, associatedData)
+	if err != nil {
+		return nil, errors.New(constants.StorageIntegrityError)
+	}
+	return plaintext, nil
+}
+
+// NeedsRotation reports whether data is plaintext or encrypted with a key other than the current one
+func (k *Keyring) NeedsRotation(data []byte) bool {
+	if !isEncrypted(data) {
+		return true
+	}
+	envelope, err := parseEnvelope(data)
+	if err != nil {
+		return false
+	}
+	return envelope.KeyId != k.currentKeyId
+}
+
+func parseEnvelope(data []byte) (encryptedFile, error) {
+	var envelope encryptedFile
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return encryptedFile{}, errors.New(constants.StorageIntegrityError)
+	}
+	if envelope.Version != encryptedFileVersion || envelope.Algorithm != encryptedFileAlgorithm {
+		return encryptedFile{}, errors.New(constants.StorageIntegrityError)
+	}
+	return envelope, nil
+}
+
+// isEncrypted reports whether data looks like an encryption envelope rather than a plain JSON array
+func isEncrypted(data []byte) bool {
+	for _, b := range data {
+		switch b {
+		case ' ', '\t', '\r', '\n':
+			continue
+		case '{':
+			return true
+		default:
+			return false
+		}
+	}
+	return false
+}
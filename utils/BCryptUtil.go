@@ -2,16 +2,16 @@ package utils
 
 import (
 	"golang.org/x/crypto/bcrypt"
-	"log"
 )
 
-func BCryptEncoder(input string) string {
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(input), bcrypt.DefaultCost)
+// BCryptEncoder hashes the input with the given cost, a cost below the minimum uses the bcrypt default
+func BCryptEncoder(input string, cost int) (string, error) {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(input), cost)
 	if err != nil {
-		log.Fatal(err)
+		return "", err
 	}
 
-	return string(hashedPassword)
+	return string(hashedPassword), nil
 }
 
 func BCryptCompare(password string, hashedPassword []byte) bool {
@@ -2,12 +2,15 @@ package utils
 
 import (
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+	"strings"
 	"testing"
 )
 
 func TestBcryptEncoder(t *testing.T) {
 	password := "password"
-	encodedPassword := BCryptEncoder(password)
+	encodedPassword, err := BCryptEncoder(password, bcrypt.MinCost)
+	assert.Nil(t, err)
 
 	t.Run("ShouldReturnTrueOnEqual", func(t *testing.T) {
 		assert.NotEmpty(t, encodedPassword, "Password encoder return is empty")
@@ -19,4 +22,9 @@ func TestBcryptEncoder(t *testing.T) {
 		isEqual := BCryptCompare("passwords", []byte(encodedPassword))
 		assert.False(t, isEqual, "Return is not false")
 	})
+
+	t.Run("ShouldReturnErrorInsteadOfExiting", func(t *testing.T) {
+		_, err := BCryptEncoder(strings.Repeat("a", 73), bcrypt.MinCost)
+		assert.NotNil(t, err)
+	})
 }
@@ -0,0 +1,22 @@
+package utils
+
+import (
+	"PaymentAPI/config"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// SignBankNotification signs the timestamp and raw body of a bank notification with the bank notification signing key
+func SignBankNotification(timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, config.BankNotificationSigningKey)
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyBankNotification reports whether the signature matches the timestamp and raw body of a bank notification
+func VerifyBankNotification(timestamp string, body []byte, signature string) bool {
+	expected := SignBankNotification(timestamp, body)
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
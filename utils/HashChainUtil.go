@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"PaymentAPI/config"
+	"PaymentAPI/entity"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+)
+
+// ComputeTransactionHash hashes every field of a transaction, including the hash of the previous record
+func ComputeTransactionHash(transaction entity.Transaction) (string, error) {
+	// The hash field itself is the only field not covered
+	transaction.Hash = ""
+
+	content, err := json.Marshal(transaction)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// SignLedgerCheckpoint signs the sequence and head hash of a checkpoint with the checkpoint signing key
+func SignLedgerCheckpoint(checkpoint entity.LedgerCheckpoint) string {
+	mac := hmac.New(sha256.New, config.LedgerCheckpointSigningKey)
+	mac.Write([]byte(strconv.Itoa(checkpoint.Sequence) + "|" + checkpoint.HeadHash + "|" + checkpoint.CreatedAt))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyLedgerCheckpoint reports whether the checkpoint signature is valid
+func VerifyLedgerCheckpoint(checkpoint entity.LedgerCheckpoint) bool {
+	expected := SignLedgerCheckpoint(checkpoint)
+	return hmac.Equal([]byte(expected), []byte(checkpoint.Signature))
+}
@@ -0,0 +1,183 @@
+package utils
+
+import (
+	"PaymentAPI/constants"
+	res "PaymentAPI/dto/response"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// jwtRsaMinBits is the smallest RSA key accepted to sign or verify access tokens
+const jwtRsaMinBits = 2048
+
+// JwtKeys holds the private key access tokens are signed with and every public key they are verified with. Besides
+// the signing key, those are the previous key while the tokens it signed have not expired, or the next key so it is
+// published before tokens are signed with it.
+type JwtKeys struct {
+	signingKeyId     string
+	signingKey       crypto.Signer
+	verificationKeys map[string]crypto.PublicKey
+}
+
+// jwtKeys are the keys in use, access tokens are signed with HS256 and config.JwtSignatureKey without them
+var jwtKeys *JwtKeys
+
+// UseJwtKeys makes access tokens be signed and verified with the keys
+func UseJwtKeys(keys *JwtKeys) {
+	jwtKeys = keys
+}
+
+// NewJwtKeys creates the keys from a PEM encoded private key and the PEM encoded public keys also accepted
+func NewJwtKeys(privateKeyPem []byte, verificationKeyPems [][]byte) (*JwtKeys, error) {
+	signingKey, err := parseJwtPrivateKey(privateKeyPem)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := &JwtKeys{verificationKeys: map[string]crypto.PublicKey{}}
+	keys.signingKeyId, err = keys.addKey(signingKey.Public())
+	if err != nil {
+		return nil, err
+	}
+	keys.signingKey = signingKey
+
+	for _, verificationKeyPem := range verificationKeyPems {
+		publicKey, err := parseJwtPublicKey(verificationKeyPem)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := keys.addKey(publicKey); err != nil {
+			return nil, err
+		}
+	}
+
+	return keys, nil
+}
+
+// SigningKeyId returns the kid of the key new access tokens are signed with
+func (k *JwtKeys) SigningKeyId() string {
+	return k.signingKeyId
+}
+
+// GetJwks returns the public keys access tokens are verified with, empty while they are signed with a shared secret
+func GetJwks() res.JwksResponse {
+	jwks := res.JwksResponse{Keys: []res.JsonWebKey{}}
+	if jwtKeys == nil {
+		return jwks
+	}
+
+	for keyId, publicKey := range jwtKeys.verificationKeys {
+		jwk := res.JsonWebKey{
+			Use: "sig",
+			Alg: jwtSigningMethodOf(publicKey).Alg(),
+			Kid: keyId,
+		}
+		switch key := publicKey.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(key.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(key)
+		}
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+
+	// The signing key first, the order of the others is only kept stable
+	sort.Slice(jwks.Keys, func(i, j int) bool {
+		if (jwks.Keys[i].Kid == jwtKeys.signingKeyId) != (jwks.Keys[j].Kid == jwtKeys.signingKeyId) {
+			return jwks.Keys[i].Kid == jwtKeys.signingKeyId
+		}
+		return jwks.Keys[i].Kid < jwks.Keys[j].Kid
+	})
+	return jwks
+}
+
+// verificationKey picks the public key of the kid in the token header, refusing a token signed with another
+// algorithm than the key is for
+func (k *JwtKeys) verificationKey(token *jwt.Token) (interface{}, error) {
+	keyId, _ := token.Header["kid"].(string)
+	publicKey, ok := k.verificationKeys[keyId]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key: %q", keyId)
+	}
+
+	if method := jwtSigningMethodOf(publicKey); token.Method != method {
+		return nil, fmt.Errorf("invalid signing method: expected %v, got %v", method.Alg(), token.Method.Alg())
+	}
+	return publicKey, nil
+}
+
+func (k *JwtKeys) addKey(publicKey crypto.PublicKey) (string, error) {
+	if jwtSigningMethodOf(publicKey) == nil {
+		return "", errors.New(constants.JwtKeyInvalidError)
+	}
+	if rsaKey, ok := publicKey.(*rsa.PublicKey); ok && rsaKey.N.BitLen() < jwtRsaMinBits {
+		return "", errors.New(constants.JwtKeyInvalidError)
+	}
+
+	keyId, err := jwtKeyIdOf(publicKey)
+	if err != nil {
+		return "", err
+	}
+	k.verificationKeys[keyId] = publicKey
+	return keyId, nil
+}
+
+// jwtKeyIdOf derives a stable kid from a public key, the same key always gets the same kid
+func jwtKeyIdOf(publicKey crypto.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(der)
+	return hex.EncodeToString(sum[:8]), nil
+}
+
+// jwtSigningMethodOf returns RS256 for an RSA key and EdDSA for an Ed25519 key, nil for any other key
+func jwtSigningMethodOf(publicKey crypto.PublicKey) jwt.SigningMethod {
+	switch publicKey.(type) {
+	case *rsa.PublicKey:
+		return jwt.SigningMethodRS256
+	case ed25519.PublicKey:
+		return jwt.SigningMethodEdDSA
+	}
+	return nil
+}
+
+// parseJwtPrivateKey reads a PEM encoded RSA key, PKCS #1 or PKCS #8, or a PKCS #8 Ed25519 key
+func parseJwtPrivateKey(privateKeyPem []byte) (crypto.Signer, error) {
+	if rsaKey, err := jwt.ParseRSAPrivateKeyFromPEM(privateKeyPem); err == nil {
+		return rsaKey, nil
+	}
+	if edKey, err := jwt.ParseEdPrivateKeyFromPEM(privateKeyPem); err == nil {
+		if signer, ok := edKey.(crypto.Signer); ok {
+			return signer, nil
+		}
+	}
+	return nil, errors.New(constants.JwtKeyInvalidError)
+}
+
+// parseJwtPublicKey reads a PEM encoded RSA or Ed25519 public key
+func parseJwtPublicKey(publicKeyPem []byte) (crypto.PublicKey, error) {
+	if rsaKey, err := jwt.ParseRSAPublicKeyFromPEM(publicKeyPem); err == nil {
+		return rsaKey, nil
+	}
+	if edKey, err := jwt.ParseEdPublicKeyFromPEM(publicKeyPem); err == nil {
+		return edKey, nil
+	}
+	return nil, errors.New(constants.JwtKeyInvalidError)
+}
@@ -0,0 +1,176 @@
+package utils
+
+import (
+	"PaymentAPI/config"
+	"PaymentAPI/constants"
+	"PaymentAPI/entity"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"time"
+)
+
+func privateKeyPem(t *testing.T, key crypto.Signer) []byte {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	assert.Nil(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+}
+
+func publicKeyPem(t *testing.T, key crypto.Signer) []byte {
+	der, err := x509.MarshalPKIXPublicKey(key.Public())
+	assert.Nil(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+}
+
+func newEd25519Key(t *testing.T) crypto.Signer {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	assert.Nil(t, err)
+	return key
+}
+
+// useJwtKeysForTest signs and verifies access tokens with the keys until the test ends
+func useJwtKeysForTest(t *testing.T, keys *JwtKeys) {
+	previousKeys, previousExpiration := jwtKeys, config.LoginExpirationDuration
+	t.Cleanup(func() {
+		jwtKeys, config.LoginExpirationDuration = previousKeys, previousExpiration
+	})
+	jwtKeys, config.LoginExpirationDuration = keys, 5*time.Minute
+}
+
+func TestNewJwtKeys(t *testing.T) {
+	t.Run("ShouldRefuseShortRsaKey", func(t *testing.T) {
+		key, err := rsa.GenerateKey(rand.Reader, 1024)
+		assert.Nil(t, err)
+
+		_, err = NewJwtKeys(privateKeyPem(t, key), nil)
+		assert.Equal(t, constants.JwtKeyInvalidError, err.Error())
+	})
+
+	t.Run("ShouldRefuseInvalidPem", func(t *testing.T) {
+		_, err := NewJwtKeys([]byte("not a key"), nil)
+		assert.Equal(t, constants.JwtKeyInvalidError, err.Error())
+
+		_, err = NewJwtKeys(privateKeyPem(t, newEd25519Key(t)), [][]byte{[]byte("not a key")})
+		assert.Equal(t, constants.JwtKeyInvalidError, err.Error())
+	})
+
+	t.Run("ShouldDeriveStableKeyId", func(t *testing.T) {
+		key := newEd25519Key(t)
+		keys, err := NewJwtKeys(privateKeyPem(t, key), nil)
+		assert.Nil(t, err)
+		again, err := NewJwtKeys(privateKeyPem(t, key), nil)
+		assert.Nil(t, err)
+		assert.Len(t, keys.SigningKeyId(), 16)
+		assert.Equal(t, keys.SigningKeyId(), again.SigningKeyId())
+	})
+}
+
+func TestAsymmetricAccessToken(t *testing.T) {
+	customer := entity.Customer{Id: "id-1", Username: "johndoe"}
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Nil(t, err)
+	signers := map[string]crypto.Signer{"RS256": rsaKey, "EdDSA": newEd25519Key(t)}
+
+	for alg, key := range signers {
+		t.Run("ShouldSignWithKidUsing"+alg, func(t *testing.T) {
+			keys, err := NewJwtKeys(privateKeyPem(t, key), nil)
+			assert.Nil(t, err)
+			useJwtKeysForTest(t, keys)
+
+			token, err := GenerateAccessToken(customer, "session-1")
+			assert.Nil(t, err)
+
+			parsed, _, err := new(jwt.Parser).ParseUnverified(token, jwt.MapClaims{})
+			assert.Nil(t, err)
+			assert.Equal(t, alg, parsed.Header["alg"])
+			assert.Equal(t, keys.SigningKeyId(), parsed.Header["kid"])
+
+			claims, err := ParseAndVerifyAccessToken(token)
+			assert.Nil(t, err)
+			id, err := GetCustomerIdFromClaims(claims)
+			assert.Nil(t, err)
+			assert.Equal(t, "id-1", id)
+		})
+	}
+
+	t.Run("ShouldVerifyTokenOfPreviousKeyDuringRotation", func(t *testing.T) {
+		previousKey, nextKey := newEd25519Key(t), newEd25519Key(t)
+
+		previousKeys, err := NewJwtKeys(privateKeyPem(t, previousKey), nil)
+		assert.Nil(t, err)
+		useJwtKeysForTest(t, previousKeys)
+		token, err := GenerateAccessToken(customer, "session-1")
+		assert.Nil(t, err)
+
+		// Signed with the next key now, the previous one is only verified with
+		rotatedKeys, err := NewJwtKeys(privateKeyPem(t, nextKey), [][]byte{publicKeyPem(t, previousKey)})
+		assert.Nil(t, err)
+		jwtKeys = rotatedKeys
+		_, err = ParseAndVerifyAccessToken(token)
+		assert.Nil(t, err)
+
+		// Once the previous key is dropped its tokens are refused
+		nextKeys, err := NewJwtKeys(privateKeyPem(t, nextKey), nil)
+		assert.Nil(t, err)
+		jwtKeys = nextKeys
+		_, err = ParseAndVerifyAccessToken(token)
+		assert.Equal(t, constants.JwtTokenInvalidError, err.Error())
+	})
+
+	t.Run("ShouldRefuseTokenSignedWithSharedSecret", func(t *testing.T) {
+		keys, err := NewJwtKeys(privateKeyPem(t, rsaKey), nil)
+		assert.Nil(t, err)
+		useJwtKeysForTest(t, keys)
+
+		// An HS256 token naming the kid of the RSA key, signed with its public key as the secret
+		forged := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": "id-1", "jti": "token-id-1"})
+		forged.Header["kid"] = keys.SigningKeyId()
+		token, err := forged.SignedString(publicKeyPem(t, rsaKey))
+		assert.Nil(t, err)
+
+		_, err = ParseAndVerifyAccessToken(token)
+		assert.Equal(t, constants.JwtTokenInvalidError, err.Error())
+	})
+}
+
+func TestGetJwks(t *testing.T) {
+	t.Run("ShouldBeEmptyWithSharedSecret", func(t *testing.T) {
+		useJwtKeysForTest(t, nil)
+		assert.Empty(t, GetJwks().Keys)
+	})
+
+	t.Run("ShouldPublishEveryVerificationKey", func(t *testing.T) {
+		rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		assert.Nil(t, err)
+		keys, err := NewJwtKeys(privateKeyPem(t, newEd25519Key(t)), [][]byte{publicKeyPem(t, rsaKey)})
+		assert.Nil(t, err)
+		useJwtKeysForTest(t, keys)
+
+		jwks := GetJwks()
+		assert.Len(t, jwks.Keys, 2)
+
+		// The signing key comes first
+		assert.Equal(t, keys.SigningKeyId(), jwks.Keys[0].Kid)
+		assert.Equal(t, "OKP", jwks.Keys[0].Kty)
+		assert.Equal(t, "Ed25519", jwks.Keys[0].Crv)
+		assert.Equal(t, "EdDSA", jwks.Keys[0].Alg)
+		assert.NotEmpty(t, jwks.Keys[0].X)
+
+		assert.Equal(t, "RSA", jwks.Keys[1].Kty)
+		assert.Equal(t, "RS256", jwks.Keys[1].Alg)
+		assert.Equal(t, "AQAB", jwks.Keys[1].E)
+		assert.NotEmpty(t, jwks.Keys[1].N)
+		for _, jwk := range jwks.Keys {
+			assert.Equal(t, "sig", jwk.Use)
+			assert.False(t, strings.ContainsAny(jwk.N+jwk.X, "+/="))
+		}
+	})
+}
@@ -4,30 +4,53 @@ import (
 	"PaymentAPI/config"
 	"PaymentAPI/constants"
 	"PaymentAPI/entity"
+	"PaymentAPI/enums"
 	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v4"
-	"strconv"
+	"github.com/google/uuid"
 	"strings"
 	"time"
 )
 
 type M map[string]interface{}
 
-func GenerateAccessToken(customer entity.Customer) (string, error) {
+// GenerateAccessToken signs an access token for the customer, tied to the session it was issued for. Every token
+// gets a JWT ID of its own to be revoked by.
+func GenerateAccessToken(customer entity.Customer, sessionId string) (string, error) {
+	// A customer stored before roles were introduced is a regular user
+	role := customer.Role
+	if role == "" {
+		role = enums.ROLE_USER
+	}
+
 	claims := entity.JwtClaims{
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.New().String(),
 			Issuer:    config.ApplicationName,
 			Subject:   customer.Id,
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(config.LoginExpirationDuration)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
+		Username:  customer.Username,
+		Role:      role,
+		SessionId: sessionId,
 	}
 
-	token := jwt.NewWithClaims(config.JwtSigningMethod, claims)
+	// Sign with the private key when keys are in use, naming it in the kid header
+	var token *jwt.Token
+	var signingKey interface{}
+	if jwtKeys != nil {
+		token = jwt.NewWithClaims(jwtSigningMethodOf(jwtKeys.signingKey.Public()), claims)
+		token.Header["kid"] = jwtKeys.signingKeyId
+		signingKey = jwtKeys.signingKey
+	} else {
+		token = jwt.NewWithClaims(config.JwtSigningMethod, claims)
+		signingKey = config.JwtSignatureKey
+	}
 
-	signedToken, err := token.SignedString(config.JwtSignatureKey)
+	signedToken, err := token.SignedString(signingKey)
 	if err != nil {
 		return "", fmt.Errorf("failed to sign token: %w", err)
 	}
@@ -53,8 +76,15 @@ func ExtractTokenFromHeader(c *gin.Context) (string, error) {
 	return parts[1], nil
 }
 
+// ParseAndVerifyAccessToken checks the signature and expiry of an access token and returns its claims, which the
+// Get*FromClaims functions read, so a token is parsed once however many of its claims are needed
 func ParseAndVerifyAccessToken(accessToken string) (jwt.MapClaims, error) {
 	token, err := jwt.Parse(accessToken, func(token *jwt.Token) (interface{}, error) {
+		// The key is picked by the kid in the header, a token signed with the shared secret is not accepted then
+		if jwtKeys != nil {
+			return jwtKeys.verificationKey(token)
+		}
+
 		method, ok := token.Method.(*jwt.SigningMethodHMAC)
 		if !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
@@ -81,39 +111,51 @@ func ParseAndVerifyAccessToken(accessToken string) (jwt.MapClaims, error) {
 	return claims, nil
 }
 
-func GetExpirationFromClaimsAsString(accessToken string) (string, error) {
-	claims, err := ParseAndVerifyAccessToken(accessToken)
-	if err != nil {
-		return "", err
+func GetExpirationFromClaims(claims jwt.MapClaims) (time.Time, error) {
+	return getTimeFromClaims(claims, "exp")
+}
+
+func GetIssuedAtFromClaims(claims jwt.MapClaims) (time.Time, error) {
+	return getTimeFromClaims(claims, "iat")
+}
+
+func GetTokenIdFromClaims(claims jwt.MapClaims) (string, error) {
+	// A token without a JWT ID could not be revoked on its own, it is not accepted
+	tokenId, ok := claims["jti"].(string)
+	if !ok || tokenId == "" {
+		return "", errors.New(constants.JwtTokenInvalidError)
 	}
+	return tokenId, nil
+}
 
-	exp, ok := claims["exp"]
+func GetCustomerIdFromClaims(claims jwt.MapClaims) (string, error) {
+	id, ok := claims["sub"].(string)
 	if !ok {
-		return "", errors.New("expiration claim (exp) not found in token")
+		return "", errors.New("Customer Id (sub) not found in token")
 	}
+	return id, nil
+}
 
-	expStr := ""
-	switch v := exp.(type) {
-	case float64:
-		expStr = strconv.FormatInt(int64(v), 10)
-	case string:
-		expStr = v
-	default:
-		return "", errors.New("unexpected type for expiration claim")
+func GetRoleFromClaims(claims jwt.MapClaims) enums.Role {
+	// Tokens issued before roles were introduced carry no role
+	role, ok := claims["role"].(string)
+	if !ok || role == "" {
+		return enums.ROLE_USER
 	}
-
-	return expStr, nil
+	return enums.Role(role)
 }
 
-func GetCustomerIdFromClaims(accessToken string) (string, error) {
-	claims, err := ParseAndVerifyAccessToken(accessToken)
-	if err != nil {
-		return "", err
-	}
+func GetSessionIdFromClaims(claims jwt.MapClaims) string {
+	// Tokens issued before sessions were introduced carry no session
+	sessionId, _ := claims["sid"].(string)
+	return sessionId
+}
 
-	id, ok := claims["sub"]
+// getTimeFromClaims reads a NumericDate claim, seconds since the epoch
+func getTimeFromClaims(claims jwt.MapClaims, name string) (time.Time, error) {
+	value, ok := claims[name].(float64)
 	if !ok {
-		return "", errors.New("Customer Id (sub) not found in token")
+		return time.Time{}, fmt.Errorf("%s claim not found in token", name)
 	}
-	return id.(string), nil
+	return time.Unix(int64(value), 0), nil
 }
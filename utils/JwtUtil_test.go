@@ -13,7 +13,7 @@ func TestGenerateAccessToken(t *testing.T) {
 		Password: "password",
 	}
 
-	token, err := GenerateAccessToken(customer)
+	token, err := GenerateAccessToken(customer, "session-1")
 	assert.NotEmpty(t, token)
 	assert.Nil(t, err)
 }
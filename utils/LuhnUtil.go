@@ -0,0 +1,31 @@
+package utils
+
+// LuhnCheckDigit computes the Luhn check digit to append to a string of decimal digits
+func LuhnCheckDigit(digits string) byte {
+	sum := 0
+	// Walking from the right, every first digit is doubled since the check digit will follow it
+	for i := len(digits) - 1; i >= 0; i-- {
+		digit := int(digits[i] - '0')
+		if (len(digits)-1-i)%2 == 0 {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+	}
+	return byte('0' + (10-sum%10)%10)
+}
+
+// IsValidLuhn reports whether a number only has decimal digits and ends with a valid Luhn check digit
+func IsValidLuhn(number string) bool {
+	if len(number) < 2 {
+		return false
+	}
+	for i := 0; i < len(number); i++ {
+		if number[i] < '0' || number[i] > '9' {
+			return false
+		}
+	}
+	return LuhnCheckDigit(number[:len(number)-1]) == number[len(number)-1]
+}
@@ -0,0 +1,21 @@
+package utils
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestLuhn(t *testing.T) {
+	t.Run("ShouldComputeCheckDigit", func(t *testing.T) {
+		assert.Equal(t, byte('3'), LuhnCheckDigit("7992739871"))
+		assert.Equal(t, byte('1'), LuhnCheckDigit("411111111111111"))
+	})
+
+	t.Run("ShouldValidateNumbers", func(t *testing.T) {
+		assert.True(t, IsValidLuhn("79927398713"))
+		assert.True(t, IsValidLuhn("4111111111111111"))
+		assert.False(t, IsValidLuhn("79927398710"))
+		assert.False(t, IsValidLuhn("7992739871a"))
+		assert.False(t, IsValidLuhn("7"))
+	})
+}
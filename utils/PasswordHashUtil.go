@@ -0,0 +1,105 @@
+package utils
+
+import (
+	"PaymentAPI/config"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+	"strings"
+)
+
+const (
+	argon2SaltLength = 16
+	argon2KeyLength  = 32
+)
+
+// argon2Parameters are the settings an argon2id hash was made with, kept in the hash itself
+type argon2Parameters struct {
+	memory      uint32
+	iterations  uint32
+	parallelism uint8
+}
+
+// HashPassword hashes a password with the configured algorithm. Argon2id hashes use the PHC string format
+// $argon2id$v=19$m=<memory>,t=<iterations>,p=<parallelism>$<salt>$<hash>, so they can be verified with other tools.
+func HashPassword(password string) (string, error) {
+	if config.PasswordHashAlgorithm != "argon2id" {
+		return BCryptEncoder(password, config.BCryptCost)
+	}
+
+	salt := make([]byte, argon2SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	parameters := configuredArgon2Parameters()
+	key := argon2.IDKey([]byte(password), salt, parameters.iterations, parameters.memory, parameters.parallelism, argon2KeyLength)
+
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version,
+		parameters.memory, parameters.iterations, parameters.parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// VerifyPassword reports whether the password matches a bcrypt or argon2id hash, regardless of the configured algorithm
+func VerifyPassword(password string, hashedPassword string) bool {
+	if !strings.HasPrefix(hashedPassword, "$argon2id$") {
+		return BCryptCompare(password, []byte(hashedPassword))
+	}
+
+	parameters, salt, key, err := decodeArgon2Hash(hashedPassword)
+	if err != nil {
+		return false
+	}
+	computed := argon2.IDKey([]byte(password), salt, parameters.iterations, parameters.memory, parameters.parallelism, uint32(len(key)))
+	return subtle.ConstantTimeCompare(computed, key) == 1
+}
+
+// PasswordNeedsRehash reports whether a hash was made with another algorithm or weaker settings than configured,
+// so it should be replaced the next time the password is known
+func PasswordNeedsRehash(hashedPassword string) bool {
+	if config.PasswordHashAlgorithm != "argon2id" {
+		cost, err := bcrypt.Cost([]byte(hashedPassword))
+		return err != nil || cost < max(config.BCryptCost, bcrypt.DefaultCost)
+	}
+
+	parameters, _, _, err := decodeArgon2Hash(hashedPassword)
+	return err != nil || parameters != configuredArgon2Parameters()
+}
+
+func configuredArgon2Parameters() argon2Parameters {
+	return argon2Parameters{
+		memory:      uint32(config.Argon2Memory),
+		iterations:  uint32(config.Argon2Iterations),
+		parallelism: uint8(config.Argon2Parallelism),
+	}
+}
+
+// decodeArgon2Hash splits an argon2id PHC string into its parameters, salt and key
+func decodeArgon2Hash(hashedPassword string) (argon2Parameters, []byte, []byte, error) {
+	parts := strings.Split(hashedPassword, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return argon2Parameters{}, nil, nil, fmt.Errorf("invalid argon2id hash")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return argon2Parameters{}, nil, nil, fmt.Errorf("unsupported argon2id version")
+	}
+
+	var parameters argon2Parameters
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &parameters.memory, &parameters.iterations, &parameters.parallelism); err != nil {
+		return argon2Parameters{}, nil, nil, err
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return argon2Parameters{}, nil, nil, err
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return argon2Parameters{}, nil, nil, err
+	}
+	return parameters, salt, key, nil
+}
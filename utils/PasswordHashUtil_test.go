@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"PaymentAPI/config"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+	"strings"
+	"testing"
+)
+
+func setPasswordHash(t *testing.T, algorithm string) {
+	previousAlgorithm, cost := config.PasswordHashAlgorithm, config.BCryptCost
+	memory, iterations, parallelism := config.Argon2Memory, config.Argon2Iterations, config.Argon2Parallelism
+	t.Cleanup(func() {
+		config.PasswordHashAlgorithm, config.BCryptCost = previousAlgorithm, cost
+		config.Argon2Memory, config.Argon2Iterations, config.Argon2Parallelism = memory, iterations, parallelism
+	})
+
+	config.PasswordHashAlgorithm, config.BCryptCost = algorithm, bcrypt.MinCost
+	config.Argon2Memory, config.Argon2Iterations, config.Argon2Parallelism = 1024, 1, 1
+}
+
+func TestHashPassword(t *testing.T) {
+	t.Run("ShouldHashWithBcrypt", func(t *testing.T) {
+		setPasswordHash(t, "bcrypt")
+
+		hashedPassword, err := HashPassword("Correct-Horse-42")
+		assert.Nil(t, err)
+		assert.True(t, strings.HasPrefix(hashedPassword, "$2a$04$"))
+		assert.True(t, VerifyPassword("Correct-Horse-42", hashedPassword))
+		assert.False(t, VerifyPassword("Correct-Horse-43", hashedPassword))
+	})
+
+	t.Run("ShouldHashWithArgon2id", func(t *testing.T) {
+		setPasswordHash(t, "argon2id")
+
+		hashedPassword, err := HashPassword("Correct-Horse-42")
+		assert.Nil(t, err)
+		assert.True(t, strings.HasPrefix(hashedPassword, "$argon2id$v=19$m=1024,t=1,p=1$"))
+		assert.True(t, VerifyPassword("Correct-Horse-42", hashedPassword))
+		assert.False(t, VerifyPassword("Correct-Horse-43", hashedPassword))
+		assert.False(t, VerifyPassword("Correct-Horse-42", "$argon2id$v=19$m=1024,t=1,p=1$invalid"))
+	})
+}
+
+func TestPasswordNeedsRehash(t *testing.T) {
+	setPasswordHash(t, "bcrypt")
+	bcryptHash, _ := HashPassword("Correct-Horse-42")
+
+	config.PasswordHashAlgorithm = "argon2id"
+	argon2Hash, _ := HashPassword("Correct-Horse-42")
+
+	t.Run("ShouldUpgradeBcryptToArgon2id", func(t *testing.T) {
+		config.PasswordHashAlgorithm = "argon2id"
+		assert.True(t, PasswordNeedsRehash(bcryptHash))
+		assert.False(t, PasswordNeedsRehash(argon2Hash))
+	})
+
+	t.Run("ShouldUpgradeWeakerArgon2idParameters", func(t *testing.T) {
+		config.PasswordHashAlgorithm, config.Argon2Iterations = "argon2id", 2
+		defer func() { config.Argon2Iterations = 1 }()
+		assert.True(t, PasswordNeedsRehash(argon2Hash))
+	})
+
+	t.Run("ShouldUpgradeLowerBcryptCost", func(t *testing.T) {
+		config.PasswordHashAlgorithm, config.BCryptCost = "bcrypt", 12
+		assert.True(t, PasswordNeedsRehash(bcryptHash))
+		assert.True(t, PasswordNeedsRehash(argon2Hash))
+	})
+}
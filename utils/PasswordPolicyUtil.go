@@ -0,0 +1,91 @@
+package utils
+
+import (
+	"PaymentAPI/config"
+	"PaymentAPI/constants"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// commonPasswords are refused whatever the configured blocklist, they are the first ones tried in a guessing attack
+var commonPasswords = []string{
+	"password", "password1", "password123", "passw0rd", "p@ssw0rd", "123456", "12345678", "123456789", "1234567890",
+	"qwerty", "qwerty123", "qwertyuiop", "abc123", "111111", "123123", "letmein", "welcome", "welcome1", "admin",
+	"admin123", "iloveyou", "monkey", "dragon", "sunshine", "princess", "football", "baseball", "master", "shadow",
+	"superman", "trustno1", "changeme", "secret", "login",
+}
+
+// PasswordPolicyError lists every rule of the password policy a password breaks, for the request field it came from
+type PasswordPolicyError struct {
+	Field      string
+	Violations []string
+}
+
+func (p *PasswordPolicyError) Error() string {
+	return constants.PasswordPolicyError
+}
+
+// CheckPasswordPolicy checks a new password against the configured length, character classes and blocklist, and
+// that it does not contain the username. It returns a PasswordPolicyError with every broken rule, or nil.
+func CheckPasswordPolicy(field string, password string, username string) error {
+	var violations []string
+
+	if utf8.RuneCountInString(password) < config.PasswordMinLength {
+		violations = append(violations, fmt.Sprintf(constants.PasswordTooShortError, config.PasswordMinLength))
+	}
+	if config.PasswordMaxLength > 0 && len(password) > config.PasswordMaxLength {
+		violations = append(violations, fmt.Sprintf(constants.PasswordTooLongError, config.PasswordMaxLength))
+	}
+
+	var hasUppercase, hasLowercase, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUppercase = true
+		case unicode.IsLower(r):
+			hasLowercase = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			hasSymbol = true
+		}
+	}
+	if config.PasswordRequireUppercase && !hasUppercase {
+		violations = append(violations, constants.PasswordUppercaseError)
+	}
+	if config.PasswordRequireLowercase && !hasLowercase {
+		violations = append(violations, constants.PasswordLowercaseError)
+	}
+	if config.PasswordRequireDigit && !hasDigit {
+		violations = append(violations, constants.PasswordDigitError)
+	}
+	if config.PasswordRequireSymbol && !hasSymbol {
+		violations = append(violations, constants.PasswordSymbolError)
+	}
+
+	if isCommonPassword(password) {
+		violations = append(violations, constants.PasswordCommonError)
+	}
+	if username != "" && strings.Contains(strings.ToLower(password), strings.ToLower(username)) {
+		violations = append(violations, constants.PasswordUsernameError)
+	}
+
+	if len(violations) > 0 {
+		return &PasswordPolicyError{Field: field, Violations: violations}
+	}
+	return nil
+}
+
+// isCommonPassword compares case-insensitively, "Password1" is guessed as early as "password1"
+func isCommonPassword(password string) bool {
+	for _, blocklist := range [][]string{commonPasswords, config.PasswordBlocklist} {
+		for _, common := range blocklist {
+			if strings.EqualFold(password, common) {
+				return true
+			}
+		}
+	}
+	return false
+}
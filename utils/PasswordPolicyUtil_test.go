@@ -0,0 +1,67 @@
+package utils
+
+import (
+	"PaymentAPI/config"
+	"PaymentAPI/constants"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func setPasswordPolicy(t *testing.T) {
+	minLength, maxLength := config.PasswordMinLength, config.PasswordMaxLength
+	upper, lower, digit, symbol := config.PasswordRequireUppercase, config.PasswordRequireLowercase, config.PasswordRequireDigit, config.PasswordRequireSymbol
+	blocklist := config.PasswordBlocklist
+	t.Cleanup(func() {
+		config.PasswordMinLength, config.PasswordMaxLength = minLength, maxLength
+		config.PasswordRequireUppercase, config.PasswordRequireLowercase, config.PasswordRequireDigit, config.PasswordRequireSymbol = upper, lower, digit, symbol
+		config.PasswordBlocklist = blocklist
+	})
+
+	config.PasswordMinLength, config.PasswordMaxLength = 8, 72
+	config.PasswordRequireUppercase, config.PasswordRequireLowercase, config.PasswordRequireDigit, config.PasswordRequireSymbol = true, true, true, true
+	config.PasswordBlocklist = []string{"Summer-2024"}
+}
+
+func TestCheckPasswordPolicy(t *testing.T) {
+	setPasswordPolicy(t)
+
+	t.Run("ShouldAcceptStrongPassword", func(t *testing.T) {
+		assert.Nil(t, CheckPasswordPolicy("password", "Correct-Horse-42", "johndoe"))
+	})
+
+	t.Run("ShouldListEveryViolation", func(t *testing.T) {
+		err := CheckPasswordPolicy("password", "abc", "johndoe")
+		var policyErr *PasswordPolicyError
+		assert.ErrorAs(t, err, &policyErr)
+		assert.Equal(t, "password", policyErr.Field)
+		assert.Equal(t, []string{
+			fmt.Sprintf(constants.PasswordTooShortError, 8),
+			constants.PasswordUppercaseError,
+			constants.PasswordDigitError,
+			constants.PasswordSymbolError,
+		}, policyErr.Violations)
+	})
+
+	t.Run("ShouldRejectCommonPasswordCaseInsensitively", func(t *testing.T) {
+		var policyErr *PasswordPolicyError
+		assert.ErrorAs(t, CheckPasswordPolicy("password", "P@ssw0rd", ""), &policyErr)
+		assert.Contains(t, policyErr.Violations, constants.PasswordCommonError)
+
+		assert.ErrorAs(t, CheckPasswordPolicy("password", "summer-2024", ""), &policyErr)
+		assert.Contains(t, policyErr.Violations, constants.PasswordCommonError)
+	})
+
+	t.Run("ShouldRejectUsernameInPassword", func(t *testing.T) {
+		var policyErr *PasswordPolicyError
+		assert.ErrorAs(t, CheckPasswordPolicy("new_password", "JohnDoe-2024", "johndoe"), &policyErr)
+		assert.Equal(t, []string{constants.PasswordUsernameError}, policyErr.Violations)
+	})
+
+	t.Run("ShouldRejectPasswordLongerThanBcryptAccepts", func(t *testing.T) {
+		var policyErr *PasswordPolicyError
+		password := "Aa1-" + fmt.Sprintf("%070d", 0)
+		assert.ErrorAs(t, CheckPasswordPolicy("password", password, ""), &policyErr)
+		assert.Equal(t, []string{fmt.Sprintf(constants.PasswordTooLongError, 72)}, policyErr.Violations)
+	})
+}
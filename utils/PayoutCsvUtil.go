@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"PaymentAPI/constants"
+	req "PaymentAPI/dto/request"
+	"PaymentAPI/entity"
+	"encoding/csv"
+	"errors"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var payoutReportHeader = []string{"row", "recipient_wallet_id", "amount", "message", "status", "transaction_id", "error_message"}
+
+// ParsePayoutCsv reads payout rows from a CSV file with a recipient_wallet_id, amount and optional message column.
+// An amount that is not a finite number, such as "abc", "NaN" or "Inf", is read as zero, so the row is reported as
+// invalid instead of failing the whole file.
+func ParsePayoutCsv(reader io.Reader) ([]req.PayoutItemRequest, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.TrimLeadingSpace = true
+	csvReader.FieldsPerRecord = -1
+
+	header, err := csvReader.Read()
+	if err != nil {
+		return nil, errors.New(constants.PayoutCsvInvalidError)
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	recipientColumn, hasRecipient := columns["recipient_wallet_id"]
+	amountColumn, hasAmount := columns["amount"]
+	messageColumn, hasMessage := columns["message"]
+	if !hasRecipient || !hasAmount {
+		return nil, errors.New(constants.PayoutCsvInvalidError)
+	}
+
+	var items []req.PayoutItemRequest
+	for {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.New(constants.PayoutCsvInvalidError)
+		}
+
+		item := req.PayoutItemRequest{RecipientWalletId: field(record, recipientColumn)}
+		if amount, err := strconv.ParseFloat(field(record, amountColumn), 64); err == nil && !math.IsNaN(amount) && !math.IsInf(amount, 0) {
+			item.Amount = amount
+		}
+		if hasMessage {
+			item.Message = field(record, messageColumn)
+		}
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// WritePayoutReport writes the result of every row of a payout batch as CSV
+func WritePayoutReport(writer io.Writer, batch entity.PayoutBatch) error {
+	csvWriter := csv.NewWriter(writer)
+	if err := csvWriter.Write(payoutReportHeader); err != nil {
+		return err
+	}
+
+	for _, item := range batch.Items {
+		record := []string{
+			strconv.Itoa(item.Row),
+			item.RecipientWalletId,
+			strconv.FormatFloat(item.Amount, 'f', -1, 64),
+			item.Message,
+			string(item.Status),
+			item.TransactionId,
+			item.ErrorMessage,
+		}
+		if err := csvWriter.Write(record); err != nil {
+			return err
+		}
+	}
+
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+func field(record []string, column int) string {
+	if column >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[column])
+}
@@ -0,0 +1,52 @@
+package utils
+
+import (
+	"PaymentAPI/constants"
+	req "PaymentAPI/dto/request"
+	"PaymentAPI/entity"
+	"PaymentAPI/enums"
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestParsePayoutCsv(t *testing.T) {
+	t.Run("ShouldParseRowsInAnyColumnOrder", func(t *testing.T) {
+		csv := "amount,recipient_wallet_id,message\n1500.50,wallet-2,Salary\nabc,wallet-3,\n"
+
+		items, err := ParsePayoutCsv(strings.NewReader(csv))
+		assert.Nil(t, err)
+		assert.Equal(t, []req.PayoutItemRequest{
+			{RecipientWalletId: "wallet-2", Amount: 1500.50, Message: "Salary"},
+			{RecipientWalletId: "wallet-3", Amount: 0},
+		}, items)
+	})
+
+	t.Run("ShouldReadAmountThatIsNotFiniteAsZero", func(t *testing.T) {
+		csv := "recipient_wallet_id,amount\nwallet-2,NaN\nwallet-3,Inf\nwallet-4,-Infinity\nwallet-5,1e400\n"
+
+		items, err := ParsePayoutCsv(strings.NewReader(csv))
+		assert.Nil(t, err)
+		assert.Len(t, items, 4)
+		for _, item := range items {
+			assert.Equal(t, float64(0), item.Amount, item.RecipientWalletId)
+		}
+	})
+
+	t.Run("ShouldFailWithoutRequiredColumns", func(t *testing.T) {
+		_, err := ParsePayoutCsv(strings.NewReader("recipient,amount\nwallet-2,100\n"))
+		assert.Equal(t, constants.PayoutCsvInvalidError, err.Error())
+	})
+}
+
+func TestWritePayoutReport(t *testing.T) {
+	batch := entity.PayoutBatch{Items: []entity.PayoutItem{
+		{Row: 1, RecipientWalletId: "wallet-2", Amount: 1500.5, Message: "Salary, June", Status: enums.PAYOUT_ITEM_SUCCEEDED, TransactionId: "transaction-1"},
+	}}
+
+	var buffer bytes.Buffer
+	assert.Nil(t, WritePayoutReport(&buffer, batch))
+	assert.Equal(t, "row,recipient_wallet_id,amount,message,status,transaction_id,error_message\n"+
+		"1,wallet-2,1500.5,\"Salary, June\",SUCCEEDED,transaction-1,\n", buffer.String())
+}
@@ -0,0 +1,197 @@
+package utils
+
+import (
+	"PaymentAPI/constants"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Tags of the EMVCo merchant-presented QR payload, the layout QRIS is based on
+const (
+	qrTagPayloadFormat     = "00"
+	qrTagPointOfInitiation = "01"
+	qrTagMerchantAccount   = "26"
+	qrTagMerchantCategory  = "52"
+	qrTagCurrency          = "53"
+	qrTagAmount            = "54"
+	qrTagCountry           = "58"
+	qrTagMerchantName      = "59"
+	qrTagMerchantCity      = "60"
+	qrTagAdditionalData    = "62"
+	qrTagCrc               = "63"
+
+	// Sub-tags of the merchant account information template
+	qrTagGloballyUniqueId  = "00"
+	qrTagWalletId          = "01"
+	qrTagCheckoutSessionId = "02"
+
+	// Sub-tag of the additional data template
+	qrTagReferenceLabel = "05"
+)
+
+const (
+	qrGloballyUniqueId  = "ID.PAYMENTAPI.WALLET"
+	qrPayloadFormat     = "01"
+	qrStaticInitiation  = "11"
+	qrDynamicInitiation = "12"
+	qrMerchantCategory  = "0000"
+	qrCurrencyIdr       = "360"
+	qrCountryIndonesia  = "ID"
+	qrMerchantCity      = "JAKARTA"
+)
+
+// QrPayment holds the fields of a payment QR payload. A checkout payload only carries the checkout session ID,
+// the receiving wallet is the one of the session's merchant.
+type QrPayment struct {
+	WalletId          string
+	CheckoutSessionId string
+	Amount            float64
+	MerchantName      string
+	Reference         string
+}
+
+// BuildQrPayload encodes a payment as an EMVCo merchant-presented QR payload, ending with its CRC.
+// A payload with an amount is dynamic, the payer cannot change the amount.
+func BuildQrPayload(payment QrPayment) string {
+	// A template is limited to 99 characters, so it carries either the wallet or the checkout session
+	merchantAccount := encodeTlv(qrTagGloballyUniqueId, qrGloballyUniqueId)
+	if payment.CheckoutSessionId != "" {
+		merchantAccount += encodeTlv(qrTagCheckoutSessionId, payment.CheckoutSessionId)
+	} else {
+		merchantAccount += encodeTlv(qrTagWalletId, payment.WalletId)
+	}
+
+	initiation := qrStaticInitiation
+	if payment.Amount > 0 {
+		initiation = qrDynamicInitiation
+	}
+
+	var payload strings.Builder
+	payload.WriteString(encodeTlv(qrTagPayloadFormat, qrPayloadFormat))
+	payload.WriteString(encodeTlv(qrTagPointOfInitiation, initiation))
+	payload.WriteString(encodeTlv(qrTagMerchantAccount, merchantAccount))
+	payload.WriteString(encodeTlv(qrTagMerchantCategory, qrMerchantCategory))
+	payload.WriteString(encodeTlv(qrTagCurrency, qrCurrencyIdr))
+	if payment.Amount > 0 {
+		payload.WriteString(encodeTlv(qrTagAmount, strconv.FormatFloat(payment.Amount, 'f', -1, 64)))
+	}
+	payload.WriteString(encodeTlv(qrTagCountry, qrCountryIndonesia))
+	payload.WriteString(encodeTlv(qrTagMerchantName, truncate(payment.MerchantName, 25)))
+	payload.WriteString(encodeTlv(qrTagMerchantCity, qrMerchantCity))
+	if payment.Reference != "" {
+		payload.WriteString(encodeTlv(qrTagAdditionalData, encodeTlv(qrTagReferenceLabel, truncate(payment.Reference, 25))))
+	}
+
+	// The CRC covers the whole payload including the tag and length of the CRC field itself
+	payload.WriteString(qrTagCrc + "04")
+	return payload.String() + fmt.Sprintf("%04X", Crc16Ccitt([]byte(payload.String())))
+}
+
+// ParseQrPayload validates the structure and CRC of a QR payload and decodes the payment it describes
+func ParseQrPayload(payload string) (QrPayment, error) {
+	payload = strings.TrimSpace(payload)
+	if len(payload) < 8 || payload[len(payload)-8:len(payload)-4] != qrTagCrc+"04" {
+		return QrPayment{}, errors.New(constants.QrPayloadInvalidError)
+	}
+
+	expectedCrc, err := strconv.ParseUint(payload[len(payload)-4:], 16, 16)
+	if err != nil {
+		return QrPayment{}, errors.New(constants.QrPayloadInvalidError)
+	}
+	if uint16(expectedCrc) != Crc16Ccitt([]byte(payload[:len(payload)-4])) {
+		return QrPayment{}, errors.New(constants.QrChecksumError)
+	}
+
+	fields, err := decodeTlv(payload[:len(payload)-8])
+	if err != nil {
+		return QrPayment{}, err
+	}
+	if fields[qrTagPayloadFormat] != qrPayloadFormat || fields[qrTagCurrency] != qrCurrencyIdr {
+		return QrPayment{}, errors.New(constants.QrUnsupportedError)
+	}
+
+	merchantAccount, err := decodeTlv(fields[qrTagMerchantAccount])
+	if err != nil {
+		return QrPayment{}, err
+	}
+	if merchantAccount[qrTagGloballyUniqueId] != qrGloballyUniqueId {
+		return QrPayment{}, errors.New(constants.QrUnsupportedError)
+	}
+	if (merchantAccount[qrTagWalletId] == "") == (merchantAccount[qrTagCheckoutSessionId] == "") {
+		return QrPayment{}, errors.New(constants.QrUnsupportedError)
+	}
+
+	payment := QrPayment{
+		WalletId:          merchantAccount[qrTagWalletId],
+		CheckoutSessionId: merchantAccount[qrTagCheckoutSessionId],
+		MerchantName:      fields[qrTagMerchantName],
+	}
+
+	if amount, ok := fields[qrTagAmount]; ok {
+		payment.Amount, err = strconv.ParseFloat(amount, 64)
+		if err != nil || payment.Amount <= 0 {
+			return QrPayment{}, errors.New(constants.QrPayloadInvalidError)
+		}
+	}
+
+	if additionalData, ok := fields[qrTagAdditionalData]; ok {
+		additionalFields, err := decodeTlv(additionalData)
+		if err != nil {
+			return QrPayment{}, err
+		}
+		payment.Reference = additionalFields[qrTagReferenceLabel]
+	}
+
+	return payment, nil
+}
+
+// Crc16Ccitt computes the CRC-16/CCITT-FALSE checksum (polynomial 0x1021, initial value 0xFFFF) used by EMV QR codes
+func Crc16Ccitt(data []byte) uint16 {
+	crc := uint16(0xFFFF)
+	for _, b := range data {
+		crc ^= uint16(b) << 8
+		for i := 0; i < 8; i++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
+
+// encodeTlv encodes a field as its two digit tag, two digit length and value
+func encodeTlv(tag string, value string) string {
+	return fmt.Sprintf("%s%02d%s", tag, len(value), value)
+}
+
+// decodeTlv decodes consecutive tag-length-value fields, rejecting duplicated or truncated fields
+func decodeTlv(data string) (map[string]string, error) {
+	fields := map[string]string{}
+	for len(data) > 0 {
+		if len(data) < 4 {
+			return nil, errors.New(constants.QrPayloadInvalidError)
+		}
+		tag := data[:2]
+		length, err := strconv.Atoi(data[2:4])
+		if err != nil || length < 0 || len(data) < 4+length {
+			return nil, errors.New(constants.QrPayloadInvalidError)
+		}
+		if _, exists := fields[tag]; exists {
+			return nil, errors.New(constants.QrPayloadInvalidError)
+		}
+		fields[tag] = data[4 : 4+length]
+		data = data[4+length:]
+	}
+	return fields, nil
+}
+
+func truncate(value string, length int) string {
+	if len(value) > length {
+		return value[:length]
+	}
+	return value
+}
@@ -0,0 +1,58 @@
+package utils
+
+import (
+	"PaymentAPI/constants"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestCrc16Ccitt(t *testing.T) {
+	assert.Equal(t, uint16(0x29B1), Crc16Ccitt([]byte("123456789")))
+}
+
+func TestQrPayload(t *testing.T) {
+	t.Run("ShouldRoundTripDynamicWalletPayload", func(t *testing.T) {
+		payment := QrPayment{WalletId: "1070f292-5d68-4b30-b37f-32042675ef2a", Amount: 15000.5, MerchantName: "johndoe"}
+
+		payload := BuildQrPayload(payment)
+		assert.True(t, strings.HasPrefix(payload, "000201010212"))
+
+		parsed, err := ParseQrPayload(payload)
+		assert.Nil(t, err)
+		assert.Equal(t, payment, parsed)
+	})
+
+	t.Run("ShouldRoundTripStaticCheckoutPayload", func(t *testing.T) {
+		payment := QrPayment{CheckoutSessionId: "198a1bff-50a7-4a3f-a18c-a724dea104de", MerchantName: "Coffee Shop", Reference: "ORDER-1"}
+
+		payload := BuildQrPayload(payment)
+		assert.True(t, strings.HasPrefix(payload, "000201010211"))
+
+		parsed, err := ParseQrPayload(payload)
+		assert.Nil(t, err)
+		assert.Equal(t, payment, parsed)
+	})
+
+	t.Run("ShouldRejectModifiedAmount", func(t *testing.T) {
+		payload := BuildQrPayload(QrPayment{WalletId: "wallet-1", Amount: 15000})
+		tampered := strings.Replace(payload, "540515000", "540595000", 1)
+
+		_, err := ParseQrPayload(tampered)
+		assert.Equal(t, constants.QrChecksumError, err.Error())
+	})
+
+	t.Run("ShouldRejectForeignPayload", func(t *testing.T) {
+		body := "000201010211" + "2620" + "0008ID.OTHER" + "0104abcd" + "5303360" + "6304"
+		payload := body + fmt.Sprintf("%04X", Crc16Ccitt([]byte(body)))
+
+		_, err := ParseQrPayload(payload)
+		assert.Equal(t, constants.QrUnsupportedError, err.Error())
+	})
+
+	t.Run("ShouldRejectTruncatedPayload", func(t *testing.T) {
+		_, err := ParseQrPayload("0002016304ABCD")
+		assert.NotNil(t, err)
+	})
+}
@@ -0,0 +1,16 @@
+package utils
+
+import "time"
+
+// RunEvery runs job on a fixed interval until the process exits, a non-positive interval disables it
+func RunEvery(interval time.Duration, job func()) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		job()
+	}
+}
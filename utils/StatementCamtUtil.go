@@ -0,0 +1,220 @@
+package utils
+
+import (
+	"PaymentAPI/entity"
+	"PaymentAPI/enums"
+	"encoding/xml"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Text length limits of the schema. A wallet or transaction UUID is written without hyphens to fit Max35Text.
+const (
+	camtMax34Text  = 34
+	camtMax35Text  = 35
+	camtMax140Text = 140
+)
+
+// camtDocument is an ISO 20022 bank to customer statement, version 2, the one most banks exchange
+type camtDocument struct {
+	XMLName   xml.Name          `xml:"urn:iso:std:iso:20022:tech:xsd:camt.053.001.02 Document"`
+	Statement camtBankStatement `xml:"BkToCstmrStmt"`
+}
+
+type camtBankStatement struct {
+	GroupHeader camtGroupHeader `xml:"GrpHdr"`
+	Statement   camtStatement   `xml:"Stmt"`
+}
+
+type camtGroupHeader struct {
+	MessageId string `xml:"MsgId"`
+	CreatedAt string `xml:"CreDtTm"`
+}
+
+type camtStatement struct {
+	Id        string        `xml:"Id"`
+	CreatedAt string        `xml:"CreDtTm"`
+	From      string        `xml:"FrToDt>FrDtTm"`
+	To        string        `xml:"FrToDt>ToDtTm"`
+	Account   camtAccount   `xml:"Acct"`
+	Balances  []camtBalance `xml:"Bal"`
+	Summary   camtSummary   `xml:"TxsSummry"`
+	Entries   []camtEntry   `xml:"Ntry"`
+}
+
+type camtAccount struct {
+	Id       string `xml:"Id>Othr>Id"`
+	Currency string `xml:"Ccy"`
+	OwnerId  string `xml:"Ownr>Id>PrvtId>Othr>Id"`
+}
+
+type camtAmount struct {
+	Currency string `xml:"Ccy,attr"`
+	Value    string `xml:",chardata"`
+}
+
+type camtBalance struct {
+	Type      string     `xml:"Tp>CdOrPrtry>Cd"`
+	Amount    camtAmount `xml:"Amt"`
+	Indicator string     `xml:"CdtDbtInd"`
+	Date      string     `xml:"Dt>Dt"`
+}
+
+type camtSummary struct {
+	Count        int              `xml:"TtlNtries>NbOfNtries"`
+	Sum          string           `xml:"TtlNtries>Sum"`
+	NetAmount    string           `xml:"TtlNtries>TtlNetNtryAmt"`
+	NetIndicator string           `xml:"TtlNtries>CdtDbtInd"`
+	Credits      camtNumberAndSum `xml:"TtlCdtNtries"`
+	Debits       camtNumberAndSum `xml:"TtlDbtNtries"`
+}
+
+type camtNumberAndSum struct {
+	Count int    `xml:"NbOfNtries"`
+	Sum   string `xml:"Sum"`
+}
+
+type camtEntry struct {
+	Reference   string          `xml:"NtryRef"`
+	Amount      camtAmount      `xml:"Amt"`
+	Indicator   string          `xml:"CdtDbtInd"`
+	Status      string          `xml:"Sts"`
+	BookedAt    string          `xml:"BookgDt>DtTm"`
+	ValueDate   string          `xml:"ValDt>Dt"`
+	ServicerRef string          `xml:"AcctSvcrRef"`
+	Domain      string          `xml:"BkTxCd>Domn>Cd"`
+	Family      string          `xml:"BkTxCd>Domn>Fmly>Cd"`
+	SubFamily   string          `xml:"BkTxCd>Domn>Fmly>SubFmlyCd"`
+	Details     camtTransaction `xml:"NtryDtls>TxDtls"`
+}
+
+type camtTransaction struct {
+	TransactionId   string            `xml:"Refs>TxId"`
+	DebtorAccount   *camtPartyAccount `xml:"RltdPties>DbtrAcct,omitempty"`
+	CreditorAccount *camtPartyAccount `xml:"RltdPties>CdtrAcct,omitempty"`
+	Unstructured    string            `xml:"RmtInf>Ustrd,omitempty"`
+}
+
+type camtPartyAccount struct {
+	Id string `xml:"Id>Othr>Id"`
+}
+
+// WriteStatementCamt053 writes a statement as an ISO 20022 camt.053.001.02 bank to customer statement. The wallet is
+// the account and every transaction is a booked entry with its booking date, value date and the transaction ID as
+// reference. Amounts are always positive, the direction is in the credit or debit indicator. The statement ID is the
+// start of the wallet ID followed by the first and last day, so the same period always gets the same ID.
+func WriteStatementCamt053(writer io.Writer, statement entity.Statement) error {
+	from, _ := time.ParseInLocation(time.DateOnly, statement.PeriodStart, time.Local)
+	to, _ := time.ParseInLocation(time.DateOnly, statement.PeriodEnd, time.Local)
+	compactWalletId := camtId(statement.WalletId, camtMax34Text)
+	walletPrefix := compactWalletId[:min(len(compactWalletId), 16)]
+
+	camtStatement := camtStatement{
+		Id:        walletPrefix + from.Format("20060102") + to.Format("20060102"),
+		CreatedAt: statement.GeneratedAt,
+		From:      from.Format(time.RFC3339),
+		To:        to.Add(24*time.Hour - time.Second).Format(time.RFC3339),
+		Account: camtAccount{
+			Id:       compactWalletId,
+			Currency: statement.Currency,
+			OwnerId:  camtId(statement.CustomerId, camtMax35Text),
+		},
+		Balances: []camtBalance{
+			camtBalanceOf("OPBD", statement.OpeningBalance, statement.Currency, statement.PeriodStart),
+			camtBalanceOf("CLBD", statement.ClosingBalance, statement.Currency, statement.PeriodEnd),
+		},
+	}
+
+	var credits, debits camtNumberAndSum
+	for _, entry := range statement.Entries {
+		transactionId := camtId(entry.TransactionId, camtMax35Text)
+		counterparty := &camtPartyAccount{Id: camtId(entry.Counterparty, camtMax34Text)}
+
+		camtEntry := camtEntry{
+			Reference:   transactionId,
+			Amount:      camtAmount{Currency: statement.Currency, Value: formatAmount(math.Abs(entry.Amount))},
+			Status:      "BOOK",
+			BookedAt:    entry.BookedAt,
+			ValueDate:   statementDate(entry.BookedAt),
+			ServicerRef: transactionId,
+			Domain:      "PMNT",
+			SubFamily:   "DMCT",
+			Details: camtTransaction{
+				TransactionId: transactionId,
+				Unstructured:  truncate(entry.Description, camtMax140Text),
+			},
+		}
+
+		// A received transfer names the payer's account, an issued one the payee's
+		if entry.Type == enums.STATEMENT_CREDIT {
+			camtEntry.Indicator = "CRDT"
+			camtEntry.Family = "RCDT"
+			camtEntry.Details.DebtorAccount = counterparty
+			credits.Count++
+		} else {
+			camtEntry.Indicator = "DBIT"
+			camtEntry.Family = "ICDT"
+			camtEntry.Details.CreditorAccount = counterparty
+			debits.Count++
+		}
+		camtStatement.Entries = append(camtStatement.Entries, camtEntry)
+	}
+
+	credits.Sum = formatAmount(statement.TotalCredits)
+	debits.Sum = formatAmount(statement.TotalDebits)
+	net := statement.TotalCredits - statement.TotalDebits
+	camtStatement.Summary = camtSummary{
+		Count:        credits.Count + debits.Count,
+		Sum:          formatAmount(statement.TotalCredits + statement.TotalDebits),
+		NetAmount:    formatAmount(math.Abs(net)),
+		NetIndicator: camtIndicator(net),
+		Credits:      credits,
+		Debits:       debits,
+	}
+
+	document := camtDocument{
+		Statement: camtBankStatement{
+			GroupHeader: camtGroupHeader{
+				MessageId: walletPrefix + strconv.FormatInt(time.Now().UnixNano(), 36),
+				CreatedAt: statement.GeneratedAt,
+			},
+			Statement: camtStatement,
+		},
+	}
+
+	if _, err := io.WriteString(writer, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(writer)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(document); err != nil {
+		return err
+	}
+	_, err := io.WriteString(writer, "\n")
+	return err
+}
+
+// camtBalanceOf writes a balance as a positive amount, a negative balance is a debit balance
+func camtBalanceOf(balanceType string, balance float64, currency string, date string) camtBalance {
+	return camtBalance{
+		Type:      balanceType,
+		Amount:    camtAmount{Currency: currency, Value: formatAmount(math.Abs(balance))},
+		Indicator: camtIndicator(balance),
+		Date:      date,
+	}
+}
+
+func camtIndicator(amount float64) string {
+	if amount < 0 {
+		return "DBIT"
+	}
+	return "CRDT"
+}
+
+// camtId removes the hyphens of a UUID and shortens the result to the length the schema allows
+func camtId(id string, length int) string {
+	return truncate(strings.ReplaceAll(id, "-", ""), length)
+}
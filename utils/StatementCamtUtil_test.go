@@ -0,0 +1,102 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/xml"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// validateCamt053 validates the document against the bundled camt.053 schema with xmllint, the Go standard library
+// has no XML schema validator. The test is skipped where xmllint is not installed.
+func validateCamt053(t *testing.T, document []byte) error {
+	xmllint, err := exec.LookPath("xmllint")
+	if err != nil {
+		t.Skip("xmllint is not installed, skipping schema validation")
+	}
+
+	path := filepath.Join(t.TempDir(), "statement.xml")
+	if err := os.WriteFile(path, document, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	output, err := exec.Command(xmllint, "--noout", "--schema", "testdata/camt.053.001.02.xsd", path).CombinedOutput()
+	if err != nil {
+		t.Log(string(output))
+	}
+	return err
+}
+
+func TestWriteStatementCamt053(t *testing.T) {
+	t.Run("ShouldMapEntriesAndBalances", func(t *testing.T) {
+		var buffer bytes.Buffer
+		assert.Nil(t, WriteStatementCamt053(&buffer, testStatement()))
+
+		var document camtDocument
+		assert.Nil(t, xml.Unmarshal(buffer.Bytes(), &document))
+		statement := document.Statement.Statement
+		assert.Equal(t, "wallet1", statement.Account.Id)
+		assert.Equal(t, "customer1", statement.Account.OwnerId)
+		assert.Equal(t, "wallet12024100120241031", statement.Id)
+		assert.Equal(t, []camtBalance{
+			{Type: "OPBD", Amount: camtAmount{Currency: "IDR", Value: "10000.00"}, Indicator: "CRDT", Date: "2024-10-01"},
+			{Type: "CLBD", Amount: camtAmount{Currency: "IDR", Value: "7800.50"}, Indicator: "CRDT", Date: "2024-10-31"},
+		}, statement.Balances)
+		assert.Equal(t, 2, statement.Summary.Count)
+		assert.Equal(t, "2199.50", statement.Summary.NetAmount)
+		assert.Equal(t, "DBIT", statement.Summary.NetIndicator)
+
+		debit := statement.Entries[0]
+		assert.Equal(t, "transaction2", debit.Reference)
+		assert.Equal(t, "2500.00", debit.Amount.Value)
+		assert.Equal(t, "DBIT", debit.Indicator)
+		assert.Equal(t, "2024-10-01T10:00:00+07:00", debit.BookedAt)
+		assert.Equal(t, "2024-10-01", debit.ValueDate)
+		assert.Equal(t, "ICDT", debit.Family)
+		assert.Equal(t, "wallet2", debit.Details.CreditorAccount.Id)
+		assert.Nil(t, debit.Details.DebtorAccount)
+
+		credit := statement.Entries[1]
+		assert.Equal(t, "CRDT", credit.Indicator)
+		assert.Equal(t, "RCDT", credit.Family)
+		assert.Equal(t, "wallet2", credit.Details.DebtorAccount.Id)
+	})
+
+	t.Run("ShouldBeValidAgainstSchema", func(t *testing.T) {
+		statement := testStatement()
+		statement.WalletId = "1070f292-5d68-4b30-b37f-32042675ef2a"
+		statement.OpeningBalance = -250.75
+		statement.Entries[0].TransactionId = "c5150131-6ddb-4a7e-824f-7c461f5655cd"
+		statement.Entries[0].Description = strings.Repeat("Long message ", 20)
+		statement.Entries[1].Counterparty = "EXTERNAL_BANK"
+		statement.Entries[1].Description = ""
+
+		var buffer bytes.Buffer
+		assert.Nil(t, WriteStatementCamt053(&buffer, statement))
+		assert.Nil(t, validateCamt053(t, buffer.Bytes()))
+	})
+
+	t.Run("ShouldBeValidWithoutEntries", func(t *testing.T) {
+		statement := testStatement()
+		statement.Entries = nil
+		statement.TotalCredits = 0
+		statement.TotalDebits = 0
+
+		var buffer bytes.Buffer
+		assert.Nil(t, WriteStatementCamt053(&buffer, statement))
+		assert.Nil(t, validateCamt053(t, buffer.Bytes()))
+	})
+
+	t.Run("SchemaShouldRejectInvalidDocument", func(t *testing.T) {
+		var buffer bytes.Buffer
+		assert.Nil(t, WriteStatementCamt053(&buffer, testStatement()))
+
+		// A reference longer than Max35Text must not pass, so a passing validation means something
+		invalid := strings.Replace(buffer.String(), "<NtryRef>transaction2</NtryRef>", "<NtryRef>"+strings.Repeat("x", 36)+"</NtryRef>", 1)
+		assert.NotNil(t, validateCamt053(t, []byte(invalid)))
+	})
+}
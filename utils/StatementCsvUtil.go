@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"PaymentAPI/entity"
+	"PaymentAPI/enums"
+	"encoding/csv"
+	"io"
+	"math"
+	"strconv"
+	"time"
+)
+
+var statementCsvHeader = []string{"date", "transaction_id", "description", "counterparty", "debit", "credit", "balance"}
+
+// WriteStatementCsv writes a statement as CSV. The first row carries the opening balance and the last row the totals
+// and closing balance, so the balance column can be followed from top to bottom in a spreadsheet.
+func WriteStatementCsv(writer io.Writer, statement entity.Statement) error {
+	csvWriter := csv.NewWriter(writer)
+	if err := csvWriter.Write(statementCsvHeader); err != nil {
+		return err
+	}
+
+	if err := csvWriter.Write([]string{statement.PeriodStart, "", "Opening balance", "", "", "", formatAmount(statement.OpeningBalance)}); err != nil {
+		return err
+	}
+
+	for _, entry := range statement.Entries {
+		debit, credit := "", ""
+		if entry.Type == enums.STATEMENT_DEBIT {
+			debit = formatAmount(-entry.Amount)
+		} else {
+			credit = formatAmount(entry.Amount)
+		}
+
+		record := []string{
+			statementDate(entry.BookedAt),
+			entry.TransactionId,
+			entry.Description,
+			entry.Counterparty,
+			debit,
+			credit,
+			formatAmount(entry.Balance),
+		}
+		if err := csvWriter.Write(record); err != nil {
+			return err
+		}
+	}
+
+	closing := []string{statement.PeriodEnd, "", "Closing balance", "", formatAmount(statement.TotalDebits), formatAmount(statement.TotalCredits), formatAmount(statement.ClosingBalance)}
+	if err := csvWriter.Write(closing); err != nil {
+		return err
+	}
+
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+// formatAmount writes an amount with the two decimals used in every statement format
+func formatAmount(amount float64) string {
+	// Rounding first and adding zero keeps an amount like -0.001 from being written as -0.00
+	return strconv.FormatFloat(math.Round(amount*100)/100+0, 'f', 2, 64)
+}
+
+// statementDate returns the day of an RFC3339 timestamp, or the timestamp unchanged when it cannot be parsed
+func statementDate(timestamp string) string {
+	parsed, err := time.Parse(time.RFC3339, timestamp)
+	if err != nil {
+		return timestamp
+	}
+	return parsed.Format(time.DateOnly)
+}
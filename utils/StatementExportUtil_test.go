@@ -0,0 +1,101 @@
+package utils
+
+import (
+	"PaymentAPI/entity"
+	"PaymentAPI/enums"
+	"bytes"
+	"encoding/xml"
+	"github.com/stretchr/testify/assert"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func testStatement() entity.Statement {
+	return entity.Statement{
+		WalletId:       "wallet-1",
+		CustomerId:     "customer-1",
+		Currency:       "IDR",
+		PeriodStart:    "2024-10-01",
+		PeriodEnd:      "2024-10-31",
+		OpeningBalance: 10000,
+		TotalCredits:   300.5,
+		TotalDebits:    2500,
+		ClosingBalance: 7800.5,
+		GeneratedAt:    "2024-11-01T08:00:00+07:00",
+		Entries: []entity.StatementEntry{
+			{TransactionId: "transaction-2", BookedAt: "2024-10-01T10:00:00+07:00", Type: enums.STATEMENT_DEBIT, Counterparty: "wallet-2", Description: "Lunch, (shared)", Amount: -2500, Balance: 7500},
+			{TransactionId: "transaction-4", BookedAt: "2024-10-12T09:30:00+07:00", Type: enums.STATEMENT_CREDIT, Counterparty: "wallet-2", Description: "Refund <café>", Amount: 300.5, Balance: 7800.5},
+		},
+	}
+}
+
+func TestWriteStatementCsv(t *testing.T) {
+	var buffer bytes.Buffer
+	assert.Nil(t, WriteStatementCsv(&buffer, testStatement()))
+	assert.Equal(t, "date,transaction_id,description,counterparty,debit,credit,balance\n"+
+		"2024-10-01,,Opening balance,,,,10000.00\n"+
+		"2024-10-01,transaction-2,\"Lunch, (shared)\",wallet-2,2500.00,,7500.00\n"+
+		"2024-10-12,transaction-4,Refund <café>,wallet-2,,300.50,7800.50\n"+
+		"2024-10-31,,Closing balance,,2500.00,300.50,7800.50\n", buffer.String())
+}
+
+func TestWriteStatementOfx(t *testing.T) {
+	var buffer bytes.Buffer
+	assert.Nil(t, WriteStatementOfx(&buffer, testStatement()))
+	ofx := buffer.String()
+
+	assert.True(t, strings.HasPrefix(ofx, `<?xml version="1.0"`))
+	assert.Contains(t, ofx, `<?OFX OFXHEADER="200" VERSION="220"`)
+	assert.Nil(t, xml.Unmarshal(buffer.Bytes(), new(interface{})))
+
+	var document ofxDocument
+	assert.Nil(t, xml.Unmarshal([]byte(ofx[strings.Index(ofx, "<OFX>"):]), &document))
+	statement := document.Bank.Statement
+	assert.Equal(t, "IDR", statement.CurDef)
+	assert.Equal(t, "wallet-1", statement.AcctId)
+	assert.Equal(t, "20241001", statement.DtStart)
+	assert.Equal(t, "7800.50", statement.LedgerBal.BalAmt)
+	assert.Equal(t, []ofxTransaction{
+		{TrnType: "DEBIT", DtPosted: "20241001030000[0:GMT]", TrnAmt: "-2500.00", FitId: "transaction-2", Name: "wallet-2", Memo: "Lunch, (shared)"},
+		{TrnType: "CREDIT", DtPosted: "20241012023000[0:GMT]", TrnAmt: "300.50", FitId: "transaction-4", Name: "wallet-2", Memo: "Refund <café>"},
+	}, statement.Transactions)
+}
+
+func TestWriteStatementPdf(t *testing.T) {
+	t.Run("ShouldWriteValidCrossReferenceTable", func(t *testing.T) {
+		var buffer bytes.Buffer
+		assert.Nil(t, WriteStatementPdf(&buffer, testStatement()))
+		pdf := buffer.String()
+
+		assert.True(t, strings.HasPrefix(pdf, "%PDF-1.4\n"))
+		assert.True(t, strings.HasSuffix(pdf, "%%EOF\n"))
+		assert.Contains(t, pdf, `(2024-10-01 Lunch, \(shared\)`)
+		assert.Contains(t, pdf, "Refund <caf?>")
+		assert.Contains(t, pdf, "(Page 1 of 1)")
+
+		// Every offset in the cross-reference table points at the object it lists
+		startxref, _ := strconv.Atoi(regexp.MustCompile(`startxref\n(\d+)`).FindStringSubmatch(pdf)[1])
+		assert.True(t, strings.HasPrefix(pdf[startxref:], "xref\n"))
+		entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllStringSubmatch(pdf, -1)
+		assert.Len(t, entries, 5)
+		for i, entry := range entries {
+			offset, _ := strconv.Atoi(entry[1])
+			assert.True(t, strings.HasPrefix(pdf[offset:], strconv.Itoa(i+1)+" 0 obj\n"))
+		}
+	})
+
+	t.Run("ShouldContinueOnNextPage", func(t *testing.T) {
+		statement := testStatement()
+		for i := 0; i < 100; i++ {
+			statement.Entries = append(statement.Entries, statement.Entries[0])
+		}
+
+		var buffer bytes.Buffer
+		assert.Nil(t, WriteStatementPdf(&buffer, statement))
+		assert.Contains(t, buffer.String(), "/Count 2")
+		assert.Contains(t, buffer.String(), "(Page 2 of 2)")
+		assert.Equal(t, 2, strings.Count(buffer.String(), "(Date       Description"))
+	})
+}
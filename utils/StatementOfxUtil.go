@@ -0,0 +1,132 @@
+package utils
+
+import (
+	"PaymentAPI/entity"
+	"encoding/xml"
+	"io"
+	"time"
+)
+
+// ofxHeader marks the document as OFX 2.2, the XML version of OFX understood by accounting software
+const ofxHeader = `<?xml version="1.0" encoding="UTF-8" standalone="no"?>` + "\n" +
+	`<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>` + "\n"
+
+// ofxBankId identifies this system as the bank holding the account
+const ofxBankId = "PAYMENTAPI"
+
+// ofxNameLength is the longest payee name OFX allows
+const ofxNameLength = 32
+
+type ofxDocument struct {
+	XMLName xml.Name         `xml:"OFX"`
+	SignOn  ofxSignOnMessage `xml:"SIGNONMSGSRSV1>SONRS"`
+	Bank    ofxBankMessage   `xml:"BANKMSGSRSV1>STMTTRNRS"`
+}
+
+type ofxStatus struct {
+	Code     int    `xml:"CODE"`
+	Severity string `xml:"SEVERITY"`
+}
+
+type ofxSignOnMessage struct {
+	Status   ofxStatus `xml:"STATUS"`
+	DtServer string    `xml:"DTSERVER"`
+	Language string    `xml:"LANGUAGE"`
+}
+
+type ofxBankMessage struct {
+	TrnUid    string       `xml:"TRNUID"`
+	Status    ofxStatus    `xml:"STATUS"`
+	Statement ofxStatement `xml:"STMTRS"`
+}
+
+type ofxStatement struct {
+	CurDef       string           `xml:"CURDEF"`
+	BankId       string           `xml:"BANKACCTFROM>BANKID"`
+	AcctId       string           `xml:"BANKACCTFROM>ACCTID"`
+	AcctType     string           `xml:"BANKACCTFROM>ACCTTYPE"`
+	DtStart      string           `xml:"BANKTRANLIST>DTSTART"`
+	DtEnd        string           `xml:"BANKTRANLIST>DTEND"`
+	Transactions []ofxTransaction `xml:"BANKTRANLIST>STMTTRN"`
+	LedgerBal    ofxLedgerBalance `xml:"LEDGERBAL"`
+}
+
+type ofxTransaction struct {
+	TrnType  string `xml:"TRNTYPE"`
+	DtPosted string `xml:"DTPOSTED"`
+	TrnAmt   string `xml:"TRNAMT"`
+	FitId    string `xml:"FITID"`
+	Name     string `xml:"NAME,omitempty"`
+	Memo     string `xml:"MEMO,omitempty"`
+}
+
+type ofxLedgerBalance struct {
+	BalAmt string `xml:"BALAMT"`
+	DtAsOf string `xml:"DTASOF"`
+}
+
+// WriteStatementOfx writes a statement as an OFX bank statement. The transaction ID is the FITID, so importing the
+// same statement twice does not duplicate transactions, and the closing balance is the ledger balance.
+func WriteStatementOfx(writer io.Writer, statement entity.Statement) error {
+	ofxStatement := ofxStatement{
+		CurDef:   statement.Currency,
+		BankId:   ofxBankId,
+		AcctId:   statement.WalletId,
+		AcctType: "CHECKING",
+		DtStart:  ofxDate(statement.PeriodStart),
+		DtEnd:    ofxDate(statement.PeriodEnd),
+		LedgerBal: ofxLedgerBalance{
+			BalAmt: formatAmount(statement.ClosingBalance),
+			DtAsOf: ofxDate(statement.PeriodEnd),
+		},
+	}
+	for _, entry := range statement.Entries {
+		name := entry.Counterparty
+		if len(name) > ofxNameLength {
+			name = name[:ofxNameLength]
+		}
+		ofxStatement.Transactions = append(ofxStatement.Transactions, ofxTransaction{
+			TrnType:  string(entry.Type),
+			DtPosted: ofxDate(entry.BookedAt),
+			TrnAmt:   formatAmount(entry.Amount),
+			FitId:    entry.TransactionId,
+			Name:     name,
+			Memo:     entry.Description,
+		})
+	}
+
+	document := ofxDocument{
+		SignOn: ofxSignOnMessage{
+			Status:   ofxStatus{Code: 0, Severity: "INFO"},
+			DtServer: ofxDate(statement.GeneratedAt),
+			Language: "ENG",
+		},
+		Bank: ofxBankMessage{
+			TrnUid:    "0",
+			Status:    ofxStatus{Code: 0, Severity: "INFO"},
+			Statement: ofxStatement,
+		},
+	}
+
+	if _, err := io.WriteString(writer, ofxHeader); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(writer)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(document); err != nil {
+		return err
+	}
+	_, err := io.WriteString(writer, "\n")
+	return err
+}
+
+// ofxDate converts an RFC3339 timestamp to an OFX date time in UTC, and a date to an OFX date
+func ofxDate(value string) string {
+	if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+		return parsed.UTC().Format("20060102150405") + "[0:GMT]"
+	}
+	if parsed, err := time.Parse(time.DateOnly, value); err == nil {
+		return parsed.Format("20060102")
+	}
+	return value
+}
@@ -0,0 +1,144 @@
+package utils
+
+import (
+	"PaymentAPI/entity"
+	"PaymentAPI/enums"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// The statement is typeset in the built-in Courier font, so a PDF viewer needs no embedded font and the columns line
+// up by character count. An A4 page fits 95 characters of 9pt Courier between the margins.
+const (
+	pdfPageWidth    = 595
+	pdfPageHeight   = 842
+	pdfMargin       = 40
+	pdfFontSize     = 9
+	pdfLineHeight   = 12
+	pdfLinesPerPage = 60
+)
+
+// statementPdfRow lays out one row of the table: date, description, counterparty, debit, credit and balance
+const statementPdfRow = "%-10s %-26s %-20s %11s %11s %12s"
+
+// WriteStatementPdf writes a statement as a PDF document with a summary and a table of the transactions,
+// continued on as many pages as needed with the table header repeated on every page
+func WriteStatementPdf(writer io.Writer, statement entity.Statement) error {
+	summary := []string{
+		"ACCOUNT STATEMENT",
+		"",
+		"Wallet:          " + statement.WalletId,
+		"Customer:        " + statement.CustomerId,
+		"Period:          " + statement.PeriodStart + " to " + statement.PeriodEnd,
+		"Currency:        " + statement.Currency,
+		"Opening balance: " + formatAmount(statement.OpeningBalance),
+		"Total credits:   " + formatAmount(statement.TotalCredits),
+		"Total debits:    " + formatAmount(statement.TotalDebits),
+		"Closing balance: " + formatAmount(statement.ClosingBalance),
+		"Generated at:    " + statement.GeneratedAt,
+		"",
+	}
+
+	header := []string{
+		fmt.Sprintf(statementPdfRow, "Date", "Description", "Counterparty", "Debit", "Credit", "Balance"),
+		strings.Repeat("-", 95),
+	}
+
+	rows := []string{fmt.Sprintf(statementPdfRow, statement.PeriodStart, "Opening balance", "", "", "", formatAmount(statement.OpeningBalance))}
+	for _, entry := range statement.Entries {
+		debit, credit := "", ""
+		if entry.Type == enums.STATEMENT_DEBIT {
+			debit = formatAmount(-entry.Amount)
+		} else {
+			credit = formatAmount(entry.Amount)
+		}
+		rows = append(rows, fmt.Sprintf(statementPdfRow,
+			statementDate(entry.BookedAt),
+			truncate(pdfText(entry.Description), 26),
+			truncate(pdfText(entry.Counterparty), 20),
+			debit,
+			credit,
+			formatAmount(entry.Balance),
+		))
+	}
+	rows = append(rows, fmt.Sprintf(statementPdfRow, statement.PeriodEnd, "Closing balance", "", formatAmount(statement.TotalDebits), formatAmount(statement.TotalCredits), formatAmount(statement.ClosingBalance)))
+
+	// The summary only appears on the first page, the table header on every page
+	var pages [][]string
+	page := append(append([]string{}, summary...), header...)
+	for _, row := range rows {
+		if len(page) == pdfLinesPerPage {
+			pages = append(pages, page)
+			page = append([]string{}, header...)
+		}
+		page = append(page, row)
+	}
+	pages = append(pages, page)
+
+	return writePdf(writer, pages)
+}
+
+// writePdf writes a PDF with one page per group of lines and a page number at the bottom of every page.
+// Objects 1 to 3 are the catalog, the page tree and the font, then every page is followed by its content stream.
+func writePdf(writer io.Writer, pages [][]string) error {
+	var objects []string
+	objects = append(objects, "<< /Type /Catalog /Pages 2 0 R >>")
+
+	var kids []string
+	for i := range pages {
+		kids = append(kids, fmt.Sprintf("%d 0 R", 4+2*i))
+	}
+	objects = append(objects, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	objects = append(objects, "<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>")
+
+	for i, lines := range pages {
+		var content bytes.Buffer
+		fmt.Fprintf(&content, "BT\n/F1 %d Tf\n%d TL\n%d %d Td\n", pdfFontSize, pdfLineHeight, pdfMargin, pdfPageHeight-pdfMargin)
+		for _, line := range lines {
+			fmt.Fprintf(&content, "(%s) Tj T*\n", escapePdfText(pdfText(line)))
+		}
+		fmt.Fprintf(&content, "ET\nBT\n/F1 %d Tf\n%d %d Td\n(Page %d of %d) Tj\nET\n", pdfFontSize, pdfMargin, pdfMargin/2, i+1, len(pages))
+
+		objects = append(objects, fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>",
+			pdfPageWidth, pdfPageHeight, 5+2*i))
+		objects = append(objects, fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()))
+	}
+
+	var document bytes.Buffer
+	document.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = document.Len()
+		fmt.Fprintf(&document, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+
+	// The cross-reference table gives the byte offset of every object, each entry is exactly 20 bytes long
+	xref := document.Len()
+	fmt.Fprintf(&document, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&document, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&document, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	_, err := writer.Write(document.Bytes())
+	return err
+}
+
+// escapePdfText escapes the characters with a meaning inside a PDF string
+func escapePdfText(text string) string {
+	return strings.NewReplacer(`\`, `\\`, "(", `\(`, ")", `\)`).Replace(text)
+}
+
+// pdfText replaces what Courier cannot show with a question mark, one per character so columns keep their width
+func pdfText(text string) string {
+	var printable strings.Builder
+	for _, r := range text {
+		if r < 32 || r > 126 {
+			r = '?'
+		}
+		printable.WriteRune(r)
+	}
+	return printable.String()
+}
@@ -0,0 +1,73 @@
+package utils
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// rfc6238Secret is the SHA-1 test secret of RFC 6238, "12345678901234567890" as base32
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTotpCode(t *testing.T) {
+	// The six digit codes are the last six digits of the eight digit codes in appendix B of RFC 6238
+	tests := map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1111111111: "050471",
+		1234567890: "005924",
+		2000000000: "279037",
+	}
+
+	for unix, expected := range tests {
+		code, err := TotpCode(rfc6238Secret, TotpStep(time.Unix(unix, 0)))
+		assert.Nil(t, err)
+		assert.Equal(t, expected, code)
+	}
+}
+
+func TestVerifyTotpCode(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	current := TotpStep(now)
+
+	t.Run("ShouldAcceptAdjacentSteps", func(t *testing.T) {
+		for _, step := range []int64{current - 1, current, current + 1} {
+			code, _ := TotpCode(rfc6238Secret, step)
+			matched, ok := VerifyTotpCode(rfc6238Secret, code, now, 0)
+			assert.True(t, ok)
+			assert.Equal(t, step, matched)
+		}
+	})
+
+	t.Run("ShouldRefuseDistantStep", func(t *testing.T) {
+		code, _ := TotpCode(rfc6238Secret, current-2)
+		_, ok := VerifyTotpCode(rfc6238Secret, code, now, 0)
+		assert.False(t, ok)
+	})
+
+	t.Run("ShouldRefuseReplayedStep", func(t *testing.T) {
+		code, _ := TotpCode(rfc6238Secret, current)
+		_, ok := VerifyTotpCode(rfc6238Secret, code, now, current)
+		assert.False(t, ok)
+	})
+
+	t.Run("ShouldRefuseMalformedCode", func(t *testing.T) {
+		_, ok := VerifyTotpCode(rfc6238Secret, "12345", now, 0)
+		assert.False(t, ok)
+	})
+}
+
+func TestTotpProvisioningUri(t *testing.T) {
+	secret, err := GenerateTotpSecret()
+	assert.Nil(t, err)
+	assert.Len(t, secret, 32)
+
+	uri, err := url.Parse(TotpProvisioningUri("Payment API App", "johndoe", secret))
+	assert.Nil(t, err)
+	assert.Equal(t, "otpauth", uri.Scheme)
+	assert.Equal(t, "totp", uri.Host)
+	assert.Equal(t, "/Payment API App:johndoe", uri.Path)
+	assert.Equal(t, secret, uri.Query().Get("secret"))
+	assert.Equal(t, "Payment API App", uri.Query().Get("issuer"))
+}
@@ -0,0 +1,24 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// webhookSignaturePrefix names the algorithm in the signature header, so receivers can tell it apart from future schemes
+const webhookSignaturePrefix = "sha256="
+
+// SignWebhookPayload signs the timestamp and raw body of a webhook delivery with the secret of its endpoint
+func SignWebhookPayload(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return webhookSignaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyWebhookSignature reports whether the signature header matches the timestamp and raw body of a webhook delivery
+func VerifyWebhookSignature(secret string, timestamp string, body []byte, signature string) bool {
+	expected := SignWebhookPayload(secret, timestamp, body)
+	return hmac.Equal([]byte(expected), []byte(signature))
+}